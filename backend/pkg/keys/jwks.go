@@ -0,0 +1,87 @@
+// Copyright 2023 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// JSON Web Key parameters, see RFC 7517, RFC 7518 and RFC 8037.
+const (
+	JWKKeyTypeRSA = "RSA"
+	JWKKeyTypeOKP = "OKP"
+
+	JWKAlgRS256 = "RS256"
+	JWKAlgEdDSA = "EdDSA"
+
+	JWKCurveEd25519 = "Ed25519"
+
+	JWKUseSignature = "sig"
+)
+
+var ErrUnsupportedKeyType = errors.New("unsupported public key type")
+
+// JWK is the JSON Web Key representation of a public key verifying the
+// signature of the tokens.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// RSA public key
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519 public key
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is the JSON Web Key Set published to the token verifiers.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK returns the JSON Web Key of the RSA or Ed25519 public key.
+func NewJWK(keyID string, pub crypto.PublicKey) (*JWK, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			KeyType:   JWKKeyTypeRSA,
+			KeyID:     keyID,
+			Use:       JWKUseSignature,
+			Algorithm: JWKAlgRS256,
+			N:         encode(key.N.Bytes()),
+			E:         encode(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return &JWK{
+			KeyType:   JWKKeyTypeOKP,
+			KeyID:     keyID,
+			Use:       JWKUseSignature,
+			Algorithm: JWKAlgEdDSA,
+			Curve:     JWKCurveEd25519,
+			X:         encode(key),
+		}, nil
+	}
+	return nil, ErrUnsupportedKeyType
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJWK(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := LoadRSAPrivate("testdata/private.pem")
+	assert.NoError(t, err)
+	jwk, err := NewJWK("1", &rsaKey.PublicKey)
+	if assert.NoError(t, err) {
+		assert.Equal(t, JWKKeyTypeRSA, jwk.KeyType)
+		assert.Equal(t, "1", jwk.KeyID)
+		assert.Equal(t, JWKUseSignature, jwk.Use)
+		assert.Equal(t, JWKAlgRS256, jwk.Algorithm)
+		assert.Equal(t, "AQAB", jwk.E)
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		assert.NoError(t, err)
+		assert.Equal(t, rsaKey.N, new(big.Int).SetBytes(n))
+	}
+
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	jwk, err = NewJWK("2", edPub)
+	if assert.NoError(t, err) {
+		assert.Equal(t, JWKKeyTypeOKP, jwk.KeyType)
+		assert.Equal(t, JWKAlgEdDSA, jwk.Algorithm)
+		assert.Equal(t, JWKCurveEd25519, jwk.Curve)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(edPub), jwk.X)
+		assert.Empty(t, jwk.N)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	_, err = NewJWK("3", &ecKey.PublicKey)
+	assert.ErrorIs(t, err, ErrUnsupportedKeyType)
+}
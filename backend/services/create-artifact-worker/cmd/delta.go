@@ -0,0 +1,426 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mendersoftware/mender-artifact/areader"
+	"github.com/mendersoftware/mender-artifact/artifact"
+	"github.com/mendersoftware/mender-artifact/awriter"
+	"github.com/mendersoftware/mender-artifact/handlers"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mendersoftware/mender-server/services/create-artifact-worker/client"
+	"github.com/mendersoftware/mender-server/services/create-artifact-worker/config"
+	"github.com/mendersoftware/mender-server/services/create-artifact-worker/delta"
+	mlog "github.com/mendersoftware/mender-server/services/create-artifact-worker/log"
+)
+
+const (
+	argGetSourceUri = "get-source-uri"
+	argGetTargetUri = "get-target-uri"
+
+	updateTypeRootfs = "rootfs-image"
+	updateTypeDelta  = "mender-binary-delta"
+
+	providesRootfsChecksum = "rootfs-image.checksum"
+)
+
+var deltaCmd = &cobra.Command{
+	Use:   "delta",
+	Short: "Generate a binary delta update between two rootfs-image artifacts.",
+	Long: "\nBesides command line args, supports the following env vars:\n\n" +
+		"CREATE_ARTIFACT_SKIPVERIFY skip ssl verification (default: false)\n" +
+		"CREATE_ARTIFACT_WORKDIR working dir for processing (default: /var)\n" +
+		"CREATE_ARTIFACT_DEPLOYMENTS_URL internal deployments service url\n",
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := NewDeltaCmd(cmd, args)
+		if err != nil {
+			mlog.Error(err.Error())
+			os.Exit(1)
+		}
+
+		err = c.Run()
+		if err != nil {
+			mlog.Error(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	deltaCmd.Flags().String(argArtifactName, "", "artifact name")
+	_ = deltaCmd.MarkFlagRequired(argArtifactName)
+
+	deltaCmd.Flags().String(argArtifactId, "", "artifact id")
+	_ = deltaCmd.MarkFlagRequired(argArtifactId)
+
+	deltaCmd.Flags().String(
+		argGetSourceUri,
+		"",
+		"pre-signed url to the source artifact (GET)",
+	)
+	_ = deltaCmd.MarkFlagRequired(argGetSourceUri)
+
+	deltaCmd.Flags().String(
+		argGetTargetUri,
+		"",
+		"pre-signed url to the target artifact (GET)",
+	)
+	_ = deltaCmd.MarkFlagRequired(argGetTargetUri)
+
+	deltaCmd.Flags().String(argTenantId, "", "tenant id")
+	_ = deltaCmd.MarkFlagRequired(argTenantId)
+
+	deltaCmd.Flags().String(argDeviceType, "", "device type")
+	_ = deltaCmd.MarkFlagRequired(argDeviceType)
+
+	deltaCmd.Flags().String(argDescription, "", "artifact description")
+}
+
+type DeltaCmd struct {
+	DeploymentsUrl string
+	SkipVerify     bool
+	Workdir        string
+
+	ArtifactName string
+	Description  string
+	DeviceTypes  []string
+	ArtifactId   string
+	GetSourceUri string
+	GetTargetUri string
+	TenantId     string
+}
+
+func NewDeltaCmd(cmd *cobra.Command, args []string) (*DeltaCmd, error) {
+	c := &DeltaCmd{}
+
+	if err := c.init(cmd); err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *DeltaCmd) init(cmd *cobra.Command) error {
+	c.DeploymentsUrl = viper.GetString(config.CfgDeploymentsUrl)
+	c.SkipVerify = viper.GetBool(config.CfgSkipVerify)
+	c.Workdir = viper.GetString(config.CfgWorkDir)
+
+	flags := cmd.Flags()
+	for name, dst := range map[string]*string{
+		argArtifactName: &c.ArtifactName,
+		argDescription:  &c.Description,
+		argArtifactId:   &c.ArtifactId,
+		argGetSourceUri: &c.GetSourceUri,
+		argGetTargetUri: &c.GetTargetUri,
+		argTenantId:     &c.TenantId,
+	} {
+		arg, err := flags.GetString(name)
+		if err != nil {
+			return err
+		}
+		*dst = arg
+	}
+
+	arg, err := flags.GetString(argDeviceType)
+	if err != nil {
+		return err
+	}
+	c.DeviceTypes = strings.Split(arg, ",")
+
+	return nil
+}
+
+func (c *DeltaCmd) Validate() error {
+	if err := config.ValidAbsPath(c.Workdir); err != nil {
+		return errors.Wrap(err, "invalid workdir")
+	}
+	if err := config.ValidUrl(c.GetSourceUri); err != nil {
+		return errors.Wrap(err, "invalid source artifact uri")
+	}
+	if err := config.ValidUrl(c.GetTargetUri); err != nil {
+		return errors.Wrap(err, "invalid target artifact uri")
+	}
+	return nil
+}
+
+func (c *DeltaCmd) Run() error {
+	mlog.Info("running delta artifact generation:\n%s", c.dumpArgs())
+	mlog.Info("config:\n%s", config.Dump())
+
+	cd, err := client.NewDeployments(c.DeploymentsUrl, c.SkipVerify)
+	if err != nil {
+		return errors.New("failed to configure 'deployments' client")
+	}
+
+	cs3 := client.NewStorage(c.SkipVerify)
+
+	ctx := context.Background()
+
+	mlog.Verbose("creating temp dir at", c.Workdir)
+
+	workDir, err := os.MkdirTemp(c.Workdir, "delta")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temp dir under workdir %s", c.Workdir)
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			mlog.Error("failed to remove temp working dir %s: %v", workDir, err.Error())
+		}
+	}()
+
+	var source, target *rootfsPayload
+	for _, input := range []struct {
+		name    string
+		uri     string
+		payload **rootfsPayload
+	}{
+		{name: "source", uri: c.GetSourceUri, payload: &source},
+		{name: "target", uri: c.GetTargetUri, payload: &target},
+	} {
+		artifactFile := filepath.Join(workDir, input.name+".mender")
+		mlog.Verbose("downloading %s artifact to %s", input.name, artifactFile)
+		err = cs3.Download(ctx, input.uri, artifactFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to download the %s artifact", input.name)
+		}
+		*input.payload, err = extractRootfsPayload(
+			artifactFile, filepath.Join(workDir, input.name),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the %s artifact", input.name)
+		}
+		if err := os.Remove(artifactFile); err != nil {
+			return err
+		}
+	}
+
+	patchFile := filepath.Join(workDir, target.name+".vcdiff")
+	mlog.Verbose("generating binary delta %s", patchFile)
+	if err := generatePatch(source.path, target.path, patchFile); err != nil {
+		return errors.Wrap(err, "failed to generate the binary delta")
+	}
+
+	// make the filename unique by naming it after the artifact
+	outfile := filepath.Join(workDir, c.ArtifactId+"-generated")
+	mlog.Verbose("generating output artifact %s", outfile)
+	if err := c.writeArtifact(outfile, patchFile, source, target); err != nil {
+		return errors.Wrap(err, "failed to write the delta artifact")
+	}
+
+	mlog.Verbose("uploading generated artifact")
+	err = cd.UploadArtifactInternal(ctx, outfile, c.ArtifactId, c.TenantId, c.Description)
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload generated artifact")
+	}
+
+	return nil
+}
+
+func (c *DeltaCmd) writeArtifact(
+	outfile, patchFile string,
+	source, target *rootfsPayload,
+) error {
+	f, err := os.Create(outfile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	updateType := updateTypeDelta
+	module := handlers.NewModuleImage(updateType)
+	if err := module.SetUpdateFiles([]*handlers.DataFile{{Name: patchFile}}); err != nil {
+		return err
+	}
+	w := awriter.NewWriter(f, artifact.NewCompressorGzip())
+	err = w.WriteArtifact(&awriter.WriteArtifactArgs{
+		Format:  "mender",
+		Version: 3,
+		Devices: c.DeviceTypes,
+		Name:    c.ArtifactName,
+		Updates: &awriter.Updates{Updates: []handlers.Composer{module}},
+		Depends: &artifact.ArtifactDepends{
+			CompatibleDevices: c.DeviceTypes,
+		},
+		Provides: &artifact.ArtifactProvides{
+			ArtifactName: c.ArtifactName,
+		},
+		TypeInfoV3: &artifact.TypeInfoV3{
+			Type: &updateType,
+			ArtifactDepends: artifact.TypeInfoDepends{
+				providesRootfsChecksum: source.provides[providesRootfsChecksum],
+			},
+			ArtifactProvides:       target.provides,
+			ClearsArtifactProvides: target.clearsProvides,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func generatePatch(sourceFile, targetFile, patchFile string) error {
+	src, err := os.Open(sourceFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tgt, err := os.Open(targetFile)
+	if err != nil {
+		return err
+	}
+	defer tgt.Close()
+
+	out, err := os.Create(patchFile)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := bufio.NewWriter(out)
+	err = delta.NewEncoder().Encode(w, src, info.Size(), bufio.NewReader(tgt))
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// rootfsPayload is the root file system image extracted from an artifact.
+type rootfsPayload struct {
+	name           string
+	path           string
+	provides       artifact.TypeInfoProvides
+	clearsProvides []string
+}
+
+// extractRootfsPayload stores the root file system image of the artifact
+// under the given path.
+func extractRootfsPayload(artifactFile, path string) (*rootfsPayload, error) {
+	f, err := os.Open(artifactFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ar := areader.NewReader(bufio.NewReader(f))
+	if err := ar.ReadArtifactHeaders(); err != nil {
+		return nil, err
+	}
+	installers := ar.GetHandlers()
+	if len(installers) != 1 {
+		return nil, errors.New("expected a single payload")
+	}
+	installer := installers[0]
+	if t := installer.GetUpdateType(); t == nil || *t != updateTypeRootfs {
+		return nil, errors.Errorf("expected a %s payload", updateTypeRootfs)
+	}
+	provides, err := installer.GetUpdateProvides()
+	if err != nil {
+		return nil, err
+	}
+	if provides[providesRootfsChecksum] == "" {
+		return nil, errors.Errorf("missing %s provides", providesRootfsChecksum)
+	}
+
+	storer := &fileUpdateStorer{path: path}
+	installer.SetUpdateStorerProducer(storer)
+	if err := ar.ReadArtifactData(); err != nil {
+		return nil, err
+	}
+	if storer.name == "" {
+		return nil, errors.New("missing payload file")
+	}
+	return &rootfsPayload{
+		name:           storer.name,
+		path:           path,
+		provides:       provides,
+		clearsProvides: installer.GetUpdateClearsProvides(),
+	}, nil
+}
+
+// fileUpdateStorer stores the single payload file under the given path.
+type fileUpdateStorer struct {
+	path string
+	name string
+}
+
+func (s *fileUpdateStorer) NewUpdateStorer(
+	updateType *string,
+	payloadNum int,
+) (handlers.UpdateStorer, error) {
+	return s, nil
+}
+
+func (s *fileUpdateStorer) Initialize(
+	artifactHeaders, artifactAugmentedHeaders artifact.HeaderInfoer,
+	payloadHeaders handlers.ArtifactUpdateHeaders,
+) error {
+	return nil
+}
+
+func (s *fileUpdateStorer) PrepareStoreUpdate() error {
+	return nil
+}
+
+func (s *fileUpdateStorer) StoreUpdate(r io.Reader, info os.FileInfo) error {
+	if s.name != "" {
+		return errors.New("expected a single payload file")
+	}
+	s.name = info.Name()
+	f, err := os.Create(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (s *fileUpdateStorer) FinishStoreUpdate() error {
+	return nil
+}
+
+func (c *DeltaCmd) dumpArgs() string {
+	return dumpArg(argArtifactName, c.ArtifactName) +
+		dumpArg(argDescription, c.Description) +
+		dumpArg(argArtifactId, c.ArtifactId) +
+		dumpArg(argDeviceType, strings.Join(c.DeviceTypes, ",")) +
+		dumpArg(argTenantId, c.TenantId) +
+		dumpArg(argGetSourceUri, c.GetSourceUri) +
+		dumpArg(argGetTargetUri, c.GetTargetUri)
+}
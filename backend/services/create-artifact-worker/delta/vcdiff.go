@@ -0,0 +1,359 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+// Package delta implements a binary delta encoder producing VCDIFF
+// (RFC 3284) patches which can be applied with xdelta3.
+package delta
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultBlockSize is the size of the source blocks indexed for
+	// finding matches, file system images are usually 4KiB aligned
+	DefaultBlockSize = 4096
+
+	// DefaultWindowSize is the size of the target windows
+	DefaultWindowSize = 4 << 20
+
+	// DefaultSourceWindowSize is the maximum size of the source segment
+	// of a window, which the decoder keeps in memory; it matches the
+	// default source window of xdelta3
+	DefaultSourceWindowSize = 64 << 20
+)
+
+// VCDIFF constants, see RFC 3284
+const (
+	vcdSource = 0x01
+
+	// instruction codes from the default code table
+	codeAdd  = 1  // ADD, size encoded separately
+	codeCopy = 19 // COPY mode VCD_SELF, size encoded separately
+
+	hashPrime = 16777619
+)
+
+var header = []byte{0xD6, 0xC3, 0xC4, 0x00, 0x00}
+
+// Encoder encodes the difference between a source and a target.
+type Encoder struct {
+	BlockSize        int
+	WindowSize       int
+	SourceWindowSize int
+
+	source     io.ReaderAt
+	sourceSize int64
+	index      map[uint32]int64
+	hashPow    uint32
+	buf        []byte
+}
+
+// NewEncoder returns an encoder with the default block and window sizes.
+func NewEncoder() *Encoder {
+	return &Encoder{
+		BlockSize:        DefaultBlockSize,
+		WindowSize:       DefaultWindowSize,
+		SourceWindowSize: DefaultSourceWindowSize,
+	}
+}
+
+type instruction struct {
+	copy   bool
+	offset int64 // source offset of a COPY
+	data   []byte
+	size   int64
+}
+
+// window is a VCDIFF window: the instructions producing size bytes of the
+// target, copying from a source segment of at most SourceWindowSize bytes.
+type window struct {
+	insts []instruction
+	size  int
+
+	segStart, segEnd int64
+}
+
+// Encode writes the VCDIFF patch which transforms the source into the
+// target to w.
+func (e *Encoder) Encode(
+	w io.Writer,
+	source io.ReaderAt,
+	sourceSize int64,
+	target io.Reader,
+) error {
+	if e.BlockSize <= 0 || e.WindowSize < e.BlockSize ||
+		e.SourceWindowSize < e.BlockSize {
+		return errors.New("delta: invalid block or window size")
+	}
+	e.source = source
+	e.sourceSize = sourceSize
+	e.buf = make([]byte, 32*1024)
+	if err := e.indexSource(); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	window := make([]byte, e.WindowSize)
+	for {
+		n, err := io.ReadFull(target, window)
+		if err == io.EOF {
+			return nil
+		} else if err != nil && err != io.ErrUnexpectedEOF {
+			return errors.Wrap(err, "delta: failed to read the target")
+		}
+		windows, err := e.match(window[:n])
+		if err != nil {
+			return err
+		}
+		for _, win := range windows {
+			if _, err := w.Write(encodeWindow(win.insts, win.size)); err != nil {
+				return err
+			}
+		}
+		if n < len(window) {
+			return nil
+		}
+	}
+}
+
+// indexSource computes the hashes of the source blocks; only the first
+// occurrence of a block is indexed.
+func (e *Encoder) indexSource() error {
+	e.index = make(map[uint32]int64, e.sourceSize/int64(e.BlockSize))
+	e.hashPow = 1
+	for i := 1; i < e.BlockSize; i++ {
+		e.hashPow *= hashPrime
+	}
+	block := make([]byte, e.BlockSize)
+	for off := int64(0); off+int64(e.BlockSize) <= e.sourceSize; off += int64(e.BlockSize) {
+		if _, err := e.source.ReadAt(block, off); err != nil {
+			return errors.Wrap(err, "delta: failed to read the source")
+		}
+		h := hash(block)
+		if _, ok := e.index[h]; !ok {
+			e.index[h] = off
+		}
+	}
+	return nil
+}
+
+// match splits the target window into COPY and ADD instructions; the
+// window is split further where a COPY does not fit in the source segment
+// of the preceding instructions.
+func (e *Encoder) match(t []byte) ([]window, error) {
+	var (
+		windows []window
+		cur     = window{segStart: -1}
+	)
+	addData := func(data []byte) {
+		cur.insts = append(cur.insts, instruction{data: data})
+		cur.size += len(data)
+	}
+	addCopy := func(offset int64, size int) {
+		segStart, segEnd := offset, offset+int64(size)
+		if cur.segStart >= 0 {
+			segStart = min(segStart, cur.segStart)
+			segEnd = max(segEnd, cur.segEnd)
+		}
+		if segEnd-segStart > int64(e.SourceWindowSize) {
+			windows = append(windows, cur)
+			cur = window{}
+			segStart, segEnd = offset, offset+int64(size)
+		}
+		cur.segStart, cur.segEnd = segStart, segEnd
+		cur.insts = append(cur.insts, instruction{
+			copy:   true,
+			offset: offset,
+			size:   int64(size),
+		})
+		cur.size += size
+	}
+	bs := e.BlockSize
+	anchor := 0
+	i := 0
+	var h uint32
+	if len(t) >= bs {
+		h = hash(t[:bs])
+	}
+	for i+bs <= len(t) {
+		off, ok := e.index[h]
+		if ok {
+			start, end, srcOff, err := e.extend(t, anchor, i, off)
+			if err != nil {
+				return nil, err
+			}
+			if start < end {
+				if end-start > e.SourceWindowSize {
+					end = start + e.SourceWindowSize
+				}
+				if anchor < start {
+					addData(t[anchor:start])
+				}
+				addCopy(srcOff, end-start)
+				anchor = end
+				i = end
+				if i+bs <= len(t) {
+					h = hash(t[i : i+bs])
+				}
+				continue
+			}
+		}
+		if i+bs < len(t) {
+			h = (h-uint32(t[i])*e.hashPow)*hashPrime + uint32(t[i+bs])
+		}
+		i++
+	}
+	if anchor < len(t) {
+		addData(t[anchor:])
+	}
+	return append(windows, cur), nil
+}
+
+// extend verifies the candidate match of the target at i with the source
+// at off and extends it in both directions; it returns the matching
+// target range and the source offset of its beginning.
+func (e *Encoder) extend(t []byte, anchor, i int, off int64) (int, int, int64, error) {
+	// forward
+	end := i
+	srcOff := off
+	for end < len(t) && srcOff < e.sourceSize {
+		n := len(e.buf)
+		if rem := len(t) - end; rem < n {
+			n = rem
+		}
+		if rem := e.sourceSize - srcOff; rem < int64(n) {
+			n = int(rem)
+		}
+		if _, err := e.source.ReadAt(e.buf[:n], srcOff); err != nil && err != io.EOF {
+			return 0, 0, 0, errors.Wrap(err, "delta: failed to read the source")
+		}
+		k := 0
+		for k < n && e.buf[k] == t[end+k] {
+			k++
+		}
+		end += k
+		srcOff += int64(k)
+		if k < n {
+			break
+		}
+	}
+	if end-i < e.BlockSize {
+		// hash collision
+		return i, i, off, nil
+	}
+
+	// backward, up to the pending ADD data
+	start := i
+	srcOff = off
+	for start > anchor && srcOff > 0 {
+		n := len(e.buf)
+		if rem := start - anchor; rem < n {
+			n = rem
+		}
+		if int64(n) > srcOff {
+			n = int(srcOff)
+		}
+		if _, err := e.source.ReadAt(e.buf[:n], srcOff-int64(n)); err != nil {
+			return 0, 0, 0, errors.Wrap(err, "delta: failed to read the source")
+		}
+		k := 0
+		for k < n && e.buf[n-1-k] == t[start-1-k] {
+			k++
+		}
+		start -= k
+		srcOff -= int64(k)
+		if k < n {
+			break
+		}
+	}
+	return start, end, srcOff, nil
+}
+
+func encodeWindow(insts []instruction, size int) []byte {
+	var data, inst, addr []byte
+
+	// the source segment spans all the COPY instructions of the window
+	var segStart, segEnd int64 = -1, 0
+	for _, in := range insts {
+		if !in.copy {
+			continue
+		}
+		if segStart < 0 || in.offset < segStart {
+			segStart = in.offset
+		}
+		if in.offset+in.size > segEnd {
+			segEnd = in.offset + in.size
+		}
+	}
+	for _, in := range insts {
+		if in.copy {
+			inst = append(inst, codeCopy)
+			inst = appendInt(inst, uint64(in.size))
+			addr = appendInt(addr, uint64(in.offset-segStart))
+		} else {
+			inst = append(inst, codeAdd)
+			inst = appendInt(inst, uint64(len(in.data)))
+			data = append(data, in.data...)
+		}
+	}
+
+	var body bytes.Buffer
+	body.Write(appendInt(nil, uint64(size)))
+	body.WriteByte(0) // Delta_Indicator
+	body.Write(appendInt(nil, uint64(len(data))))
+	body.Write(appendInt(nil, uint64(len(inst))))
+	body.Write(appendInt(nil, uint64(len(addr))))
+	body.Write(data)
+	body.Write(inst)
+	body.Write(addr)
+
+	var out []byte
+	if segStart >= 0 {
+		out = append(out, vcdSource)
+		out = appendInt(out, uint64(segEnd-segStart))
+		out = appendInt(out, uint64(segStart))
+	} else {
+		out = append(out, 0)
+	}
+	out = appendInt(out, uint64(body.Len()))
+	return append(out, body.Bytes()...)
+}
+
+// appendInt appends the VCDIFF variable length encoding of v: base 128
+// digits, most significant first, with the high bit set on all but the
+// last byte.
+func appendInt(b []byte, v uint64) []byte {
+	var tmp [10]byte
+	i := len(tmp) - 1
+	tmp[i] = byte(v & 0x7f)
+	for v >>= 7; v > 0; v >>= 7 {
+		i--
+		tmp[i] = byte(v&0x7f) | 0x80
+	}
+	return append(b, tmp[i:]...)
+}
+
+func hash(b []byte) uint32 {
+	var h uint32
+	for _, c := range b {
+		h = h*hashPrime + uint32(c)
+	}
+	return h
+}
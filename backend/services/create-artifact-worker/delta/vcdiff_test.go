@@ -0,0 +1,272 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readInt(r *bufio.Reader) (uint64, error) {
+	var v uint64
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<7 | uint64(c&0x7f)
+		if c&0x80 == 0 {
+			return v, nil
+		}
+	}
+}
+
+// apply decodes the subset of VCDIFF produced by the encoder, failing if
+// the source segment of a window exceeds maxSegment bytes.
+func apply(source []byte, patch []byte, maxSegment int) ([]byte, error) {
+	r := bufio.NewReader(bytes.NewReader(patch))
+	hdr := make([]byte, len(header))
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, err
+	} else if !bytes.Equal(hdr, header) {
+		return nil, errors.New("invalid header")
+	}
+	var out []byte
+	for {
+		indicator, err := r.ReadByte()
+		if err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return nil, err
+		}
+		var segLen, segPos uint64
+		if indicator&vcdSource != 0 {
+			if segLen, err = readInt(r); err != nil {
+				return nil, err
+			}
+			if segPos, err = readInt(r); err != nil {
+				return nil, err
+			}
+		}
+		var fields [6]uint64
+		for i := range fields {
+			if i == 2 {
+				if _, err := r.ReadByte(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if fields[i], err = readInt(r); err != nil {
+				return nil, err
+			}
+		}
+		size := fields[1]
+		data := make([]byte, fields[3])
+		inst := make([]byte, fields[4])
+		addr := make([]byte, fields[5])
+		for _, section := range [][]byte{data, inst, addr} {
+			if _, err := io.ReadFull(r, section); err != nil {
+				return nil, err
+			}
+		}
+		if segLen > uint64(maxSegment) {
+			return nil, errors.Errorf("source segment of %d bytes", segLen)
+		}
+		segment := source[segPos : segPos+segLen]
+		instR := bufio.NewReader(bytes.NewReader(inst))
+		addrR := bufio.NewReader(bytes.NewReader(addr))
+		window := make([]byte, 0, size)
+		for {
+			code, err := instR.ReadByte()
+			if err == io.EOF {
+				break
+			}
+			n, err := readInt(instR)
+			if err != nil {
+				return nil, err
+			}
+			switch code {
+			case codeAdd:
+				window = append(window, data[:n]...)
+				data = data[n:]
+			case codeCopy:
+				a, err := readInt(addrR)
+				if err != nil {
+					return nil, err
+				}
+				window = append(window, segment[a:a+n]...)
+			default:
+				return nil, errors.Errorf("unexpected instruction %d", code)
+			}
+		}
+		if uint64(len(window)) != size {
+			return nil, errors.New("invalid window size")
+		}
+		out = append(out, window...)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		rnd.Read(b)
+		return b
+	}
+	concat := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	base := random(64 * 1024)
+	modified := append([]byte{}, base...)
+	copy(modified[10000:], random(100))
+
+	testCases := map[string]struct {
+		Source []byte
+		Target []byte
+
+		SourceWindowSize int
+
+		// maximum expected patch size
+		MaxSize int
+	}{
+		"identical": {
+			Source:  base,
+			Target:  base,
+			MaxSize: 100,
+		},
+		"modified block": {
+			Source:  base,
+			Target:  modified,
+			MaxSize: 2*DefaultBlockSize + 100,
+		},
+		"inserted and removed data": {
+			Source:  base,
+			Target:  concat(random(10), base[:30000], random(777), base[40000:]),
+			MaxSize: DefaultBlockSize,
+		},
+		"reordered data": {
+			Source:  base,
+			Target:  concat(base[32768:], base[:32768]),
+			MaxSize: 100,
+		},
+		"reordered data, small source window": {
+			Source: base,
+			Target: concat(base[32768:], base[:32768]),
+			// the matches are split into windows of the source
+			// window size
+			SourceWindowSize: 8 * 1024,
+			MaxSize:          200,
+		},
+		"empty source": {
+			Target:  base,
+			MaxSize: len(base) + 100,
+		},
+		"empty target": {
+			Source:  base,
+			MaxSize: len(header),
+		},
+		"unaligned short target": {
+			Source:  base,
+			Target:  base[1:100],
+			MaxSize: 200,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			enc := NewEncoder()
+			enc.WindowSize = 16 * 1024
+			if tc.SourceWindowSize > 0 {
+				enc.SourceWindowSize = tc.SourceWindowSize
+			}
+
+			var patch bytes.Buffer
+			err := enc.Encode(&patch, bytes.NewReader(tc.Source),
+				int64(len(tc.Source)), bytes.NewReader(tc.Target))
+			require.NoError(t, err)
+			assert.LessOrEqual(t, patch.Len(), tc.MaxSize)
+
+			target, err := apply(tc.Source, patch.Bytes(), enc.SourceWindowSize)
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.Target), len(target))
+			assert.True(t, bytes.Equal(tc.Target, target))
+		})
+	}
+}
+
+// TestEncodeXdelta3 decodes the patches with xdelta3, the decoder of the
+// binary delta update module, when it is installed.
+func TestEncodeXdelta3(t *testing.T) {
+	t.Parallel()
+
+	xdelta3, err := exec.LookPath("xdelta3")
+	if err != nil {
+		t.Skip("xdelta3 is not installed")
+	}
+	rnd := rand.New(rand.NewSource(2))
+	source := make([]byte, 1<<20)
+	rnd.Read(source)
+	// a file system image with modified, moved and new blocks
+	target := bytes.Join([][]byte{
+		source[512*1024:],
+		source[4096 : 256*1024],
+		make([]byte, 10000),
+		source[100:4000],
+	}, nil)
+	copy(target[300000:], []byte("modified"))
+
+	enc := NewEncoder()
+	enc.WindowSize = 64 * 1024
+	enc.SourceWindowSize = 256 * 1024
+
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "source")
+	patchPath := filepath.Join(dir, "patch")
+	require.NoError(t, os.WriteFile(sourcePath, source, 0o600))
+	var patch bytes.Buffer
+	require.NoError(t, enc.Encode(&patch, bytes.NewReader(source),
+		int64(len(source)), bytes.NewReader(target)))
+	require.NoError(t, os.WriteFile(patchPath, patch.Bytes(), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(xdelta3, "-d", "-c", "-s", sourcePath, patchPath)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	require.NoError(t, cmd.Run(), stderr.String())
+	assert.True(t, bytes.Equal(target, stdout.Bytes()))
+}
+
+func TestAppendInt(t *testing.T) {
+	t.Parallel()
+
+	// example from RFC 3284 section 2
+	assert.Equal(t, []byte{0xBA, 0xEF, 0x9A, 0x15}, appendInt(nil, 123456789))
+	assert.Equal(t, []byte{0x00}, appendInt(nil, 0))
+	assert.Equal(t, []byte{0x81, 0x00}, appendInt(nil, 128))
+}
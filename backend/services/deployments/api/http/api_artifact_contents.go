@@ -0,0 +1,43 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+)
+
+// GetArtifactContents returns the files of the artifact payloads.
+func (d *DeploymentsApiHandlers) GetArtifactContents(c *gin.Context) {
+	id := c.Param("id")
+	if !govalidator.IsUUID(id) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+
+	contents, err := d.app.GetArtifactContents(c.Request.Context(), id)
+	switch err {
+	case nil:
+		d.view.RenderSuccessGet(c, contents)
+	case app.ErrImageMetaNotFound:
+		d.view.RenderErrorNotFound(c)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+)
+
+func TestGetArtifactContents(t *testing.T) {
+	t.Parallel()
+
+	const imageID = "f826484e-1157-4109-af21-304e6d711560"
+	contents := &model.ArtifactContents{
+		Payloads: []model.PayloadContents{{
+			Type: "app",
+			Files: []model.PayloadFile{{
+				Name:     "update.tar",
+				Size:     10240,
+				Checksum: "checksum",
+				Entries: []model.PayloadEntry{{
+					Path:     "etc/app.conf",
+					Type:     model.PayloadEntryTypeFile,
+					Size:     3,
+					Mode:     0644,
+					Checksum: "checksum",
+				}},
+			}},
+		}},
+	}
+	testCases := map[string]struct {
+		ID string
+
+		CallApp     bool
+		AppContents *model.ArtifactContents
+		AppError    error
+
+		ResponseCode int
+	}{
+		"ok": {
+			ID:           imageID,
+			CallApp:      true,
+			AppContents:  contents,
+			ResponseCode: http.StatusOK,
+		},
+		"error, id not UUID": {
+			ID:           "foo",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, not found": {
+			ID:           imageID,
+			CallApp:      true,
+			AppError:     app.ErrImageMetaNotFound,
+			ResponseCode: http.StatusNotFound,
+		},
+		"error, internal": {
+			ID:           imageID,
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			if tc.CallApp {
+				mockApp.On("GetArtifactContents", contextMatcher(), tc.ID).
+					Return(tc.AppContents, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.GET(ApiUrlManagementArtifactsIdContents, d.GetArtifactContents)
+
+			uri := strings.Replace(ApiUrlManagementArtifactsIdContents, ":id", tc.ID, 1)
+			req := httptest.NewRequest(http.MethodGet, "http://localhost"+uri, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ResponseCode, w.Code)
+			if tc.ResponseCode == http.StatusOK {
+				var actual model.ArtifactContents
+				if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual)) {
+					assert.Equal(t, *tc.AppContents, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+func (d *DeploymentsApiHandlers) GetArtifactRetentionSettings(c *gin.Context) {
+	settings, err := d.app.GetArtifactRetentionSettings(c.Request.Context())
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	d.view.RenderSuccessGet(c, settings)
+}
+
+func (d *DeploymentsApiHandlers) PutArtifactRetentionSettings(c *gin.Context) {
+	var settings model.ArtifactRetentionSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+	if err := settings.Validate(); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+
+	err := d.app.SetArtifactRetentionSettings(c.Request.Context(), &settings)
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	d.view.RenderSuccessPut(c)
+}
+
+// PreviewArtifactRetention reports the artifacts which the storage daemon
+// would remove according to the current retention policy.
+func (d *DeploymentsApiHandlers) PreviewArtifactRetention(c *gin.Context) {
+	report, err := d.app.ApplyArtifactRetention(c.Request.Context(), true)
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	d.view.RenderSuccessGet(c, report)
+}
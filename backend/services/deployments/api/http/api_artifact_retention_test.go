@@ -0,0 +1,162 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	mt "github.com/mendersoftware/mender-server/pkg/testing"
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+	deployments_testing "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestPutArtifactRetentionSettings(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Body interface{}
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			Body: &model.ArtifactRetentionSettings{
+				KeepLastPerDeviceType: 5,
+				DeleteUnusedAfterDays: 30,
+			},
+			CallApp:      true,
+			ResponseCode: http.StatusNoContent,
+		},
+		"ok, disabled": {
+			Body:         &model.ArtifactRetentionSettings{},
+			CallApp:      true,
+			ResponseCode: http.StatusNoContent,
+		},
+		"error, too many days": {
+			Body: &model.ArtifactRetentionSettings{
+				DeleteUnusedAfterDays: model.MaxArtifactRetentionUnusedDays + 1,
+			},
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(
+				"Validating request body: delete_unused_after_days: " +
+					"must be no greater than 3650."),
+		},
+		"error, internal": {
+			Body: &model.ArtifactRetentionSettings{
+				KeepLastPerRelease: 2,
+			},
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				app.On("SetArtifactRetentionSettings", contextMatcher(), tc.Body).
+					Return(tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.PUT(ApiUrlManagementArtifactsRetentionPolicy,
+				d.PutArtifactRetentionSettings)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodPut,
+				Path:   "http://localhost" + ApiUrlManagementArtifactsRetentionPolicy,
+				Body:   tc.Body,
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+func TestPreviewArtifactRetention(t *testing.T) {
+	t.Parallel()
+
+	report := &model.ArtifactRetentionReport{
+		DryRun: true,
+		Artifacts: []model.ArtifactRetentionCandidate{{
+			ID:                    "f826484e-1157-4109-af21-304e6d711560",
+			Name:                  "release-1",
+			DeviceTypesCompatible: []string{"rpi4"},
+			Size:                  1024,
+			Reason:                model.ArtifactRetentionReasonUnused,
+		}},
+		Size: 1024,
+	}
+	testCases := map[string]struct {
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			ResponseCode: http.StatusOK,
+			ResponseBody: report,
+		},
+		"error, internal": {
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.AppError != nil {
+				app.On("ApplyArtifactRetention", contextMatcher(), true).
+					Return(nil, tc.AppError)
+			} else {
+				app.On("ApplyArtifactRetention", contextMatcher(), true).
+					Return(report, nil)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.GET(ApiUrlManagementArtifactsRetentionPreview,
+				d.PreviewArtifactRetention)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodGet,
+				Path:   "http://localhost" + ApiUrlManagementArtifactsRetentionPreview,
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
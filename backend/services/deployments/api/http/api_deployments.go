@@ -39,6 +39,7 @@ import (
 	"github.com/mendersoftware/mender-server/services/deployments/app"
 	dconfig "github.com/mendersoftware/mender-server/services/deployments/config"
 	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
 	"github.com/mendersoftware/mender-server/services/deployments/store"
 	"github.com/mendersoftware/mender-server/services/deployments/utils"
 )
@@ -72,6 +73,8 @@ const (
 	ParamUpdateType   = "update_type"
 	ParamDeploymentID = "deployment_id"
 	ParamDeviceID     = "device_id"
+	ParamArtifactID   = "artifact_id"
+	ParamAttempt      = "attempt"
 	ParamTenantID     = "tenant_id"
 	ParamName         = "name"
 	ParamTag          = "tag"
@@ -149,6 +152,10 @@ type Config struct {
 	// related to releases; helpful in performing long-running maintenance and data
 	// migrations on the artifacts and releases collections.
 	DisableNewReleasesFeature bool
+
+	// LocalStorage is the object storage serving the signed storage
+	// requests; only set when the artifacts are stored on the local file system.
+	LocalStorage storage.ObjectStorage
 }
 
 func NewConfig() *Config {
@@ -211,6 +218,11 @@ func (conf *Config) SetMaxRequestSize(size int64) *Config {
 	return conf
 }
 
+func (conf *Config) SetLocalStorage(objStore storage.ObjectStorage) *Config {
+	conf.LocalStorage = objStore
+	return conf
+}
+
 type DeploymentsApiHandlers struct {
 	view   RESTView
 	store  store.DataStore
@@ -250,6 +262,9 @@ func NewDeploymentsApiHandlers(
 		if c.MaxRequestSize > 0 {
 			conf.MaxRequestSize = c.MaxRequestSize
 		}
+		if c.LocalStorage != nil {
+			conf.LocalStorage = c.LocalStorage
+		}
 		conf.DisableNewReleasesFeature = c.DisableNewReleasesFeature
 		conf.EnableDirectUpload = c.EnableDirectUpload
 		conf.EnableDirectUploadSkipVerify = c.EnableDirectUploadSkipVerify
@@ -393,9 +408,20 @@ func (d *DeploymentsApiHandlers) GetLimit(c *gin.Context) {
 		return
 	}
 
+	var usage uint64 // TODO fill the storage usage when ready
+	if name == model.LimitDownload {
+		downloads, err := d.app.GetDownloadUsage(c.Request.Context(),
+			model.DownloadUsageFilter{Month: model.DownloadUsageMonth(time.Now())})
+		if err != nil {
+			d.view.RenderInternalError(c, err)
+			return
+		}
+		usage = uint64(downloads.Served)
+	}
+
 	d.view.RenderSuccessGet(c, limitResponse{
 		Limit: limit.Value,
-		Usage: 0, // TODO fill this when ready
+		Usage: usage,
 	})
 }
 
@@ -527,7 +553,10 @@ func (d *DeploymentsApiHandlers) DownloadLink(c *gin.Context) {
 	expireSeconds := config.Config.GetInt(dconfig.SettingsStorageDownloadExpireSeconds)
 	link, err := d.app.DownloadLink(c.Request.Context(), id,
 		time.Duration(expireSeconds)*time.Second)
-	if err != nil {
+	if err == app.ErrDownloadQuotaExceeded {
+		d.view.RenderError(c, err, http.StatusTooManyRequests)
+		return
+	} else if err != nil {
 		d.view.RenderInternalError(c, err)
 		return
 	}
@@ -548,7 +577,10 @@ func (d *DeploymentsApiHandlers) UploadLink(c *gin.Context) {
 		time.Duration(expireSeconds)*time.Second,
 		d.config.EnableDirectUploadSkipVerify,
 	)
-	if err != nil {
+	if errors.Is(err, app.ErrStorageMigrationSwitching) {
+		d.view.RenderError(c, err, http.StatusConflict)
+		return
+	} else if err != nil {
 		d.view.RenderInternalError(c, err)
 		return
 	}
@@ -596,6 +628,8 @@ func (d *DeploymentsApiHandlers) CompleteUpload(c *gin.Context) {
 		c.Status(http.StatusAccepted)
 	case app.ErrUploadNotFound:
 		d.view.RenderErrorNotFound(c)
+	case app.ErrStorageMigrationSwitching:
+		d.view.RenderError(c, err, http.StatusConflict)
 	default:
 		d.view.RenderInternalError(c, err)
 	}
@@ -829,9 +863,13 @@ func (d *DeploymentsApiHandlers) newImageWithContext(
 	default:
 		d.view.RenderInternalError(c, err)
 		return
-	case app.ErrModelArtifactNotUnique:
+	case app.ErrModelArtifactNotUnique,
+		app.ErrArtifactNotSigned, app.ErrArtifactNotVerified:
 		d.view.RenderError(c, cause, http.StatusUnprocessableEntity)
 		return
+	case app.ErrStorageMigrationSwitching:
+		d.view.RenderError(c, cause, http.StatusConflict)
+		return
 	case app.ErrModelParsingArtifactFailed:
 		d.view.RenderError(c, formatArtifactUploadError(err), http.StatusBadRequest)
 		return
@@ -896,8 +934,11 @@ func (d *DeploymentsApiHandlers) GenerateImage(c *gin.Context) {
 		d.view.RenderInternalError(c, err)
 	case nil:
 		d.view.RenderSuccessPost(c, imgID)
-	case app.ErrModelArtifactNotUnique:
+	case app.ErrModelArtifactNotUnique,
+		app.ErrArtifactNotSigned, app.ErrArtifactNotVerified:
 		d.view.RenderError(c, cause, http.StatusUnprocessableEntity)
+	case app.ErrStorageMigrationSwitching:
+		d.view.RenderError(c, cause, http.StatusConflict)
 	case app.ErrModelParsingArtifactFailed:
 		d.view.RenderError(c, formatArtifactUploadError(err), http.StatusBadRequest)
 	case utils.ErrStreamTooLarge, ErrModelArtifactFileTooLarge:
@@ -909,6 +950,37 @@ func (d *DeploymentsApiHandlers) GenerateImage(c *gin.Context) {
 	}
 }
 
+// GenerateDeltaImage starts the generation of a binary delta artifact
+// between two root file system image artifacts.
+func (d *DeploymentsApiHandlers) GenerateDeltaImage(c *gin.Context) {
+	var req model.GenerateDeltaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+
+	imgID, err := d.app.GenerateDeltaImage(c.Request.Context(), &req)
+	switch err {
+	case nil:
+		d.view.RenderSuccessPost(c, imgID)
+	case app.ErrImageMetaNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	case app.ErrDeltaNotRootfsImage, app.ErrDeltaNoCommonDeviceType,
+		app.ErrDeltaSameRootfsImage:
+		d.view.RenderError(c, err, http.StatusUnprocessableEntity)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
 // ParseMultipart parses multipart/form-data message.
 func (d *DeploymentsApiHandlers) ParseMultipart(
 	r *multipart.Reader,
@@ -1113,12 +1185,13 @@ func (d *DeploymentsApiHandlers) createDeployment(
 	}
 
 	id, err := d.app.CreateDeployment(ctx, constructor)
-	switch err {
+	switch errors.Cause(err) {
 	case nil:
 		location := fmt.Sprintf("%s/%s", ApiUrlManagement+ApiUrlManagementDeployments, id)
 		c.Writer.Header().Add("Location", location)
 		c.Status(http.StatusCreated)
-	case app.ErrNoArtifact:
+	case app.ErrNoArtifact, app.ErrPredecessorNotFound,
+		app.ErrReleaseNotFound, app.ErrReleaseIncompatibleDeviceTypes:
 		d.view.RenderError(c, err, http.StatusUnprocessableEntity)
 	case app.ErrNoDevices:
 		d.view.RenderError(c, err, http.StatusBadRequest)
@@ -1145,6 +1218,77 @@ func (d *DeploymentsApiHandlers) DeployToGroup(c *gin.Context) {
 	d.createDeployment(c, ctx, group)
 }
 
+func (d *DeploymentsApiHandlers) previewDeployment(c *gin.Context, group string) {
+	constructor, err := d.getDeploymentConstructorFromBody(c, group)
+	if err != nil {
+		d.view.RenderError(
+			c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest,
+		)
+		return
+	}
+	page, perPage, err := rest.ParsePagingParameters(c.Request)
+	if err != nil {
+		d.view.RenderError(c, err, http.StatusBadRequest)
+		return
+	}
+	query := model.DeploymentPreviewQuery{
+		Page:    page,
+		PerPage: perPage,
+		Status:  model.DevicePreviewStatus(c.Query("status")),
+	}
+	if err = query.Validate(); err != nil {
+		d.view.RenderError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	preview, err := d.app.PreviewDeployment(c.Request.Context(), constructor, query)
+	switch err {
+	case nil:
+	case app.ErrNoArtifact, app.ErrReleaseNotFound:
+		d.view.RenderError(c, err, http.StatusUnprocessableEntity)
+		return
+	default:
+		d.view.RenderInternalError(c, err)
+		return
+	}
+
+	totalCount := preview.Counts.Count(query.Status)
+	hints := rest.NewPagingHints().
+		SetPage(page).
+		SetPerPage(perPage).
+		SetHasNext(totalCount > int(page*perPage)).
+		SetTotalCount(int64(totalCount))
+	links, err := rest.MakePagingHeaders(c.Request, hints)
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	for _, l := range links {
+		c.Writer.Header().Add(hdrLink, l)
+	}
+	c.Writer.Header().Add(hdrTotalCount, strconv.Itoa(totalCount))
+	d.view.RenderSuccessGet(c, preview)
+}
+
+// PreviewDeployment returns the expected outcome of the deployment for
+// each of the targeted devices without creating the deployment.
+func (d *DeploymentsApiHandlers) PreviewDeployment(c *gin.Context) {
+	d.previewDeployment(c, "")
+}
+
+// PreviewGroupDeployment returns the expected outcome of the deployment to
+// the group without creating the deployment.
+func (d *DeploymentsApiHandlers) PreviewGroupDeployment(c *gin.Context) {
+	group := c.Param("name")
+	if len(group) < 1 {
+		d.view.RenderError(c, ErrMissingGroupName, http.StatusBadRequest)
+		return
+	}
+	d.previewDeployment(c, group)
+}
+
 // parseDeviceConfigurationDeploymentPathParams parses expected params
 // and check if the params are not empty
 func parseDeviceConfigurationDeploymentPathParams(c *gin.Context) (string, string, string, error) {
@@ -1881,7 +2025,28 @@ func (d *DeploymentsApiHandlers) GetDeploymentLogForDevice(c *gin.Context) {
 	did := c.Param("id")
 	devid := c.Param("devid")
 
-	depl, err := d.app.GetDeviceDeploymentLog(ctx, devid, did)
+	var (
+		depl *model.DeploymentLog
+		err  error
+	)
+	if qAttempt := c.Query(ParamAttempt); qAttempt != "" {
+		// log of a failed attempt of the device, if retried
+		attempt, parseErr := strconv.Atoi(qAttempt)
+		if parseErr != nil {
+			d.view.RenderError(c,
+				rest.ErrQueryParmInvalid(ParamAttempt, qAttempt),
+				http.StatusBadRequest)
+			return
+		} else if attempt < 1 {
+			d.view.RenderError(c,
+				rest.ErrQueryParmLimit(ParamAttempt),
+				http.StatusBadRequest)
+			return
+		}
+		depl, err = d.app.GetDeviceDeploymentAttemptLog(ctx, devid, did, attempt)
+	} else {
+		depl, err = d.app.GetDeviceDeploymentLog(ctx, devid, did)
+	}
 
 	if err != nil {
 		d.view.RenderInternalError(c, err)
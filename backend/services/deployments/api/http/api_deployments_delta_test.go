@@ -0,0 +1,125 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	mt "github.com/mendersoftware/mender-server/pkg/testing"
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+	deployments_testing "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestGenerateDeltaImage(t *testing.T) {
+	t.Parallel()
+
+	const (
+		sourceID   = "d1b1c7e4-5a6b-4c1d-8e0f-2a3b4c5d6e7f"
+		targetID   = "0e6b1d6c-7f4a-4b2e-9c3d-1a2b3c4d5e6f"
+		artifactID = "f826484e-1157-4109-af21-304e6d711561"
+	)
+	request := &model.GenerateDeltaRequest{
+		SourceID: sourceID,
+		TargetID: targetID,
+	}
+
+	testCases := map[string]struct {
+		Body interface{}
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode     int
+		ResponseLocation string
+		ResponseBody     interface{}
+	}{
+		"ok": {
+			Body:             request,
+			CallApp:          true,
+			ResponseCode:     http.StatusCreated,
+			ResponseLocation: ApiUrlManagementArtifactsGenerateDelta + "/" + artifactID,
+		},
+		"error, same artifact": {
+			Body: &model.GenerateDeltaRequest{
+				SourceID: sourceID,
+				TargetID: sourceID,
+			},
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(
+				"Validating request body: " + model.ErrDeltaSameArtifact.Error()),
+		},
+		"error, not found": {
+			Body:         request,
+			CallApp:      true,
+			AppError:     app.ErrImageMetaNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: deployments_testing.RestError(app.ErrImageMetaNotFound.Error()),
+		},
+		"error, not a rootfs image": {
+			Body:         request,
+			CallApp:      true,
+			AppError:     app.ErrDeltaNotRootfsImage,
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: deployments_testing.RestError(app.ErrDeltaNotRootfsImage.Error()),
+		},
+		"error, internal": {
+			Body:         request,
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				var id string
+				if tc.AppError == nil {
+					id = artifactID
+				}
+				app.On("GenerateDeltaImage", contextMatcher(), tc.Body).
+					Return(id, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.POST(ApiUrlManagementArtifactsGenerateDelta, d.GenerateDeltaImage)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodPost,
+				Path:   "http://localhost" + ApiUrlManagementArtifactsGenerateDelta,
+				Body:   tc.Body,
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode,
+				map[string]string{
+					"Location": tc.ResponseLocation,
+				}, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/rest.utils"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+const (
+	ParamExportFormat = "format"
+	ParamGroup        = "group"
+)
+
+// ExportDeviceDeploymentsHistory streams the deployment history of the
+// devices matching the query as CSV or newline delimited JSON.
+func (d *DeploymentsApiHandlers) ExportDeviceDeploymentsHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	query := c.Request.URL.Query()
+
+	format := query.Get(ParamExportFormat)
+	switch format {
+	case "":
+		format = model.DeviceDeploymentHistoryFormatNDJSON
+	case model.DeviceDeploymentHistoryFormatCSV,
+		model.DeviceDeploymentHistoryFormatNDJSON:
+	default:
+		d.view.RenderError(c,
+			rest.ErrQueryParmInvalid(ParamExportFormat, format),
+			http.StatusBadRequest)
+		return
+	}
+	filter := model.DeviceDeploymentHistoryFilter{
+		DeviceIDs: query[ParamDeviceID],
+		Group:     query.Get(ParamGroup),
+	}
+	if createdAfter := query.Get("created_after"); createdAfter != "" {
+		t, err := parseEpochToTimestamp(createdAfter)
+		if err != nil {
+			d.view.RenderError(c, errors.Wrap(err,
+				"timestamp parsing failed for created_after parameter"),
+				http.StatusBadRequest)
+			return
+		}
+		filter.CreatedAfter = &t
+	}
+	if createdBefore := query.Get("created_before"); createdBefore != "" {
+		t, err := parseEpochToTimestamp(createdBefore)
+		if err != nil {
+			d.view.RenderError(c, errors.Wrap(err,
+				"timestamp parsing failed for created_before parameter"),
+				http.StatusBadRequest)
+			return
+		}
+		filter.CreatedBefore = &t
+	}
+	if err := filter.Validate(); err != nil {
+		d.view.RenderError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	it, err := d.app.ExportDeviceDeploymentsHistory(ctx, filter)
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	defer it.Close(ctx)
+
+	hdr := c.Writer.Header()
+	var writeRecord func(model.DeviceDeploymentHistoryRecord) error
+	if format == model.DeviceDeploymentHistoryFormatCSV {
+		hdr.Set("Content-Type", "text/csv")
+		hdr.Set("Content-Disposition",
+			`attachment; filename="deployments_history.csv"`)
+		w := csv.NewWriter(c.Writer)
+		defer w.Flush()
+		writeRecord = func(record model.DeviceDeploymentHistoryRecord) error {
+			return w.Write(record.CSVRecord())
+		}
+		c.Status(http.StatusOK)
+		err = w.Write(model.DeviceDeploymentHistoryCSVHeader)
+	} else {
+		hdr.Set("Content-Type", "application/x-ndjson")
+		hdr.Set("Content-Disposition",
+			`attachment; filename="deployments_history.ndjson"`)
+		enc := json.NewEncoder(c.Writer)
+		writeRecord = func(record model.DeviceDeploymentHistoryRecord) error {
+			return enc.Encode(record)
+		}
+		c.Status(http.StatusOK)
+	}
+	for err == nil {
+		var next bool
+		next, err = it.Next(ctx)
+		if !next {
+			break
+		}
+		var record model.DeviceDeploymentHistoryRecord
+		if err = it.Decode(&record); err == nil {
+			err = writeRecord(record)
+		}
+	}
+	if err != nil {
+		// The response is already partially written, the export is
+		// truncated.
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	mt "github.com/mendersoftware/mender-server/pkg/testing"
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+	deployments_testing "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+type historyIterator struct {
+	records []model.DeviceDeploymentHistoryRecord
+	idx     int
+	err     error
+}
+
+func (it *historyIterator) Next(ctx context.Context) (bool, error) {
+	if it.idx >= len(it.records) {
+		return false, it.err
+	}
+	it.idx++
+	return true, nil
+}
+
+func (it *historyIterator) Decode(record *model.DeviceDeploymentHistoryRecord) error {
+	*record = it.records[it.idx-1]
+	return nil
+}
+
+func (it *historyIterator) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestExportDeviceDeploymentsHistory(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	finished := created.Add(time.Hour)
+	records := []model.DeviceDeploymentHistoryRecord{{
+		DeviceID:       "device-1",
+		DeploymentID:   "f826484e-1157-4109-af21-304e6d711560",
+		DeploymentName: "Q1 update",
+		ArtifactName:   "release-1",
+		Status:         model.DeviceDeploymentStatusSuccess,
+		Created:        &created,
+		Finished:       &finished,
+	}, {
+		DeviceID:       "device-2",
+		DeploymentID:   "f826484e-1157-4109-af21-304e6d711560",
+		DeploymentName: "Q1 update",
+		ArtifactName:   "release-1",
+		Status:         model.DeviceDeploymentStatusFailure,
+		SubState:       "ArtifactInstall",
+		Created:        &created,
+	}}
+	after := time.Unix(1704067200, 0).UTC()
+	testCases := map[string]struct {
+		Query string
+
+		Filter    *model.DeviceDeploymentHistoryFilter
+		Iterator  *historyIterator
+		AppError  error
+		ErrorBody interface{}
+
+		ResponseCode int
+		ContentType  string
+		ResponseBody string
+	}{
+		"ok, ndjson": {
+			Query: "?device_id=device-1&device_id=device-2&created_after=1704067200",
+			Filter: &model.DeviceDeploymentHistoryFilter{
+				DeviceIDs:    []string{"device-1", "device-2"},
+				CreatedAfter: &after,
+			},
+			Iterator:     &historyIterator{records: records},
+			ResponseCode: http.StatusOK,
+			ContentType:  "application/x-ndjson",
+			ResponseBody: `{"device_id":"device-1",` +
+				`"deployment_id":"f826484e-1157-4109-af21-304e6d711560",` +
+				`"deployment_name":"Q1 update","artifact_name":"release-1",` +
+				`"status":"success","created":"2024-01-01T10:00:00Z",` +
+				`"finished":"2024-01-01T11:00:00Z"}` + "\n" +
+				`{"device_id":"device-2",` +
+				`"deployment_id":"f826484e-1157-4109-af21-304e6d711560",` +
+				`"deployment_name":"Q1 update","artifact_name":"release-1",` +
+				`"status":"failure","substate":"ArtifactInstall",` +
+				`"created":"2024-01-01T10:00:00Z"}` + "\n",
+		},
+		"ok, csv": {
+			Query: "?format=csv&group=regulated",
+			Filter: &model.DeviceDeploymentHistoryFilter{
+				Group: "regulated",
+			},
+			Iterator:     &historyIterator{records: records},
+			ResponseCode: http.StatusOK,
+			ContentType:  "text/csv",
+			ResponseBody: "device_id,deployment_id,deployment_name,artifact_name," +
+				"status,substate,created,started,finished\n" +
+				"device-1,f826484e-1157-4109-af21-304e6d711560,Q1 update," +
+				"release-1,success,,2024-01-01T10:00:00Z,,2024-01-01T11:00:00Z\n" +
+				"device-2,f826484e-1157-4109-af21-304e6d711560,Q1 update," +
+				"release-1,failure,ArtifactInstall,2024-01-01T10:00:00Z,,\n",
+		},
+		"ok, truncated": {
+			Query:        "?format=csv",
+			Filter:       &model.DeviceDeploymentHistoryFilter{},
+			Iterator:     &historyIterator{err: errors.New("cursor killed")},
+			ResponseCode: http.StatusOK,
+			ContentType:  "text/csv",
+			ResponseBody: "device_id,deployment_id,deployment_name,artifact_name," +
+				"status,substate,created,started,finished\n",
+		},
+		"error, invalid format": {
+			Query:        "?format=xml",
+			ResponseCode: http.StatusBadRequest,
+			ErrorBody: deployments_testing.RestError(
+				"invalid format query: \"xml\""),
+		},
+		"error, invalid timestamp": {
+			Query:        "?created_before=yesterday",
+			ResponseCode: http.StatusBadRequest,
+			ErrorBody: deployments_testing.RestError(
+				"timestamp parsing failed for created_before parameter: " +
+					"invalid timestamp: yesterday"),
+		},
+		"error, devices and group": {
+			Query:        "?device_id=device-1&group=regulated",
+			ResponseCode: http.StatusBadRequest,
+			ErrorBody: deployments_testing.RestError(
+				model.ErrDeviceDeploymentHistoryDevices.Error()),
+		},
+		"error, internal": {
+			Filter:       &model.DeviceDeploymentHistoryFilter{},
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ErrorBody:    deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.Filter != nil {
+				if tc.AppError != nil {
+					app.On("ExportDeviceDeploymentsHistory", contextMatcher(), *tc.Filter).
+						Return(nil, tc.AppError)
+				} else {
+					app.On("ExportDeviceDeploymentsHistory", contextMatcher(), *tc.Filter).
+						Return(tc.Iterator, nil)
+				}
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.GET(ApiUrlManagementDeploymentsHistoryExport,
+				d.ExportDeviceDeploymentsHistory)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodGet,
+				Path: "http://localhost" +
+					ApiUrlManagementDeploymentsHistoryExport + tc.Query,
+			})
+			recorded := restutil.RunRequest(t, router, req)
+			if tc.ErrorBody != nil {
+				checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ErrorBody)
+				mt.CheckHTTPResponse(t, checker, recorded)
+				return
+			}
+			assert.Equal(t, tc.ResponseCode, recorded.Recorder.Code)
+			assert.Equal(t, tc.ContentType,
+				recorded.Recorder.Header().Get("Content-Type"))
+			assert.Equal(t, tc.ResponseBody, recorded.Recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mendersoftware/mender-server/pkg/rest.utils"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+const (
+	ParamLogSearchQuery = "q"
+	ParamLogSearchRegex = "regex"
+)
+
+// SearchDeploymentLogs finds the device deployment logs of the deployment
+// with messages containing the string, or matching the regular expression,
+// given in the query.
+func (d *DeploymentsApiHandlers) SearchDeploymentLogs(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	page, perPage, err := rest.ParsePagingParameters(c.Request)
+	if err != nil {
+		d.view.RenderError(c, err, http.StatusBadRequest)
+		return
+	}
+	var regex bool
+	if q := c.Query(ParamLogSearchRegex); q != "" {
+		regex, err = strconv.ParseBool(q)
+		if err != nil {
+			d.view.RenderError(c,
+				rest.ErrQueryParmInvalid(ParamLogSearchRegex, q),
+				http.StatusBadRequest)
+			return
+		}
+	}
+	search := model.DeploymentLogSearch{
+		DeploymentID: c.Param("id"),
+		Query:        c.Query(ParamLogSearchQuery),
+		Regex:        regex,
+		Page:         int(page),
+		PerPage:      int(perPage),
+	}
+	if err := search.Validate(); err != nil {
+		d.view.RenderError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	matches, count, err := d.app.SearchDeviceDeploymentLogs(ctx, search)
+	switch err {
+	case nil:
+	case app.ErrModelDeploymentNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+		return
+	default:
+		d.view.RenderInternalError(c, err)
+		return
+	}
+
+	hints := rest.NewPagingHints().
+		SetPage(page).
+		SetPerPage(perPage).
+		SetHasNext(count > int(page*perPage)).
+		SetTotalCount(int64(count))
+	links, err := rest.MakePagingHeaders(c.Request, hints)
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	for _, l := range links {
+		c.Writer.Header().Add(hdrLink, l)
+	}
+	c.Writer.Header().Add(hdrTotalCount, strconv.Itoa(count))
+	d.view.RenderSuccessGet(c, matches)
+}
@@ -0,0 +1,177 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+)
+
+func TestSearchDeploymentLogs(t *testing.T) {
+	t.Parallel()
+
+	const deploymentID = "f826484e-1157-4109-af21-304e6d711560"
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	matches := []model.DeploymentLogMatch{{
+		DeviceID: "device",
+		Messages: []model.LogMessage{{
+			Timestamp: &timestamp,
+			Level:     "error",
+			Message:   "failed to install",
+		}},
+	}}
+	testCases := map[string]struct {
+		ID    string
+		Query string
+
+		CallApp    bool
+		Search     model.DeploymentLogSearch
+		AppMatches []model.DeploymentLogMatch
+		AppCount   int
+		AppError   error
+
+		ResponseCode int
+		TotalCount   string
+		HasNext      bool
+	}{
+		"ok": {
+			ID:      deploymentID,
+			Query:   "q=failed",
+			CallApp: true,
+			Search: model.DeploymentLogSearch{
+				DeploymentID: deploymentID,
+				Query:        "failed",
+				Page:         1,
+				PerPage:      20,
+			},
+			AppMatches:   matches,
+			AppCount:     1,
+			ResponseCode: http.StatusOK,
+			TotalCount:   "1",
+		},
+		"ok, regex with next page": {
+			ID:      deploymentID,
+			Query:   "q=fail.*&regex=true&page=1&per_page=1",
+			CallApp: true,
+			Search: model.DeploymentLogSearch{
+				DeploymentID: deploymentID,
+				Query:        "fail.*",
+				Regex:        true,
+				Page:         1,
+				PerPage:      1,
+			},
+			AppMatches:   matches,
+			AppCount:     2,
+			ResponseCode: http.StatusOK,
+			TotalCount:   "2",
+			HasNext:      true,
+		},
+		"error, id not UUID": {
+			ID:           "foo",
+			Query:        "q=failed",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, missing query": {
+			ID:           deploymentID,
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, invalid regex flag": {
+			ID:           deploymentID,
+			Query:        "q=failed&regex=maybe",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, invalid regular expression": {
+			ID:           deploymentID,
+			Query:        "q=fail(&regex=true",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, invalid paging": {
+			ID:           deploymentID,
+			Query:        "q=failed&page=0",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, deployment not found": {
+			ID:      deploymentID,
+			Query:   "q=failed",
+			CallApp: true,
+			Search: model.DeploymentLogSearch{
+				DeploymentID: deploymentID,
+				Query:        "failed",
+				Page:         1,
+				PerPage:      20,
+			},
+			AppError:     app.ErrModelDeploymentNotFound,
+			ResponseCode: http.StatusNotFound,
+		},
+		"error, internal": {
+			ID:      deploymentID,
+			Query:   "q=failed",
+			CallApp: true,
+			Search: model.DeploymentLogSearch{
+				DeploymentID: deploymentID,
+				Query:        "failed",
+				Page:         1,
+				PerPage:      20,
+			},
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			if tc.CallApp {
+				mockApp.On("SearchDeviceDeploymentLogs", contextMatcher(), tc.Search).
+					Return(tc.AppMatches, tc.AppCount, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.GET(ApiUrlManagementDeploymentsLogSearch, d.SearchDeploymentLogs)
+
+			uri := strings.Replace(ApiUrlManagementDeploymentsLogSearch, ":id", tc.ID, 1)
+			req := httptest.NewRequest(http.MethodGet,
+				"http://localhost"+uri+"?"+tc.Query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ResponseCode, w.Code)
+			if tc.ResponseCode == http.StatusOK {
+				assert.Equal(t, tc.TotalCount, w.Header().Get(hdrTotalCount))
+				links := strings.Join(w.Header().Values(hdrLink), ",")
+				assert.Equal(t, tc.HasNext, strings.Contains(links, `rel="next"`))
+				var actual []model.DeploymentLogMatch
+				if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual)) {
+					assert.Equal(t, tc.AppMatches, actual)
+				}
+			}
+		})
+	}
+}
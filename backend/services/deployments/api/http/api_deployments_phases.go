@@ -0,0 +1,79 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+)
+
+const (
+	ParamPhaseID = "phase_id"
+)
+
+func (d *DeploymentsApiHandlers) GetDeploymentPhases(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := c.Param("id")
+	if !govalidator.IsUUID(id) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+
+	phases, err := d.app.GetDeploymentPhases(ctx, id)
+	switch err {
+	case nil:
+		d.view.RenderSuccessGet(c, phases)
+	case app.ErrModelDeploymentNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+func (d *DeploymentsApiHandlers) ResumeDeploymentPhase(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := c.Param("id")
+	if !govalidator.IsUUID(id) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+	phaseID := c.Param(ParamPhaseID)
+	if !govalidator.IsUUID(phaseID) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+
+	log.FromContext(ctx).Infof("Resume deployment %s phase: %s", id, phaseID)
+
+	err := d.app.ResumeDeploymentPhase(ctx, id, phaseID)
+	switch err {
+	case nil:
+		d.view.RenderEmptySuccessResponse(c)
+	case app.ErrModelDeploymentNotFound, app.ErrPhaseNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	case app.ErrPhaseNotResumable:
+		d.view.RenderError(c, err, http.StatusConflict)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
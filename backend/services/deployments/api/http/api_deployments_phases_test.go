@@ -0,0 +1,196 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	mt "github.com/mendersoftware/mender-server/pkg/testing"
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+	deployments_testing "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestGetDeploymentPhases(t *testing.T) {
+	t.Parallel()
+
+	const deploymentID = "f826484e-1157-4109-af21-304e6d711561"
+
+	testCases := map[string]struct {
+		DeploymentID string
+
+		CallApp  bool
+		Phases   []model.DeploymentPhaseInfo
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			DeploymentID: deploymentID,
+			CallApp:      true,
+			Phases: []model.DeploymentPhaseInfo{{
+				DeploymentPhase: model.DeploymentPhase{Id: "1", BatchSize: 10},
+				Status:          model.DeploymentPhaseStatusActive,
+				DeviceLimit:     1,
+			}},
+			ResponseCode: http.StatusOK,
+		},
+		"error, invalid id": {
+			DeploymentID: "foo",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(ErrIDNotUUID.Error()),
+		},
+		"error, not found": {
+			DeploymentID: deploymentID,
+			CallApp:      true,
+			AppError:     app.ErrModelDeploymentNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: deployments_testing.RestError(
+				app.ErrModelDeploymentNotFound.Error()),
+		},
+		"error, internal": {
+			DeploymentID: deploymentID,
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				app.On("GetDeploymentPhases",
+					contextMatcher(), tc.DeploymentID).
+					Return(tc.Phases, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.GET(ApiUrlManagementDeploymentsPhases, d.GetDeploymentPhases)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodGet,
+				Path: "http://localhost" + strings.Replace(
+					ApiUrlManagementDeploymentsPhases, ":id", tc.DeploymentID, 1),
+			})
+			body := tc.ResponseBody
+			if body == nil {
+				body = tc.Phases
+			}
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, body)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+func TestResumeDeploymentPhase(t *testing.T) {
+	t.Parallel()
+
+	const (
+		deploymentID = "f826484e-1157-4109-af21-304e6d711561"
+		phaseID      = "0a7bd6bd-7ab1-4d8a-b8a8-2d4b4b34c8a7"
+	)
+
+	testCases := map[string]struct {
+		DeploymentID string
+		PhaseID      string
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			DeploymentID: deploymentID,
+			PhaseID:      phaseID,
+			CallApp:      true,
+			ResponseCode: http.StatusNoContent,
+		},
+		"error, invalid phase id": {
+			DeploymentID: deploymentID,
+			PhaseID:      "foo",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(ErrIDNotUUID.Error()),
+		},
+		"error, phase not found": {
+			DeploymentID: deploymentID,
+			PhaseID:      phaseID,
+			CallApp:      true,
+			AppError:     app.ErrPhaseNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: deployments_testing.RestError(app.ErrPhaseNotFound.Error()),
+		},
+		"error, not resumable": {
+			DeploymentID: deploymentID,
+			PhaseID:      phaseID,
+			CallApp:      true,
+			AppError:     app.ErrPhaseNotResumable,
+			ResponseCode: http.StatusConflict,
+			ResponseBody: deployments_testing.RestError(app.ErrPhaseNotResumable.Error()),
+		},
+		"error, internal": {
+			DeploymentID: deploymentID,
+			PhaseID:      phaseID,
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				app.On("ResumeDeploymentPhase",
+					contextMatcher(), tc.DeploymentID, tc.PhaseID).
+					Return(tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.POST(ApiUrlManagementDeploymentsPhaseResume, d.ResumeDeploymentPhase)
+
+			path := strings.NewReplacer(
+				":id", tc.DeploymentID,
+				":phase_id", tc.PhaseID,
+			).Replace(ApiUrlManagementDeploymentsPhaseResume)
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodPost,
+				Path:   "http://localhost" + path,
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
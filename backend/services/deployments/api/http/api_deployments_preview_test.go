@@ -0,0 +1,203 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+)
+
+func TestPreviewDeployment(t *testing.T) {
+	t.Parallel()
+
+	preview := &model.DeploymentPreview{
+		Counts: model.DeploymentPreviewCounts{
+			Total:        3,
+			WillInstall:  2,
+			Incompatible: 1,
+		},
+		Devices: []model.DevicePreview{{
+			ID:           "1",
+			Status:       model.DevicePreviewStatusWillInstall,
+			DeviceType:   "rpi4",
+			ArtifactName: "release-1",
+			ArtifactID:   "f826484e-1157-4109-af21-304e6d711560",
+		}},
+	}
+	testCases := map[string]struct {
+		Group string
+		Query string
+		Body  interface{}
+
+		Constructor *model.DeploymentConstructor
+		AppQuery    model.DeploymentPreviewQuery
+		AppError    error
+
+		ResponseCode       int
+		ResponseTotalCount string
+		ResponseLink       string
+	}{
+		"ok": {
+			Query: "?per_page=1&status=will-install",
+			Body: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				Devices:      []string{"1", "2", "3"},
+			},
+			Constructor: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				Devices:      []string{"1", "2", "3"},
+			},
+			AppQuery: model.DeploymentPreviewQuery{
+				Page:    1,
+				PerPage: 1,
+				Status:  model.DevicePreviewStatusWillInstall,
+			},
+			ResponseCode:       http.StatusOK,
+			ResponseTotalCount: "2",
+			ResponseLink:       `rel="next"`,
+		},
+		"ok, group": {
+			Group: "production",
+			Body: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+			},
+			Constructor: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				Group:        "production",
+			},
+			AppQuery: model.DeploymentPreviewQuery{
+				Page:    1,
+				PerPage: 20,
+			},
+			ResponseCode:       http.StatusOK,
+			ResponseTotalCount: "3",
+			ResponseLink:       `rel="first"`,
+		},
+		"error, empty body": {
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, invalid status": {
+			Query: "?status=installed",
+			Body: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				AllDevices:   true,
+			},
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, no artifact": {
+			Body: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				AllDevices:   true,
+			},
+			Constructor: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				AllDevices:   true,
+			},
+			AppQuery: model.DeploymentPreviewQuery{
+				Page:    1,
+				PerPage: 20,
+			},
+			AppError:     app.ErrNoArtifact,
+			ResponseCode: http.StatusUnprocessableEntity,
+		},
+		"error, internal": {
+			Body: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				AllDevices:   true,
+			},
+			Constructor: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				AllDevices:   true,
+			},
+			AppQuery: model.DeploymentPreviewQuery{
+				Page:    1,
+				PerPage: 20,
+			},
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			if tc.Constructor != nil {
+				if tc.AppError != nil {
+					mockApp.On("PreviewDeployment",
+						contextMatcher(), tc.Constructor, tc.AppQuery,
+					).Return(nil, tc.AppError)
+				} else {
+					mockApp.On("PreviewDeployment",
+						contextMatcher(), tc.Constructor, tc.AppQuery,
+					).Return(preview, nil)
+				}
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.POST(ApiUrlManagementDeploymentsPreview, d.PreviewDeployment)
+			router.POST(ApiUrlManagementDeploymentsGroupPreview, d.PreviewGroupDeployment)
+
+			uri := ApiUrlManagementDeploymentsPreview
+			if tc.Group != "" {
+				uri = strings.Replace(ApiUrlManagementDeploymentsGroupPreview,
+					":name", tc.Group, 1)
+			}
+			var body []byte
+			if tc.Body != nil {
+				body, _ = json.Marshal(tc.Body)
+			}
+			req := httptest.NewRequest(http.MethodPost,
+				"http://localhost"+uri+tc.Query, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ResponseCode, w.Code)
+			if tc.ResponseCode == http.StatusOK {
+				assert.Equal(t, tc.ResponseTotalCount, w.Header().Get(hdrTotalCount))
+				assert.Contains(t, strings.Join(w.Header().Values(hdrLink), ", "),
+					tc.ResponseLink)
+				var actual model.DeploymentPreview
+				if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual)) {
+					assert.Equal(t, *preview, actual)
+				}
+			}
+		})
+	}
+}
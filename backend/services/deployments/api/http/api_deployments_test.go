@@ -290,6 +290,22 @@ func TestUploadLink(t *testing.T) {
 		BodyAssertionFunc: func(t *testing.T, body string) bool {
 			return true
 		},
+	}, {
+		Name: "error/storage switching",
+
+		App: func(t *testing.T) *mapp.App {
+			appMock := new(mapp.App)
+			appMock.On("UploadLink", contextMatcher(),
+				mock.AnythingOfType("time.Duration"), false).
+				Return(nil, app.ErrStorageMigrationSwitching)
+
+			return appMock
+		},
+
+		StatusCode: http.StatusConflict,
+		BodyAssertionFunc: func(t *testing.T, body string) bool {
+			return assert.Contains(t, body, app.ErrStorageMigrationSwitching.Error())
+		},
 	}, {
 		Name: "error/not found",
 
@@ -517,6 +533,87 @@ func TestPostDeployment(t *testing.T) {
 			Err:       app.ErrConflictingDeployment.Error(),
 			RequestID: "test",
 		},
+	}, {
+		Name: "ok, release",
+		InputBody: &model.DeploymentConstructor{
+			Name:       "foo",
+			Release:    &model.DeploymentRelease{Name: "bar", Tag: "stable"},
+			AllDevices: true,
+		},
+		ResponseCode:           http.StatusCreated,
+		ResponseLocationHeader: "/api/management/v1/deployments/deployments/foo",
+	}, {
+		Name: "error: app error: release not found",
+		InputBody: &model.DeploymentConstructor{
+			Name:       "foo",
+			Release:    &model.DeploymentRelease{Tag: "stable"},
+			AllDevices: true,
+		},
+		AppError:     app.ErrReleaseNotFound,
+		ResponseCode: http.StatusUnprocessableEntity,
+		ResponseBody: rest.Error{
+			Err:       app.ErrReleaseNotFound.Error(),
+			RequestID: "test",
+		},
+	}, {
+		Name: "error: app error: release incompatible",
+		InputBody: &model.DeploymentConstructor{
+			Name:       "foo",
+			Release:    &model.DeploymentRelease{Name: "bar"},
+			AllDevices: true,
+		},
+		AppError: errors.Wrap(app.ErrReleaseIncompatibleDeviceTypes,
+			"device types qemu"),
+		ResponseCode: http.StatusUnprocessableEntity,
+		ResponseBody: rest.Error{
+			Err: "device types qemu: " +
+				app.ErrReleaseIncompatibleDeviceTypes.Error(),
+			RequestID: "test",
+		},
+	}, {
+		Name: "ok, continuous",
+		InputBody: &model.DeploymentConstructor{
+			Name:    "foo",
+			Release: &model.DeploymentRelease{Tag: "stable"},
+			DeviceFilter: []model.FilterPredicate{{
+				Scope:     "inventory",
+				Attribute: "device_type",
+				Type:      "$eq",
+				Value:     "rpi4",
+			}},
+			Continuous:     true,
+			AllowDowngrade: true,
+		},
+		ResponseCode:           http.StatusCreated,
+		ResponseLocationHeader: "/api/management/v1/deployments/deployments/foo",
+	}, {
+		Name: "error: continuous with devices",
+		InputBody: &model.DeploymentConstructor{
+			Name:       "foo",
+			Release:    &model.DeploymentRelease{Tag: "stable"},
+			AllDevices: true,
+			Continuous: true,
+		},
+		ResponseCode: http.StatusBadRequest,
+		ResponseBody: rest.Error{
+			Err: "Validating request body: " +
+				model.ErrInvalidContinuousDeploymentDevices.Error(),
+			RequestID: "test",
+		},
+	}, {
+		Name: "error: release and artifact name",
+		InputBody: &model.DeploymentConstructor{
+			Name:         "foo",
+			ArtifactName: "bar",
+			Release:      &model.DeploymentRelease{Name: "bar"},
+			AllDevices:   true,
+		},
+		ResponseCode: http.StatusBadRequest,
+		ResponseBody: rest.Error{
+			Err: "Validating request body: " +
+				model.ErrInvalidDeploymentReleaseConflict.Error(),
+			RequestID: "test",
+		},
 	}, {
 		Name: "error: conflict",
 		InputBody: &model.DeploymentConstructor{
@@ -2706,3 +2803,92 @@ func TestNewConfig(t *testing.T) {
 	conf.SetDisableNewReleasesFeature(true)
 	assert.True(t, conf.DisableNewReleasesFeature)
 }
+
+func TestGetDeploymentLogForDevice(t *testing.T) {
+	t.Parallel()
+
+	const (
+		deploymentID = "f826484e-1157-4109-af21-304e6d711561"
+		deviceID     = "device"
+	)
+	logTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deploymentLog := &model.DeploymentLog{
+		DeviceID:     deviceID,
+		DeploymentID: deploymentID,
+		Messages: []model.LogMessage{{
+			Timestamp: &logTime,
+			Level:     "error",
+			Message:   "connection timed out",
+		}},
+	}
+
+	testCases := map[string]struct {
+		Query string
+
+		CallApp     string
+		AppAttempts []interface{}
+		AppLog      *model.DeploymentLog
+
+		ResponseCode int
+		ResponseBody string
+	}{
+		"ok": {
+			CallApp:      "GetDeviceDeploymentLog",
+			AppLog:       deploymentLog,
+			ResponseCode: http.StatusOK,
+			ResponseBody: deploymentLog.Messages[0].String() + "\n",
+		},
+		"ok, failed attempt": {
+			Query:        "?attempt=2",
+			CallApp:      "GetDeviceDeploymentAttemptLog",
+			AppAttempts:  []interface{}{2},
+			AppLog:       deploymentLog,
+			ResponseCode: http.StatusOK,
+			ResponseBody: deploymentLog.Messages[0].String() + "\n",
+		},
+		"error, attempt not found": {
+			Query:        "?attempt=3",
+			CallApp:      "GetDeviceDeploymentAttemptLog",
+			AppAttempts:  []interface{}{3},
+			ResponseCode: http.StatusNotFound,
+		},
+		"error, invalid attempt": {
+			Query:        "?attempt=first",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, attempt out of range": {
+			Query:        "?attempt=0",
+			ResponseCode: http.StatusBadRequest,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			appMock := &mapp.App{}
+			defer appMock.AssertExpectations(t)
+			if tc.CallApp != "" {
+				args := append([]interface{}{contextMatcher(), deviceID, deploymentID},
+					tc.AppAttempts...)
+				appMock.On(tc.CallApp, args...).Return(tc.AppLog, nil).Once()
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), appMock)
+			router := setUpTestRouter()
+			router.GET(ApiUrlManagementDeploymentsLog, d.GetDeploymentLogForDevice)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodGet,
+				Path: "http://localhost" + strings.NewReplacer(
+					":id", deploymentID,
+					":devid", deviceID,
+				).Replace(ApiUrlManagementDeploymentsLog) + tc.Query,
+			})
+			recorded := restutil.RunRequest(t, router, req)
+			assert.Equal(t, tc.ResponseCode, recorded.Recorder.Code)
+			if tc.ResponseBody != "" {
+				assert.Equal(t, tc.ResponseBody, recorded.Recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+func (d *DeploymentsApiHandlers) ContinueDeployment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := c.Param("id")
+	if !govalidator.IsUUID(id) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+
+	// the request body is optional: all the paused devices are continued
+	// if the devices are not listed
+	var req model.ContinueDeviceDeployments
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			d.view.RenderError(c, err, http.StatusBadRequest)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			d.view.RenderError(c, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	log.FromContext(ctx).Infof("Continue deployment %s", id)
+
+	count, err := d.app.ContinueDeployment(ctx, id, req.Devices)
+	switch err {
+	case nil:
+		d.view.RenderSuccessGet(c, model.ContinuedDeviceDeployments{Count: count})
+	case app.ErrModelDeploymentNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+func (d *DeploymentsApiHandlers) ContinueDeviceDeployment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := c.Param("id")
+	if !govalidator.IsUUID(id) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+	deviceID := c.Param("devid")
+
+	log.FromContext(ctx).Infof("Continue deployment %s for device: %s", id, deviceID)
+
+	err := d.app.ContinueDeviceDeployment(ctx, id, deviceID)
+	switch err {
+	case nil:
+		d.view.RenderEmptySuccessResponse(c)
+	case app.ErrStorageNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	case app.ErrDeviceDeploymentNotPaused:
+		d.view.RenderError(c, err, http.StatusConflict)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
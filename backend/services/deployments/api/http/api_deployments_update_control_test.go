@@ -0,0 +1,195 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+)
+
+func TestContinueDeployment(t *testing.T) {
+	t.Parallel()
+
+	const deploymentID = "f826484e-1157-4109-af21-304e6d711560"
+	testCases := map[string]struct {
+		ID   string
+		Body interface{}
+
+		CallApp  bool
+		Devices  []string
+		AppCount int
+		AppError error
+
+		ResponseCode int
+	}{
+		"ok, all devices": {
+			ID:           deploymentID,
+			CallApp:      true,
+			AppCount:     3,
+			ResponseCode: http.StatusOK,
+		},
+		"ok, selected devices": {
+			ID: deploymentID,
+			Body: model.ContinueDeviceDeployments{
+				Devices: []string{"1", "2"},
+			},
+			CallApp:      true,
+			Devices:      []string{"1", "2"},
+			AppCount:     2,
+			ResponseCode: http.StatusOK,
+		},
+		"error, id not UUID": {
+			ID:           "foo",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, empty device ID": {
+			ID: deploymentID,
+			Body: model.ContinueDeviceDeployments{
+				Devices: []string{""},
+			},
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, invalid body": {
+			ID:           deploymentID,
+			Body:         "devices",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, deployment not found": {
+			ID:           deploymentID,
+			CallApp:      true,
+			AppError:     app.ErrModelDeploymentNotFound,
+			ResponseCode: http.StatusNotFound,
+		},
+		"error, internal": {
+			ID:           deploymentID,
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			if tc.CallApp {
+				mockApp.On("ContinueDeployment", contextMatcher(), tc.ID, tc.Devices).
+					Return(tc.AppCount, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.POST(ApiUrlManagementDeploymentsContinue, d.ContinueDeployment)
+
+			var body []byte
+			if tc.Body != nil {
+				body, _ = json.Marshal(tc.Body)
+			}
+			uri := strings.Replace(ApiUrlManagementDeploymentsContinue, ":id", tc.ID, 1)
+			req := httptest.NewRequest(http.MethodPost,
+				"http://localhost"+uri, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ResponseCode, w.Code)
+			if tc.ResponseCode == http.StatusOK {
+				var actual model.ContinuedDeviceDeployments
+				if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual)) {
+					assert.Equal(t, tc.AppCount, actual.Count)
+				}
+			}
+		})
+	}
+}
+
+func TestContinueDeviceDeployment(t *testing.T) {
+	t.Parallel()
+
+	const deploymentID = "f826484e-1157-4109-af21-304e6d711560"
+	testCases := map[string]struct {
+		ID string
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode int
+	}{
+		"ok": {
+			ID:           deploymentID,
+			CallApp:      true,
+			ResponseCode: http.StatusNoContent,
+		},
+		"error, id not UUID": {
+			ID:           "foo",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, not found": {
+			ID:           deploymentID,
+			CallApp:      true,
+			AppError:     app.ErrStorageNotFound,
+			ResponseCode: http.StatusNotFound,
+		},
+		"error, not paused": {
+			ID:           deploymentID,
+			CallApp:      true,
+			AppError:     app.ErrDeviceDeploymentNotPaused,
+			ResponseCode: http.StatusConflict,
+		},
+		"error, internal": {
+			ID:           deploymentID,
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			if tc.CallApp {
+				mockApp.On("ContinueDeviceDeployment", contextMatcher(), tc.ID, "device").
+					Return(tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.POST(ApiUrlManagementDeploymentsDeviceContinue, d.ContinueDeviceDeployment)
+
+			uri := strings.NewReplacer(":id", tc.ID, ":devid", "device").
+				Replace(ApiUrlManagementDeploymentsDeviceContinue)
+			req := httptest.NewRequest(http.MethodPost, "http://localhost"+uri, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ResponseCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+const ParamMonth = "month"
+
+// GetDownloadUsage returns the artifact bytes served in the month, the
+// current one by default, optionally restricted to a deployment or a device.
+func (d *DeploymentsApiHandlers) GetDownloadUsage(c *gin.Context) {
+	query := c.Request.URL.Query()
+	filter := model.DownloadUsageFilter{
+		Month:        query.Get(ParamMonth),
+		DeploymentID: query.Get(ParamDeploymentID),
+		DeviceID:     query.Get(ParamDeviceID),
+	}
+	if filter.Month == "" {
+		filter.Month = model.DownloadUsageMonth(time.Now())
+	}
+	if err := filter.Validate(); err != nil {
+		d.view.RenderError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	usage, err := d.app.GetDownloadUsage(c.Request.Context(), filter)
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	d.view.RenderSuccessGet(c, usage)
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	mt "github.com/mendersoftware/mender-server/pkg/testing"
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+	deployments_testing "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestGetDownloadUsage(t *testing.T) {
+	t.Parallel()
+
+	const deploymentID = "f826484e-1157-4109-af21-304e6d711560"
+	usage := &model.DownloadUsage{
+		Month:        "2024-05",
+		DeploymentID: deploymentID,
+		Served:       2048,
+		Downloaded:   1024,
+	}
+	testCases := map[string]struct {
+		Query string
+
+		Filter   *model.DownloadUsageFilter
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			Query: "?month=2024-05&deployment_id=" + deploymentID,
+			Filter: &model.DownloadUsageFilter{
+				Month:        "2024-05",
+				DeploymentID: deploymentID,
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: usage,
+		},
+		"ok, current month": {
+			Filter: &model.DownloadUsageFilter{
+				Month: model.DownloadUsageMonth(time.Now()),
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: usage,
+		},
+		"error, invalid month": {
+			Query:        "?month=May",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(
+				"month: must be a valid date."),
+		},
+		"error, invalid deployment ID": {
+			Query:        "?deployment_id=foo",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(
+				"deployment_id: must be a valid UUID."),
+		},
+		"error, internal": {
+			Query: "?month=2024-05",
+			Filter: &model.DownloadUsageFilter{
+				Month: "2024-05",
+			},
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.Filter != nil {
+				if tc.AppError != nil {
+					app.On("GetDownloadUsage", contextMatcher(), *tc.Filter).
+						Return(nil, tc.AppError)
+				} else {
+					app.On("GetDownloadUsage", contextMatcher(), *tc.Filter).
+						Return(usage, nil)
+				}
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.GET(ApiUrlManagementDeploymentsDownloadStatistics,
+				d.GetDownloadUsage)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodGet,
+				Path: "http://localhost" +
+					ApiUrlManagementDeploymentsDownloadStatistics + tc.Query,
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/config"
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	dconfig "github.com/mendersoftware/mender-server/services/deployments/config"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+)
+
+func (d *DeploymentsApiHandlers) ListEdgeCaches(c *gin.Context) {
+	caches, err := d.app.ListEdgeCaches(c.Request.Context())
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	d.view.RenderSuccessGet(c, caches)
+}
+
+func (d *DeploymentsApiHandlers) GetEdgeCache(c *gin.Context) {
+	id := c.Param("id")
+	if !govalidator.IsUUID(id) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+
+	cache, err := d.app.GetEdgeCache(c.Request.Context(), id)
+	switch err {
+	case nil:
+		d.view.RenderSuccessGet(c, cache)
+	case app.ErrEdgeCacheNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+// AddEdgeCache registers the cache endpoint; the response holds the secret
+// used by the cache to verify the download links.
+func (d *DeploymentsApiHandlers) AddEdgeCache(c *gin.Context) {
+	var req model.EdgeCacheRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+
+	cache, err := d.app.AddEdgeCache(c.Request.Context(), req)
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	c.Writer.Header().Set(
+		view.HttpHeaderLocation,
+		fmt.Sprintf("%s/%s", c.Request.URL.Path, cache.ID),
+	)
+	c.JSON(http.StatusCreated, cache)
+}
+
+func (d *DeploymentsApiHandlers) DeleteEdgeCache(c *gin.Context) {
+	id := c.Param("id")
+	if !govalidator.IsUUID(id) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+
+	err := d.app.DeleteEdgeCache(c.Request.Context(), id)
+	switch err {
+	case nil:
+		d.view.RenderSuccessDelete(c)
+	case app.ErrEdgeCacheNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+// edgeCacheRequest verifies the request of the cache signed with its secret
+// and returns the context of the tenant of the cache and the cache ID.
+func (d *DeploymentsApiHandlers) edgeCacheRequest(
+	c *gin.Context,
+) (context.Context, string, bool) {
+	id := c.Param("id")
+	if !govalidator.IsUUID(id) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return nil, "", false
+	}
+	sig := model.NewRequestSignature(c.Request, nil)
+	if err := sig.Validate(); err != nil {
+		switch cause := errors.Cause(err); cause {
+		case model.ErrLinkExpired:
+			d.view.RenderError(c, cause, http.StatusForbidden)
+		default:
+			d.view.RenderError(c,
+				errors.Wrap(err, "invalid request parameters"),
+				http.StatusBadRequest,
+			)
+		}
+		return nil, "", false
+	}
+
+	ctx := identity.WithContext(c.Request.Context(), &identity.Identity{
+		Subject: id,
+		Tenant:  c.Query(ParamTenantID),
+	})
+	err := d.app.AuthenticateEdgeCache(ctx, id, sig)
+	switch err {
+	case nil:
+		return ctx, id, true
+	case app.ErrEdgeCacheNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	case app.ErrEdgeCacheSignatureInvalid:
+		d.view.RenderError(c, err, http.StatusForbidden)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+	return nil, "", false
+}
+
+// PutEdgeCacheArtifacts saves the artifacts held by the cache, reported
+// by the cache itself in a request signed with its secret.
+func (d *DeploymentsApiHandlers) PutEdgeCacheArtifacts(c *gin.Context) {
+	ctx, id, ok := d.edgeCacheRequest(c)
+	if !ok {
+		return
+	}
+	var report model.EdgeCacheReport
+	if err := c.ShouldBindJSON(&report); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+	if err := report.Validate(); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+
+	err := d.app.ReportEdgeCacheArtifacts(ctx, id, report)
+	switch err {
+	case nil:
+		d.view.RenderSuccessPut(c)
+	case app.ErrEdgeCacheNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+// GetEdgeCacheArtifact redirects the cache filling itself to the download
+// link of the artifact; the request is signed with the secret of the cache.
+func (d *DeploymentsApiHandlers) GetEdgeCacheArtifact(c *gin.Context) {
+	ctx, _, ok := d.edgeCacheRequest(c)
+	if !ok {
+		return
+	}
+	artifactID := c.Param(ParamArtifactID)
+	if !govalidator.IsUUID(artifactID) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+
+	expireSeconds := config.Config.GetInt(dconfig.SettingsStorageDownloadExpireSeconds)
+	link, err := d.app.DownloadLink(ctx, artifactID,
+		time.Duration(expireSeconds)*time.Second)
+	if err == app.ErrDownloadQuotaExceeded {
+		d.view.RenderError(c, err, http.StatusTooManyRequests)
+		return
+	} else if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	if link == nil {
+		d.view.RenderErrorNotFound(c)
+		return
+	}
+	c.Redirect(http.StatusFound, link.Uri)
+}
@@ -0,0 +1,464 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	mt "github.com/mendersoftware/mender-server/pkg/testing"
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+	deployments_testing "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestAddEdgeCache(t *testing.T) {
+	t.Parallel()
+
+	const cacheID = "f826484e-1157-4109-af21-304e6d711561"
+
+	cache := &model.EdgeCache{
+		ID:     cacheID,
+		URL:    "https://cache.plant-7.local",
+		Secret: "secret",
+		Attribute: model.EdgeCacheAttribute{
+			Scope: "inventory",
+			Name:  "site",
+			Value: "plant-7",
+		},
+		Artifacts: []model.EdgeCacheArtifact{},
+	}
+
+	testCases := map[string]struct {
+		Body interface{}
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode     int
+		ResponseLocation string
+		ResponseBody     interface{}
+	}{
+		"ok": {
+			Body: model.EdgeCacheRequest{
+				URL: "https://cache.plant-7.local",
+				Attribute: model.EdgeCacheAttribute{
+					Name:  "site",
+					Value: "plant-7",
+				},
+			},
+			CallApp:          true,
+			ResponseCode:     http.StatusCreated,
+			ResponseLocation: ApiUrlManagementEdgeCaches + "/" + cacheID,
+			ResponseBody:     cache,
+		},
+		"error, invalid url": {
+			Body: model.EdgeCacheRequest{
+				URL: "ftp://cache.plant-7.local",
+				Attribute: model.EdgeCacheAttribute{
+					Name:  "site",
+					Value: "plant-7",
+				},
+			},
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(
+				"Validating request body: url: " +
+					model.ErrEdgeCacheURLScheme.Error() + "."),
+		},
+		"error, internal": {
+			Body: model.EdgeCacheRequest{
+				URL: "https://cache.plant-7.local",
+				Attribute: model.EdgeCacheAttribute{
+					Name:  "site",
+					Value: "plant-7",
+				},
+			},
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				var res *model.EdgeCache
+				if tc.AppError == nil {
+					res = cache
+				}
+				app.On("AddEdgeCache", contextMatcher(), tc.Body).
+					Return(res, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.POST(ApiUrlManagementEdgeCaches, d.AddEdgeCache)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodPost,
+				Path:   "http://localhost" + ApiUrlManagementEdgeCaches,
+				Body:   tc.Body,
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode,
+				map[string]string{
+					"Location": tc.ResponseLocation,
+				}, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+func TestGetEdgeCache(t *testing.T) {
+	t.Parallel()
+
+	const cacheID = "f826484e-1157-4109-af21-304e6d711561"
+
+	testCases := map[string]struct {
+		CacheID string
+
+		CallApp  bool
+		AppCache *model.EdgeCache
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			CacheID:      cacheID,
+			CallApp:      true,
+			AppCache:     &model.EdgeCache{ID: cacheID},
+			ResponseCode: http.StatusOK,
+			ResponseBody: &model.EdgeCache{ID: cacheID},
+		},
+		"error, invalid id": {
+			CacheID:      "foo",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(ErrIDNotUUID.Error()),
+		},
+		"error, not found": {
+			CacheID:      cacheID,
+			CallApp:      true,
+			AppError:     app.ErrEdgeCacheNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: deployments_testing.RestError(app.ErrEdgeCacheNotFound.Error()),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				app.On("GetEdgeCache", contextMatcher(), tc.CacheID).
+					Return(tc.AppCache, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.GET(ApiUrlManagementEdgeCachesId, d.GetEdgeCache)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodGet,
+				Path: "http://localhost" + strings.Replace(
+					ApiUrlManagementEdgeCachesId, ":id", tc.CacheID, 1),
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+func TestDeleteEdgeCache(t *testing.T) {
+	t.Parallel()
+
+	const cacheID = "f826484e-1157-4109-af21-304e6d711561"
+
+	testCases := map[string]struct {
+		CacheID string
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			CacheID:      cacheID,
+			CallApp:      true,
+			ResponseCode: http.StatusNoContent,
+		},
+		"error, not found": {
+			CacheID:      cacheID,
+			CallApp:      true,
+			AppError:     app.ErrEdgeCacheNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: deployments_testing.RestError(app.ErrEdgeCacheNotFound.Error()),
+		},
+		"error, internal": {
+			CacheID:      cacheID,
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				app.On("DeleteEdgeCache", contextMatcher(), tc.CacheID).
+					Return(tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.DELETE(ApiUrlManagementEdgeCachesId, d.DeleteEdgeCache)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodDelete,
+				Path: "http://localhost" + strings.Replace(
+					ApiUrlManagementEdgeCachesId, ":id", tc.CacheID, 1),
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+// signEdgeCacheRequest returns the URL of the request signed by the cache.
+func signEdgeCacheRequest(method, path string, expire time.Time) string {
+	uri, _ := url.Parse("http://localhost" + path)
+	q := uri.Query()
+	q.Set(model.ParamTenantID, "tenant")
+	uri.RawQuery = q.Encode()
+	sig := model.NewRequestSignature(&http.Request{
+		Method: method,
+		URL:    uri,
+	}, []byte("secret"))
+	sig.SetExpire(expire)
+	return sig.PresignURL()
+}
+
+func TestPutEdgeCacheArtifacts(t *testing.T) {
+	t.Parallel()
+
+	const (
+		cacheID    = "f826484e-1157-4109-af21-304e6d711561"
+		artifactID = "3bd2ea4e-0b4e-4bf4-8d1d-1e5f0b3c2a10"
+	)
+	path := ApiUrlDevices + strings.Replace(
+		ApiUrlDevicesEdgeCacheArtifacts, ":id", cacheID, 1)
+
+	testCases := map[string]struct {
+		Body interface{}
+		URL  string
+
+		AuthError error
+		CallApp   bool
+		AppError  error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			Body: model.EdgeCacheReport{
+				Artifacts: []model.EdgeCacheArtifact{{ID: artifactID}},
+			},
+			CallApp:      true,
+			ResponseCode: http.StatusNoContent,
+		},
+		"error, invalid artifact id": {
+			Body: model.EdgeCacheReport{
+				Artifacts: []model.EdgeCacheArtifact{{ID: "foo"}},
+			},
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(
+				"Validating request body: artifacts: (0: (id: must be a valid UUID.).)."),
+		},
+		"error, not found": {
+			Body: model.EdgeCacheReport{
+				Artifacts: []model.EdgeCacheArtifact{},
+			},
+			AuthError:    app.ErrEdgeCacheNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: deployments_testing.RestError(app.ErrEdgeCacheNotFound.Error()),
+		},
+		"error, signature invalid": {
+			Body: model.EdgeCacheReport{
+				Artifacts: []model.EdgeCacheArtifact{},
+			},
+			AuthError:    app.ErrEdgeCacheSignatureInvalid,
+			ResponseCode: http.StatusForbidden,
+			ResponseBody: deployments_testing.RestError(
+				app.ErrEdgeCacheSignatureInvalid.Error()),
+		},
+		"error, link expired": {
+			Body: model.EdgeCacheReport{
+				Artifacts: []model.EdgeCacheArtifact{},
+			},
+			URL: signEdgeCacheRequest(http.MethodPut, path,
+				time.Now().Add(-time.Minute)),
+			ResponseCode: http.StatusForbidden,
+			ResponseBody: deployments_testing.RestError(model.ErrLinkExpired.Error()),
+		},
+		"error, unsigned request": {
+			Body: model.EdgeCacheReport{
+				Artifacts: []model.EdgeCacheArtifact{},
+			},
+			URL:          "http://localhost" + path,
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(
+				"invalid request parameters: x-men-expire: required key is missing; " +
+					"x-men-signature: required key is missing."),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			appMock := &mapp.App{}
+			defer appMock.AssertExpectations(t)
+			if tc.URL == "" {
+				tc.URL = signEdgeCacheRequest(http.MethodPut, path,
+					time.Now().Add(time.Minute))
+				appMock.On("AuthenticateEdgeCache",
+					mock.MatchedBy(func(ctx context.Context) bool {
+						id := identity.FromContext(ctx)
+						return id != nil && id.Tenant == "tenant"
+					}),
+					cacheID,
+					mock.AnythingOfType("*model.RequestSignature"),
+				).Return(tc.AuthError)
+			}
+			if tc.CallApp {
+				appMock.On("ReportEdgeCacheArtifacts", contextMatcher(), cacheID, tc.Body).
+					Return(tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), appMock)
+			router := setUpTestRouter()
+			router.PUT(ApiUrlDevices+ApiUrlDevicesEdgeCacheArtifacts,
+				d.PutEdgeCacheArtifacts)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodPut,
+				Path:   tc.URL,
+				Body:   tc.Body,
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+func TestGetEdgeCacheArtifact(t *testing.T) {
+	t.Parallel()
+
+	const (
+		cacheID    = "f826484e-1157-4109-af21-304e6d711561"
+		artifactID = "3bd2ea4e-0b4e-4bf4-8d1d-1e5f0b3c2a10"
+	)
+	path := ApiUrlDevices + strings.NewReplacer(
+		":id", cacheID,
+		":artifact_id", artifactID,
+	).Replace(ApiUrlDevicesEdgeCacheArtifact)
+
+	testCases := map[string]struct {
+		AuthError error
+		CallApp   bool
+		Link      *model.Link
+		AppError  error
+
+		ResponseCode     int
+		ResponseLocation string
+	}{
+		"ok": {
+			CallApp: true,
+			Link: &model.Link{
+				Uri: "https://storage.local/artifact",
+			},
+			ResponseCode:     http.StatusFound,
+			ResponseLocation: "https://storage.local/artifact",
+		},
+		"error, artifact not found": {
+			CallApp:      true,
+			ResponseCode: http.StatusNotFound,
+		},
+		"error, quota exceeded": {
+			CallApp:      true,
+			AppError:     app.ErrDownloadQuotaExceeded,
+			ResponseCode: http.StatusTooManyRequests,
+		},
+		"error, signature invalid": {
+			AuthError:    app.ErrEdgeCacheSignatureInvalid,
+			ResponseCode: http.StatusForbidden,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			appMock := &mapp.App{}
+			defer appMock.AssertExpectations(t)
+			appMock.On("AuthenticateEdgeCache", contextMatcher(), cacheID,
+				mock.AnythingOfType("*model.RequestSignature")).
+				Return(tc.AuthError)
+			if tc.CallApp {
+				appMock.On("DownloadLink", contextMatcher(), artifactID,
+					mock.AnythingOfType("time.Duration")).
+					Return(tc.Link, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), appMock)
+			router := setUpTestRouter()
+			router.GET(ApiUrlDevices+ApiUrlDevicesEdgeCacheArtifact,
+				d.GetEdgeCacheArtifact)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodGet,
+				Path: signEdgeCacheRequest(http.MethodGet, path,
+					time.Now().Add(time.Minute)),
+			})
+			recorded := restutil.RunRequest(t, router, req)
+			assert.Equal(t, tc.ResponseCode, recorded.Recorder.Code)
+			assert.Equal(t, tc.ResponseLocation, recorded.Recorder.Header().Get("Location"))
+		})
+	}
+}
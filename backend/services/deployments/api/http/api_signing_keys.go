@@ -0,0 +1,108 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+func (d *DeploymentsApiHandlers) ListSigningKeys(c *gin.Context) {
+	keys, err := d.app.ListSigningKeys(c.Request.Context())
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	d.view.RenderSuccessGet(c, keys)
+}
+
+func (d *DeploymentsApiHandlers) AddSigningKey(c *gin.Context) {
+	var req model.SigningKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+
+	key, err := d.app.AddSigningKey(c.Request.Context(), req)
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	d.view.RenderSuccessPost(c, key.ID)
+}
+
+func (d *DeploymentsApiHandlers) DeleteSigningKey(c *gin.Context) {
+	id := c.Param("id")
+	if !govalidator.IsUUID(id) {
+		d.view.RenderError(c, ErrIDNotUUID, http.StatusBadRequest)
+		return
+	}
+
+	err := d.app.DeleteSigningKey(c.Request.Context(), id)
+	switch err {
+	case nil:
+		d.view.RenderSuccessDelete(c)
+	case app.ErrSigningKeyNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+func (d *DeploymentsApiHandlers) GetArtifactSignatureSettings(c *gin.Context) {
+	settings, err := d.app.GetArtifactSignatureSettings(c.Request.Context())
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	d.view.RenderSuccessGet(c, settings)
+}
+
+func (d *DeploymentsApiHandlers) PutArtifactSignatureSettings(c *gin.Context) {
+	var settings model.ArtifactSignatureSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+	if err := settings.Validate(); err != nil {
+		d.view.RenderError(c,
+			errors.Wrap(err, "Validating request body"),
+			http.StatusBadRequest)
+		return
+	}
+
+	err := d.app.SetArtifactSignatureSettings(c.Request.Context(), &settings)
+	if err != nil {
+		d.view.RenderInternalError(c, err)
+		return
+	}
+	d.view.RenderSuccessPut(c)
+}
@@ -0,0 +1,252 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	mt "github.com/mendersoftware/mender-server/pkg/testing"
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+	deployments_testing "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestAddSigningKey(t *testing.T) {
+	t.Parallel()
+
+	const keyID = "f826484e-1157-4109-af21-304e6d711561"
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	testCases := map[string]struct {
+		Body interface{}
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode     int
+		ResponseLocation string
+		ResponseBody     interface{}
+	}{
+		"ok": {
+			Body: model.SigningKeyRequest{
+				Name:      "release key",
+				PublicKey: publicKey,
+			},
+			CallApp:          true,
+			ResponseCode:     http.StatusCreated,
+			ResponseLocation: ApiUrlManagementArtifactsSigningKeys + "/" + keyID,
+		},
+		"error, invalid key": {
+			Body: model.SigningKeyRequest{
+				Name:      "release key",
+				PublicKey: "foo",
+			},
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(
+				"Validating request body: public_key: " +
+					model.ErrSigningKeyInvalidPEM.Error() + "."),
+		},
+		"error, internal": {
+			Body: model.SigningKeyRequest{
+				PublicKey: publicKey,
+			},
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				var key *model.SigningKey
+				if tc.AppError == nil {
+					key = &model.SigningKey{ID: keyID}
+				}
+				app.On("AddSigningKey", contextMatcher(), tc.Body).
+					Return(key, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.POST(ApiUrlManagementArtifactsSigningKeys, d.AddSigningKey)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodPost,
+				Path:   "http://localhost" + ApiUrlManagementArtifactsSigningKeys,
+				Body:   tc.Body,
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode,
+				map[string]string{
+					"Location": tc.ResponseLocation,
+				}, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+func TestDeleteSigningKey(t *testing.T) {
+	t.Parallel()
+
+	const keyID = "f826484e-1157-4109-af21-304e6d711561"
+
+	testCases := map[string]struct {
+		KeyID string
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			KeyID:        keyID,
+			CallApp:      true,
+			ResponseCode: http.StatusNoContent,
+		},
+		"error, invalid id": {
+			KeyID:        "foo",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(ErrIDNotUUID.Error()),
+		},
+		"error, not found": {
+			KeyID:        keyID,
+			CallApp:      true,
+			AppError:     app.ErrSigningKeyNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: deployments_testing.RestError(app.ErrSigningKeyNotFound.Error()),
+		},
+		"error, internal": {
+			KeyID:        keyID,
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				app.On("DeleteSigningKey", contextMatcher(), tc.KeyID).
+					Return(tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.DELETE(ApiUrlManagementArtifactsSigningKeysId, d.DeleteSigningKey)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodDelete,
+				Path: "http://localhost" + strings.Replace(
+					ApiUrlManagementArtifactsSigningKeysId, ":id", tc.KeyID, 1),
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+func TestPutArtifactSignatureSettings(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Body interface{}
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			Body: &model.ArtifactSignatureSettings{
+				Policy: model.SignaturePolicyRequire,
+			},
+			CallApp:      true,
+			ResponseCode: http.StatusNoContent,
+		},
+		"error, invalid policy": {
+			Body: &model.ArtifactSignatureSettings{
+				Policy: "foo",
+			},
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError(
+				"Validating request body: policy: must be a valid value."),
+		},
+		"error, internal": {
+			Body: &model.ArtifactSignatureSettings{
+				Policy: model.SignaturePolicyVerify,
+			},
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := &mapp.App{}
+			defer app.AssertExpectations(t)
+			if tc.CallApp {
+				app.On("SetArtifactSignatureSettings", contextMatcher(), tc.Body).
+					Return(tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), app)
+			router := setUpTestRouter()
+			router.PUT(ApiUrlManagementArtifactsSigningPolicy,
+				d.PutArtifactSignatureSettings)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodPut,
+				Path:   "http://localhost" + ApiUrlManagementArtifactsSigningPolicy,
+				Body:   tc.Body,
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
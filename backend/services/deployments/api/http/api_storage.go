@@ -0,0 +1,160 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+	"github.com/mendersoftware/mender-server/services/deployments/storage/filesystem"
+)
+
+const ParamObjectPath = "path"
+
+// storageRequest verifies the signature of the storage request and returns
+// the path of the requested object.
+func (d *DeploymentsApiHandlers) storageRequest(c *gin.Context) (string, bool) {
+	if d.config.LocalStorage == nil || d.config.PresignSecret == nil {
+		d.view.RenderErrorNotFound(c)
+		return "", false
+	}
+	req := c.Request
+	if req.Method == http.MethodHead {
+		// HEAD requests are authorized by the download (GET) signature.
+		headReq := *req
+		headReq.Method = http.MethodGet
+		req = &headReq
+	}
+	sig := model.NewRequestSignature(req, d.config.PresignSecret)
+	if err := sig.Validate(); err != nil {
+		switch cause := errors.Cause(err); cause {
+		case model.ErrLinkExpired:
+			d.view.RenderError(c, cause, http.StatusForbidden)
+		default:
+			d.view.RenderError(c,
+				errors.Wrap(err, "invalid request parameters"),
+				http.StatusBadRequest,
+			)
+		}
+		return "", false
+	}
+	if !sig.VerifyHMAC256() {
+		d.view.RenderError(c,
+			errors.New("signature invalid"),
+			http.StatusForbidden,
+		)
+		return "", false
+	}
+	return strings.TrimPrefix(c.Param(ParamObjectPath), "/"), true
+}
+
+func (d *DeploymentsApiHandlers) renderStorageError(c *gin.Context, err error) {
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		d.view.RenderErrorNotFound(c)
+	} else {
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+// GetStorageObject serves the signed download requests of the local
+// object storage.
+func (d *DeploymentsApiHandlers) GetStorageObject(c *gin.Context) {
+	objectPath, ok := d.storageRequest(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	info, err := d.config.LocalStorage.StatObject(ctx, objectPath)
+	if err != nil {
+		d.renderStorageError(c, err)
+		return
+	}
+	obj, err := d.config.LocalStorage.GetObject(ctx, objectPath)
+	if err != nil {
+		d.renderStorageError(c, err)
+		return
+	}
+	defer obj.Close()
+
+	hdr := c.Writer.Header()
+	hdr.Set("Content-Type", app.ArtifactContentType)
+	filename := c.Query(filesystem.ParamFilename)
+	if filename != "" {
+		hdr.Set("Content-Disposition", mime.FormatMediaType(
+			"attachment", map[string]string{"filename": filename},
+		))
+	}
+	var modTime time.Time
+	if info.LastModified != nil {
+		modTime = *info.LastModified
+	}
+	// Support range requests for resuming the downloads.
+	if rs, ok := obj.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, filename, modTime, rs)
+		return
+	}
+	if info.Size != nil {
+		hdr.Set("Content-Length", strconv.FormatInt(*info.Size, 10))
+	}
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, obj)
+}
+
+// PutStorageObject serves the signed upload requests of the local object
+// storage.
+func (d *DeploymentsApiHandlers) PutStorageObject(c *gin.Context) {
+	objectPath, ok := d.storageRequest(c)
+	if !ok {
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, d.config.MaxImageSize)
+	err := d.config.LocalStorage.PutObject(c.Request.Context(), objectPath, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			d.view.RenderError(c, ErrModelArtifactFileTooLarge,
+				http.StatusRequestEntityTooLarge)
+		} else {
+			d.renderStorageError(c, err)
+		}
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// DeleteStorageObject serves the signed delete requests of the local
+// object storage.
+func (d *DeploymentsApiHandlers) DeleteStorageObject(c *gin.Context) {
+	objectPath, ok := d.storageRequest(c)
+	if !ok {
+		return
+	}
+	err := d.config.LocalStorage.DeleteObject(c.Request.Context(), objectPath)
+	if err != nil {
+		d.renderStorageError(c, err)
+		return
+	}
+	d.view.RenderSuccessDelete(c)
+}
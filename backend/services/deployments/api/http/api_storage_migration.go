@@ -0,0 +1,85 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+func tenantContext(c *gin.Context) context.Context {
+	return identity.WithContext(
+		c.Request.Context(),
+		&identity.Identity{Tenant: c.Param("tenant")},
+	)
+}
+
+// PostTenantStorageMigration schedules the copy of the artifacts of the
+// tenant to another storage.
+func (d *DeploymentsApiHandlers) PostTenantStorageMigration(c *gin.Context) {
+	defer c.Request.Body.Close()
+
+	req, err := model.ParseStorageMigrationRequest(c.Request.Body)
+	if err != nil {
+		d.view.RenderError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	migration, err := d.app.StartStorageMigration(tenantContext(c), *req)
+	switch err {
+	case nil:
+		d.view.RenderSuccessPost(c, migration.ID)
+	case app.ErrStorageMigrationInProgress:
+		d.view.RenderError(c, err, http.StatusConflict)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+// GetTenantStorageMigration returns the status and the progress of the
+// storage migration.
+func (d *DeploymentsApiHandlers) GetTenantStorageMigration(c *gin.Context) {
+	migration, err := d.app.GetStorageMigration(tenantContext(c), c.Param("id"))
+	switch err {
+	case nil:
+		d.view.RenderSuccessGet(c, migration)
+	case app.ErrStorageMigrationNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+// ResumeTenantStorageMigration schedules again a failed storage migration.
+func (d *DeploymentsApiHandlers) ResumeTenantStorageMigration(c *gin.Context) {
+	migration, err := d.app.ResumeStorageMigration(tenantContext(c), c.Param("id"))
+	switch err {
+	case nil:
+		d.view.RenderSuccessGet(c, migration)
+	case app.ErrStorageMigrationNotFound:
+		d.view.RenderError(c, err, http.StatusNotFound)
+	case app.ErrStorageMigrationInProgress, app.ErrStorageMigrationNotResumable:
+		d.view.RenderError(c, err, http.StatusConflict)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
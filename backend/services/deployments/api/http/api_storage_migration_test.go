@@ -0,0 +1,262 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	mt "github.com/mendersoftware/mender-server/pkg/testing"
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+	deployments_testing "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+const storageMigrationTenantID = "123456789012345678901234"
+
+func tenantContextMatcher() interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		id := identity.FromContext(ctx)
+		return id != nil && id.Tenant == storageMigrationTenantID
+	})
+}
+
+func storageMigrationURL(url string) string {
+	url = strings.Replace(url, ":tenant", storageMigrationTenantID, 1)
+	return "http://localhost" + strings.Replace(url, ":id", "migration", 1)
+}
+
+func TestPostTenantStorageMigration(t *testing.T) {
+	t.Parallel()
+
+	target := map[string]interface{}{
+		"type":   "s3",
+		"key":    "not_so_secret_key_id",
+		"secret": "super_secret",
+		"bucket": "bucketMcBucketFace",
+		"region": "wrld-east-west-1",
+	}
+	testCases := map[string]struct {
+		Body interface{}
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			Body:         map[string]interface{}{"mode": "migrate", "target": target},
+			CallApp:      true,
+			ResponseCode: http.StatusCreated,
+		},
+		"error, invalid mode": {
+			Body:         map[string]interface{}{"mode": "move", "target": target},
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: deployments_testing.RestError("mode: must be a valid value."),
+		},
+		"error, in progress": {
+			Body:         map[string]interface{}{"mode": "mirror", "target": target},
+			CallApp:      true,
+			AppError:     app.ErrStorageMigrationInProgress,
+			ResponseCode: http.StatusConflict,
+			ResponseBody: deployments_testing.RestError(
+				app.ErrStorageMigrationInProgress.Error()),
+		},
+		"error, internal": {
+			Body:         map[string]interface{}{"mode": "mirror", "target": target},
+			CallApp:      true,
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			if tc.CallApp {
+				call := mockApp.On("StartStorageMigration", tenantContextMatcher(),
+					mock.MatchedBy(func(req model.StorageMigrationRequest) bool {
+						return req.Target != nil &&
+							req.Target.Bucket == "bucketMcBucketFace"
+					}))
+				if tc.AppError != nil {
+					call.Return(nil, tc.AppError)
+				} else {
+					call.Return(&model.StorageMigration{ID: "migration"}, nil)
+				}
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.POST(ApiUrlInternalTenantStorageMigrations,
+				d.PostTenantStorageMigration)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodPost,
+				Path:   storageMigrationURL(ApiUrlInternalTenantStorageMigrations),
+				Body:   tc.Body,
+			})
+			recorded := restutil.RunRequest(t, router, req)
+			if tc.ResponseBody == nil {
+				assert.Equal(t, tc.ResponseCode, recorded.Recorder.Code)
+				assert.Equal(t,
+					"/tenants/"+storageMigrationTenantID+"/storage/migrations/migration",
+					recorded.Recorder.Header().Get("Location"))
+				return
+			}
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+func TestGetTenantStorageMigration(t *testing.T) {
+	t.Parallel()
+
+	migration := &model.StorageMigration{
+		ID:         "migration",
+		Mode:       model.StorageMigrationModeMigrate,
+		Status:     model.StorageMigrationStatusRunning,
+		Artifacts:  10,
+		Copied:     4,
+		Size:       1000,
+		CopiedSize: 400,
+	}
+	testCases := map[string]struct {
+		Migration *model.StorageMigration
+		AppError  error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			Migration:    migration,
+			ResponseCode: http.StatusOK,
+			ResponseBody: migration,
+		},
+		"error, not found": {
+			AppError:     app.ErrStorageMigrationNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: deployments_testing.RestError(
+				app.ErrStorageMigrationNotFound.Error()),
+		},
+		"error, internal": {
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			mockApp.On("GetStorageMigration", tenantContextMatcher(), "migration").
+				Return(tc.Migration, tc.AppError)
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.GET(ApiUrlInternalTenantStorageMigration,
+				d.GetTenantStorageMigration)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodGet,
+				Path:   storageMigrationURL(ApiUrlInternalTenantStorageMigration),
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
+
+func TestResumeTenantStorageMigration(t *testing.T) {
+	t.Parallel()
+
+	migration := &model.StorageMigration{
+		ID:     "migration",
+		Mode:   model.StorageMigrationModeMirror,
+		Status: model.StorageMigrationStatusPending,
+	}
+	testCases := map[string]struct {
+		Migration *model.StorageMigration
+		AppError  error
+
+		ResponseCode int
+		ResponseBody interface{}
+	}{
+		"ok": {
+			Migration:    migration,
+			ResponseCode: http.StatusOK,
+			ResponseBody: migration,
+		},
+		"error, not found": {
+			AppError:     app.ErrStorageMigrationNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: deployments_testing.RestError(
+				app.ErrStorageMigrationNotFound.Error()),
+		},
+		"error, not resumable": {
+			AppError:     app.ErrStorageMigrationNotResumable,
+			ResponseCode: http.StatusConflict,
+			ResponseBody: deployments_testing.RestError(
+				app.ErrStorageMigrationNotResumable.Error()),
+		},
+		"error, internal": {
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: deployments_testing.RestError("internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			mockApp.On("ResumeStorageMigration", tenantContextMatcher(), "migration").
+				Return(tc.Migration, tc.AppError)
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.POST(ApiUrlInternalTenantStorageMigrationResume,
+				d.ResumeTenantStorageMigration)
+
+			req := rtest.MakeTestRequest(&rtest.TestRequest{
+				Method: http.MethodPost,
+				Path:   storageMigrationURL(ApiUrlInternalTenantStorageMigrationResume),
+			})
+			checker := mt.NewJSONResponse(tc.ResponseCode, nil, tc.ResponseBody)
+			recorded := restutil.RunRequest(t, router, req)
+			mt.CheckHTTPResponse(t, checker, recorded)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+	"github.com/mendersoftware/mender-server/services/deployments/storage/filesystem"
+)
+
+func TestStorageObject(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	secret := []byte("secret")
+	const objectPath = "tenant/artifact"
+
+	uri, _ := url.Parse("http://localhost" + ApiUrlDevices + ApiUrlDevicesStorage)
+	objStore, err := filesystem.New(ctx, t.TempDir(), filesystem.NewOptions().
+		SetExternalURI(uri).
+		SetSecret(secret))
+	require.NoError(t, err)
+
+	router := NewRouter(ctx, &mapp.App{}, nil, NewConfig().
+		SetPresignSecret(secret).
+		SetMaxImageSize(16).
+		SetLocalStorage(objStore))
+	serve := func(method, uri, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, uri, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// upload
+	link, err := objStore.PutRequest(ctx, objectPath, time.Minute, true)
+	require.NoError(t, err)
+	w := serve(http.MethodPut, link.Uri, "content")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(http.MethodPut, link.Uri, "content exceeding the maximum size")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// download
+	link, err = objStore.GetRequest(ctx, objectPath, "artifact.mender", time.Minute, true)
+	require.NoError(t, err)
+	w = serve(http.MethodGet, link.Uri, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "content", w.Body.String())
+	assert.Equal(t, app.ArtifactContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=artifact.mender`,
+		w.Header().Get("Content-Disposition"))
+
+	req := httptest.NewRequest(http.MethodGet, link.Uri, nil)
+	req.Header.Set("Range", "bytes=3-")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "tent", w.Body.String())
+
+	// HEAD is authorized by the download signature
+	w = serve(http.MethodHead, link.Uri, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "7", w.Header().Get("Content-Length"))
+
+	// the signature covers the method and the path
+	w = serve(http.MethodDelete, link.Uri, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve(http.MethodGet, strings.Replace(link.Uri, objectPath, "tenant/other", 1), "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// expired link
+	link, err = objStore.GetRequest(ctx, objectPath, "", -time.Minute, true)
+	require.NoError(t, err)
+	w = serve(http.MethodGet, link.Uri, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// unsigned request
+	w = serve(http.MethodGet, uri.String()+"/"+objectPath, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// delete
+	link, err = objStore.DeleteRequest(ctx, objectPath, time.Minute, false)
+	require.NoError(t, err)
+	w = serve(http.MethodDelete, link.Uri, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(http.MethodDelete, link.Uri, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	_, err = objStore.StatObject(ctx, objectPath)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+}
+
+func TestStorageObjectDisabled(t *testing.T) {
+	t.Parallel()
+
+	router := NewRouter(context.Background(), &mapp.App{}, nil, NewConfig().
+		SetPresignSecret([]byte("secret")))
+
+	req := httptest.NewRequest(http.MethodGet,
+		"http://localhost"+ApiUrlDevices+ApiUrlDevicesStorage+"/tenant/artifact?"+
+			model.ParamExpire+"="+time.Now().Add(time.Minute).Format(time.RFC3339),
+		nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
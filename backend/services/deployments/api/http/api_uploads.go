@@ -0,0 +1,139 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/config"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	dconfig "github.com/mendersoftware/mender-server/services/deployments/config"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+)
+
+const ParamPartNumber = "number"
+
+var ErrUploadPartTooLarge = errors.New("upload part exceeds the maximum part size")
+
+func (d *DeploymentsApiHandlers) renderUploadError(c *gin.Context, err error) {
+	switch errors.Cause(err) {
+	case app.ErrUploadNotFound:
+		d.view.RenderErrorNotFound(c)
+	case app.ErrUploadNotPending, app.ErrStorageMigrationSwitching:
+		d.view.RenderError(c, err, http.StatusConflict)
+	case app.ErrUploadPartNumber:
+		d.view.RenderError(c, err, http.StatusBadRequest)
+	case app.ErrUploadPartsMissing, app.ErrUploadPartTooSmall:
+		d.view.RenderError(c, err, http.StatusUnprocessableEntity)
+	case app.ErrUploadTooLarge:
+		d.view.RenderError(c, err, http.StatusRequestEntityTooLarge)
+	default:
+		d.view.RenderInternalError(c, err)
+	}
+}
+
+// InitiateMultipartUpload starts a resumable artifact upload.
+func (d *DeploymentsApiHandlers) InitiateMultipartUpload(c *gin.Context) {
+	expireSeconds := config.Config.GetInt(
+		dconfig.SettingsStorageMultipartUploadExpireSeconds,
+	)
+	upload, err := d.app.InitiateMultipartUpload(
+		c.Request.Context(),
+		time.Duration(expireSeconds)*time.Second,
+	)
+	if err != nil {
+		d.renderUploadError(c, err)
+		return
+	}
+	c.Writer.Header().Set(
+		view.HttpHeaderLocation,
+		fmt.Sprintf("%s/%s", c.Request.URL.Path, upload.ID),
+	)
+	c.JSON(http.StatusCreated, upload)
+}
+
+// UploadPart stores the request body as the part of the resumable upload.
+func (d *DeploymentsApiHandlers) UploadPart(c *gin.Context) {
+	partNumber, err := strconv.Atoi(c.Param(ParamPartNumber))
+	if err != nil {
+		d.view.RenderError(c, app.ErrUploadPartNumber, http.StatusBadRequest)
+		return
+	}
+	// The parts are buffered to allow the storage to retry the requests.
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			d.view.RenderError(c, ErrUploadPartTooLarge,
+				http.StatusRequestEntityTooLarge)
+		} else {
+			d.view.RenderError(c,
+				errors.Wrap(err, "failed to read request body"),
+				http.StatusBadRequest)
+		}
+		return
+	}
+	part, err := d.app.UploadPart(
+		c.Request.Context(),
+		c.Param(ParamID),
+		partNumber,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		d.renderUploadError(c, err)
+		return
+	}
+	d.view.RenderSuccessGet(c, part)
+}
+
+// ListUploadParts returns the uploaded parts of the resumable upload.
+func (d *DeploymentsApiHandlers) ListUploadParts(c *gin.Context) {
+	parts, err := d.app.ListUploadParts(c.Request.Context(), c.Param(ParamID))
+	if err != nil {
+		d.renderUploadError(c, err)
+		return
+	}
+	d.view.RenderSuccessGet(c, parts)
+}
+
+// CompleteMultipartUpload assembles the uploaded parts and starts the
+// processing of the artifact.
+func (d *DeploymentsApiHandlers) CompleteMultipartUpload(c *gin.Context) {
+	err := d.app.CompleteMultipartUpload(c.Request.Context(), c.Param(ParamID))
+	if err != nil {
+		d.renderUploadError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// AbortMultipartUpload discards the resumable upload.
+func (d *DeploymentsApiHandlers) AbortMultipartUpload(c *gin.Context) {
+	err := d.app.AbortMultipartUpload(c.Request.Context(), c.Param(ParamID))
+	if err != nil {
+		d.renderUploadError(c, err)
+		return
+	}
+	d.view.RenderSuccessDelete(c)
+}
@@ -0,0 +1,335 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/requestsize"
+
+	"github.com/mendersoftware/mender-server/services/deployments/app"
+	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
+)
+
+const testUploadID = "f826484e-1157-4109-af21-304e6d711561"
+
+func TestInitiateMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	upload := &model.MultipartUpload{
+		ID:          testUploadID,
+		Expire:      time.Now().Add(time.Hour).Round(time.Second).UTC(),
+		PartSizeMin: model.MultipartUploadPartSizeMin,
+		PartSizeMax: model.MultipartUploadPartSizeMax,
+		MaxParts:    model.MultipartUploadMaxParts,
+	}
+	testCases := map[string]struct {
+		AppError error
+
+		ResponseCode int
+	}{
+		"ok": {
+			ResponseCode: http.StatusCreated,
+		},
+		"error, internal": {
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			if tc.AppError != nil {
+				mockApp.On("InitiateMultipartUpload", contextMatcher(), mock.Anything).
+					Return(nil, tc.AppError)
+			} else {
+				mockApp.On("InitiateMultipartUpload", contextMatcher(), mock.Anything).
+					Return(upload, nil)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.POST(ApiUrlManagementArtifactsUploads, d.InitiateMultipartUpload)
+
+			req := httptest.NewRequest(http.MethodPost,
+				"http://localhost"+ApiUrlManagementArtifactsUploads, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ResponseCode, w.Code)
+			if tc.AppError == nil {
+				assert.Equal(t, ApiUrlManagementArtifactsUploads+"/"+testUploadID,
+					w.Header().Get("Location"))
+				var actual model.MultipartUpload
+				if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual)) {
+					assert.Equal(t, *upload, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadPart(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		PartNumber string
+		Body       string
+
+		CallApp  bool
+		AppError error
+
+		ResponseCode int
+	}{
+		"ok": {
+			PartNumber:   "1",
+			Body:         "part",
+			CallApp:      true,
+			ResponseCode: http.StatusOK,
+		},
+		"error, invalid part number": {
+			PartNumber:   "first",
+			Body:         "part",
+			ResponseCode: http.StatusBadRequest,
+		},
+		"error, part too large": {
+			PartNumber:   "1",
+			Body:         "part exceeding the maximum part size",
+			ResponseCode: http.StatusRequestEntityTooLarge,
+		},
+		"error, upload not found": {
+			PartNumber:   "1",
+			Body:         "part",
+			CallApp:      true,
+			AppError:     app.ErrUploadNotFound,
+			ResponseCode: http.StatusNotFound,
+		},
+		"error, upload not pending": {
+			PartNumber:   "1",
+			Body:         "part",
+			CallApp:      true,
+			AppError:     app.ErrUploadNotPending,
+			ResponseCode: http.StatusConflict,
+		},
+		"error, part number out of range": {
+			PartNumber:   "10001",
+			Body:         "part",
+			CallApp:      true,
+			AppError:     app.ErrUploadPartNumber,
+			ResponseCode: http.StatusBadRequest,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			if tc.CallApp {
+				var part *model.UploadPart
+				if tc.AppError == nil {
+					part = &model.UploadPart{
+						PartNumber: 1,
+						Size:       int64(len(tc.Body)),
+					}
+				}
+				mockApp.On("UploadPart",
+					contextMatcher(),
+					testUploadID,
+					mock.AnythingOfType("int"),
+					mock.MatchedBy(func(r io.ReadSeeker) bool {
+						_, _ = r.Seek(0, io.SeekStart)
+						b, _ := io.ReadAll(r)
+						return assert.Equal(t, tc.Body, string(b))
+					}),
+				).Return(part, tc.AppError)
+			}
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.PUT(ApiUrlManagementArtifactsUploadsPart,
+				requestsize.Middleware(16), d.UploadPart)
+
+			uri := "http://localhost" + strings.NewReplacer(
+				":id", testUploadID,
+				":number", tc.PartNumber,
+			).Replace(ApiUrlManagementArtifactsUploadsPart)
+			req := httptest.NewRequest(http.MethodPut, uri,
+				bytes.NewReader([]byte(tc.Body)))
+			// Hide the content length from the middleware to exercise
+			// the limit while reading the body.
+			req.ContentLength = -1
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ResponseCode, w.Code)
+		})
+	}
+}
+
+func TestListUploadParts(t *testing.T) {
+	t.Parallel()
+
+	parts := []model.UploadPart{
+		{PartNumber: 1, Size: model.MultipartUploadPartSizeMin},
+		{PartNumber: 2, Size: 10},
+	}
+	mockApp := &mapp.App{}
+	defer mockApp.AssertExpectations(t)
+	mockApp.On("ListUploadParts", contextMatcher(), testUploadID).
+		Return(parts, nil).
+		Once().
+		On("ListUploadParts", contextMatcher(), "unknown").
+		Return(nil, app.ErrUploadNotFound).
+		Once()
+
+	d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+	router := setUpTestRouter()
+	router.GET(ApiUrlManagementArtifactsUploadsParts, d.ListUploadParts)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"http://localhost"+strings.Replace(
+			ApiUrlManagementArtifactsUploadsParts, ":id", testUploadID, 1,
+		), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual []model.UploadPart
+	if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual)) {
+		assert.Equal(t, parts, actual)
+	}
+
+	req = httptest.NewRequest(http.MethodGet,
+		"http://localhost"+strings.Replace(
+			ApiUrlManagementArtifactsUploadsParts, ":id", "unknown", 1,
+		), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCompleteMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		AppError error
+
+		ResponseCode int
+	}{
+		"ok": {
+			ResponseCode: http.StatusAccepted,
+		},
+		"error, upload not found": {
+			AppError:     app.ErrUploadNotFound,
+			ResponseCode: http.StatusNotFound,
+		},
+		"error, parts missing": {
+			AppError:     app.ErrUploadPartsMissing,
+			ResponseCode: http.StatusUnprocessableEntity,
+		},
+		"error, part too small": {
+			AppError:     app.ErrUploadPartTooSmall,
+			ResponseCode: http.StatusUnprocessableEntity,
+		},
+		"error, internal": {
+			AppError:     errors.New("some error"),
+			ResponseCode: http.StatusInternalServerError,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			mockApp.On("CompleteMultipartUpload", contextMatcher(), testUploadID).
+				Return(tc.AppError)
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.POST(ApiUrlManagementArtifactsUploadsComplete, d.CompleteMultipartUpload)
+
+			req := httptest.NewRequest(http.MethodPost,
+				"http://localhost"+strings.Replace(
+					ApiUrlManagementArtifactsUploadsComplete, ":id", testUploadID, 1,
+				), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ResponseCode, w.Code)
+		})
+	}
+}
+
+func TestAbortMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		AppError error
+
+		ResponseCode int
+	}{
+		"ok": {
+			ResponseCode: http.StatusNoContent,
+		},
+		"error, upload not found": {
+			AppError:     app.ErrUploadNotFound,
+			ResponseCode: http.StatusNotFound,
+		},
+		"error, upload not pending": {
+			AppError:     app.ErrUploadNotPending,
+			ResponseCode: http.StatusConflict,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mockApp := &mapp.App{}
+			defer mockApp.AssertExpectations(t)
+			mockApp.On("AbortMultipartUpload", contextMatcher(), testUploadID).
+				Return(tc.AppError)
+
+			d := NewDeploymentsApiHandlers(nil, new(view.RESTView), mockApp)
+			router := setUpTestRouter()
+			router.DELETE(ApiUrlManagementArtifactsUploadsId, d.AbortMultipartUpload)
+
+			req := httptest.NewRequest(http.MethodDelete,
+				"http://localhost"+strings.Replace(
+					ApiUrlManagementArtifactsUploadsId, ":id", testUploadID, 1,
+				), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.ResponseCode, w.Code)
+		})
+	}
+}
@@ -46,6 +46,7 @@ func TestGetLimits(t *testing.T) {
 		body  map[string]interface{}
 		err   error
 		limit *model.Limit
+		usage *model.DownloadUsage
 	}{
 		{
 			name: "storage",
@@ -62,6 +63,16 @@ func TestGetLimits(t *testing.T) {
 			body: deployments_testing.RestError("internal error"),
 			err:  errors.New("failed"),
 		},
+		{
+			name: "download",
+			code: http.StatusOK,
+			body: map[string]interface{}{"limit": 1000, "usage": 300},
+			limit: &model.Limit{
+				Name:  "download",
+				Value: 1000,
+			},
+			usage: &model.DownloadUsage{Served: 300, Downloaded: 200},
+		},
 		{
 			name: "foobar",
 			code: http.StatusBadRequest,
@@ -85,6 +96,11 @@ func TestGetLimits(t *testing.T) {
 				app.On("GetLimit", contextMatcher(), tc.name).
 					Return(tc.limit, tc.err)
 			}
+			if tc.usage != nil {
+				app.On("GetDownloadUsage", contextMatcher(),
+					mock.AnythingOfType("model.DownloadUsageFilter")).
+					Return(tc.usage, nil)
+			}
 			req := rtest.MakeTestRequest(&rtest.TestRequest{
 				Method: "GET",
 				Path:   "http://localhost/api/0.0.1/limits/" + tc.name,
@@ -32,6 +32,7 @@ import (
 	"github.com/mendersoftware/mender-server/pkg/routing"
 
 	"github.com/mendersoftware/mender-server/services/deployments/app"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
 	"github.com/mendersoftware/mender-server/services/deployments/store"
 	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil"
 	"github.com/mendersoftware/mender-server/services/deployments/utils/restutil/view"
@@ -45,24 +46,50 @@ const (
 	ApiUrlManagementArtifacts               = "/artifacts"
 	ApiUrlManagementArtifactsList           = "/artifacts/list"
 	ApiUrlManagementArtifactsGenerate       = "/artifacts/generate"
+	ApiUrlManagementArtifactsGenerateDelta  = "/artifacts/generate/delta"
 	ApiUrlManagementArtifactsDirectUpload   = "/artifacts/directupload"
 	ApiUrlManagementArtifactsCompleteUpload = ApiUrlManagementArtifactsDirectUpload +
 		"/:id/complete"
-	ApiUrlManagementArtifactsId         = "/artifacts/:id"
-	ApiUrlManagementArtifactsIdDownload = "/artifacts/:id/download"
+	ApiUrlManagementArtifactsUploads         = "/artifacts/uploads"
+	ApiUrlManagementArtifactsUploadsId       = "/artifacts/uploads/:id"
+	ApiUrlManagementArtifactsUploadsParts    = "/artifacts/uploads/:id/parts"
+	ApiUrlManagementArtifactsUploadsPart     = "/artifacts/uploads/:id/parts/:number"
+	ApiUrlManagementArtifactsUploadsComplete = "/artifacts/uploads/:id/complete"
+	ApiUrlManagementArtifactsId              = "/artifacts/:id"
+	ApiUrlManagementArtifactsIdDownload      = "/artifacts/:id/download"
+	ApiUrlManagementArtifactsIdContents      = "/artifacts/:id/contents"
+
+	ApiUrlManagementArtifactsSigningKeys   = "/artifacts/signing/keys"
+	ApiUrlManagementArtifactsSigningKeysId = "/artifacts/signing/keys/:id"
+	ApiUrlManagementArtifactsSigningPolicy = "/artifacts/signing/policy"
+
+	ApiUrlManagementArtifactsRetentionPolicy  = "/artifacts/retention/policy"
+	ApiUrlManagementArtifactsRetentionPreview = "/artifacts/retention/preview"
 
 	ApiUrlManagementDeployments                   = "/deployments"
 	ApiUrlManagementMultipleDeploymentsStatistics = "/deployments/statistics/list"
+	ApiUrlManagementDeploymentsDownloadStatistics = "/deployments/statistics/downloads"
 	ApiUrlManagementDeploymentsGroup              = "/deployments/group/:name"
+	ApiUrlManagementDeploymentsGroupPreview       = "/deployments/group/:name/preview"
+	ApiUrlManagementDeploymentsPreview            = "/deployments/preview"
 	ApiUrlManagementDeploymentsId                 = "/deployments/:id"
 	ApiUrlManagementDeploymentsStatistics         = "/deployments/:id/statistics"
 	ApiUrlManagementDeploymentsStatus             = "/deployments/:id/status"
 	ApiUrlManagementDeploymentsDevices            = "/deployments/:id/devices"
 	ApiUrlManagementDeploymentsDevicesList        = "/deployments/:id/devices/list"
 	ApiUrlManagementDeploymentsLog                = "/deployments/:id/devices/:devid/log"
+	ApiUrlManagementDeploymentsLogSearch          = "/deployments/:id/logs/search"
 	ApiUrlManagementDeploymentsDeviceId           = "/deployments/devices/:id"
 	ApiUrlManagementDeploymentsDeviceHistory      = "/deployments/devices/:id/history"
+	ApiUrlManagementDeploymentsHistoryExport      = "/deployments/devices/history/export"
 	ApiUrlManagementDeploymentsDeviceList         = "/deployments/:id/device_list"
+	ApiUrlManagementDeploymentsPhases             = "/deployments/:id/phases"
+	ApiUrlManagementDeploymentsPhaseResume        = "/deployments/:id/phases/:phase_id/resume"
+	ApiUrlManagementDeploymentsContinue           = "/deployments/:id/continue"
+	ApiUrlManagementDeploymentsDeviceContinue     = "/deployments/:id/devices/:devid/continue"
+
+	ApiUrlManagementEdgeCaches   = "/deployments/caches"
+	ApiUrlManagementEdgeCachesId = "/deployments/caches/:id"
 
 	ApiUrlManagementReleases     = "/deployments/releases"
 	ApiUrlManagementReleasesList = "/deployments/releases/list"
@@ -82,6 +109,11 @@ const (
 	ApiUrlDevicesDeploymentsLog   = "/device/deployments/:id/log"
 	ApiUrlDevicesDownloadConfig   = "/download/configuration" +
 		"/:deployment_id/:device_type/:device_id"
+	ApiUrlDevicesStorage       = "/download/storage"
+	ApiUrlDevicesStorageObject = ApiUrlDevicesStorage + "/*path"
+
+	ApiUrlDevicesEdgeCacheArtifacts = "/download/caches/:id/artifacts"
+	ApiUrlDevicesEdgeCacheArtifact  = ApiUrlDevicesEdgeCacheArtifacts + "/:artifact_id"
 
 	ApiUrlInternalAlive                          = "/alive"
 	ApiUrlInternalHealth                         = "/health"
@@ -91,6 +123,9 @@ const (
 	ApiUrlInternalTenantDeploymentsDevice        = "/tenants/:tenant/deployments/devices/:id"
 	ApiUrlInternalTenantArtifacts                = "/tenants/:tenant/artifacts"
 	ApiUrlInternalTenantStorageSettings          = "/tenants/:tenant/storage/settings"
+	ApiUrlInternalTenantStorageMigrations        = "/tenants/:tenant/storage/migrations"
+	ApiUrlInternalTenantStorageMigration         = "/tenants/:tenant/storage/migrations/:id"
+	ApiUrlInternalTenantStorageMigrationResume   = "/tenants/:tenant/storage/migrations/:id/resume"
 	ApiUrlInternalDeviceConfigurationDeployments = "/tenants/:tenant/configuration/deployments" +
 		"/:deployment_id/devices/:device_id"
 	ApiUrlInternalDeviceDeploymentLastStatusDeployments = "/tenants/:tenant/devices/deployments" +
@@ -132,6 +167,7 @@ func NewRouter(
 	withAuth.Use(identity.Middleware())
 
 	NewImagesResourceRoutes(withAuth, deploymentsHandlers, cfg)
+	NewStorageRoutes(publicAPIs, deploymentsHandlers, cfg)
 
 	// The rest of the public APIs does not need custom request size limits
 	publicAPIs.Use(requestsize.Middleware(cfg.MaxRequestSize))
@@ -162,6 +198,7 @@ func NewImagesResourceRoutes(router *gin.RouterGroup,
 
 	artifactSizeLimit := requestsize.Middleware(cfg.MaxImageSize)
 	generateDataSizeLimit := requestsize.Middleware(cfg.MaxGenerateDataSize)
+	partSizeLimit := requestsize.Middleware(model.MultipartUploadPartSizeMax)
 
 	mgmtV1.Use(requestsize.Middleware(cfg.MaxRequestSize))
 	mgmtV2.Use(requestsize.Middleware(cfg.MaxRequestSize))
@@ -173,6 +210,21 @@ func NewImagesResourceRoutes(router *gin.RouterGroup,
 	mgmtV1.GET(ApiUrlManagementArtifactsList, controller.ListImages)
 	mgmtV1.GET(ApiUrlManagementArtifactsId, controller.GetImage)
 	mgmtV1.GET(ApiUrlManagementArtifactsIdDownload, controller.DownloadLink)
+	mgmtV1.GET(ApiUrlManagementArtifactsIdContents, controller.GetArtifactContents)
+	mgmtV1.GET(ApiUrlManagementArtifactsSigningKeys, controller.ListSigningKeys)
+	mgmtV1.GET(ApiUrlManagementArtifactsSigningPolicy,
+		controller.GetArtifactSignatureSettings)
+	mgmtV1.DELETE(ApiUrlManagementArtifactsSigningKeysId, controller.DeleteSigningKey)
+	mgmtV1.GET(ApiUrlManagementArtifactsRetentionPolicy,
+		controller.GetArtifactRetentionSettings)
+	mgmtV1.GET(ApiUrlManagementArtifactsRetentionPreview,
+		controller.PreviewArtifactRetention)
+	mgmtV1.Group(".").Use(contenttype.CheckJSON()).
+		POST(ApiUrlManagementArtifactsSigningKeys, controller.AddSigningKey).
+		PUT(ApiUrlManagementArtifactsSigningPolicy,
+			controller.PutArtifactSignatureSettings).
+		PUT(ApiUrlManagementArtifactsRetentionPolicy,
+			controller.PutArtifactRetentionSettings)
 	if !controller.config.DisableNewReleasesFeature {
 		mgmtV1.DELETE(ApiUrlManagementArtifactsId, controller.DeleteImage)
 		mgmtV1Artifacts.Group(".").Use(artifactType).
@@ -180,8 +232,16 @@ func NewImagesResourceRoutes(router *gin.RouterGroup,
 				artifactSizeLimit, controller.NewImage).
 			POST(ApiUrlManagementArtifactsGenerate,
 				generateDataSizeLimit, controller.GenerateImage)
+		mgmtV1Artifacts.PUT(ApiUrlManagementArtifactsUploadsPart,
+			partSizeLimit, controller.UploadPart)
+		mgmtV1.POST(ApiUrlManagementArtifactsUploads, controller.InitiateMultipartUpload)
+		mgmtV1.GET(ApiUrlManagementArtifactsUploadsParts, controller.ListUploadParts)
+		mgmtV1.POST(ApiUrlManagementArtifactsUploadsComplete,
+			controller.CompleteMultipartUpload)
+		mgmtV1.DELETE(ApiUrlManagementArtifactsUploadsId, controller.AbortMultipartUpload)
 		mgmtV1.Group(".").Use(contenttype.CheckJSON()).
-			PUT(ApiUrlManagementArtifactsId, controller.EditImage)
+			PUT(ApiUrlManagementArtifactsId, controller.EditImage).
+			POST(ApiUrlManagementArtifactsGenerateDelta, controller.GenerateDeltaImage)
 
 	} else {
 		mgmtV1.DELETE(ApiUrlManagementArtifactsId, ServiceUnavailable)
@@ -190,6 +250,12 @@ func NewImagesResourceRoutes(router *gin.RouterGroup,
 			POST(ApiUrlManagementArtifacts, ServiceUnavailable).
 			POST(ApiUrlManagementArtifactsGenerate, ServiceUnavailable)
 		mgmtV1.PUT(ApiUrlManagementArtifactsId, ServiceUnavailable)
+		mgmtV1.POST(ApiUrlManagementArtifactsGenerateDelta, ServiceUnavailable)
+		mgmtV1.POST(ApiUrlManagementArtifactsUploads, ServiceUnavailable)
+		mgmtV1.PUT(ApiUrlManagementArtifactsUploadsPart, ServiceUnavailable)
+		mgmtV1.GET(ApiUrlManagementArtifactsUploadsParts, ServiceUnavailable)
+		mgmtV1.POST(ApiUrlManagementArtifactsUploadsComplete, ServiceUnavailable)
+		mgmtV1.DELETE(ApiUrlManagementArtifactsUploadsId, ServiceUnavailable)
 
 	}
 	if !controller.config.DisableNewReleasesFeature && cfg.EnableDirectUpload {
@@ -210,6 +276,20 @@ func NewImagesResourceRoutes(router *gin.RouterGroup,
 	}
 }
 
+// NewStorageRoutes defines the routes serving the signed requests of the
+// local object storage; the requests are authorized by the signature.
+func NewStorageRoutes(router *gin.RouterGroup,
+	controller *DeploymentsApiHandlers, cfg *Config) {
+	if cfg == nil || cfg.LocalStorage == nil {
+		return
+	}
+	devices := router.Group(ApiUrlDevices)
+	devices.GET(ApiUrlDevicesStorageObject, controller.GetStorageObject)
+	devices.HEAD(ApiUrlDevicesStorageObject, controller.GetStorageObject)
+	devices.PUT(ApiUrlDevicesStorageObject, controller.PutStorageObject)
+	devices.DELETE(ApiUrlDevicesStorageObject, controller.DeleteStorageObject)
+}
+
 func NewDeploymentsResourceRoutes(router *gin.RouterGroup, controller *DeploymentsApiHandlers) {
 
 	if controller == nil {
@@ -224,28 +304,49 @@ func NewDeploymentsResourceRoutes(router *gin.RouterGroup, controller *Deploymen
 	mgmtV2.GET(ApiUrlManagementV2Deployments, controller.LookupDeploymentV2)
 	mgmtV1.GET(ApiUrlManagementDeploymentsId, controller.GetDeployment)
 	mgmtV1.GET(ApiUrlManagementDeploymentsStatistics, controller.GetDeploymentStats)
+	mgmtV1.GET(ApiUrlManagementDeploymentsDownloadStatistics,
+		controller.GetDownloadUsage)
 	mgmtV1.GET(ApiUrlManagementDeploymentsDevices,
 		controller.GetDeviceStatusesForDeployment)
 	mgmtV1.GET(ApiUrlManagementDeploymentsDevicesList,
 		controller.GetDevicesListForDeployment)
 	mgmtV1.GET(ApiUrlManagementDeploymentsLog,
 		controller.GetDeploymentLogForDevice)
+	mgmtV1.GET(ApiUrlManagementDeploymentsLogSearch,
+		controller.SearchDeploymentLogs)
 	mgmtV1.GET(ApiUrlManagementDeploymentsDeviceId,
 		controller.ListDeviceDeployments)
+	mgmtV1.GET(ApiUrlManagementDeploymentsHistoryExport,
+		controller.ExportDeviceDeploymentsHistory)
 	mgmtV1.GET(ApiUrlManagementDeploymentsDeviceList,
 		controller.GetDeploymentDeviceList)
+	mgmtV1.GET(ApiUrlManagementDeploymentsPhases,
+		controller.GetDeploymentPhases)
+	mgmtV1.POST(ApiUrlManagementDeploymentsPhaseResume,
+		controller.ResumeDeploymentPhase)
+	mgmtV1.POST(ApiUrlManagementDeploymentsContinue,
+		controller.ContinueDeployment)
+	mgmtV1.POST(ApiUrlManagementDeploymentsDeviceContinue,
+		controller.ContinueDeviceDeployment)
 
 	mgmtV1.DELETE(ApiUrlManagementDeploymentsDeviceId,
 		controller.AbortDeviceDeployments)
 	mgmtV1.DELETE(ApiUrlManagementDeploymentsDeviceHistory,
 		controller.DeleteDeviceDeploymentsHistory)
 
+	mgmtV1.GET(ApiUrlManagementEdgeCaches, controller.ListEdgeCaches)
+	mgmtV1.GET(ApiUrlManagementEdgeCachesId, controller.GetEdgeCache)
+	mgmtV1.DELETE(ApiUrlManagementEdgeCachesId, controller.DeleteEdgeCache)
+
 	mgmtV1.Group(".").Use(contenttype.CheckJSON()).
 		POST(ApiUrlManagementDeployments, controller.PostDeployment).
 		POST(ApiUrlManagementDeploymentsGroup, controller.DeployToGroup).
+		POST(ApiUrlManagementDeploymentsPreview, controller.PreviewDeployment).
+		POST(ApiUrlManagementDeploymentsGroupPreview, controller.PreviewGroupDeployment).
 		POST(ApiUrlManagementMultipleDeploymentsStatistics,
 			controller.GetDeploymentsStats).
-		PUT(ApiUrlManagementDeploymentsStatus, controller.AbortDeployment)
+		PUT(ApiUrlManagementDeploymentsStatus, controller.AbortDeployment).
+		POST(ApiUrlManagementEdgeCaches, controller.AddEdgeCache)
 
 	// Devices
 	devices := router.Group(ApiUrlDevices)
@@ -253,6 +354,11 @@ func NewDeploymentsResourceRoutes(router *gin.RouterGroup, controller *Deploymen
 	devices.GET(ApiUrlDevicesDownloadConfig,
 		controller.DownloadConfiguration)
 
+	// The requests of the edge caches are signed with the cache secret.
+	devices.GET(ApiUrlDevicesEdgeCacheArtifact, controller.GetEdgeCacheArtifact)
+	devices.Group(".").Use(contenttype.CheckJSON()).
+		PUT(ApiUrlDevicesEdgeCacheArtifacts, controller.PutEdgeCacheArtifacts)
+
 	devices.Use(identity.Middleware())
 
 	devices.GET(ApiUrlDevicesDeploymentsNext, controller.GetDeploymentForDevice)
@@ -313,6 +419,12 @@ func InternalRoutes(router *gin.RouterGroup, controller *DeploymentsApiHandlers)
 	// per-tenant storage settings
 	router.GET(ApiUrlInternalTenantStorageSettings, controller.GetTenantStorageSettingsHandler)
 	router.PUT(ApiUrlInternalTenantStorageSettings, controller.PutTenantStorageSettingsHandler)
+	router.POST(ApiUrlInternalTenantStorageMigrations,
+		controller.PostTenantStorageMigration)
+	router.GET(ApiUrlInternalTenantStorageMigration,
+		controller.GetTenantStorageMigration)
+	router.POST(ApiUrlInternalTenantStorageMigrationResume,
+		controller.ResumeTenantStorageMigration)
 
 	// Configuration deployments (internal)
 	router.POST(ApiUrlInternalDeviceConfigurationDeployments,
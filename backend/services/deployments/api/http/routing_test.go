@@ -18,9 +18,16 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
 	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
 	mapp "github.com/mendersoftware/mender-server/services/deployments/app/mocks"
@@ -84,3 +91,113 @@ func TestNewRouter(t *testing.T) {
 		})
 	}
 }
+
+// gatewayRouter is a traefik router of the deployments service defined in
+// the labels of the docker-compose setup.
+type gatewayRouter struct {
+	name        string
+	path        *regexp.Regexp
+	priority    int
+	middlewares string
+}
+
+var gatewayPathRegexp = regexp.MustCompile("^PathRegexp\\(`([^`]+)`\\)$")
+
+func loadGatewayRouters(t *testing.T, service string) []gatewayRouter {
+	b, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "..", "docker-compose.yml"))
+	require.NoError(t, err)
+	var compose struct {
+		Services map[string]struct {
+			Labels map[string]string `yaml:"labels"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(b, &compose))
+
+	const prefix = "traefik.http.routers."
+	labels := compose.Services[service].Labels
+	var routers []gatewayRouter
+	for key, svc := range labels {
+		name, ok := strings.CutSuffix(strings.TrimPrefix(key, prefix), ".service")
+		if !strings.HasPrefix(key, prefix) || !ok || svc != service {
+			continue
+		}
+		rule := strings.TrimSpace(labels[prefix+name+".rule"])
+		m := gatewayPathRegexp.FindStringSubmatch(rule)
+		require.NotNilf(t, m, "unsupported rule of router %q: %s", name, rule)
+		router := gatewayRouter{
+			name:        name,
+			path:        regexp.MustCompile(m[1]),
+			priority:    len(rule),
+			middlewares: labels[prefix+name+".middlewares"],
+		}
+		if p, ok := labels[prefix+name+".priority"]; ok {
+			router.priority, err = strconv.Atoi(p)
+			require.NoError(t, err)
+		}
+		routers = append(routers, router)
+	}
+	require.NotEmpty(t, routers)
+	return routers
+}
+
+// route returns the router handling the path: traefik picks the matching
+// router with the highest priority, which defaults to the rule length.
+func route(routers []gatewayRouter, path string) *gatewayRouter {
+	var match *gatewayRouter
+	for i := range routers {
+		if routers[i].path.MatchString(path) &&
+			(match == nil || routers[i].priority > match.priority) {
+			match = &routers[i]
+		}
+	}
+	return match
+}
+
+func TestGatewayRoutes(t *testing.T) {
+	t.Parallel()
+	routers := loadGatewayRouters(t, "deployments")
+
+	deviceAuth := func(t *testing.T, router *gatewayRouter) bool {
+		return assert.Contains(t, router.middlewares, "devStack@file",
+			"router %q does not authenticate the devices", router.name)
+	}
+	noDeviceAuth := func(t *testing.T, router *gatewayRouter) bool {
+		return assert.NotContains(t, router.middlewares, "devStack@file",
+			"router %q requires a device token", router.name)
+	}
+	testCases := map[string]struct {
+		path   string
+		assert func(t *testing.T, router *gatewayRouter) bool
+	}{
+		"device API": {
+			path:   ApiUrlDevices + ApiUrlDevicesDeploymentsNext,
+			assert: deviceAuth,
+		},
+		"configuration download": {
+			path:   ApiUrlDevices + "/download/configuration/deployment/rpi4/device",
+			assert: noDeviceAuth,
+		},
+		"signed storage link": {
+			path:   ApiUrlDevices + ApiUrlDevicesStorage + "/tenant/artifact",
+			assert: noDeviceAuth,
+		},
+		"edge cache report": {
+			path:   ApiUrlDevices + "/download/caches/cache/artifacts",
+			assert: noDeviceAuth,
+		},
+		"edge cache fill": {
+			path:   ApiUrlDevices + "/download/caches/cache/artifacts/artifact",
+			assert: noDeviceAuth,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			router := route(routers, tc.path)
+			if assert.NotNil(t, router, "no gateway route to %s", tc.path) {
+				tc.assert(t, router)
+			}
+		})
+	}
+}
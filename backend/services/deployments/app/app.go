@@ -34,6 +34,7 @@ import (
 
 	"github.com/mendersoftware/mender-server/pkg/identity"
 	"github.com/mendersoftware/mender-server/pkg/log"
+	mstore "github.com/mendersoftware/mender-server/pkg/store"
 
 	"github.com/mendersoftware/mender-server/services/deployments/client/inventory"
 	"github.com/mendersoftware/mender-server/services/deployments/client/reporting"
@@ -55,6 +56,7 @@ const (
 	PerPageInventoryDevices          = 512
 	InventoryGroupScope              = "system"
 	InventoryIdentityScope           = "identity"
+	InventoryAttributeScope          = "inventory"
 	InventoryGroupAttributeName      = "group"
 	InventoryStatusAttributeName     = "status"
 	InventoryIdAttributeName         = "id"
@@ -99,6 +101,7 @@ var (
 	ErrDeviceDecommissioned    = errors.New("Device decommissioned")
 	ErrNoArtifact              = errors.New("No artifact for the deployment")
 	ErrNoDevices               = errors.New("No devices for the deployment")
+	ErrPredecessorNotFound     = errors.New("The deployment to follow does not exist")
 	ErrDuplicateDeployment     = errors.New("Deployment with given ID already exists")
 	ErrInvalidDeploymentID     = errors.New("Deployment ID must be a valid UUID")
 	ErrConflictingRequestData  = errors.New("Device provided conflicting request data")
@@ -115,11 +118,46 @@ type App interface {
 	HealthCheck(ctx context.Context) error
 	// limits
 	GetLimit(ctx context.Context, name string) (*model.Limit, error)
+	GetDownloadUsage(ctx context.Context,
+		filter model.DownloadUsageFilter) (*model.DownloadUsage, error)
 	ProvisionTenant(ctx context.Context, tenant_id string) error
 
 	// Storage Settings
 	GetStorageSettings(ctx context.Context) (*model.StorageSettings, error)
 	SetStorageSettings(ctx context.Context, storageSettings *model.StorageSettings) error
+	StartStorageMigration(
+		ctx context.Context,
+		req model.StorageMigrationRequest,
+	) (*model.StorageMigration, error)
+	GetStorageMigration(ctx context.Context, id string) (*model.StorageMigration, error)
+	ResumeStorageMigration(ctx context.Context, id string) (*model.StorageMigration, error)
+
+	// artifact signatures
+	GetArtifactSignatureSettings(ctx context.Context) (*model.ArtifactSignatureSettings, error)
+	SetArtifactSignatureSettings(
+		ctx context.Context,
+		settings *model.ArtifactSignatureSettings,
+	) error
+	GetArtifactRetentionSettings(ctx context.Context) (*model.ArtifactRetentionSettings, error)
+	SetArtifactRetentionSettings(
+		ctx context.Context,
+		settings *model.ArtifactRetentionSettings,
+	) error
+	ApplyArtifactRetention(
+		ctx context.Context,
+		dryRun bool,
+	) (*model.ArtifactRetentionReport, error)
+	ListSigningKeys(ctx context.Context) ([]model.SigningKey, error)
+	AddSigningKey(ctx context.Context, req model.SigningKeyRequest) (*model.SigningKey, error)
+	DeleteSigningKey(ctx context.Context, id string) error
+
+	// edge caches
+	ListEdgeCaches(ctx context.Context) ([]model.EdgeCache, error)
+	GetEdgeCache(ctx context.Context, id string) (*model.EdgeCache, error)
+	AddEdgeCache(ctx context.Context, req model.EdgeCacheRequest) (*model.EdgeCache, error)
+	DeleteEdgeCache(ctx context.Context, id string) error
+	AuthenticateEdgeCache(ctx context.Context, id string, sig *model.RequestSignature) error
+	ReportEdgeCacheArtifacts(ctx context.Context, id string, report model.EdgeCacheReport) error
 
 	// images
 	ListImages(
@@ -132,6 +170,7 @@ type App interface {
 	) ([]*model.Image, error)
 	DownloadLink(ctx context.Context, imageID string,
 		expire time.Duration) (*model.Link, error)
+	GetArtifactContents(ctx context.Context, id string) (*model.ArtifactContents, error)
 	UploadLink(
 		ctx context.Context,
 		expire time.Duration,
@@ -143,12 +182,26 @@ type App interface {
 		skipVerify bool,
 		metadata *model.DirectUploadMetadata,
 	) error
+	InitiateMultipartUpload(
+		ctx context.Context,
+		expire time.Duration,
+	) (*model.MultipartUpload, error)
+	UploadPart(
+		ctx context.Context,
+		id string,
+		partNumber int,
+		src io.ReadSeeker,
+	) (*model.UploadPart, error)
+	ListUploadParts(ctx context.Context, id string) ([]model.UploadPart, error)
+	CompleteMultipartUpload(ctx context.Context, id string) error
+	AbortMultipartUpload(ctx context.Context, id string) error
 	GetImage(ctx context.Context, id string) (*model.Image, error)
 	DeleteImage(ctx context.Context, imageID string) error
 	CreateImage(ctx context.Context,
 		multipartUploadMsg *model.MultipartUploadMsg) (string, error)
 	GenerateImage(ctx context.Context,
 		multipartUploadMsg *model.MultipartGenerateImageMsg) (string, error)
+	GenerateDeltaImage(ctx context.Context, req *model.GenerateDeltaRequest) (string, error)
 	GenerateConfigurationImage(
 		ctx context.Context,
 		deviceType string,
@@ -160,6 +213,11 @@ type App interface {
 	// deployments
 	CreateDeployment(ctx context.Context,
 		constructor *model.DeploymentConstructor) (string, error)
+	PreviewDeployment(
+		ctx context.Context,
+		constructor *model.DeploymentConstructor,
+		query model.DeploymentPreviewQuery,
+	) (*model.DeploymentPreview, error)
 	GetDeployment(ctx context.Context, deploymentID string) (*model.Deployment, error)
 	IsDeploymentFinished(ctx context.Context, deploymentID string) (bool, error)
 	AbortDeployment(ctx context.Context, deploymentID string) error
@@ -178,12 +236,22 @@ type App interface {
 		query store.ListQuery) ([]model.DeviceDeployment, int, error)
 	GetDeviceDeploymentListForDevice(ctx context.Context,
 		query store.ListQueryDeviceDeployments) ([]model.DeviceDeploymentListItem, int, error)
+	ExportDeviceDeploymentsHistory(
+		ctx context.Context,
+		filter model.DeviceDeploymentHistoryFilter,
+	) (store.Iterator[model.DeviceDeploymentHistoryRecord], error)
 	LookupDeployment(ctx context.Context,
 		query model.Query) ([]*model.Deployment, int64, error)
 	SaveDeviceDeploymentLog(ctx context.Context, deviceID string,
 		deploymentID string, logs []model.LogMessage) error
 	GetDeviceDeploymentLog(ctx context.Context,
 		deviceID, deploymentID string) (*model.DeploymentLog, error)
+	GetDeviceDeploymentAttemptLog(ctx context.Context,
+		deviceID, deploymentID string, attempt int) (*model.DeploymentLog, error)
+	SearchDeviceDeploymentLogs(
+		ctx context.Context,
+		search model.DeploymentLogSearch,
+	) ([]model.DeploymentLogMatch, int, error)
 	AbortDeviceDeployments(ctx context.Context, deviceID string) error
 	DeleteDeviceDeploymentsHistory(ctx context.Context, deviceId string) error
 	DecommissionDevice(ctx context.Context, deviceID string) error
@@ -201,6 +269,17 @@ type App interface {
 		model.DeviceDeploymentLastStatuses,
 		error,
 	)
+	GetDeploymentPhases(
+		ctx context.Context,
+		deploymentID string,
+	) ([]model.DeploymentPhaseInfo, error)
+	ResumeDeploymentPhase(ctx context.Context, deploymentID, phaseID string) error
+	ContinueDeviceDeployment(ctx context.Context, deploymentID, deviceID string) error
+	ContinueDeployment(
+		ctx context.Context,
+		deploymentID string,
+		deviceIDs []string,
+	) (int, error)
 
 	// releases
 	ReplaceReleaseTags(ctx context.Context, releaseName string, tags model.Tags) error
@@ -217,6 +296,13 @@ type Deployments struct {
 	workflowsClient workflows.Client
 	inventoryClient inventory.Client
 	reportingClient reporting.Client
+
+	// time after which the device deployment logs are removed
+	deploymentLogTTL time.Duration
+	// maximum size of the artifacts assembled from upload parts
+	maxImageSize int64
+	// devices which recently did not match continuous deployments
+	continuousMisses *continuousMisses
 }
 
 // Compile-time check
@@ -229,10 +315,11 @@ func NewDeployments(
 	withAuditLogs bool,
 ) *Deployments {
 	return &Deployments{
-		db:              storage,
-		objectStorage:   objectStorage,
-		workflowsClient: workflows.NewClient(),
-		inventoryClient: inventory.NewClient(),
+		db:               storage,
+		objectStorage:    objectStorage,
+		workflowsClient:  workflows.NewClient(),
+		inventoryClient:  inventory.NewClient(),
+		continuousMisses: newContinuousMisses(ContinuousMissTTL),
 	}
 }
 
@@ -276,6 +363,31 @@ func (d *Deployments) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// forEachTenant calls fn with the context of every tenant, or once with
+// the given context if there are no tenants, stopping at the first error.
+func (d *Deployments) forEachTenant(
+	ctx context.Context,
+	fn func(ctx context.Context, tenant string) error,
+) error {
+	tenantDbs, err := d.db.GetTenantDbs()
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve tenant DBs")
+	}
+	if len(tenantDbs) == 0 {
+		return fn(ctx, "")
+	}
+	for _, db := range tenantDbs {
+		tenant := mstore.TenantFromDbName(db, mongo.DbName)
+		tenantCtx := identity.WithContext(ctx, &identity.Identity{
+			Tenant: tenant,
+		})
+		if err := fn(tenantCtx, tenant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (d *Deployments) contextWithStorageSettings(
 	ctx context.Context,
 ) (context.Context, error) {
@@ -364,10 +476,17 @@ func (d *Deployments) handleArtifact(ctx context.Context,
 ) (string, error) {
 
 	l := log.FromContext(ctx)
+	if err := d.checkStorageSwitching(ctx); err != nil {
+		return "", err
+	}
 	ctx, err := d.contextWithStorageSettings(ctx)
 	if err != nil {
 		return "", err
 	}
+	policy, signingKeys, err := d.getArtifactSignatureVerification(ctx)
+	if err != nil {
+		return "", err
+	}
 
 	// create pipe
 	pR, pW := io.Pipe()
@@ -406,9 +525,14 @@ func (d *Deployments) handleArtifact(ctx context.Context,
 		return err
 	}()
 
+	// Direct uploads skip reading the payload, unless a signature policy
+	// is set: the signature only covers the manifest, so the payload must
+	// be checked against the manifest checksums to be trusted.
+	readPayload := !skipVerify || policy != model.SignaturePolicyNone
+
 	// parse artifact
 	// artifact library reads all the data from the given reader
-	metaArtifactConstructor, err := getMetaFromArchive(&tee, skipVerify)
+	metaArtifactConstructor, err := getMetaFromArchive(&tee, !readPayload, signingKeys)
 	if err != nil {
 		_ = pW.CloseWithError(err)
 		<-ch
@@ -429,7 +553,7 @@ func (d *Deployments) handleArtifact(ctx context.Context,
 		return artifactID, ErrModelInvalidMetadata
 	}
 
-	if !skipVerify {
+	if readPayload {
 		// read the rest of the data,
 		// just in case the artifact library did not read all the data from the reader
 		_, err = io.Copy(io.Discard, tee)
@@ -449,6 +573,16 @@ func (d *Deployments) handleArtifact(ctx context.Context,
 		return artifactID, uploadResponseErr
 	}
 
+	if err = checkArtifactSignature(policy, metaArtifactConstructor); err != nil {
+		errDelete := d.objectStorage.DeleteObject(
+			ctx, model.ImagePathFromContext(ctx, artifactID),
+		)
+		if errDelete != nil {
+			l.Warnf("failed to remove the rejected artifact %s: %s", artifactID, errDelete)
+		}
+		return artifactID, err
+	}
+
 	size := artifactReader.Count()
 	if skipVerify && validMetadata {
 		size = metadata.Size
@@ -460,8 +594,12 @@ func (d *Deployments) handleArtifact(ctx context.Context,
 		size,
 	)
 
-	// save image structure in the system
-	if err = d.db.InsertImage(ctx, image); err != nil {
+	// save image structure in the system, unless the storage started
+	// being switched over during the upload
+	if err = d.checkStorageSwitching(ctx); err == nil {
+		err = d.db.InsertImage(ctx, image)
+	}
+	if err != nil {
 		// Try to remove the storage from s3.
 		if errDelete := d.objectStorage.DeleteObject(
 			ctx, model.ImagePathFromContext(ctx, artifactID),
@@ -605,6 +743,9 @@ func (d *Deployments) handleRawFile(ctx context.Context,
 	if !isArtifactUnique {
 		return "", ErrModelArtifactNotUnique
 	}
+	if err = d.checkStorageSwitching(ctx); err != nil {
+		return "", err
+	}
 
 	ctx, err = d.contextWithStorageSettings(ctx)
 	if err != nil {
@@ -808,6 +949,10 @@ func (d *Deployments) DownloadLink(ctx context.Context, imageID string,
 		return nil, nil
 	}
 
+	if err := d.checkDownloadQuota(ctx, image.Size); err != nil {
+		return nil, err
+	}
+
 	ctx, err = d.contextWithStorageSettings(ctx)
 	if err != nil {
 		return nil, err
@@ -828,6 +973,7 @@ func (d *Deployments) DownloadLink(ctx context.Context, imageID string,
 	if err != nil {
 		return nil, errors.Wrap(err, "Generating download link")
 	}
+	d.accountDownload(ctx, model.DownloadUsage{Served: image.Size})
 
 	return link, nil
 }
@@ -837,6 +983,9 @@ func (d *Deployments) UploadLink(
 	expire time.Duration,
 	skipVerify bool,
 ) (*model.UploadLink, error) {
+	if err := d.checkStorageSwitching(ctx); err != nil {
+		return nil, err
+	}
 	ctx, err := d.contextWithStorageSettings(ctx)
 	if err != nil {
 		return nil, err
@@ -929,6 +1078,9 @@ func (d *Deployments) CompleteUpload(
 ) error {
 	l := log.FromContext(ctx)
 	idty := identity.FromContext(ctx)
+	if err := d.checkStorageSwitching(ctx); err != nil {
+		return err
+	}
 	ctx, err := d.contextWithStorageSettings(ctx)
 	if err != nil {
 		return err
@@ -1002,15 +1154,26 @@ func getUpdateFiles(uFiles []*handlers.DataFile) ([]model.UpdateFile, error) {
 	return files, nil
 }
 
-func getMetaFromArchive(r *io.Reader, skipVerify bool) (*model.ArtifactMeta, error) {
+func getMetaFromArchive(
+	r *io.Reader,
+	skipVerify bool,
+	signingKeys []model.SigningKey,
+) (*model.ArtifactMeta, error) {
 	metaArtifact := model.NewArtifactMeta()
 
 	aReader := areader.NewReader(*r)
 
-	// There is no signature verification here.
-	// It is just simple check if artifact is signed or not.
+	// The signature is verified against the trusted keys of the tenant;
+	// a signature which cannot be verified does not fail the parsing, the
+	// decision to reject the artifact is left to the signature policy.
 	aReader.VerifySignatureCallback = func(message, sig []byte) error {
 		metaArtifact.Signed = true
+		for _, key := range signingKeys {
+			if key.Verify(message, sig) == nil {
+				metaArtifact.SigningKeyID = key.ID
+				break
+			}
+		}
 		return nil
 	}
 
@@ -1202,13 +1365,26 @@ func (d *Deployments) CreateDeployment(ctx context.Context,
 		return "", errors.Wrap(err, "Validating deployment")
 	}
 
-	if len(constructor.Group) > 0 || constructor.AllDevices {
+	// continuous deployments follow the group instead of the devices
+	// belonging to it at creation
+	if (len(constructor.Group) > 0 || constructor.AllDevices) && !constructor.Continuous {
 		constructor, err = d.updateDeploymentConstructor(ctx, constructor)
 		if err != nil {
 			return "", err
 		}
 	}
 
+	if constructor.Release != nil {
+		release, err := d.resolveDeploymentRelease(ctx, constructor)
+		if err != nil {
+			return "", err
+		}
+		err = d.checkReleaseCompatibility(ctx, release, constructor)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	deployment, err := model.NewDeploymentFromConstructor(constructor)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create deployment")
@@ -1226,6 +1402,15 @@ func (d *Deployments) CreateDeployment(ctx context.Context,
 		return "", ErrNoArtifact
 	}
 
+	if constructor.AfterDeployment != "" {
+		predecessor, err := d.db.FindDeploymentByID(ctx, constructor.AfterDeployment)
+		if err != nil {
+			return "", errors.Wrap(err, "Finding the deployment to follow")
+		} else if predecessor == nil {
+			return "", ErrPredecessorNotFound
+		}
+	}
+
 	deployment.Artifacts = getArtifactIDs(artifacts)
 	deployment.DeviceList = constructor.Devices
 	deployment.MaxDevices = len(constructor.Devices)
@@ -1283,7 +1468,11 @@ func getDeploymentFilter(
 
 	var filter *model.Filter
 
-	if len(constructor.Group) > 0 {
+	if constructor.Continuous {
+		filter = &model.Filter{
+			Terms: deploymentDeviceFilters(constructor),
+		}
+	} else if len(constructor.Group) > 0 {
 		filter = &model.Filter{
 			Terms: []model.FilterPredicate{
 				{
@@ -1404,6 +1593,13 @@ func (d *Deployments) getDeploymentForDevice(ctx context.Context,
 	if deployment == nil {
 		return nil, nil, errors.New("No deployment corresponding to device deployment")
 	}
+	if deviceDeployment.Status == model.DeviceDeploymentStatusPending &&
+		deployment.IsExpired(time.Now()) {
+		if err := d.expireDeployment(ctx, deployment); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to expire the deployment")
+		}
+		return d.getNewDeploymentForDevice(ctx, deviceID)
+	}
 
 	return deployment, deviceDeployment, nil
 }
@@ -1415,6 +1611,9 @@ func (d *Deployments) getDeploymentForDevice(ctx context.Context,
 // deployment applied by the device;
 // this way we guarantee that the device will not receive deployment
 // that is older than the one installed on the device;
+//
+// when no such deployment exists, the device is offered the continuous
+// deployments it belongs to;
 func (d *Deployments) getNewDeploymentForDevice(ctx context.Context,
 	deviceID string) (*model.Deployment, *model.DeviceDeployment, error) {
 
@@ -1439,9 +1638,28 @@ func (d *Deployments) getNewDeploymentForDevice(ctx context.Context,
 			return nil, nil, errors.Wrap(err, "Failed to search for newer active deployments")
 		}
 		if deploy != nil {
+			now := time.Now()
+			if deploy.IsExpired(now) {
+				if err := d.expireDeployment(ctx, deploy); err != nil {
+					return nil, nil, errors.Wrap(err, "failed to expire the deployment")
+				}
+				lastDeployment = deploy.Created
+				continue
+			}
+			if deploy.IsScheduled(now) {
+				lastDeployment = deploy.Created
+				continue
+			}
 			if deploy.MaxDevices > 0 &&
 				deploy.DeviceCount != nil &&
-				*deploy.DeviceCount >= deploy.MaxDevices {
+				*deploy.DeviceCount >= deploy.PhasedDeviceLimit(now) {
+				lastDeployment = deploy.Created
+				continue
+			}
+			dependency, err := d.checkDeploymentDependency(ctx, deploy, deviceID)
+			if err != nil {
+				return nil, nil, err
+			} else if dependency == deploymentDependencyPending {
 				lastDeployment = deploy.Created
 				continue
 			}
@@ -1450,12 +1668,20 @@ func (d *Deployments) getNewDeploymentForDevice(ctx context.Context,
 			if err != nil {
 				return nil, nil, err
 			}
+			if dependency == deploymentDependencyFailed {
+				// skip the deployment for the device
+				if err := d.assignNoArtifact(ctx, deviceDeployment); err != nil {
+					return nil, nil, err
+				}
+				lastDeployment = deploy.Created
+				continue
+			}
 			return deploy, deviceDeployment, nil
 		} else {
 			lastDeployment = nil
 		}
 	}
-	return nil, nil, nil
+	return d.getContinuousDeploymentForDevice(ctx, deviceID, deviceDeployment)
 }
 
 func (d *Deployments) createDeviceDeploymentWithStatus(
@@ -1474,6 +1700,9 @@ func (d *Deployments) createDeviceDeploymentWithStatus(
 	deviceDeployment.Status = status
 	deviceDeployment.Active = status.Active()
 	deviceDeployment.Created = deployment.Created
+	if deployment.DeploymentConstructor != nil {
+		deviceDeployment.Retries = deployment.Retries
+	}
 
 	if err := d.setDeploymentDeviceCountIfUnset(ctx, deployment); err != nil {
 		return nil, err
@@ -1510,6 +1739,17 @@ func (d *Deployments) GetDeploymentForDeviceWithCurrent(ctx context.Context, dev
 	} else if deployment == nil {
 		return nil, nil
 	}
+	if deviceDeployment.Status == model.DeviceDeploymentStatusPending {
+		now := time.Now()
+		if deviceDeployment.RetryAfter != nil && now.Before(*deviceDeployment.RetryAfter) {
+			// the device waits before retrying the deployment
+			return nil, nil
+		}
+		if !d.isMaintenanceWindowOpen(ctx, deviceID, deployment, now) {
+			// the device is not allowed to start the deployment yet
+			return nil, nil
+		}
+	}
 
 	err = d.saveDeviceDeploymentRequest(ctx, deviceID, deviceDeployment, request)
 	if err != nil {
@@ -1580,21 +1820,53 @@ func (d *Deployments) getDeploymentInstructions(
 		}
 	}
 
-	ctx, err := d.contextWithStorageSettings(ctx)
-	if err != nil {
-		return nil, err
+	// the download quota applies when the artifact is first served: the
+	// device polling again during the deployment (e.g. after a reboot) is
+	// served the same artifact, which is accounted for already
+	firstServed := deviceDeployment.Status == model.DeviceDeploymentStatusPending
+	if firstServed {
+		if err := d.checkDownloadQuota(ctx, deviceDeployment.Image.Size); err != nil {
+			if err == ErrDownloadQuotaExceeded {
+				// the device asks again later, possibly in the next month
+				l.Warnf("not serving the deployment %s to the device %s: %s",
+					deviceDeployment.DeploymentId, deviceDeployment.DeviceId, err.Error())
+				return nil, nil
+			}
+			return nil, err
+		}
 	}
 
-	imagePath := model.ImagePathFromContext(ctx, deviceDeployment.Image.Id)
-	link, err := d.objectStorage.GetRequest(
-		ctx,
-		imagePath,
-		deviceDeployment.Image.Name+model.ArtifactFileSuffix,
-		DefaultUpdateDownloadLinkExpire,
-		true,
-	)
+	// serve the artifact from the nearest edge cache holding it, if any
+	link, err := d.getEdgeCacheLink(ctx, deviceDeployment.DeviceId,
+		deviceDeployment.Image.Id, time.Now().Add(DefaultUpdateDownloadLinkExpire))
 	if err != nil {
-		return nil, errors.Wrap(err, "Generating download link for the device")
+		l.Warnf("failed to get the edge cache download link, "+
+			"falling back to the storage: %s", err.Error())
+	}
+	if link == nil {
+		ctx, err := d.contextWithStorageSettings(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		imagePath := model.ImagePathFromContext(ctx, deviceDeployment.Image.Id)
+		link, err = d.objectStorage.GetRequest(
+			ctx,
+			imagePath,
+			deviceDeployment.Image.Name+model.ArtifactFileSuffix,
+			DefaultUpdateDownloadLinkExpire,
+			true,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "Generating download link for the device")
+		}
+	}
+	if firstServed {
+		d.accountDownload(ctx, model.DownloadUsage{
+			DeploymentID: deviceDeployment.DeploymentId,
+			DeviceID:     deviceDeployment.DeviceId,
+			Served:       deviceDeployment.Image.Size,
+		})
 	}
 
 	instructions := &model.DeploymentInstructions{
@@ -1608,6 +1880,11 @@ func (d *Deployments) getDeploymentInstructions(
 				ArtifactMeta.DeviceTypesCompatible,
 		},
 	}
+	if deployment.DeploymentConstructor != nil && deployment.UpdateControlMap != nil {
+		instructions.UpdateControlMap = deployment.UpdateControlMap.ForDevice(
+			deployment.Id, deviceDeployment.ContinuedStates, time.Now(),
+		)
+	}
 
 	return instructions, nil
 }
@@ -1704,6 +1981,13 @@ func (d *Deployments) updateDeviceDeploymentStatus(
 		return nil
 	}
 
+	if ddState.Status == model.DeviceDeploymentStatusFailure {
+		retried, err := d.retryDeviceDeployment(ctx, dd, ddState)
+		if err != nil || retried {
+			return err
+		}
+	}
+
 	// update finish time
 	ddState.FinishTime = finishTime
 
@@ -1713,6 +1997,14 @@ func (d *Deployments) updateDeviceDeploymentStatus(
 		return err
 	}
 
+	if model.DownloadCompleted(old, ddState.Status) && dd.Image != nil {
+		d.accountDownload(ctx, model.DownloadUsage{
+			DeploymentID: dd.DeploymentId,
+			DeviceID:     dd.DeviceId,
+			Downloaded:   dd.Image.Size,
+		})
+	}
+
 	if old != ddState.Status {
 		// fetch deployment stats and update deployment status
 		deployment, err := d.db.FindDeploymentByID(ctx, dd.DeploymentId)
@@ -1722,17 +2014,14 @@ func (d *Deployments) updateDeviceDeploymentStatus(
 		if deployment == nil {
 			return ErrModelDeploymentNotFound
 		}
-		beforeStatus := deployment.GetStatus()
-
-		deployment.Stats, err = d.db.UpdateStatsInc(ctx, dd.DeploymentId, old, ddState.Status)
+		newStatus, err := d.updateDeploymentStats(ctx, deployment, old, ddState.Status)
 		if err != nil {
 			return err
 		}
-		newStatus := deployment.GetStatus()
-		if beforeStatus != newStatus {
-			err = d.db.SetDeploymentStatus(ctx, dd.DeploymentId, newStatus, time.Now())
-			if err != nil {
-				return errors.Wrap(err, "failed to update deployment status")
+		if ddState.Status == model.DeviceDeploymentStatusFailure &&
+			newStatus != model.DeploymentStatusFinished {
+			if err := d.applyFailurePolicy(ctx, deployment); err != nil {
+				l.Error(errors.Wrap(err, "failed to apply the deployment failure policy"))
 			}
 		}
 	}
@@ -1759,6 +2048,50 @@ func (d *Deployments) updateDeviceDeploymentStatus(
 	return nil
 }
 
+// updateDeploymentStats moves a device between two statuses in the
+// deployment statistics and updates the deployment status accordingly.
+func (d *Deployments) updateDeploymentStats(
+	ctx context.Context,
+	deployment *model.Deployment,
+	from, to model.DeviceDeploymentStatus,
+) (model.DeploymentStatus, error) {
+	beforeStatus := deployment.GetStatus()
+
+	var err error
+	deployment.Stats, err = d.db.UpdateStatsInc(ctx, deployment.Id, from, to)
+	if err != nil {
+		return beforeStatus, err
+	}
+	newStatus := deployment.GetStatus()
+	if beforeStatus != newStatus {
+		err = d.db.SetDeploymentStatus(ctx, deployment.Id, newStatus, time.Now())
+		if err != nil {
+			return beforeStatus, errors.Wrap(err, "failed to update deployment status")
+		}
+	}
+	return newStatus, nil
+}
+
+// applyFailurePolicy aborts the deployment if the statistics exceed the
+// thresholds set by the deployment failure policy.
+func (d *Deployments) applyFailurePolicy(
+	ctx context.Context,
+	deployment *model.Deployment,
+) error {
+	if deployment.DeploymentConstructor == nil || deployment.FailurePolicy == nil {
+		return nil
+	}
+	reason := deployment.FailurePolicy.Evaluate(deployment.Stats)
+	if reason == "" {
+		return nil
+	}
+	log.FromContext(ctx).Warnf("aborting deployment %s: %s", deployment.Id, reason)
+	if err := d.db.SetDeploymentAbortReason(ctx, deployment.Id, reason); err != nil {
+		return errors.Wrap(err, "failed to record the abort reason")
+	}
+	return d.AbortDeployment(ctx, deployment.Id)
+}
+
 func (d *Deployments) GetDeploymentStats(ctx context.Context,
 	deploymentID string) (model.Stats, error) {
 
@@ -1932,6 +2265,10 @@ func (d *Deployments) SaveDeviceDeploymentLog(ctx context.Context, deviceID stri
 	if err := dlog.Validate(); err != nil {
 		return errors.Wrap(err, ErrStorageInvalidLog.Error())
 	}
+	if d.deploymentLogTTL > 0 {
+		expireAt := time.Now().Add(d.deploymentLogTTL)
+		dlog.ExpireAt = &expireAt
+	}
 
 	if has, err := d.HasDeploymentForDevice(ctx, deploymentID, deviceID); !has {
 		if err != nil {
@@ -1956,6 +2293,34 @@ func (d *Deployments) GetDeviceDeploymentLog(ctx context.Context,
 		deviceID, deploymentID)
 }
 
+// GetDeviceDeploymentAttemptLog returns the log uploaded by the device
+// during the given failed attempt, starting from 1.
+func (d *Deployments) GetDeviceDeploymentAttemptLog(ctx context.Context,
+	deviceID, deploymentID string, attempt int) (*model.DeploymentLog, error) {
+
+	return d.db.GetDeviceDeploymentAttemptLog(ctx,
+		deviceID, deploymentID, attempt)
+}
+
+// SearchDeviceDeploymentLogs finds the device deployment logs of the
+// deployment containing the searched string or regular expression.
+func (d *Deployments) SearchDeviceDeploymentLogs(
+	ctx context.Context,
+	search model.DeploymentLogSearch,
+) ([]model.DeploymentLogMatch, int, error) {
+	deployment, err := d.db.FindDeploymentByID(ctx, search.DeploymentID)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Searching for deployment by ID")
+	} else if deployment == nil {
+		return nil, 0, ErrModelDeploymentNotFound
+	}
+	matches, count, err := d.db.SearchDeviceDeploymentLogs(ctx, search)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to search the device deployment logs")
+	}
+	return matches, count, nil
+}
+
 func (d *Deployments) HasDeploymentForDevice(ctx context.Context,
 	deploymentID string, deviceID string) (bool, error) {
 	return d.db.HasDeploymentForDevice(ctx, deploymentID, deviceID)
@@ -2142,6 +2507,20 @@ func (d *Deployments) WithReporting(c reporting.Client) *Deployments {
 	return d
 }
 
+// WithDeploymentLogTTL sets the time after which the device deployment
+// logs are removed.
+func (d *Deployments) WithDeploymentLogTTL(ttl time.Duration) *Deployments {
+	d.deploymentLogTTL = ttl
+	return d
+}
+
+// WithMaxImageSize sets the maximum size of the artifacts assembled from
+// the parts of the resumable uploads.
+func (d *Deployments) WithMaxImageSize(size int64) *Deployments {
+	d.maxImageSize = size
+	return d
+}
+
 func (d *Deployments) haveReporting() bool {
 	return d.reportingClient != nil
 }
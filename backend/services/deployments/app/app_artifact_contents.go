@@ -0,0 +1,230 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mendersoftware/mender-artifact/areader"
+	"github.com/mendersoftware/mender-artifact/artifact"
+	"github.com/mendersoftware/mender-artifact/handlers"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+// GetArtifactContents returns the listing of the artifact payloads. The
+// artifact is streamed from the object storage and inspected on the first
+// request only, the result is cached on the image.
+func (d *Deployments) GetArtifactContents(
+	ctx context.Context,
+	id string,
+) (*model.ArtifactContents, error) {
+	image, err := d.db.FindImageByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "Searching for image with specified ID")
+	} else if image == nil {
+		return nil, ErrImageMetaNotFound
+	}
+	if image.Contents != nil {
+		return image.Contents, nil
+	}
+
+	ctx, err = d.contextWithStorageSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	artifactReader, err := d.objectStorage.GetObject(ctx,
+		model.ImagePathFromContext(ctx, id))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download the artifact")
+	}
+	defer artifactReader.Close()
+
+	contents, err := inspectArtifact(artifactReader)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrModelParsingArtifactFailed.Error())
+	}
+	now := time.Now()
+	contents.Inspected = &now
+	if err := d.db.SetImageContents(ctx, id, contents); err != nil {
+		return nil, errors.Wrap(err, "failed to save the artifact contents")
+	}
+	return contents, nil
+}
+
+func inspectArtifact(r io.Reader) (*model.ArtifactContents, error) {
+	inspector := &payloadInspector{
+		entries: make(map[int]map[string][]model.PayloadEntry),
+	}
+	aReader := areader.NewReader(r)
+	if err := aReader.ReadArtifactHeaders(); err != nil {
+		return nil, errors.Wrap(err, "reading artifact error")
+	}
+	installers := aReader.GetHandlers()
+	for _, p := range installers {
+		p.SetUpdateStorerProducer(inspector)
+	}
+	if err := aReader.ReadArtifactData(); err != nil {
+		return nil, errors.Wrap(err, "reading artifact error")
+	}
+
+	contents := &model.ArtifactContents{
+		Payloads:  make([]model.PayloadContents, 0, len(installers)),
+		Truncated: inspector.truncated,
+	}
+	for i := 0; i < len(installers); i++ {
+		p, ok := installers[i]
+		if !ok {
+			continue
+		}
+		metaData, err := p.GetUpdateMetaData()
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot get update metadata")
+		}
+		payload := model.PayloadContents{
+			MetaData: metaData,
+			Files:    []model.PayloadFile{},
+		}
+		if updateType := p.GetUpdateType(); updateType != nil {
+			payload.Type = *updateType
+		}
+		for _, f := range p.GetUpdateFiles() {
+			payload.Files = append(payload.Files, model.PayloadFile{
+				Name:     f.Name,
+				Size:     f.Size,
+				Checksum: string(f.Checksum),
+				Entries:  inspector.entries[i][f.Name],
+			})
+		}
+		contents.Payloads = append(contents.Payloads, payload)
+	}
+	return contents, nil
+}
+
+// payloadArchiveCompressor returns the compressor of the payload file if
+// the file is a tar archive.
+func payloadArchiveCompressor(name string) (artifact.Compressor, bool) {
+	if strings.HasSuffix(name, ".tgz") {
+		return artifact.NewCompressorGzip(), true
+	}
+	comp, err := artifact.NewCompressorFromFileName(name)
+	if err != nil {
+		return nil, false
+	}
+	return comp, strings.HasSuffix(
+		strings.TrimSuffix(name, comp.GetFileExtension()), ".tar",
+	)
+}
+
+// payloadInspector lists the entries of the payload archives while the
+// artifact data is read.
+type payloadInspector struct {
+	entries   map[int]map[string][]model.PayloadEntry
+	count     int
+	truncated bool
+}
+
+func (p *payloadInspector) NewUpdateStorer(
+	_ *string,
+	payloadNum int,
+) (handlers.UpdateStorer, error) {
+	p.entries[payloadNum] = make(map[string][]model.PayloadEntry)
+	return &payloadStorer{
+		inspector:  p,
+		payloadNum: payloadNum,
+	}, nil
+}
+
+// listArchive returns the entries of the archive; an archive which cannot
+// be read is not listed.
+func (p *payloadInspector) listArchive(
+	r io.Reader,
+	comp artifact.Compressor,
+) []model.PayloadEntry {
+	zr, err := comp.NewReader(r)
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+
+	entries := []model.PayloadEntry{}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		} else if err != nil {
+			return nil
+		}
+		if p.count >= model.MaxArtifactContentsEntries {
+			p.truncated = true
+			return entries
+		}
+		entry := model.PayloadEntry{
+			Path:       hdr.Name,
+			Type:       model.PayloadEntryType(hdr.Typeflag),
+			Size:       hdr.Size,
+			Mode:       hdr.Mode,
+			LinkTarget: hdr.Linkname,
+		}
+		if entry.Type == model.PayloadEntryTypeFile {
+			hash := sha256.New()
+			if _, err := io.Copy(hash, tr); err != nil {
+				return nil
+			}
+			entry.Checksum = hex.EncodeToString(hash.Sum(nil))
+		}
+		entries = append(entries, entry)
+		p.count++
+	}
+}
+
+type payloadStorer struct {
+	inspector  *payloadInspector
+	payloadNum int
+}
+
+func (s *payloadStorer) Initialize(
+	artifact.HeaderInfoer,
+	artifact.HeaderInfoer,
+	handlers.ArtifactUpdateHeaders,
+) error {
+	return nil
+}
+
+func (s *payloadStorer) PrepareStoreUpdate() error {
+	return nil
+}
+
+func (s *payloadStorer) StoreUpdate(r io.Reader, info os.FileInfo) error {
+	if comp, ok := payloadArchiveCompressor(info.Name()); ok {
+		s.inspector.entries[s.payloadNum][info.Name()] = s.inspector.listArchive(r, comp)
+	}
+	// the reader verifies the checksum of the payload file once read
+	_, err := io.Copy(io.Discard, r)
+	return err
+}
+
+func (s *payloadStorer) FinishStoreUpdate() error {
+	return nil
+}
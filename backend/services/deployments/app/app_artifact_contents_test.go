@@ -0,0 +1,276 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mendersoftware/mender-artifact/artifact"
+	"github.com/mendersoftware/mender-artifact/awriter"
+	"github.com/mendersoftware/mender-artifact/handlers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	fs_mocks "github.com/mendersoftware/mender-server/services/deployments/storage/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeTestAppArtifact writes an "app" artifact with a compressed tar
+// archive and a plain file as payload files.
+func writeTestAppArtifact(t *testing.T) []byte {
+	dir := t.TempDir()
+
+	var archive bytes.Buffer
+	zw := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(zw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "etc/",
+		Typeflag: tar.TypeDir,
+		Mode:     0755,
+	}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "etc/app.conf",
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     3,
+	}))
+	_, err := tw.Write([]byte("foo"))
+	require.NoError(t, err)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "etc/app.link",
+		Typeflag: tar.TypeSymlink,
+		Linkname: "app.conf",
+		Mode:     0777,
+	}))
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "update.tar.gz"), archive.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "README"), []byte("bar"), 0644))
+
+	updateType := "app"
+	module := handlers.NewModuleImage(updateType)
+	require.NoError(t, module.SetUpdateFiles([]*handlers.DataFile{
+		{Name: filepath.Join(dir, "update.tar.gz")},
+		{Name: filepath.Join(dir, "README")},
+	}))
+
+	var buf bytes.Buffer
+	err = awriter.NewWriter(&buf, artifact.NewCompressorNone()).
+		WriteArtifact(&awriter.WriteArtifactArgs{
+			Format:  "mender",
+			Version: 3,
+			Devices: []string{"hammer"},
+			Name:    "app-1.0",
+			Updates: &awriter.Updates{Updates: []handlers.Composer{module}},
+			Depends: &artifact.ArtifactDepends{
+				CompatibleDevices: []string{"hammer"},
+			},
+			Provides: &artifact.ArtifactProvides{
+				ArtifactName: "app-1.0",
+			},
+			MetaData: map[string]interface{}{"container": "app"},
+			TypeInfoV3: &artifact.TypeInfoV3{
+				Type:             &updateType,
+				ArtifactProvides: artifact.TypeInfoProvides{},
+				ArtifactDepends:  artifact.TypeInfoDepends{},
+			},
+		})
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestInspectArtifact(t *testing.T) {
+	t.Parallel()
+
+	contents, err := inspectArtifact(bytes.NewReader(writeTestAppArtifact(t)))
+	require.NoError(t, err)
+	require.Len(t, contents.Payloads, 1)
+	assert.False(t, contents.Truncated)
+
+	payload := contents.Payloads[0]
+	assert.Equal(t, "app", payload.Type)
+	assert.Equal(t, map[string]interface{}{"container": "app"}, payload.MetaData)
+	require.Len(t, payload.Files, 2)
+
+	files := make(map[string]model.PayloadFile)
+	for _, f := range payload.Files {
+		files[f.Name] = f
+	}
+	readme := files["README"]
+	assert.Equal(t, int64(3), readme.Size)
+	assert.Equal(t, sha256Hex([]byte("bar")), readme.Checksum)
+	assert.Nil(t, readme.Entries)
+
+	archive := files["update.tar.gz"]
+	assert.NotEmpty(t, archive.Checksum)
+	assert.Equal(t, []model.PayloadEntry{{
+		Path: "etc/",
+		Type: model.PayloadEntryTypeDir,
+		Mode: 0755,
+	}, {
+		Path:     "etc/app.conf",
+		Type:     model.PayloadEntryTypeFile,
+		Size:     3,
+		Mode:     0644,
+		Checksum: sha256Hex([]byte("foo")),
+	}, {
+		Path:       "etc/app.link",
+		Type:       model.PayloadEntryTypeSymlink,
+		Mode:       0777,
+		LinkTarget: "app.conf",
+	}}, archive.Entries)
+}
+
+func TestInspectArtifactInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := inspectArtifact(bytes.NewReader([]byte("not an artifact")))
+	assert.Error(t, err)
+}
+
+func TestPayloadArchiveCompressor(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]bool{
+		"update.tar":     true,
+		"update.tar.gz":  true,
+		"update.tgz":     true,
+		"update.tar.xz":  true,
+		"update.tar.zst": true,
+		"rootfs.ext4":    false,
+		"update.gz":      false,
+		"README":         false,
+	}
+	for name, isArchive := range testCases {
+		_, ok := payloadArchiveCompressor(name)
+		assert.Equal(t, isArchive, ok, name)
+	}
+}
+
+func TestGetArtifactContents(t *testing.T) {
+	t.Parallel()
+
+	const imageID = "f826484e-1157-4109-af21-304e6d711560"
+	artifactData := writeTestAppArtifact(t)
+	cached := &model.ArtifactContents{
+		Payloads: []model.PayloadContents{{
+			Type:  "app",
+			Files: []model.PayloadFile{{Name: "README"}},
+		}},
+	}
+	testCases := map[string]struct {
+		Image   *model.Image
+		FindErr error
+
+		Download    bool
+		DownloadErr error
+		SaveErr     error
+
+		Contents *model.ArtifactContents
+		Error    error
+	}{
+		"ok": {
+			Image:    &model.Image{Id: imageID},
+			Download: true,
+		},
+		"ok, cached": {
+			Image:    &model.Image{Id: imageID, Contents: cached},
+			Contents: cached,
+		},
+		"error, not found": {
+			Error: ErrImageMetaNotFound,
+		},
+		"error, find image": {
+			FindErr: errors.New("some error"),
+			Error:   errors.New("Searching for image with specified ID: some error"),
+		},
+		"error, download": {
+			Image:       &model.Image{Id: imageID},
+			Download:    true,
+			DownloadErr: errors.New("some error"),
+			Error:       errors.New("failed to download the artifact: some error"),
+		},
+		"error, save": {
+			Image:    &model.Image{Id: imageID},
+			Download: true,
+			SaveErr:  errors.New("some error"),
+			Error:    errors.New("failed to save the artifact contents: some error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			fs := &fs_mocks.ObjectStorage{}
+			defer fs.AssertExpectations(t)
+
+			db.On("FindImageByID", ctx, imageID).
+				Return(tc.Image, tc.FindErr).Once()
+			if tc.Download {
+				db.On("GetStorageSettings", ctx).Return(nil, nil).Once()
+				var body io.ReadCloser
+				if tc.DownloadErr == nil {
+					body = io.NopCloser(bytes.NewReader(artifactData))
+					db.On("SetImageContents", h.ContextMatcher(), imageID,
+						mock.MatchedBy(func(contents *model.ArtifactContents) bool {
+							return contents.Inspected != nil &&
+								len(contents.Payloads) == 1
+						})).
+						Return(tc.SaveErr).Once()
+				}
+				fs.On("GetObject", h.ContextMatcher(), imageID).
+					Return(body, tc.DownloadErr).Once()
+			}
+
+			ds := NewDeployments(db, fs, 0, false)
+			contents, err := ds.GetArtifactContents(ctx, imageID)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+				return
+			}
+			assert.NoError(t, err)
+			if tc.Contents != nil {
+				assert.Equal(t, tc.Contents, contents)
+			} else if assert.NotNil(t, contents) {
+				assert.Len(t, contents.Payloads, 1)
+				assert.NotNil(t, contents.Inspected)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"path"
 	"time"
 
@@ -52,6 +53,15 @@ func (d *Deployments) cleanupExpiredLink(
 		if link.TenantID != "" {
 			objectPath = path.Join(link.TenantID, objectPath)
 		}
+		if link.MultipartUploadID != "" && link.Status == model.LinkStatusPending {
+			// discard the parts of the unfinished resumable upload
+			err = d.objectStorage.AbortMultipartUpload(
+				ctx, objectPath, link.MultipartUploadID,
+			)
+			if err != nil && !errors.Is(err, storage.ErrUploadNotFound) {
+				break
+			}
+		}
 		err = d.objectStorage.DeleteObject(ctx, objectPath)
 		if err != nil && err != storage.ErrObjectNotFound {
 			break
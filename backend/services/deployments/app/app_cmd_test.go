@@ -160,6 +160,47 @@ func TestCleanupExpiredUploads(t *testing.T) {
 		err := app.CleanupExpiredUploads(ctx, 0, jitter)
 		assert.NoError(t, err)
 	})
+	t.Run("single-shot/multipart upload", func(t *testing.T) {
+		const (
+			jitter = time.Second
+		)
+		ctx := context.Background()
+		link := model.UploadLink{
+			ArtifactID: "94a89c91-a905-4c3a-8bfa-62a362851c1f",
+			Link: model.Link{
+				TenantID: "123456789012345678901234",
+				Expire:   time.Now().Add(-time.Hour),
+			},
+			Status:            model.LinkStatusPending,
+			MultipartUploadID: "upload-id",
+		}
+		objectPath := path.Join(link.TenantID, link.ArtifactID) + fileSuffixTmp
+
+		database := new(mstore.DataStore)
+		objectStore := new(mstorage.ObjectStorage)
+		defer database.AssertExpectations(t)
+		defer objectStore.AssertExpectations(t)
+
+		database.On("FindUploadLinks", ctx, mock.Anything).
+			Return(NewArrayIterator([]model.UploadLink{link}), nil).
+			Once().
+			On("UpdateUploadIntentStatus",
+				ctx, link.ArtifactID, model.LinkStatusPending,
+				model.LinkStatusAborted|model.LinkStatusProcessedBit).
+			Return(nil).
+			Once()
+		objectStore.On("AbortMultipartUpload", ctx, objectPath, link.MultipartUploadID).
+			Return(storage.ErrUploadNotFound).
+			Once().
+			On("DeleteObject", ctx, objectPath).
+			Return(storage.ErrObjectNotFound).
+			Once()
+
+		app := NewDeployments(database, objectStore, 0, false)
+
+		err := app.CleanupExpiredUploads(ctx, 0, jitter)
+		assert.NoError(t, err)
+	})
 	t.Run("periodic/context canceled", func(t *testing.T) {
 		const (
 			jitter = time.Second
@@ -0,0 +1,177 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+const (
+	// ContinuousMissTTL is the time a device which does not match the
+	// filter of a continuous deployment is not matched against it again;
+	// the devices joining the group of the deployment are offered it at
+	// most this late.
+	ContinuousMissTTL = 5 * time.Minute
+	// maxContinuousMisses bounds the number of remembered misses
+	maxContinuousMisses = 100000
+)
+
+type continuousMissKey struct {
+	tenantID     string
+	deviceID     string
+	deploymentID string
+}
+
+// continuousMisses remembers the devices which did not match the filter of
+// a continuous deployment, so that their polls don't search the inventory
+// for the attributes of the device until the entry expires.
+type continuousMisses struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	expires map[continuousMissKey]time.Time
+}
+
+func newContinuousMisses(ttl time.Duration) *continuousMisses {
+	return &continuousMisses{
+		ttl:     ttl,
+		expires: make(map[continuousMissKey]time.Time),
+	}
+}
+
+func (c *continuousMisses) has(key continuousMissKey, now time.Time) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires, ok := c.expires[key]
+	return ok && now.Before(expires)
+}
+
+func (c *continuousMisses) add(key continuousMissKey, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.expires) >= maxContinuousMisses {
+		for k, expires := range c.expires {
+			if !now.Before(expires) {
+				delete(c.expires, k)
+			}
+		}
+		if len(c.expires) >= maxContinuousMisses {
+			c.expires = make(map[continuousMissKey]time.Time)
+		}
+	}
+	c.expires[key] = now.Add(c.ttl)
+}
+
+// getContinuousDeploymentForDevice returns the most recent active continuous
+// deployment the device belongs to and has not been offered yet, creating
+// the device deployment. Devices which installed a deployment newer than the
+// continuous deployment are not downgraded, unless the deployment allows it.
+// The device deployments and the inventory attributes of the device are
+// looked up once for all the continuous deployments, and the deployments
+// the device recently did not match are skipped without looking them up.
+func (d *Deployments) getContinuousDeploymentForDevice(
+	ctx context.Context,
+	deviceID string,
+	latest *model.DeviceDeployment,
+) (*model.Deployment, *model.DeviceDeployment, error) {
+	deployments, err := d.db.FindActiveContinuousDeployments(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to search for continuous deployments")
+	}
+	now := time.Now()
+	candidates := make([]*model.Deployment, 0, len(deployments))
+	candidateIDs := make([]string, 0, len(deployments))
+	for _, deploy := range deployments {
+		if deploy.Filter == nil || deploy.IsScheduled(now) ||
+			(!deploy.AllowDowngrade && deploy.IsDowngrade(latest)) {
+			continue
+		}
+		candidates = append(candidates, deploy)
+		candidateIDs = append(candidateIDs, deploy.Id)
+	}
+	if len(candidates) == 0 {
+		return nil, nil, nil
+	}
+	offeredIDs, err := d.db.FindDeviceDeploymentIDs(ctx, deviceID, candidateIDs)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Searching for the device deployments")
+	}
+	offered := make(map[string]bool, len(offeredIDs))
+	for _, id := range offeredIDs {
+		offered[id] = true
+	}
+	missKey := continuousMissKey{deviceID: deviceID}
+	if id := identity.FromContext(ctx); id != nil {
+		missKey.tenantID = id.Tenant
+	}
+
+	var (
+		attributes []model.DeviceAttribute
+		fetched    bool
+	)
+	for _, deploy := range candidates {
+		if offered[deploy.Id] {
+			// the device has already been offered the deployment
+			continue
+		}
+		missKey.deploymentID = deploy.Id
+		if d.continuousMisses.has(missKey, now) {
+			continue
+		}
+		if !fetched {
+			attributes, err = d.getDeviceAttributes(ctx, deviceID)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "error searching for devices")
+			}
+			fetched = true
+		}
+		if !deploy.Filter.Matches(attributes) {
+			d.continuousMisses.add(missKey, now)
+			continue
+		}
+		dependency, err := d.checkDeploymentDependency(ctx, deploy, deviceID)
+		if err != nil {
+			return nil, nil, err
+		} else if dependency == deploymentDependencyPending {
+			continue
+		}
+		deviceDeployment, err := d.createDeviceDeploymentWithStatus(ctx,
+			deviceID, deploy, model.DeviceDeploymentStatusPending)
+		if err != nil {
+			return nil, nil, err
+		}
+		if dependency == deploymentDependencyFailed {
+			if err := d.assignNoArtifact(ctx, deviceDeployment); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		return deploy, deviceDeployment, nil
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,385 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	inventory_mocks "github.com/mendersoftware/mender-server/services/deployments/client/inventory/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestGetContinuousDeploymentForDevice(t *testing.T) {
+	t.Parallel()
+
+	const (
+		tenantID = "123456789012345678901234"
+		deviceID = "device"
+	)
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	later := now.Add(time.Hour)
+
+	newContinuous := func(name string, allowDowngrade bool) *model.Deployment {
+		deployment, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+			Name:           name,
+			ArtifactName:   "release-1",
+			Group:          "factory",
+			Continuous:     true,
+			AllowDowngrade: allowDowngrade,
+		})
+		deployment.Created = &earlier
+		deployment.Filter = &model.Filter{
+			Terms: deploymentDeviceFilters(deployment.DeploymentConstructor),
+		}
+		return deployment
+	}
+	continuous := newContinuous("baseline", false)
+	downgrade := newContinuous("baseline", true)
+	scheduled := newContinuous("scheduled", false)
+	// newer deployment of a group the device is not in
+	newer := newContinuous("lab", false)
+	newer.Filter.Terms[1].Value = "lab"
+	scheduled.StartTime = &later
+
+	searchParams := model.SearchParams{
+		Page:      1,
+		PerPage:   1,
+		DeviceIDs: []string{deviceID},
+	}
+	device := func(group string) []model.InvDevice {
+		return []model.InvDevice{{
+			ID: deviceID,
+			Attributes: []model.DeviceAttribute{{
+				Scope: InventoryIdentityScope,
+				Name:  InventoryStatusAttributeName,
+				Value: InventoryStatusAccepted,
+			}, {
+				Scope: InventoryGroupScope,
+				Name:  InventoryGroupAttributeName,
+				Value: group,
+			}},
+		}}
+	}
+	testCases := map[string]struct {
+		Deployments []*model.Deployment
+		Latest      *model.DeviceDeployment
+		Mocks       func(db *mocks.DataStore, inv *inventory_mocks.Client)
+
+		Deployment *model.Deployment
+		Error      error
+	}{
+		"ok, device joined the group": {
+			Deployments: []*model.Deployment{continuous},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				db.On("FindDeviceDeploymentIDs", h.ContextMatcher(),
+					deviceID, []string{continuous.Id}).
+					Return([]string{}, nil).Once()
+				inv.On("Search", h.ContextMatcher(), tenantID, searchParams).
+					Return(device("factory"), 1, nil).Once()
+				db.On("GetDeviceDeployment", h.ContextMatcher(),
+					continuous.Id, deviceID, true).
+					Return(nil, mongo.ErrStorageNotFound).Once()
+				db.On("InsertDeviceDeployment", h.ContextMatcher(),
+					mock.MatchedBy(func(dd *model.DeviceDeployment) bool {
+						return dd.DeploymentId == continuous.Id &&
+							dd.DeviceId == deviceID &&
+							dd.Status == model.DeviceDeploymentStatusPending
+					}), true).
+					Return(nil).Once()
+			},
+			Deployment: continuous,
+		},
+		"ok, device not in the group": {
+			Deployments: []*model.Deployment{continuous},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				db.On("FindDeviceDeploymentIDs", h.ContextMatcher(),
+					deviceID, []string{continuous.Id}).
+					Return([]string{}, nil).Once()
+				inv.On("Search", h.ContextMatcher(), tenantID, searchParams).
+					Return(device("lab"), 1, nil).Once()
+			},
+		},
+		"ok, device not in the inventory": {
+			Deployments: []*model.Deployment{continuous},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				db.On("FindDeviceDeploymentIDs", h.ContextMatcher(),
+					deviceID, []string{continuous.Id}).
+					Return([]string{}, nil).Once()
+				inv.On("Search", h.ContextMatcher(), tenantID, searchParams).
+					Return([]model.InvDevice{}, 0, nil).Once()
+			},
+		},
+		"ok, device already offered the deployment": {
+			Deployments: []*model.Deployment{continuous},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				db.On("FindDeviceDeploymentIDs", h.ContextMatcher(),
+					deviceID, []string{continuous.Id}).
+					Return([]string{continuous.Id}, nil).Once()
+			},
+		},
+		"ok, device searched once for more deployments": {
+			Deployments: []*model.Deployment{newer, continuous},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				db.On("FindDeviceDeploymentIDs", h.ContextMatcher(),
+					deviceID, []string{newer.Id, continuous.Id}).
+					Return([]string{}, nil).Once()
+				inv.On("Search", h.ContextMatcher(), tenantID, searchParams).
+					Return(device("factory"), 1, nil).Once()
+				db.On("GetDeviceDeployment", h.ContextMatcher(),
+					continuous.Id, deviceID, true).
+					Return(nil, mongo.ErrStorageNotFound).Once()
+				db.On("InsertDeviceDeployment", h.ContextMatcher(),
+					mock.AnythingOfType("*model.DeviceDeployment"), true).
+					Return(nil).Once()
+			},
+			Deployment: continuous,
+		},
+		"ok, scheduled": {
+			Deployments: []*model.Deployment{scheduled},
+			Mocks:       func(db *mocks.DataStore, inv *inventory_mocks.Client) {},
+		},
+		"ok, no downgrade": {
+			Deployments: []*model.Deployment{continuous},
+			Latest: &model.DeviceDeployment{
+				Created: &now,
+				Status:  model.DeviceDeploymentStatusSuccess,
+			},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {},
+		},
+		"ok, downgrade allowed": {
+			Deployments: []*model.Deployment{downgrade},
+			Latest: &model.DeviceDeployment{
+				Created: &now,
+				Status:  model.DeviceDeploymentStatusSuccess,
+			},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				db.On("FindDeviceDeploymentIDs", h.ContextMatcher(),
+					deviceID, []string{downgrade.Id}).
+					Return([]string{}, nil).Once()
+				inv.On("Search", h.ContextMatcher(), tenantID, searchParams).
+					Return(device("factory"), 1, nil).Once()
+				db.On("GetDeviceDeployment", h.ContextMatcher(),
+					downgrade.Id, deviceID, true).
+					Return(nil, mongo.ErrStorageNotFound).Once()
+				db.On("InsertDeviceDeployment", h.ContextMatcher(),
+					mock.AnythingOfType("*model.DeviceDeployment"), true).
+					Return(nil).Once()
+			},
+			Deployment: downgrade,
+		},
+		"error, inventory": {
+			Deployments: []*model.Deployment{continuous},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				db.On("FindDeviceDeploymentIDs", h.ContextMatcher(),
+					deviceID, []string{continuous.Id}).
+					Return([]string{}, nil).Once()
+				inv.On("Search", h.ContextMatcher(), tenantID, searchParams).
+					Return(nil, -1, errors.New("connection refused")).Once()
+			},
+			Error: errors.New("error searching for devices: connection refused"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := identity.WithContext(context.Background(), &identity.Identity{
+				Tenant: tenantID,
+			})
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			inv := &inventory_mocks.Client{}
+			defer inv.AssertExpectations(t)
+
+			db.On("FindActiveContinuousDeployments", h.ContextMatcher()).
+				Return(tc.Deployments, nil).Once()
+			tc.Mocks(db, inv)
+
+			d := NewDeployments(db, nil, 0, false)
+			d.SetInventoryClient(inv)
+			deployment, deviceDeployment, err := d.getContinuousDeploymentForDevice(
+				ctx, deviceID, tc.Latest,
+			)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.Deployment, deployment)
+				if tc.Deployment != nil {
+					assert.NotNil(t, deviceDeployment)
+				} else {
+					assert.Nil(t, deviceDeployment)
+				}
+			}
+		})
+	}
+}
+
+func TestGetContinuousDeploymentForDeviceMiss(t *testing.T) {
+	t.Parallel()
+
+	const (
+		tenantID = "123456789012345678901234"
+		deviceID = "device"
+	)
+	ctx := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: tenantID,
+	})
+	earlier := time.Now().Add(-time.Hour)
+	continuous, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "baseline",
+		ArtifactName: "release-1",
+		Group:        "factory",
+		Continuous:   true,
+	})
+	continuous.Created = &earlier
+	continuous.Filter = &model.Filter{
+		Terms: deploymentDeviceFilters(continuous.DeploymentConstructor),
+	}
+	searchParams := model.SearchParams{
+		Page:      1,
+		PerPage:   1,
+		DeviceIDs: []string{deviceID},
+	}
+	device := func(group string) []model.InvDevice {
+		return []model.InvDevice{{
+			ID: deviceID,
+			Attributes: []model.DeviceAttribute{{
+				Scope: InventoryIdentityScope,
+				Name:  InventoryStatusAttributeName,
+				Value: InventoryStatusAccepted,
+			}, {
+				Scope: InventoryGroupScope,
+				Name:  InventoryGroupAttributeName,
+				Value: group,
+			}},
+		}}
+	}
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	inv := &inventory_mocks.Client{}
+	defer inv.AssertExpectations(t)
+	db.On("FindActiveContinuousDeployments", h.ContextMatcher()).
+		Return([]*model.Deployment{continuous}, nil)
+	db.On("FindDeviceDeploymentIDs", h.ContextMatcher(),
+		deviceID, []string{continuous.Id}).
+		Return([]string{}, nil)
+
+	d := NewDeployments(db, nil, 0, false)
+	d.SetInventoryClient(inv)
+
+	// the device is not in the group: the miss is remembered and the
+	// next poll does not search the inventory
+	inv.On("Search", h.ContextMatcher(), tenantID, searchParams).
+		Return(device("lab"), 1, nil).Once()
+	for i := 0; i < 2; i++ {
+		deployment, deviceDeployment, err := d.getContinuousDeploymentForDevice(
+			ctx, deviceID, nil,
+		)
+		assert.NoError(t, err)
+		assert.Nil(t, deployment)
+		assert.Nil(t, deviceDeployment)
+	}
+	inv.AssertNumberOfCalls(t, "Search", 1)
+
+	// once the miss expires the device which joined the group is matched
+	for key := range d.continuousMisses.expires {
+		d.continuousMisses.expires[key] = earlier
+	}
+	inv.On("Search", h.ContextMatcher(), tenantID, searchParams).
+		Return(device("factory"), 1, nil).Once()
+	db.On("GetDeviceDeployment", h.ContextMatcher(),
+		continuous.Id, deviceID, true).
+		Return(nil, mongo.ErrStorageNotFound).Once()
+	db.On("InsertDeviceDeployment", h.ContextMatcher(),
+		mock.AnythingOfType("*model.DeviceDeployment"), true).
+		Return(nil).Once()
+	deployment, deviceDeployment, err := d.getContinuousDeploymentForDevice(
+		ctx, deviceID, nil,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, continuous, deployment)
+	assert.NotNil(t, deviceDeployment)
+}
+
+func TestCreateContinuousDeployment(t *testing.T) {
+	t.Parallel()
+
+	const tenantID = "123456789012345678901234"
+	ctx := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: tenantID,
+	})
+	release := &model.Release{
+		Name: "release-1",
+		Artifacts: []model.Image{{
+			Id: "artifact",
+			ArtifactMeta: &model.ArtifactMeta{
+				Name:                  "release-1",
+				DeviceTypesCompatible: []string{"rpi4"},
+			},
+		}},
+	}
+	constructor := &model.DeploymentConstructor{
+		Name:       "baseline",
+		Release:    &model.DeploymentRelease{Name: "release-1"},
+		Group:      "factory",
+		Continuous: true,
+	}
+	filters := deploymentDeviceFilters(constructor)
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	inv := &inventory_mocks.Client{}
+	defer inv.AssertExpectations(t)
+
+	db.On("GetRelease", h.ContextMatcher(), "release-1").
+		Return(release, nil).Once()
+	// the group is empty: the devices join it later
+	inv.On("Search", h.ContextMatcher(), tenantID, model.SearchParams{
+		Page:    1,
+		PerPage: PerPageInventoryDevices,
+		Filters: filters,
+	}).Return([]model.InvDevice{}, 0, nil).Once()
+	db.On("ImagesByName", h.ContextMatcher(), "release-1").
+		Return([]*model.Image{&release.Artifacts[0]}, nil).Once()
+	db.On("InsertDeployment", h.ContextMatcher(),
+		mock.MatchedBy(func(deployment *model.Deployment) bool {
+			return deployment.IsContinuous() &&
+				deployment.MaxDevices == 0 &&
+				len(deployment.DeviceList) == 0 &&
+				assert.Equal(t, filters, deployment.Filter.Terms) &&
+				assert.Equal(t, []string{"factory"}, deployment.Groups)
+		})).
+		Return(nil).Once()
+
+	d := NewDeployments(db, nil, 0, false)
+	d.SetInventoryClient(inv)
+	id, err := d.CreateDeployment(ctx, constructor)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, id)
+}
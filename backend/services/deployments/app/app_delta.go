@@ -0,0 +1,133 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+var (
+	ErrDeltaNotRootfsImage = errors.New(
+		"delta artifacts can only be generated from root file system image artifacts",
+	)
+	ErrDeltaNoCommonDeviceType = errors.New(
+		"the source and target artifacts have no common device type",
+	)
+	ErrDeltaSameRootfsImage = errors.New(
+		"the source and target artifacts contain the same root file system image",
+	)
+)
+
+// GenerateDeltaImage starts the workflow generating a binary delta artifact
+// which updates the devices running the source artifact to the target
+// artifact. The delta artifact is stored under the target artifact name.
+func (d *Deployments) GenerateDeltaImage(
+	ctx context.Context,
+	req *model.GenerateDeltaRequest,
+) (string, error) {
+	source, err := d.getDeltaInputImage(ctx, req.SourceID)
+	if err != nil {
+		return "", err
+	}
+	target, err := d.getDeltaInputImage(ctx, req.TargetID)
+	if err != nil {
+		return "", err
+	}
+	if source.ArtifactMeta.Provides[model.ArtifactProvidesRootfsChecksum] ==
+		target.ArtifactMeta.Provides[model.ArtifactProvidesRootfsChecksum] {
+		return "", ErrDeltaSameRootfsImage
+	}
+	deviceTypes := commonDeviceTypes(
+		source.ArtifactMeta.DeviceTypesCompatible,
+		target.ArtifactMeta.DeviceTypesCompatible,
+	)
+	if len(deviceTypes) == 0 {
+		return "", ErrDeltaNoCommonDeviceType
+	}
+
+	ctx, err = d.contextWithStorageSettings(ctx)
+	if err != nil {
+		return "", err
+	}
+	msg := &model.GenerateDeltaMsg{
+		ArtifactID:            uuid.NewString(),
+		Name:                  target.ArtifactMeta.Name,
+		Description:           req.Description,
+		DeviceTypesCompatible: deviceTypes,
+	}
+	if id := identity.FromContext(ctx); id != nil {
+		msg.TenantID = id.Tenant
+	}
+	for _, input := range []struct {
+		image *model.Image
+		uri   *string
+	}{
+		{image: source, uri: &msg.GetSourceURI},
+		{image: target, uri: &msg.GetTargetURI},
+	} {
+		link, err := d.objectStorage.GetRequest(
+			ctx,
+			model.ImagePathFromContext(ctx, input.image.Id),
+			input.image.Name+model.ArtifactFileSuffix,
+			DefaultImageGenerationLinkExpire,
+			false,
+		)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to generate the artifact download link")
+		}
+		*input.uri = link.Uri
+	}
+
+	if err := d.workflowsClient.StartGenerateDeltaArtifact(ctx, msg); err != nil {
+		return "", err
+	}
+	return msg.ArtifactID, nil
+}
+
+func (d *Deployments) getDeltaInputImage(
+	ctx context.Context,
+	id string,
+) (*model.Image, error) {
+	image, err := d.db.FindImageByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "Searching for image with specified ID")
+	} else if image == nil {
+		return nil, ErrImageMetaNotFound
+	}
+	if image.ArtifactMeta == nil || !image.ArtifactMeta.IsRootfsImage() {
+		return nil, ErrDeltaNotRootfsImage
+	}
+	return image, nil
+}
+
+func commonDeviceTypes(a, b []string) []string {
+	common := []string{}
+	for _, deviceType := range a {
+		for _, other := range b {
+			if deviceType == other {
+				common = append(common, deviceType)
+				break
+			}
+		}
+	}
+	return common
+}
@@ -0,0 +1,272 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	workflows_mocks "github.com/mendersoftware/mender-server/services/deployments/client/workflows/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	fs_mocks "github.com/mendersoftware/mender-server/services/deployments/storage/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func makeDeltaTestImage(
+	id, name, updateType, checksum string,
+	deviceTypes []string,
+	depends map[string]interface{},
+) *model.Image {
+	return &model.Image{
+		Id: id,
+		ArtifactMeta: &model.ArtifactMeta{
+			Name:                  name,
+			DeviceTypesCompatible: deviceTypes,
+			Updates: []model.Update{{
+				TypeInfo: model.ArtifactUpdateTypeInfo{Type: &updateType},
+			}},
+			Provides: map[string]string{
+				model.ArtifactProvidesRootfsChecksum: checksum,
+			},
+			Depends: depends,
+		},
+	}
+}
+
+func TestGenerateDeltaImage(t *testing.T) {
+	t.Parallel()
+
+	const (
+		sourceID = "d1b1c7e4-5a6b-4c1d-8e0f-2a3b4c5d6e7f"
+		targetID = "0e6b1d6c-7f4a-4b2e-9c3d-1a2b3c4d5e6f"
+	)
+	source := makeDeltaTestImage(sourceID, "release-1", model.ArtifactUpdateTypeRootfs,
+		"abc", []string{"qemu", "rpi"}, nil)
+	target := makeDeltaTestImage(targetID, "release-2", model.ArtifactUpdateTypeRootfs,
+		"def", []string{"rpi", "bbb"}, nil)
+	sameChecksum := makeDeltaTestImage(targetID, "release-2", model.ArtifactUpdateTypeRootfs,
+		"abc", []string{"rpi"}, nil)
+	otherDevice := makeDeltaTestImage(targetID, "release-2", model.ArtifactUpdateTypeRootfs,
+		"def", []string{"bbb"}, nil)
+	delta := makeDeltaTestImage(targetID, "release-2", model.ArtifactUpdateTypeDelta,
+		"def", []string{"rpi"}, nil)
+
+	testCases := map[string]struct {
+		Source *model.Image
+		Target *model.Image
+
+		FindErr     error
+		GetLinkErr  error
+		WorkflowErr error
+
+		Error error
+	}{
+		"ok": {
+			Source: source,
+			Target: target,
+		},
+		"error, source not found": {
+			Error: ErrImageMetaNotFound,
+		},
+		"error, finding the source": {
+			FindErr: errors.New("internal error"),
+			Error:   errors.New("Searching for image with specified ID: internal error"),
+		},
+		"error, target not a rootfs image": {
+			Source: source,
+			Target: delta,
+			Error:  ErrDeltaNotRootfsImage,
+		},
+		"error, same rootfs image": {
+			Source: source,
+			Target: sameChecksum,
+			Error:  ErrDeltaSameRootfsImage,
+		},
+		"error, no common device type": {
+			Source: source,
+			Target: otherDevice,
+			Error:  ErrDeltaNoCommonDeviceType,
+		},
+		"error, link": {
+			Source:     source,
+			Target:     target,
+			GetLinkErr: errors.New("storage error"),
+			Error: errors.New(
+				"failed to generate the artifact download link: storage error",
+			),
+		},
+		"error, workflow": {
+			Source:      source,
+			Target:      target,
+			WorkflowErr: errors.New("workflow error"),
+			Error:       errors.New("workflow error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := identity.WithContext(context.Background(), &identity.Identity{
+				Tenant: "tenant",
+			})
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			fs := &fs_mocks.ObjectStorage{}
+			defer fs.AssertExpectations(t)
+			wf := &workflows_mocks.Client{}
+			defer wf.AssertExpectations(t)
+
+			db.On("FindImageByID", ctx, sourceID).
+				Return(tc.Source, tc.FindErr).Once()
+			if tc.Source != nil {
+				db.On("FindImageByID", ctx, targetID).
+					Return(tc.Target, nil).Once()
+			}
+			if tc.Error == nil || tc.GetLinkErr != nil || tc.WorkflowErr != nil {
+				db.On("GetStorageSettings", ctx).Return(nil, nil).Once()
+				fs.On("GetRequest",
+					h.ContextMatcher(),
+					"tenant/"+sourceID,
+					"release-1.mender",
+					DefaultImageGenerationLinkExpire,
+					false,
+				).Return(&model.Link{Uri: "source"}, tc.GetLinkErr).Once()
+			}
+			if tc.GetLinkErr == nil && (tc.Error == nil || tc.WorkflowErr != nil) {
+				fs.On("GetRequest",
+					h.ContextMatcher(),
+					"tenant/"+targetID,
+					"release-2.mender",
+					DefaultImageGenerationLinkExpire,
+					false,
+				).Return(&model.Link{Uri: "target"}, nil).Once()
+				wf.On("StartGenerateDeltaArtifact",
+					h.ContextMatcher(),
+					mock.MatchedBy(func(msg *model.GenerateDeltaMsg) bool {
+						return assert.NotEmpty(t, msg.ArtifactID) &&
+							assert.Equal(t, &model.GenerateDeltaMsg{
+								ArtifactID:            msg.ArtifactID,
+								Name:                  "release-2",
+								Description:           "description",
+								DeviceTypesCompatible: []string{"rpi"},
+								GetSourceURI:          "source",
+								GetTargetURI:          "target",
+								TenantID:              "tenant",
+							}, msg)
+					}),
+				).Return(tc.WorkflowErr).Once()
+			}
+
+			d := NewDeployments(db, fs, 0, false)
+			d.SetWorkflowsClient(wf)
+			id, err := d.GenerateDeltaImage(ctx, &model.GenerateDeltaRequest{
+				SourceID:    sourceID,
+				TargetID:    targetID,
+				Description: "description",
+			})
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+				assert.Empty(t, id)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, id)
+			}
+		})
+	}
+}
+
+func TestSelectArtifact(t *testing.T) {
+	t.Parallel()
+
+	full := makeDeltaTestImage("full", "release-2", model.ArtifactUpdateTypeRootfs,
+		"def", []string{"rpi"}, nil)
+	deltaFromOther := makeDeltaTestImage("delta-other", "release-2",
+		model.ArtifactUpdateTypeDelta, "def", []string{"rpi"},
+		map[string]interface{}{
+			"device_type":                        []interface{}{"rpi"},
+			model.ArtifactProvidesRootfsChecksum: "xyz",
+		})
+	deltaFromInstalled := makeDeltaTestImage("delta", "release-2",
+		model.ArtifactUpdateTypeDelta, "def", []string{"rpi"},
+		map[string]interface{}{
+			"device_type":                        []interface{}{"rpi"},
+			model.ArtifactProvidesRootfsChecksum: "abc",
+		})
+	fullFromOther := makeDeltaTestImage("full-other", "release-2",
+		model.ArtifactUpdateTypeRootfs, "def", []string{"rpi"},
+		map[string]interface{}{
+			"device_type":       []interface{}{"rpi"},
+			"bootloader.flavor": "legacy",
+		})
+	fullFromInstalled := makeDeltaTestImage("full-installed", "release-2",
+		model.ArtifactUpdateTypeRootfs, "def", []string{"rpi"},
+		map[string]interface{}{
+			"device_type":       []interface{}{"rpi"},
+			"bootloader.flavor": "uefi",
+		})
+	installed := &model.InstalledDeviceDeployment{
+		ArtifactName: "release-1",
+		DeviceType:   "rpi",
+		Provides: map[string]string{
+			model.ArtifactProvidesRootfsChecksum: "abc",
+			"bootloader.flavor":                  "uefi",
+		},
+	}
+
+	testCases := map[string]struct {
+		Artifacts []*model.Image
+		Expected  *model.Image
+	}{
+		"no artifacts": {},
+		"full image": {
+			Artifacts: []*model.Image{full},
+			Expected:  full,
+		},
+		"matching delta": {
+			Artifacts: []*model.Image{deltaFromOther, deltaFromInstalled, full},
+			Expected:  deltaFromInstalled,
+		},
+		"no matching delta": {
+			Artifacts: []*model.Image{deltaFromOther, full},
+			Expected:  full,
+		},
+		"only non-matching delta": {
+			Artifacts: []*model.Image{deltaFromOther},
+		},
+		"full image matching the provides": {
+			Artifacts: []*model.Image{fullFromOther, fullFromInstalled},
+			Expected:  fullFromInstalled,
+		},
+		"no full image matching the provides": {
+			Artifacts: []*model.Image{fullFromOther},
+			Expected:  fullFromOther,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.Expected, selectArtifact(tc.Artifacts, installed))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+)
+
+type deploymentDependency int
+
+const (
+	// deploymentDependencySatisfied: the device may start the deployment.
+	deploymentDependencySatisfied deploymentDependency = iota
+	// deploymentDependencyPending: the device did not complete the
+	// deployment to follow yet.
+	deploymentDependencyPending
+	// deploymentDependencyFailed: the device will never complete the
+	// deployment to follow successfully, the deployment is skipped.
+	deploymentDependencyFailed
+)
+
+// checkDeploymentDependency checks if the device completed the deployment
+// which the deployment follows. The already installed status counts as
+// success, since the device runs the artifact of the deployment to follow.
+func (d *Deployments) checkDeploymentDependency(
+	ctx context.Context,
+	deployment *model.Deployment,
+	deviceID string,
+) (deploymentDependency, error) {
+	if deployment.DeploymentConstructor == nil || deployment.AfterDeployment == "" {
+		return deploymentDependencySatisfied, nil
+	}
+	deviceDeployment, err := d.db.GetDeviceDeployment(
+		ctx, deployment.AfterDeployment, deviceID, true,
+	)
+	if err == mongo.ErrStorageNotFound {
+		// the device did not start the deployment to follow, it still
+		// may if the deployment is not finished
+		predecessor, err := d.db.FindDeploymentByID(ctx, deployment.AfterDeployment)
+		if err != nil {
+			return 0, errors.Wrap(err, "Searching for the deployment to follow")
+		}
+		if predecessor == nil || predecessor.Status == model.DeploymentStatusFinished {
+			return deploymentDependencyFailed, nil
+		}
+		return deploymentDependencyPending, nil
+	} else if err != nil {
+		return 0, errors.Wrap(err, "Searching for the device deployment to follow")
+	}
+	switch {
+	case deviceDeployment.Status == model.DeviceDeploymentStatusSuccess,
+		deviceDeployment.Status == model.DeviceDeploymentStatusAlreadyInst:
+		return deploymentDependencySatisfied, nil
+	case deviceDeployment.Status.Active():
+		return deploymentDependencyPending, nil
+	default:
+		return deploymentDependencyFailed, nil
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+)
+
+func TestCheckDeploymentDependency(t *testing.T) {
+	t.Parallel()
+
+	const (
+		deviceID      = "device"
+		predecessorID = "f826484e-1157-4109-af21-304e6d711560"
+	)
+
+	testCases := map[string]struct {
+		AfterDeployment string
+
+		DeviceDeployment    *model.DeviceDeployment
+		DeviceDeploymentErr error
+		Predecessor         *model.Deployment
+		PredecessorErr      error
+
+		Dependency deploymentDependency
+		Error      error
+	}{
+		"ok, no dependency": {
+			Dependency: deploymentDependencySatisfied,
+		},
+		"ok, success": {
+			AfterDeployment: predecessorID,
+			DeviceDeployment: &model.DeviceDeployment{
+				Status: model.DeviceDeploymentStatusSuccess,
+			},
+			Dependency: deploymentDependencySatisfied,
+		},
+		"ok, already installed": {
+			AfterDeployment: predecessorID,
+			DeviceDeployment: &model.DeviceDeployment{
+				Status: model.DeviceDeploymentStatusAlreadyInst,
+			},
+			Dependency: deploymentDependencySatisfied,
+		},
+		"pending, in progress": {
+			AfterDeployment: predecessorID,
+			DeviceDeployment: &model.DeviceDeployment{
+				Status: model.DeviceDeploymentStatusInstalling,
+			},
+			Dependency: deploymentDependencyPending,
+		},
+		"pending, not started": {
+			AfterDeployment:     predecessorID,
+			DeviceDeploymentErr: mongo.ErrStorageNotFound,
+			Predecessor: &model.Deployment{
+				Id:     predecessorID,
+				Status: model.DeploymentStatusInProgress,
+			},
+			Dependency: deploymentDependencyPending,
+		},
+		"failed, failure": {
+			AfterDeployment: predecessorID,
+			DeviceDeployment: &model.DeviceDeployment{
+				Status: model.DeviceDeploymentStatusFailure,
+			},
+			Dependency: deploymentDependencyFailed,
+		},
+		"failed, aborted": {
+			AfterDeployment: predecessorID,
+			DeviceDeployment: &model.DeviceDeployment{
+				Status: model.DeviceDeploymentStatusAborted,
+			},
+			Dependency: deploymentDependencyFailed,
+		},
+		"failed, finished without the device": {
+			AfterDeployment:     predecessorID,
+			DeviceDeploymentErr: mongo.ErrStorageNotFound,
+			Predecessor: &model.Deployment{
+				Id:     predecessorID,
+				Status: model.DeploymentStatusFinished,
+			},
+			Dependency: deploymentDependencyFailed,
+		},
+		"failed, deployment not found": {
+			AfterDeployment:     predecessorID,
+			DeviceDeploymentErr: mongo.ErrStorageNotFound,
+			Dependency:          deploymentDependencyFailed,
+		},
+		"error, device deployment": {
+			AfterDeployment:     predecessorID,
+			DeviceDeploymentErr: errors.New("connection refused"),
+			Error:               errors.New("connection refused"),
+		},
+		"error, deployment": {
+			AfterDeployment:     predecessorID,
+			DeviceDeploymentErr: mongo.ErrStorageNotFound,
+			PredecessorErr:      errors.New("connection refused"),
+			Error:               errors.New("connection refused"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			if tc.AfterDeployment != "" {
+				db.On("GetDeviceDeployment", ctx, tc.AfterDeployment, deviceID, true).
+					Return(tc.DeviceDeployment, tc.DeviceDeploymentErr)
+			}
+			if tc.DeviceDeploymentErr == mongo.ErrStorageNotFound {
+				db.On("FindDeploymentByID", ctx, tc.AfterDeployment).
+					Return(tc.Predecessor, tc.PredecessorErr)
+			}
+
+			deployment, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+				Name:            "foo",
+				ArtifactName:    "bar",
+				AfterDeployment: tc.AfterDeployment,
+			})
+			ds := &Deployments{db: db}
+			dependency, err := ds.checkDeploymentDependency(ctx, deployment, deviceID)
+			if tc.Error != nil {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.Error.Error())
+				}
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.Dependency, dependency)
+			}
+		})
+	}
+}
+
+func TestGetNewDeploymentForDeviceDependencyPending(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	const deviceID = "device"
+
+	predecessor, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "bootloader",
+		ArtifactName: "bootloader-2",
+	})
+	predecessor.Status = model.DeploymentStatusInProgress
+	deployment, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:            "rootfs",
+		ArtifactName:    "rootfs-2",
+		AfterDeployment: predecessor.Id,
+	})
+
+	db := mocks.DataStore{}
+	defer db.AssertExpectations(t)
+
+	db.On("FindLatestInactiveDeviceDeployment", ctx, deviceID).
+		Return(nil, nil)
+	db.On("FindNewerActiveDeployment", ctx, &time.Time{}, deviceID).
+		Return(deployment, nil).Once()
+	db.On("GetDeviceDeployment", ctx, predecessor.Id, deviceID, true).
+		Return(nil, mongo.ErrStorageNotFound).Once()
+	db.On("FindDeploymentByID", ctx, predecessor.Id).
+		Return(predecessor, nil).Once()
+	db.On("FindNewerActiveDeployment", ctx, deployment.Created, deviceID).
+		Return(nil, nil).Once()
+	db.On("FindActiveContinuousDeployments", ctx).
+		Return(nil, nil).Once()
+
+	ds := &Deployments{db: &db}
+	depl, deviceDeployment, err := ds.getNewDeploymentForDevice(ctx, deviceID)
+	assert.NoError(t, err)
+	assert.Nil(t, depl)
+	assert.Nil(t, deviceDeployment)
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+var ErrReleaseIncompatibleDeviceTypes = errors.New(
+	"The release has no artifact compatible with some of the targeted devices",
+)
+
+// resolveDeploymentRelease selects the release deployed by the deployment
+// and sets the artifact name of the deployment to the name of the release.
+func (d *Deployments) resolveDeploymentRelease(
+	ctx context.Context,
+	constructor *model.DeploymentConstructor,
+) (*model.Release, error) {
+	selector := constructor.Release
+	var release *model.Release
+	if selector.Name != "" {
+		var err error
+		release, err = d.db.GetRelease(ctx, selector.Name)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrReleaseNotFound
+		} else if err != nil {
+			return nil, errors.Wrap(err, "Finding the release")
+		}
+	} else {
+		releases, _, err := d.db.GetReleases(ctx, &model.ReleaseOrImageFilter{
+			Tags:    []string{string(selector.Tag)},
+			Sort:    "modified:" + model.SortDirectionDescending,
+			Page:    1,
+			PerPage: 1,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "Finding the release")
+		} else if len(releases) > 0 {
+			release = &releases[0]
+		}
+	}
+	if !selector.Matches(release) {
+		return nil, ErrReleaseNotFound
+	}
+	constructor.ArtifactName = release.Name
+	return release, nil
+}
+
+// checkReleaseCompatibility rejects the release if some of the device
+// types of the targeted devices, as reported to the inventory, have no
+// compatible artifact in the release.
+func (d *Deployments) checkReleaseCompatibility(
+	ctx context.Context,
+	release *model.Release,
+	constructor *model.DeploymentConstructor,
+) error {
+	var deviceTypes []string
+	collect := func(device model.InvDevice) {
+		if installed := device.Installed(); installed != nil && installed.DeviceType != "" {
+			deviceTypes = append(deviceTypes, installed.DeviceType)
+		}
+	}
+	var err error
+	if len(constructor.Devices) > 0 {
+		err = d.previewDeviceList(ctx, constructor.Devices, collect,
+			func(model.DevicePreview) {})
+	} else {
+		err = d.previewDeviceSearch(ctx, deploymentDeviceFilters(constructor), collect)
+	}
+	if err != nil {
+		return err
+	}
+	if incompatible := release.IncompatibleDeviceTypes(deviceTypes); len(incompatible) > 0 {
+		return errors.Wrapf(ErrReleaseIncompatibleDeviceTypes,
+			"device types %s", strings.Join(incompatible, ", "))
+	}
+	return nil
+}
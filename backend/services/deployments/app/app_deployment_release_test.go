@@ -0,0 +1,303 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	inventory_mocks "github.com/mendersoftware/mender-server/services/deployments/client/inventory/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestResolveDeploymentRelease(t *testing.T) {
+	t.Parallel()
+
+	release := &model.Release{
+		Name: "release-1",
+		Tags: model.Tags{"stable"},
+	}
+	testCases := map[string]struct {
+		Selector model.DeploymentRelease
+		Store    func(db *mocks.DataStore)
+
+		Release *model.Release
+		Error   error
+	}{
+		"ok, by name": {
+			Selector: model.DeploymentRelease{Name: "release-1"},
+			Store: func(db *mocks.DataStore) {
+				db.On("GetRelease", h.ContextMatcher(), "release-1").
+					Return(release, nil)
+			},
+			Release: release,
+		},
+		"ok, by name and tag": {
+			Selector: model.DeploymentRelease{Name: "release-1", Tag: "stable"},
+			Store: func(db *mocks.DataStore) {
+				db.On("GetRelease", h.ContextMatcher(), "release-1").
+					Return(release, nil)
+			},
+			Release: release,
+		},
+		"ok, by tag": {
+			Selector: model.DeploymentRelease{Tag: "stable"},
+			Store: func(db *mocks.DataStore) {
+				db.On("GetReleases", h.ContextMatcher(), &model.ReleaseOrImageFilter{
+					Tags:    []string{"stable"},
+					Sort:    "modified:desc",
+					Page:    1,
+					PerPage: 1,
+				}).Return([]model.Release{*release}, 1, nil)
+			},
+			Release: release,
+		},
+		"error, release not found": {
+			Selector: model.DeploymentRelease{Name: "release-2"},
+			Store: func(db *mocks.DataStore) {
+				db.On("GetRelease", h.ContextMatcher(), "release-2").
+					Return(nil, store.ErrNotFound)
+			},
+			Error: ErrReleaseNotFound,
+		},
+		"error, release not tagged": {
+			Selector: model.DeploymentRelease{Name: "release-1", Tag: "beta"},
+			Store: func(db *mocks.DataStore) {
+				db.On("GetRelease", h.ContextMatcher(), "release-1").
+					Return(release, nil)
+			},
+			Error: ErrReleaseNotFound,
+		},
+		"error, no release with the tag": {
+			Selector: model.DeploymentRelease{Tag: "beta"},
+			Store: func(db *mocks.DataStore) {
+				db.On("GetReleases", h.ContextMatcher(), mock.AnythingOfType(
+					"*model.ReleaseOrImageFilter",
+				)).Return([]model.Release{}, 0, nil)
+			},
+			Error: ErrReleaseNotFound,
+		},
+		"error, internal": {
+			Selector: model.DeploymentRelease{Name: "release-1"},
+			Store: func(db *mocks.DataStore) {
+				db.On("GetRelease", h.ContextMatcher(), "release-1").
+					Return(nil, errors.New("internal error"))
+			},
+			Error: errors.New("Finding the release: internal error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			tc.Store(&db)
+
+			d := NewDeployments(&db, nil, 0, false)
+			constructor := &model.DeploymentConstructor{
+				Name:    "foo",
+				Release: &tc.Selector,
+			}
+			res, err := d.resolveDeploymentRelease(context.Background(), constructor)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+				assert.Empty(t, constructor.ArtifactName)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.Release, res)
+				assert.Equal(t, tc.Release.Name, constructor.ArtifactName)
+			}
+		})
+	}
+}
+
+func TestCheckReleaseCompatibility(t *testing.T) {
+	t.Parallel()
+
+	const tenantID = "123456789012345678901234"
+	release := &model.Release{
+		Name: "release-1",
+		Artifacts: []model.Image{{
+			ArtifactMeta: &model.ArtifactMeta{
+				Name:                  "release-1",
+				DeviceTypesCompatible: []string{"rpi3"},
+			},
+		}, {
+			ArtifactMeta: &model.ArtifactMeta{
+				Name:                  "release-1",
+				DeviceTypesCompatible: []string{"rpi4"},
+			},
+		}},
+	}
+	testCases := map[string]struct {
+		Devices []model.InvDevice
+		Search  error
+
+		Error error
+	}{
+		"ok": {
+			Devices: []model.InvDevice{
+				previewInvDevice("1", "rpi3", "release-0"),
+				previewInvDevice("2", "rpi4", "release-0"),
+			},
+		},
+		"ok, device type unknown": {
+			Devices: []model.InvDevice{
+				previewInvDevice("1", "rpi3", "release-0"),
+				previewInvDevice("2", "", ""),
+			},
+		},
+		"error, incompatible device types": {
+			Devices: []model.InvDevice{
+				previewInvDevice("1", "qemu", "release-0"),
+				previewInvDevice("2", "rpi4", "release-0"),
+				previewInvDevice("3", "bbb", "release-0"),
+			},
+			Error: errors.New("device types bbb, qemu: " +
+				ErrReleaseIncompatibleDeviceTypes.Error()),
+		},
+		"error, inventory": {
+			Search: errors.New("connection refused"),
+			Error:  errors.New("error searching for devices: connection refused"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := identity.WithContext(context.Background(), &identity.Identity{
+				Tenant: tenantID,
+			})
+			deviceIDs := []string{"1", "2", "3"}
+			inv := &inventory_mocks.Client{}
+			defer inv.AssertExpectations(t)
+			inv.On("Search", h.ContextMatcher(), tenantID, model.SearchParams{
+				Page:      1,
+				PerPage:   len(deviceIDs),
+				DeviceIDs: deviceIDs,
+			}).Return(tc.Devices, len(tc.Devices), tc.Search)
+
+			d := NewDeployments(nil, nil, 0, false)
+			d.SetInventoryClient(inv)
+			err := d.checkReleaseCompatibility(ctx, release,
+				&model.DeploymentConstructor{Devices: deviceIDs})
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckReleaseCompatibilityGroup(t *testing.T) {
+	t.Parallel()
+
+	const tenantID = "123456789012345678901234"
+	release := &model.Release{
+		Name: "release-1",
+		Artifacts: []model.Image{{
+			ArtifactMeta: &model.ArtifactMeta{
+				Name:                  "release-1",
+				DeviceTypesCompatible: []string{"rpi3"},
+			},
+		}},
+	}
+	testCases := map[string]struct {
+		Group   string
+		Devices []model.InvDevice
+
+		Error error
+	}{
+		"ok, group": {
+			Group: "production",
+			Devices: []model.InvDevice{
+				previewInvDevice("1", "rpi3", "release-0"),
+			},
+		},
+		"error, group with incompatible device types": {
+			Group: "production",
+			Devices: []model.InvDevice{
+				previewInvDevice("1", "rpi3", "release-0"),
+				previewInvDevice("2", "rpi4", "release-0"),
+			},
+			Error: errors.New("device types rpi4: " +
+				ErrReleaseIncompatibleDeviceTypes.Error()),
+		},
+		"error, all devices with incompatible device types": {
+			Devices: []model.InvDevice{
+				previewInvDevice("1", "qemu", "release-0"),
+			},
+			Error: errors.New("device types qemu: " +
+				ErrReleaseIncompatibleDeviceTypes.Error()),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := identity.WithContext(context.Background(), &identity.Identity{
+				Tenant: tenantID,
+			})
+			filters := []model.FilterPredicate{{
+				Scope:     InventoryIdentityScope,
+				Attribute: InventoryStatusAttributeName,
+				Type:      "$eq",
+				Value:     InventoryStatusAccepted,
+			}}
+			if tc.Group != "" {
+				filters = append(filters, model.FilterPredicate{
+					Scope:     InventoryGroupScope,
+					Attribute: InventoryGroupAttributeName,
+					Type:      "$eq",
+					Value:     tc.Group,
+				})
+			}
+			inv := &inventory_mocks.Client{}
+			defer inv.AssertExpectations(t)
+			inv.On("Search", h.ContextMatcher(), tenantID, model.SearchParams{
+				Page:    1,
+				PerPage: PerPageInventoryDevices,
+				Filters: filters,
+			}).Return(tc.Devices, len(tc.Devices), nil)
+
+			d := NewDeployments(nil, nil, 0, false)
+			d.SetInventoryClient(inv)
+			err := d.checkReleaseCompatibility(ctx, release,
+				&model.DeploymentConstructor{
+					Group:      tc.Group,
+					AllDevices: tc.Group == "",
+				})
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+// ExportDeviceDeploymentsHistory returns an iterator over the deployment
+// history of the devices matching the filter; the devices of the group are
+// looked up in the inventory.
+func (d *Deployments) ExportDeviceDeploymentsHistory(
+	ctx context.Context,
+	filter model.DeviceDeploymentHistoryFilter,
+) (store.Iterator[model.DeviceDeploymentHistoryRecord], error) {
+	if filter.Group != "" {
+		filter.DeviceIDs = []string{}
+		err := d.previewDeviceSearch(ctx, []model.FilterPredicate{{
+			Scope:     InventoryGroupScope,
+			Attribute: InventoryGroupAttributeName,
+			Type:      "$eq",
+			Value:     filter.Group,
+		}}, func(device model.InvDevice) {
+			filter.DeviceIDs = append(filter.DeviceIDs, device.ID)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	it, err := d.db.ExportDeviceDeploymentsHistory(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to export the device deployments history")
+	}
+	return it, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	inventory_mocks "github.com/mendersoftware/mender-server/services/deployments/client/inventory/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestExportDeviceDeploymentsHistory(t *testing.T) {
+	t.Parallel()
+
+	const tenantID = "123456789012345678901234"
+	groupSearch := model.SearchParams{
+		Page:    1,
+		PerPage: PerPageInventoryDevices,
+		Filters: []model.FilterPredicate{{
+			Scope:     InventoryGroupScope,
+			Attribute: InventoryGroupAttributeName,
+			Type:      "$eq",
+			Value:     "regulated",
+		}},
+	}
+	records := []model.DeviceDeploymentHistoryRecord{{
+		DeviceID:       "device-1",
+		DeploymentID:   "f826484e-1157-4109-af21-304e6d711560",
+		DeploymentName: "Q1 update",
+		ArtifactName:   "release-1",
+		Status:         model.DeviceDeploymentStatusSuccess,
+	}}
+	testCases := map[string]struct {
+		Filter model.DeviceDeploymentHistoryFilter
+		Mocks  func(db *mocks.DataStore, inv *inventory_mocks.Client)
+
+		Error error
+	}{
+		"ok, devices": {
+			Filter: model.DeviceDeploymentHistoryFilter{
+				DeviceIDs: []string{"device-1"},
+			},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				db.On("ExportDeviceDeploymentsHistory", h.ContextMatcher(),
+					model.DeviceDeploymentHistoryFilter{
+						DeviceIDs: []string{"device-1"},
+					}).
+					Return(NewArrayIterator(records), nil).Once()
+			},
+		},
+		"ok, group": {
+			Filter: model.DeviceDeploymentHistoryFilter{
+				Group: "regulated",
+			},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				inv.On("Search", h.ContextMatcher(), tenantID, groupSearch).
+					Return([]model.InvDevice{{ID: "device-1"}, {ID: "device-2"}}, 2, nil).
+					Once()
+				db.On("ExportDeviceDeploymentsHistory", h.ContextMatcher(),
+					model.DeviceDeploymentHistoryFilter{
+						DeviceIDs: []string{"device-1", "device-2"},
+						Group:     "regulated",
+					}).
+					Return(NewArrayIterator(records), nil).Once()
+			},
+		},
+		"ok, empty group": {
+			Filter: model.DeviceDeploymentHistoryFilter{
+				Group: "regulated",
+			},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				inv.On("Search", h.ContextMatcher(), tenantID, groupSearch).
+					Return([]model.InvDevice{}, 0, nil).Once()
+				db.On("ExportDeviceDeploymentsHistory", h.ContextMatcher(),
+					model.DeviceDeploymentHistoryFilter{
+						DeviceIDs: []string{},
+						Group:     "regulated",
+					}).
+					Return(NewArrayIterator([]model.DeviceDeploymentHistoryRecord{}), nil).
+					Once()
+			},
+		},
+		"error, inventory": {
+			Filter: model.DeviceDeploymentHistoryFilter{
+				Group: "regulated",
+			},
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				inv.On("Search", h.ContextMatcher(), tenantID, groupSearch).
+					Return(nil, 0, errors.New("connection refused")).Once()
+			},
+			Error: errors.New("error searching for devices: connection refused"),
+		},
+		"error, store": {
+			Mocks: func(db *mocks.DataStore, inv *inventory_mocks.Client) {
+				db.On("ExportDeviceDeploymentsHistory", h.ContextMatcher(),
+					model.DeviceDeploymentHistoryFilter{}).
+					Return(nil, errors.New("connection refused")).Once()
+			},
+			Error: errors.New("failed to export the device deployments history: " +
+				"connection refused"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			inv := &inventory_mocks.Client{}
+			defer inv.AssertExpectations(t)
+			tc.Mocks(db, inv)
+
+			d := NewDeployments(db, nil, 0, false)
+			d.SetInventoryClient(inv)
+
+			ctx := identity.WithContext(context.Background(),
+				&identity.Identity{Tenant: tenantID})
+			it, err := d.ExportDeviceDeploymentsHistory(ctx, tc.Filter)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+				assert.Nil(t, it)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, it)
+			}
+		})
+	}
+}
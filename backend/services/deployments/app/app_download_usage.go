@@ -0,0 +1,87 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+)
+
+var (
+	ErrDownloadQuotaExceeded = errors.New("The monthly download quota is exceeded")
+)
+
+// GetDownloadUsage returns the artifact bytes served in the month to the
+// devices and the users, optionally restricted to a deployment or a device.
+func (d *Deployments) GetDownloadUsage(
+	ctx context.Context,
+	filter model.DownloadUsageFilter,
+) (*model.DownloadUsage, error) {
+	usage, err := d.db.GetDownloadUsage(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the download usage")
+	}
+	return usage, nil
+}
+
+// checkDownloadQuota returns ErrDownloadQuotaExceeded if serving an artifact
+// of the given size exceeds the monthly download quota of the tenant.
+func (d *Deployments) checkDownloadQuota(ctx context.Context, size int64) error {
+	limit, err := d.db.GetLimit(ctx, model.LimitDownload)
+	if err == mongo.ErrLimitNotFound {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to obtain limit from storage")
+	} else if limit.Value == 0 {
+		// no quota
+		return nil
+	}
+	usage, err := d.db.GetDownloadUsage(ctx, model.DownloadUsageFilter{
+		Month: model.DownloadUsageMonth(time.Now()),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get the download usage")
+	}
+	if uint64(usage.Served+size) > limit.Value {
+		return ErrDownloadQuotaExceeded
+	}
+	return nil
+}
+
+// accountDownload adds the usage to the download usage of the current month
+// and to the statistics of the deployment; failures are only logged, not to
+// fail serving the artifact.
+func (d *Deployments) accountDownload(ctx context.Context, usage model.DownloadUsage) {
+	l := log.FromContext(ctx)
+	usage.Month = model.DownloadUsageMonth(time.Now())
+	if err := d.db.IncrementDownloadUsage(ctx, usage); err != nil {
+		l.Errorf("failed to increment the download usage: %s", err.Error())
+	}
+	if usage.DeploymentID == "" {
+		return
+	}
+	if err := d.db.IncrementDeploymentDownloadSize(
+		ctx, usage.DeploymentID, usage.Served, usage.Downloaded,
+	); err != nil {
+		l.Errorf("failed to increment deployment download size: %s", err.Error())
+	}
+}
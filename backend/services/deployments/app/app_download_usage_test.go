@@ -0,0 +1,253 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	fs_mocks "github.com/mendersoftware/mender-server/services/deployments/storage/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestCheckDownloadQuota(t *testing.T) {
+	t.Parallel()
+
+	month := model.DownloadUsageMonth(time.Now())
+	testCases := map[string]struct {
+		Limit    *model.Limit
+		LimitErr error
+		Usage    *model.DownloadUsage
+		UsageErr error
+
+		Error error
+	}{
+		"ok, no limit": {
+			LimitErr: mongo.ErrLimitNotFound,
+		},
+		"ok, unlimited": {
+			Limit: &model.Limit{Name: model.LimitDownload},
+		},
+		"ok, below the quota": {
+			Limit: &model.Limit{Name: model.LimitDownload, Value: 1000},
+			Usage: &model.DownloadUsage{Month: month, Served: 900},
+		},
+		"error, quota exceeded": {
+			Limit: &model.Limit{Name: model.LimitDownload, Value: 1000},
+			Usage: &model.DownloadUsage{Month: month, Served: 901},
+			Error: ErrDownloadQuotaExceeded,
+		},
+		"error, limit": {
+			LimitErr: errors.New("connection refused"),
+			Error:    errors.New("failed to obtain limit from storage: connection refused"),
+		},
+		"error, usage": {
+			Limit:    &model.Limit{Name: model.LimitDownload, Value: 1000},
+			UsageErr: errors.New("connection refused"),
+			Error:    errors.New("failed to get the download usage: connection refused"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			db.On("GetLimit", h.ContextMatcher(), model.LimitDownload).
+				Return(tc.Limit, tc.LimitErr).Once()
+			if tc.Usage != nil || tc.UsageErr != nil {
+				db.On("GetDownloadUsage", h.ContextMatcher(),
+					model.DownloadUsageFilter{Month: month}).
+					Return(tc.Usage, tc.UsageErr).Once()
+			}
+
+			d := NewDeployments(db, nil, 0, false)
+			err := d.checkDownloadQuota(context.Background(), 100)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAccountDownload(t *testing.T) {
+	t.Parallel()
+
+	month := model.DownloadUsageMonth(time.Now())
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+
+	db.On("IncrementDownloadUsage", h.ContextMatcher(), model.DownloadUsage{
+		Month:        month,
+		DeploymentID: "deployment",
+		DeviceID:     "device",
+		Downloaded:   100,
+	}).Return(errors.New("connection refused")).Once()
+	db.On("IncrementDeploymentDownloadSize", h.ContextMatcher(),
+		"deployment", int64(0), int64(100)).Return(nil).Once()
+	db.On("IncrementDownloadUsage", h.ContextMatcher(), model.DownloadUsage{
+		Month:  month,
+		Served: 100,
+	}).Return(nil).Once()
+
+	d := NewDeployments(db, nil, 0, false)
+	d.accountDownload(context.Background(), model.DownloadUsage{
+		DeploymentID: "deployment",
+		DeviceID:     "device",
+		Downloaded:   100,
+	})
+	d.accountDownload(context.Background(), model.DownloadUsage{Served: 100})
+}
+
+func TestGetDeploymentInstructionsDownloadQuota(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	deployment, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "foo",
+		ArtifactName: "bar",
+	})
+	deviceDeployment := model.NewDeviceDeployment("device", deployment.Id)
+	deviceDeployment.Image = &model.Image{
+		Id:   "image",
+		Size: 100,
+		ArtifactMeta: &model.ArtifactMeta{
+			Name:                  "bar",
+			DeviceTypesCompatible: []string{"hammer"},
+		},
+	}
+	request := &model.DeploymentNextRequest{
+		DeviceProvides: &model.InstalledDeviceDeployment{
+			ArtifactName: "foo",
+			DeviceType:   "hammer",
+		},
+	}
+	month := model.DownloadUsageMonth(time.Now())
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		db := &mocks.DataStore{}
+		defer db.AssertExpectations(t)
+		fs := &fs_mocks.ObjectStorage{}
+		defer fs.AssertExpectations(t)
+
+		db.On("GetLimit", ctx, model.LimitDownload).
+			Return(&model.Limit{Name: model.LimitDownload, Value: 1000}, nil).Once()
+		db.On("GetDownloadUsage", ctx, model.DownloadUsageFilter{Month: month}).
+			Return(&model.DownloadUsage{Month: month, Served: 900}, nil).Once()
+		db.On("ListEdgeCaches", ctx).Return([]model.EdgeCache{}, nil).Once()
+		db.On("GetStorageSettings", ctx).Return(nil, nil).Once()
+		fs.On("GetRequest", h.ContextMatcher(), "image", "bar.mender",
+			DefaultUpdateDownloadLinkExpire, true,
+		).Return(&model.Link{Uri: "link"}, nil).Once()
+		db.On("IncrementDownloadUsage", h.ContextMatcher(), model.DownloadUsage{
+			Month:        month,
+			DeploymentID: deployment.Id,
+			DeviceID:     "device",
+			Served:       100,
+		}).Return(nil).Once()
+		db.On("IncrementDeploymentDownloadSize", h.ContextMatcher(),
+			deployment.Id, int64(100), int64(0)).Return(nil).Once()
+
+		ds := NewDeployments(db, fs, 0, false)
+		instructions, err := ds.getDeploymentInstructions(
+			ctx, deployment, deviceDeployment, request,
+		)
+		assert.NoError(t, err)
+		if assert.NotNil(t, instructions) {
+			assert.Equal(t, "link", instructions.Artifact.Source.Uri)
+		}
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		db := &mocks.DataStore{}
+		defer db.AssertExpectations(t)
+
+		db.On("GetLimit", ctx, model.LimitDownload).
+			Return(&model.Limit{Name: model.LimitDownload, Value: 1000}, nil).Once()
+		db.On("GetDownloadUsage", ctx, model.DownloadUsageFilter{Month: month}).
+			Return(&model.DownloadUsage{Month: month, Served: 950}, nil).Once()
+
+		ds := NewDeployments(db, nil, 0, false)
+		instructions, err := ds.getDeploymentInstructions(
+			ctx, deployment, deviceDeployment, request,
+		)
+		assert.NoError(t, err)
+		assert.Nil(t, instructions)
+	})
+
+	t.Run("ok, served again", func(t *testing.T) {
+		t.Parallel()
+
+		db := &mocks.DataStore{}
+		defer db.AssertExpectations(t)
+		fs := &fs_mocks.ObjectStorage{}
+		defer fs.AssertExpectations(t)
+
+		// the quota is neither checked nor the download accounted again
+		db.On("ListEdgeCaches", ctx).Return([]model.EdgeCache{}, nil).Once()
+		db.On("GetStorageSettings", ctx).Return(nil, nil).Once()
+		fs.On("GetRequest", h.ContextMatcher(), "image", "bar.mender",
+			DefaultUpdateDownloadLinkExpire, true,
+		).Return(&model.Link{Uri: "link"}, nil).Once()
+
+		downloading := *deviceDeployment
+		downloading.Status = model.DeviceDeploymentStatusDownloading
+
+		ds := NewDeployments(db, fs, 0, false)
+		instructions, err := ds.getDeploymentInstructions(
+			ctx, deployment, &downloading, request,
+		)
+		assert.NoError(t, err)
+		if assert.NotNil(t, instructions) {
+			assert.Equal(t, "link", instructions.Artifact.Source.Uri)
+		}
+		db.AssertNotCalled(t, "IncrementDownloadUsage", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDownloadLinkDownloadQuota(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	image := &model.Image{Id: "image", Size: 100}
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+
+	db.On("FindImageByID", ctx, "image").Return(image, nil).Once()
+	db.On("GetLimit", ctx, model.LimitDownload).
+		Return(&model.Limit{Name: model.LimitDownload, Value: 100}, nil).Once()
+	db.On("GetDownloadUsage", ctx, mock.AnythingOfType("model.DownloadUsageFilter")).
+		Return(&model.DownloadUsage{Served: 1}, nil).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	link, err := ds.DownloadLink(ctx, "image", time.Minute)
+	assert.ErrorIs(t, err, ErrDownloadQuotaExceeded)
+	assert.Nil(t, link)
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+const (
+	// EdgeCacheMaxAge is the time after the last report of an artifact
+	// when the cache is no longer considered to hold the artifact.
+	EdgeCacheMaxAge = 24 * time.Hour
+)
+
+var (
+	ErrEdgeCacheNotFound         = errors.New("Edge cache not found")
+	ErrEdgeCacheSignatureInvalid = errors.New("signature invalid")
+)
+
+func (d *Deployments) ListEdgeCaches(ctx context.Context) ([]model.EdgeCache, error) {
+	caches, err := d.db.ListEdgeCaches(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the edge caches")
+	}
+	for i := range caches {
+		caches[i].Secret = ""
+	}
+	return caches, nil
+}
+
+func (d *Deployments) GetEdgeCache(ctx context.Context, id string) (*model.EdgeCache, error) {
+	cache, err := d.db.GetEdgeCache(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the edge cache")
+	} else if cache == nil {
+		return nil, ErrEdgeCacheNotFound
+	}
+	cache.Secret = ""
+	return cache, nil
+}
+
+// AddEdgeCache registers the cache endpoint; the returned cache holds the
+// secret used to sign the download links, which is not returned again.
+func (d *Deployments) AddEdgeCache(
+	ctx context.Context,
+	req model.EdgeCacheRequest,
+) (*model.EdgeCache, error) {
+	cache, err := model.NewEdgeCache(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.db.InsertEdgeCache(ctx, cache); err != nil {
+		return nil, errors.Wrap(err, "failed to save the edge cache")
+	}
+	return cache, nil
+}
+
+func (d *Deployments) DeleteEdgeCache(ctx context.Context, id string) error {
+	err := d.db.DeleteEdgeCache(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrEdgeCacheNotFound
+	} else if err != nil {
+		return errors.Wrap(err, "failed to delete the edge cache")
+	}
+	return nil
+}
+
+// AuthenticateEdgeCache verifies the request of the cache signed with the
+// secret of the cache; the request parameters must be already validated.
+func (d *Deployments) AuthenticateEdgeCache(
+	ctx context.Context,
+	id string,
+	sig *model.RequestSignature,
+) error {
+	cache, err := d.db.GetEdgeCache(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the edge cache")
+	} else if cache == nil {
+		return ErrEdgeCacheNotFound
+	}
+	sig.Secret = []byte(cache.Secret)
+	if !sig.VerifyHMAC256() {
+		return ErrEdgeCacheSignatureInvalid
+	}
+	return nil
+}
+
+// ReportEdgeCacheArtifacts replaces the artifacts held by the cache.
+func (d *Deployments) ReportEdgeCacheArtifacts(
+	ctx context.Context,
+	id string,
+	report model.EdgeCacheReport,
+) error {
+	now := time.Now()
+	artifacts := make([]model.EdgeCacheArtifact, len(report.Artifacts))
+	for i, artifact := range report.Artifacts {
+		if artifact.Synced.IsZero() || artifact.Synced.After(now) {
+			artifact.Synced = now
+		}
+		artifacts[i] = artifact
+	}
+	err := d.db.SetEdgeCacheArtifacts(ctx, id, artifacts, now)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrEdgeCacheNotFound
+	} else if err != nil {
+		return errors.Wrap(err, "failed to save the edge cache artifacts")
+	}
+	return nil
+}
+
+// getEdgeCacheLink returns the download link of the artifact from the
+// nearest cache serving the device and holding the artifact, or nil if
+// there is no such cache.
+func (d *Deployments) getEdgeCacheLink(
+	ctx context.Context,
+	deviceID string,
+	artifactID string,
+	expire time.Time,
+) (*model.Link, error) {
+	caches, err := d.db.ListEdgeCaches(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the edge caches")
+	}
+	now := time.Now()
+	fresh := caches[:0]
+	for _, cache := range caches {
+		if cache.IsFresh(artifactID, now, EdgeCacheMaxAge) {
+			fresh = append(fresh, cache)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, nil
+	}
+	attributes, err := d.getDeviceAttributes(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	var tenantID string
+	if id := identity.FromContext(ctx); id != nil {
+		tenantID = id.Tenant
+	}
+	// the caches are ordered by priority: the first match is the nearest
+	for _, cache := range fresh {
+		if cache.Matches(attributes) {
+			log.FromContext(ctx).Debugf("serving the artifact %s to the device %s from the cache %s",
+				artifactID, deviceID, cache.ID)
+			return cache.DownloadLink(tenantID, artifactID, expire)
+		}
+	}
+	return nil, nil
+}
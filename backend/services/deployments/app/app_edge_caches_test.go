@@ -0,0 +1,417 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	inventory_mocks "github.com/mendersoftware/mender-server/services/deployments/client/inventory/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestListEdgeCaches(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("ListEdgeCaches", ctx).Return([]model.EdgeCache{
+		{ID: "1", Secret: "secret"},
+		{ID: "2", Secret: "secret"},
+	}, nil).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	caches, err := ds.ListEdgeCaches(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, caches, 2) {
+		assert.Empty(t, caches[0].Secret)
+		assert.Empty(t, caches[1].Secret)
+	}
+}
+
+func TestGetEdgeCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("GetEdgeCache", ctx, "1").
+		Return(&model.EdgeCache{ID: "1", Secret: "secret"}, nil).Once()
+	db.On("GetEdgeCache", ctx, "2").Return(nil, nil).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	cache, err := ds.GetEdgeCache(ctx, "1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, cache) {
+		assert.Empty(t, cache.Secret)
+	}
+
+	_, err = ds.GetEdgeCache(ctx, "2")
+	assert.ErrorIs(t, err, ErrEdgeCacheNotFound)
+}
+
+func TestAddEdgeCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("InsertEdgeCache", ctx, mock.AnythingOfType("*model.EdgeCache")).
+		Return(nil).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	cache, err := ds.AddEdgeCache(ctx, model.EdgeCacheRequest{
+		URL: "https://cache.plant-7.local",
+		Attribute: model.EdgeCacheAttribute{
+			Name:  "site",
+			Value: "plant-7",
+		},
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, cache) {
+		assert.NotEmpty(t, cache.Secret)
+		assert.Equal(t, model.EdgeCacheAttributeScopeDefault, cache.Attribute.Scope)
+	}
+}
+
+func TestDeleteEdgeCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("DeleteEdgeCache", ctx, "1").Return(nil).Once()
+	db.On("DeleteEdgeCache", ctx, "2").Return(store.ErrNotFound).Once()
+	db.On("DeleteEdgeCache", ctx, "3").Return(errors.New("internal error")).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	assert.NoError(t, ds.DeleteEdgeCache(ctx, "1"))
+	assert.ErrorIs(t, ds.DeleteEdgeCache(ctx, "2"), ErrEdgeCacheNotFound)
+	assert.EqualError(t, ds.DeleteEdgeCache(ctx, "3"),
+		"failed to delete the edge cache: internal error")
+}
+
+func TestAuthenticateEdgeCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("GetEdgeCache", ctx, "1").
+		Return(&model.EdgeCache{ID: "1", Secret: "secret"}, nil).Times(3)
+	db.On("GetEdgeCache", ctx, "2").Return(nil, nil).Once()
+
+	signed := func(secret string) *model.RequestSignature {
+		uri, _ := url.Parse("https://deployments.local" +
+			"/api/devices/v1/deployments/download/caches/1/artifacts?tenant_id=tenant")
+		sig := model.NewRequestSignature(&http.Request{
+			Method: http.MethodPut,
+			URL:    uri,
+		}, []byte(secret))
+		sig.SetExpire(time.Now().Add(time.Minute))
+		sig.PresignURL()
+		return model.NewRequestSignature(&http.Request{
+			Method: http.MethodPut,
+			URL:    uri,
+		}, nil)
+	}
+
+	ds := NewDeployments(db, nil, 0, false)
+	assert.NoError(t, ds.AuthenticateEdgeCache(ctx, "1", signed("secret")))
+	assert.ErrorIs(t, ds.AuthenticateEdgeCache(ctx, "1", signed("other")),
+		ErrEdgeCacheSignatureInvalid)
+
+	// the signature covers the method
+	sig := signed("secret")
+	sig.Method = http.MethodGet
+	assert.ErrorIs(t, ds.AuthenticateEdgeCache(ctx, "1", sig),
+		ErrEdgeCacheSignatureInvalid)
+
+	assert.ErrorIs(t, ds.AuthenticateEdgeCache(ctx, "2", signed("secret")),
+		ErrEdgeCacheNotFound)
+}
+
+func TestReportEdgeCacheArtifacts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	synced := time.Now().Add(-time.Hour)
+	report := model.EdgeCacheReport{
+		Artifacts: []model.EdgeCacheArtifact{
+			{ID: "a", Synced: synced},
+			{ID: "b"},
+			{ID: "c", Synced: time.Now().Add(time.Hour)},
+		},
+	}
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("SetEdgeCacheArtifacts", ctx, "1",
+		mock.MatchedBy(func(artifacts []model.EdgeCacheArtifact) bool {
+			now := time.Now()
+			return len(artifacts) == 3 &&
+				artifacts[0].Synced.Equal(synced) &&
+				!artifacts[1].Synced.IsZero() &&
+				!artifacts[2].Synced.After(now)
+		}),
+		mock.AnythingOfType("time.Time"),
+	).Return(nil).Once()
+	db.On("SetEdgeCacheArtifacts", ctx, "2",
+		[]model.EdgeCacheArtifact{},
+		mock.AnythingOfType("time.Time"),
+	).Return(store.ErrNotFound).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	assert.NoError(t, ds.ReportEdgeCacheArtifacts(ctx, "1", report))
+	assert.ErrorIs(t,
+		ds.ReportEdgeCacheArtifacts(ctx, "2", model.EdgeCacheReport{
+			Artifacts: []model.EdgeCacheArtifact{},
+		}),
+		ErrEdgeCacheNotFound,
+	)
+}
+
+func TestGetEdgeCacheLink(t *testing.T) {
+	t.Parallel()
+
+	const (
+		deviceID   = "device"
+		artifactID = "artifact"
+	)
+	now := time.Now()
+	expire := now.Add(time.Hour)
+	site := func(value string) []model.DeviceAttribute {
+		return []model.DeviceAttribute{{
+			Name:  "site",
+			Scope: InventoryAttributeScope,
+			Value: value,
+		}}
+	}
+	cache := func(id, value string, synced time.Time) model.EdgeCache {
+		return model.EdgeCache{
+			ID:  id,
+			URL: "https://" + id + ".local/cache",
+			Attribute: model.EdgeCacheAttribute{
+				Scope: InventoryAttributeScope,
+				Name:  "site",
+				Value: value,
+			},
+			Secret: "secret-" + id,
+			Artifacts: []model.EdgeCacheArtifact{{
+				ID:     artifactID,
+				Synced: synced,
+			}},
+		}
+	}
+
+	testCases := map[string]struct {
+		caches     []model.EdgeCache
+		listErr    error
+		searched   bool
+		attributes []model.DeviceAttribute
+		searchErr  error
+
+		cache string
+		err   string
+	}{
+		"no caches": {},
+		"stale cache": {
+			caches: []model.EdgeCache{
+				cache("plant-7", "plant-7", now.Add(-2*EdgeCacheMaxAge)),
+			},
+		},
+		"device not at the site": {
+			caches: []model.EdgeCache{
+				cache("plant-7", "plant-7", now),
+			},
+			searched:   true,
+			attributes: site("plant-8"),
+		},
+		"nearest cache": {
+			caches: []model.EdgeCache{
+				cache("plant-7", "plant-7", now),
+				cache("region", "plant-7", now),
+			},
+			searched:   true,
+			attributes: site("plant-7"),
+			cache:      "plant-7",
+		},
+		"nearest fresh cache": {
+			caches: []model.EdgeCache{
+				cache("plant-7", "plant-7", now.Add(-2*EdgeCacheMaxAge)),
+				cache("region", "plant-7", now),
+			},
+			searched:   true,
+			attributes: site("plant-7"),
+			cache:      "region",
+		},
+		"error, store": {
+			listErr: errors.New("internal error"),
+			err:     "failed to list the edge caches: internal error",
+		},
+		"error, inventory": {
+			caches: []model.EdgeCache{
+				cache("plant-7", "plant-7", now),
+			},
+			searched:  true,
+			searchErr: errors.New("inventory unavailable"),
+			err:       "inventory unavailable",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := identity.WithContext(context.Background(), &identity.Identity{
+				Tenant: "tenant",
+			})
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			db.On("ListEdgeCaches", ctx).Return(tc.caches, tc.listErr).Once()
+
+			inv := &inventory_mocks.Client{}
+			defer inv.AssertExpectations(t)
+			if tc.searched {
+				var devices []model.InvDevice
+				if tc.searchErr == nil {
+					devices = []model.InvDevice{{
+						ID:         deviceID,
+						Attributes: tc.attributes,
+					}}
+				}
+				inv.On("Search", ctx, "tenant", model.SearchParams{
+					Page:      1,
+					PerPage:   1,
+					DeviceIDs: []string{deviceID},
+				}).Return(devices, len(devices), tc.searchErr).Once()
+			}
+
+			ds := &Deployments{db: db, inventoryClient: inv}
+			link, err := ds.getEdgeCacheLink(ctx, deviceID, artifactID, expire)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.cache == "" {
+				assert.Nil(t, link)
+				return
+			}
+			if !assert.NotNil(t, link) {
+				return
+			}
+			uri, err := url.Parse(link.Uri)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.cache+".local", uri.Host)
+			assert.Equal(t, "/cache/artifacts/"+artifactID, uri.Path)
+			assert.Equal(t, "tenant", uri.Query().Get(model.ParamTenantID))
+
+			sig := model.NewRequestSignature(&http.Request{
+				Method: http.MethodGet,
+				URL:    uri,
+			}, []byte("secret-"+tc.cache))
+			assert.NoError(t, sig.Validate())
+			assert.True(t, sig.VerifyHMAC256())
+		})
+	}
+}
+
+func TestGetDeploymentInstructionsEdgeCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	deployment, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "foo",
+		ArtifactName: "bar",
+	})
+	deviceDeployment := model.NewDeviceDeployment("device", deployment.Id)
+	deviceDeployment.Image = &model.Image{
+		Id:   "image",
+		Size: 100,
+		ArtifactMeta: &model.ArtifactMeta{
+			Name:                  "bar",
+			DeviceTypesCompatible: []string{"hammer"},
+		},
+	}
+	request := &model.DeploymentNextRequest{
+		DeviceProvides: &model.InstalledDeviceDeployment{
+			ArtifactName: "foo",
+			DeviceType:   "hammer",
+		},
+	}
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	inv := &inventory_mocks.Client{}
+	defer inv.AssertExpectations(t)
+
+	db.On("GetLimit", ctx, model.LimitDownload).
+		Return(nil, mongo.ErrLimitNotFound).Once()
+	db.On("ListEdgeCaches", ctx).Return([]model.EdgeCache{{
+		ID:  "plant-7",
+		URL: "https://cache.plant-7.local",
+		Attribute: model.EdgeCacheAttribute{
+			Scope: InventoryAttributeScope,
+			Name:  "site",
+			Value: "plant-7",
+		},
+		Secret: "secret",
+		Artifacts: []model.EdgeCacheArtifact{{
+			ID:     "image",
+			Synced: time.Now(),
+		}},
+	}}, nil).Once()
+	inv.On("Search", ctx, "", mock.AnythingOfType("model.SearchParams")).
+		Return([]model.InvDevice{{
+			ID: "device",
+			Attributes: []model.DeviceAttribute{{
+				Name:  "site",
+				Scope: InventoryAttributeScope,
+				Value: "plant-7",
+			}},
+		}}, 1, nil).Once()
+	db.On("IncrementDownloadUsage", h.ContextMatcher(),
+		mock.AnythingOfType("model.DownloadUsage")).Return(nil).Once()
+	db.On("IncrementDeploymentDownloadSize", h.ContextMatcher(),
+		deployment.Id, int64(100), int64(0)).Return(nil).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	ds.inventoryClient = inv
+	instructions, err := ds.getDeploymentInstructions(
+		ctx, deployment, deviceDeployment, request,
+	)
+	assert.NoError(t, err)
+	if assert.NotNil(t, instructions) {
+		assert.Contains(t, instructions.Artifact.Source.Uri,
+			"https://cache.plant-7.local/artifacts/image?")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+)
+
+func TestSaveDeviceDeploymentLogTTL(t *testing.T) {
+	t.Parallel()
+
+	const (
+		deploymentID = "f826484e-1157-4109-af21-304e6d711560"
+		deviceID     = "device"
+	)
+	now := time.Now()
+	messages := []model.LogMessage{{
+		Timestamp: &now,
+		Level:     "info",
+		Message:   "installing",
+	}}
+	testCases := map[string]struct {
+		TTL time.Duration
+	}{
+		"ok, no expiration": {},
+		"ok, expiring": {
+			TTL: 24 * time.Hour,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("HasDeploymentForDevice", ctx, deploymentID, deviceID).
+				Return(true, nil).Once()
+			db.On("SaveDeviceDeploymentLog", ctx,
+				mock.MatchedBy(func(dlog model.DeploymentLog) bool {
+					if tc.TTL == 0 {
+						return dlog.ExpireAt == nil
+					}
+					return dlog.ExpireAt != nil &&
+						!dlog.ExpireAt.Before(now.Add(tc.TTL))
+				})).
+				Return(nil).Once()
+			db.On("UpdateDeviceDeploymentLogAvailability", ctx,
+				deviceID, deploymentID, true).
+				Return(nil).Once()
+
+			ds := NewDeployments(db, nil, 0, false).WithDeploymentLogTTL(tc.TTL)
+			err := ds.SaveDeviceDeploymentLog(ctx, deviceID, deploymentID, messages)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSearchDeviceDeploymentLogs(t *testing.T) {
+	t.Parallel()
+
+	const deploymentID = "f826484e-1157-4109-af21-304e6d711560"
+	search := model.DeploymentLogSearch{
+		DeploymentID: deploymentID,
+		Query:        "failed",
+		Page:         1,
+		PerPage:      20,
+	}
+	matches := []model.DeploymentLogMatch{{
+		DeviceID: "device",
+		Messages: []model.LogMessage{{Level: "error", Message: "failed"}},
+	}}
+	testCases := map[string]struct {
+		Deployment *model.Deployment
+		FindErr    error
+		SearchErr  error
+
+		Matches []model.DeploymentLogMatch
+		Count   int
+		Error   error
+	}{
+		"ok": {
+			Deployment: &model.Deployment{Id: deploymentID},
+			Matches:    matches,
+			Count:      1,
+		},
+		"error, deployment not found": {
+			Error: ErrModelDeploymentNotFound,
+		},
+		"error, find deployment": {
+			FindErr: errors.New("some error"),
+			Error:   errors.New("Searching for deployment by ID: some error"),
+		},
+		"error, search": {
+			Deployment: &model.Deployment{Id: deploymentID},
+			SearchErr:  errors.New("some error"),
+			Error: errors.New("failed to search the device deployment logs: " +
+				"some error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("FindDeploymentByID", ctx, deploymentID).
+				Return(tc.Deployment, tc.FindErr).Once()
+			if tc.Deployment != nil {
+				db.On("SearchDeviceDeploymentLogs", ctx, search).
+					Return(tc.Matches, tc.Count, tc.SearchErr).Once()
+			}
+
+			ds := NewDeployments(db, nil, 0, false)
+			matches, count, err := ds.SearchDeviceDeploymentLogs(ctx, search)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.Matches, matches)
+				assert.Equal(t, tc.Count, count)
+			}
+		})
+	}
+}
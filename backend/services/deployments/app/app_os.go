@@ -102,7 +102,7 @@ func (d *Deployments) assignArtifact(
 		}
 	} else {
 		// Select artifact for the device deployment from artifacts assigned to the deployment.
-		artifact, err = d.db.ImageByIdsAndDeviceType(
+		artifacts, err := d.db.ImagesByIdsAndDeviceType(
 			ctx,
 			deployment.Artifacts,
 			installed.DeviceType,
@@ -110,6 +110,7 @@ func (d *Deployments) assignArtifact(
 		if err != nil {
 			return errors.Wrap(err, "assigning artifact to device deployment")
 		}
+		artifact = selectArtifact(artifacts, installed)
 	}
 
 	// If not having appropriate image, set noartifact status
@@ -131,6 +132,34 @@ func (d *Deployments) assignArtifact(
 	return nil
 }
 
+// selectArtifact picks the artifact to install on the device: a delta
+// artifact applicable to the artifact currently installed on the device is
+// preferred, then the smallest full artifact whose depends match the
+// provides of the device, otherwise the smallest full artifact is picked.
+func selectArtifact(
+	artifacts []*model.Image,
+	installed *model.InstalledDeviceDeployment,
+) *model.Image {
+	var full, fullSatisfied *model.Image
+	for _, artifact := range artifacts {
+		if artifact.ArtifactMeta == nil || !artifact.ArtifactMeta.IsDelta() {
+			if full == nil {
+				full = artifact
+			}
+			if fullSatisfied == nil && artifact.ArtifactMeta != nil &&
+				artifact.ArtifactMeta.DependsSatisfied(installed) {
+				fullSatisfied = artifact
+			}
+		} else if artifact.ArtifactMeta.DependsSatisfied(installed) {
+			return artifact
+		}
+	}
+	if fullSatisfied != nil {
+		return fullSatisfied
+	}
+	return full
+}
+
 func (d *Deployments) assignNoArtifact(
 	ctx context.Context,
 	deviceDeployment *model.DeviceDeployment,
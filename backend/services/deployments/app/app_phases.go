@@ -0,0 +1,85 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+)
+
+var (
+	ErrPhaseNotFound     = errors.New("Deployment phase not found")
+	ErrPhaseNotResumable = errors.New(
+		"Deployment phase is already active or the deployment is finished",
+	)
+)
+
+// GetDeploymentPhases returns the phases of the deployment together with
+// their current status.
+func (d *Deployments) GetDeploymentPhases(
+	ctx context.Context,
+	deploymentID string,
+) ([]model.DeploymentPhaseInfo, error) {
+	deployment, err := d.db.FindDeploymentByID(ctx, deploymentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Searching for deployment by ID")
+	} else if deployment == nil {
+		return nil, ErrModelDeploymentNotFound
+	}
+	return deployment.PhasesInfo(time.Now()), nil
+}
+
+// ResumeDeploymentPhase starts the given phase immediately, regardless of
+// its start time or pause setting.
+func (d *Deployments) ResumeDeploymentPhase(
+	ctx context.Context,
+	deploymentID, phaseID string,
+) error {
+	deployment, err := d.db.FindDeploymentByID(ctx, deploymentID)
+	if err != nil {
+		return errors.Wrap(err, "Searching for deployment by ID")
+	} else if deployment == nil {
+		return ErrModelDeploymentNotFound
+	}
+
+	var phase *model.DeploymentPhase
+	for i := range deployment.Phases {
+		if deployment.Phases[i].Id == phaseID {
+			phase = &deployment.Phases[i]
+			break
+		}
+	}
+	if phase == nil {
+		return ErrPhaseNotFound
+	}
+	now := time.Now()
+	if !deployment.Active || phase.IsStarted(now) {
+		return ErrPhaseNotResumable
+	}
+
+	err = d.db.SetDeploymentPhaseResumed(ctx, deploymentID, phaseID, now)
+	if err == mongo.ErrStorageNotFound {
+		// the deployment finished or the phase was resumed concurrently
+		return ErrPhaseNotResumable
+	} else if err != nil {
+		return errors.Wrap(err, "failed to resume the deployment phase")
+	}
+	return nil
+}
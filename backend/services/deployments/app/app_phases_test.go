@@ -0,0 +1,178 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestResumeDeploymentPhase(t *testing.T) {
+	t.Parallel()
+
+	const (
+		deploymentID = "f826484e-1157-4109-af21-304e6d711561"
+		phaseID      = "0a7bd6bd-7ab1-4d8a-b8a8-2d4b4b34c8a7"
+	)
+	future := time.Now().Add(time.Hour)
+
+	testCases := map[string]struct {
+		Deployment    *model.Deployment
+		DeploymentErr error
+
+		CallResume bool
+		ResumeErr  error
+
+		Error error
+	}{
+		"ok": {
+			Deployment: &model.Deployment{
+				Id:     deploymentID,
+				Active: true,
+				DeploymentConstructor: &model.DeploymentConstructor{
+					Phases: []model.DeploymentPhase{
+						{Id: "first", BatchSize: 10},
+						{Id: phaseID, PauseUntilResumed: true},
+					},
+				},
+			},
+			CallResume: true,
+		},
+		"error, deployment not found": {
+			Error: ErrModelDeploymentNotFound,
+		},
+		"error, storage": {
+			DeploymentErr: errors.New("mongo: internal error"),
+			Error:         errors.New("Searching for deployment by ID: mongo: internal error"),
+		},
+		"error, phase not found": {
+			Deployment: &model.Deployment{
+				Id:                    deploymentID,
+				Active:                true,
+				DeploymentConstructor: &model.DeploymentConstructor{},
+			},
+			Error: ErrPhaseNotFound,
+		},
+		"error, phase already started": {
+			Deployment: &model.Deployment{
+				Id:     deploymentID,
+				Active: true,
+				DeploymentConstructor: &model.DeploymentConstructor{
+					Phases: []model.DeploymentPhase{{Id: phaseID}},
+				},
+			},
+			Error: ErrPhaseNotResumable,
+		},
+		"error, deployment finished": {
+			Deployment: &model.Deployment{
+				Id: deploymentID,
+				DeploymentConstructor: &model.DeploymentConstructor{
+					Phases: []model.DeploymentPhase{
+						{Id: phaseID, StartTime: &future},
+					},
+				},
+			},
+			Error: ErrPhaseNotResumable,
+		},
+		"error, resumed concurrently": {
+			Deployment: &model.Deployment{
+				Id:     deploymentID,
+				Active: true,
+				DeploymentConstructor: &model.DeploymentConstructor{
+					Phases: []model.DeploymentPhase{
+						{Id: phaseID, StartTime: &future},
+					},
+				},
+			},
+			CallResume: true,
+			ResumeErr:  mongo.ErrStorageNotFound,
+			Error:      ErrPhaseNotResumable,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			db := mocks.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("FindDeploymentByID", h.ContextMatcher(), deploymentID).
+				Return(tc.Deployment, tc.DeploymentErr)
+			if tc.CallResume {
+				db.On("SetDeploymentPhaseResumed",
+					h.ContextMatcher(), deploymentID, phaseID,
+					mock.AnythingOfType("time.Time")).
+					Return(tc.ResumeErr)
+			}
+
+			ds := &Deployments{db: &db}
+			err := ds.ResumeDeploymentPhase(context.Background(), deploymentID, phaseID)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetNewDeploymentForDevicePhased(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	const deviceID = "device"
+
+	deployment, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "foo",
+		ArtifactName: "bar",
+		Phases: []model.DeploymentPhase{
+			{BatchDevices: 1},
+			{PauseUntilResumed: true},
+		},
+	})
+	deployment.MaxDevices = 10
+	deviceCount := 1
+	deployment.DeviceCount = &deviceCount
+
+	db := mocks.DataStore{}
+	defer db.AssertExpectations(t)
+
+	db.On("FindLatestInactiveDeviceDeployment", ctx, deviceID).
+		Return(nil, nil)
+	db.On("FindNewerActiveDeployment", ctx, &time.Time{}, deviceID).
+		Return(deployment, nil).Once()
+	// the first phase is full: the device must not be scheduled
+	db.On("FindNewerActiveDeployment", ctx, deployment.Created, deviceID).
+		Return(nil, nil).Once()
+	db.On("FindActiveContinuousDeployments", ctx).
+		Return(nil, nil).Once()
+
+	ds := &Deployments{db: &db}
+	depl, deviceDeployment, err := ds.getNewDeploymentForDevice(ctx, deviceID)
+	assert.NoError(t, err)
+	assert.Nil(t, depl)
+	assert.Nil(t, deviceDeployment)
+}
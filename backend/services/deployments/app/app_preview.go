@@ -0,0 +1,225 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+// PreviewDeployment evaluates the deployment for each of the targeted
+// devices without creating the deployment. The devices are evaluated with
+// the inventory attributes the same way the artifacts are assigned when the
+// devices request the deployment.
+func (d *Deployments) PreviewDeployment(
+	ctx context.Context,
+	constructor *model.DeploymentConstructor,
+	query model.DeploymentPreviewQuery,
+) (*model.DeploymentPreview, error) {
+	if constructor == nil {
+		return nil, ErrModelMissingInput
+	}
+	if err := constructor.ValidateNew(); err != nil {
+		return nil, errors.Wrap(err, "Validating deployment")
+	}
+	if constructor.Release != nil {
+		if _, err := d.resolveDeploymentRelease(ctx, constructor); err != nil {
+			return nil, err
+		}
+	}
+	artifacts, err := d.db.ImagesByName(ctx, constructor.ArtifactName)
+	if err != nil {
+		return nil, errors.Wrap(err, "Finding artifact with given name")
+	} else if len(artifacts) == 0 {
+		return nil, ErrNoArtifact
+	}
+	// smaller artifacts are preferred, same as for the device deployments
+	sort.SliceStable(artifacts, func(i, j int) bool {
+		return artifacts[i].Size < artifacts[j].Size
+	})
+
+	preview := &model.DeploymentPreview{
+		Devices: []model.DevicePreview{},
+	}
+	skip := int((query.Page - 1) * query.PerPage)
+	add := func(device model.DevicePreview) {
+		preview.Counts.Add(device.Status)
+		if query.Status != "" && query.Status != device.Status {
+			return
+		}
+		if skip > 0 {
+			skip--
+		} else if len(preview.Devices) < int(query.PerPage) {
+			preview.Devices = append(preview.Devices, device)
+		}
+	}
+	previewDevice := func(device model.InvDevice) {
+		add(previewDeviceDeployment(device, artifacts, constructor.ForceInstallation))
+	}
+	if len(constructor.Devices) > 0 {
+		err = d.previewDeviceList(ctx, constructor.Devices, previewDevice, add)
+	} else {
+		err = d.previewDeviceSearch(ctx, deploymentDeviceFilters(constructor), previewDevice)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// previewDeviceList evaluates the devices listed in the deployment; the
+// devices missing in the inventory are unknown.
+func (d *Deployments) previewDeviceList(
+	ctx context.Context,
+	deviceIDs []string,
+	previewDevice func(model.InvDevice),
+	add func(model.DevicePreview),
+) error {
+	var tenantID string
+	if id := identity.FromContext(ctx); id != nil {
+		tenantID = id.Tenant
+	}
+	devices := make(map[string]model.InvDevice, len(deviceIDs))
+	for i := 0; i < len(deviceIDs); i += PerPageInventoryDevices {
+		batch := deviceIDs[i:min(i+PerPageInventoryDevices, len(deviceIDs))]
+		found, _, err := d.search(ctx, tenantID, model.SearchParams{
+			Page:      1,
+			PerPage:   len(batch),
+			DeviceIDs: batch,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error searching for devices")
+		}
+		for _, device := range found {
+			devices[device.ID] = device
+		}
+	}
+	for _, deviceID := range deviceIDs {
+		if device, ok := devices[deviceID]; ok {
+			previewDevice(device)
+		} else {
+			add(model.DevicePreview{
+				ID:     deviceID,
+				Status: model.DevicePreviewStatusUnknown,
+			})
+		}
+	}
+	return nil
+}
+
+// deploymentDeviceFilters returns the inventory filter selecting the accepted
+// devices of the group, or matching the device filter, of the deployment;
+// all the accepted devices are selected if neither is set.
+func deploymentDeviceFilters(constructor *model.DeploymentConstructor) []model.FilterPredicate {
+	filters := []model.FilterPredicate{{
+		Scope:     InventoryIdentityScope,
+		Attribute: InventoryStatusAttributeName,
+		Type:      "$eq",
+		Value:     InventoryStatusAccepted,
+	}}
+	if constructor.Group != "" {
+		filters = append(filters, model.FilterPredicate{
+			Scope:     InventoryGroupScope,
+			Attribute: InventoryGroupAttributeName,
+			Type:      "$eq",
+			Value:     constructor.Group,
+		})
+	}
+	return append(filters, constructor.DeviceFilter...)
+}
+
+// previewDeviceSearch evaluates the devices matching the filters.
+func (d *Deployments) previewDeviceSearch(
+	ctx context.Context,
+	filters []model.FilterPredicate,
+	previewDevice func(model.InvDevice),
+) error {
+	var tenantID string
+	if id := identity.FromContext(ctx); id != nil {
+		tenantID = id.Tenant
+	}
+	searchParams := model.SearchParams{
+		Page:    1,
+		PerPage: PerPageInventoryDevices,
+		Filters: filters,
+	}
+	var seen int
+	for {
+		devices, count, err := d.search(ctx, tenantID, searchParams)
+		if err != nil {
+			return errors.Wrap(err, "error searching for devices")
+		}
+		for _, device := range devices {
+			previewDevice(device)
+		}
+		seen += len(devices)
+		if len(devices) == 0 || seen >= count {
+			return nil
+		}
+		searchParams.Page++
+	}
+}
+
+// previewDeviceDeployment returns the expected outcome of the deployment
+// for the device.
+func previewDeviceDeployment(
+	device model.InvDevice,
+	artifacts []*model.Image,
+	forceInstallation bool,
+) model.DevicePreview {
+	preview := model.DevicePreview{
+		ID:     device.ID,
+		Status: model.DevicePreviewStatusUnknown,
+	}
+	installed := device.Installed()
+	if installed == nil {
+		return preview
+	}
+	preview.DeviceType = installed.DeviceType
+	preview.ArtifactName = installed.ArtifactName
+
+	// the artifacts are filtered by device type only, as when assigning
+	// the artifact to the device: the depends are left to selectArtifact
+	compatible := make([]*model.Image, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		if artifact.ArtifactMeta == nil ||
+			!slices.Contains(
+				artifact.ArtifactMeta.DeviceTypesCompatible,
+				installed.DeviceType,
+			) {
+			continue
+		}
+		compatible = append(compatible, artifact)
+	}
+	artifact := selectArtifact(compatible, installed)
+	switch {
+	case artifact == nil:
+		preview.Status = model.DevicePreviewStatusIncompatible
+	case !forceInstallation && artifact.ArtifactMeta.Name == installed.ArtifactName:
+		preview.Status = model.DevicePreviewStatusAlreadyInstalled
+		preview.ArtifactID = artifact.Id
+	default:
+		preview.Status = model.DevicePreviewStatusWillInstall
+		preview.ArtifactID = artifact.Id
+	}
+	return preview
+}
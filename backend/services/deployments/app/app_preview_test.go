@@ -0,0 +1,388 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	inventory_mocks "github.com/mendersoftware/mender-server/services/deployments/client/inventory/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func previewInvDevice(id, deviceType, artifactName string, provides ...string) model.InvDevice {
+	device := model.InvDevice{ID: id}
+	if deviceType != "" {
+		device.Attributes = append(device.Attributes, model.DeviceAttribute{
+			Name:  "device_type",
+			Value: deviceType,
+			Scope: model.AttrScopeInventory,
+		})
+	}
+	if artifactName != "" {
+		device.Attributes = append(device.Attributes, model.DeviceAttribute{
+			Name:  "artifact_name",
+			Value: artifactName,
+			Scope: model.AttrScopeInventory,
+		})
+	}
+	for i := 0; i+1 < len(provides); i += 2 {
+		device.Attributes = append(device.Attributes, model.DeviceAttribute{
+			Name:  provides[i],
+			Value: provides[i+1],
+			Scope: model.AttrScopeInventory,
+		})
+	}
+	return device
+}
+
+func TestPreviewDeviceDeployment(t *testing.T) {
+	t.Parallel()
+
+	rootfsType := model.ArtifactUpdateTypeRootfs
+	deltaType := model.ArtifactUpdateTypeDelta
+	full := &model.Image{
+		Id:   "full",
+		Size: 100,
+		ArtifactMeta: &model.ArtifactMeta{
+			Name:                  "release-2",
+			DeviceTypesCompatible: []string{"rpi4", "rpi3"},
+			Depends: map[string]interface{}{
+				"device_type": []interface{}{"rpi4", "rpi3"},
+			},
+			Updates: []model.Update{{
+				TypeInfo: model.ArtifactUpdateTypeInfo{Type: &rootfsType},
+			}},
+		},
+	}
+	delta := &model.Image{
+		Id:   "delta",
+		Size: 10,
+		ArtifactMeta: &model.ArtifactMeta{
+			Name:                  "release-2",
+			DeviceTypesCompatible: []string{"rpi4"},
+			Depends: map[string]interface{}{
+				"rootfs-image.checksum": "checksum-1",
+			},
+			Updates: []model.Update{{
+				TypeInfo: model.ArtifactUpdateTypeInfo{Type: &deltaType},
+			}},
+		},
+	}
+	conditional := &model.Image{
+		Id:   "conditional",
+		Size: 50,
+		ArtifactMeta: &model.ArtifactMeta{
+			Name:                  "release-2",
+			DeviceTypesCompatible: []string{"bbb"},
+			Depends: map[string]interface{}{
+				"artifact_name": "release-1",
+			},
+		},
+	}
+	artifacts := []*model.Image{delta, conditional, full}
+
+	testCases := map[string]struct {
+		Device            model.InvDevice
+		ForceInstallation bool
+
+		Expected model.DevicePreview
+	}{
+		"will install full": {
+			Device: previewInvDevice("1", "rpi3", "release-1"),
+			Expected: model.DevicePreview{
+				ID:           "1",
+				Status:       model.DevicePreviewStatusWillInstall,
+				DeviceType:   "rpi3",
+				ArtifactName: "release-1",
+				ArtifactID:   "full",
+			},
+		},
+		"will install delta": {
+			Device: previewInvDevice("1", "rpi4", "release-1",
+				"rootfs-image.checksum", "checksum-1"),
+			Expected: model.DevicePreview{
+				ID:           "1",
+				Status:       model.DevicePreviewStatusWillInstall,
+				DeviceType:   "rpi4",
+				ArtifactName: "release-1",
+				ArtifactID:   "delta",
+			},
+		},
+		"will install full, delta depends not satisfied": {
+			Device: previewInvDevice("1", "rpi4", "release-1",
+				"rootfs-image.checksum", "checksum-0"),
+			Expected: model.DevicePreview{
+				ID:           "1",
+				Status:       model.DevicePreviewStatusWillInstall,
+				DeviceType:   "rpi4",
+				ArtifactName: "release-1",
+				ArtifactID:   "full",
+			},
+		},
+		"already installed": {
+			Device: previewInvDevice("1", "rpi3", "release-2"),
+			Expected: model.DevicePreview{
+				ID:           "1",
+				Status:       model.DevicePreviewStatusAlreadyInstalled,
+				DeviceType:   "rpi3",
+				ArtifactName: "release-2",
+				ArtifactID:   "full",
+			},
+		},
+		"already installed, forced": {
+			Device:            previewInvDevice("1", "rpi3", "release-2"),
+			ForceInstallation: true,
+			Expected: model.DevicePreview{
+				ID:           "1",
+				Status:       model.DevicePreviewStatusWillInstall,
+				DeviceType:   "rpi3",
+				ArtifactName: "release-2",
+				ArtifactID:   "full",
+			},
+		},
+		"incompatible device type": {
+			Device: previewInvDevice("1", "x86", "release-1"),
+			Expected: model.DevicePreview{
+				ID:           "1",
+				Status:       model.DevicePreviewStatusIncompatible,
+				DeviceType:   "x86",
+				ArtifactName: "release-1",
+			},
+		},
+		"will install full, depends not satisfied": {
+			// the full artifact is assigned regardless of the depends,
+			// as when the device asks for the deployment
+			Device: previewInvDevice("1", "bbb", "release-0"),
+			Expected: model.DevicePreview{
+				ID:           "1",
+				Status:       model.DevicePreviewStatusWillInstall,
+				DeviceType:   "bbb",
+				ArtifactName: "release-0",
+				ArtifactID:   "conditional",
+			},
+		},
+		"unknown, no inventory": {
+			Device: previewInvDevice("1", "rpi3", ""),
+			Expected: model.DevicePreview{
+				ID:     "1",
+				Status: model.DevicePreviewStatusUnknown,
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			actual := previewDeviceDeployment(tc.Device, artifacts, tc.ForceInstallation)
+			assert.Equal(t, tc.Expected, actual)
+		})
+	}
+}
+
+func TestPreviewDeployment(t *testing.T) {
+	t.Parallel()
+
+	const tenantID = "123456789012345678901234"
+	artifacts := []*model.Image{{
+		Id: "artifact",
+		ArtifactMeta: &model.ArtifactMeta{
+			Name:                  "release-2",
+			DeviceTypesCompatible: []string{"rpi4"},
+		},
+	}}
+	devices := []model.InvDevice{
+		previewInvDevice("1", "rpi4", "release-1"),
+		previewInvDevice("2", "rpi4", "release-2"),
+		previewInvDevice("3", "x86", "release-1"),
+		previewInvDevice("4", "", ""),
+		previewInvDevice("5", "rpi4", "release-1"),
+	}
+
+	testCases := map[string]struct {
+		Constructor *model.DeploymentConstructor
+		Query       model.DeploymentPreviewQuery
+
+		Artifacts   []*model.Image
+		ArtifactErr error
+		Search      func(inv *inventory_mocks.Client)
+
+		Expected *model.DeploymentPreview
+		Error    error
+	}{
+		"ok, device list": {
+			Constructor: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				Devices:      []string{"1", "2", "3", "4", "6"},
+			},
+			Query:     model.DeploymentPreviewQuery{Page: 1, PerPage: 2},
+			Artifacts: artifacts,
+			Search: func(inv *inventory_mocks.Client) {
+				inv.On("Search", h.ContextMatcher(), tenantID, model.SearchParams{
+					Page:      1,
+					PerPage:   5,
+					DeviceIDs: []string{"1", "2", "3", "4", "6"},
+				}).Return(devices[:4], 4, nil)
+			},
+			Expected: &model.DeploymentPreview{
+				Counts: model.DeploymentPreviewCounts{
+					Total:            5,
+					WillInstall:      1,
+					AlreadyInstalled: 1,
+					Incompatible:     1,
+					Unknown:          2,
+				},
+				Devices: []model.DevicePreview{{
+					ID:           "1",
+					Status:       model.DevicePreviewStatusWillInstall,
+					DeviceType:   "rpi4",
+					ArtifactName: "release-1",
+					ArtifactID:   "artifact",
+				}, {
+					ID:           "2",
+					Status:       model.DevicePreviewStatusAlreadyInstalled,
+					DeviceType:   "rpi4",
+					ArtifactName: "release-2",
+					ArtifactID:   "artifact",
+				}},
+			},
+		},
+		"ok, group, filtered by status": {
+			Constructor: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				Group:        "production",
+			},
+			Query: model.DeploymentPreviewQuery{
+				Page:    2,
+				PerPage: 1,
+				Status:  model.DevicePreviewStatusWillInstall,
+			},
+			Artifacts: artifacts,
+			Search: func(inv *inventory_mocks.Client) {
+				filters := []model.FilterPredicate{{
+					Scope:     InventoryIdentityScope,
+					Attribute: InventoryStatusAttributeName,
+					Type:      "$eq",
+					Value:     InventoryStatusAccepted,
+				}, {
+					Scope:     InventoryGroupScope,
+					Attribute: InventoryGroupAttributeName,
+					Type:      "$eq",
+					Value:     "production",
+				}}
+				inv.On("Search", h.ContextMatcher(), tenantID, model.SearchParams{
+					Page:    1,
+					PerPage: PerPageInventoryDevices,
+					Filters: filters,
+				}).Return(devices[:3], len(devices), nil).
+					On("Search", h.ContextMatcher(), tenantID, model.SearchParams{
+						Page:    2,
+						PerPage: PerPageInventoryDevices,
+						Filters: filters,
+					}).Return(devices[3:], len(devices), nil)
+			},
+			Expected: &model.DeploymentPreview{
+				Counts: model.DeploymentPreviewCounts{
+					Total:            5,
+					WillInstall:      2,
+					AlreadyInstalled: 1,
+					Incompatible:     1,
+					Unknown:          1,
+				},
+				Devices: []model.DevicePreview{{
+					ID:           "5",
+					Status:       model.DevicePreviewStatusWillInstall,
+					DeviceType:   "rpi4",
+					ArtifactName: "release-1",
+					ArtifactID:   "artifact",
+				}},
+			},
+		},
+		"error, invalid constructor": {
+			Constructor: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+			},
+			Query: model.DeploymentPreviewQuery{Page: 1, PerPage: 20},
+			Error: model.ErrInvalidDeploymentDefinitionNoDevices,
+		},
+		"error, no artifact": {
+			Constructor: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				AllDevices:   true,
+			},
+			Query:     model.DeploymentPreviewQuery{Page: 1, PerPage: 20},
+			Artifacts: []*model.Image{},
+			Error:     ErrNoArtifact,
+		},
+		"error, inventory": {
+			Constructor: &model.DeploymentConstructor{
+				Name:         "foo",
+				ArtifactName: "release-2",
+				AllDevices:   true,
+			},
+			Query:     model.DeploymentPreviewQuery{Page: 1, PerPage: 20},
+			Artifacts: artifacts,
+			Search: func(inv *inventory_mocks.Client) {
+				inv.On("Search", h.ContextMatcher(), tenantID, mock.AnythingOfType("model.SearchParams")).
+					Return(nil, -1, errors.New("inventory unavailable"))
+			},
+			Error: errors.New("error searching for devices: inventory unavailable"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := identity.WithContext(context.Background(), &identity.Identity{
+				Tenant: tenantID,
+			})
+
+			ds := &mocks.DataStore{}
+			defer ds.AssertExpectations(t)
+			if tc.Artifacts != nil || tc.ArtifactErr != nil {
+				ds.On("ImagesByName", ctx, tc.Constructor.ArtifactName).
+					Return(tc.Artifacts, tc.ArtifactErr)
+			}
+			inv := &inventory_mocks.Client{}
+			defer inv.AssertExpectations(t)
+			if tc.Search != nil {
+				tc.Search(inv)
+			}
+
+			d := NewDeployments(ds, nil, 0, false)
+			d.SetInventoryClient(inv)
+			preview, err := d.PreviewDeployment(ctx, tc.Constructor, tc.Query)
+			if tc.Error != nil {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.Error.Error())
+				}
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.Expected, preview)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+// GetArtifactRetentionSettings returns the artifact retention policy of
+// the tenant.
+func (d *Deployments) GetArtifactRetentionSettings(
+	ctx context.Context,
+) (*model.ArtifactRetentionSettings, error) {
+	settings, err := d.db.GetArtifactRetentionSettings(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the artifact retention settings")
+	}
+	if settings == nil {
+		settings = &model.ArtifactRetentionSettings{}
+	}
+	return settings, nil
+}
+
+func (d *Deployments) SetArtifactRetentionSettings(
+	ctx context.Context,
+	settings *model.ArtifactRetentionSettings,
+) error {
+	if err := d.db.SetArtifactRetentionSettings(ctx, settings); err != nil {
+		return errors.Wrap(err, "failed to save the artifact retention settings")
+	}
+	return nil
+}
+
+// ApplyArtifactRetention removes the artifacts of the tenant according to
+// its retention policy; in dry-run mode the artifacts are only reported.
+func (d *Deployments) ApplyArtifactRetention(
+	ctx context.Context,
+	dryRun bool,
+) (*model.ArtifactRetentionReport, error) {
+	report := &model.ArtifactRetentionReport{
+		DryRun:    dryRun,
+		Artifacts: []model.ArtifactRetentionCandidate{},
+	}
+	if id := identity.FromContext(ctx); id != nil {
+		report.TenantID = id.Tenant
+	}
+	settings, err := d.GetArtifactRetentionSettings(ctx)
+	if err != nil {
+		return nil, err
+	} else if !settings.Enabled() {
+		return report, nil
+	}
+
+	images, _, err := d.db.ListImages(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Searching for image metadata")
+	}
+	reasons := settings.ExceedingArtifacts(images)
+	if since := settings.UnusedSince(time.Now()); since != nil {
+		for _, img := range images {
+			if _, ok := reasons[img.Id]; ok ||
+				img.Modified == nil || img.Modified.After(*since) {
+				continue
+			}
+			latest, err := d.db.FindLatestDeploymentByArtifactId(ctx, img.Id)
+			if err != nil {
+				return nil, errors.Wrap(err,
+					"Searching for usage of the image among deployments")
+			}
+			if latest == nil || latest.Created == nil || latest.Created.Before(*since) {
+				reasons[img.Id] = model.ArtifactRetentionReasonUnused
+			}
+		}
+	}
+
+	l := log.FromContext(ctx)
+	for _, img := range images {
+		reason, ok := reasons[img.Id]
+		if !ok {
+			continue
+		}
+		inUse, err := d.ImageUsedInActiveDeployment(ctx, img.Id)
+		if err != nil {
+			return nil, err
+		} else if inUse {
+			continue
+		}
+		if !dryRun {
+			err = d.DeleteImage(ctx, img.Id)
+			if err == ErrModelImageInActiveDeployment || err == ErrImageMetaNotFound {
+				// the artifact was deployed or removed in the meantime
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			l.Infof("artifact retention: removed artifact %s (%s): %s",
+				img.Id, img.ArtifactMeta.Name, reason)
+		}
+		report.Artifacts = append(report.Artifacts, model.ArtifactRetentionCandidate{
+			ID:                    img.Id,
+			Name:                  img.ArtifactMeta.Name,
+			DeviceTypesCompatible: img.DeviceTypesCompatible,
+			Size:                  img.Size,
+			Reason:                reason,
+		})
+		report.Size += img.Size
+	}
+	sort.Slice(report.Artifacts, func(i, j int) bool {
+		return report.Artifacts[i].ID < report.Artifacts[j].ID
+	})
+	return report, nil
+}
+
+// EnforceArtifactRetention applies the artifact retention policies of all
+// the tenants every interval, or once if the interval is zero.
+func (d *Deployments) EnforceArtifactRetention(
+	ctx context.Context, interval time.Duration, dryRun bool,
+) error {
+	var (
+		err error
+		tc  <-chan time.Time
+		run bool = true
+	)
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tc = ticker.C
+	} else {
+		c := make(chan time.Time)
+		close(c)
+		tc = c
+	}
+	l := log.FromContext(ctx)
+	for run && err == nil {
+		err = d.forEachTenant(ctx, func(ctx context.Context, tenant string) error {
+			report, err := d.ApplyArtifactRetention(ctx, dryRun)
+			if err != nil {
+				// keep going with the other tenants
+				l.Errorf("artifact retention: tenant %q: %s", tenant, err.Error())
+				return nil
+			}
+			if dryRun {
+				for _, a := range report.Artifacts {
+					l.Infof("artifact retention (dry-run): tenant %q: "+
+						"would remove artifact %s (%s): %s",
+						tenant, a.ID, a.Name, a.Reason)
+				}
+			}
+			if len(report.Artifacts) > 0 {
+				l.Infof("artifact retention: tenant %q: %d artifacts, %d bytes",
+					tenant, len(report.Artifacts), report.Size)
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+
+		case _, run = <-tc:
+		}
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	fs_mocks "github.com/mendersoftware/mender-server/services/deployments/storage/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestApplyArtifactRetention(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	daysAgo := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+	image := func(id, name, deviceType string, age int) *model.Image {
+		return &model.Image{
+			Id: id,
+			ArtifactMeta: &model.ArtifactMeta{
+				Name:                  name,
+				DeviceTypesCompatible: []string{deviceType},
+			},
+			Size:     100,
+			Modified: daysAgo(age),
+		}
+	}
+	unused := image("a", "release-1", "a", 60)
+	images := []*model.Image{
+		unused,
+		// exceeds the device type rule but is in an active deployment
+		image("b1", "release-2", "b", 60),
+		image("b2", "release-3", "b", 1),
+		// recently deployed
+		image("d", "release-4", "d", 60),
+	}
+	settings := &model.ArtifactRetentionSettings{
+		KeepLastPerDeviceType: 1,
+		DeleteUnusedAfterDays: 30,
+	}
+	expected := []model.ArtifactRetentionCandidate{{
+		ID:                    "a",
+		Name:                  "release-1",
+		DeviceTypesCompatible: []string{"a"},
+		Size:                  100,
+		Reason:                model.ArtifactRetentionReasonUnused,
+	}}
+
+	for name, dryRun := range map[string]bool{
+		"ok, dry run": true,
+		"ok":          false,
+	} {
+		dryRun := dryRun
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := identity.WithContext(context.Background(), &identity.Identity{
+				Tenant: "tenant",
+			})
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			fs := &fs_mocks.ObjectStorage{}
+			defer fs.AssertExpectations(t)
+
+			db.On("GetArtifactRetentionSettings", ctx).Return(settings, nil).Once()
+			db.On("ListImages", ctx, (*model.ReleaseOrImageFilter)(nil)).
+				Return(images, len(images), nil).Once()
+			db.On("FindLatestDeploymentByArtifactId", ctx, "a").
+				Return(&model.Deployment{Created: daysAgo(40)}, nil).Once()
+			db.On("FindLatestDeploymentByArtifactId", ctx, "d").
+				Return(&model.Deployment{Created: daysAgo(5)}, nil).Once()
+			db.On("ExistUnfinishedByArtifactId", ctx, "b1").Return(true, nil).Once()
+			if dryRun {
+				db.On("ExistUnfinishedByArtifactId", ctx, "a").Return(false, nil).Once()
+			} else {
+				db.On("ExistUnfinishedByArtifactId", ctx, "a").Return(false, nil).Twice()
+				db.On("FindImageByID", ctx, "a").Return(unused, nil).Once()
+				db.On("GetStorageSettings", ctx).Return(nil, nil).Once()
+				fs.On("DeleteObject", h.ContextMatcher(), "tenant/a").Return(nil).Once()
+				db.On("DeleteImage", h.ContextMatcher(), "a").Return(nil).Once()
+				db.On("UpdateReleaseArtifacts", h.ContextMatcher(),
+					(*model.Image)(nil), unused, "release-1").Return(nil).Once()
+			}
+
+			ds := NewDeployments(db, fs, 0, false)
+			report, err := ds.ApplyArtifactRetention(ctx, dryRun)
+			if assert.NoError(t, err) {
+				assert.Equal(t, &model.ArtifactRetentionReport{
+					TenantID:  "tenant",
+					DryRun:    dryRun,
+					Artifacts: expected,
+					Size:      100,
+				}, report)
+			}
+		})
+	}
+}
+
+func TestApplyArtifactRetentionDisabled(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("GetArtifactRetentionSettings", ctx).Return(nil, nil).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	report, err := ds.ApplyArtifactRetention(ctx, false)
+	if assert.NoError(t, err) {
+		assert.Empty(t, report.Artifacts)
+	}
+}
+
+func TestEnforceArtifactRetention(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("GetTenantDbs").
+		Return([]string{"deployment_service-t1", "deployment_service-t2"}, nil).Once()
+	for _, tenant := range []string{"t1", "t2"} {
+		db.On("GetArtifactRetentionSettings",
+			identity.WithContext(ctx, &identity.Identity{Tenant: tenant}),
+		).Return(&model.ArtifactRetentionSettings{}, nil).Once()
+	}
+
+	ds := NewDeployments(db, nil, 0, false)
+	err := ds.EnforceArtifactRetention(ctx, 0, true)
+	assert.NoError(t, err)
+}
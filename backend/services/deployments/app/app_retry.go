@@ -0,0 +1,82 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+// retryDeviceDeployment offers the deployment again to the device which
+// reported a failure, if the deployment allows more attempts. The failed
+// attempt is recorded in the device deployment, and its log is kept apart
+// under the number of the attempt.
+// Returns true if the deployment will be retried.
+func (d *Deployments) retryDeviceDeployment(
+	ctx context.Context,
+	dd *model.DeviceDeployment,
+	ddState model.DeviceDeploymentState,
+) (bool, error) {
+	if !dd.CanRetry() {
+		return false, nil
+	}
+	deployment, err := d.db.FindDeploymentByID(ctx, dd.DeploymentId)
+	if err != nil {
+		return false, errors.Wrap(err, "failed when searching for deployment")
+	} else if deployment == nil {
+		return false, ErrModelDeploymentNotFound
+	}
+
+	now := time.Now()
+	attempt := model.DeviceDeploymentAttempt{
+		Started:  dd.Started,
+		Finished: &now,
+		Status:   ddState.Status,
+		SubState: ddState.SubState,
+	}
+	attempt.LogAvailable, err = d.db.ArchiveDeviceDeploymentLog(ctx,
+		dd.DeviceId, dd.DeploymentId, len(dd.Attempts)+1)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to save the log of the attempt")
+	}
+	var retryAfter *time.Time
+	if delay := deployment.RetryDelay(len(dd.Attempts)); delay > 0 {
+		t := now.Add(delay)
+		retryAfter = &t
+	}
+
+	old, err := d.db.RetryDeviceDeployment(ctx,
+		dd.DeviceId, dd.DeploymentId, attempt, retryAfter)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to retry the device deployment")
+	}
+	log.FromContext(ctx).Infof("Retrying deployment %s for device %s: attempt %d of %d",
+		dd.DeploymentId, dd.DeviceId, len(dd.Attempts)+2, dd.Retries+1)
+
+	if old != model.DeviceDeploymentStatusPending {
+		if _, err := d.updateDeploymentStats(
+			ctx, deployment, old, model.DeviceDeploymentStatusPending,
+		); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+)
+
+func TestUpdateDeviceDeploymentStatusRetry(t *testing.T) {
+	t.Parallel()
+
+	const devID = "somedevice"
+	logTime := time.Now().Add(-time.Minute)
+	messages := []model.LogMessage{{
+		Timestamp: &logTime,
+		Level:     "error",
+		Message:   "connection timed out",
+	}}
+
+	testCases := map[string]struct {
+		Attempts int
+		Log      *model.DeploymentLog
+
+		RetryDelay time.Duration
+	}{
+		"ok, first retry": {
+			Log: &model.DeploymentLog{
+				DeviceID: devID,
+				Messages: messages,
+			},
+			RetryDelay: time.Minute,
+		},
+		"ok, second retry": {
+			Attempts: 1,
+			Log: &model.DeploymentLog{
+				DeviceID: devID,
+				Messages: messages,
+			},
+			RetryDelay: 2 * time.Minute,
+		},
+		"ok, second retry, no log": {
+			Attempts:   1,
+			RetryDelay: 2 * time.Minute,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			deployment, _ := model.NewDeploymentFromConstructor(
+				&model.DeploymentConstructor{
+					Name:         "foo",
+					ArtifactName: "bar",
+					Retries:      2,
+					RetryBackoff: 60,
+				},
+			)
+			deployment.MaxDevices = 1
+			deployment.Stats.Set(model.DeviceDeploymentStatusInstalling, 1)
+
+			started := time.Now().Add(-time.Hour)
+			deviceDeployment := model.NewDeviceDeployment(devID, deployment.Id)
+			deviceDeployment.Status = model.DeviceDeploymentStatusInstalling
+			deviceDeployment.Started = &started
+			deviceDeployment.Retries = 2
+			deviceDeployment.Attempts = make([]model.DeviceDeploymentAttempt, tc.Attempts)
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("GetDeviceDeployment", ctx, deployment.Id, devID, false).
+				Return(deviceDeployment, nil).Once()
+			db.On("FindDeploymentByID", ctx, deployment.Id).
+				Return(deployment, nil).Once()
+			db.On("ArchiveDeviceDeploymentLog", ctx, devID, deployment.Id, tc.Attempts+1).
+				Return(tc.Log != nil, nil).Once()
+			before := time.Now()
+			db.On("RetryDeviceDeployment", ctx, devID, deployment.Id,
+				mock.MatchedBy(func(attempt model.DeviceDeploymentAttempt) bool {
+					return assert.Equal(t, &started, attempt.Started) &&
+						assert.NotNil(t, attempt.Finished) &&
+						assert.Equal(t, model.DeviceDeploymentStatusFailure, attempt.Status) &&
+						assert.Equal(t, "timeout", attempt.SubState) &&
+						assert.Equal(t, tc.Log != nil, attempt.LogAvailable)
+				}),
+				mock.MatchedBy(func(retryAfter *time.Time) bool {
+					return assert.NotNil(t, retryAfter) &&
+						assert.WithinDuration(t, before.Add(tc.RetryDelay), *retryAfter,
+							time.Second)
+				}),
+			).Return(model.DeviceDeploymentStatusInstalling, nil).Once()
+			db.On("UpdateStatsInc", ctx, deployment.Id,
+				model.DeviceDeploymentStatusInstalling,
+				model.DeviceDeploymentStatusPending,
+			).Return(model.Stats{model.DeviceDeploymentStatusPendingStr: 1}, nil).Once()
+			db.On("SetDeploymentStatus", ctx, deployment.Id,
+				model.DeploymentStatusPending, mock.AnythingOfType("time.Time"),
+			).Return(nil).Once()
+
+			ds := NewDeployments(db, nil, 0, false)
+			err := ds.UpdateDeviceDeploymentStatus(ctx, deployment.Id, devID,
+				model.DeviceDeploymentState{
+					Status:   model.DeviceDeploymentStatusFailure,
+					SubState: "timeout",
+				})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUpdateDeviceDeploymentStatusRetriesExhausted(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	const devID = "somedevice"
+	deployment, _ := model.NewDeploymentFromConstructor(
+		&model.DeploymentConstructor{
+			Name:         "foo",
+			ArtifactName: "bar",
+			Retries:      1,
+		},
+	)
+	deployment.MaxDevices = 1
+	deviceDeployment := model.NewDeviceDeployment(devID, deployment.Id)
+	deviceDeployment.Status = model.DeviceDeploymentStatusInstalling
+	deviceDeployment.Retries = 1
+	deviceDeployment.Attempts = []model.DeviceDeploymentAttempt{{
+		Status: model.DeviceDeploymentStatusFailure,
+	}}
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+
+	db.On("GetDeviceDeployment", ctx, deployment.Id, devID, false).
+		Return(deviceDeployment, nil).Once()
+	db.On("UpdateDeviceDeploymentStatus", ctx, devID, deployment.Id,
+		mock.AnythingOfType("model.DeviceDeploymentState"),
+		model.DeviceDeploymentStatusInstalling,
+	).Return(model.DeviceDeploymentStatusInstalling, nil).Once()
+	db.On("FindDeploymentByID", ctx, deployment.Id).
+		Return(deployment, nil).Once()
+	db.On("UpdateStatsInc", ctx, deployment.Id,
+		model.DeviceDeploymentStatusInstalling,
+		model.DeviceDeploymentStatusFailure,
+	).Return(model.Stats{model.DeviceDeploymentStatusFailureStr: 1}, nil).Once()
+	db.On("SetDeploymentStatus", ctx, deployment.Id,
+		model.DeploymentStatusFinished, mock.AnythingOfType("time.Time"),
+	).Return(nil).Once()
+	db.On("SaveLastDeviceDeploymentStatus", ctx,
+		mock.AnythingOfType("model.DeviceDeployment"),
+	).Return(nil).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	err := ds.UpdateDeviceDeploymentStatus(ctx, deployment.Id, devID,
+		model.DeviceDeploymentState{
+			Status: model.DeviceDeploymentStatusFailure,
+		})
+	assert.NoError(t, err)
+}
+
+func TestGetDeploymentForDeviceWithCurrentRetryAfter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	const devID = "somedevice"
+	deployment, _ := model.NewDeploymentFromConstructor(
+		&model.DeploymentConstructor{
+			Name:         "foo",
+			ArtifactName: "bar",
+			Retries:      1,
+			RetryBackoff: 60,
+		},
+	)
+	retryAfter := time.Now().Add(time.Minute)
+	deviceDeployment := model.NewDeviceDeployment(devID, deployment.Id)
+	deviceDeployment.Retries = 1
+	deviceDeployment.Attempts = []model.DeviceDeploymentAttempt{{
+		Status: model.DeviceDeploymentStatusFailure,
+	}}
+	deviceDeployment.RetryAfter = &retryAfter
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+
+	db.On("FindOldestActiveDeviceDeployment", ctx, devID).
+		Return(deviceDeployment, nil).Once()
+	db.On("FindDeploymentByID", ctx, deployment.Id).
+		Return(deployment, nil).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	instructions, err := ds.GetDeploymentForDeviceWithCurrent(ctx, devID,
+		&model.DeploymentNextRequest{
+			DeviceProvides: &model.InstalledDeviceDeployment{
+				ArtifactName: "foo",
+				DeviceType:   "hammer",
+			},
+		})
+	assert.NoError(t, err)
+	assert.Nil(t, instructions)
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+// expireDeployment marks the device deployments of an expired deployment
+// which did not start yet as expired and finishes the deployment unless some
+// devices are still updating.
+func (d *Deployments) expireDeployment(
+	ctx context.Context,
+	deployment *model.Deployment,
+) error {
+	if err := d.db.ExpireDeviceDeployments(ctx, deployment.Id); err != nil {
+		return errors.Wrap(err, "failed to expire device deployments")
+	}
+
+	stats, err := d.db.AggregateDeviceDeploymentByStatus(ctx, deployment.Id)
+	if err != nil {
+		return err
+	} else if stats == nil {
+		stats = model.NewDeviceDeploymentStats()
+	}
+	// the devices which never asked for the deployment have no device
+	// deployment to expire: count them as expired
+	total := 0
+	for _, count := range stats {
+		total += count
+	}
+	if missing := deployment.MaxDevices - total; missing > 0 {
+		stats.Set(model.DeviceDeploymentStatusExpired,
+			stats.Get(model.DeviceDeploymentStatusExpired)+missing)
+	}
+	if err := d.db.UpdateStats(ctx, deployment.Id, stats); err != nil {
+		return errors.Wrap(err, "failed to update deployment stats")
+	}
+	deployment.Stats = stats
+
+	for _, status := range model.ActiveDeploymentStatuses() {
+		if stats.Get(status) > 0 {
+			// wait for the devices to finish the update
+			return nil
+		}
+	}
+	if err := d.db.SetDeploymentStatus(ctx,
+		deployment.Id, model.DeploymentStatusFinished, time.Now()); err != nil {
+		return errors.Wrap(err, "failed to update deployment status")
+	}
+	deployment.Status = model.DeploymentStatusFinished
+	return nil
+}
+
+// expireDeployments expires the active deployments of the tenant whose end
+// time is past and returns the number of deployments finished.
+func (d *Deployments) expireDeployments(ctx context.Context, now time.Time) (int, error) {
+	deployments, err := d.db.FindExpiredActiveDeployments(ctx, now)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to look up the expired deployments")
+	}
+	finished := 0
+	for _, deployment := range deployments {
+		if err := d.expireDeployment(ctx, deployment); err != nil {
+			return finished, errors.WithMessagef(err, "deployment %s", deployment.Id)
+		}
+		if deployment.Status == model.DeploymentStatusFinished {
+			finished++
+		}
+	}
+	return finished, nil
+}
+
+// ExpireDeployments expires the deployments of all the tenants whose end
+// time is past every interval, or once if the interval is zero; otherwise
+// the deployments expire only when their devices ask for an update.
+func (d *Deployments) ExpireDeployments(ctx context.Context, interval time.Duration) error {
+	var (
+		err error
+		tc  <-chan time.Time
+		run bool = true
+	)
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tc = ticker.C
+	} else {
+		c := make(chan time.Time)
+		close(c)
+		tc = c
+	}
+	l := log.FromContext(ctx)
+	for run && err == nil {
+		now := time.Now()
+		err = d.forEachTenant(ctx, func(ctx context.Context, tenant string) error {
+			finished, err := d.expireDeployments(ctx, now)
+			if err != nil {
+				// keep going with the other tenants
+				l.Errorf("deployment expiry: tenant %q: %s", tenant, err.Error())
+			}
+			if finished > 0 {
+				l.Infof("deployment expiry: tenant %q: %d deployments finished",
+					tenant, finished)
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+
+		case _, run = <-tc:
+		}
+	}
+	return err
+}
+
+// isMaintenanceWindowOpen returns true if the device is allowed to start
+// the deployment at the given time.
+func (d *Deployments) isMaintenanceWindowOpen(
+	ctx context.Context,
+	deviceID string,
+	deployment *model.Deployment,
+	now time.Time,
+) bool {
+	window := deployment.MaintenanceWindow
+	if window == nil {
+		return true
+	}
+	loc := window.Location()
+	if window.TimezoneAttribute != "" {
+		deviceLoc, err := d.getDeviceLocation(ctx, deviceID, window.TimezoneAttribute)
+		if err != nil {
+			log.FromContext(ctx).Warnf(
+				"failed to get the time zone of device %s, using %s: %s",
+				deviceID, loc, err.Error(),
+			)
+		} else if deviceLoc != nil {
+			loc = deviceLoc
+		}
+	}
+	return window.IsOpen(now, loc)
+}
+
+// getDeviceLocation returns the time zone stored in the given inventory
+// attribute of the device, or nil if the attribute is not set.
+func (d *Deployments) getDeviceLocation(
+	ctx context.Context,
+	deviceID string,
+	attribute string,
+) (*time.Location, error) {
+	attributes, err := d.getDeviceAttributes(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range attributes {
+		if attr.Name != attribute || attr.Scope != InventoryAttributeScope {
+			continue
+		}
+		tz, ok := attr.Value.(string)
+		if !ok {
+			return nil, errors.Errorf("attribute %q is not a string", attribute)
+		}
+		return time.LoadLocation(tz)
+	}
+	return nil, nil
+}
+
+// getDeviceAttributes returns the inventory attributes of the device.
+func (d *Deployments) getDeviceAttributes(
+	ctx context.Context,
+	deviceID string,
+) ([]model.DeviceAttribute, error) {
+	var tenantID string
+	if id := identity.FromContext(ctx); id != nil {
+		tenantID = id.Tenant
+	}
+	devices, _, err := d.search(ctx, tenantID, model.SearchParams{
+		Page:      1,
+		PerPage:   1,
+		DeviceIDs: []string{deviceID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, nil
+	}
+	return devices[0].Attributes, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	inventory_mocks "github.com/mendersoftware/mender-server/services/deployments/client/inventory/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+)
+
+func TestGetNewDeploymentForDeviceScheduled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	const deviceID = "device"
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	scheduled, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "scheduled",
+		ArtifactName: "bar",
+		StartTime:    &later,
+	})
+	expired, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "expired",
+		ArtifactName: "bar",
+		EndTime:      &earlier,
+	})
+	expired.Created = &later
+	stats := model.NewDeviceDeploymentStats()
+	stats.Set(model.DeviceDeploymentStatusExpired, 3)
+
+	db := mocks.DataStore{}
+	defer db.AssertExpectations(t)
+
+	db.On("FindLatestInactiveDeviceDeployment", ctx, deviceID).
+		Return(nil, nil)
+	db.On("FindNewerActiveDeployment", ctx, &time.Time{}, deviceID).
+		Return(scheduled, nil).Once()
+	db.On("FindNewerActiveDeployment", ctx, scheduled.Created, deviceID).
+		Return(expired, nil).Once()
+	db.On("ExpireDeviceDeployments", ctx, expired.Id).
+		Return(nil).Once()
+	db.On("AggregateDeviceDeploymentByStatus", ctx, expired.Id).
+		Return(stats, nil).Once()
+	db.On("UpdateStats", ctx, expired.Id, stats).
+		Return(nil).Once()
+	db.On("SetDeploymentStatus", ctx, expired.Id,
+		model.DeploymentStatusFinished, mock.AnythingOfType("time.Time")).
+		Return(nil).Once()
+	db.On("FindNewerActiveDeployment", ctx, expired.Created, deviceID).
+		Return(nil, nil).Once()
+	db.On("FindActiveContinuousDeployments", ctx).
+		Return(nil, nil).Once()
+
+	ds := &Deployments{db: &db}
+	depl, deviceDeployment, err := ds.getNewDeploymentForDevice(ctx, deviceID)
+	assert.NoError(t, err)
+	assert.Nil(t, depl)
+	assert.Nil(t, deviceDeployment)
+}
+
+func TestExpireDeployments(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	earlier := time.Now().Add(-time.Hour)
+	newExpired := func(name string) *model.Deployment {
+		deployment, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+			Name:         name,
+			ArtifactName: "bar",
+			EndTime:      &earlier,
+		})
+		return deployment
+	}
+	// all the devices expired
+	expired := newExpired("expired")
+	expiredStats := model.NewDeviceDeploymentStats()
+	expiredStats.Set(model.DeviceDeploymentStatusExpired, 3)
+	// a device is still installing the update
+	installing := newExpired("installing")
+	installingStats := model.NewDeviceDeploymentStats()
+	installingStats.Set(model.DeviceDeploymentStatusExpired, 2)
+	installingStats.Set(model.DeviceDeploymentStatusInstalling, 1)
+	// two of the devices never asked for the deployment
+	unseen := newExpired("unseen")
+	unseen.MaxDevices = 3
+	unseenStats := model.NewDeviceDeploymentStats()
+	unseenStats.Set(model.DeviceDeploymentStatusExpired, 1)
+	unseenExpected := model.NewDeviceDeploymentStats()
+	unseenExpected.Set(model.DeviceDeploymentStatusExpired, 3)
+
+	t1Ctx := identity.WithContext(ctx, &identity.Identity{Tenant: "t1"})
+	t2Ctx := identity.WithContext(ctx, &identity.Identity{Tenant: "t2"})
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("GetTenantDbs").
+		Return([]string{"deployment_service-t1", "deployment_service-t2"}, nil).Once()
+	db.On("FindExpiredActiveDeployments", t1Ctx, mock.AnythingOfType("time.Time")).
+		Return([]*model.Deployment{expired, installing, unseen}, nil).Once()
+	for _, tc := range []struct {
+		deployment *model.Deployment
+		stats      model.Stats
+		expected   model.Stats
+	}{
+		{expired, expiredStats, expiredStats},
+		{installing, installingStats, installingStats},
+		{unseen, unseenStats, unseenExpected},
+	} {
+		db.On("ExpireDeviceDeployments", t1Ctx, tc.deployment.Id).
+			Return(nil).Once()
+		db.On("AggregateDeviceDeploymentByStatus", t1Ctx, tc.deployment.Id).
+			Return(tc.stats, nil).Once()
+		db.On("UpdateStats", t1Ctx, tc.deployment.Id, tc.expected).
+			Return(nil).Once()
+	}
+	for _, deployment := range []*model.Deployment{expired, unseen} {
+		db.On("SetDeploymentStatus", t1Ctx, deployment.Id,
+			model.DeploymentStatusFinished, mock.AnythingOfType("time.Time")).
+			Return(nil).Once()
+	}
+	// the errors of a tenant do not stop the sweep
+	db.On("FindExpiredActiveDeployments", t2Ctx, mock.AnythingOfType("time.Time")).
+		Return(nil, errors.New("connection refused")).Once()
+
+	ds := NewDeployments(db, nil, 0, false)
+	err := ds.ExpireDeployments(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, model.DeploymentStatusFinished, expired.Status)
+	assert.Equal(t, model.DeploymentStatusFinished, unseen.Status)
+	assert.Equal(t, 3, unseen.Stats.Get(model.DeviceDeploymentStatusExpired))
+	assert.NotEqual(t, model.DeploymentStatusFinished, installing.Status)
+}
+
+func TestIsMaintenanceWindowOpen(t *testing.T) {
+	t.Parallel()
+
+	const deviceID = "device"
+	// window open from 22:00 to 23:00 in the device time zone
+	now := time.Date(2024, 3, 4, 21, 30, 0, 0, time.UTC)
+	window := &model.MaintenanceWindow{
+		Schedule:          "0 22 * * *",
+		Duration:          60,
+		TimezoneAttribute: "timezone",
+	}
+
+	testCases := map[string]struct {
+		devices   []model.InvDevice
+		searchErr error
+
+		open bool
+	}{
+		"open, device time zone": {
+			devices: []model.InvDevice{{
+				ID: deviceID,
+				Attributes: []model.DeviceAttribute{{
+					Name:  "timezone",
+					Scope: InventoryAttributeScope,
+					Value: "Europe/Oslo",
+				}},
+			}},
+			open: true,
+		},
+		"closed, attribute not set": {
+			devices: []model.InvDevice{{ID: deviceID}},
+		},
+		"closed, inventory error": {
+			searchErr: errors.New("inventory unavailable"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			inv := &inventory_mocks.Client{}
+			defer inv.AssertExpectations(t)
+			inv.On("Search", ctx, "", model.SearchParams{
+				Page:      1,
+				PerPage:   1,
+				DeviceIDs: []string{deviceID},
+			}).Return(tc.devices, len(tc.devices), tc.searchErr)
+
+			deployment, _ := model.NewDeploymentFromConstructor(
+				&model.DeploymentConstructor{
+					Name:              "foo",
+					ArtifactName:      "bar",
+					MaintenanceWindow: window,
+				},
+			)
+			ds := &Deployments{inventoryClient: inv}
+			open := ds.isMaintenanceWindowOpen(ctx, deviceID, deployment, now)
+			assert.Equal(t, tc.open, open)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+var (
+	ErrSigningKeyNotFound  = errors.New("Signing key not found")
+	ErrArtifactNotSigned   = errors.New("The artifact is not signed")
+	ErrArtifactNotVerified = errors.New(
+		"The artifact signature cannot be verified with any of the trusted keys",
+	)
+)
+
+// GetArtifactSignatureSettings returns the artifact signature policy of
+// the tenant.
+func (d *Deployments) GetArtifactSignatureSettings(
+	ctx context.Context,
+) (*model.ArtifactSignatureSettings, error) {
+	settings, err := d.db.GetArtifactSignatureSettings(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the artifact signature settings")
+	}
+	if settings == nil {
+		settings = &model.ArtifactSignatureSettings{
+			Policy: model.SignaturePolicyNone,
+		}
+	}
+	return settings, nil
+}
+
+func (d *Deployments) SetArtifactSignatureSettings(
+	ctx context.Context,
+	settings *model.ArtifactSignatureSettings,
+) error {
+	if err := d.db.SetArtifactSignatureSettings(ctx, settings); err != nil {
+		return errors.Wrap(err, "failed to save the artifact signature settings")
+	}
+	return nil
+}
+
+func (d *Deployments) ListSigningKeys(ctx context.Context) ([]model.SigningKey, error) {
+	keys, err := d.db.ListSigningKeys(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the signing keys")
+	}
+	return keys, nil
+}
+
+func (d *Deployments) AddSigningKey(
+	ctx context.Context,
+	req model.SigningKeyRequest,
+) (*model.SigningKey, error) {
+	key, err := model.NewSigningKey(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.db.InsertSigningKey(ctx, key); err != nil {
+		return nil, errors.Wrap(err, "failed to save the signing key")
+	}
+	return key, nil
+}
+
+func (d *Deployments) DeleteSigningKey(ctx context.Context, id string) error {
+	err := d.db.DeleteSigningKey(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrSigningKeyNotFound
+	} else if err != nil {
+		return errors.Wrap(err, "failed to delete the signing key")
+	}
+	return nil
+}
+
+// getArtifactSignatureVerification returns the signature policy and the
+// trusted keys used to verify the uploaded artifacts.
+func (d *Deployments) getArtifactSignatureVerification(
+	ctx context.Context,
+) (model.SignaturePolicy, []model.SigningKey, error) {
+	settings, err := d.GetArtifactSignatureSettings(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	keys, err := d.ListSigningKeys(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return settings.Policy, keys, nil
+}
+
+// checkArtifactSignature applies the signature policy to the parsed artifact.
+func checkArtifactSignature(policy model.SignaturePolicy, meta *model.ArtifactMeta) error {
+	switch policy {
+	case model.SignaturePolicyRequire:
+		if !meta.Signed {
+			return ErrArtifactNotSigned
+		}
+		fallthrough
+	case model.SignaturePolicyVerify:
+		if meta.Signed && meta.SigningKeyID == "" {
+			return ErrArtifactNotVerified
+		}
+	}
+	return nil
+}
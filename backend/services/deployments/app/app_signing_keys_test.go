@@ -0,0 +1,204 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+)
+
+func TestCheckArtifactSignature(t *testing.T) {
+	t.Parallel()
+
+	unsigned := &model.ArtifactMeta{}
+	unverified := &model.ArtifactMeta{Signed: true}
+	verified := &model.ArtifactMeta{Signed: true, SigningKeyID: "key"}
+
+	testCases := map[string]struct {
+		Policy model.SignaturePolicy
+		Meta   *model.ArtifactMeta
+		Error  error
+	}{
+		"none, unsigned": {
+			Policy: model.SignaturePolicyNone,
+			Meta:   unsigned,
+		},
+		"none, unverified": {
+			Policy: model.SignaturePolicyNone,
+			Meta:   unverified,
+		},
+		"verify, unsigned": {
+			Policy: model.SignaturePolicyVerify,
+			Meta:   unsigned,
+		},
+		"verify, unverified": {
+			Policy: model.SignaturePolicyVerify,
+			Meta:   unverified,
+			Error:  ErrArtifactNotVerified,
+		},
+		"verify, verified": {
+			Policy: model.SignaturePolicyVerify,
+			Meta:   verified,
+		},
+		"require, unsigned": {
+			Policy: model.SignaturePolicyRequire,
+			Meta:   unsigned,
+			Error:  ErrArtifactNotSigned,
+		},
+		"require, unverified": {
+			Policy: model.SignaturePolicyRequire,
+			Meta:   unverified,
+			Error:  ErrArtifactNotVerified,
+		},
+		"require, verified": {
+			Policy: model.SignaturePolicyRequire,
+			Meta:   verified,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.Error, checkArtifactSignature(tc.Policy, tc.Meta))
+		})
+	}
+}
+
+// tamperArtifactPayload replaces the contents of the named payload file,
+// leaving the manifest and its checksums untouched.
+func tamperArtifactPayload(t *testing.T, artifact []byte, name string) []byte {
+	var out bytes.Buffer
+	tr := tar.NewReader(bytes.NewReader(artifact))
+	tw := tar.NewWriter(&out)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		if hdr.Name == "data/0000.tar" {
+			var payload bytes.Buffer
+			ptr := tar.NewReader(bytes.NewReader(data))
+			ptw := tar.NewWriter(&payload)
+			for {
+				phdr, err := ptr.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				pdata, err := io.ReadAll(ptr)
+				require.NoError(t, err)
+				if phdr.Name == name {
+					pdata = bytes.Repeat([]byte{'x'}, len(pdata))
+				}
+				require.NoError(t, ptw.WriteHeader(phdr))
+				_, err = ptw.Write(pdata)
+				require.NoError(t, err)
+			}
+			require.NoError(t, ptw.Close())
+			data = payload.Bytes()
+			hdr.Size = int64(len(data))
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err = tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return out.Bytes()
+}
+
+func TestHandleArtifactDirectUploadPayload(t *testing.T) {
+	t.Parallel()
+
+	tampered := tamperArtifactPayload(t, writeTestAppArtifact(t), "README")
+
+	ctx := context.Background()
+	db := mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("FindUnfinishedStorageMigration", ctx).Return(nil, nil)
+	db.On("GetStorageSettings", ctx).Return(nil, nil)
+	db.On("GetArtifactSignatureSettings", mock.Anything).
+		Return(&model.ArtifactSignatureSettings{
+			Policy: model.SignaturePolicyVerify,
+		}, nil)
+	db.On("ListSigningKeys", mock.Anything).Return([]model.SigningKey{}, nil)
+
+	ds := &Deployments{db: &db}
+
+	// the direct uploads skip the verification of the payload, unless
+	// a signature policy is set
+	_, err := ds.handleArtifact(ctx, &model.MultipartUploadMsg{
+		ArtifactReader: bytes.NewReader(tampered),
+	}, true, nil)
+	assert.ErrorIs(t, err, ErrModelParsingArtifactFailed)
+}
+
+func TestGetArtifactSignatureSettings(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("GetArtifactSignatureSettings", ctx).Return(nil, nil).Once()
+	db.On("GetArtifactSignatureSettings", ctx).
+		Return(&model.ArtifactSignatureSettings{
+			Policy: model.SignaturePolicyRequire,
+		}, nil).Once()
+	db.On("GetArtifactSignatureSettings", ctx).
+		Return(nil, errors.New("db error")).Once()
+
+	ds := &Deployments{db: &db}
+
+	settings, err := ds.GetArtifactSignatureSettings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, model.SignaturePolicyNone, settings.Policy)
+
+	settings, err = ds.GetArtifactSignatureSettings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, model.SignaturePolicyRequire, settings.Policy)
+
+	_, err = ds.GetArtifactSignatureSettings(ctx)
+	assert.EqualError(t, err, "failed to get the artifact signature settings: db error")
+}
+
+func TestDeleteSigningKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db := mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("DeleteSigningKey", ctx, "found").Return(nil).Once()
+	db.On("DeleteSigningKey", ctx, "missing").Return(store.ErrNotFound).Once()
+
+	ds := &Deployments{db: &db}
+	assert.NoError(t, ds.DeleteSigningKey(ctx, "found"))
+	assert.Equal(t, ErrSigningKeyNotFound, ds.DeleteSigningKey(ctx, "missing"))
+}
@@ -0,0 +1,349 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+)
+
+var (
+	ErrStorageMigrationNotFound   = errors.New("storage migration not found")
+	ErrStorageMigrationInProgress = errors.New(
+		"another storage migration is in progress")
+	ErrStorageMigrationNotResumable = errors.New(
+		"only failed storage migrations can be resumed")
+	ErrStorageMigrationChecksum = errors.New(
+		"the checksum of the copied artifact does not match the source")
+	ErrStorageMigrationSwitching = errors.New(
+		"the artifact storage is being switched over, retry the upload later")
+)
+
+// StartStorageMigration schedules the copy of the artifacts of the tenant
+// to the target storage; the copy is carried out by the migrate-storage
+// command.
+func (d *Deployments) StartStorageMigration(
+	ctx context.Context,
+	req model.StorageMigrationRequest,
+) (*model.StorageMigration, error) {
+	unfinished, err := d.db.FindUnfinishedStorageMigration(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to look up the storage migrations")
+	} else if unfinished != nil {
+		return nil, ErrStorageMigrationInProgress
+	}
+	targetCtx := storage.SettingsWithContext(ctx, req.Target)
+	if err := d.objectStorage.HealthCheck(targetCtx); err != nil {
+		return nil, errors.WithMessage(err,
+			"the target storage settings failed the health check",
+		)
+	}
+
+	now := time.Now()
+	migration := &model.StorageMigration{
+		ID:      uuid.NewString(),
+		Mode:    req.Mode,
+		Target:  req.Target,
+		Status:  model.StorageMigrationStatusPending,
+		Created: &now,
+		Updated: &now,
+	}
+	if err := d.db.InsertStorageMigration(ctx, migration); err != nil {
+		return nil, errors.Wrap(err, "failed to save the storage migration")
+	}
+	return migration, nil
+}
+
+func (d *Deployments) GetStorageMigration(
+	ctx context.Context,
+	id string,
+) (*model.StorageMigration, error) {
+	migration, err := d.db.GetStorageMigration(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the storage migration")
+	} else if migration == nil {
+		return nil, ErrStorageMigrationNotFound
+	}
+	return migration, nil
+}
+
+// ResumeStorageMigration schedules again a failed storage migration; the
+// artifacts already copied are not copied again.
+func (d *Deployments) ResumeStorageMigration(
+	ctx context.Context,
+	id string,
+) (*model.StorageMigration, error) {
+	migration, err := d.GetStorageMigration(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if migration.Status != model.StorageMigrationStatusFailed {
+		return nil, ErrStorageMigrationNotResumable
+	}
+	unfinished, err := d.db.FindUnfinishedStorageMigration(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to look up the storage migrations")
+	} else if unfinished != nil {
+		return nil, ErrStorageMigrationInProgress
+	}
+
+	now := time.Now()
+	migration.Status = model.StorageMigrationStatusPending
+	migration.Error = ""
+	migration.Updated = &now
+	if err := d.db.UpdateStorageMigration(ctx, migration); err != nil {
+		return nil, errors.Wrap(err, "failed to save the storage migration")
+	}
+	return migration, nil
+}
+
+// ProcessStorageMigrations runs the pending storage migrations of the given
+// tenant, or of all the tenants if empty. Migrations interrupted while
+// running are resumed.
+func (d *Deployments) ProcessStorageMigrations(ctx context.Context, tenant string) error {
+	l := log.FromContext(ctx)
+	var errReturned error
+	process := func(ctx context.Context, tenant string) error {
+		migration, err := d.db.FindUnfinishedStorageMigration(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to look up the storage migrations")
+		} else if migration == nil {
+			return nil
+		}
+		l.Infof("storage migration: tenant %q: running %s %s",
+			tenant, migration.Mode, migration.ID)
+		err = d.runStorageMigration(ctx, migration)
+		if err != nil {
+			// keep going with the other tenants
+			l.Errorf("storage migration: tenant %q: %s: %s",
+				tenant, migration.ID, err.Error())
+			errReturned = err
+			return nil
+		}
+		l.Infof("storage migration: tenant %q: %s completed: %d artifacts, %d bytes",
+			tenant, migration.ID, migration.Copied, migration.CopiedSize)
+		return nil
+	}
+	if tenant != "" {
+		ctx = identity.WithContext(ctx, &identity.Identity{
+			Tenant: tenant,
+		})
+		if err := process(ctx, tenant); err != nil {
+			return err
+		}
+	} else if err := d.forEachTenant(ctx, process); err != nil {
+		return err
+	}
+	return errReturned
+}
+
+// checkStorageSwitching returns ErrStorageMigrationSwitching while a
+// storage migration switches the storage settings of the tenant over, so
+// that no artifact is uploaded to the old storage after its final pass.
+func (d *Deployments) checkStorageSwitching(ctx context.Context) error {
+	migration, err := d.db.FindUnfinishedStorageMigration(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to look up the storage migrations")
+	} else if migration != nil &&
+		migration.Status == model.StorageMigrationStatusSwitching {
+		return ErrStorageMigrationSwitching
+	}
+	return nil
+}
+
+// runStorageMigration copies the artifacts in the order of their IDs,
+// saving the progress after each artifact. Once all the artifacts are
+// copied, the migrations in migrate mode switch the storage settings of the
+// tenant to the target storage: the uploads are rejected during the final
+// pass over the artifacts missing from the target, and the artifacts of the
+// uploads that raced the switch-over are copied after it.
+func (d *Deployments) runStorageMigration(
+	ctx context.Context,
+	migration *model.StorageMigration,
+) (err error) {
+	defer func() {
+		if err == nil {
+			return
+		}
+		now := time.Now()
+		migration.Status = model.StorageMigrationStatusFailed
+		migration.Error = err.Error()
+		migration.Updated = &now
+		if errSave := d.db.UpdateStorageMigration(ctx, migration); errSave != nil {
+			log.FromContext(ctx).Errorf(
+				"failed to save the failed storage migration: %s", errSave.Error())
+		}
+	}()
+	srcCtx, err := d.contextWithStorageSettings(ctx)
+	if err != nil {
+		return errors.Wrap(err, "invalid source storage settings")
+	}
+	dstCtx := storage.SettingsWithContext(ctx, migration.Target)
+
+	images, _, err := d.db.ListImages(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the artifacts")
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Id < images[j].Id
+	})
+	migration.Status = model.StorageMigrationStatusRunning
+	migration.Artifacts = len(images)
+	migration.Copied, migration.Size, migration.CopiedSize = 0, 0, 0
+	for _, img := range images {
+		migration.Size += img.Size
+		if img.Id <= migration.LastArtifactID {
+			migration.Copied++
+			migration.CopiedSize += img.Size
+		}
+	}
+	if err = d.saveStorageMigration(ctx, migration); err != nil {
+		return err
+	}
+
+	for _, img := range images {
+		if img.Id <= migration.LastArtifactID {
+			continue
+		}
+		if _, err = d.copyArtifact(srcCtx, dstCtx, img.Id); err != nil {
+			return errors.WithMessagef(err, "artifact %s", img.Id)
+		}
+		migration.Copied++
+		migration.CopiedSize += img.Size
+		migration.LastArtifactID = img.Id
+		if err = d.saveStorageMigration(ctx, migration); err != nil {
+			return err
+		}
+	}
+
+	if migration.Mode == model.StorageMigrationModeMigrate {
+		migration.Status = model.StorageMigrationStatusSwitching
+		if err = d.saveStorageMigration(ctx, migration); err != nil {
+			return err
+		}
+		// artifacts uploaded in the meantime are copied before the
+		// settings are switched over
+		if err = d.copyMissingArtifacts(srcCtx, dstCtx, migration); err != nil {
+			return err
+		}
+		if err = d.db.SetStorageSettings(ctx, migration.Target); err != nil {
+			return errors.Wrap(err, "failed to switch the storage settings")
+		}
+		// uploads which passed the check right before the switching
+		// status was saved complete in the old storage
+		if err = d.copyMissingArtifacts(srcCtx, dstCtx, migration); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	migration.Status = model.StorageMigrationStatusCompleted
+	migration.Finished = &now
+	return d.saveStorageMigration(ctx, migration)
+}
+
+func (d *Deployments) saveStorageMigration(
+	ctx context.Context,
+	migration *model.StorageMigration,
+) error {
+	now := time.Now()
+	migration.Updated = &now
+	if err := d.db.UpdateStorageMigration(ctx, migration); err != nil {
+		return errors.Wrap(err, "failed to save the storage migration")
+	}
+	return nil
+}
+
+// copyMissingArtifacts copies the artifacts not found in the target
+// storage.
+func (d *Deployments) copyMissingArtifacts(
+	srcCtx, dstCtx context.Context,
+	migration *model.StorageMigration,
+) error {
+	images, _, err := d.db.ListImages(srcCtx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the artifacts")
+	}
+	for _, img := range images {
+		_, err := d.objectStorage.StatObject(dstCtx,
+			model.ImagePathFromContext(dstCtx, img.Id))
+		if err == nil {
+			continue
+		} else if !errors.Is(err, storage.ErrObjectNotFound) {
+			return errors.WithMessagef(err, "artifact %s", img.Id)
+		}
+		copied, err := d.copyArtifact(srcCtx, dstCtx, img.Id)
+		if err != nil {
+			return errors.WithMessagef(err, "artifact %s", img.Id)
+		} else if !copied {
+			continue
+		}
+		migration.Artifacts++
+		migration.Copied++
+		migration.Size += img.Size
+		migration.CopiedSize += img.Size
+	}
+	return nil
+}
+
+// copyArtifact copies the artifact from the source to the target storage
+// and verifies the SHA256 checksum of the copy; artifacts missing from the
+// source storage are skipped.
+func (d *Deployments) copyArtifact(
+	srcCtx, dstCtx context.Context,
+	id string,
+) (bool, error) {
+	path := model.ImagePathFromContext(srcCtx, id)
+	src, err := d.objectStorage.GetObject(srcCtx, path)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		log.FromContext(srcCtx).Warnf(
+			"storage migration: artifact %s not found in the source storage", id)
+		return false, nil
+	} else if err != nil {
+		return false, errors.WithMessage(err, "failed to read the artifact")
+	}
+	defer src.Close()
+
+	srcHash := sha256.New()
+	err = d.objectStorage.PutObject(dstCtx, path, io.TeeReader(src, srcHash))
+	if err != nil {
+		return false, errors.WithMessage(err, "failed to write the artifact")
+	}
+
+	dst, err := d.objectStorage.GetObject(dstCtx, path)
+	if err != nil {
+		return false, errors.WithMessage(err, "failed to read back the artifact")
+	}
+	defer dst.Close()
+	dstHash := sha256.New()
+	if _, err = io.Copy(dstHash, dst); err != nil {
+		return false, errors.WithMessage(err, "failed to read back the artifact")
+	}
+	if !bytes.Equal(srcHash.Sum(nil), dstHash.Sum(nil)) {
+		return false, ErrStorageMigrationChecksum
+	}
+	return true, nil
+}
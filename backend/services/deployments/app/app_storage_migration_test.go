@@ -0,0 +1,401 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+	fs_mocks "github.com/mendersoftware/mender-server/services/deployments/storage/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+var storageMigrationTarget = &model.StorageSettings{
+	Type:   model.StorageTypeAzure,
+	Bucket: "container",
+	Key:    "account",
+	Secret: "account-key",
+}
+
+func storageSettingsMatcher(settings *model.StorageSettings) interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		s, _ := storage.SettingsFromContext(ctx)
+		return s == settings
+	})
+}
+
+func TestStartStorageMigration(t *testing.T) {
+	t.Parallel()
+
+	req := model.StorageMigrationRequest{
+		Mode:   model.StorageMigrationModeMigrate,
+		Target: storageMigrationTarget,
+	}
+	testCases := map[string]struct {
+		Unfinished    *model.StorageMigration
+		UnfinishedErr error
+		HealthErr     error
+		InsertErr     error
+
+		Error error
+	}{
+		"ok": {},
+		"error, in progress": {
+			Unfinished: &model.StorageMigration{ID: "migration"},
+			Error:      ErrStorageMigrationInProgress,
+		},
+		"error, looking up migrations": {
+			UnfinishedErr: errors.New("connection refused"),
+			Error: errors.New("failed to look up the storage migrations: " +
+				"connection refused"),
+		},
+		"error, target unhealthy": {
+			HealthErr: errors.New("access denied"),
+			Error: errors.New("the target storage settings failed the health check: " +
+				"access denied"),
+		},
+		"error, saving the migration": {
+			InsertErr: errors.New("connection refused"),
+			Error:     errors.New("failed to save the storage migration: connection refused"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			fs := &fs_mocks.ObjectStorage{}
+			defer fs.AssertExpectations(t)
+
+			db.On("FindUnfinishedStorageMigration", h.ContextMatcher()).
+				Return(tc.Unfinished, tc.UnfinishedErr).Once()
+			if tc.Unfinished == nil && tc.UnfinishedErr == nil {
+				fs.On("HealthCheck", storageSettingsMatcher(req.Target)).
+					Return(tc.HealthErr).Once()
+				if tc.HealthErr == nil {
+					db.On("InsertStorageMigration", h.ContextMatcher(),
+						mock.MatchedBy(func(m *model.StorageMigration) bool {
+							return m.ID != "" &&
+								m.Mode == req.Mode &&
+								m.Target == req.Target &&
+								m.Status == model.StorageMigrationStatusPending
+						})).
+						Return(tc.InsertErr).Once()
+				}
+			}
+
+			d := NewDeployments(db, fs, 0, false)
+			migration, err := d.StartStorageMigration(context.Background(), req)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+				assert.Nil(t, migration)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, migration)
+			}
+		})
+	}
+}
+
+func TestResumeStorageMigration(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Migration  *model.StorageMigration
+		Unfinished *model.StorageMigration
+
+		Error error
+	}{
+		"ok": {
+			Migration: &model.StorageMigration{
+				ID:             "migration",
+				Status:         model.StorageMigrationStatusFailed,
+				Error:          "connection reset",
+				LastArtifactID: "artifact",
+			},
+		},
+		"error, not found": {
+			Error: ErrStorageMigrationNotFound,
+		},
+		"error, completed": {
+			Migration: &model.StorageMigration{
+				ID:     "migration",
+				Status: model.StorageMigrationStatusCompleted,
+			},
+			Error: ErrStorageMigrationNotResumable,
+		},
+		"error, another migration in progress": {
+			Migration: &model.StorageMigration{
+				ID:     "migration",
+				Status: model.StorageMigrationStatusFailed,
+			},
+			Unfinished: &model.StorageMigration{ID: "other"},
+			Error:      ErrStorageMigrationInProgress,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("GetStorageMigration", h.ContextMatcher(), "migration").
+				Return(tc.Migration, nil).Once()
+			if tc.Migration != nil &&
+				tc.Migration.Status == model.StorageMigrationStatusFailed {
+				db.On("FindUnfinishedStorageMigration", h.ContextMatcher()).
+					Return(tc.Unfinished, nil).Once()
+				if tc.Unfinished == nil {
+					db.On("UpdateStorageMigration", h.ContextMatcher(),
+						mock.MatchedBy(func(m *model.StorageMigration) bool {
+							return m.Status == model.StorageMigrationStatusPending &&
+								m.Error == "" &&
+								m.LastArtifactID == "artifact"
+						})).
+						Return(nil).Once()
+				}
+			}
+
+			d := NewDeployments(db, nil, 0, false)
+			migration, err := d.ResumeStorageMigration(context.Background(), "migration")
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+				assert.Nil(t, migration)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, model.StorageMigrationStatusPending, migration.Status)
+			}
+		})
+	}
+}
+
+func TestProcessStorageMigrations(t *testing.T) {
+	t.Parallel()
+
+	const tenantID = "123456789012345678901234"
+	images := []*model.Image{
+		{Id: "b", Size: 20},
+		{Id: "a", Size: 10},
+	}
+	content := func(id string) io.ReadCloser {
+		return io.NopCloser(strings.NewReader("content of " + id))
+	}
+	testCases := map[string]struct {
+		Migration *model.StorageMigration
+		Mocks     func(db *mocks.DataStore, fs *fs_mocks.ObjectStorage)
+
+		Statuses   []string
+		Status     string
+		Copied     int
+		CopiedSize int64
+		Error      string
+	}{
+		"ok, migrate": {
+			Migration: &model.StorageMigration{
+				ID:     "migration",
+				Mode:   model.StorageMigrationModeMigrate,
+				Target: storageMigrationTarget,
+				Status: model.StorageMigrationStatusPending,
+			},
+			Mocks: func(db *mocks.DataStore, fs *fs_mocks.ObjectStorage) {
+				for _, id := range []string{"a", "b"} {
+					path := tenantID + "/" + id
+					fs.On("GetObject", storageSettingsMatcher(nil), path).
+						Return(content(id), nil).Once()
+					fs.On("PutObject", storageSettingsMatcher(storageMigrationTarget),
+						path, mock.Anything).
+						Run(func(args mock.Arguments) {
+							_, _ = io.Copy(io.Discard, args.Get(2).(io.Reader))
+						}).
+						Return(nil).Once()
+					fs.On("GetObject", storageSettingsMatcher(storageMigrationTarget), path).
+						Return(content(id), nil).Once()
+				}
+				copyMissing := func(id string) {
+					path := tenantID + "/" + id
+					fs.On("StatObject", storageSettingsMatcher(storageMigrationTarget),
+						path).
+						Return(nil, storage.ErrObjectNotFound).Once()
+					fs.On("GetObject", storageSettingsMatcher(nil), path).
+						Return(content(id), nil).Once()
+					fs.On("PutObject", storageSettingsMatcher(storageMigrationTarget),
+						path, mock.Anything).
+						Run(func(args mock.Arguments) {
+							_, _ = io.Copy(io.Discard, args.Get(2).(io.Reader))
+						}).
+						Return(nil).Once()
+					fs.On("GetObject", storageSettingsMatcher(storageMigrationTarget),
+						path).
+						Return(content(id), nil).Once()
+				}
+				// artifact uploaded while copying, copied by the final
+				// pass while the uploads are rejected
+				db.On("ListImages", h.ContextMatcher(), (*model.ReleaseOrImageFilter)(nil)).
+					Return(append(images, &model.Image{Id: "c", Size: 5}), 3, nil).Once()
+				copyMissing("c")
+				db.On("SetStorageSettings", h.ContextMatcher(), storageMigrationTarget).
+					Return(nil).Once()
+				// artifact of an upload which raced the switch-over,
+				// copied after it
+				db.On("ListImages", h.ContextMatcher(), (*model.ReleaseOrImageFilter)(nil)).
+					Return(append(images,
+						&model.Image{Id: "c", Size: 5},
+						&model.Image{Id: "d", Size: 7},
+					), 4, nil).Once()
+				copyMissing("d")
+				fs.On("StatObject", storageSettingsMatcher(storageMigrationTarget),
+					mock.MatchedBy(func(path string) bool {
+						return path != tenantID+"/d"
+					})).
+					Return(&storage.ObjectInfo{}, nil).Times(5)
+			},
+			Statuses: []string{
+				model.StorageMigrationStatusRunning,
+				model.StorageMigrationStatusSwitching,
+				model.StorageMigrationStatusCompleted,
+			},
+			Status:     model.StorageMigrationStatusCompleted,
+			Copied:     4,
+			CopiedSize: 42,
+		},
+		"ok, resume mirror": {
+			Migration: &model.StorageMigration{
+				ID:             "migration",
+				Mode:           model.StorageMigrationModeMirror,
+				Target:         storageMigrationTarget,
+				Status:         model.StorageMigrationStatusRunning,
+				LastArtifactID: "a",
+			},
+			Mocks: func(db *mocks.DataStore, fs *fs_mocks.ObjectStorage) {
+				path := tenantID + "/b"
+				fs.On("GetObject", storageSettingsMatcher(nil), path).
+					Return(content("b"), nil).Once()
+				fs.On("PutObject", storageSettingsMatcher(storageMigrationTarget),
+					path, mock.Anything).
+					Run(func(args mock.Arguments) {
+						_, _ = io.Copy(io.Discard, args.Get(2).(io.Reader))
+					}).
+					Return(nil).Once()
+				fs.On("GetObject", storageSettingsMatcher(storageMigrationTarget), path).
+					Return(content("b"), nil).Once()
+			},
+			Status:     model.StorageMigrationStatusCompleted,
+			Copied:     2,
+			CopiedSize: 30,
+		},
+		"error, checksum mismatch": {
+			Migration: &model.StorageMigration{
+				ID:     "migration",
+				Mode:   model.StorageMigrationModeMigrate,
+				Target: storageMigrationTarget,
+				Status: model.StorageMigrationStatusPending,
+			},
+			Mocks: func(db *mocks.DataStore, fs *fs_mocks.ObjectStorage) {
+				path := tenantID + "/a"
+				fs.On("GetObject", storageSettingsMatcher(nil), path).
+					Return(content("a"), nil).Once()
+				fs.On("PutObject", storageSettingsMatcher(storageMigrationTarget),
+					path, mock.Anything).
+					Run(func(args mock.Arguments) {
+						_, _ = io.Copy(io.Discard, args.Get(2).(io.Reader))
+					}).
+					Return(nil).Once()
+				fs.On("GetObject", storageSettingsMatcher(storageMigrationTarget), path).
+					Return(content("truncated"), nil).Once()
+			},
+			Status: model.StorageMigrationStatusFailed,
+			Error:  "artifact a: " + ErrStorageMigrationChecksum.Error(),
+		},
+		"error, source not readable": {
+			Migration: &model.StorageMigration{
+				ID:     "migration",
+				Mode:   model.StorageMigrationModeMirror,
+				Target: storageMigrationTarget,
+				Status: model.StorageMigrationStatusPending,
+			},
+			Mocks: func(db *mocks.DataStore, fs *fs_mocks.ObjectStorage) {
+				fs.On("GetObject", storageSettingsMatcher(nil), tenantID+"/a").
+					Return(nil, errors.New("access denied")).Once()
+			},
+			Status: model.StorageMigrationStatusFailed,
+			Error:  "artifact a: failed to read the artifact: access denied",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+			fs := &fs_mocks.ObjectStorage{}
+			defer fs.AssertExpectations(t)
+
+			db.On("FindUnfinishedStorageMigration", h.ContextMatcher()).
+				Return(tc.Migration, nil).Once()
+			db.On("GetStorageSettings", h.ContextMatcher()).
+				Return(nil, nil).Once()
+			db.On("ListImages", h.ContextMatcher(), (*model.ReleaseOrImageFilter)(nil)).
+				Return(images, len(images), nil).Once()
+			var (
+				saved    model.StorageMigration
+				statuses []string
+			)
+			db.On("UpdateStorageMigration", h.ContextMatcher(),
+				mock.AnythingOfType("*model.StorageMigration")).
+				Run(func(args mock.Arguments) {
+					saved = *args.Get(1).(*model.StorageMigration)
+					if n := len(statuses); n == 0 || statuses[n-1] != saved.Status {
+						statuses = append(statuses, saved.Status)
+					}
+				}).
+				Return(nil)
+			tc.Mocks(db, fs)
+
+			d := NewDeployments(db, fs, 0, false)
+			ctx := identity.WithContext(context.Background(),
+				&identity.Identity{Tenant: tenantID})
+			err := d.ProcessStorageMigrations(ctx, tenantID)
+			if tc.Error != "" {
+				assert.EqualError(t, err, tc.Error)
+				assert.Equal(t, tc.Error, saved.Error)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.Copied, saved.Copied)
+				assert.Equal(t, tc.CopiedSize, saved.CopiedSize)
+				assert.NotNil(t, saved.Finished)
+			}
+			assert.Equal(t, tc.Status, saved.Status)
+			if tc.Statuses != nil {
+				assert.Equal(t, tc.Statuses, statuses)
+			}
+		})
+	}
+}
@@ -183,6 +183,8 @@ func TestDeploymentModelCreateDeployment(t *testing.T) {
 
 		ReportingService bool
 
+		Predecessor *model.Deployment
+
 		OutputError error
 		OutputBody  bool
 	}{
@@ -296,6 +298,30 @@ func TestDeploymentModelCreateDeployment(t *testing.T) {
 
 			OutputError: ErrConflictingDeployment,
 		},
+		"ok, after deployment": {
+			InputConstructor: &model.DeploymentConstructor{
+				Name:            "NYC Production",
+				ArtifactName:    "App 123",
+				Devices:         []string{"b532b01a-9313-404f-8d19-e7fcbe5cc347"},
+				AfterDeployment: "f826484e-1157-4109-af21-304e6d711560",
+			},
+			CallGetDeviceGroups: true,
+			Predecessor: &model.Deployment{
+				Id: "f826484e-1157-4109-af21-304e6d711560",
+			},
+
+			OutputBody: true,
+		},
+		"ko, after deployment not found": {
+			InputConstructor: &model.DeploymentConstructor{
+				Name:            "NYC Production",
+				ArtifactName:    "App 123",
+				Devices:         []string{"b532b01a-9313-404f-8d19-e7fcbe5cc347"},
+				AfterDeployment: "f826484e-1157-4109-af21-304e6d711560",
+			},
+
+			OutputError: ErrPredecessorNotFound,
+		},
 	}
 
 	for testCaseName, testCase := range testCases {
@@ -326,6 +352,11 @@ func TestDeploymentModelCreateDeployment(t *testing.T) {
 							Depends: map[string]interface{}{},
 						}, artifactSize)},
 					testCase.InputImagesByNameError)
+			if testCase.InputConstructor != nil &&
+				testCase.InputConstructor.AfterDeployment != "" {
+				db.On("FindDeploymentByID", ctx, testCase.InputConstructor.AfterDeployment).
+					Return(testCase.Predecessor, nil)
+			}
 
 			fs := &fs_mocks.ObjectStorage{}
 			ds := NewDeployments(&db, fs, 0, false)
@@ -455,6 +486,8 @@ func TestUploadLink(t *testing.T) {
 		ctx := context.Background()
 		objStore := new(fs_mocks.ObjectStorage)
 		ds := new(mocks.DataStore)
+		ds.On("FindUnfinishedStorageMigration", mock.Anything).
+			Return(nil, nil)
 		deploy := NewDeployments(ds, objStore, 0, false)
 		objStore.On("PutRequest",
 			h.ContextMatcher(),
@@ -483,6 +516,8 @@ func TestUploadLink(t *testing.T) {
 		})
 		objStore := new(fs_mocks.ObjectStorage)
 		ds := new(mocks.DataStore)
+		ds.On("FindUnfinishedStorageMigration", mock.Anything).
+			Return(nil, nil)
 		deploy := NewDeployments(ds, objStore, 0, false)
 		objStore.On("PutRequest",
 			h.ContextMatcher(),
@@ -512,6 +547,8 @@ func TestUploadLink(t *testing.T) {
 		})
 		objStore := new(fs_mocks.ObjectStorage)
 		ds := new(mocks.DataStore)
+		ds.On("FindUnfinishedStorageMigration", mock.Anything).
+			Return(nil, nil)
 		deploy := NewDeployments(ds, objStore, 0, false)
 		errInternal := errors.New("internal error")
 		ds.On("GetStorageSettings", ctx).
@@ -539,6 +576,8 @@ func TestUploadLink(t *testing.T) {
 		})
 		objStore := new(fs_mocks.ObjectStorage)
 		ds := new(mocks.DataStore)
+		ds.On("FindUnfinishedStorageMigration", mock.Anything).
+			Return(nil, nil)
 		deploy := NewDeployments(ds, objStore, 0, false)
 		errInternal := errors.New("internal error")
 		objStore.On("PutRequest",
@@ -562,12 +601,29 @@ func TestUploadLink(t *testing.T) {
 		objStore.AssertExpectations(t)
 		ds.AssertExpectations(t)
 	})
+	t.Run("error/storage switching", func(t *testing.T) {
+		ctx := context.Background()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		ds.On("FindUnfinishedStorageMigration", ctx).
+			Return(&model.StorageMigration{
+				Status: model.StorageMigrationStatusSwitching,
+			}, nil)
+		deploy := NewDeployments(ds, objStore, 0, false)
+		upLink, err := deploy.UploadLink(ctx, time.Minute, false)
+		assert.ErrorIs(t, err, ErrStorageMigrationSwitching)
+		assert.Nil(t, upLink)
+		objStore.AssertExpectations(t)
+		ds.AssertExpectations(t)
+	})
 	t.Run("error/getting storage settings", func(t *testing.T) {
 		ctx := identity.WithContext(context.Background(), &identity.Identity{
 			Tenant: "123456789012345678901234",
 		})
 		objStore := new(fs_mocks.ObjectStorage)
 		ds := new(mocks.DataStore)
+		ds.On("FindUnfinishedStorageMigration", mock.Anything).
+			Return(nil, nil)
 		deploy := NewDeployments(ds, objStore, 0, false)
 		errInternal := errors.New("internal error")
 		ds.On("GetStorageSettings", ctx).
@@ -633,6 +689,8 @@ func TestCompleteUpload(t *testing.T) {
 
 		Database: func(t *testing.T, self *testCase) *mocks.DataStore {
 			ds := new(mocks.DataStore)
+			ds.On("FindUnfinishedStorageMigration", mock.Anything).
+				Return(nil, nil)
 			ds.On("GetStorageSettings", contextHasIdentity(t, self.Identity)).
 				Return(nil, nil).
 				Once().
@@ -643,6 +701,14 @@ func TestCompleteUpload(t *testing.T) {
 					model.LinkStatusProcessing).
 				Return(nil).
 				Once().
+				On("GetArtifactSignatureSettings",
+					contextHasIdentity(t, self.Identity)).
+				Return(nil, nil).
+				Once().
+				On("ListSigningKeys",
+					contextHasIdentity(t, self.Identity)).
+				Return([]model.SigningKey{}, nil).
+				Once().
 				On("UpdateUploadIntentStatus",
 					contextHasIdentity(t, self.Identity),
 					intentID,
@@ -688,6 +754,8 @@ func TestCompleteUpload(t *testing.T) {
 
 		Database: func(t *testing.T, self *testCase) *mocks.DataStore {
 			ds := new(mocks.DataStore)
+			ds.On("FindUnfinishedStorageMigration", mock.Anything).
+				Return(nil, nil)
 			ds.On("GetStorageSettings", contextHasIdentity(t, self.Identity)).
 				Return(nil, nil).
 				Once().
@@ -698,6 +766,14 @@ func TestCompleteUpload(t *testing.T) {
 					model.LinkStatusProcessing).
 				Return(nil).
 				Once().
+				On("GetArtifactSignatureSettings",
+					contextHasIdentity(t, self.Identity)).
+				Return(nil, nil).
+				Once().
+				On("ListSigningKeys",
+					contextHasIdentity(t, self.Identity)).
+				Return([]model.SigningKey{}, nil).
+				Once().
 				On("UpdateUploadIntentStatus",
 					contextHasIdentity(t, self.Identity),
 					intentID,
@@ -741,6 +817,8 @@ func TestCompleteUpload(t *testing.T) {
 		},
 		Database: func(t *testing.T, self *testCase) *mocks.DataStore {
 			ds := new(mocks.DataStore)
+			ds.On("FindUnfinishedStorageMigration", mock.Anything).
+				Return(nil, nil)
 			ds.On("GetStorageSettings", contextHasIdentity(t, self.Identity)).
 				Return(nil, nil).
 				Once().
@@ -751,6 +829,14 @@ func TestCompleteUpload(t *testing.T) {
 					model.LinkStatusProcessing).
 				Return(nil).
 				Once().
+				On("GetArtifactSignatureSettings",
+					contextHasIdentity(t, self.Identity)).
+				Return(nil, nil).
+				Once().
+				On("ListSigningKeys",
+					contextHasIdentity(t, self.Identity)).
+				Return([]model.SigningKey{}, nil).
+				Once().
 				On("UpdateUploadIntentStatus",
 					contextHasIdentity(t, self.Identity),
 					intentID,
@@ -800,6 +886,8 @@ func TestCompleteUpload(t *testing.T) {
 		},
 		Database: func(t *testing.T, self *testCase) *mocks.DataStore {
 			ds := new(mocks.DataStore)
+			ds.On("FindUnfinishedStorageMigration", mock.Anything).
+				Return(nil, nil)
 			ds.On("GetStorageSettings", contextHasIdentity(t, self.Identity)).
 				Return(nil, nil).
 				Once().
@@ -810,6 +898,14 @@ func TestCompleteUpload(t *testing.T) {
 					model.LinkStatusProcessing).
 				Return(nil).
 				Once().
+				On("GetArtifactSignatureSettings",
+					contextHasIdentity(t, self.Identity)).
+				Return(nil, nil).
+				Once().
+				On("ListSigningKeys",
+					contextHasIdentity(t, self.Identity)).
+				Return([]model.SigningKey{}, nil).
+				Once().
 				On("UpdateUploadIntentStatus",
 					contextHasIdentity(t, self.Identity),
 					intentID,
@@ -851,6 +947,8 @@ func TestCompleteUpload(t *testing.T) {
 
 		Database: func(t *testing.T, self *testCase) *mocks.DataStore {
 			ds := new(mocks.DataStore)
+			ds.On("FindUnfinishedStorageMigration", mock.Anything).
+				Return(nil, nil)
 			ds.On("GetStorageSettings", contextHasIdentity(t, self.Identity)).
 				Return(nil, nil).
 				Once().
@@ -894,6 +992,8 @@ func TestCompleteUpload(t *testing.T) {
 
 		Database: func(t *testing.T, self *testCase) *mocks.DataStore {
 			ds := new(mocks.DataStore)
+			ds.On("FindUnfinishedStorageMigration", mock.Anything).
+				Return(nil, nil)
 			ds.On("GetStorageSettings", contextHasIdentity(t, self.Identity)).
 				Return(nil, nil).
 				Once().
@@ -937,6 +1037,8 @@ func TestCompleteUpload(t *testing.T) {
 
 		Database: func(t *testing.T, self *testCase) *mocks.DataStore {
 			ds := new(mocks.DataStore)
+			ds.On("FindUnfinishedStorageMigration", mock.Anything).
+				Return(nil, nil)
 			ds.On("GetStorageSettings", contextHasIdentity(t, self.Identity)).
 				Return(nil, nil).
 				Once()
@@ -960,6 +1062,8 @@ func TestCompleteUpload(t *testing.T) {
 
 		Database: func(t *testing.T, self *testCase) *mocks.DataStore {
 			ds := new(mocks.DataStore)
+			ds.On("FindUnfinishedStorageMigration", mock.Anything).
+				Return(nil, nil)
 			ds.On("GetStorageSettings", contextHasIdentity(t, self.Identity)).
 				Return(nil, nil).
 				Once()
@@ -983,6 +1087,8 @@ func TestCompleteUpload(t *testing.T) {
 
 		Database: func(t *testing.T, self *testCase) *mocks.DataStore {
 			ds := new(mocks.DataStore)
+			ds.On("FindUnfinishedStorageMigration", mock.Anything).
+				Return(nil, nil)
 			ds.On("GetStorageSettings", contextHasIdentity(t, self.Identity)).
 				Return(nil, testErr).
 				Once()
@@ -0,0 +1,72 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+)
+
+var (
+	ErrDeviceDeploymentNotPaused = errors.New("The device has not paused the deployment")
+)
+
+// ContinueDeviceDeployment continues the device paused by the update control
+// map of the deployment. The device receives the updated map the next time
+// it polls for the deployment.
+func (d *Deployments) ContinueDeviceDeployment(
+	ctx context.Context,
+	deploymentID, deviceID string,
+) error {
+	deviceDeployment, err := d.db.GetDeviceDeployment(ctx, deploymentID, deviceID, false)
+	if err == mongo.ErrStorageNotFound {
+		return ErrStorageNotFound
+	} else if err != nil {
+		return errors.Wrap(err, "Searching for the device deployment")
+	}
+	if model.UpdateControlStatePaused(deviceDeployment.Status) == "" {
+		return ErrDeviceDeploymentNotPaused
+	}
+	_, err = d.db.ContinueDeviceDeployments(ctx, deploymentID, []string{deviceID})
+	if err != nil {
+		return errors.Wrap(err, "failed to continue the device deployment")
+	}
+	return nil
+}
+
+// ContinueDeployment continues the given paused devices of the deployment,
+// or all the paused devices if the list is empty, and returns the number of
+// devices continued.
+func (d *Deployments) ContinueDeployment(
+	ctx context.Context,
+	deploymentID string,
+	deviceIDs []string,
+) (int, error) {
+	deployment, err := d.db.FindDeploymentByID(ctx, deploymentID)
+	if err != nil {
+		return 0, errors.Wrap(err, "Searching for deployment by ID")
+	} else if deployment == nil {
+		return 0, ErrModelDeploymentNotFound
+	}
+	count, err := d.db.ContinueDeviceDeployments(ctx, deploymentID, deviceIDs)
+	if err != nil {
+		return count, errors.Wrap(err, "failed to continue the device deployments")
+	}
+	return count, nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	fs_mocks "github.com/mendersoftware/mender-server/services/deployments/storage/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mongo"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+func TestContinueDeviceDeployment(t *testing.T) {
+	t.Parallel()
+
+	const (
+		deploymentID = "f826484e-1157-4109-af21-304e6d711560"
+		deviceID     = "device"
+	)
+	testCases := map[string]struct {
+		DeviceDeployment *model.DeviceDeployment
+		GetErr           error
+		ContinueErr      error
+
+		Error error
+	}{
+		"ok": {
+			DeviceDeployment: &model.DeviceDeployment{
+				Status: model.DeviceDeploymentStatusPauseBeforeReboot,
+			},
+		},
+		"error, not found": {
+			GetErr: mongo.ErrStorageNotFound,
+			Error:  ErrStorageNotFound,
+		},
+		"error, not paused": {
+			DeviceDeployment: &model.DeviceDeployment{
+				Status: model.DeviceDeploymentStatusInstalling,
+			},
+			Error: ErrDeviceDeploymentNotPaused,
+		},
+		"error, continue": {
+			DeviceDeployment: &model.DeviceDeployment{
+				Status: model.DeviceDeploymentStatusPauseBeforeInstall,
+			},
+			ContinueErr: errors.New("some error"),
+			Error: errors.New("failed to continue the device deployment: " +
+				"some error"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("GetDeviceDeployment", ctx, deploymentID, deviceID, false).
+				Return(tc.DeviceDeployment, tc.GetErr).Once()
+			if tc.DeviceDeployment != nil &&
+				model.UpdateControlStatePaused(tc.DeviceDeployment.Status) != "" {
+				db.On("ContinueDeviceDeployments", ctx, deploymentID, []string{deviceID}).
+					Return(1, tc.ContinueErr).Once()
+			}
+
+			ds := NewDeployments(db, nil, 0, false)
+			err := ds.ContinueDeviceDeployment(ctx, deploymentID, deviceID)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestContinueDeployment(t *testing.T) {
+	t.Parallel()
+
+	const deploymentID = "f826484e-1157-4109-af21-304e6d711560"
+	testCases := map[string]struct {
+		Deployment *model.Deployment
+		Devices    []string
+
+		Count int
+		Error error
+	}{
+		"ok, all devices": {
+			Deployment: &model.Deployment{Id: deploymentID},
+			Count:      3,
+		},
+		"ok, selected devices": {
+			Deployment: &model.Deployment{Id: deploymentID},
+			Devices:    []string{"1", "2"},
+			Count:      2,
+		},
+		"error, deployment not found": {
+			Error: ErrModelDeploymentNotFound,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("FindDeploymentByID", ctx, deploymentID).
+				Return(tc.Deployment, nil).Once()
+			if tc.Deployment != nil {
+				db.On("ContinueDeviceDeployments", ctx, deploymentID, tc.Devices).
+					Return(tc.Count, nil).Once()
+			}
+
+			ds := NewDeployments(db, nil, 0, false)
+			count, err := ds.ContinueDeployment(ctx, deploymentID, tc.Devices)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.Count, count)
+			}
+		})
+	}
+}
+
+func TestGetDeploymentInstructionsUpdateControlMap(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	expired := time.Now().Add(-time.Minute)
+	deployment, _ := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "foo",
+		ArtifactName: "bar",
+		UpdateControlMap: &model.UpdateControlMap{
+			Priority: 1,
+			States: map[string]model.UpdateControlMapState{
+				model.UpdateControlStateArtifactInstall: {
+					Action: model.UpdateControlActionPause,
+				},
+				model.UpdateControlStateArtifactReboot: {
+					Action: model.UpdateControlActionPause,
+				},
+				model.UpdateControlStateArtifactCommit: {
+					Action:      model.UpdateControlActionPause,
+					OnMapExpire: model.UpdateControlActionForceContinue,
+					Expire:      &expired,
+				},
+			},
+		},
+	})
+	deviceDeployment := model.NewDeviceDeployment("device", deployment.Id)
+	deviceDeployment.Status = model.DeviceDeploymentStatusPauseBeforeReboot
+	deviceDeployment.ContinuedStates = []string{model.UpdateControlStateArtifactInstall}
+	deviceDeployment.Image = &model.Image{
+		Id: "image",
+		ArtifactMeta: &model.ArtifactMeta{
+			Name:                  "bar",
+			DeviceTypesCompatible: []string{"hammer"},
+		},
+	}
+
+	db := &mocks.DataStore{}
+	defer db.AssertExpectations(t)
+	fs := &fs_mocks.ObjectStorage{}
+	defer fs.AssertExpectations(t)
+
+	db.On("ListEdgeCaches", ctx).Return([]model.EdgeCache{}, nil).Once()
+	db.On("GetStorageSettings", ctx).Return(nil, nil).Once()
+	fs.On("GetRequest", h.ContextMatcher(), "image", "bar.mender",
+		DefaultUpdateDownloadLinkExpire, true,
+	).Return(&model.Link{Uri: "link"}, nil).Once()
+
+	ds := NewDeployments(db, fs, 0, false)
+	instructions, err := ds.getDeploymentInstructions(ctx, deployment, deviceDeployment,
+		&model.DeploymentNextRequest{
+			DeviceProvides: &model.InstalledDeviceDeployment{
+				ArtifactName: "foo",
+				DeviceType:   "hammer",
+			},
+		})
+	assert.NoError(t, err)
+	if assert.NotNil(t, instructions) {
+		assert.Equal(t, &model.UpdateControlMap{
+			ID:       deployment.Id,
+			Priority: 1,
+			States: map[string]model.UpdateControlMapState{
+				model.UpdateControlStateArtifactInstall: {
+					Action: model.UpdateControlActionContinue,
+				},
+				model.UpdateControlStateArtifactReboot: {
+					Action: model.UpdateControlActionPause,
+				},
+				model.UpdateControlStateArtifactCommit: {
+					Action: model.UpdateControlActionForceContinue,
+				},
+			},
+		}, instructions.UpdateControlMap)
+	}
+}
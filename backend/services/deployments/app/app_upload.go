@@ -0,0 +1,237 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package app
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+var (
+	ErrUploadPartNumber = errors.New(
+		"part number must be between 1 and the maximum number of parts",
+	)
+	ErrUploadPartsMissing = errors.New(
+		"the upload parts must be numbered contiguously starting from 1",
+	)
+	ErrUploadPartTooSmall = errors.New(
+		"all the upload parts except the last must have the minimum part size",
+	)
+	ErrUploadTooLarge = errors.New(
+		"the upload parts exceed the maximum artifact size",
+	)
+	ErrUploadNotPending = errors.New("the upload is not pending")
+)
+
+// InitiateMultipartUpload starts a resumable artifact upload; the parts of
+// the artifact are uploaded individually and assembled in the object
+// storage when the upload is completed.
+func (d *Deployments) InitiateMultipartUpload(
+	ctx context.Context,
+	expire time.Duration,
+) (*model.MultipartUpload, error) {
+	if err := d.checkStorageSwitching(ctx); err != nil {
+		return nil, err
+	}
+	ctx, err := d.contextWithStorageSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	artifactID := uuid.New().String()
+	uploadID, err := d.objectStorage.CreateMultipartUpload(ctx,
+		model.ImagePathFromContext(ctx, artifactID)+fileSuffixTmp,
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "app: failed to create multipart upload")
+	}
+	now := time.Now()
+	upLink := &model.UploadLink{
+		ArtifactID: artifactID,
+		Link: model.Link{
+			Expire: now.Add(expire),
+		},
+		IssuedAt:          now,
+		Status:            model.LinkStatusPending,
+		MultipartUploadID: uploadID,
+	}
+	err = d.db.InsertUploadIntent(ctx, upLink)
+	if err != nil {
+		return nil, errors.WithMessage(err, "app: error recording the upload intent")
+	}
+	return &model.MultipartUpload{
+		ID:          artifactID,
+		Expire:      upLink.Expire,
+		PartSizeMin: model.MultipartUploadPartSizeMin,
+		PartSizeMax: model.MultipartUploadPartSizeMax,
+		MaxParts:    model.MultipartUploadMaxParts,
+	}, nil
+}
+
+// pendingMultipartUpload returns the context with the storage settings and
+// the upload intent of the pending resumable upload.
+func (d *Deployments) pendingMultipartUpload(
+	ctx context.Context,
+	id string,
+) (context.Context, *model.UploadLink, error) {
+	link, err := d.db.FindUploadIntent(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil, ErrUploadNotFound
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if link.MultipartUploadID == "" || link.Expire.Before(time.Now()) {
+		return nil, nil, ErrUploadNotFound
+	} else if link.Status != model.LinkStatusPending {
+		return nil, nil, ErrUploadNotPending
+	}
+	ctx, err = d.contextWithStorageSettings(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, link, nil
+}
+
+func uploadStorageError(err error) error {
+	if errors.Is(err, storage.ErrUploadNotFound) {
+		return ErrUploadNotFound
+	}
+	return err
+}
+
+// UploadPart stores the part of the resumable upload; uploading a part
+// with the same number again replaces the part.
+func (d *Deployments) UploadPart(
+	ctx context.Context,
+	id string,
+	partNumber int,
+	src io.ReadSeeker,
+) (*model.UploadPart, error) {
+	if partNumber < 1 || partNumber > model.MultipartUploadMaxParts {
+		return nil, ErrUploadPartNumber
+	}
+	ctx, link, err := d.pendingMultipartUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	part, err := d.objectStorage.UploadPart(ctx,
+		model.ImagePathFromContext(ctx, id)+fileSuffixTmp,
+		link.MultipartUploadID, partNumber, src,
+	)
+	if err != nil {
+		return nil, uploadStorageError(err)
+	}
+	return part, nil
+}
+
+// ListUploadParts returns the parts uploaded so far, which allows the
+// clients to resume an interrupted upload.
+func (d *Deployments) ListUploadParts(
+	ctx context.Context,
+	id string,
+) ([]model.UploadPart, error) {
+	ctx, link, err := d.pendingMultipartUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := d.objectStorage.ListParts(ctx,
+		model.ImagePathFromContext(ctx, id)+fileSuffixTmp,
+		link.MultipartUploadID,
+	)
+	if err != nil {
+		return nil, uploadStorageError(err)
+	}
+	return parts, nil
+}
+
+// validateUploadParts checks that the parts make a complete artifact of at
+// most maxSize bytes; the size is not limited if maxSize is zero.
+func validateUploadParts(parts []model.UploadPart, maxSize int64) error {
+	if len(parts) == 0 {
+		return ErrUploadPartsMissing
+	}
+	var size int64
+	for i, part := range parts {
+		size += part.Size
+		if maxSize > 0 && size > maxSize {
+			return ErrUploadTooLarge
+		}
+		if part.PartNumber != i+1 {
+			return ErrUploadPartsMissing
+		}
+		if i < len(parts)-1 && part.Size < model.MultipartUploadPartSizeMin {
+			return ErrUploadPartTooSmall
+		}
+	}
+	return nil
+}
+
+// CompleteMultipartUpload assembles the parts of the resumable upload and
+// processes the artifact like the completed direct uploads.
+func (d *Deployments) CompleteMultipartUpload(ctx context.Context, id string) error {
+	if err := d.checkStorageSwitching(ctx); err != nil {
+		return err
+	}
+	ctxStorage, link, err := d.pendingMultipartUpload(ctx, id)
+	if err != nil {
+		return err
+	}
+	objectPath := model.ImagePathFromContext(ctxStorage, id) + fileSuffixTmp
+	parts, err := d.objectStorage.ListParts(ctxStorage,
+		objectPath, link.MultipartUploadID,
+	)
+	if err != nil {
+		return uploadStorageError(err)
+	}
+	if err = validateUploadParts(parts, d.maxImageSize); err != nil {
+		return err
+	}
+	err = d.objectStorage.CompleteMultipartUpload(ctxStorage,
+		objectPath, link.MultipartUploadID, parts,
+	)
+	if err != nil {
+		return uploadStorageError(err)
+	}
+	return d.CompleteUpload(ctx, id, false, nil)
+}
+
+// AbortMultipartUpload discards the parts of the resumable upload.
+func (d *Deployments) AbortMultipartUpload(ctx context.Context, id string) error {
+	ctxStorage, link, err := d.pendingMultipartUpload(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = d.objectStorage.AbortMultipartUpload(ctxStorage,
+		model.ImagePathFromContext(ctxStorage, id)+fileSuffixTmp,
+		link.MultipartUploadID,
+	)
+	if err != nil && !errors.Is(err, storage.ErrUploadNotFound) {
+		return err
+	}
+	err = d.db.UpdateUploadIntentStatus(ctx, id,
+		model.LinkStatusPending, model.LinkStatusAborted,
+	)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrUploadNotPending
+	}
+	return err
+}
@@ -0,0 +1,442 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+	fs_mocks "github.com/mendersoftware/mender-server/services/deployments/storage/mocks"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+	"github.com/mendersoftware/mender-server/services/deployments/store/mocks"
+	h "github.com/mendersoftware/mender-server/services/deployments/utils/testing"
+)
+
+const (
+	testUploadID      = "9bf1bfff-eeb4-49d4-b55d-d717d407888a"
+	testMultipartID   = "multipart-upload-id"
+	testUploadTenant  = "123456789012345678901234"
+	testUploadObject  = testUploadTenant + "/" + testUploadID + fileSuffixTmp
+	testPartSizeSmall = model.MultipartUploadPartSizeMin - 1
+)
+
+func testUploadContext() context.Context {
+	return identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: testUploadTenant,
+	})
+}
+
+func pendingUploadLink() *model.UploadLink {
+	return &model.UploadLink{
+		ArtifactID: testUploadID,
+		Link: model.Link{
+			Expire:   time.Now().Add(time.Hour),
+			TenantID: testUploadTenant,
+		},
+		Status:            model.LinkStatusPending,
+		MultipartUploadID: testMultipartID,
+	}
+}
+
+func TestInitiateMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ok", func(t *testing.T) {
+		ctx := testUploadContext()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		deploy := NewDeployments(ds, objStore, 0, false)
+		defer objStore.AssertExpectations(t)
+		defer ds.AssertExpectations(t)
+
+		ds.On("FindUnfinishedStorageMigration", h.ContextMatcher()).
+			Return(nil, nil).
+			On("GetStorageSettings", h.ContextMatcher()).
+			Return(nil, nil).
+			Once()
+		objStore.On("CreateMultipartUpload",
+			h.ContextMatcher(),
+			mock.MatchedBy(func(path string) bool {
+				return assert.Regexp(t, `^`+testUploadTenant+
+					`/[0-9a-f]{8}-([0-9a-f]{4}-){3}[0-9a-f]{12}\`+fileSuffixTmp+`$`, path)
+			}),
+		).Return(testMultipartID, nil)
+		ds.On("InsertUploadIntent",
+			h.ContextMatcher(),
+			mock.MatchedBy(func(link *model.UploadLink) bool {
+				return assert.Equal(t, testMultipartID, link.MultipartUploadID) &&
+					assert.Equal(t, model.LinkStatusPending, link.Status) &&
+					assert.WithinDuration(t,
+						time.Now().Add(time.Hour), link.Expire, time.Minute)
+			}),
+		).Return(nil)
+
+		upload, err := deploy.InitiateMultipartUpload(ctx, time.Hour)
+		if assert.NoError(t, err) {
+			assert.NotEmpty(t, upload.ID)
+			assert.Equal(t, int64(model.MultipartUploadPartSizeMin), upload.PartSizeMin)
+			assert.Equal(t, int64(model.MultipartUploadPartSizeMax), upload.PartSizeMax)
+			assert.Equal(t, model.MultipartUploadMaxParts, upload.MaxParts)
+		}
+	})
+
+	t.Run("error/storage", func(t *testing.T) {
+		ctx := testUploadContext()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		deploy := NewDeployments(ds, objStore, 0, false)
+		defer objStore.AssertExpectations(t)
+		defer ds.AssertExpectations(t)
+
+		errInternal := errors.New("internal error")
+		ds.On("FindUnfinishedStorageMigration", h.ContextMatcher()).
+			Return(nil, nil).
+			On("GetStorageSettings", h.ContextMatcher()).
+			Return(nil, nil).
+			Once()
+		objStore.On("CreateMultipartUpload", h.ContextMatcher(), mock.AnythingOfType("string")).
+			Return("", errInternal)
+
+		_, err := deploy.InitiateMultipartUpload(ctx, time.Hour)
+		assert.ErrorIs(t, err, errInternal)
+	})
+
+	t.Run("error/storage switching", func(t *testing.T) {
+		ctx := testUploadContext()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		deploy := NewDeployments(ds, objStore, 0, false)
+		defer objStore.AssertExpectations(t)
+		defer ds.AssertExpectations(t)
+
+		ds.On("FindUnfinishedStorageMigration", h.ContextMatcher()).
+			Return(&model.StorageMigration{
+				Status: model.StorageMigrationStatusSwitching,
+			}, nil)
+
+		_, err := deploy.InitiateMultipartUpload(ctx, time.Hour)
+		assert.ErrorIs(t, err, ErrStorageMigrationSwitching)
+	})
+}
+
+func TestUploadPart(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		PartNumber int
+		Link       *model.UploadLink
+		LinkErr    error
+		Part       *model.UploadPart
+		StorageErr error
+
+		Error error
+	}{
+		"ok": {
+			PartNumber: 1,
+			Link:       pendingUploadLink(),
+			Part:       &model.UploadPart{PartNumber: 1, Size: 4, ETag: "etag"},
+		},
+		"error/part number zero": {
+			PartNumber: 0,
+			Error:      ErrUploadPartNumber,
+		},
+		"error/part number too large": {
+			PartNumber: model.MultipartUploadMaxParts + 1,
+			Error:      ErrUploadPartNumber,
+		},
+		"error/upload not found": {
+			PartNumber: 1,
+			LinkErr:    store.ErrNotFound,
+			Error:      ErrUploadNotFound,
+		},
+		"error/not a multipart upload": {
+			PartNumber: 1,
+			Link: func() *model.UploadLink {
+				link := pendingUploadLink()
+				link.MultipartUploadID = ""
+				return link
+			}(),
+			Error: ErrUploadNotFound,
+		},
+		"error/upload expired": {
+			PartNumber: 1,
+			Link: func() *model.UploadLink {
+				link := pendingUploadLink()
+				link.Expire = time.Now().Add(-time.Minute)
+				return link
+			}(),
+			Error: ErrUploadNotFound,
+		},
+		"error/upload completed": {
+			PartNumber: 1,
+			Link: func() *model.UploadLink {
+				link := pendingUploadLink()
+				link.Status = model.LinkStatusProcessing
+				return link
+			}(),
+			Error: ErrUploadNotPending,
+		},
+		"error/storage upload not found": {
+			PartNumber: 1,
+			Link:       pendingUploadLink(),
+			StorageErr: storage.ErrUploadNotFound,
+			Error:      ErrUploadNotFound,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := testUploadContext()
+			objStore := new(fs_mocks.ObjectStorage)
+			ds := new(mocks.DataStore)
+			deploy := NewDeployments(ds, objStore, 0, false)
+			defer objStore.AssertExpectations(t)
+			defer ds.AssertExpectations(t)
+
+			src := bytes.NewReader([]byte("part"))
+			if tc.Link != nil || tc.LinkErr != nil {
+				ds.On("FindUploadIntent", ctx, testUploadID).
+					Return(tc.Link, tc.LinkErr)
+			}
+			if tc.Part != nil || tc.StorageErr != nil {
+				ds.On("GetStorageSettings", ctx).Return(nil, nil)
+				objStore.On("UploadPart",
+					h.ContextMatcher(),
+					testUploadObject,
+					testMultipartID,
+					tc.PartNumber,
+					src,
+				).Return(tc.Part, tc.StorageErr)
+			}
+
+			part, err := deploy.UploadPart(ctx, testUploadID, tc.PartNumber, src)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.Part, part)
+			}
+		})
+	}
+}
+
+func TestValidateUploadParts(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Parts   []model.UploadPart
+		MaxSize int64
+		Error   error
+	}{
+		"ok": {
+			Parts: []model.UploadPart{
+				{PartNumber: 1, Size: model.MultipartUploadPartSizeMin},
+				{PartNumber: 2, Size: model.MultipartUploadPartSizeMax},
+				{PartNumber: 3, Size: 1},
+			},
+		},
+		"ok/single small part": {
+			Parts: []model.UploadPart{{PartNumber: 1, Size: 1}},
+		},
+		"ok/maximum size": {
+			Parts: []model.UploadPart{
+				{PartNumber: 1, Size: model.MultipartUploadPartSizeMin},
+				{PartNumber: 2, Size: 1},
+			},
+			MaxSize: model.MultipartUploadPartSizeMin + 1,
+		},
+		"error/too large": {
+			Parts: []model.UploadPart{
+				{PartNumber: 1, Size: model.MultipartUploadPartSizeMin},
+				{PartNumber: 2, Size: 2},
+			},
+			MaxSize: model.MultipartUploadPartSizeMin + 1,
+			Error:   ErrUploadTooLarge,
+		},
+		"error/no parts": {
+			Error: ErrUploadPartsMissing,
+		},
+		"error/first part missing": {
+			Parts: []model.UploadPart{
+				{PartNumber: 2, Size: model.MultipartUploadPartSizeMin},
+			},
+			Error: ErrUploadPartsMissing,
+		},
+		"error/gap": {
+			Parts: []model.UploadPart{
+				{PartNumber: 1, Size: model.MultipartUploadPartSizeMin},
+				{PartNumber: 3, Size: model.MultipartUploadPartSizeMin},
+			},
+			Error: ErrUploadPartsMissing,
+		},
+		"error/part too small": {
+			Parts: []model.UploadPart{
+				{PartNumber: 1, Size: testPartSizeSmall},
+				{PartNumber: 2, Size: model.MultipartUploadPartSizeMin},
+			},
+			Error: ErrUploadPartTooSmall,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.ErrorIs(t, validateUploadParts(tc.Parts, tc.MaxSize), tc.Error)
+		})
+	}
+}
+
+func TestCompleteMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error/parts missing", func(t *testing.T) {
+		ctx := testUploadContext()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		deploy := NewDeployments(ds, objStore, 0, false)
+		defer objStore.AssertExpectations(t)
+		defer ds.AssertExpectations(t)
+
+		ds.On("FindUnfinishedStorageMigration", ctx).
+			Return(nil, nil).
+			On("FindUploadIntent", ctx, testUploadID).
+			Return(pendingUploadLink(), nil).
+			On("GetStorageSettings", ctx).
+			Return(nil, nil)
+		objStore.On("ListParts", h.ContextMatcher(), testUploadObject, testMultipartID).
+			Return([]model.UploadPart{}, nil)
+
+		err := deploy.CompleteMultipartUpload(ctx, testUploadID)
+		assert.ErrorIs(t, err, ErrUploadPartsMissing)
+	})
+
+	t.Run("error/too large", func(t *testing.T) {
+		ctx := testUploadContext()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		deploy := NewDeployments(ds, objStore, 0, false).WithMaxImageSize(10)
+		defer objStore.AssertExpectations(t)
+		defer ds.AssertExpectations(t)
+
+		ds.On("FindUnfinishedStorageMigration", ctx).
+			Return(nil, nil).
+			On("FindUploadIntent", ctx, testUploadID).
+			Return(pendingUploadLink(), nil).
+			On("GetStorageSettings", ctx).
+			Return(nil, nil)
+		objStore.On("ListParts", h.ContextMatcher(), testUploadObject, testMultipartID).
+			Return([]model.UploadPart{{PartNumber: 1, Size: 11, ETag: "etag"}}, nil)
+
+		err := deploy.CompleteMultipartUpload(ctx, testUploadID)
+		assert.ErrorIs(t, err, ErrUploadTooLarge)
+	})
+
+	t.Run("error/storage", func(t *testing.T) {
+		ctx := testUploadContext()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		deploy := NewDeployments(ds, objStore, 0, false)
+		defer objStore.AssertExpectations(t)
+		defer ds.AssertExpectations(t)
+
+		parts := []model.UploadPart{{PartNumber: 1, Size: 10, ETag: "etag"}}
+		errInternal := errors.New("internal error")
+		ds.On("FindUnfinishedStorageMigration", ctx).
+			Return(nil, nil).
+			On("FindUploadIntent", ctx, testUploadID).
+			Return(pendingUploadLink(), nil).
+			On("GetStorageSettings", ctx).
+			Return(nil, nil)
+		objStore.On("ListParts", h.ContextMatcher(), testUploadObject, testMultipartID).
+			Return(parts, nil).
+			On("CompleteMultipartUpload",
+				h.ContextMatcher(), testUploadObject, testMultipartID, parts).
+			Return(errInternal)
+
+		err := deploy.CompleteMultipartUpload(ctx, testUploadID)
+		assert.ErrorIs(t, err, errInternal)
+	})
+
+	t.Run("error/storage switching", func(t *testing.T) {
+		ctx := testUploadContext()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		deploy := NewDeployments(ds, objStore, 0, false)
+		defer objStore.AssertExpectations(t)
+		defer ds.AssertExpectations(t)
+
+		ds.On("FindUnfinishedStorageMigration", ctx).
+			Return(&model.StorageMigration{
+				Status: model.StorageMigrationStatusSwitching,
+			}, nil)
+
+		err := deploy.CompleteMultipartUpload(ctx, testUploadID)
+		assert.ErrorIs(t, err, ErrStorageMigrationSwitching)
+	})
+}
+
+func TestAbortMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ok", func(t *testing.T) {
+		ctx := testUploadContext()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		deploy := NewDeployments(ds, objStore, 0, false)
+		defer objStore.AssertExpectations(t)
+		defer ds.AssertExpectations(t)
+
+		ds.On("FindUploadIntent", ctx, testUploadID).
+			Return(pendingUploadLink(), nil).
+			On("GetStorageSettings", ctx).
+			Return(nil, nil).
+			On("UpdateUploadIntentStatus", ctx, testUploadID,
+				model.LinkStatusPending, model.LinkStatusAborted).
+			Return(nil)
+		objStore.On("AbortMultipartUpload",
+			h.ContextMatcher(), testUploadObject, testMultipartID).
+			Return(nil)
+
+		err := deploy.AbortMultipartUpload(ctx, testUploadID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("error/not pending", func(t *testing.T) {
+		ctx := testUploadContext()
+		objStore := new(fs_mocks.ObjectStorage)
+		ds := new(mocks.DataStore)
+		deploy := NewDeployments(ds, objStore, 0, false)
+		defer objStore.AssertExpectations(t)
+		defer ds.AssertExpectations(t)
+
+		link := pendingUploadLink()
+		link.Status = model.LinkStatusAborted
+		ds.On("FindUploadIntent", ctx, testUploadID).
+			Return(link, nil)
+
+		err := deploy.AbortMultipartUpload(ctx, testUploadID)
+		assert.ErrorIs(t, err, ErrUploadNotPending)
+	})
+}
@@ -154,6 +154,10 @@ func TestGenerateImageErrorWhileUploading(t *testing.T) {
 		mock.AnythingOfType("[]string"),
 	).Return(true, nil)
 
+	db.On("FindUnfinishedStorageMigration",
+		h.ContextMatcher(),
+	).Return(nil, nil)
+
 	multipartGenerateImage := &model.MultipartGenerateImageMsg{
 		Name:                  "name",
 		Description:           "description",
@@ -191,6 +195,10 @@ func TestGenerateImageErrorS3GetRequest(t *testing.T) {
 		mock.AnythingOfType("[]string"),
 	).Return(true, nil)
 
+	db.On("FindUnfinishedStorageMigration",
+		h.ContextMatcher(),
+	).Return(nil, nil)
+
 	db.On("GetStorageSettings",
 		ctx,
 	).Return(nil, nil)
@@ -244,6 +252,10 @@ func TestGenerateImageErrorS3DeleteRequest(t *testing.T) {
 		mock.AnythingOfType("[]string"),
 	).Return(true, nil)
 
+	db.On("FindUnfinishedStorageMigration",
+		h.ContextMatcher(),
+	).Return(nil, nil)
+
 	db.On("GetStorageSettings",
 		ctx,
 	).Return(nil, nil)
@@ -338,6 +350,10 @@ func TestGenerateImageErrorWhileStartingWorkflow(t *testing.T) {
 		mock.AnythingOfType("[]string"),
 	).Return(true, nil)
 
+	db.On("FindUnfinishedStorageMigration",
+		h.ContextMatcher(),
+	).Return(nil, nil)
+
 	db.On("GetStorageSettings",
 		ctx,
 	).Return(nil, nil)
@@ -412,6 +428,10 @@ func TestGenerateImageErrorWhileStartingWorkflowAndFailsWhenCleaningUp(t *testin
 		mock.AnythingOfType("[]string"),
 	).Return(true, nil)
 
+	db.On("FindUnfinishedStorageMigration",
+		h.ContextMatcher(),
+	).Return(nil, nil)
+
 	db.On("GetStorageSettings",
 		ctx,
 	).Return(nil, nil)
@@ -490,6 +510,10 @@ func TestGenerateImageSuccessful(t *testing.T) {
 		mock.AnythingOfType("[]string"),
 	).Return(true, nil)
 
+	db.On("FindUnfinishedStorageMigration",
+		h.ContextMatcher(),
+	).Return(nil, nil)
+
 	db.On("GetStorageSettings",
 		ctx,
 	).Return(nil, nil)
@@ -556,6 +580,10 @@ func TestGenerateImageSuccessfulWithTenant(t *testing.T) {
 		mock.AnythingOfType("[]string"),
 	).Return(true, nil)
 
+	db.On("FindUnfinishedStorageMigration",
+		h.ContextMatcher(),
+	).Return(nil, nil)
+
 	identityObject := &identity.Identity{Tenant: "tenant_id"}
 	ctxWithIdentity := identity.WithContext(ctx, identityObject)
 
@@ -70,6 +70,150 @@ func (_m *App) AbortDeviceDeployments(ctx context.Context, deviceID string) erro
 	return r0
 }
 
+// AbortMultipartUpload provides a mock function with given fields: ctx, id
+func (_m *App) AbortMultipartUpload(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AbortMultipartUpload")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddEdgeCache provides a mock function with given fields: ctx, req
+func (_m *App) AddEdgeCache(ctx context.Context, req model.EdgeCacheRequest) (*model.EdgeCache, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddEdgeCache")
+	}
+
+	var r0 *model.EdgeCache
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.EdgeCacheRequest) (*model.EdgeCache, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.EdgeCacheRequest) *model.EdgeCache); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.EdgeCache)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.EdgeCacheRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddSigningKey provides a mock function with given fields: ctx, req
+func (_m *App) AddSigningKey(ctx context.Context, req model.SigningKeyRequest) (*model.SigningKey, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSigningKey")
+	}
+
+	var r0 *model.SigningKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.SigningKeyRequest) (*model.SigningKey, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.SigningKeyRequest) *model.SigningKey); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SigningKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.SigningKeyRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApplyArtifactRetention provides a mock function with given fields: ctx, dryRun
+func (_m *App) ApplyArtifactRetention(ctx context.Context, dryRun bool) (*model.ArtifactRetentionReport, error) {
+	ret := _m.Called(ctx, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyArtifactRetention")
+	}
+
+	var r0 *model.ArtifactRetentionReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, bool) (*model.ArtifactRetentionReport, error)); ok {
+		return rf(ctx, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bool) *model.ArtifactRetentionReport); ok {
+		r0 = rf(ctx, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ArtifactRetentionReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bool) error); ok {
+		r1 = rf(ctx, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthenticateEdgeCache provides a mock function with given fields: ctx, id, sig
+func (_m *App) AuthenticateEdgeCache(ctx context.Context, id string, sig *model.RequestSignature) error {
+	ret := _m.Called(ctx, id, sig)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthenticateEdgeCache")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.RequestSignature) error); ok {
+		r0 = rf(ctx, id, sig)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CompleteMultipartUpload provides a mock function with given fields: ctx, id
+func (_m *App) CompleteMultipartUpload(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteMultipartUpload")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CompleteUpload provides a mock function with given fields: ctx, intentID, skipVerify, metadata
 func (_m *App) CompleteUpload(ctx context.Context, intentID string, skipVerify bool, metadata *model.DirectUploadMetadata) error {
 	ret := _m.Called(ctx, intentID, skipVerify, metadata)
@@ -88,6 +232,52 @@ func (_m *App) CompleteUpload(ctx context.Context, intentID string, skipVerify b
 	return r0
 }
 
+// ContinueDeployment provides a mock function with given fields: ctx, deploymentID, deviceIDs
+func (_m *App) ContinueDeployment(ctx context.Context, deploymentID string, deviceIDs []string) (int, error) {
+	ret := _m.Called(ctx, deploymentID, deviceIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ContinueDeployment")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (int, error)); ok {
+		return rf(ctx, deploymentID, deviceIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) int); ok {
+		r0 = rf(ctx, deploymentID, deviceIDs)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, deploymentID, deviceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ContinueDeviceDeployment provides a mock function with given fields: ctx, deploymentID, deviceID
+func (_m *App) ContinueDeviceDeployment(ctx context.Context, deploymentID string, deviceID string) error {
+	ret := _m.Called(ctx, deploymentID, deviceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ContinueDeviceDeployment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, deploymentID, deviceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateDeployment provides a mock function with given fields: ctx, constructor
 func (_m *App) CreateDeployment(ctx context.Context, constructor *model.DeploymentConstructor) (string, error) {
 	ret := _m.Called(ctx, constructor)
@@ -208,6 +398,24 @@ func (_m *App) DeleteDeviceDeploymentsHistory(ctx context.Context, deviceId stri
 	return r0
 }
 
+// DeleteEdgeCache provides a mock function with given fields: ctx, id
+func (_m *App) DeleteEdgeCache(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEdgeCache")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteImage provides a mock function with given fields: ctx, imageID
 func (_m *App) DeleteImage(ctx context.Context, imageID string) error {
 	ret := _m.Called(ctx, imageID)
@@ -256,6 +464,24 @@ func (_m *App) DeleteReleases(ctx context.Context, releaseNames []string) ([]str
 	return r0, r1
 }
 
+// DeleteSigningKey provides a mock function with given fields: ctx, id
+func (_m *App) DeleteSigningKey(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSigningKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DownloadLink provides a mock function with given fields: ctx, imageID, expire
 func (_m *App) DownloadLink(ctx context.Context, imageID string, expire time.Duration) (*model.Link, error) {
 	ret := _m.Called(ctx, imageID, expire)
@@ -314,6 +540,36 @@ func (_m *App) EditImage(ctx context.Context, id string, constructorData *model.
 	return r0, r1
 }
 
+// ExportDeviceDeploymentsHistory provides a mock function with given fields: ctx, filter
+func (_m *App) ExportDeviceDeploymentsHistory(ctx context.Context, filter model.DeviceDeploymentHistoryFilter) (store.Iterator[model.DeviceDeploymentHistoryRecord], error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportDeviceDeploymentsHistory")
+	}
+
+	var r0 store.Iterator[model.DeviceDeploymentHistoryRecord]
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DeviceDeploymentHistoryFilter) (store.Iterator[model.DeviceDeploymentHistoryRecord], error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.DeviceDeploymentHistoryFilter) store.Iterator[model.DeviceDeploymentHistoryRecord]); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.Iterator[model.DeviceDeploymentHistoryRecord])
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.DeviceDeploymentHistoryFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GenerateConfigurationImage provides a mock function with given fields: ctx, deviceType, deploymentID
 func (_m *App) GenerateConfigurationImage(ctx context.Context, deviceType string, deploymentID string) (io.Reader, error) {
 	ret := _m.Called(ctx, deviceType, deploymentID)
@@ -344,6 +600,34 @@ func (_m *App) GenerateConfigurationImage(ctx context.Context, deviceType string
 	return r0, r1
 }
 
+// GenerateDeltaImage provides a mock function with given fields: ctx, req
+func (_m *App) GenerateDeltaImage(ctx context.Context, req *model.GenerateDeltaRequest) (string, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateDeltaImage")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GenerateDeltaRequest) (string, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GenerateDeltaRequest) string); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GenerateDeltaRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GenerateImage provides a mock function with given fields: ctx, multipartUploadMsg
 func (_m *App) GenerateImage(ctx context.Context, multipartUploadMsg *model.MultipartGenerateImageMsg) (string, error) {
 	ret := _m.Called(ctx, multipartUploadMsg)
@@ -372,6 +656,96 @@ func (_m *App) GenerateImage(ctx context.Context, multipartUploadMsg *model.Mult
 	return r0, r1
 }
 
+// GetArtifactContents provides a mock function with given fields: ctx, id
+func (_m *App) GetArtifactContents(ctx context.Context, id string) (*model.ArtifactContents, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetArtifactContents")
+	}
+
+	var r0 *model.ArtifactContents
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.ArtifactContents, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.ArtifactContents); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ArtifactContents)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetArtifactRetentionSettings provides a mock function with given fields: ctx
+func (_m *App) GetArtifactRetentionSettings(ctx context.Context) (*model.ArtifactRetentionSettings, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetArtifactRetentionSettings")
+	}
+
+	var r0 *model.ArtifactRetentionSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.ArtifactRetentionSettings, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.ArtifactRetentionSettings); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ArtifactRetentionSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetArtifactSignatureSettings provides a mock function with given fields: ctx
+func (_m *App) GetArtifactSignatureSettings(ctx context.Context) (*model.ArtifactSignatureSettings, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetArtifactSignatureSettings")
+	}
+
+	var r0 *model.ArtifactSignatureSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.ArtifactSignatureSettings, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.ArtifactSignatureSettings); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ArtifactSignatureSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDeployment provides a mock function with given fields: ctx, deploymentID
 func (_m *App) GetDeployment(ctx context.Context, deploymentID string) (*model.Deployment, error) {
 	ret := _m.Called(ctx, deploymentID)
@@ -432,6 +806,36 @@ func (_m *App) GetDeploymentForDeviceWithCurrent(ctx context.Context, deviceID s
 	return r0, r1
 }
 
+// GetDeploymentPhases provides a mock function with given fields: ctx, deploymentID
+func (_m *App) GetDeploymentPhases(ctx context.Context, deploymentID string) ([]model.DeploymentPhaseInfo, error) {
+	ret := _m.Called(ctx, deploymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeploymentPhases")
+	}
+
+	var r0 []model.DeploymentPhaseInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]model.DeploymentPhaseInfo, error)); ok {
+		return rf(ctx, deploymentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.DeploymentPhaseInfo); ok {
+		r0 = rf(ctx, deploymentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.DeploymentPhaseInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, deploymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDeploymentStats provides a mock function with given fields: ctx, deploymentID
 func (_m *App) GetDeploymentStats(ctx context.Context, deploymentID string) (model.Stats, error) {
 	ret := _m.Called(ctx, deploymentID)
@@ -499,6 +903,36 @@ func (_m *App) GetDeploymentsStats(ctx context.Context, deploymentIDs ...string)
 	return r0, r1
 }
 
+// GetDeviceDeploymentAttemptLog provides a mock function with given fields: ctx, deviceID, deploymentID, attempt
+func (_m *App) GetDeviceDeploymentAttemptLog(ctx context.Context, deviceID string, deploymentID string, attempt int) (*model.DeploymentLog, error) {
+	ret := _m.Called(ctx, deviceID, deploymentID, attempt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeviceDeploymentAttemptLog")
+	}
+
+	var r0 *model.DeploymentLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) (*model.DeploymentLog, error)); ok {
+		return rf(ctx, deviceID, deploymentID, attempt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) *model.DeploymentLog); ok {
+		r0 = rf(ctx, deviceID, deploymentID, attempt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DeploymentLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, deviceID, deploymentID, attempt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDeviceDeploymentLastStatus provides a mock function with given fields: ctx, devicesIds
 func (_m *App) GetDeviceDeploymentLastStatus(ctx context.Context, devicesIds []string) (model.DeviceDeploymentLastStatuses, error) {
 	ret := _m.Called(ctx, devicesIds)
@@ -661,6 +1095,66 @@ func (_m *App) GetDevicesListForDeployment(ctx context.Context, query store.List
 	return r0, r1, r2
 }
 
+// GetDownloadUsage provides a mock function with given fields: ctx, filter
+func (_m *App) GetDownloadUsage(ctx context.Context, filter model.DownloadUsageFilter) (*model.DownloadUsage, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDownloadUsage")
+	}
+
+	var r0 *model.DownloadUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DownloadUsageFilter) (*model.DownloadUsage, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.DownloadUsageFilter) *model.DownloadUsage); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DownloadUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.DownloadUsageFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEdgeCache provides a mock function with given fields: ctx, id
+func (_m *App) GetEdgeCache(ctx context.Context, id string) (*model.EdgeCache, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEdgeCache")
+	}
+
+	var r0 *model.EdgeCache
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.EdgeCache, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.EdgeCache); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.EdgeCache)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetImage provides a mock function with given fields: ctx, id
 func (_m *App) GetImage(ctx context.Context, id string) (*model.Image, error) {
 	ret := _m.Called(ctx, id)
@@ -742,8 +1236,38 @@ func (_m *App) GetRelease(ctx context.Context, releaseName string) (*model.Relea
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, releaseName)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, releaseName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReleasesUpdateTypes provides a mock function with given fields: ctx
+func (_m *App) GetReleasesUpdateTypes(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReleasesUpdateTypes")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -751,29 +1275,29 @@ func (_m *App) GetRelease(ctx context.Context, releaseName string) (*model.Relea
 	return r0, r1
 }
 
-// GetReleasesUpdateTypes provides a mock function with given fields: ctx
-func (_m *App) GetReleasesUpdateTypes(ctx context.Context) ([]string, error) {
-	ret := _m.Called(ctx)
+// GetStorageMigration provides a mock function with given fields: ctx, id
+func (_m *App) GetStorageMigration(ctx context.Context, id string) (*model.StorageMigration, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetReleasesUpdateTypes")
+		panic("no return value specified for GetStorageMigration")
 	}
 
-	var r0 []string
+	var r0 *model.StorageMigration
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.StorageMigration, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.StorageMigration); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]string)
+			r0 = ret.Get(0).(*model.StorageMigration)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -857,6 +1381,36 @@ func (_m *App) HealthCheck(ctx context.Context) error {
 	return r0
 }
 
+// InitiateMultipartUpload provides a mock function with given fields: ctx, expire
+func (_m *App) InitiateMultipartUpload(ctx context.Context, expire time.Duration) (*model.MultipartUpload, error) {
+	ret := _m.Called(ctx, expire)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InitiateMultipartUpload")
+	}
+
+	var r0 *model.MultipartUpload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) (*model.MultipartUpload, error)); ok {
+		return rf(ctx, expire)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) *model.MultipartUpload); ok {
+		r0 = rf(ctx, expire)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.MultipartUpload)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, expire)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // IsDeploymentFinished provides a mock function with given fields: ctx, deploymentID
 func (_m *App) IsDeploymentFinished(ctx context.Context, deploymentID string) (bool, error) {
 	ret := _m.Called(ctx, deploymentID)
@@ -885,6 +1439,36 @@ func (_m *App) IsDeploymentFinished(ctx context.Context, deploymentID string) (b
 	return r0, r1
 }
 
+// ListEdgeCaches provides a mock function with given fields: ctx
+func (_m *App) ListEdgeCaches(ctx context.Context) ([]model.EdgeCache, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListEdgeCaches")
+	}
+
+	var r0 []model.EdgeCache
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.EdgeCache, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.EdgeCache); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.EdgeCache)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListImages provides a mock function with given fields: ctx, filters
 func (_m *App) ListImages(ctx context.Context, filters *model.ReleaseOrImageFilter) ([]*model.Image, int, error) {
 	ret := _m.Called(ctx, filters)
@@ -982,6 +1566,66 @@ func (_m *App) ListReleaseTags(ctx context.Context) (model.Tags, error) {
 	return r0, r1
 }
 
+// ListSigningKeys provides a mock function with given fields: ctx
+func (_m *App) ListSigningKeys(ctx context.Context) ([]model.SigningKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSigningKeys")
+	}
+
+	var r0 []model.SigningKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.SigningKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.SigningKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SigningKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUploadParts provides a mock function with given fields: ctx, id
+func (_m *App) ListUploadParts(ctx context.Context, id string) ([]model.UploadPart, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUploadParts")
+	}
+
+	var r0 []model.UploadPart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]model.UploadPart, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []model.UploadPart); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UploadPart)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // LookupDeployment provides a mock function with given fields: ctx, query
 func (_m *App) LookupDeployment(ctx context.Context, query model.Query) ([]*model.Deployment, int64, error) {
 	ret := _m.Called(ctx, query)
@@ -1019,6 +1663,36 @@ func (_m *App) LookupDeployment(ctx context.Context, query model.Query) ([]*mode
 	return r0, r1, r2
 }
 
+// PreviewDeployment provides a mock function with given fields: ctx, constructor, query
+func (_m *App) PreviewDeployment(ctx context.Context, constructor *model.DeploymentConstructor, query model.DeploymentPreviewQuery) (*model.DeploymentPreview, error) {
+	ret := _m.Called(ctx, constructor, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PreviewDeployment")
+	}
+
+	var r0 *model.DeploymentPreview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.DeploymentConstructor, model.DeploymentPreviewQuery) (*model.DeploymentPreview, error)); ok {
+		return rf(ctx, constructor, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.DeploymentConstructor, model.DeploymentPreviewQuery) *model.DeploymentPreview); ok {
+		r0 = rf(ctx, constructor, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DeploymentPreview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.DeploymentConstructor, model.DeploymentPreviewQuery) error); ok {
+		r1 = rf(ctx, constructor, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ProvisionTenant provides a mock function with given fields: ctx, tenant_id
 func (_m *App) ProvisionTenant(ctx context.Context, tenant_id string) error {
 	ret := _m.Called(ctx, tenant_id)
@@ -1055,6 +1729,72 @@ func (_m *App) ReplaceReleaseTags(ctx context.Context, releaseName string, tags
 	return r0
 }
 
+// ReportEdgeCacheArtifacts provides a mock function with given fields: ctx, id, report
+func (_m *App) ReportEdgeCacheArtifacts(ctx context.Context, id string, report model.EdgeCacheReport) error {
+	ret := _m.Called(ctx, id, report)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReportEdgeCacheArtifacts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.EdgeCacheReport) error); ok {
+		r0 = rf(ctx, id, report)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResumeDeploymentPhase provides a mock function with given fields: ctx, deploymentID, phaseID
+func (_m *App) ResumeDeploymentPhase(ctx context.Context, deploymentID string, phaseID string) error {
+	ret := _m.Called(ctx, deploymentID, phaseID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResumeDeploymentPhase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, deploymentID, phaseID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResumeStorageMigration provides a mock function with given fields: ctx, id
+func (_m *App) ResumeStorageMigration(ctx context.Context, id string) (*model.StorageMigration, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResumeStorageMigration")
+	}
+
+	var r0 *model.StorageMigration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.StorageMigration, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.StorageMigration); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.StorageMigration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveDeviceDeploymentLog provides a mock function with given fields: ctx, deviceID, deploymentID, logs
 func (_m *App) SaveDeviceDeploymentLog(ctx context.Context, deviceID string, deploymentID string, logs []model.LogMessage) error {
 	ret := _m.Called(ctx, deviceID, deploymentID, logs)
@@ -1073,6 +1813,79 @@ func (_m *App) SaveDeviceDeploymentLog(ctx context.Context, deviceID string, dep
 	return r0
 }
 
+// SearchDeviceDeploymentLogs provides a mock function with given fields: ctx, search
+func (_m *App) SearchDeviceDeploymentLogs(ctx context.Context, search model.DeploymentLogSearch) ([]model.DeploymentLogMatch, int, error) {
+	ret := _m.Called(ctx, search)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchDeviceDeploymentLogs")
+	}
+
+	var r0 []model.DeploymentLogMatch
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DeploymentLogSearch) ([]model.DeploymentLogMatch, int, error)); ok {
+		return rf(ctx, search)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.DeploymentLogSearch) []model.DeploymentLogMatch); ok {
+		r0 = rf(ctx, search)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.DeploymentLogMatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.DeploymentLogSearch) int); ok {
+		r1 = rf(ctx, search)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.DeploymentLogSearch) error); ok {
+		r2 = rf(ctx, search)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetArtifactRetentionSettings provides a mock function with given fields: ctx, settings
+func (_m *App) SetArtifactRetentionSettings(ctx context.Context, settings *model.ArtifactRetentionSettings) error {
+	ret := _m.Called(ctx, settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetArtifactRetentionSettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArtifactRetentionSettings) error); ok {
+		r0 = rf(ctx, settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetArtifactSignatureSettings provides a mock function with given fields: ctx, settings
+func (_m *App) SetArtifactSignatureSettings(ctx context.Context, settings *model.ArtifactSignatureSettings) error {
+	ret := _m.Called(ctx, settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetArtifactSignatureSettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArtifactSignatureSettings) error); ok {
+		r0 = rf(ctx, settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetStorageSettings provides a mock function with given fields: ctx, storageSettings
 func (_m *App) SetStorageSettings(ctx context.Context, storageSettings *model.StorageSettings) error {
 	ret := _m.Called(ctx, storageSettings)
@@ -1091,6 +1904,36 @@ func (_m *App) SetStorageSettings(ctx context.Context, storageSettings *model.St
 	return r0
 }
 
+// StartStorageMigration provides a mock function with given fields: ctx, req
+func (_m *App) StartStorageMigration(ctx context.Context, req model.StorageMigrationRequest) (*model.StorageMigration, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartStorageMigration")
+	}
+
+	var r0 *model.StorageMigration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.StorageMigrationRequest) (*model.StorageMigration, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.StorageMigrationRequest) *model.StorageMigration); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.StorageMigration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.StorageMigrationRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateDeploymentsWithArtifactName provides a mock function with given fields: ctx, artifactName
 func (_m *App) UpdateDeploymentsWithArtifactName(ctx context.Context, artifactName string) error {
 	ret := _m.Called(ctx, artifactName)
@@ -1175,6 +2018,36 @@ func (_m *App) UploadLink(ctx context.Context, expire time.Duration, skipVerify
 	return r0, r1
 }
 
+// UploadPart provides a mock function with given fields: ctx, id, partNumber, src
+func (_m *App) UploadPart(ctx context.Context, id string, partNumber int, src io.ReadSeeker) (*model.UploadPart, error) {
+	ret := _m.Called(ctx, id, partNumber, src)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UploadPart")
+	}
+
+	var r0 *model.UploadPart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, io.ReadSeeker) (*model.UploadPart, error)); ok {
+		return rf(ctx, id, partNumber, src)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, io.ReadSeeker) *model.UploadPart); ok {
+		r0 = rf(ctx, id, partNumber, src)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UploadPart)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, io.ReadSeeker) error); ok {
+		r1 = rf(ctx, id, partNumber, src)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewApp creates a new instance of App. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewApp(t interface {
@@ -645,3 +645,117 @@ func TestAbortDeviceDeployments(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateDeviceDeploymentStatusFailurePolicy(t *testing.T) {
+	ctx := context.TODO()
+
+	testCases := map[string]struct {
+		policy *model.DeploymentFailurePolicy
+		stats  model.Stats
+
+		abort bool
+	}{
+		"ok, no policy": {
+			stats: model.Stats{
+				model.DeviceDeploymentStatusFailureStr: 5,
+			},
+		},
+		"ok, below threshold": {
+			policy: &model.DeploymentFailurePolicy{MaxFailures: 2},
+			stats: model.Stats{
+				model.DeviceDeploymentStatusFailureStr: 1,
+			},
+		},
+		"ok, max failures exceeded": {
+			policy: &model.DeploymentFailurePolicy{MaxFailures: 2},
+			stats: model.Stats{
+				model.DeviceDeploymentStatusFailureStr: 2,
+			},
+			abort: true,
+		},
+		"ok, failure rate exceeded": {
+			policy: &model.DeploymentFailurePolicy{
+				MaxFailureRate: 20,
+				MinFinished:    4,
+			},
+			stats: model.Stats{
+				model.DeviceDeploymentStatusSuccessStr: 3,
+				model.DeviceDeploymentStatusFailureStr: 0,
+			},
+			abort: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fakeDeployment, err := model.NewDeploymentFromConstructor(
+				&model.DeploymentConstructor{
+					Name:          "foo",
+					ArtifactName:  "bar",
+					FailurePolicy: tc.policy,
+				},
+			)
+			assert.NoError(t, err)
+			fakeDeployment.MaxDevices = 100
+			for status, count := range tc.stats {
+				fakeDeployment.Stats[status] = count
+			}
+
+			devId := "somedevice"
+			fakeDeviceDeployment := model.NewDeviceDeployment(
+				devId, fakeDeployment.Id)
+			fakeDeviceDeployment.Status = model.DeviceDeploymentStatusInstalling
+
+			db := &mocks.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("GetDeviceDeployment", ctx,
+				fakeDeployment.Id, devId, false).
+				Return(fakeDeviceDeployment, nil).Once()
+			db.On("UpdateDeviceDeploymentStatus", ctx,
+				devId,
+				fakeDeployment.Id,
+				mock.AnythingOfType("model.DeviceDeploymentState"),
+				model.DeviceDeploymentStatusInstalling,
+			).Return(model.DeviceDeploymentStatusInstalling, nil).Once()
+			db.On("FindDeploymentByID", ctx, fakeDeployment.Id).
+				Return(fakeDeployment, nil).Once()
+			db.On("UpdateStatsInc", ctx,
+				fakeDeployment.Id,
+				model.DeviceDeploymentStatusInstalling,
+				model.DeviceDeploymentStatusFailure).Run(func(args mock.Arguments) {
+				fakeDeployment.Stats.Inc(model.DeviceDeploymentStatusFailure)
+			}).Return(fakeDeployment.Stats, nil).Once()
+			db.On("SetDeploymentStatus", ctx,
+				fakeDeployment.Id,
+				model.DeploymentStatusInProgress,
+				mock.AnythingOfType("time.Time")).Return(nil).Maybe()
+			if tc.abort {
+				db.On("SetDeploymentAbortReason", ctx,
+					fakeDeployment.Id,
+					mock.AnythingOfType("string"),
+				).Return(nil).Once()
+				db.On("AbortDeviceDeployments", ctx, fakeDeployment.Id).
+					Return(nil).Once()
+				db.On("AggregateDeviceDeploymentByStatus", ctx, fakeDeployment.Id).
+					Return(fakeDeployment.Stats, nil).Once()
+				db.On("UpdateStats", ctx, fakeDeployment.Id, fakeDeployment.Stats).
+					Return(nil).Once()
+				db.On("SetDeploymentStatus", ctx,
+					fakeDeployment.Id,
+					model.DeploymentStatusFinished,
+					mock.AnythingOfType("time.Time")).Return(nil).Once()
+			}
+			db.On("SaveLastDeviceDeploymentStatus", ctx,
+				mock.AnythingOfType("model.DeviceDeployment"),
+			).Return(nil).Once()
+
+			ds := NewDeployments(db, nil, 0, false)
+
+			err = ds.UpdateDeviceDeploymentStatus(ctx, fakeDeployment.Id, devId,
+				model.DeviceDeploymentState{
+					Status: model.DeviceDeploymentStatusFailure,
+				})
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -38,6 +38,7 @@ import (
 const (
 	healthURL                          = "/api/v1/health"
 	generateArtifactURL                = "/api/v1/workflow/generate_artifact"
+	generateDeltaArtifactURL           = "/api/v1/workflow/generate_delta_artifact"
 	reindexReportingURL                = "/api/v1/workflow/reindex_reporting"
 	reindexReportingDeploymentURL      = "/api/v1/workflow/reindex_reporting_deployment"
 	reindexReportingDeploymentBatchURL = "/api/v1/workflow/reindex_reporting_deployment/batch"
@@ -59,6 +60,7 @@ type Client interface {
 		ctx context.Context,
 		multipartGenerateImageMsg *model.MultipartGenerateImageMsg,
 	) error
+	StartGenerateDeltaArtifact(ctx context.Context, msg *model.GenerateDeltaMsg) error
 	StartReindexReporting(c context.Context, device string) error
 	StartReindexReportingDeployment(c context.Context, device, deployment, id string) error
 	StartReindexReportingDeploymentBatch(c context.Context, info []DeviceDeploymentShortInfo) error
@@ -145,6 +147,41 @@ func (c *client) StartGenerateArtifact(
 	return nil
 }
 
+func (c *client) StartGenerateDeltaArtifact(
+	ctx context.Context,
+	msg *model.GenerateDeltaMsg,
+) error {
+	l := log.FromContext(ctx)
+	l.Debugf("Submit generate delta artifact: tenantID=%s, artifactID=%s",
+		msg.TenantID, msg.ArtifactID)
+
+	workflowsURL := c.baseURL + generateDeltaArtifactURL
+
+	payload, _ := json.Marshal(msg)
+	req, err := http.NewRequestWithContext(ctx,
+		http.MethodPost, workflowsURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to start workflow: generate_delta_artifact")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			body = []byte("<failed to read>")
+		}
+		l.Errorf("generate delta artifact failed with status %v, response text: %s",
+			res.StatusCode, body)
+		return errors.New("failed to start workflow: generate_delta_artifact")
+	}
+	return nil
+}
+
 func (c *client) StartReindexReporting(ctx context.Context, device string) error {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -556,3 +556,58 @@ func TestReindexDeploymentBatchWorkflow(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateDeltaArtifact(t *testing.T) {
+	t.Parallel()
+
+	msg := &model.GenerateDeltaMsg{
+		ArtifactID:            "artifact_id",
+		Name:                  "name",
+		Description:           "description",
+		DeviceTypesCompatible: []string{"Beagle Bone"},
+		GetSourceURI:          "https://localhost/source",
+		GetTargetURI:          "https://localhost/target",
+		TenantID:              "tenant_id",
+	}
+	testCases := map[string]struct {
+		Code  int
+		Error error
+	}{
+		"ok": {
+			Code: http.StatusCreated,
+		},
+		"error": {
+			Code:  http.StatusBadRequest,
+			Error: errors.New("failed to start workflow: generate_delta_artifact"),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, http.MethodPost, r.Method)
+					assert.Equal(t, generateDeltaArtifactURL, r.URL.Path)
+					assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+					var actual model.GenerateDeltaMsg
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&actual))
+					assert.Equal(t, *msg, actual)
+					w.WriteHeader(tc.Code)
+				},
+			))
+			defer srv.Close()
+
+			workflowsClient := NewClient().(*client)
+			workflowsClient.baseURL = srv.URL
+
+			err := workflowsClient.StartGenerateDeltaArtifact(context.Background(), msg)
+			if tc.Error != nil {
+				assert.EqualError(t, err, tc.Error.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
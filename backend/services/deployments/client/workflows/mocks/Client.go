@@ -66,6 +66,24 @@ func (_m *Client) StartGenerateArtifact(ctx context.Context, multipartGenerateIm
 	return r0
 }
 
+// StartGenerateDeltaArtifact provides a mock function with given fields: ctx, msg
+func (_m *Client) StartGenerateDeltaArtifact(ctx context.Context, msg *model.GenerateDeltaMsg) error {
+	ret := _m.Called(ctx, msg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartGenerateDeltaArtifact")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GenerateDeltaMsg) error); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // StartReindexReporting provides a mock function with given fields: c, device
 func (_m *Client) StartReindexReporting(c context.Context, device string) error {
 	ret := _m.Called(c, device)
@@ -62,6 +62,10 @@ const (
 	SettingsStorageUploadExpireSeconds          = SettingStorage + ".upload_expire_seconds"
 	SettingsStorageUploadExpireSecondsDefault   = 3600
 
+	SettingsStorageMultipartUploadExpireSeconds = SettingStorage +
+		".multipart_upload_expire_seconds"
+	SettingsStorageMultipartUploadExpireSecondsDefault = 86400
+
 	SettingsAws                       = "aws"
 	SettingAwsS3Region                = SettingsAws + ".region"
 	SettingAwsS3RegionDefault         = "us-east-1"
@@ -90,6 +94,17 @@ const (
 	SettingAzureSharedKeyAccountKey = SettingAzureSharedKey + ".account_key"
 	SettingAzureSharedKeyURI        = SettingAzureSharedKey + ".uri"
 
+	SettingFilesystem            = "filesystem"
+	SettingFilesystemPath        = SettingFilesystem + ".path"
+	SettingFilesystemPathDefault = "/var/lib/mender/deployments"
+	// SettingFilesystemExternalURI sets the base URL of the signed storage
+	// requests handed out to the clients; defaults to the storage endpoint
+	// of the deployments service under presign.url_hostname.
+	SettingFilesystemExternalURI = SettingFilesystem + ".external_uri"
+	// SettingFilesystemURI sets the base URL of the signed storage
+	// requests used by the other services (e.g. artifact generation).
+	SettingFilesystemURI = SettingFilesystem + ".uri"
+
 	SettingMongo        = "mongo-url"
 	SettingMongoDefault = "mongodb://mongo-deployments:27017"
 
@@ -155,11 +170,17 @@ const (
 	// Max Request body size
 	SettingMaxRequestSize        = "request_size_limit"
 	SettingMaxRequestSizeDefault = 1024 * 1024 // 1 MiB
+
+	// SettingDeploymentLogsExpireDays sets the number of days after which
+	// the device deployment logs are removed; 0 keeps the logs forever.
+	SettingDeploymentLogsExpireDays        = "deployment_logs_expire_days"
+	SettingDeploymentLogsExpireDaysDefault = 0
 )
 
 const (
-	StorageTypeAWS   = "aws"
-	StorageTypeAzure = "azure"
+	StorageTypeAWS        = "aws"
+	StorageTypeAzure      = "azure"
+	StorageTypeFilesystem = "filesystem"
 )
 
 const (
@@ -214,9 +235,9 @@ func ValidateHttps(c config.Reader) error {
 
 func ValidateStorage(c config.Reader) error {
 	svc := c.GetString(SettingDefaultStorage)
-	if svc != StorageTypeAWS && svc != StorageTypeAzure {
+	if svc != StorageTypeAWS && svc != StorageTypeAzure && svc != StorageTypeFilesystem {
 		return fmt.Errorf(
-			`setting "%s" (%s) must be one of "aws" or "azure"`,
+			`setting "%s" (%s) must be one of "aws", "azure" or "filesystem"`,
 			SettingDefaultStorage, svc,
 		)
 	}
@@ -303,6 +324,9 @@ var (
 		{Key: SettingsStorageDownloadExpireSeconds,
 			Value: SettingsStorageDownloadExpireSecondsDefault},
 		{Key: SettingsStorageUploadExpireSeconds, Value: SettingsStorageUploadExpireSecondsDefault},
+		{Key: SettingsStorageMultipartUploadExpireSeconds,
+			Value: SettingsStorageMultipartUploadExpireSecondsDefault},
+		{Key: SettingFilesystemPath, Value: SettingFilesystemPathDefault},
 		{Key: SettingMongo, Value: SettingMongoDefault},
 		{Key: SettingDbSSL, Value: SettingDbSSLDefault},
 		{Key: SettingDbSSLSkipVerify, Value: SettingDbSSLSkipVerifyDefault},
@@ -318,5 +342,6 @@ var (
 		{Key: SettingPresignScheme, Value: SettingPresignSchemeDefault},
 		{Key: SettingDisableNewReleasesFeature, Value: SettingDisableNewReleasesFeatureDefault},
 		{Key: SettingMaxRequestSize, Value: SettingMaxRequestSizeDefault},
+		{Key: SettingDeploymentLogsExpireDays, Value: SettingDeploymentLogsExpireDaysDefault},
 	}
 )
@@ -132,9 +132,52 @@ func doMain(args []string) {
 						"to be removed.",
 					Value: time.Second * 3,
 				},
+				cli.BoolFlag{
+					Name: "retention",
+					Usage: "Remove the artifacts according to the " +
+						"retention policies of the tenants.",
+				},
+				cli.DurationFlag{
+					Name: "retention-interval",
+					Usage: "Time interval to apply the artifact " +
+						"retention policies; ignored in cron mode.",
+					Value: time.Hour * 24,
+				},
+				cli.BoolFlag{
+					Name: "dry-run",
+					Usage: "Do not remove any artifact," +
+						" just report the artifacts to be removed.",
+				},
 			},
 			Action: cmdStorageDaemon,
 		},
+		{
+			Name: "expire-deployments",
+			Usage: "Finish the deployments whose end time is past, " +
+				"independently of the devices polling for updates",
+			Flags: []cli.Flag{
+				cli.DurationFlag{
+					Name: "interval",
+					Usage: "Time interval to look up the expired " +
+						"deployments; a value of 0 runs once and " +
+						"terminates (cron mode).",
+					Value: 0,
+				},
+			},
+			Action: cmdExpireDeployments,
+		},
+		{
+			Name: "migrate-storage",
+			Usage: "Copy the artifacts of the tenants with a pending " +
+				"storage migration to the target storage",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "tenant",
+					Usage: "Tenant ID (optional) - migrate just a single tenant.",
+				},
+			},
+			Action: cmdMigrateStorage,
+		},
 		{
 			Name:  "version",
 			Usage: "Show version information",
@@ -253,11 +296,85 @@ func cmdStorageDaemon(args *cli.Context) error {
 	}
 	database := mongo.NewDataStoreMongoWithClient(mgo)
 	app := app.NewDeployments(database, objectStorage, 0, false)
-	return app.CleanupExpiredUploads(
-		ctx,
-		args.Duration("interval"),
-		args.Duration("time-jitter"),
-	)
+	interval := args.Duration("interval")
+	if !args.Bool("retention") {
+		return app.CleanupExpiredUploads(
+			ctx,
+			interval,
+			args.Duration("time-jitter"),
+		)
+	}
+
+	retentionInterval := args.Duration("retention-interval")
+	if interval == 0 {
+		retentionInterval = 0
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errChan := make(chan error, 2)
+	go func() {
+		errChan <- app.CleanupExpiredUploads(
+			ctx,
+			interval,
+			args.Duration("time-jitter"),
+		)
+	}()
+	go func() {
+		errChan <- app.EnforceArtifactRetention(
+			ctx,
+			retentionInterval,
+			args.Bool("dry-run"),
+		)
+	}()
+	// stop the other routine as soon as one of them fails
+	var errReturned error
+	for i := 0; i < 2; i++ {
+		if err := <-errChan; err != nil && errReturned == nil {
+			errReturned = err
+			cancel()
+		}
+	}
+	return errReturned
+}
+
+func cmdExpireDeployments(args *cli.Context) error {
+	ctx := context.Background()
+	mgo, err := mongo.NewMongoClient(ctx, config.Config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = mgo.Disconnect(context.Background())
+	}()
+	database := mongo.NewDataStoreMongoWithClient(mgo)
+	app := app.NewDeployments(database, nil, 0, false)
+	err = app.ExpireDeployments(ctx, args.Duration("interval"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func cmdMigrateStorage(args *cli.Context) error {
+	ctx := context.Background()
+	objectStorage, err := SetupObjectStorage(ctx)
+	if err != nil {
+		return err
+	}
+	mgo, err := mongo.NewMongoClient(ctx, config.Config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = mgo.Disconnect(context.Background())
+	}()
+	database := mongo.NewDataStoreMongoWithClient(mgo)
+	app := app.NewDeployments(database, objectStorage, 0, false)
+	err = app.ProcessStorageMigrations(ctx, args.String("tenant"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
 }
 
 func cmdPropagateReporting(args *cli.Context) error {
@@ -0,0 +1,98 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"time"
+)
+
+// MaxArtifactContentsEntries is the maximum number of archive entries listed
+// for an artifact; the listing of larger artifacts is truncated.
+const MaxArtifactContentsEntries = 10000
+
+// Types of the entries of a payload archive
+const (
+	PayloadEntryTypeFile    = "file"
+	PayloadEntryTypeDir     = "dir"
+	PayloadEntryTypeSymlink = "symlink"
+	PayloadEntryTypeLink    = "link"
+	PayloadEntryTypeOther   = "other"
+)
+
+// ArtifactContents is the listing of the payloads of an artifact,
+// cached on the image once the artifact has been inspected.
+type ArtifactContents struct {
+	// Payloads of the artifact, in the order of the artifact updates.
+	Payloads []PayloadContents `json:"payloads" bson:"payloads"`
+
+	// Truncated is set when the archives hold more entries than listed.
+	Truncated bool `json:"truncated" bson:"truncated"`
+
+	// Time the artifact has been inspected.
+	Inspected *time.Time `json:"inspected" bson:"inspected"`
+}
+
+// PayloadContents holds the files of one payload of the artifact.
+type PayloadContents struct {
+	// Payload type, the update module handling the payload.
+	Type string `json:"type" bson:"type"`
+
+	// Payload meta-data.
+	MetaData map[string]interface{} `json:"meta_data,omitempty" bson:"meta_data,omitempty"`
+
+	// Files of the payload.
+	Files []PayloadFile `json:"files" bson:"files"`
+}
+
+// PayloadFile is a file of a payload; the entries are listed for the files
+// that are (optionally compressed) tar archives.
+type PayloadFile struct {
+	Name     string         `json:"name" bson:"name"`
+	Size     int64          `json:"size" bson:"size"`
+	Checksum string         `json:"checksum" bson:"checksum"`
+	Entries  []PayloadEntry `json:"entries,omitempty" bson:"entries,omitempty"`
+}
+
+// PayloadEntry is an entry of a payload archive.
+type PayloadEntry struct {
+	Path string `json:"path" bson:"path"`
+	Type string `json:"type" bson:"type"`
+	Size int64  `json:"size" bson:"size"`
+	Mode int64  `json:"mode" bson:"mode"`
+
+	// Checksum is the SHA256 checksum of the regular files.
+	Checksum string `json:"checksum,omitempty" bson:"checksum,omitempty"`
+
+	// LinkTarget is the target of the symbolic and hard links.
+	LinkTarget string `json:"link_target,omitempty" bson:"link_target,omitempty"`
+}
+
+// PayloadEntryType returns the type of the archive entry with the given
+// tar header type flag.
+func PayloadEntryType(typeflag byte) string {
+	switch typeflag {
+	case tar.TypeReg, tar.TypeRegA: //nolint:staticcheck
+		return PayloadEntryTypeFile
+	case tar.TypeDir:
+		return PayloadEntryTypeDir
+	case tar.TypeSymlink:
+		return PayloadEntryTypeSymlink
+	case tar.TypeLink:
+		return PayloadEntryTypeLink
+	default:
+		return PayloadEntryTypeOther
+	}
+}
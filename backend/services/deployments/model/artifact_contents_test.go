@@ -0,0 +1,38 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"archive/tar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayloadEntryType(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[byte]string{
+		tar.TypeReg:     PayloadEntryTypeFile,
+		tar.TypeDir:     PayloadEntryTypeDir,
+		tar.TypeSymlink: PayloadEntryTypeSymlink,
+		tar.TypeLink:    PayloadEntryTypeLink,
+		tar.TypeFifo:    PayloadEntryTypeOther,
+		tar.TypeChar:    PayloadEntryTypeOther,
+	}
+	for typeflag, expected := range testCases {
+		assert.Equal(t, expected, PayloadEntryType(typeflag))
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"sort"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	// MaxArtifactRetentionKeepLast is the maximum number of artifacts
+	// retained per device type or release.
+	MaxArtifactRetentionKeepLast = 10000
+	// MaxArtifactRetentionUnusedDays is the maximum number of days an
+	// artifact may stay unused before it is removed.
+	MaxArtifactRetentionUnusedDays = 3650
+)
+
+// Reasons for which an artifact is removed by the retention policy
+const (
+	ArtifactRetentionReasonDeviceType = "keep_last_per_device_type"
+	ArtifactRetentionReasonRelease    = "keep_last_per_release"
+	ArtifactRetentionReasonUnused     = "delete_unused_after_days"
+)
+
+// ArtifactRetentionSettings is the per-tenant artifact retention policy
+// enforced by the storage daemon; the rules set to zero are disabled.
+// An artifact is removed as soon as it breaks any of the rules, but the
+// artifacts used in an active deployment are never removed.
+type ArtifactRetentionSettings struct {
+	// Number of most recent artifacts kept for each compatible device type;
+	// the delta artifacts are counted apart from the full artifacts
+	KeepLastPerDeviceType uint `json:"keep_last_per_device_type,omitempty" bson:"keep_last_per_device_type,omitempty"` //nolint:lll
+
+	// Number of most recent releases whose artifacts are kept for each
+	// compatible device type; all the artifacts of a kept release, full
+	// and delta, are kept
+	KeepLastPerRelease uint `json:"keep_last_per_release,omitempty" bson:"keep_last_per_release,omitempty"` //nolint:lll
+
+	// Number of days after which the artifacts not used in any deployment
+	// are removed
+	DeleteUnusedAfterDays uint `json:"delete_unused_after_days,omitempty" bson:"delete_unused_after_days,omitempty"` //nolint:lll
+}
+
+func (s ArtifactRetentionSettings) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.KeepLastPerDeviceType,
+			validation.Max(uint(MaxArtifactRetentionKeepLast))),
+		validation.Field(&s.KeepLastPerRelease,
+			validation.Max(uint(MaxArtifactRetentionKeepLast))),
+		validation.Field(&s.DeleteUnusedAfterDays,
+			validation.Max(uint(MaxArtifactRetentionUnusedDays))),
+	)
+}
+
+// Enabled returns true if any of the retention rules is set.
+func (s ArtifactRetentionSettings) Enabled() bool {
+	return s.KeepLastPerDeviceType > 0 ||
+		s.KeepLastPerRelease > 0 ||
+		s.DeleteUnusedAfterDays > 0
+}
+
+// UnusedSince returns the time since which the artifacts must have been
+// unused to be removed, or nil if the rule is disabled.
+func (s ArtifactRetentionSettings) UnusedSince(now time.Time) *time.Time {
+	if s.DeleteUnusedAfterDays == 0 {
+		return nil
+	}
+	since := now.AddDate(0, 0, -int(s.DeleteUnusedAfterDays))
+	return &since
+}
+
+// ExceedingArtifacts returns the reason for removal of the artifacts which
+// are not among the most recent ones kept for any of their device types,
+// or which do not belong to the most recent releases kept for any of their
+// device types, keyed by artifact ID. The delta artifacts are counted apart
+// from the full artifacts per device type, so that generating deltas never
+// evicts the full artifacts they apply to.
+func (s ArtifactRetentionSettings) ExceedingArtifacts(images []*Image) map[string]string {
+	exceeding := make(map[string]string)
+	if s.KeepLastPerDeviceType == 0 && s.KeepLastPerRelease == 0 {
+		return exceeding
+	}
+	sorted := make([]*Image, 0, len(images))
+	for _, img := range images {
+		if img != nil && img.ArtifactMeta != nil {
+			sorted = append(sorted, img)
+		}
+	}
+	// most recent artifacts first
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return modifiedTime(sorted[i]).After(modifiedTime(sorted[j]))
+	})
+
+	if s.KeepLastPerDeviceType > 0 {
+		kept := keepLastArtifacts(sorted, s.KeepLastPerDeviceType,
+			func(img *Image, deviceType string) string {
+				return deviceType
+			})
+		for _, img := range sorted {
+			if !kept[img.Id] {
+				exceeding[img.Id] = ArtifactRetentionReasonDeviceType
+			}
+		}
+	}
+	if s.KeepLastPerRelease > 0 {
+		kept := keepLastReleases(sorted, s.KeepLastPerRelease)
+		for _, img := range sorted {
+			if _, ok := exceeding[img.Id]; !ok && !kept[img.Id] {
+				exceeding[img.Id] = ArtifactRetentionReasonRelease
+			}
+		}
+	}
+	return exceeding
+}
+
+// keepLastArtifacts returns the IDs of the artifacts among the most recent
+// keepLast ones of the group of any of their device types; the artifacts
+// must be sorted most recent first, and the delta artifacts are grouped
+// apart from the full ones.
+func keepLastArtifacts(
+	sorted []*Image,
+	keepLast uint,
+	group func(img *Image, deviceType string) string,
+) map[string]bool {
+	count := make(map[string]uint)
+	kept := make(map[string]bool)
+	for _, img := range sorted {
+		kind := "full"
+		if img.ArtifactMeta.IsDelta() {
+			kind = "delta"
+		}
+		for _, deviceType := range img.DeviceTypesCompatible {
+			key := kind + "\x00" + group(img, deviceType)
+			if count[key] < keepLast {
+				kept[img.Id] = true
+			}
+			count[key]++
+		}
+	}
+	return kept
+}
+
+// keepLastReleases returns the IDs of the artifacts belonging to the most
+// recent keepLast releases of any of their device types; a release is as
+// recent as its most recent artifact, and the artifacts must be sorted most
+// recent first.
+func keepLastReleases(sorted []*Image, keepLast uint) map[string]bool {
+	releases := make(map[string]map[string]bool)
+	kept := make(map[string]bool)
+	for _, img := range sorted {
+		for _, deviceType := range img.DeviceTypesCompatible {
+			keptReleases, ok := releases[deviceType]
+			if !ok {
+				keptReleases = make(map[string]bool)
+				releases[deviceType] = keptReleases
+			}
+			if !keptReleases[img.ArtifactMeta.Name] && uint(len(keptReleases)) < keepLast {
+				keptReleases[img.ArtifactMeta.Name] = true
+			}
+			if keptReleases[img.ArtifactMeta.Name] {
+				kept[img.Id] = true
+			}
+		}
+	}
+	return kept
+}
+
+func modifiedTime(img *Image) time.Time {
+	if img.Modified == nil {
+		return time.Time{}
+	}
+	return *img.Modified
+}
+
+// ArtifactRetentionCandidate is an artifact removed by the retention policy.
+type ArtifactRetentionCandidate struct {
+	ID                    string   `json:"id"`
+	Name                  string   `json:"name"`
+	DeviceTypesCompatible []string `json:"device_types_compatible"`
+	Size                  int64    `json:"size"`
+	Reason                string   `json:"reason"`
+}
+
+// ArtifactRetentionReport lists the artifacts removed, or to be removed in
+// dry-run mode, by the retention policy of a tenant.
+type ArtifactRetentionReport struct {
+	TenantID  string                       `json:"tenant_id,omitempty"`
+	DryRun    bool                         `json:"dry_run"`
+	Artifacts []ArtifactRetentionCandidate `json:"artifacts"`
+
+	// Total size of the artifacts in bytes
+	Size int64 `json:"size"`
+}
@@ -0,0 +1,185 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtifactRetentionSettingsExceedingArtifacts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	image := func(id, name string, age int, deviceTypes ...string) *Image {
+		modified := now.Add(-time.Duration(age) * time.Hour)
+		return &Image{
+			Id: id,
+			ArtifactMeta: &ArtifactMeta{
+				Name:                  name,
+				DeviceTypesCompatible: deviceTypes,
+			},
+			Modified: &modified,
+		}
+	}
+	images := []*Image{
+		image("1", "release-1", 4, "rpi3"),
+		image("2", "release-2", 3, "rpi3", "rpi4"),
+		image("3", "release-2", 2, "rpi4"),
+		image("4", "release-3", 1, "rpi3"),
+		image("5", "release-3", 0, "rpi4"),
+	}
+
+	testCases := map[string]struct {
+		Settings ArtifactRetentionSettings
+		Expected map[string]string
+	}{
+		"disabled": {
+			Expected: map[string]string{},
+		},
+		"keep last per device type": {
+			Settings: ArtifactRetentionSettings{KeepLastPerDeviceType: 2},
+			Expected: map[string]string{
+				"1": ArtifactRetentionReasonDeviceType,
+			},
+		},
+		"keep last per release": {
+			Settings: ArtifactRetentionSettings{KeepLastPerRelease: 1},
+			Expected: map[string]string{
+				"1": ArtifactRetentionReasonRelease,
+				"2": ArtifactRetentionReasonRelease,
+				"3": ArtifactRetentionReasonRelease,
+			},
+		},
+		"keep two last releases": {
+			Settings: ArtifactRetentionSettings{KeepLastPerRelease: 2},
+			Expected: map[string]string{
+				"1": ArtifactRetentionReasonRelease,
+			},
+		},
+		"both rules": {
+			Settings: ArtifactRetentionSettings{
+				KeepLastPerDeviceType: 1,
+				KeepLastPerRelease:    1,
+			},
+			Expected: map[string]string{
+				"1": ArtifactRetentionReasonDeviceType,
+				"2": ArtifactRetentionReasonDeviceType,
+				"3": ArtifactRetentionReasonDeviceType,
+			},
+		},
+		"unused only": {
+			Settings: ArtifactRetentionSettings{DeleteUnusedAfterDays: 1},
+			Expected: map[string]string{},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.Expected, tc.Settings.ExceedingArtifacts(images))
+		})
+	}
+}
+
+func TestArtifactRetentionSettingsValidate(t *testing.T) {
+	t.Parallel()
+
+	settings := ArtifactRetentionSettings{
+		KeepLastPerDeviceType: 10,
+		KeepLastPerRelease:    2,
+		DeleteUnusedAfterDays: 90,
+	}
+	assert.NoError(t, settings.Validate())
+	assert.True(t, settings.Enabled())
+
+	settings.KeepLastPerRelease = MaxArtifactRetentionKeepLast + 1
+	assert.Error(t, settings.Validate())
+
+	assert.False(t, ArtifactRetentionSettings{}.Enabled())
+	assert.Nil(t, ArtifactRetentionSettings{}.UnusedSince(time.Now()))
+}
+
+func TestArtifactRetentionSettingsExceedingReleaseArtifacts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	delta := ArtifactUpdateTypeDelta
+	image := func(id, name string, age int, isDelta bool, deviceType string) *Image {
+		modified := now.Add(-time.Duration(age) * time.Hour)
+		meta := &ArtifactMeta{
+			Name:                  name,
+			DeviceTypesCompatible: []string{deviceType},
+		}
+		if isDelta {
+			meta.Updates = []Update{{TypeInfo: ArtifactUpdateTypeInfo{Type: &delta}}}
+		}
+		return &Image{Id: id, ArtifactMeta: meta, Modified: &modified}
+	}
+	// releases built for two device types, with a delta artifact
+	// generated after the full artifacts for one of them
+	images := []*Image{
+		image("rpi3-release-1", "release-1", 6, false, "rpi3"),
+		image("rpi4-release-1", "release-1", 5, false, "rpi4"),
+		image("rpi3-release-2", "release-2", 4, false, "rpi3"),
+		image("rpi3-release-2-delta", "release-2", 3, true, "rpi3"),
+		image("rpi3-release-3", "release-3", 2, false, "rpi3"),
+		image("rpi3-release-3-delta", "release-3", 1, true, "rpi3"),
+		image("rpi4-release-2", "release-2", 0, false, "rpi4"),
+	}
+
+	testCases := map[string]struct {
+		Settings ArtifactRetentionSettings
+		Expected map[string]string
+	}{
+		"keep last per release": {
+			Settings: ArtifactRetentionSettings{KeepLastPerRelease: 1},
+			Expected: map[string]string{
+				"rpi3-release-1":       ArtifactRetentionReasonRelease,
+				"rpi4-release-1":       ArtifactRetentionReasonRelease,
+				"rpi3-release-2":       ArtifactRetentionReasonRelease,
+				"rpi3-release-2-delta": ArtifactRetentionReasonRelease,
+			},
+		},
+		"keep two per release": {
+			Settings: ArtifactRetentionSettings{KeepLastPerRelease: 2},
+			Expected: map[string]string{
+				"rpi3-release-1": ArtifactRetentionReasonRelease,
+			},
+		},
+		"keep three per release": {
+			Settings: ArtifactRetentionSettings{KeepLastPerRelease: 3},
+			Expected: map[string]string{},
+		},
+		"keep last per device type": {
+			Settings: ArtifactRetentionSettings{KeepLastPerDeviceType: 1},
+			Expected: map[string]string{
+				"rpi3-release-1":       ArtifactRetentionReasonDeviceType,
+				"rpi4-release-1":       ArtifactRetentionReasonDeviceType,
+				"rpi3-release-2":       ArtifactRetentionReasonDeviceType,
+				"rpi3-release-2-delta": ArtifactRetentionReasonDeviceType,
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.Expected, tc.Settings.ExceedingArtifacts(images))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	ArtifactUpdateTypeRootfs = "rootfs-image"
+	ArtifactUpdateTypeDelta  = "mender-binary-delta"
+
+	ArtifactProvidesRootfsChecksum = "rootfs-image.checksum"
+
+	artifactDependsDeviceType   = "device_type"
+	artifactDependsArtifactName = "artifact_name"
+)
+
+var (
+	ErrDeltaSameArtifact = errors.New("source and target artifacts must be different")
+)
+
+// GenerateDeltaRequest is the request to generate a binary delta artifact
+// updating the devices from the source to the target artifact.
+type GenerateDeltaRequest struct {
+	SourceID    string `json:"source_id"`
+	TargetID    string `json:"target_id"`
+	Description string `json:"description,omitempty"`
+}
+
+func (r GenerateDeltaRequest) Validate() error {
+	if err := validation.ValidateStruct(&r,
+		validation.Field(&r.SourceID, validation.Required, is.UUID),
+		validation.Field(&r.TargetID, validation.Required, is.UUID),
+		validation.Field(&r.Description, lengthLessThan4096),
+	); err != nil {
+		return err
+	}
+	if r.SourceID == r.TargetID {
+		return ErrDeltaSameArtifact
+	}
+	return nil
+}
+
+// GenerateDeltaMsg is the input of the generate_delta_artifact workflow.
+type GenerateDeltaMsg struct {
+	ArtifactID            string   `json:"artifact_id"`
+	Name                  string   `json:"name"`
+	Description           string   `json:"description"`
+	DeviceTypesCompatible []string `json:"device_types_compatible"`
+	GetSourceURI          string   `json:"get_source_uri"`
+	GetTargetURI          string   `json:"get_target_uri"`
+	TenantID              string   `json:"tenant_id"`
+}
+
+// IsDelta returns true if the artifact carries a binary delta update.
+func (am *ArtifactMeta) IsDelta() bool {
+	for _, update := range am.Updates {
+		if update.TypeInfo.Type != nil &&
+			*update.TypeInfo.Type == ArtifactUpdateTypeDelta {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRootfsImage returns true if the artifact carries a single full root
+// file system image identified by its checksum.
+func (am *ArtifactMeta) IsRootfsImage() bool {
+	return len(am.Updates) == 1 &&
+		am.Updates[0].TypeInfo.Type != nil &&
+		*am.Updates[0].TypeInfo.Type == ArtifactUpdateTypeRootfs &&
+		am.Provides[ArtifactProvidesRootfsChecksum] != ""
+}
+
+// DependsSatisfied returns true if the artifact depends match the
+// artifact installed on the device.
+func (am *ArtifactMeta) DependsSatisfied(installed *InstalledDeviceDeployment) bool {
+	for key, value := range am.Depends {
+		var provided string
+		switch key {
+		case artifactDependsDeviceType:
+			provided = installed.DeviceType
+		case artifactDependsArtifactName:
+			provided = installed.ArtifactName
+		default:
+			var ok bool
+			provided, ok = installed.Provides[key]
+			if !ok {
+				return false
+			}
+		}
+		if !dependsValueMatches(value, provided) {
+			return false
+		}
+	}
+	return true
+}
+
+func dependsValueMatches(value interface{}, provided string) bool {
+	switch v := value.(type) {
+	case string:
+		return v == provided
+	case []string:
+		for _, s := range v {
+			if s == provided {
+				return true
+			}
+		}
+	case []interface{}:
+		return dependsValueMatches(bson.A(v), provided)
+	case bson.A:
+		for _, s := range v {
+			if s == provided {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGenerateDeltaRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		sourceID = "d1b1c7e4-5a6b-4c1d-8e0f-2a3b4c5d6e7f"
+		targetID = "0e6b1d6c-7f4a-4b2e-9c3d-1a2b3c4d5e6f"
+	)
+	testCases := map[string]struct {
+		Request GenerateDeltaRequest
+		Error   string
+	}{
+		"ok": {
+			Request: GenerateDeltaRequest{
+				SourceID:    sourceID,
+				TargetID:    targetID,
+				Description: "delta",
+			},
+		},
+		"error, missing source": {
+			Request: GenerateDeltaRequest{
+				TargetID: targetID,
+			},
+			Error: "source_id: cannot be blank.",
+		},
+		"error, invalid target": {
+			Request: GenerateDeltaRequest{
+				SourceID: sourceID,
+				TargetID: "foo",
+			},
+			Error: "target_id: must be a valid UUID.",
+		},
+		"error, same artifact": {
+			Request: GenerateDeltaRequest{
+				SourceID: sourceID,
+				TargetID: sourceID,
+			},
+			Error: ErrDeltaSameArtifact.Error(),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.Request.Validate()
+			if tc.Error != "" {
+				assert.EqualError(t, err, tc.Error)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestArtifactMetaDelta(t *testing.T) {
+	t.Parallel()
+
+	rootfs := ArtifactUpdateTypeRootfs
+	delta := ArtifactUpdateTypeDelta
+	testCases := map[string]struct {
+		Meta ArtifactMeta
+
+		IsDelta       bool
+		IsRootfsImage bool
+	}{
+		"rootfs image": {
+			Meta: ArtifactMeta{
+				Updates: []Update{{TypeInfo: ArtifactUpdateTypeInfo{Type: &rootfs}}},
+				Provides: map[string]string{
+					ArtifactProvidesRootfsChecksum: "abc",
+				},
+			},
+			IsRootfsImage: true,
+		},
+		"rootfs image without checksum": {
+			Meta: ArtifactMeta{
+				Updates: []Update{{TypeInfo: ArtifactUpdateTypeInfo{Type: &rootfs}}},
+			},
+		},
+		"delta": {
+			Meta: ArtifactMeta{
+				Updates: []Update{{TypeInfo: ArtifactUpdateTypeInfo{Type: &delta}}},
+				Provides: map[string]string{
+					ArtifactProvidesRootfsChecksum: "abc",
+				},
+			},
+			IsDelta: true,
+		},
+		"no updates": {},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.IsDelta, tc.Meta.IsDelta())
+			assert.Equal(t, tc.IsRootfsImage, tc.Meta.IsRootfsImage())
+		})
+	}
+}
+
+func TestArtifactMetaDependsSatisfied(t *testing.T) {
+	t.Parallel()
+
+	installed := &InstalledDeviceDeployment{
+		ArtifactName: "release-1",
+		DeviceType:   "qemu",
+		Provides: map[string]string{
+			ArtifactProvidesRootfsChecksum: "abc",
+		},
+	}
+	testCases := map[string]struct {
+		Depends   map[string]interface{}
+		Satisfied bool
+	}{
+		"no depends": {
+			Satisfied: true,
+		},
+		"satisfied": {
+			Depends: map[string]interface{}{
+				artifactDependsDeviceType:      []interface{}{"rpi", "qemu"},
+				artifactDependsArtifactName:    "release-1",
+				ArtifactProvidesRootfsChecksum: "abc",
+			},
+			Satisfied: true,
+		},
+		"satisfied, bson array": {
+			Depends: map[string]interface{}{
+				artifactDependsDeviceType: bson.A{"qemu"},
+			},
+			Satisfied: true,
+		},
+		"different checksum": {
+			Depends: map[string]interface{}{
+				ArtifactProvidesRootfsChecksum: "def",
+			},
+		},
+		"missing provide": {
+			Depends: map[string]interface{}{
+				"data-partition.version": "1",
+			},
+		},
+		"incompatible device type": {
+			Depends: map[string]interface{}{
+				artifactDependsDeviceType: []string{"rpi"},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			meta := ArtifactMeta{Depends: tc.Depends}
+			assert.Equal(t, tc.Satisfied, meta.DependsSatisfied(installed))
+		})
+	}
+}
@@ -78,6 +78,10 @@ type DeploymentConstructor struct {
 	// Artifact name to be installed required, associated with image
 	ArtifactName string `json:"artifact_name,omitempty"`
 
+	// Release to be installed, the artifact name is set from the selected
+	// release when the deployment is created
+	Release *DeploymentRelease `json:"release,omitempty" bson:"release,omitempty"`
+
 	// List of device id's targeted for deployments, required
 	Devices []string `json:"devices,omitempty" bson:"-"`
 
@@ -90,6 +94,51 @@ type DeploymentConstructor struct {
 
 	// When set the deployment will be created for all accepted devices from a given group
 	Group string `json:"-" bson:"-"`
+
+	// Phases split the deployment into batches of devices released one after another
+	Phases []DeploymentPhase `json:"phases,omitempty" bson:"phases,omitempty"`
+
+	// FailurePolicy automatically aborts the deployment when too many devices fail
+	//nolint:lll
+	FailurePolicy *DeploymentFailurePolicy `json:"failure_policy,omitempty" bson:"failure_policy,omitempty"`
+
+	// Time from which the deployment is visible to the devices
+	StartTime *time.Time `json:"start_ts,omitempty" bson:"start_ts,omitempty"`
+
+	// Time after which the devices which did not start the deployment
+	// are not updated anymore
+	EndTime *time.Time `json:"end_ts,omitempty" bson:"end_ts,omitempty"`
+
+	// Recurring window in which the devices are allowed to start the deployment
+	//nolint:lll
+	MaintenanceWindow *MaintenanceWindow `json:"maintenance_window,omitempty" bson:"maintenance_window,omitempty"`
+
+	// ID of the deployment which the devices must complete successfully
+	// before they are allowed to start this deployment
+	AfterDeployment string `json:"after_deployment,omitempty" bson:"after_deployment,omitempty"`
+
+	// Number of times the deployment is offered again to a device which
+	// reported a failure
+	Retries uint `json:"retries,omitempty" bson:"retries,omitempty"`
+
+	// Time in seconds a device waits before retrying the deployment, doubled
+	// after each failed attempt
+	RetryBackoff uint `json:"retry_backoff,omitempty" bson:"retry_backoff,omitempty"`
+
+	// Update control map pausing the devices at the given update states
+	//nolint:lll
+	UpdateControlMap *UpdateControlMap `json:"update_control_map,omitempty" bson:"update_control_map,omitempty"`
+
+	// Continuous deployments stay active until stopped and serve the release
+	// to every device joining the group or matching the filter
+	Continuous bool `json:"continuous,omitempty" bson:"continuous,omitempty"`
+
+	// Inventory filter selecting the devices of a continuous deployment
+	DeviceFilter []FilterPredicate `json:"device_filter,omitempty" bson:"-"`
+
+	// AllowDowngrade lets a continuous deployment install the release on
+	// devices which installed a newer deployment
+	AllowDowngrade bool `json:"allow_downgrade,omitempty" bson:"allow_downgrade,omitempty"`
 }
 
 // Validate checks structure according to valid tags
@@ -97,8 +146,27 @@ type DeploymentConstructor struct {
 func (c DeploymentConstructor) Validate() error {
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.Name, validation.Required, lengthIn1To4096),
-		validation.Field(&c.ArtifactName, validation.Required, lengthIn1To4096),
+		validation.Field(&c.ArtifactName,
+			validation.When(c.Release == nil, validation.Required), lengthIn1To4096),
+		validation.Field(&c.Release),
 		validation.Field(&c.Devices, validation.Each(validation.Required)),
+		validation.Field(&c.Phases, validation.By(func(interface{}) error {
+			return deploymentPhases(c.Phases).Validate()
+		})),
+		validation.Field(&c.FailurePolicy),
+		validation.Field(&c.EndTime, validation.By(func(interface{}) error {
+			if c.StartTime != nil && c.EndTime != nil && !c.EndTime.After(*c.StartTime) {
+				return ErrInvalidDeploymentEndTime
+			}
+			return nil
+		})),
+		validation.Field(&c.MaintenanceWindow),
+		validation.Field(&c.AfterDeployment, is.UUID),
+		validation.Field(&c.Retries, validation.Max(uint(MaxDeploymentRetries))),
+		validation.Field(&c.RetryBackoff,
+			validation.Max(uint(MaxDeploymentRetryBackoff/time.Second))),
+		validation.Field(&c.UpdateControlMap),
+		validation.Field(&c.DeviceFilter),
 	)
 }
 
@@ -106,6 +174,14 @@ func (c DeploymentConstructor) ValidateNew() error {
 	if err := c.Validate(); err != nil {
 		return err
 	}
+	if c.Release != nil && c.ArtifactName != "" {
+		return ErrInvalidDeploymentReleaseConflict
+	}
+	if c.Continuous {
+		return c.validateContinuous()
+	} else if len(c.DeviceFilter) > 0 || c.AllowDowngrade {
+		return ErrInvalidContinuousDeploymentOptions
+	}
 
 	if len(c.Group) == 0 {
 		if len(c.Devices) == 0 && !c.AllDevices {
@@ -133,6 +209,10 @@ func (c DeploymentConstructor) Checksum() string {
 type DeploymentStatistics struct {
 	Status    Stats `json:"status" bson:"-"`
 	TotalSize int   `json:"total_size" bson:"total_size"`
+	// Bytes of the artifacts download links were issued for
+	ServedSize int64 `json:"served_size,omitempty" bson:"served_size"`
+	// Bytes of the artifacts the devices reported as downloaded
+	DownloadedSize int64 `json:"downloaded_size,omitempty" bson:"downloaded_size"`
 }
 
 type Deployment struct {
@@ -193,6 +273,9 @@ type Deployment struct {
 	// The artifact will be generated when the device will ask
 	// for an update.
 	Configuration deploymentConfiguration `json:"configuration,omitempty" bson:"configuration"`
+
+	// Reason for which the deployment was automatically aborted
+	AbortReason string `json:"abort_reason,omitempty" bson:"abort_reason,omitempty"`
 }
 
 type DeploymentArtifactsUpdate struct {
@@ -226,6 +309,8 @@ func NewDeploymentFromConstructor(constructor *DeploymentConstructor) (*Deployme
 	deployment.DeploymentConstructor = constructor
 	if constructor != nil {
 		deployment.DeploymentConstructorChecksum = constructor.Checksum()
+		// phase IDs are random, assign them after computing the checksum
+		deploymentPhases(constructor.Phases).assignPhaseIDs()
 	}
 	deployment.Status = DeploymentStatusPending
 
@@ -301,6 +386,7 @@ func (d *Deployment) IsFinished() bool {
 			d.Stats[DeviceDeploymentStatusFailureStr]+
 			d.Stats[DeviceDeploymentStatusNoArtifactStr]+
 			d.Stats[DeviceDeploymentStatusDecommissionedStr]+
+			d.Stats[DeviceDeploymentStatusExpiredStr]+
 			d.Stats[DeviceDeploymentStatusAbortedStr]) >= d.MaxDevices) {
 		return true
 	}
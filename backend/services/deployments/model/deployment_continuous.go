@@ -0,0 +1,70 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidContinuousDeploymentOptions = errors.New(
+		"Invalid deployments definition: device_filter and allow_downgrade" +
+			" require a continuous deployment",
+	)
+	ErrInvalidContinuousDeploymentRelease = errors.New(
+		"Invalid continuous deployment: release must be set",
+	)
+	ErrInvalidContinuousDeploymentDevices = errors.New(
+		"Invalid continuous deployment: provide either a group or a device_filter," +
+			" not a list of devices nor the all_devices flag",
+	)
+	ErrInvalidContinuousDeploymentSchedule = errors.New(
+		"Invalid continuous deployment: phases and end_ts are not supported",
+	)
+)
+
+func (c DeploymentConstructor) validateContinuous() error {
+	if c.Release == nil {
+		return ErrInvalidContinuousDeploymentRelease
+	}
+	if len(c.Devices) > 0 || c.AllDevices ||
+		(c.Group == "") == (len(c.DeviceFilter) == 0) {
+		return ErrInvalidContinuousDeploymentDevices
+	}
+	if len(c.Phases) > 0 || c.EndTime != nil {
+		return ErrInvalidContinuousDeploymentSchedule
+	}
+	return nil
+}
+
+// IsContinuous returns true if the deployment follows its group or filter
+// instead of a list of devices fixed at creation.
+func (d *Deployment) IsContinuous() bool {
+	return d.DeploymentConstructor != nil && d.Continuous
+}
+
+// IsDowngrade returns true if serving the continuous deployment to a device
+// whose latest finished device deployment is the given one would replace a
+// newer software installed successfully.
+func (d *Deployment) IsDowngrade(latest *DeviceDeployment) bool {
+	if latest == nil || latest.Created == nil || d.Created == nil {
+		return false
+	}
+	switch latest.Status {
+	case DeviceDeploymentStatusSuccess, DeviceDeploymentStatusAlreadyInst:
+		return latest.Created.After(*d.Created)
+	}
+	return false
+}
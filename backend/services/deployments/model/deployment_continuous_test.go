@@ -0,0 +1,163 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentConstructorValidateContinuous(t *testing.T) {
+	t.Parallel()
+
+	release := &DeploymentRelease{Name: "release-1"}
+	filter := []FilterPredicate{{
+		Scope:     "inventory",
+		Attribute: "device_type",
+		Type:      "$eq",
+		Value:     "rpi4",
+	}}
+	endTime := time.Now().Add(time.Hour)
+	testCases := map[string]struct {
+		constructor DeploymentConstructor
+
+		err error
+	}{
+		"ok, group": {
+			constructor: DeploymentConstructor{
+				Release:    release,
+				Group:      "factory",
+				Continuous: true,
+			},
+		},
+		"ok, device filter and downgrade": {
+			constructor: DeploymentConstructor{
+				Release:        release,
+				DeviceFilter:   filter,
+				Continuous:     true,
+				AllowDowngrade: true,
+			},
+		},
+		"error, artifact name": {
+			constructor: DeploymentConstructor{
+				ArtifactName: "release-1",
+				Group:        "factory",
+				Continuous:   true,
+			},
+			err: ErrInvalidContinuousDeploymentRelease,
+		},
+		"error, no group nor filter": {
+			constructor: DeploymentConstructor{
+				Release:    release,
+				Continuous: true,
+			},
+			err: ErrInvalidContinuousDeploymentDevices,
+		},
+		"error, group and filter": {
+			constructor: DeploymentConstructor{
+				Release:      release,
+				Group:        "factory",
+				DeviceFilter: filter,
+				Continuous:   true,
+			},
+			err: ErrInvalidContinuousDeploymentDevices,
+		},
+		"error, all devices": {
+			constructor: DeploymentConstructor{
+				Release:      release,
+				DeviceFilter: filter,
+				AllDevices:   true,
+				Continuous:   true,
+			},
+			err: ErrInvalidContinuousDeploymentDevices,
+		},
+		"error, end time": {
+			constructor: DeploymentConstructor{
+				Release:    release,
+				Group:      "factory",
+				EndTime:    &endTime,
+				Continuous: true,
+			},
+			err: ErrInvalidContinuousDeploymentSchedule,
+		},
+		"error, not continuous": {
+			constructor: DeploymentConstructor{
+				Release:        release,
+				AllDevices:     true,
+				AllowDowngrade: true,
+			},
+			err: ErrInvalidContinuousDeploymentOptions,
+		},
+		"error, invalid filter": {
+			constructor: DeploymentConstructor{
+				Release: release,
+				DeviceFilter: []FilterPredicate{{
+					Scope:     "inventory",
+					Attribute: "device_type",
+					Type:      "$regex",
+					Value:     "rpi.*",
+				}},
+				Continuous: true,
+			},
+			err: assert.AnError,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.constructor.Name = "baseline"
+			err := tc.constructor.ValidateNew()
+			switch tc.err {
+			case nil:
+				assert.NoError(t, err)
+			case assert.AnError:
+				assert.Error(t, err)
+			default:
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestDeploymentIsDowngrade(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	later := now.Add(time.Hour)
+	deployment := &Deployment{Created: &now}
+
+	assert.False(t, deployment.IsDowngrade(nil))
+	assert.False(t, deployment.IsDowngrade(&DeviceDeployment{
+		Created: &earlier,
+		Status:  DeviceDeploymentStatusSuccess,
+	}))
+	assert.False(t, deployment.IsDowngrade(&DeviceDeployment{
+		Created: &later,
+		Status:  DeviceDeploymentStatusFailure,
+	}))
+	assert.True(t, deployment.IsDowngrade(&DeviceDeployment{
+		Created: &later,
+		Status:  DeviceDeploymentStatusSuccess,
+	}))
+	assert.True(t, deployment.IsDowngrade(&DeviceDeployment{
+		Created: &later,
+		Status:  DeviceDeploymentStatusAlreadyInst,
+	}))
+}
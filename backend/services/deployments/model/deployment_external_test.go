@@ -34,6 +34,7 @@ func TestDeploymentConstructorValidate(t *testing.T) {
 		InputDevices      []string
 		InputAllDevices   bool
 		InputGroup        string
+		InputAfter        string
 		IsValid           bool
 	}{
 		{
@@ -119,6 +120,20 @@ func TestDeploymentConstructorValidate(t *testing.T) {
 			InputAllDevices:   true,
 			IsValid:           false,
 		},
+		{
+			InputName:         "f826484e-1157-4109-af21-304e6d711560",
+			InputArtifactName: "f826484e-1157-4109-af21-304e6d711560",
+			InputDevices:      []string{"lala"},
+			InputAfter:        "f826484e-1157-4109-af21-304e6d711560",
+			IsValid:           true,
+		},
+		{
+			InputName:         "f826484e-1157-4109-af21-304e6d711560",
+			InputArtifactName: "f826484e-1157-4109-af21-304e6d711560",
+			InputDevices:      []string{"lala"},
+			InputAfter:        "lala",
+			IsValid:           false,
+		},
 	}
 
 	for _, test := range testCases {
@@ -129,6 +144,7 @@ func TestDeploymentConstructorValidate(t *testing.T) {
 		dep.Devices = test.InputDevices
 		dep.Group = test.InputGroup
 		dep.AllDevices = test.InputAllDevices
+		dep.AfterDeployment = test.InputAfter
 
 		err := dep.ValidateNew()
 
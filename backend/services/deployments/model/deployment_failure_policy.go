@@ -0,0 +1,85 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidFailurePolicy = errors.New(
+	"Invalid failure policy: either max_failures or max_failure_rate must be set",
+)
+
+// DeploymentFailurePolicy defines when a deployment is automatically aborted
+// because too many devices failed to update.
+type DeploymentFailurePolicy struct {
+	// Abort the deployment when the number of failed devices exceeds this value
+	MaxFailures int `json:"max_failures,omitempty" bson:"max_failures,omitempty"`
+
+	// Abort the deployment when the percentage of failed devices among the
+	// finished ones exceeds this value
+	MaxFailureRate int `json:"max_failure_rate,omitempty" bson:"max_failure_rate,omitempty"`
+
+	// Minimum number of finished devices before evaluating MaxFailureRate
+	MinFinished int `json:"min_finished,omitempty" bson:"min_finished,omitempty"`
+}
+
+func (p DeploymentFailurePolicy) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.MaxFailures, validation.Min(0)),
+		validation.Field(&p.MaxFailureRate, validation.Min(0), validation.Max(100)),
+		validation.Field(&p.MinFinished, validation.Min(0)),
+	)
+	if err != nil {
+		return err
+	}
+	if p.MaxFailures == 0 && p.MaxFailureRate == 0 {
+		return ErrInvalidFailurePolicy
+	}
+	return nil
+}
+
+// Evaluate checks the deployment statistics against the policy and returns
+// a human readable abort reason when the thresholds are exceeded, or an
+// empty string otherwise.
+func (p DeploymentFailurePolicy) Evaluate(stats Stats) string {
+	failures := stats.Get(DeviceDeploymentStatusFailure)
+	if p.MaxFailures > 0 && failures > p.MaxFailures {
+		return fmt.Sprintf(
+			"failure policy: %d devices failed, exceeding the limit of %d",
+			failures, p.MaxFailures,
+		)
+	}
+	if p.MaxFailureRate > 0 {
+		finished := failures +
+			stats.Get(DeviceDeploymentStatusSuccess) +
+			stats.Get(DeviceDeploymentStatusAlreadyInst) +
+			stats.Get(DeviceDeploymentStatusNoArtifact)
+		if finished == 0 || finished < p.MinFinished {
+			return ""
+		}
+		if failures*100 > p.MaxFailureRate*finished {
+			return fmt.Sprintf(
+				"failure policy: %d out of %d finished devices failed, "+
+					"exceeding the limit of %d%%",
+				failures, finished, p.MaxFailureRate,
+			)
+		}
+	}
+	return ""
+}
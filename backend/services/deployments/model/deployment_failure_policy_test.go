@@ -0,0 +1,90 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentFailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		policy DeploymentFailurePolicy
+		stats  Stats
+
+		validateErr bool
+		abort       bool
+	}{
+		"error, empty policy": {
+			validateErr: true,
+		},
+		"error, rate above 100": {
+			policy:      DeploymentFailurePolicy{MaxFailureRate: 101},
+			validateErr: true,
+		},
+		"ok, failures below limit": {
+			policy: DeploymentFailurePolicy{MaxFailures: 3},
+			stats:  Stats{DeviceDeploymentStatusFailureStr: 3},
+		},
+		"ok, failures above limit": {
+			policy: DeploymentFailurePolicy{MaxFailures: 3},
+			stats:  Stats{DeviceDeploymentStatusFailureStr: 4},
+			abort:  true,
+		},
+		"ok, rate above limit": {
+			policy: DeploymentFailurePolicy{MaxFailureRate: 10},
+			stats: Stats{
+				DeviceDeploymentStatusFailureStr: 2,
+				DeviceDeploymentStatusSuccessStr: 8,
+			},
+			abort: true,
+		},
+		"ok, rate at limit": {
+			policy: DeploymentFailurePolicy{MaxFailureRate: 20},
+			stats: Stats{
+				DeviceDeploymentStatusFailureStr: 2,
+				DeviceDeploymentStatusSuccessStr: 8,
+			},
+		},
+		"ok, not enough finished devices": {
+			policy: DeploymentFailurePolicy{
+				MaxFailureRate: 10,
+				MinFinished:    5,
+			},
+			stats: Stats{DeviceDeploymentStatusFailureStr: 1},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.policy.Validate()
+			if tc.validateErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			reason := tc.policy.Evaluate(tc.stats)
+			if tc.abort {
+				assert.NotEmpty(t, reason)
+			} else {
+				assert.Empty(t, reason)
+			}
+		})
+	}
+}
@@ -22,7 +22,8 @@ type ArtifactDeploymentInstructions struct {
 }
 
 type DeploymentInstructions struct {
-	ID       string                         `json:"id"`
-	Artifact ArtifactDeploymentInstructions `json:"artifact"`
-	Type     DeploymentType                 `json:"-"`
+	ID               string                         `json:"id"`
+	Artifact         ArtifactDeploymentInstructions `json:"artifact"`
+	Type             DeploymentType                 `json:"-"`
+	UpdateControlMap *UpdateControlMap              `json:"update_control_map,omitempty"`
 }
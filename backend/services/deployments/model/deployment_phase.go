@@ -0,0 +1,224 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	MaxDeploymentPhases = 10
+
+	DeploymentPhaseStatusScheduled = "scheduled"
+	DeploymentPhaseStatusPaused    = "paused"
+	DeploymentPhaseStatusActive    = "active"
+)
+
+var (
+	ErrInvalidPhaseBatchSize = errors.New(
+		"Invalid deployment phases: each phase except the last one must set " +
+			"either batch_size or batch_devices",
+	)
+	ErrInvalidPhaseLastBatchSize = errors.New(
+		"Invalid deployment phases: the last phase includes all remaining " +
+			"devices and cannot set batch_size or batch_devices",
+	)
+	ErrInvalidPhaseBatchSizeSum = errors.New(
+		"Invalid deployment phases: the sum of batch_size cannot exceed 100",
+	)
+	ErrInvalidPhaseStartTime = errors.New(
+		"Invalid deployment phases: start_ts must not precede the start of " +
+			"the previous phase",
+	)
+)
+
+// DeploymentPhase describes a single batch of a phased (canary) rollout.
+type DeploymentPhase struct {
+	// Phase id, generated on deployment creation
+	Id string `json:"id" bson:"id"`
+
+	// Percentage of the deployment devices included in the phase
+	BatchSize int `json:"batch_size,omitempty" bson:"batch_size,omitempty"`
+
+	// Absolute number of devices included in the phase
+	BatchDevices int `json:"batch_devices,omitempty" bson:"batch_devices,omitempty"`
+
+	// Time from which the devices in the phase are allowed to update
+	StartTime *time.Time `json:"start_ts,omitempty" bson:"start_ts,omitempty"`
+
+	// When set the phase is only started after being explicitly resumed
+	PauseUntilResumed bool `json:"pause_until_resumed,omitempty" bson:"pause_until_resumed,omitempty"`
+
+	// Time the phase was resumed through the management API
+	Resumed *time.Time `json:"resumed_ts,omitempty" bson:"resumed_ts,omitempty"`
+}
+
+func (p DeploymentPhase) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.BatchSize, validation.Min(0), validation.Max(100)),
+		validation.Field(&p.BatchDevices, validation.Min(0)),
+	)
+}
+
+// IsStarted returns true if devices belonging to the phase can be
+// scheduled at the given time.
+func (p DeploymentPhase) IsStarted(now time.Time) bool {
+	if p.Resumed != nil {
+		return true
+	}
+	if p.PauseUntilResumed {
+		return false
+	}
+	return p.StartTime == nil || !now.Before(*p.StartTime)
+}
+
+// Status returns the phase status at the given time.
+func (p DeploymentPhase) Status(now time.Time) string {
+	if p.IsStarted(now) {
+		return DeploymentPhaseStatusActive
+	} else if p.PauseUntilResumed {
+		return DeploymentPhaseStatusPaused
+	}
+	return DeploymentPhaseStatusScheduled
+}
+
+type deploymentPhases []DeploymentPhase
+
+func (phases deploymentPhases) Validate() error {
+	if err := validation.Validate([]DeploymentPhase(phases),
+		validation.Length(0, MaxDeploymentPhases),
+	); err != nil {
+		return err
+	}
+	var (
+		batchSizeSum int
+		lastStart    *time.Time
+	)
+	for i, phase := range phases {
+		if err := phase.Validate(); err != nil {
+			return err
+		}
+		hasBatch := phase.BatchSize > 0 || phase.BatchDevices > 0
+		if i == len(phases)-1 {
+			if hasBatch {
+				return ErrInvalidPhaseLastBatchSize
+			}
+		} else if !hasBatch || (phase.BatchSize > 0 && phase.BatchDevices > 0) {
+			return ErrInvalidPhaseBatchSize
+		}
+		batchSizeSum += phase.BatchSize
+		if phase.StartTime != nil {
+			if lastStart != nil && phase.StartTime.Before(*lastStart) {
+				return ErrInvalidPhaseStartTime
+			}
+			lastStart = phase.StartTime
+		}
+	}
+	if batchSizeSum > 100 {
+		return ErrInvalidPhaseBatchSizeSum
+	}
+	return nil
+}
+
+// DeploymentPhaseInfo is the phase representation returned by the
+// management API.
+type DeploymentPhaseInfo struct {
+	DeploymentPhase
+
+	// Status of the phase: scheduled, paused or active
+	Status string `json:"status"`
+
+	// Number of devices included in the phase
+	DeviceLimit int `json:"device_limit"`
+}
+
+// assignPhaseIDs generates the IDs of the phases of a new deployment.
+func (phases deploymentPhases) assignPhaseIDs() {
+	for i := range phases {
+		phases[i].Id = uuid.NewString()
+	}
+}
+
+// phaseDeviceLimits returns the number of devices included in each phase
+// for a deployment targeting maxDevices devices.
+func (phases deploymentPhases) phaseDeviceLimits(maxDevices int) []int {
+	limits := make([]int, len(phases))
+	remaining := maxDevices
+	for i, phase := range phases {
+		var limit int
+		switch {
+		case i == len(phases)-1:
+			limit = remaining
+		case phase.BatchSize > 0:
+			// round up so that small deployments do not end up
+			// with empty phases
+			limit = (maxDevices*phase.BatchSize + 99) / 100
+		default:
+			limit = phase.BatchDevices
+		}
+		if limit > remaining {
+			limit = remaining
+		}
+		limits[i] = limit
+		remaining -= limit
+	}
+	return limits
+}
+
+// PhasedDeviceLimit returns the number of devices which can be scheduled
+// for the deployment at the given time. Phases are started sequentially:
+// a phase cannot start before all the preceding phases have started.
+func (d *Deployment) PhasedDeviceLimit(now time.Time) int {
+	if d.DeploymentConstructor == nil || len(d.Phases) == 0 {
+		return d.MaxDevices
+	}
+	phases := deploymentPhases(d.Phases)
+	limit := 0
+	for i, phaseLimit := range phases.phaseDeviceLimits(d.MaxDevices) {
+		if !phases[i].IsStarted(now) {
+			break
+		}
+		limit += phaseLimit
+	}
+	return limit
+}
+
+// PhasesInfo returns the status of the deployment phases at the given time.
+func (d *Deployment) PhasesInfo(now time.Time) []DeploymentPhaseInfo {
+	if d.DeploymentConstructor == nil {
+		return []DeploymentPhaseInfo{}
+	}
+	phases := deploymentPhases(d.Phases)
+	limits := phases.phaseDeviceLimits(d.MaxDevices)
+	info := make([]DeploymentPhaseInfo, len(phases))
+	started := true
+	for i, phase := range phases {
+		started = started && phase.IsStarted(now)
+		info[i] = DeploymentPhaseInfo{
+			DeploymentPhase: phase,
+			Status:          phase.Status(now),
+			DeviceLimit:     limits[i],
+		}
+		if !started && info[i].Status == DeploymentPhaseStatusActive {
+			// waiting for the preceding phases
+			info[i].Status = DeploymentPhaseStatusScheduled
+		}
+	}
+	return info
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentPhasesValidate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	testCases := map[string]struct {
+		Phases []DeploymentPhase
+		Error  error
+	}{
+		"ok, no phases": {},
+		"ok, single delayed phase": {
+			Phases: []DeploymentPhase{{StartTime: &later}},
+		},
+		"ok": {
+			Phases: []DeploymentPhase{
+				{BatchSize: 10},
+				{BatchDevices: 100, StartTime: &now},
+				{StartTime: &later, PauseUntilResumed: true},
+			},
+		},
+		"error, no batch size": {
+			Phases: []DeploymentPhase{{}, {}},
+			Error:  ErrInvalidPhaseBatchSize,
+		},
+		"error, both batch size and devices": {
+			Phases: []DeploymentPhase{{BatchSize: 10, BatchDevices: 10}, {}},
+			Error:  ErrInvalidPhaseBatchSize,
+		},
+		"error, batch size in last phase": {
+			Phases: []DeploymentPhase{{BatchSize: 10}, {BatchSize: 90}},
+			Error:  ErrInvalidPhaseLastBatchSize,
+		},
+		"error, batch size sum": {
+			Phases: []DeploymentPhase{{BatchSize: 60}, {BatchSize: 60}, {}},
+			Error:  ErrInvalidPhaseBatchSizeSum,
+		},
+		"error, start time order": {
+			Phases: []DeploymentPhase{
+				{BatchSize: 10, StartTime: &later},
+				{StartTime: &now},
+			},
+			Error: ErrInvalidPhaseStartTime,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := deploymentPhases(tc.Phases).Validate()
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDeploymentPhasedDeviceLimit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	testCases := map[string]struct {
+		MaxDevices int
+		Phases     []DeploymentPhase
+
+		Limit    int
+		Statuses []string
+	}{
+		"no phases": {
+			MaxDevices: 10,
+			Limit:      10,
+			Statuses:   []string{},
+		},
+		"first phase started": {
+			MaxDevices: 10,
+			Phases: []DeploymentPhase{
+				{BatchSize: 25},
+				{StartTime: &future},
+			},
+			Limit: 3,
+			Statuses: []string{
+				DeploymentPhaseStatusActive,
+				DeploymentPhaseStatusScheduled,
+			},
+		},
+		"all phases started": {
+			MaxDevices: 10,
+			Phases: []DeploymentPhase{
+				{BatchSize: 25},
+				{BatchDevices: 2, StartTime: &past},
+				{StartTime: &past},
+			},
+			Limit: 10,
+			Statuses: []string{
+				DeploymentPhaseStatusActive,
+				DeploymentPhaseStatusActive,
+				DeploymentPhaseStatusActive,
+			},
+		},
+		"paused phase blocks the following phases": {
+			MaxDevices: 10,
+			Phases: []DeploymentPhase{
+				{BatchDevices: 1},
+				{BatchDevices: 2, PauseUntilResumed: true},
+				{StartTime: &past},
+			},
+			Limit: 1,
+			Statuses: []string{
+				DeploymentPhaseStatusActive,
+				DeploymentPhaseStatusPaused,
+				DeploymentPhaseStatusScheduled,
+			},
+		},
+		"resumed phase": {
+			MaxDevices: 10,
+			Phases: []DeploymentPhase{
+				{BatchDevices: 1},
+				{BatchDevices: 2, PauseUntilResumed: true, Resumed: &past},
+				{StartTime: &future},
+			},
+			Limit: 3,
+			Statuses: []string{
+				DeploymentPhaseStatusActive,
+				DeploymentPhaseStatusActive,
+				DeploymentPhaseStatusScheduled,
+			},
+		},
+		"batch larger than the deployment": {
+			MaxDevices: 2,
+			Phases: []DeploymentPhase{
+				{BatchDevices: 5},
+				{StartTime: &future},
+			},
+			Limit: 2,
+			Statuses: []string{
+				DeploymentPhaseStatusActive,
+				DeploymentPhaseStatusScheduled,
+			},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			deployment, _ := NewDeploymentFromConstructor(&DeploymentConstructor{
+				Phases: tc.Phases,
+			})
+			deployment.MaxDevices = tc.MaxDevices
+
+			assert.Equal(t, tc.Limit, deployment.PhasedDeviceLimit(now))
+			info := deployment.PhasesInfo(now)
+			statuses := make([]string, len(info))
+			for i := range info {
+				assert.NotEmpty(t, info[i].Id)
+				statuses[i] = info[i].Status
+			}
+			assert.Equal(t, tc.Statuses, statuses)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// DevicePreviewStatus is the expected outcome of a deployment for a device.
+type DevicePreviewStatus string
+
+const (
+	// DevicePreviewStatusWillInstall: an artifact of the deployment is
+	// compatible with the device and not installed yet.
+	DevicePreviewStatusWillInstall DevicePreviewStatus = "will-install"
+	// DevicePreviewStatusAlreadyInstalled: the device already runs the
+	// artifact and the deployment does not force the installation.
+	DevicePreviewStatusAlreadyInstalled DevicePreviewStatus = "already-installed"
+	// DevicePreviewStatusIncompatible: no full artifact of the deployment
+	// matches the device type, and no delta artifact applies to the
+	// artifact installed on the device.
+	DevicePreviewStatusIncompatible DevicePreviewStatus = "incompatible"
+	// DevicePreviewStatusUnknown: the inventory does not hold the device
+	// type and the installed artifact of the device.
+	DevicePreviewStatusUnknown DevicePreviewStatus = "unknown"
+)
+
+func (s DevicePreviewStatus) Validate() error {
+	return validation.Validate(string(s), validation.In(
+		string(DevicePreviewStatusWillInstall),
+		string(DevicePreviewStatusAlreadyInstalled),
+		string(DevicePreviewStatusIncompatible),
+		string(DevicePreviewStatusUnknown),
+	))
+}
+
+// DevicePreview is the expected outcome of a deployment for a device.
+type DevicePreview struct {
+	ID     string              `json:"id"`
+	Status DevicePreviewStatus `json:"status"`
+	// DeviceType and ArtifactName are reported by the device inventory.
+	DeviceType   string `json:"device_type,omitempty"`
+	ArtifactName string `json:"artifact_name,omitempty"`
+	// ArtifactID is the ID of the artifact selected for the device.
+	ArtifactID string `json:"artifact_id,omitempty"`
+}
+
+// DeploymentPreviewCounts holds the number of devices per status.
+type DeploymentPreviewCounts struct {
+	Total            int `json:"total"`
+	WillInstall      int `json:"will-install"`
+	AlreadyInstalled int `json:"already-installed"`
+	Incompatible     int `json:"incompatible"`
+	Unknown          int `json:"unknown"`
+}
+
+func (c *DeploymentPreviewCounts) Add(status DevicePreviewStatus) {
+	c.Total++
+	switch status {
+	case DevicePreviewStatusWillInstall:
+		c.WillInstall++
+	case DevicePreviewStatusAlreadyInstalled:
+		c.AlreadyInstalled++
+	case DevicePreviewStatusIncompatible:
+		c.Incompatible++
+	case DevicePreviewStatusUnknown:
+		c.Unknown++
+	}
+}
+
+// Count returns the number of devices with the status, or all the devices
+// if the status is empty.
+func (c DeploymentPreviewCounts) Count(status DevicePreviewStatus) int {
+	switch status {
+	case DevicePreviewStatusWillInstall:
+		return c.WillInstall
+	case DevicePreviewStatusAlreadyInstalled:
+		return c.AlreadyInstalled
+	case DevicePreviewStatusIncompatible:
+		return c.Incompatible
+	case DevicePreviewStatusUnknown:
+		return c.Unknown
+	}
+	return c.Total
+}
+
+// DeploymentPreview is the expected outcome of a deployment which is not
+// created yet.
+type DeploymentPreview struct {
+	Counts DeploymentPreviewCounts `json:"counts"`
+	// Devices is the requested page of the devices.
+	Devices []DevicePreview `json:"devices"`
+}
+
+// DeploymentPreviewQuery selects the page of the devices of the preview.
+type DeploymentPreviewQuery struct {
+	Page    int64
+	PerPage int64
+	// Status filters the devices by status; all the devices are listed
+	// if empty.
+	Status DevicePreviewStatus
+}
+
+func (q DeploymentPreviewQuery) Validate() error {
+	return validation.ValidateStruct(&q,
+		validation.Field(&q.Page, validation.Required, validation.Min(int64(1))),
+		validation.Field(&q.PerPage, validation.Required, validation.Min(int64(1))),
+		validation.Field(&q.Status),
+	)
+}
+
+// Installed returns the artifact installed on the device according to the
+// inventory attributes, or nil if the inventory does not hold the device
+// type and the artifact name of the device. The provides of the installed
+// artifact are reported as inventory attributes too.
+func (d InvDevice) Installed() *InstalledDeviceDeployment {
+	installed := &InstalledDeviceDeployment{
+		Provides: map[string]string{},
+	}
+	for _, attr := range d.Attributes {
+		if attr.Scope != AttrScopeInventory {
+			continue
+		}
+		value, ok := attr.Value.(string)
+		if !ok {
+			continue
+		}
+		switch attr.Name {
+		case artifactDependsDeviceType:
+			installed.DeviceType = value
+		case artifactDependsArtifactName:
+			installed.ArtifactName = value
+		default:
+			installed.Provides[attr.Name] = value
+		}
+	}
+	if installed.DeviceType == "" || installed.ArtifactName == "" {
+		return nil
+	}
+	return installed
+}
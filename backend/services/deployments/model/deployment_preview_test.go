@@ -0,0 +1,148 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentPreviewQueryValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		query DeploymentPreviewQuery
+		err   bool
+	}{
+		"ok": {
+			query: DeploymentPreviewQuery{Page: 1, PerPage: 20},
+		},
+		"ok, status": {
+			query: DeploymentPreviewQuery{
+				Page:    2,
+				PerPage: 20,
+				Status:  DevicePreviewStatusIncompatible,
+			},
+		},
+		"error, page": {
+			query: DeploymentPreviewQuery{PerPage: 20},
+			err:   true,
+		},
+		"error, status": {
+			query: DeploymentPreviewQuery{
+				Page:    1,
+				PerPage: 20,
+				Status:  "installed",
+			},
+			err: true,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.query.Validate()
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDeploymentPreviewCounts(t *testing.T) {
+	t.Parallel()
+
+	var counts DeploymentPreviewCounts
+	counts.Add(DevicePreviewStatusWillInstall)
+	counts.Add(DevicePreviewStatusWillInstall)
+	counts.Add(DevicePreviewStatusAlreadyInstalled)
+	counts.Add(DevicePreviewStatusUnknown)
+
+	assert.Equal(t, DeploymentPreviewCounts{
+		Total:            4,
+		WillInstall:      2,
+		AlreadyInstalled: 1,
+		Unknown:          1,
+	}, counts)
+	assert.Equal(t, 4, counts.Count(""))
+	assert.Equal(t, 2, counts.Count(DevicePreviewStatusWillInstall))
+	assert.Equal(t, 0, counts.Count(DevicePreviewStatusIncompatible))
+}
+
+func TestInvDeviceInstalled(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		device   InvDevice
+		expected *InstalledDeviceDeployment
+	}{
+		"ok": {
+			device: InvDevice{
+				ID: "1",
+				Attributes: []DeviceAttribute{{
+					Name:  "device_type",
+					Value: "rpi4",
+					Scope: AttrScopeInventory,
+				}, {
+					Name:  "artifact_name",
+					Value: "release-1",
+					Scope: AttrScopeInventory,
+				}, {
+					Name:  "rootfs-image.checksum",
+					Value: "checksum",
+					Scope: AttrScopeInventory,
+				}, {
+					Name:  "status",
+					Value: "accepted",
+					Scope: "identity",
+				}, {
+					Name:  "cpu_cores",
+					Value: float64(4),
+					Scope: AttrScopeInventory,
+				}},
+			},
+			expected: &InstalledDeviceDeployment{
+				ArtifactName: "release-1",
+				DeviceType:   "rpi4",
+				Provides: map[string]string{
+					"rootfs-image.checksum": "checksum",
+				},
+			},
+		},
+		"missing artifact name": {
+			device: InvDevice{
+				ID: "1",
+				Attributes: []DeviceAttribute{{
+					Name:  "device_type",
+					Value: "rpi4",
+					Scope: AttrScopeInventory,
+				}},
+			},
+		},
+		"no attributes": {
+			device: InvDevice{ID: "1"},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, tc.device.Installed())
+		})
+	}
+}
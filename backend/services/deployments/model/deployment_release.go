@@ -0,0 +1,92 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"slices"
+	"sort"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidDeploymentRelease = errors.New(
+		"Invalid release: either name or tag must be set",
+	)
+	ErrInvalidDeploymentReleaseConflict = errors.New(
+		"Invalid deployments definition: artifact_name provided together with release",
+	)
+)
+
+// DeploymentRelease selects the release deployed by a deployment; the
+// artifact matching each device is picked among the artifacts of the
+// release. The selection is an exact name and/or a single tag: releases
+// have no version besides their name, so version ranges are not supported,
+// and a moving version is followed by selecting the release by tag.
+type DeploymentRelease struct {
+	// Name of the release; when empty, the most recently modified
+	// release with the tag is deployed
+	Name string `json:"name,omitempty" bson:"name,omitempty"`
+
+	// Tag the release must be tagged with
+	Tag Tag `json:"tag,omitempty" bson:"tag,omitempty"`
+}
+
+func (r DeploymentRelease) Validate() error {
+	err := validation.ValidateStruct(&r,
+		validation.Field(&r.Name, lengthIn1To4096),
+		validation.Field(&r.Tag, validation.Skip.When(r.Tag == "")),
+	)
+	if err != nil {
+		return err
+	}
+	if r.Name == "" && r.Tag == "" {
+		return ErrInvalidDeploymentRelease
+	}
+	return nil
+}
+
+// Matches returns true if the release satisfies the selection.
+func (r DeploymentRelease) Matches(release *Release) bool {
+	if release == nil {
+		return false
+	}
+	if r.Name != "" && r.Name != release.Name {
+		return false
+	}
+	return r.Tag == "" || slices.Contains(release.Tags, r.Tag)
+}
+
+// IncompatibleDeviceTypes returns the sorted device types, among the given
+// ones, which no artifact of the release is compatible with.
+func (release Release) IncompatibleDeviceTypes(deviceTypes []string) []string {
+	var incompatible []string
+	for _, deviceType := range deviceTypes {
+		compatible := false
+		for _, artifact := range release.Artifacts {
+			if artifact.ArtifactMeta != nil &&
+				slices.Contains(artifact.ArtifactMeta.DeviceTypesCompatible, deviceType) {
+				compatible = true
+				break
+			}
+		}
+		if !compatible && !slices.Contains(incompatible, deviceType) {
+			incompatible = append(incompatible, deviceType)
+		}
+	}
+	sort.Strings(incompatible)
+	return incompatible
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentReleaseValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		release DeploymentRelease
+
+		err error
+	}{
+		"ok, name": {
+			release: DeploymentRelease{Name: "release-1"},
+		},
+		"ok, tag": {
+			release: DeploymentRelease{Tag: "stable"},
+		},
+		"ok, name and tag": {
+			release: DeploymentRelease{Name: "release-1", Tag: "stable"},
+		},
+		"error, empty": {
+			err: ErrInvalidDeploymentRelease,
+		},
+		"error, name too long": {
+			release: DeploymentRelease{Name: strings.Repeat("a", 4097)},
+			err:     assert.AnError,
+		},
+		"error, invalid tag": {
+			release: DeploymentRelease{Tag: "not a tag!"},
+			err:     assert.AnError,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.release.Validate()
+			switch tc.err {
+			case nil:
+				assert.NoError(t, err)
+			case assert.AnError:
+				assert.Error(t, err)
+			default:
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestDeploymentReleaseMatches(t *testing.T) {
+	t.Parallel()
+
+	release := &Release{Name: "release-1", Tags: Tags{"stable"}}
+
+	assert.False(t, DeploymentRelease{Name: "release-1"}.Matches(nil))
+	assert.True(t, DeploymentRelease{Name: "release-1"}.Matches(release))
+	assert.True(t, DeploymentRelease{Tag: "stable"}.Matches(release))
+	assert.True(t, DeploymentRelease{Name: "release-1", Tag: "stable"}.Matches(release))
+	assert.False(t, DeploymentRelease{Name: "release-2"}.Matches(release))
+	assert.False(t, DeploymentRelease{Name: "release-1", Tag: "beta"}.Matches(release))
+}
+
+func TestReleaseIncompatibleDeviceTypes(t *testing.T) {
+	t.Parallel()
+
+	release := Release{
+		Name: "release-1",
+		Artifacts: []Image{{
+			ArtifactMeta: &ArtifactMeta{DeviceTypesCompatible: []string{"rpi3", "rpi4"}},
+		}, {
+			ArtifactMeta: &ArtifactMeta{DeviceTypesCompatible: []string{"qemu"}},
+		}, {}},
+	}
+	assert.Empty(t, release.IncompatibleDeviceTypes(nil))
+	assert.Empty(t, release.IncompatibleDeviceTypes([]string{"rpi4", "qemu"}))
+	assert.Equal(t, []string{"bbb", "x86"}, release.IncompatibleDeviceTypes(
+		[]string{"x86", "rpi3", "bbb", "x86"},
+	))
+}
+
+func TestDeploymentConstructorValidateRelease(t *testing.T) {
+	t.Parallel()
+
+	constructor := DeploymentConstructor{
+		Name:       "foo",
+		AllDevices: true,
+		Release:    &DeploymentRelease{Name: "release-1"},
+	}
+	assert.NoError(t, constructor.ValidateNew())
+
+	constructor.ArtifactName = "release-1"
+	assert.ErrorIs(t, constructor.ValidateNew(), ErrInvalidDeploymentReleaseConflict)
+
+	constructor.ArtifactName = ""
+	constructor.Release = &DeploymentRelease{}
+	assert.Error(t, constructor.ValidateNew())
+
+	constructor.Release = nil
+	assert.Error(t, constructor.ValidateNew())
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+const (
+	// MaxDeploymentRetries is the maximum number of times a device retries
+	// a failed deployment.
+	MaxDeploymentRetries = 100
+	// MaxDeploymentRetryBackoff caps the delay between two attempts.
+	MaxDeploymentRetryBackoff = 24 * time.Hour
+)
+
+// DeviceDeploymentAttempt is a failed attempt of the device to install the
+// deployment, recorded when the deployment is offered to the device again.
+type DeviceDeploymentAttempt struct {
+	Started  *time.Time             `json:"started,omitempty" bson:"started,omitempty"`
+	Finished *time.Time             `json:"finished" bson:"finished"`
+	Status   DeviceDeploymentStatus `json:"status" bson:"status"`
+	SubState string                 `json:"substate,omitempty" bson:"substate,omitempty"`
+	// LogAvailable is set if the device uploaded a log during the attempt;
+	// the log is stored apart with the number of the attempt.
+	LogAvailable bool `json:"log_available" bson:"log_available"`
+}
+
+// CanRetry returns true if the device may try the deployment again after
+// reporting a failure.
+func (d *DeviceDeployment) CanRetry() bool {
+	return len(d.Attempts) < int(d.Retries)
+}
+
+// RetryDelay returns the time the device waits before the next attempt,
+// doubling the backoff after each failed attempt.
+func (d *Deployment) RetryDelay(attempts int) time.Duration {
+	if d.DeploymentConstructor == nil || d.RetryBackoff == 0 {
+		return 0
+	}
+	delay := time.Duration(d.RetryBackoff) * time.Second
+	for i := 0; i < attempts && delay < MaxDeploymentRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, MaxDeploymentRetryBackoff)
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		backoff  uint
+		attempts int
+		delay    time.Duration
+	}{
+		"no backoff": {
+			attempts: 3,
+		},
+		"first attempt": {
+			backoff: 30,
+			delay:   30 * time.Second,
+		},
+		"third attempt": {
+			backoff:  30,
+			attempts: 2,
+			delay:    2 * time.Minute,
+		},
+		"capped": {
+			backoff:  3600,
+			attempts: 50,
+			delay:    MaxDeploymentRetryBackoff,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			deployment, _ := NewDeploymentFromConstructor(&DeploymentConstructor{
+				Retries:      MaxDeploymentRetries,
+				RetryBackoff: tc.backoff,
+			})
+			assert.Equal(t, tc.delay, deployment.RetryDelay(tc.attempts))
+		})
+	}
+}
+
+func TestDeviceDeploymentCanRetry(t *testing.T) {
+	t.Parallel()
+
+	dd := NewDeviceDeployment("device", "f826484e-1157-4109-af21-304e6d711560")
+	assert.False(t, dd.CanRetry())
+
+	dd.Retries = 2
+	assert.True(t, dd.CanRetry())
+
+	dd.Attempts = make([]DeviceDeploymentAttempt, 2)
+	assert.False(t, dd.CanRetry())
+}
+
+func TestDeploymentConstructorValidateRetries(t *testing.T) {
+	t.Parallel()
+
+	constructor := DeploymentConstructor{
+		Name:         "foo",
+		ArtifactName: "bar",
+		AllDevices:   true,
+		Retries:      3,
+		RetryBackoff: 60,
+	}
+	assert.NoError(t, constructor.ValidateNew())
+
+	constructor.Retries = MaxDeploymentRetries + 1
+	assert.Error(t, constructor.ValidateNew())
+
+	constructor.Retries = 3
+	constructor.RetryBackoff = uint(MaxDeploymentRetryBackoff/time.Second) + 1
+	assert.Error(t, constructor.ValidateNew())
+}
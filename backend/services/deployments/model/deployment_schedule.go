@@ -0,0 +1,209 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxMaintenanceWindowDuration is the maximum duration of a
+	// maintenance window in minutes (one week)
+	MaxMaintenanceWindowDuration = 7 * 24 * 60
+)
+
+var (
+	ErrInvalidDeploymentEndTime = errors.New(
+		"Invalid deployments definition: end_ts must be after start_ts",
+	)
+	ErrInvalidCronSchedule = errors.New("invalid cron schedule")
+)
+
+// MaintenanceWindow defines the recurring periods during which the devices
+// are allowed to start a deployment.
+type MaintenanceWindow struct {
+	// Cron expression (minute hour day-of-month month day-of-week)
+	// defining when the window opens
+	Schedule string `json:"schedule" bson:"schedule"`
+
+	// Duration of the window in minutes
+	Duration int `json:"duration" bson:"duration"`
+
+	// IANA time zone the schedule is evaluated in, defaults to UTC
+	Timezone string `json:"timezone,omitempty" bson:"timezone,omitempty"`
+
+	// Name of the inventory attribute holding the IANA time zone of the
+	// device; when present it takes precedence over Timezone
+	//nolint:lll
+	TimezoneAttribute string `json:"timezone_attribute,omitempty" bson:"timezone_attribute,omitempty"`
+}
+
+func (w MaintenanceWindow) Validate() error {
+	return validation.ValidateStruct(&w,
+		validation.Field(&w.Schedule, validation.Required,
+			validation.By(func(interface{}) error {
+				_, err := parseCronSchedule(w.Schedule)
+				return err
+			}),
+		),
+		validation.Field(&w.Duration, validation.Required,
+			validation.Min(1), validation.Max(MaxMaintenanceWindowDuration)),
+		validation.Field(&w.Timezone, validation.By(func(interface{}) error {
+			_, err := time.LoadLocation(w.Timezone)
+			return err
+		})),
+		validation.Field(&w.TimezoneAttribute, lengthLessThan4096),
+	)
+}
+
+// Location returns the time zone configured for the window.
+func (w MaintenanceWindow) Location() *time.Location {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// IsOpen returns true if the window is open at the given time evaluated in
+// the given time zone.
+func (w MaintenanceWindow) IsOpen(now time.Time, loc *time.Location) bool {
+	schedule, err := parseCronSchedule(w.Schedule)
+	if err != nil {
+		return false
+	}
+	if loc == nil {
+		loc = w.Location()
+	}
+	t := now.In(loc).Truncate(time.Minute)
+	for i := 0; i < w.Duration; i++ {
+		if schedule.matches(t.Add(-time.Duration(i) * time.Minute)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsScheduled returns true if the deployment start time is in the future.
+func (d *Deployment) IsScheduled(now time.Time) bool {
+	return d.DeploymentConstructor != nil &&
+		d.StartTime != nil && now.Before(*d.StartTime)
+}
+
+// IsExpired returns true if the deployment end time has passed.
+func (d *Deployment) IsExpired(now time.Time) bool {
+	return d.DeploymentConstructor != nil &&
+		d.EndTime != nil && !now.Before(*d.EndTime)
+}
+
+type cronField struct {
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week, both 0 and 7 are sunday
+}
+
+type cronSchedule struct {
+	fields [5]uint64
+	// day of month and day of week restricted
+	domSet, dowSet bool
+}
+
+func parseCronSchedule(spec string) (*cronSchedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, errors.Wrap(ErrInvalidCronSchedule, "expected 5 fields")
+	}
+	s := &cronSchedule{}
+	for i, part := range parts {
+		bits, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidCronSchedule, "field %q", part)
+		}
+		s.fields[i] = bits
+	}
+	// sunday can be expressed as 7
+	if s.fields[4]&(1<<7) != 0 {
+		s.fields[4] |= 1
+	}
+	s.domSet = parts[2] != "*"
+	s.dowSet = parts[4] != "*"
+	return s, nil
+}
+
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		step := 1
+		if rng, stepStr, ok := strings.Cut(expr, "/"); ok {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step < 1 {
+				return 0, ErrInvalidCronSchedule
+			}
+			expr = rng
+		}
+		low, high := bounds.min, bounds.max
+		if expr != "*" {
+			var err error
+			lowStr, highStr, isRange := strings.Cut(expr, "-")
+			low, err = strconv.Atoi(lowStr)
+			if err != nil {
+				return 0, ErrInvalidCronSchedule
+			}
+			high = low
+			if isRange {
+				high, err = strconv.Atoi(highStr)
+				if err != nil {
+					return 0, ErrInvalidCronSchedule
+				}
+			}
+		}
+		if low < bounds.min || high > bounds.max || low > high {
+			return 0, ErrInvalidCronSchedule
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSchedule) matches(t time.Time) bool {
+	has := func(field int, v int) bool {
+		return s.fields[field]&(1<<uint(v)) != 0
+	}
+	if !has(0, t.Minute()) || !has(1, t.Hour()) || !has(3, int(t.Month())) {
+		return false
+	}
+	dom := has(2, t.Day())
+	dow := has(4, int(t.Weekday()))
+	// standard cron semantics: when both day fields are restricted
+	// it is enough for one of them to match
+	if s.domSet && s.dowSet {
+		return dom || dow
+	}
+	return dom && dow
+}
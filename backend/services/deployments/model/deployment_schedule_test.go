@@ -0,0 +1,170 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		window MaintenanceWindow
+		err    bool
+	}{
+		"ok": {
+			window: MaintenanceWindow{
+				Schedule: "0 22 * * 1-5",
+				Duration: 480,
+				Timezone: "Europe/Oslo",
+			},
+		},
+		"ok, lists and steps": {
+			window: MaintenanceWindow{
+				Schedule: "0,30 */2 1-15 1,6 0,7",
+				Duration: 30,
+			},
+		},
+		"error, missing field": {
+			window: MaintenanceWindow{
+				Schedule: "0 22 * *",
+				Duration: 60,
+			},
+			err: true,
+		},
+		"error, out of range": {
+			window: MaintenanceWindow{
+				Schedule: "0 24 * * *",
+				Duration: 60,
+			},
+			err: true,
+		},
+		"error, invalid step": {
+			window: MaintenanceWindow{
+				Schedule: "*/0 * * * *",
+				Duration: 60,
+			},
+			err: true,
+		},
+		"error, no duration": {
+			window: MaintenanceWindow{
+				Schedule: "0 22 * * *",
+			},
+			err: true,
+		},
+		"error, invalid time zone": {
+			window: MaintenanceWindow{
+				Schedule: "0 22 * * *",
+				Duration: 60,
+				Timezone: "Mars/Olympus_Mons",
+			},
+			err: true,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.window.Validate()
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowIsOpen(t *testing.T) {
+	t.Parallel()
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// weekdays from 22:00 to 06:00
+	window := MaintenanceWindow{
+		Schedule: "0 22 * * 1-5",
+		Duration: 8 * 60,
+		Timezone: "Europe/Oslo",
+	}
+
+	testCases := map[string]struct {
+		now  time.Time
+		loc  *time.Location
+		open bool
+	}{
+		"open, window start": {
+			// Monday
+			now:  time.Date(2024, 3, 4, 22, 0, 0, 0, oslo),
+			open: true,
+		},
+		"open, after midnight": {
+			now:  time.Date(2024, 3, 5, 5, 59, 0, 0, oslo),
+			open: true,
+		},
+		"closed, window end": {
+			now: time.Date(2024, 3, 5, 6, 0, 0, 0, oslo),
+		},
+		"closed, weekend": {
+			// Saturday
+			now: time.Date(2024, 3, 9, 23, 0, 0, 0, oslo),
+		},
+		"open, evaluated in UTC": {
+			now:  time.Date(2024, 3, 4, 22, 30, 0, 0, time.UTC),
+			open: true,
+		},
+		"closed, device time zone": {
+			now: time.Date(2024, 3, 4, 22, 30, 0, 0, time.UTC),
+			loc: time.FixedZone("UTC+14", 14*3600),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.open, window.IsOpen(tc.now, tc.loc))
+		})
+	}
+}
+
+func TestDeploymentSchedule(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	constructor := DeploymentConstructor{
+		Name:         "foo",
+		ArtifactName: "bar",
+		StartTime:    &after,
+		EndTime:      &before,
+	}
+	assert.ErrorContains(t, constructor.Validate(), ErrInvalidDeploymentEndTime.Error())
+
+	deployment := &Deployment{DeploymentConstructor: &DeploymentConstructor{
+		StartTime: &before,
+		EndTime:   &after,
+	}}
+	assert.False(t, deployment.IsScheduled(now))
+	assert.False(t, deployment.IsExpired(now))
+	assert.True(t, deployment.IsScheduled(before.Add(-time.Minute)))
+	assert.True(t, deployment.IsExpired(after))
+}
@@ -18,6 +18,9 @@ import (
 	"time"
 )
 
+// AttrScopeInventory is the scope of the attributes reported by the devices.
+const AttrScopeInventory = "inventory"
+
 type DeviceAttribute struct {
 	Name        string      `json:"name" bson:",omitempty"`
 	Description *string     `json:"description,omitempty" bson:",omitempty"`
@@ -59,6 +59,8 @@ const (
 	DeviceDeploymentStatusDecommissioned
 	// DeviceDeploymentStatusNew = (DeviceDeploymentStatusSuccess +
 	// DeviceDeploymentStatusNoArtifact) / 2
+	DeviceDeploymentStatusExpired = (DeviceDeploymentStatusAborted +
+		DeviceDeploymentStatusPauseBeforeInstall) / 2
 
 	DeviceDeploymentStatusActiveLow  = DeviceDeploymentStatusPauseBeforeInstall
 	DeviceDeploymentStatusActiveHigh = DeviceDeploymentStatusPending
@@ -76,6 +78,7 @@ const (
 	DeviceDeploymentStatusNoArtifactStr         = "noartifact"
 	DeviceDeploymentStatusAlreadyInstStr        = "already-installed"
 	DeviceDeploymentStatusDecommissionedStr     = "decommissioned"
+	DeviceDeploymentStatusExpiredStr            = "expired"
 	// DeviceDeploymentStatusNew = "lorem-ipsum"
 )
 
@@ -105,6 +108,7 @@ var allStatuses = []DeviceDeploymentStatus{
 	DeviceDeploymentStatusNoArtifact,
 	DeviceDeploymentStatusAlreadyInst,
 	DeviceDeploymentStatusDecommissioned,
+	DeviceDeploymentStatusExpired,
 	// DeviceDeploymentStatusNew
 }
 
@@ -136,6 +140,8 @@ func (stat DeviceDeploymentStatus) MarshalText() ([]byte, error) {
 		return []byte(DeviceDeploymentStatusAlreadyInstStr), nil
 	case DeviceDeploymentStatusDecommissioned:
 		return []byte(DeviceDeploymentStatusDecommissionedStr), nil
+	case DeviceDeploymentStatusExpired:
+		return []byte(DeviceDeploymentStatusExpiredStr), nil
 	//case DeviceDeploymentStatusNew:
 	//	return []byte(DeviceDeploymentStatusNewStr), nil
 	case 0:
@@ -181,6 +187,8 @@ func (stat *DeviceDeploymentStatus) UnmarshalText(b []byte) error {
 		*stat = DeviceDeploymentStatusAlreadyInst
 	case DeviceDeploymentStatusDecommissionedStr:
 		*stat = DeviceDeploymentStatusDecommissioned
+	case DeviceDeploymentStatusExpiredStr:
+		*stat = DeviceDeploymentStatusExpired
 	//case DeviceDeploymentStatusNewStr:
 	//	*stat = DeviceDeploymentStatusNew
 	default:
@@ -251,6 +259,18 @@ type DeviceDeployment struct {
 
 	// Device reported substate
 	SubState string `json:"substate,omitempty" bson:"substate,omitempty"`
+
+	// Number of times the deployment is offered again after a failure
+	Retries uint `json:"retries,omitempty" bson:"retries,omitempty"`
+
+	// Failed attempts of the device to install the deployment
+	Attempts []DeviceDeploymentAttempt `json:"attempts,omitempty" bson:"attempts,omitempty"`
+
+	// Time before which the deployment is not offered again to the device
+	RetryAfter *time.Time `json:"retry_after,omitempty" bson:"retry_after,omitempty"`
+
+	// Update control map states in which the paused device was continued
+	ContinuedStates []string `json:"continued_states,omitempty" bson:"continued_states,omitempty"`
 }
 
 func NewDeviceDeployment(deviceId, deploymentId string) *DeviceDeployment {
@@ -332,7 +352,8 @@ func (s Stats) Get(status DeviceDeploymentStatus) int {
 func IsDeviceDeploymentStatusFinished(status DeviceDeploymentStatus) bool {
 	if status == DeviceDeploymentStatusFailure || status == DeviceDeploymentStatusSuccess ||
 		status == DeviceDeploymentStatusNoArtifact || status == DeviceDeploymentStatusAlreadyInst ||
-		status == DeviceDeploymentStatusAborted || status == DeviceDeploymentStatusDecommissioned {
+		status == DeviceDeploymentStatusAborted || status == DeviceDeploymentStatusDecommissioned ||
+		status == DeviceDeploymentStatusExpired {
 		return true
 	}
 	return false
@@ -360,6 +381,7 @@ func InactiveDeploymentStatuses() []DeviceDeploymentStatus {
 		DeviceDeploymentStatusAlreadyInst,
 		DeviceDeploymentStatusAborted,
 		DeviceDeploymentStatusDecommissioned,
+		DeviceDeploymentStatusExpired,
 	}
 }
 
@@ -0,0 +1,109 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	DeviceDeploymentHistoryFormatCSV    = "csv"
+	DeviceDeploymentHistoryFormatNDJSON = "ndjson"
+)
+
+var (
+	ErrDeviceDeploymentHistoryDevices = errors.New(
+		"only one of the device IDs and the group can be specified")
+	ErrDeviceDeploymentHistoryRange = errors.New(
+		"created_before must be after created_after")
+
+	// DeviceDeploymentHistoryCSVHeader is the header of the CSV export
+	// of the device deployment history, in the order of the record fields.
+	DeviceDeploymentHistoryCSVHeader = []string{
+		"device_id",
+		"deployment_id",
+		"deployment_name",
+		"artifact_name",
+		"status",
+		"substate",
+		"created",
+		"started",
+		"finished",
+	}
+)
+
+// DeviceDeploymentHistoryFilter selects the device deployments to export.
+type DeviceDeploymentHistoryFilter struct {
+	// DeviceIDs restricts the export to the devices; nil selects all
+	// devices while an empty slice selects none.
+	DeviceIDs []string `json:"device_id"`
+	// Group restricts the export to the devices of the inventory group.
+	Group string `json:"group"`
+
+	CreatedAfter  *time.Time `json:"created_after"`
+	CreatedBefore *time.Time `json:"created_before"`
+}
+
+func (f DeviceDeploymentHistoryFilter) Validate() error {
+	if len(f.DeviceIDs) > 0 && f.Group != "" {
+		return ErrDeviceDeploymentHistoryDevices
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil &&
+		!f.CreatedBefore.After(*f.CreatedAfter) {
+		return ErrDeviceDeploymentHistoryRange
+	}
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.DeviceIDs, validation.Each(validation.Required)),
+	)
+}
+
+// DeviceDeploymentHistoryRecord is a device deployment in the export of the
+// deployment history of the devices.
+type DeviceDeploymentHistoryRecord struct {
+	DeviceID       string                 `json:"device_id" bson:"deviceid"`
+	DeploymentID   string                 `json:"deployment_id" bson:"deploymentid"`
+	DeploymentName string                 `json:"deployment_name" bson:"deployment_name"`
+	ArtifactName   string                 `json:"artifact_name" bson:"artifact_name"`
+	Status         DeviceDeploymentStatus `json:"status" bson:"status"`
+	SubState       string                 `json:"substate,omitempty" bson:"substate,omitempty"`
+	Created        *time.Time             `json:"created,omitempty" bson:"created,omitempty"`
+	Started        *time.Time             `json:"started,omitempty" bson:"started,omitempty"`
+	Finished       *time.Time             `json:"finished,omitempty" bson:"finished,omitempty"`
+}
+
+// CSVRecord returns the fields of the record in the order of
+// DeviceDeploymentHistoryCSVHeader.
+func (r DeviceDeploymentHistoryRecord) CSVRecord() []string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		r.DeviceID,
+		r.DeploymentID,
+		r.DeploymentName,
+		r.ArtifactName,
+		r.Status.String(),
+		r.SubState,
+		formatTime(r.Created),
+		formatTime(r.Started),
+		formatTime(r.Finished),
+	}
+}
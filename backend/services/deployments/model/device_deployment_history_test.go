@@ -0,0 +1,77 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceDeploymentHistoryFilterValidate(t *testing.T) {
+	t.Parallel()
+
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, DeviceDeploymentHistoryFilter{}.Validate())
+	assert.NoError(t, DeviceDeploymentHistoryFilter{
+		DeviceIDs:     []string{"device-1", "device-2"},
+		CreatedAfter:  &after,
+		CreatedBefore: &before,
+	}.Validate())
+	assert.NoError(t, DeviceDeploymentHistoryFilter{Group: "regulated"}.Validate())
+	assert.ErrorIs(t, DeviceDeploymentHistoryFilter{
+		DeviceIDs: []string{"device-1"},
+		Group:     "regulated",
+	}.Validate(), ErrDeviceDeploymentHistoryDevices)
+	assert.ErrorIs(t, DeviceDeploymentHistoryFilter{
+		CreatedAfter:  &before,
+		CreatedBefore: &after,
+	}.Validate(), ErrDeviceDeploymentHistoryRange)
+	assert.Error(t, DeviceDeploymentHistoryFilter{
+		DeviceIDs: []string{""},
+	}.Validate())
+}
+
+func TestDeviceDeploymentHistoryRecordCSV(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	finished := created.Add(time.Hour)
+	record := DeviceDeploymentHistoryRecord{
+		DeviceID:       "device-1",
+		DeploymentID:   "f826484e-1157-4109-af21-304e6d711560",
+		DeploymentName: "Q1 update",
+		ArtifactName:   "release-1",
+		Status:         DeviceDeploymentStatusFailure,
+		SubState:       "ArtifactInstall",
+		Created:        &created,
+		Finished:       &finished,
+	}
+	assert.Equal(t, []string{
+		"device-1",
+		"f826484e-1157-4109-af21-304e6d711560",
+		"Q1 update",
+		"release-1",
+		"failure",
+		"ArtifactInstall",
+		"2024-01-01T09:00:00Z",
+		"",
+		"2024-01-01T10:00:00Z",
+	}, record.CSVRecord())
+	assert.Len(t, record.CSVRecord(), len(DeviceDeploymentHistoryCSVHeader))
+}
@@ -17,6 +17,8 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -65,6 +67,13 @@ type DeploymentLog struct {
 	DeploymentID string `json:"-" valid:"uuidv4,required"`
 
 	Messages []LogMessage `json:"messages" valid:"required"`
+
+	// Time after which the log is removed, if set
+	ExpireAt *time.Time `json:"-" bson:"expire_at,omitempty"`
+
+	// Number of the failed attempt of the device the log belongs to,
+	// starting from 1; zero for the current attempt.
+	Attempt int `json:"-" bson:"attempt,omitempty"`
 }
 
 func (d *DeploymentLog) UnmarshalJSON(raw []byte) error {
@@ -91,3 +100,72 @@ func (d DeploymentLog) Validate() error {
 		validation.Field(&d.Messages, validation.Required),
 	)
 }
+
+const DeploymentLogSearchPerPageMax = 500
+
+// DeploymentLogSearch finds the device deployment logs of a deployment
+// containing a string or matching a regular expression.
+type DeploymentLogSearch struct {
+	DeploymentID string
+
+	// String to search for, or regular expression if Regex is set
+	Query string
+	Regex bool
+
+	Page    int
+	PerPage int
+}
+
+func (s DeploymentLogSearch) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.DeploymentID, validation.Required, is.UUID),
+		validation.Field(&s.Query, validation.Required, lengthLessThan4096,
+			validation.By(func(interface{}) error {
+				_, err := regexp.Compile(s.Pattern())
+				return err
+			}),
+		),
+		validation.Field(&s.Page, validation.Required, validation.Min(1)),
+		validation.Field(&s.PerPage, validation.Required,
+			validation.Min(1), validation.Max(DeploymentLogSearchPerPageMax)),
+	)
+}
+
+// Pattern returns the regular expression matching the log messages.
+func (s DeploymentLogSearch) Pattern() string {
+	if s.Regex {
+		return s.Query
+	}
+	return regexp.QuoteMeta(s.Query)
+}
+
+// Match returns the messages of the log matching the search, or nil if the
+// search is not a valid regular expression.
+func (s DeploymentLogSearch) Match(log DeploymentLog) []LogMessage {
+	var matches []LogMessage
+	if !s.Regex {
+		for _, msg := range log.Messages {
+			if strings.Contains(msg.Message, s.Query) {
+				matches = append(matches, msg)
+			}
+		}
+		return matches
+	}
+	re, err := regexp.Compile(s.Query)
+	if err != nil {
+		return nil
+	}
+	for _, msg := range log.Messages {
+		if re.MatchString(msg.Message) {
+			matches = append(matches, msg)
+		}
+	}
+	return matches
+}
+
+// DeploymentLogMatch holds the messages of a device deployment log matching
+// a search.
+type DeploymentLogMatch struct {
+	DeviceID string       `json:"device_id"`
+	Messages []LogMessage `json:"messages"`
+}
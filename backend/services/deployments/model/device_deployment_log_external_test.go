@@ -194,3 +194,114 @@ func TestUnmarshalLogMessage(t *testing.T) {
 	}
 
 }
+
+func TestDeploymentLogSearchValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Search DeploymentLogSearch
+		Error  string
+	}{
+		"ok": {
+			Search: DeploymentLogSearch{
+				DeploymentID: "30b3e62c-9ec2-4312-a7fa-cff24cc7397a",
+				Query:        "fail(",
+				Page:         1,
+				PerPage:      20,
+			},
+		},
+		"ok, regex": {
+			Search: DeploymentLogSearch{
+				DeploymentID: "30b3e62c-9ec2-4312-a7fa-cff24cc7397a",
+				Query:        "fail(ed|ure)",
+				Regex:        true,
+				Page:         1,
+				PerPage:      DeploymentLogSearchPerPageMax,
+			},
+		},
+		"error, invalid regex": {
+			Search: DeploymentLogSearch{
+				DeploymentID: "30b3e62c-9ec2-4312-a7fa-cff24cc7397a",
+				Query:        "fail(",
+				Regex:        true,
+				Page:         1,
+				PerPage:      20,
+			},
+			Error: "Query: error parsing regexp: missing closing ): `fail(`.",
+		},
+		"error, blank query": {
+			Search: DeploymentLogSearch{
+				DeploymentID: "30b3e62c-9ec2-4312-a7fa-cff24cc7397a",
+				Page:         1,
+				PerPage:      20,
+			},
+			Error: "Query: cannot be blank.",
+		},
+		"error, per page too large": {
+			Search: DeploymentLogSearch{
+				DeploymentID: "30b3e62c-9ec2-4312-a7fa-cff24cc7397a",
+				Query:        "failed",
+				Page:         1,
+				PerPage:      DeploymentLogSearchPerPageMax + 1,
+			},
+			Error: "PerPage: must be no greater than 500.",
+		},
+		"error, deployment ID": {
+			Search: DeploymentLogSearch{
+				DeploymentID: "foo",
+				Query:        "failed",
+				Page:         1,
+				PerPage:      20,
+			},
+			Error: "DeploymentID: must be a valid UUID.",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.Search.Validate()
+			if tc.Error != "" {
+				assert.EqualError(t, err, tc.Error)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDeploymentLogSearchMatch(t *testing.T) {
+	t.Parallel()
+
+	tref := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := DeploymentLog{
+		Messages: []LogMessage{
+			{Timestamp: &tref, Level: "info", Message: "installing (1/2)"},
+			{Timestamp: &tref, Level: "error", Message: "install failed"},
+			{Timestamp: &tref, Level: "error", Message: "rollback failure"},
+		},
+	}
+	testCases := map[string]struct {
+		Search   DeploymentLogSearch
+		Expected []LogMessage
+	}{
+		"substring": {
+			Search:   DeploymentLogSearch{Query: "(1/2)"},
+			Expected: log.Messages[:1],
+		},
+		"regex": {
+			Search:   DeploymentLogSearch{Query: "fail(ed|ure)$", Regex: true},
+			Expected: log.Messages[1:],
+		},
+		"no match": {
+			Search: DeploymentLogSearch{Query: "reboot"},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.Expected, tc.Search.Match(log))
+		})
+	}
+}
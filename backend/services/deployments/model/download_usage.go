@@ -0,0 +1,77 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// DownloadUsageMonthFormat is the layout of the months download usage is
+// accounted in.
+const DownloadUsageMonthFormat = "2006-01"
+
+// DownloadUsageMonth returns the month the download at the given time is
+// accounted in.
+func DownloadUsageMonth(t time.Time) string {
+	return t.UTC().Format(DownloadUsageMonthFormat)
+}
+
+// DownloadUsage is the artifact bytes served in a month to a device for a
+// deployment; the artifacts downloaded by the users through the management
+// API are accounted with empty deployment and device IDs.
+type DownloadUsage struct {
+	Month        string `json:"month" bson:"month"`
+	DeploymentID string `json:"deployment_id,omitempty" bson:"deployment_id"`
+	DeviceID     string `json:"device_id,omitempty" bson:"device_id"`
+	// Bytes of the artifacts download links were issued for
+	Served int64 `json:"served" bson:"served"`
+	// Bytes of the artifacts the devices reported as downloaded
+	Downloaded int64 `json:"downloaded" bson:"downloaded"`
+}
+
+// DownloadUsageFilter selects the download usage to sum up.
+type DownloadUsageFilter struct {
+	Month        string `json:"month"`
+	DeploymentID string `json:"deployment_id"`
+	DeviceID     string `json:"device_id"`
+}
+
+func (f DownloadUsageFilter) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.Month, validation.Required, validation.Date(DownloadUsageMonthFormat)),
+		validation.Field(&f.DeploymentID, is.UUID),
+	)
+}
+
+// DownloadCompleted returns true if the device reports a status reached only
+// after downloading the artifact for the first time.
+func DownloadCompleted(from, to DeviceDeploymentStatus) bool {
+	if from != DeviceDeploymentStatusPending && from != DeviceDeploymentStatusDownloading {
+		return false
+	}
+	switch to {
+	case DeviceDeploymentStatusPauseBeforeInstall,
+		DeviceDeploymentStatusInstalling,
+		DeviceDeploymentStatusPauseBeforeReboot,
+		DeviceDeploymentStatusRebooting,
+		DeviceDeploymentStatusPauseBeforeCommit,
+		DeviceDeploymentStatusSuccess:
+		return true
+	}
+	return false
+}
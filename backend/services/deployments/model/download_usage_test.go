@@ -0,0 +1,94 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadUsageMonth(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 3, 31, 23, 30, 0, 0, time.FixedZone("CET", -3600))
+	assert.Equal(t, "2024-04", DownloadUsageMonth(ts))
+}
+
+func TestDownloadUsageFilterValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, DownloadUsageFilter{Month: "2024-03"}.Validate())
+	assert.NoError(t, DownloadUsageFilter{
+		Month:        "2024-03",
+		DeploymentID: "f826484e-1157-4109-af21-304e6d711560",
+		DeviceID:     "device",
+	}.Validate())
+	assert.Error(t, DownloadUsageFilter{}.Validate())
+	assert.Error(t, DownloadUsageFilter{Month: "2024-13"}.Validate())
+	assert.Error(t, DownloadUsageFilter{Month: "2024-03-01"}.Validate())
+	assert.Error(t, DownloadUsageFilter{Month: "2024-03", DeploymentID: "foo"}.Validate())
+}
+
+func TestDownloadCompleted(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		from DeviceDeploymentStatus
+		to   DeviceDeploymentStatus
+
+		completed bool
+	}{
+		"downloading to installing": {
+			from:      DeviceDeploymentStatusDownloading,
+			to:        DeviceDeploymentStatusInstalling,
+			completed: true,
+		},
+		"pending to pause before installing": {
+			from:      DeviceDeploymentStatusPending,
+			to:        DeviceDeploymentStatusPauseBeforeInstall,
+			completed: true,
+		},
+		"downloading to success": {
+			from:      DeviceDeploymentStatusDownloading,
+			to:        DeviceDeploymentStatusSuccess,
+			completed: true,
+		},
+		"pending to downloading": {
+			from: DeviceDeploymentStatusPending,
+			to:   DeviceDeploymentStatusDownloading,
+		},
+		"downloading to failure": {
+			from: DeviceDeploymentStatusDownloading,
+			to:   DeviceDeploymentStatusFailure,
+		},
+		"pending to already installed": {
+			from: DeviceDeploymentStatusPending,
+			to:   DeviceDeploymentStatusAlreadyInst,
+		},
+		"installing to rebooting": {
+			from: DeviceDeploymentStatusInstalling,
+			to:   DeviceDeploymentStatusRebooting,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.completed, DownloadCompleted(tc.from, tc.to))
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EdgeCacheAttributeScopeDefault is the scope of the device attribute
+	// selecting the cache, if not specified.
+	EdgeCacheAttributeScopeDefault = "inventory"
+
+	edgeCacheSecretSize = 32
+)
+
+var ErrEdgeCacheURLScheme = errors.New("the cache URL must use the http or https scheme")
+
+// EdgeCacheAttribute is the device attribute selecting the devices served
+// by the cache.
+type EdgeCacheAttribute struct {
+	Scope string `json:"scope" bson:"scope"`
+	Name  string `json:"name" bson:"name"`
+	Value string `json:"value" bson:"value"`
+}
+
+func (a EdgeCacheAttribute) Validate() error {
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Scope, lengthIn0To200),
+		validation.Field(&a.Name, validation.Required, lengthIn1To256),
+		validation.Field(&a.Value, validation.Required, lengthIn1To4096),
+	)
+}
+
+// EdgeCacheRequest is the request to register a cache endpoint.
+type EdgeCacheRequest struct {
+	// Human readable name of the cache
+	Name string `json:"name"`
+
+	// Base URL of the cache
+	URL string `json:"url"`
+
+	// Attribute selecting the devices served by the cache
+	Attribute EdgeCacheAttribute `json:"attribute"`
+
+	// Priority of the cache if more caches serve the device; the cache
+	// with the lowest priority is the nearest one.
+	Priority int `json:"priority"`
+}
+
+func (r EdgeCacheRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, lengthLessThan4096),
+		validation.Field(&r.URL, validation.Required, is.URL,
+			validation.By(func(interface{}) error {
+				uri, err := url.Parse(r.URL)
+				if err != nil {
+					return err
+				}
+				if uri.Scheme != "http" && uri.Scheme != "https" {
+					return ErrEdgeCacheURLScheme
+				}
+				return nil
+			}),
+		),
+		validation.Field(&r.Attribute),
+		validation.Field(&r.Priority, validation.Min(0)),
+	)
+}
+
+// EdgeCacheArtifact records when the cache last reported holding the
+// artifact.
+type EdgeCacheArtifact struct {
+	ID     string    `json:"id" bson:"id"`
+	Synced time.Time `json:"synced" bson:"synced"`
+}
+
+func (a EdgeCacheArtifact) Validate() error {
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.ID, validation.Required, is.UUID),
+	)
+}
+
+// EdgeCache is a cache endpoint serving the artifacts to the devices
+// matching its attribute; the cache verifies the download links signed
+// with its secret using the RequestSignature scheme.
+type EdgeCache struct {
+	// Cache identifier
+	ID string `json:"id" bson:"_id"`
+
+	// Human readable name of the cache
+	Name string `json:"name,omitempty" bson:"name,omitempty"`
+
+	// Base URL of the cache
+	URL string `json:"url" bson:"url"`
+
+	// Attribute selecting the devices served by the cache
+	Attribute EdgeCacheAttribute `json:"attribute" bson:"attribute"`
+
+	// Priority of the cache, lowest first
+	Priority int `json:"priority" bson:"priority"`
+
+	// Secret is the HMAC key of the download links; only returned when
+	// the cache is registered.
+	Secret string `json:"secret,omitempty" bson:"secret"`
+
+	// Artifacts held by the cache, as of its last report
+	Artifacts []EdgeCacheArtifact `json:"artifacts" bson:"artifacts"`
+
+	// Creation time
+	Created time.Time `json:"created" bson:"created"`
+
+	// Time of the last artifacts report
+	Reported *time.Time `json:"reported,omitempty" bson:"reported,omitempty"`
+}
+
+// NewEdgeCache creates a new cache endpoint with a random secret.
+func NewEdgeCache(req EdgeCacheRequest) (*EdgeCache, error) {
+	secret := make([]byte, edgeCacheSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "failed to generate the cache secret")
+	}
+	attribute := req.Attribute
+	if attribute.Scope == "" {
+		attribute.Scope = EdgeCacheAttributeScopeDefault
+	}
+	return &EdgeCache{
+		ID:        uuid.NewString(),
+		Name:      req.Name,
+		URL:       req.URL,
+		Attribute: attribute,
+		Priority:  req.Priority,
+		Secret:    base64.RawURLEncoding.EncodeToString(secret),
+		Artifacts: []EdgeCacheArtifact{},
+		Created:   time.Now(),
+	}, nil
+}
+
+// Matches returns true if the device has the attribute of the cache.
+func (c EdgeCache) Matches(attributes []DeviceAttribute) bool {
+	for _, attr := range attributes {
+		if attr.Scope != c.Attribute.Scope || attr.Name != c.Attribute.Name {
+			continue
+		}
+		switch value := attr.Value.(type) {
+		case []interface{}:
+			for _, v := range value {
+				if fmt.Sprint(v) == c.Attribute.Value {
+					return true
+				}
+			}
+		default:
+			if fmt.Sprint(value) == c.Attribute.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsFresh returns true if the cache reported holding the artifact no
+// earlier than maxAge before now.
+func (c EdgeCache) IsFresh(artifactID string, now time.Time, maxAge time.Duration) bool {
+	for _, artifact := range c.Artifacts {
+		if artifact.ID == artifactID {
+			return now.Sub(artifact.Synced) <= maxAge
+		}
+	}
+	return false
+}
+
+// DownloadLink returns the link to download the artifact from the cache,
+// signed with the secret of the cache.
+func (c EdgeCache) DownloadLink(
+	tenantID string,
+	artifactID string,
+	expire time.Time,
+) (*Link, error) {
+	uri, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cache URL")
+	}
+	uri.Path = strings.TrimSuffix(uri.Path, "/") + "/artifacts/" + artifactID
+	uri.RawPath = ""
+	q := url.Values{}
+	if tenantID != "" {
+		q.Set(ParamTenantID, tenantID)
+	}
+	uri.RawQuery = q.Encode()
+
+	sig := NewRequestSignature(&http.Request{
+		Method: http.MethodGet,
+		URL:    uri,
+	}, []byte(c.Secret))
+	sig.SetExpire(expire)
+	return &Link{
+		Uri:    sig.PresignURL(),
+		Method: http.MethodGet,
+		Expire: expire,
+	}, nil
+}
+
+// EdgeCacheReport is the list of the artifacts held by the cache; the
+// report replaces the previous one. The artifacts without the synced time
+// are considered synced at the time of the report.
+type EdgeCacheReport struct {
+	Artifacts []EdgeCacheArtifact `json:"artifacts"`
+}
+
+func (r EdgeCacheReport) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Artifacts, validation.NotNil),
+	)
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdgeCacheRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	attribute := EdgeCacheAttribute{Name: "site", Value: "plant-7"}
+	testCases := map[string]struct {
+		req EdgeCacheRequest
+		err string
+	}{
+		"ok": {
+			req: EdgeCacheRequest{
+				Name:      "plant-7",
+				URL:       "https://cache.plant-7.local:8080/mender",
+				Attribute: attribute,
+			},
+		},
+		"error, missing URL": {
+			req: EdgeCacheRequest{Attribute: attribute},
+			err: "url: cannot be blank.",
+		},
+		"error, URL scheme": {
+			req: EdgeCacheRequest{
+				URL:       "ftp://cache.plant-7.local",
+				Attribute: attribute,
+			},
+			err: "url: " + ErrEdgeCacheURLScheme.Error() + ".",
+		},
+		"error, missing attribute value": {
+			req: EdgeCacheRequest{
+				URL:       "http://cache.plant-7.local",
+				Attribute: EdgeCacheAttribute{Name: "site"},
+			},
+			err: "attribute: (value: cannot be blank.).",
+		},
+		"error, negative priority": {
+			req: EdgeCacheRequest{
+				URL:       "http://cache.plant-7.local",
+				Attribute: attribute,
+				Priority:  -1,
+			},
+			err: "priority: must be no less than 0.",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.req.Validate()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEdgeCacheReportValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, EdgeCacheReport{Artifacts: []EdgeCacheArtifact{}}.Validate())
+	assert.NoError(t, EdgeCacheReport{Artifacts: []EdgeCacheArtifact{{
+		ID: "3bd2ea4e-0b4e-4bf4-8d1d-1e5f0b3c2a10",
+	}}}.Validate())
+	assert.EqualError(t, EdgeCacheReport{}.Validate(),
+		"artifacts: is required.")
+	assert.EqualError(t, EdgeCacheReport{Artifacts: []EdgeCacheArtifact{{
+		ID: "foo",
+	}}}.Validate(), "artifacts: (0: (id: must be a valid UUID.).).")
+}
+
+func TestEdgeCacheMatches(t *testing.T) {
+	t.Parallel()
+
+	cache, err := NewEdgeCache(EdgeCacheRequest{
+		URL:       "https://cache.plant-7.local",
+		Attribute: EdgeCacheAttribute{Name: "site", Value: "plant-7"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, cache.Matches([]DeviceAttribute{{
+		Scope: "inventory", Name: "site", Value: "plant-7",
+	}}))
+	assert.True(t, cache.Matches([]DeviceAttribute{{
+		Scope: "inventory", Name: "site", Value: []interface{}{"plant-6", "plant-7"},
+	}}))
+	assert.False(t, cache.Matches([]DeviceAttribute{{
+		Scope: "identity", Name: "site", Value: "plant-7",
+	}}))
+	assert.False(t, cache.Matches([]DeviceAttribute{{
+		Scope: "inventory", Name: "site", Value: "plant-8",
+	}}))
+	assert.False(t, cache.Matches(nil))
+}
+
+func TestEdgeCacheIsFresh(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cache := EdgeCache{Artifacts: []EdgeCacheArtifact{
+		{ID: "fresh", Synced: now.Add(-time.Minute)},
+		{ID: "stale", Synced: now.Add(-2 * time.Hour)},
+	}}
+	assert.True(t, cache.IsFresh("fresh", now, time.Hour))
+	assert.False(t, cache.IsFresh("stale", now, time.Hour))
+	assert.False(t, cache.IsFresh("missing", now, time.Hour))
+}
+
+func TestEdgeCacheDownloadLink(t *testing.T) {
+	t.Parallel()
+
+	cache := EdgeCache{
+		URL:    "https://cache.plant-7.local/mender/",
+		Secret: "secret",
+	}
+	expire := time.Now().Add(time.Hour)
+	link, err := cache.DownloadLink("tenant", "artifact", expire)
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodGet, link.Method)
+	assert.Equal(t, expire, link.Expire)
+
+	uri, err := url.Parse(link.Uri)
+	require.NoError(t, err)
+	assert.Equal(t, "/mender/artifacts/artifact", uri.Path)
+	assert.Equal(t, "tenant", uri.Query().Get(ParamTenantID))
+
+	sig := NewRequestSignature(&http.Request{
+		Method: http.MethodGet,
+		URL:    uri,
+	}, []byte("secret"))
+	assert.NoError(t, sig.Validate())
+	assert.True(t, sig.VerifyHMAC256())
+
+	sig.Secret = []byte("other")
+	assert.False(t, sig.VerifyHMAC256())
+}
@@ -14,6 +14,15 @@
 
 package model
 
+import (
+	"fmt"
+	"reflect"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var validFilterPredicateTypes = []interface{}{"$eq", "$in", "$nin"}
+
 type SearchParams struct {
 	Page      int               `json:"page"`
 	PerPage   int               `json:"per_page"`
@@ -33,3 +42,64 @@ type FilterPredicate struct {
 	Type      string      `json:"type" bson:"type"`
 	Value     interface{} `json:"value" bson:"value"`
 }
+
+func (f FilterPredicate) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.Scope, validation.Required),
+		validation.Field(&f.Attribute, validation.Required),
+		validation.Field(&f.Type, validation.Required,
+			validation.In(validFilterPredicateTypes...)),
+		validation.Field(&f.Value, validation.NotNil),
+	)
+}
+
+// Matches returns true if the device attributes satisfy all the terms of
+// the filter.
+func (f Filter) Matches(attributes []DeviceAttribute) bool {
+	for _, term := range f.Terms {
+		if !term.Matches(attributes) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches evaluates the predicate on the device attributes as the
+// inventory search does: an attribute holding a list matches if any of its
+// items does, and a missing attribute matches only `$nin`.
+func (f FilterPredicate) Matches(attributes []DeviceAttribute) bool {
+	operands := []interface{}{f.Value}
+	if f.Type == "$in" || f.Type == "$nin" {
+		operands = filterValues(f.Value)
+	}
+	matched := false
+	for _, attr := range attributes {
+		if attr.Scope != f.Scope || attr.Name != f.Attribute {
+			continue
+		}
+		for _, value := range filterValues(attr.Value) {
+			for _, operand := range operands {
+				if fmt.Sprint(value) == fmt.Sprint(operand) {
+					matched = true
+				}
+			}
+		}
+	}
+	if f.Type == "$nin" {
+		return !matched
+	}
+	return matched
+}
+
+// filterValues returns the items of the list value, or the value itself.
+func filterValues(value interface{}) []interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
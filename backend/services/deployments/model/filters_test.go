@@ -0,0 +1,119 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFilterMatches(t *testing.T) {
+	t.Parallel()
+
+	attributes := []DeviceAttribute{{
+		Scope: "identity",
+		Name:  "status",
+		Value: "accepted",
+	}, {
+		Scope: "inventory",
+		Name:  "device_type",
+		Value: []interface{}{"rpi4", "rpi4-64"},
+	}, {
+		Scope: "inventory",
+		Name:  "rootfs_size",
+		Value: float64(1024),
+	}}
+
+	testCases := map[string]struct {
+		Terms []FilterPredicate
+		Match bool
+	}{
+		"ok, $eq": {
+			Terms: []FilterPredicate{{
+				Scope: "identity", Attribute: "status", Type: "$eq", Value: "accepted",
+			}},
+			Match: true,
+		},
+		"ok, $eq list attribute": {
+			Terms: []FilterPredicate{{
+				Scope: "inventory", Attribute: "device_type", Type: "$eq", Value: "rpi4",
+			}},
+			Match: true,
+		},
+		"ok, $eq number": {
+			Terms: []FilterPredicate{{
+				Scope: "inventory", Attribute: "rootfs_size", Type: "$eq", Value: 1024,
+			}},
+			Match: true,
+		},
+		"ok, $in stored": {
+			Terms: []FilterPredicate{{
+				Scope: "inventory", Attribute: "device_type", Type: "$in",
+				Value: primitive.A{"qemux86-64", "rpi4-64"},
+			}},
+			Match: true,
+		},
+		"ok, $nin missing attribute": {
+			Terms: []FilterPredicate{{
+				Scope: "inventory", Attribute: "site", Type: "$nin",
+				Value: []string{"plant-7"},
+			}},
+			Match: true,
+		},
+		"ok, all terms": {
+			Terms: []FilterPredicate{{
+				Scope: "identity", Attribute: "status", Type: "$eq", Value: "accepted",
+			}, {
+				Scope: "inventory", Attribute: "device_type", Type: "$nin",
+				Value: []interface{}{"rpi3"},
+			}},
+			Match: true,
+		},
+		"no match, $eq other scope": {
+			Terms: []FilterPredicate{{
+				Scope: "inventory", Attribute: "status", Type: "$eq", Value: "accepted",
+			}},
+		},
+		"no match, $in": {
+			Terms: []FilterPredicate{{
+				Scope: "inventory", Attribute: "device_type", Type: "$in",
+				Value: []interface{}{"rpi3", "qemux86-64"},
+			}},
+		},
+		"no match, $nin": {
+			Terms: []FilterPredicate{{
+				Scope: "inventory", Attribute: "device_type", Type: "$nin",
+				Value: []interface{}{"rpi4"},
+			}},
+		},
+		"no match, one of the terms": {
+			Terms: []FilterPredicate{{
+				Scope: "identity", Attribute: "status", Type: "$eq", Value: "accepted",
+			}, {
+				Scope: "system", Attribute: "group", Type: "$eq", Value: "factory",
+			}},
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			filter := Filter{Terms: tc.Terms}
+			assert.Equal(t, tc.Match, filter.Matches(attributes))
+		})
+	}
+}
@@ -101,6 +101,9 @@ type ArtifactMeta struct {
 	// Flag that indicates if artifact is signed or not
 	Signed bool `json:"signed" bson:"signed"`
 
+	// ID of the trusted key which verified the artifact signature
+	SigningKeyID string `json:"signing_key_id,omitempty" bson:"signing_key_id,omitempty"`
+
 	// List of updates
 	Updates []Update `json:"updates" valid:"-"`
 
@@ -192,6 +195,9 @@ type Image struct {
 
 	// Last modification time, including image upload time
 	Modified *time.Time `json:"modified" valid:"-"`
+
+	// Contents of the artifact payloads, set once the artifact is inspected
+	Contents *ArtifactContents `json:"-" bson:"contents,omitempty" valid:"-"`
 }
 
 func (img Image) MarshalBSON() (b []byte, err error) {
@@ -16,10 +16,13 @@ package model
 
 const (
 	LimitStorage = "storage"
+	// LimitDownload is the monthly quota of artifact bytes served to the
+	// devices and the users
+	LimitDownload = "download"
 )
 
 var (
-	ValidLimits = []string{LimitStorage}
+	ValidLimits = []string{LimitStorage, LimitDownload}
 )
 
 type Limit struct {
@@ -36,6 +36,10 @@ type UploadLink struct {
 	IssuedAt  time.Time  `json:"-" bson:"issued_ts"`
 	UpdatedTS time.Time  `json:"-" bson:"updated_ts"`
 	Status    LinkStatus `json:"-" bson:"status"`
+
+	// MultipartUploadID identifies the storage multipart upload of
+	// resumable uploads.
+	MultipartUploadID string `json:"-" bson:"multipart_upload_id,omitempty"`
 }
 
 type LinkStatus uint32
@@ -0,0 +1,188 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/mendersoftware/mender-artifact/artifact"
+	"github.com/pkg/errors"
+)
+
+const (
+	SigningKeyTypeRSA     = "rsa"
+	SigningKeyTypeECDSA   = "ecdsa"
+	SigningKeyTypeED25519 = "ed25519"
+)
+
+type SignaturePolicy string
+
+const (
+	// SignaturePolicyNone accepts all the artifacts; signatures are
+	// verified against the trusted keys on a best effort basis.
+	SignaturePolicyNone SignaturePolicy = "none"
+	// SignaturePolicyVerify rejects signed artifacts which cannot be
+	// verified with any of the trusted keys.
+	SignaturePolicyVerify SignaturePolicy = "verify"
+	// SignaturePolicyRequire rejects unsigned artifacts and artifacts
+	// which cannot be verified with any of the trusted keys.
+	SignaturePolicyRequire SignaturePolicy = "require"
+)
+
+var (
+	ErrSigningKeyInvalidPEM     = errors.New("failed to decode the PEM encoded public key")
+	ErrSigningKeyUnsupported    = errors.New("unsupported public key type")
+	ErrSigningKeyCurve          = errors.New("only ECDSA keys on the P-256 curve are supported")
+	ErrSignatureNotVerified     = errors.New("the signature does not match the public key")
+	ErrSignatureInvalidEncoding = errors.New("invalid signature encoding")
+)
+
+func (p SignaturePolicy) Validate() error {
+	return validation.In(
+		SignaturePolicyNone,
+		SignaturePolicyVerify,
+		SignaturePolicyRequire,
+	).Validate(p)
+}
+
+// ArtifactSignatureSettings is the per-tenant artifact signature policy.
+type ArtifactSignatureSettings struct {
+	Policy SignaturePolicy `json:"policy" bson:"policy"`
+}
+
+func (s ArtifactSignatureSettings) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.Policy, validation.Required),
+	)
+}
+
+// SigningKeyRequest is the request to add a trusted artifact signing key.
+type SigningKeyRequest struct {
+	// Human readable name of the key
+	Name string `json:"name"`
+
+	// PEM encoded public key
+	PublicKey string `json:"public_key"`
+}
+
+func (r SigningKeyRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, lengthLessThan4096),
+		validation.Field(&r.PublicKey, validation.Required,
+			validation.By(func(interface{}) error {
+				_, _, err := parsePublicKey(r.PublicKey)
+				return err
+			}),
+		),
+	)
+}
+
+// SigningKey is a public key trusted for verifying artifact signatures.
+type SigningKey struct {
+	// Key identifier
+	ID string `json:"id" bson:"_id"`
+
+	// Human readable name of the key
+	Name string `json:"name,omitempty" bson:"name,omitempty"`
+
+	// Key type: rsa, ecdsa or ed25519
+	Type string `json:"type" bson:"type"`
+
+	// PEM encoded public key
+	PublicKey string `json:"public_key" bson:"public_key"`
+
+	// Creation time
+	Created time.Time `json:"created" bson:"created"`
+}
+
+// NewSigningKey creates a new signing key from the request.
+func NewSigningKey(req SigningKeyRequest) (*SigningKey, error) {
+	_, keyType, err := parsePublicKey(req.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{
+		ID:        uuid.NewString(),
+		Name:      req.Name,
+		Type:      keyType,
+		PublicKey: req.PublicKey,
+		Created:   time.Now(),
+	}, nil
+}
+
+// Verify checks the base64 encoded signature of the message.
+func (k SigningKey) Verify(message, sig []byte) error {
+	key, _, err := parsePublicKey(k.PublicKey)
+	if err != nil {
+		return err
+	}
+	dec := make([]byte, base64.StdEncoding.DecodedLen(len(sig)))
+	n, err := base64.StdEncoding.Decode(dec, sig)
+	if err != nil {
+		return ErrSignatureInvalidEncoding
+	}
+	dec = dec[:n]
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		err = new(artifact.RSA).Verify(message, dec, pub)
+	case *ecdsa.PublicKey:
+		err = new(artifact.ECDSA256).Verify(message, dec, pub)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, message, dec) {
+			return ErrSignatureNotVerified
+		}
+	default:
+		err = ErrSigningKeyUnsupported
+	}
+	if err != nil {
+		return errors.Wrap(ErrSignatureNotVerified, err.Error())
+	}
+	return nil
+}
+
+func parsePublicKey(keyPEM string) (interface{}, string, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, "", ErrSigningKeyInvalidPEM
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to parse the public key")
+	}
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		return key, SigningKeyTypeRSA, nil
+	case *ecdsa.PublicKey:
+		// mender-artifact signs with ECDSA on the P-256 curve only
+		if pub.Curve != elliptic.P256() {
+			return nil, "", ErrSigningKeyCurve
+		}
+		return key, SigningKeyTypeECDSA, nil
+	case ed25519.PublicKey:
+		return key, SigningKeyTypeED25519, nil
+	default:
+		return nil, "", ErrSigningKeyUnsupported
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/mendersoftware/mender-artifact/artifact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePublicKey(t *testing.T, pub crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestSigningKeyVerify(t *testing.T) {
+	t.Parallel()
+
+	message := []byte("manifest")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSig, err := new(artifact.RSA).Sign(message, rsaKey)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecSig, err := new(artifact.ECDSA256).Sign(message, ecKey)
+	require.NoError(t, err)
+
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edSig := ed25519.Sign(edKey, message)
+
+	testCases := map[string]struct {
+		PublicKey crypto.PublicKey
+		Type      string
+		Signature []byte
+
+		Error error
+	}{
+		"ok, rsa": {
+			PublicKey: rsaKey.Public(),
+			Type:      SigningKeyTypeRSA,
+			Signature: rsaSig,
+		},
+		"ok, ecdsa": {
+			PublicKey: ecKey.Public(),
+			Type:      SigningKeyTypeECDSA,
+			Signature: ecSig,
+		},
+		"ok, ed25519": {
+			PublicKey: edPub,
+			Type:      SigningKeyTypeED25519,
+			Signature: edSig,
+		},
+		"error, wrong key": {
+			PublicKey: rsaKey.Public(),
+			Type:      SigningKeyTypeRSA,
+			Signature: ecSig,
+			Error:     ErrSignatureNotVerified,
+		},
+		"error, wrong ed25519 signature": {
+			PublicKey: edPub,
+			Type:      SigningKeyTypeED25519,
+			Signature: ecSig,
+			Error:     ErrSignatureNotVerified,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			key, err := NewSigningKey(SigningKeyRequest{
+				Name:      name,
+				PublicKey: encodePublicKey(t, tc.PublicKey),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.Type, key.Type)
+
+			sig := []byte(base64.StdEncoding.EncodeToString(tc.Signature))
+			err = key.Verify(message, sig)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSigningKeyRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		Request SigningKeyRequest
+		Error   string
+	}{
+		"ok": {
+			Request: SigningKeyRequest{
+				Name:      "release key",
+				PublicKey: encodePublicKey(t, pub),
+			},
+		},
+		"error, missing key": {
+			Request: SigningKeyRequest{Name: "release key"},
+			Error:   "public_key: cannot be blank.",
+		},
+		"error, not PEM": {
+			Request: SigningKeyRequest{PublicKey: "foo"},
+			Error:   ErrSigningKeyInvalidPEM.Error(),
+		},
+		"error, not a public key": {
+			Request: SigningKeyRequest{
+				PublicKey: string(pem.EncodeToMemory(&pem.Block{
+					Type:  "PUBLIC KEY",
+					Bytes: []byte("foo"),
+				})),
+			},
+			Error: "failed to parse the public key",
+		},
+		"error, ecdsa curve": {
+			Request: SigningKeyRequest{
+				PublicKey: encodePublicKey(t, p384.Public()),
+			},
+			Error: ErrSigningKeyCurve.Error(),
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.Request.Validate()
+			if tc.Error != "" {
+				assert.ErrorContains(t, err, tc.Error)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestArtifactSignatureSettingsValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ArtifactSignatureSettings{Policy: SignaturePolicyRequire}.Validate())
+	assert.Error(t, ArtifactSignatureSettings{}.Validate())
+	assert.Error(t, ArtifactSignatureSettings{Policy: "foo"}.Validate())
+}
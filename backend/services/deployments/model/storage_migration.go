@@ -0,0 +1,116 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StorageMigrationModeMirror copies the artifacts to the target
+	// storage, leaving the storage settings of the tenant unchanged.
+	StorageMigrationModeMirror = "mirror"
+	// StorageMigrationModeMigrate copies the artifacts to the target
+	// storage and switches the storage settings of the tenant to the
+	// target once all the artifacts are copied.
+	StorageMigrationModeMigrate = "migrate"
+
+	StorageMigrationStatusPending = "pending"
+	StorageMigrationStatusRunning = "running"
+	// StorageMigrationStatusSwitching is the final pass of a migration in
+	// migrate mode: the uploads of the tenant are rejected until the
+	// storage settings are switched over to the target.
+	StorageMigrationStatusSwitching = "switching"
+	StorageMigrationStatusCompleted = "completed"
+	StorageMigrationStatusFailed    = "failed"
+)
+
+var ErrStorageMigrationTargetMissing = errors.New("target storage settings are required")
+
+// StorageMigration is the copy of the artifacts of a tenant to another
+// storage; the artifacts are copied in the order of their IDs so that an
+// interrupted migration is resumed after the last copied artifact.
+type StorageMigration struct {
+	ID     string           `json:"id" bson:"_id"`
+	Mode   string           `json:"mode" bson:"mode"`
+	Target *StorageSettings `json:"target" bson:"target"`
+	Status string           `json:"status" bson:"status"`
+
+	// Artifacts is the number of artifacts to copy, known once the
+	// migration is running.
+	Artifacts int `json:"artifacts" bson:"artifacts"`
+	// Copied is the number of artifacts copied and verified.
+	Copied int `json:"copied" bson:"copied"`
+	// Size is the total size in bytes of the artifacts to copy.
+	Size int64 `json:"size" bson:"size"`
+	// CopiedSize is the size in bytes of the artifacts copied.
+	CopiedSize int64 `json:"copied_size" bson:"copied_size"`
+	// LastArtifactID is the ID of the last artifact copied.
+	LastArtifactID string `json:"-" bson:"last_artifact_id,omitempty"`
+
+	Error    string     `json:"error,omitempty" bson:"error,omitempty"`
+	Created  *time.Time `json:"created" bson:"created"`
+	Updated  *time.Time `json:"updated" bson:"updated"`
+	Finished *time.Time `json:"finished,omitempty" bson:"finished,omitempty"`
+}
+
+// StorageMigrationRequest is the request to copy the artifacts of the tenant
+// to the target storage.
+type StorageMigrationRequest struct {
+	Mode   string           `json:"mode"`
+	Target *StorageSettings `json:"-"`
+}
+
+func (r StorageMigrationRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Mode, validation.Required, validation.In(
+			StorageMigrationModeMirror,
+			StorageMigrationModeMigrate,
+		)),
+	)
+}
+
+// ParseStorageMigrationRequest parses the migration request; the target
+// storage settings follow the schema of the tenant storage settings.
+func ParseStorageMigrationRequest(source io.Reader) (*StorageMigrationRequest, error) {
+	var schema struct {
+		Mode   string          `json:"mode"`
+		Target json.RawMessage `json:"target"`
+	}
+	if err := json.NewDecoder(source).Decode(&schema); err != nil {
+		return nil, err
+	}
+	req := &StorageMigrationRequest{Mode: schema.Mode}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if len(schema.Target) == 0 {
+		return nil, ErrStorageMigrationTargetMissing
+	}
+	target, err := ParseStorageSettingsRequest(bytes.NewReader(schema.Target))
+	if err != nil {
+		return nil, errors.WithMessage(err, "target")
+	} else if target == nil {
+		return nil, ErrStorageMigrationTargetMissing
+	}
+	req.Target = target
+	return req, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStorageMigrationRequest(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Raw string
+
+		Expected *StorageMigrationRequest
+		Error    string
+	}{
+		"ok, migrate to azure": {
+			Raw: `{"mode": "migrate", "target": {
+				"type": "azure",
+				"account_name": "AccountName",
+				"account_key": "AccountKey",
+				"container_name": "containerMcBucketFace"
+			}}`,
+			Expected: &StorageMigrationRequest{
+				Mode: StorageMigrationModeMigrate,
+				Target: &StorageSettings{
+					Type:   StorageTypeAzure,
+					Bucket: "containerMcBucketFace",
+					Key:    "AccountName",
+					Secret: "AccountKey",
+				},
+			},
+		},
+		"ok, mirror to s3": {
+			Raw: `{"mode": "mirror", "target": {
+				"type": "s3",
+				"key": "not_so_secret_key_id",
+				"secret": "super_secret",
+				"bucket": "bucketMcBucketFace",
+				"region": "wrld-east-west-1"
+			}}`,
+			Expected: &StorageMigrationRequest{
+				Mode: StorageMigrationModeMirror,
+				Target: &StorageSettings{
+					Type:   StorageTypeS3,
+					Bucket: "bucketMcBucketFace",
+					Key:    "not_so_secret_key_id",
+					Secret: "super_secret",
+					Region: "wrld-east-west-1",
+				},
+			},
+		},
+		"error, malformed": {
+			Raw:   `{"mode": `,
+			Error: "unexpected EOF",
+		},
+		"error, invalid mode": {
+			Raw:   `{"mode": "move", "target": {}}`,
+			Error: "mode: must be a valid value.",
+		},
+		"error, missing target": {
+			Raw:   `{"mode": "mirror"}`,
+			Error: ErrStorageMigrationTargetMissing.Error(),
+		},
+		"error, empty target": {
+			Raw:   `{"mode": "mirror", "target": {}}`,
+			Error: ErrStorageMigrationTargetMissing.Error(),
+		},
+		"error, invalid target": {
+			Raw: `{"mode": "mirror", "target": {"type": "s3", "bucket": "bucket"}}`,
+			Error: "target: invalid settings schema: key: cannot be blank; " +
+				"region: cannot be blank; secret: cannot be blank.",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			req, err := ParseStorageMigrationRequest(strings.NewReader(tc.Raw))
+			if tc.Error != "" {
+				assert.EqualError(t, err, tc.Error)
+				assert.Nil(t, req)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.Expected, req)
+			}
+		})
+	}
+}
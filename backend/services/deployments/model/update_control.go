@@ -0,0 +1,166 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"slices"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
+
+// Update control map states in which the device may pause the update
+const (
+	UpdateControlStateArtifactInstall = "ArtifactInstall_Enter"
+	UpdateControlStateArtifactReboot  = "ArtifactReboot_Enter"
+	UpdateControlStateArtifactCommit  = "ArtifactCommit_Enter"
+)
+
+var ErrInvalidUpdateControlState = errors.New("Invalid update control map state")
+
+// UpdateControlAction is the action the device takes when entering a state.
+type UpdateControlAction string
+
+const (
+	UpdateControlActionContinue      UpdateControlAction = "continue"
+	UpdateControlActionForceContinue UpdateControlAction = "force_continue"
+	UpdateControlActionPause         UpdateControlAction = "pause"
+	UpdateControlActionFail          UpdateControlAction = "fail"
+)
+
+// UpdateControlMapState controls the device when entering the state.
+type UpdateControlMapState struct {
+	// Action taken when entering the state: pause, continue or fail
+	Action UpdateControlAction `json:"action" bson:"action"`
+
+	// Action taken by the device if the map expires before the device is
+	// continued
+	OnMapExpire UpdateControlAction `json:"on_map_expire,omitempty" bson:"on_map_expire,omitempty"`
+
+	// Time after which the server replaces the action with OnMapExpire,
+	// or fails the paused devices if OnMapExpire is not set
+	Expire *time.Time `json:"expire,omitempty" bson:"expire,omitempty"`
+}
+
+func (s UpdateControlMapState) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.Action, validation.Required, validation.In(
+			UpdateControlActionContinue,
+			UpdateControlActionPause,
+			UpdateControlActionFail,
+		)),
+		validation.Field(&s.OnMapExpire, validation.In(
+			UpdateControlActionContinue,
+			UpdateControlActionForceContinue,
+			UpdateControlActionFail,
+		)),
+	)
+}
+
+// UpdateControlMap pauses the devices at the given states of the update
+// until the devices are continued from the management API.
+type UpdateControlMap struct {
+	// ID of the map, set to the deployment ID when served to the devices
+	ID string `json:"id,omitempty" bson:"-"`
+
+	// Priority of the map among the maps known to the device
+	Priority int `json:"priority,omitempty" bson:"priority,omitempty"`
+
+	States map[string]UpdateControlMapState `json:"states" bson:"states"`
+}
+
+func (m UpdateControlMap) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Priority, validation.Min(-10), validation.Max(10)),
+		validation.Field(&m.States, validation.Required,
+			validation.By(func(interface{}) error {
+				for state := range m.States {
+					if !slices.Contains(updateControlStates, state) {
+						return errors.Wrap(ErrInvalidUpdateControlState, state)
+					}
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+var updateControlStates = []string{
+	UpdateControlStateArtifactInstall,
+	UpdateControlStateArtifactReboot,
+	UpdateControlStateArtifactCommit,
+}
+
+// UpdateControlStatePaused returns the state in which the device paused the
+// update, or an empty string if the device is not paused.
+func UpdateControlStatePaused(status DeviceDeploymentStatus) string {
+	switch status {
+	case DeviceDeploymentStatusPauseBeforeInstall:
+		return UpdateControlStateArtifactInstall
+	case DeviceDeploymentStatusPauseBeforeReboot:
+		return UpdateControlStateArtifactReboot
+	case DeviceDeploymentStatusPauseBeforeCommit:
+		return UpdateControlStateArtifactCommit
+	}
+	return ""
+}
+
+// ForDevice returns the map served to the device: the states in which the
+// device was continued become "continue", and the expired states take the
+// on_map_expire action.
+func (m UpdateControlMap) ForDevice(
+	deploymentID string,
+	continued []string,
+	now time.Time,
+) *UpdateControlMap {
+	deviceMap := &UpdateControlMap{
+		ID:       deploymentID,
+		Priority: m.Priority,
+		States:   make(map[string]UpdateControlMapState, len(m.States)),
+	}
+	for name, state := range m.States {
+		switch {
+		case slices.Contains(continued, name):
+			state = UpdateControlMapState{Action: UpdateControlActionContinue}
+		case state.Expire != nil && !now.Before(*state.Expire):
+			action := state.OnMapExpire
+			if action == "" {
+				action = UpdateControlActionFail
+			}
+			state = UpdateControlMapState{Action: action}
+		}
+		state.Expire = nil
+		deviceMap.States[name] = state
+	}
+	return deviceMap
+}
+
+// ContinueDeviceDeployments selects the paused devices of a deployment to
+// continue; all the paused devices are continued if the list is empty.
+type ContinueDeviceDeployments struct {
+	Devices []string `json:"devices,omitempty"`
+}
+
+func (c ContinueDeviceDeployments) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Devices, validation.Each(validation.Required)),
+	)
+}
+
+// ContinuedDeviceDeployments is the number of devices continued.
+type ContinuedDeviceDeployments struct {
+	Count int `json:"count"`
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateControlMapValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Map   UpdateControlMap
+		Error bool
+	}{
+		"ok": {
+			Map: UpdateControlMap{
+				Priority: 5,
+				States: map[string]UpdateControlMapState{
+					UpdateControlStateArtifactReboot: {
+						Action:      UpdateControlActionPause,
+						OnMapExpire: UpdateControlActionForceContinue,
+					},
+				},
+			},
+		},
+		"error, no states": {
+			Map:   UpdateControlMap{},
+			Error: true,
+		},
+		"error, priority": {
+			Map: UpdateControlMap{
+				Priority: 11,
+				States: map[string]UpdateControlMapState{
+					UpdateControlStateArtifactReboot: {
+						Action: UpdateControlActionPause,
+					},
+				},
+			},
+			Error: true,
+		},
+		"error, unknown state": {
+			Map: UpdateControlMap{
+				States: map[string]UpdateControlMapState{
+					"Download_Enter": {
+						Action: UpdateControlActionPause,
+					},
+				},
+			},
+			Error: true,
+		},
+		"error, invalid action": {
+			Map: UpdateControlMap{
+				States: map[string]UpdateControlMapState{
+					UpdateControlStateArtifactInstall: {
+						Action: UpdateControlActionForceContinue,
+					},
+				},
+			},
+			Error: true,
+		},
+		"error, invalid on_map_expire": {
+			Map: UpdateControlMap{
+				States: map[string]UpdateControlMapState{
+					UpdateControlStateArtifactInstall: {
+						Action:      UpdateControlActionPause,
+						OnMapExpire: UpdateControlActionPause,
+					},
+				},
+			},
+			Error: true,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.Map.Validate()
+			if tc.Error {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUpdateControlMapForDevice(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+	m := UpdateControlMap{
+		Priority: 2,
+		States: map[string]UpdateControlMapState{
+			UpdateControlStateArtifactInstall: {
+				Action: UpdateControlActionPause,
+				Expire: &future,
+			},
+			UpdateControlStateArtifactReboot: {
+				Action: UpdateControlActionPause,
+				Expire: &past,
+			},
+			UpdateControlStateArtifactCommit: {
+				Action:      UpdateControlActionPause,
+				OnMapExpire: UpdateControlActionContinue,
+				Expire:      &past,
+			},
+		},
+	}
+
+	assert.Equal(t, &UpdateControlMap{
+		ID:       "deployment",
+		Priority: 2,
+		States: map[string]UpdateControlMapState{
+			UpdateControlStateArtifactInstall: {
+				Action: UpdateControlActionPause,
+			},
+			UpdateControlStateArtifactReboot: {
+				Action: UpdateControlActionFail,
+			},
+			UpdateControlStateArtifactCommit: {
+				Action: UpdateControlActionContinue,
+			},
+		},
+	}, m.ForDevice("deployment", nil, now))
+
+	served := m.ForDevice("deployment",
+		[]string{UpdateControlStateArtifactInstall}, now)
+	assert.Equal(t, UpdateControlActionContinue,
+		served.States[UpdateControlStateArtifactInstall].Action)
+	// the stored map is left untouched
+	assert.Equal(t, &future, m.States[UpdateControlStateArtifactInstall].Expire)
+}
+
+func TestUpdateControlStatePaused(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, UpdateControlStateArtifactInstall,
+		UpdateControlStatePaused(DeviceDeploymentStatusPauseBeforeInstall))
+	assert.Equal(t, UpdateControlStateArtifactReboot,
+		UpdateControlStatePaused(DeviceDeploymentStatusPauseBeforeReboot))
+	assert.Equal(t, UpdateControlStateArtifactCommit,
+		UpdateControlStatePaused(DeviceDeploymentStatusPauseBeforeCommit))
+	assert.Empty(t, UpdateControlStatePaused(DeviceDeploymentStatusInstalling))
+}
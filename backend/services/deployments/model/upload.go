@@ -0,0 +1,47 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+const (
+	// MultipartUploadMaxParts is the maximum number of parts of a
+	// resumable upload.
+	MultipartUploadMaxParts = 10000
+	// MultipartUploadPartSizeMin is the minimum size of the parts of a
+	// resumable upload, except for the last part.
+	MultipartUploadPartSizeMin = 5 * 1024 * 1024
+	// MultipartUploadPartSizeMax is the maximum size of the parts of a
+	// resumable upload.
+	MultipartUploadPartSizeMax = 32 * 1024 * 1024
+)
+
+// MultipartUpload describes a resumable artifact upload.
+type MultipartUpload struct {
+	ID          string    `json:"id"`
+	Expire      time.Time `json:"expire"`
+	PartSizeMin int64     `json:"part_size_min"`
+	PartSizeMax int64     `json:"part_size_max"`
+	MaxParts    int       `json:"max_parts"`
+}
+
+// UploadPart describes an uploaded part of a resumable upload.
+type UploadPart struct {
+	PartNumber int    `json:"part_number"`
+	Size       int64  `json:"size"`
+	ETag       string `json:"etag,omitempty"`
+}
@@ -36,6 +36,7 @@ import (
 	dconfig "github.com/mendersoftware/mender-server/services/deployments/config"
 	"github.com/mendersoftware/mender-server/services/deployments/storage"
 	"github.com/mendersoftware/mender-server/services/deployments/storage/azblob"
+	"github.com/mendersoftware/mender-server/services/deployments/storage/filesystem"
 	"github.com/mendersoftware/mender-server/services/deployments/storage/manager"
 	"github.com/mendersoftware/mender-server/services/deployments/storage/s3"
 	mstore "github.com/mendersoftware/mender-server/services/deployments/store/mongo"
@@ -132,6 +133,55 @@ func SetupBlobStorage(
 	return azblob.New(ctx, c.GetString(dconfig.SettingStorageBucket), options)
 }
 
+// presignSecret decodes the base64 secret in either std or URL encoding
+// ignoring padding.
+func presignSecret(c config.Reader) ([]byte, error) {
+	base64Repl := strings.NewReplacer("-", "+", "_", "/", "=", "")
+	return base64.RawStdEncoding.DecodeString(
+		base64Repl.Replace(
+			c.GetString(dconfig.SettingPresignSecret),
+		),
+	)
+}
+
+func SetupFilesystem(ctx context.Context) (storage.ObjectStorage, error) {
+	c := config.Config
+
+	secret, err := presignSecret(c)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid setting %q", dconfig.SettingPresignSecret)
+	}
+	options := filesystem.NewOptions().SetSecret(secret)
+
+	var externalURI *url.URL
+	if c.IsSet(dconfig.SettingFilesystemExternalURI) {
+		externalURI, err = url.Parse(c.GetString(dconfig.SettingFilesystemExternalURI))
+		if err != nil {
+			return nil, errors.WithMessagef(err,
+				"invalid setting %q", dconfig.SettingFilesystemExternalURI)
+		}
+	} else if hostname := c.GetString(dconfig.SettingPresignHost); hostname != "" {
+		externalURI = &url.URL{
+			Scheme: c.GetString(dconfig.SettingPresignScheme),
+			Host:   hostname,
+			Path:   api.ApiUrlDevices + api.ApiUrlDevicesStorage,
+		}
+	} else {
+		return nil, errors.Errorf("filesystem storage requires setting %q or %q",
+			dconfig.SettingFilesystemExternalURI, dconfig.SettingPresignHost)
+	}
+	options.SetExternalURI(externalURI)
+	if c.IsSet(dconfig.SettingFilesystemURI) {
+		uri, err := url.Parse(c.GetString(dconfig.SettingFilesystemURI))
+		if err != nil {
+			return nil, errors.WithMessagef(err,
+				"invalid setting %q", dconfig.SettingFilesystemURI)
+		}
+		options.SetURI(uri)
+	}
+	return filesystem.New(ctx, c.GetString(dconfig.SettingFilesystemPath), options)
+}
+
 func SetupObjectStorage(ctx context.Context) (objManager storage.ObjectStorage, err error) {
 	c := config.Config
 
@@ -154,10 +204,13 @@ func SetupObjectStorage(ctx context.Context) (objManager storage.ObjectStorage,
 		defaultStorage, err = SetupS3(ctx, s3Options)
 	case dconfig.StorageTypeAzure:
 		defaultStorage, err = SetupBlobStorage(ctx, azOptions)
+	case dconfig.StorageTypeFilesystem:
+		defaultStorage, err = SetupFilesystem(ctx)
 	default:
 		err = errors.Errorf(
-			`storage type must be one of %q or %q, received value %q`,
-			dconfig.StorageTypeAWS, dconfig.StorageTypeAzure, defType,
+			`storage type must be one of %q, %q or %q, received value %q`,
+			dconfig.StorageTypeAWS, dconfig.StorageTypeAzure,
+			dconfig.StorageTypeFilesystem, defType,
 		)
 	}
 	if err != nil {
@@ -185,14 +238,17 @@ func RunServer(ctx context.Context) error {
 		return errors.WithMessage(err, "main: failed to setup storage client")
 	}
 
-	app := app.NewDeployments(ds, objStore, 0, false)
+	app := app.NewDeployments(ds, objStore, 0, false).
+		WithMaxImageSize(c.GetInt64(dconfig.SettingStorageMaxImageSize))
 	if addr := c.GetString(dconfig.SettingReportingAddr); addr != "" {
 		c := reporting.NewClient(addr)
 		app = app.WithReporting(c)
 	}
+	if days := c.GetInt(dconfig.SettingDeploymentLogsExpireDays); days > 0 {
+		app = app.WithDeploymentLogTTL(time.Duration(days) * 24 * time.Hour)
+	}
 
 	// Setup API Router configuration
-	base64Repl := strings.NewReplacer("-", "+", "_", "/", "=", "")
 	expireSec := c.GetDuration(dconfig.SettingPresignExpireSeconds)
 	apiConf := api.NewConfig().
 		SetPresignExpire(time.Second * expireSec).
@@ -204,13 +260,16 @@ func RunServer(ctx context.Context) error {
 		SetEnableDirectUploadSkipVerify(c.GetBool(dconfig.SettingStorageDirectUploadSkipVerify)).
 		SetDisableNewReleasesFeature(c.GetBool(dconfig.SettingDisableNewReleasesFeature)).
 		SetMaxRequestSize(c.GetInt64(dconfig.SettingMaxRequestSize))
-	if key, err := base64.RawStdEncoding.DecodeString(
-		base64Repl.Replace(
-			c.GetString(dconfig.SettingPresignSecret),
-		),
-	); err == nil {
+	if key, err := presignSecret(c); err == nil {
 		apiConf.SetPresignSecret(key)
 	}
+	if c.GetString(dconfig.SettingDefaultStorage) == dconfig.StorageTypeFilesystem {
+		localStore, err := SetupFilesystem(ctx)
+		if err != nil {
+			return errors.WithMessage(err, "main: failed to setup storage client")
+		}
+		apiConf.SetLocalStorage(localStore)
+	}
 	handler := api.NewRouter(ctx, app, ds, apiConf)
 
 	listen := c.GetString(dconfig.SettingListen)
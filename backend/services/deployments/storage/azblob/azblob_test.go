@@ -463,3 +463,27 @@ func TestGetObject(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockID(t *testing.T) {
+	t.Parallel()
+
+	uploadID := uuid.NewString()
+	ids := map[string]struct{}{}
+	for _, n := range []int{1, 2, 10, model.MultipartUploadMaxParts} {
+		id := blockID(uploadID, n)
+		// all the block IDs of a blob must have the same length
+		assert.Len(t, id, len(blockID(uploadID, 1)))
+		ids[id] = struct{}{}
+
+		actual, ok := partNumber(uploadID, id)
+		assert.True(t, ok)
+		assert.Equal(t, n, actual)
+
+		_, ok = partNumber(uuid.NewString(), id)
+		assert.False(t, ok, "block of another upload")
+	}
+	assert.Len(t, ids, 4)
+
+	_, ok := partNumber(uploadID, "not base64!")
+	assert.False(t, ok)
+}
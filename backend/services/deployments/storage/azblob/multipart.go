@@ -0,0 +1,216 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package azblob
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/google/uuid"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+// Multipart uploads are implemented with block lists: the parts are staged
+// as uncommitted blocks of the blob and committed on completion. The block
+// IDs are derived from the upload ID and the part number. Azure discards
+// the uncommitted blocks which are not committed within a week, so aborting
+// an upload does not require any request.
+
+// blockID returns the base64 encoded block ID of the part; all the block
+// IDs of a blob must have the same length.
+func blockID(uploadID string, partNumber int) string {
+	return base64.StdEncoding.EncodeToString(
+		[]byte(fmt.Sprintf("%s-%05d", uploadID, partNumber)),
+	)
+}
+
+// partNumber returns the part number of the block if the block belongs to
+// the upload.
+func partNumber(uploadID string, blockID string) (int, bool) {
+	b, err := base64.StdEncoding.DecodeString(blockID)
+	if err != nil {
+		return 0, false
+	}
+	num, found := strings.CutPrefix(string(b), uploadID+"-")
+	if !found {
+		return 0, false
+	}
+	n, err := strconv.Atoi(num)
+	return n, err == nil
+}
+
+func (c *client) CreateMultipartUpload(
+	ctx context.Context,
+	path string,
+) (string, error) {
+	if _, err := c.clientFromContext(ctx); err != nil {
+		return "", OpError{
+			Op:     OpCreateMultipartUpload,
+			Reason: err,
+		}
+	}
+	return uuid.NewString(), nil
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
+func (c *client) UploadPart(
+	ctx context.Context,
+	path string,
+	uploadID string,
+	partNumber int,
+	src io.ReadSeeker,
+) (*model.UploadPart, error) {
+	azClient, err := c.clientFromContext(ctx)
+	if err != nil {
+		return nil, OpError{
+			Op:     OpUploadPart,
+			Reason: err,
+		}
+	}
+	size, err := src.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = src.Seek(0, io.SeekStart)
+	}
+	if err == nil {
+		bc := azClient.NewBlockBlobClient(path)
+		_, err = bc.StageBlock(ctx,
+			blockID(uploadID, partNumber), nopSeekCloser{src}, nil,
+		)
+	}
+	if err != nil {
+		return nil, OpError{
+			Op:      OpUploadPart,
+			Message: "failed to stage block",
+			Reason:  err,
+		}
+	}
+	return &model.UploadPart{
+		PartNumber: partNumber,
+		Size:       size,
+	}, nil
+}
+
+func (c *client) ListParts(
+	ctx context.Context,
+	path string,
+	uploadID string,
+) ([]model.UploadPart, error) {
+	azClient, err := c.clientFromContext(ctx)
+	if err != nil {
+		return nil, OpError{
+			Op:     OpListParts,
+			Reason: err,
+		}
+	}
+	parts := []model.UploadPart{}
+	bc := azClient.NewBlockBlobClient(path)
+	rsp, err := bc.GetBlockList(ctx, blockblob.BlockListTypeUncommitted, nil)
+	if bloberror.HasCode(err,
+		bloberror.BlobNotFound,
+		bloberror.ResourceNotFound) {
+		// no block staged yet
+		return parts, nil
+	} else if err != nil {
+		return nil, OpError{
+			Op:      OpListParts,
+			Message: "failed to retrieve block list",
+			Reason:  err,
+		}
+	}
+	for _, block := range rsp.UncommittedBlocks {
+		if block == nil || block.Name == nil {
+			continue
+		}
+		n, ok := partNumber(uploadID, *block.Name)
+		if !ok {
+			continue
+		}
+		var size int64
+		if block.Size != nil {
+			size = *block.Size
+		}
+		parts = append(parts, model.UploadPart{
+			PartNumber: n,
+			Size:       size,
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	return parts, nil
+}
+
+func (c *client) CompleteMultipartUpload(
+	ctx context.Context,
+	path string,
+	uploadID string,
+	parts []model.UploadPart,
+) error {
+	azClient, err := c.clientFromContext(ctx)
+	if err != nil {
+		return OpError{
+			Op:     OpCompleteMultipartUpload,
+			Reason: err,
+		}
+	}
+	blockIDs := make([]string, len(parts))
+	for i, part := range parts {
+		blockIDs[i] = blockID(uploadID, part.PartNumber)
+	}
+	bc := azClient.NewBlockBlobClient(path)
+	_, err = bc.CommitBlockList(ctx, blockIDs, &blockblob.CommitBlockListOptions{
+		HTTPHeaders: &blob.HTTPHeaders{
+			BlobContentType: c.contentType,
+		},
+	})
+	if err != nil {
+		return OpError{
+			Op:      OpCompleteMultipartUpload,
+			Message: "failed to commit block list",
+			Reason:  err,
+		}
+	}
+	return nil
+}
+
+func (c *client) AbortMultipartUpload(
+	ctx context.Context,
+	path string,
+	uploadID string,
+) error {
+	if _, err := c.clientFromContext(ctx); err != nil {
+		return OpError{
+			Op:     OpAbortMultipartUpload,
+			Reason: err,
+		}
+	}
+	return nil
+}
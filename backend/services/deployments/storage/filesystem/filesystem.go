@@ -0,0 +1,359 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+// Package filesystem implements the object storage on the local file system.
+// The signed requests point to the deployments service, which verifies the
+// signature and serves the objects from the storage.
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+)
+
+const (
+	// ParamFilename is the query parameter of the signed GET requests
+	// holding the name of the downloaded file.
+	ParamFilename = "filename"
+
+	tempFilePattern = ".upload-*"
+
+	dirMode = 0o750
+)
+
+type client struct {
+	root        string
+	externalURI *url.URL
+	uri         *url.URL
+	secret      []byte
+}
+
+// New initializes the object storage storing the objects under the root
+// directory.
+func New(ctx context.Context, root string, opts ...*Options) (storage.ObjectStorage, error) {
+	opt := NewOptions(opts...)
+	if err := opt.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, dirMode); err != nil {
+		return nil, err
+	}
+	objStore := &client{
+		root:        root,
+		externalURI: opt.ExternalURI,
+		uri:         opt.URI,
+		secret:      opt.Secret,
+	}
+	if objStore.uri == nil {
+		objStore.uri = objStore.externalURI
+	}
+	if err := objStore.HealthCheck(ctx); err != nil {
+		return nil, err
+	}
+	return objStore, nil
+}
+
+// cleanPath returns the object path relative to the storage root; the path
+// can not escape the root directory.
+func cleanPath(objectPath string) (string, error) {
+	p := strings.TrimPrefix(path.Clean("/"+objectPath), "/")
+	if p == "" {
+		return "", ErrInvalidPath
+	}
+	return p, nil
+}
+
+func (c *client) filePath(objectPath string) (string, error) {
+	p, err := cleanPath(objectPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(c.root, filepath.FromSlash(p)), nil
+}
+
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return storage.ErrObjectNotFound
+	}
+	return err
+}
+
+func (c *client) HealthCheck(ctx context.Context) error {
+	info, err := os.Stat(c.root)
+	if err == nil && !info.IsDir() {
+		err = errors.New("storage root is not a directory")
+	}
+	if err != nil {
+		return OpError{
+			Op:     OpHealthCheck,
+			Reason: err,
+		}
+	}
+	return nil
+}
+
+type objectReader struct {
+	*os.File
+	length int64
+}
+
+func (r objectReader) Length() int64 {
+	return r.length
+}
+
+func (c *client) GetObject(
+	ctx context.Context,
+	objectPath string,
+) (io.ReadCloser, error) {
+	filePath, err := c.filePath(objectPath)
+	if err != nil {
+		return nil, OpError{
+			Op:     OpGetObject,
+			Reason: err,
+		}
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, OpError{
+			Op:     OpGetObject,
+			Reason: notFound(err),
+		}
+	}
+	info, err := f.Stat()
+	if err == nil && info.IsDir() {
+		err = storage.ErrObjectNotFound
+	}
+	if err != nil {
+		f.Close()
+		return nil, OpError{
+			Op:     OpGetObject,
+			Reason: err,
+		}
+	}
+	return objectReader{
+		File:   f,
+		length: info.Size(),
+	}, nil
+}
+
+// PutObject writes the object to a temporary file which replaces the
+// object once completely written; readers never see partial objects.
+func (c *client) PutObject(
+	ctx context.Context,
+	objectPath string,
+	src io.Reader,
+) error {
+	filePath, err := c.filePath(objectPath)
+	if err == nil {
+		err = writeFile(ctx, filePath, src)
+	}
+	if err != nil {
+		return OpError{
+			Op:      OpPutObject,
+			Message: "failed to write object",
+			Reason:  err,
+		}
+	}
+	return nil
+}
+
+func writeFile(ctx context.Context, filePath string, src io.Reader) (err error) {
+	dir := filepath.Dir(filePath)
+	if err = os.MkdirAll(dir, dirMode); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, tempFilePattern)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if _, err = io.Copy(f, src); err != nil {
+		return err
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filePath)
+}
+
+func (c *client) DeleteObject(
+	ctx context.Context,
+	objectPath string,
+) error {
+	filePath, err := c.filePath(objectPath)
+	if err == nil {
+		err = notFound(os.Remove(filePath))
+	}
+	if err != nil {
+		return OpError{
+			Op:      OpDeleteObject,
+			Message: "failed to delete object",
+			Reason:  err,
+		}
+	}
+	return nil
+}
+
+func (c *client) StatObject(
+	ctx context.Context,
+	objectPath string,
+) (*storage.ObjectInfo, error) {
+	filePath, err := c.filePath(objectPath)
+	if err != nil {
+		return nil, OpError{
+			Op:     OpStatObject,
+			Reason: err,
+		}
+	}
+	info, err := os.Stat(filePath)
+	if err == nil && info.IsDir() {
+		err = storage.ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, OpError{
+			Op:      OpStatObject,
+			Message: "failed to retrieve object properties",
+			Reason:  notFound(err),
+		}
+	}
+	size := info.Size()
+	modTime := info.ModTime()
+	return &storage.ObjectInfo{
+		Path:         objectPath,
+		Size:         &size,
+		LastModified: &modTime,
+	}, nil
+}
+
+// signRequest creates a request to the object signed with the presign
+// secret of the deployments service.
+func (c *client) signRequest(
+	method string,
+	objectPath string,
+	duration time.Duration,
+	public bool,
+	query url.Values,
+) (*model.Link, error) {
+	p, err := cleanPath(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	base := c.uri
+	if public {
+		base = c.externalURI
+	}
+	uri := *base
+	uri.Path = strings.TrimSuffix(base.Path, "/") + "/" + p
+	uri.RawPath = ""
+	uri.RawQuery = query.Encode()
+
+	sig := model.NewRequestSignature(&http.Request{
+		Method: method,
+		URL:    &uri,
+	}, c.secret)
+	expire := time.Now().Add(duration)
+	sig.SetExpire(expire)
+	return &model.Link{
+		Uri:    sig.PresignURL(),
+		Method: method,
+		Expire: expire,
+	}, nil
+}
+
+func (c *client) GetRequest(
+	ctx context.Context,
+	objectPath string,
+	filename string,
+	duration time.Duration,
+	public bool,
+) (*model.Link, error) {
+	// Check if object exists
+	if _, err := c.StatObject(ctx, objectPath); err != nil {
+		return nil, OpError{
+			Op:      OpGetRequest,
+			Message: "failed to check preconditions",
+			Reason:  errors.Unwrap(err),
+		}
+	}
+	query := url.Values{}
+	if filename != "" {
+		query.Set(ParamFilename, filename)
+	}
+	link, err := c.signRequest(http.MethodGet, objectPath, duration, public, query)
+	if err != nil {
+		return nil, OpError{
+			Op:      OpGetRequest,
+			Message: "failed to create pre-signed URL",
+			Reason:  err,
+		}
+	}
+	return link, nil
+}
+
+func (c *client) DeleteRequest(
+	ctx context.Context,
+	objectPath string,
+	duration time.Duration,
+	public bool,
+) (*model.Link, error) {
+	link, err := c.signRequest(http.MethodDelete, objectPath, duration, public, nil)
+	if err != nil {
+		return nil, OpError{
+			Op:      OpDeleteRequest,
+			Message: "failed to create pre-signed URL",
+			Reason:  err,
+		}
+	}
+	return link, nil
+}
+
+func (c *client) PutRequest(
+	ctx context.Context,
+	objectPath string,
+	duration time.Duration,
+	public bool,
+) (*model.Link, error) {
+	link, err := c.signRequest(http.MethodPut, objectPath, duration, public, nil)
+	if err != nil {
+		return nil, OpError{
+			Op:      OpPutRequest,
+			Message: "failed to create pre-signed URL",
+			Reason:  err,
+		}
+	}
+	return link, nil
+}
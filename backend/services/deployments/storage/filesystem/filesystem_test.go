@@ -0,0 +1,232 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package filesystem
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+)
+
+var testSecret = []byte("secret")
+
+func newTestStorage(t *testing.T) (storage.ObjectStorage, string) {
+	root := t.TempDir()
+	externalURI, _ := url.Parse("https://mender.io/api/devices/v1/deployments/storage")
+	internalURI, _ := url.Parse("http://deployments:8080/api/devices/v1/deployments/storage/")
+	objStore, err := New(context.Background(), root, NewOptions().
+		SetExternalURI(externalURI).
+		SetURI(internalURI).
+		SetSecret(testSecret),
+	)
+	require.NoError(t, err)
+	return objStore, root
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	uri, _ := url.Parse("https://mender.io")
+	_, err := New(context.Background(), t.TempDir(), NewOptions().SetSecret(testSecret))
+	assert.ErrorIs(t, err, ErrMissingURI)
+
+	_, err = New(context.Background(), t.TempDir(), NewOptions().SetExternalURI(uri))
+	assert.ErrorIs(t, err, ErrMissingSecret)
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err = New(context.Background(), file, NewOptions().
+		SetExternalURI(uri).
+		SetSecret(testSecret))
+	assert.Error(t, err)
+}
+
+func TestObjectLifecycle(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	objStore, root := newTestStorage(t)
+	const objectPath = "tenant/artifact"
+
+	_, err := objStore.GetObject(ctx, objectPath)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+	_, err = objStore.StatObject(ctx, objectPath)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+	err = objStore.DeleteObject(ctx, objectPath)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+
+	err = objStore.PutObject(ctx, objectPath, strings.NewReader("first"))
+	require.NoError(t, err)
+	err = objStore.PutObject(ctx, objectPath, strings.NewReader("content"))
+	require.NoError(t, err)
+
+	info, err := objStore.StatObject(ctx, objectPath)
+	require.NoError(t, err)
+	assert.Equal(t, objectPath, info.Path)
+	if assert.NotNil(t, info.Size) {
+		assert.Equal(t, int64(len("content")), *info.Size)
+	}
+	assert.NotNil(t, info.LastModified)
+
+	obj, err := objStore.GetObject(ctx, objectPath)
+	require.NoError(t, err)
+	if assert.Implements(t, (*storage.ObjectReader)(nil), obj) {
+		assert.Equal(t, int64(len("content")), obj.(storage.ObjectReader).Length())
+	}
+	b, err := io.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "content", string(b))
+	assert.NoError(t, obj.Close())
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(root, "tenant"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// directories are not objects
+	_, err = objStore.GetObject(ctx, "tenant")
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+
+	assert.NoError(t, objStore.DeleteObject(ctx, objectPath))
+	_, err = objStore.StatObject(ctx, objectPath)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestPutObjectAtomic(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	objStore, root := newTestStorage(t)
+	const objectPath = "artifact"
+
+	require.NoError(t, objStore.PutObject(ctx, objectPath, strings.NewReader("content")))
+
+	err := objStore.PutObject(ctx, objectPath, io.MultiReader(
+		strings.NewReader("partial"), errReader{},
+	))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	b, err := os.ReadFile(filepath.Join(root, objectPath))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(b))
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestObjectPath(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	objStore, root := newTestStorage(t)
+
+	err := objStore.PutObject(ctx, "../../escape", strings.NewReader("content"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(root, "escape"))
+	assert.NoError(t, err)
+
+	err = objStore.PutObject(ctx, "/", strings.NewReader("content"))
+	assert.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func TestSignedRequests(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	objStore, _ := newTestStorage(t)
+	const objectPath = "tenant/artifact"
+
+	_, err := objStore.GetRequest(ctx, objectPath, "artifact.mender", time.Minute, true)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+
+	require.NoError(t, objStore.PutObject(ctx, objectPath, strings.NewReader("content")))
+
+	testCases := map[string]struct {
+		Request func() (*model.Link, error)
+
+		Method string
+		URL    string
+	}{
+		"get, public": {
+			Request: func() (*model.Link, error) {
+				return objStore.GetRequest(ctx, objectPath, "artifact.mender",
+					time.Minute, true)
+			},
+			Method: http.MethodGet,
+			URL:    "https://mender.io/api/devices/v1/deployments/storage/tenant/artifact",
+		},
+		"get, internal": {
+			Request: func() (*model.Link, error) {
+				return objStore.GetRequest(ctx, objectPath, "artifact.mender",
+					time.Minute, false)
+			},
+			Method: http.MethodGet,
+			URL:    "http://deployments:8080/api/devices/v1/deployments/storage/tenant/artifact",
+		},
+		"put": {
+			Request: func() (*model.Link, error) {
+				return objStore.PutRequest(ctx, objectPath, time.Minute, true)
+			},
+			Method: http.MethodPut,
+			URL:    "https://mender.io/api/devices/v1/deployments/storage/tenant/artifact",
+		},
+		"delete": {
+			Request: func() (*model.Link, error) {
+				return objStore.DeleteRequest(ctx, objectPath, time.Minute, false)
+			},
+			Method: http.MethodDelete,
+			URL:    "http://deployments:8080/api/devices/v1/deployments/storage/tenant/artifact",
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			link, err := tc.Request()
+			require.NoError(t, err)
+			assert.Equal(t, tc.Method, link.Method)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), link.Expire, time.Second)
+			assert.True(t, strings.HasPrefix(link.Uri, tc.URL+"?"), link.Uri)
+
+			req, err := http.NewRequest(tc.Method, link.Uri, nil)
+			require.NoError(t, err)
+			sig := model.NewRequestSignature(req, testSecret)
+			assert.NoError(t, sig.Validate())
+			assert.True(t, sig.VerifyHMAC256())
+
+			req.Method = http.MethodPost
+			assert.False(t, sig.VerifyHMAC256())
+		})
+	}
+}
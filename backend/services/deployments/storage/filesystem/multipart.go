@@ -0,0 +1,210 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+)
+
+// multipartDir is the directory under the storage root holding the parts
+// of the pending multipart uploads; each upload has its own subdirectory.
+const multipartDir = ".multipart"
+
+func partName(partNumber int) string {
+	return fmt.Sprintf("%05d", partNumber)
+}
+
+// uploadDir returns the directory holding the parts of the upload; the
+// directory exists as long as the upload is pending.
+func (c *client) uploadDir(uploadID string) (string, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return "", storage.ErrUploadNotFound
+	}
+	dir := filepath.Join(c.root, multipartDir, uploadID)
+	info, err := os.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && !info.IsDir()) {
+		return "", storage.ErrUploadNotFound
+	} else if err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func (c *client) CreateMultipartUpload(
+	ctx context.Context,
+	objectPath string,
+) (string, error) {
+	uploadID := uuid.NewString()
+	_, err := cleanPath(objectPath)
+	if err == nil {
+		err = os.MkdirAll(
+			filepath.Join(c.root, multipartDir, uploadID), dirMode,
+		)
+	}
+	if err != nil {
+		return "", OpError{
+			Op:      OpCreateMultipartUpload,
+			Message: "failed to create upload",
+			Reason:  err,
+		}
+	}
+	return uploadID, nil
+}
+
+func (c *client) UploadPart(
+	ctx context.Context,
+	objectPath string,
+	uploadID string,
+	partNumber int,
+	src io.ReadSeeker,
+) (*model.UploadPart, error) {
+	dir, err := c.uploadDir(uploadID)
+	if err == nil {
+		err = writeFile(ctx, filepath.Join(dir, partName(partNumber)), src)
+	}
+	var info fs.FileInfo
+	if err == nil {
+		info, err = os.Stat(filepath.Join(dir, partName(partNumber)))
+	}
+	if err != nil {
+		return nil, OpError{
+			Op:      OpUploadPart,
+			Message: "failed to write part",
+			Reason:  err,
+		}
+	}
+	return &model.UploadPart{
+		PartNumber: partNumber,
+		Size:       info.Size(),
+	}, nil
+}
+
+func (c *client) ListParts(
+	ctx context.Context,
+	objectPath string,
+	uploadID string,
+) ([]model.UploadPart, error) {
+	dir, err := c.uploadDir(uploadID)
+	var entries []fs.DirEntry
+	if err == nil {
+		entries, err = os.ReadDir(dir)
+	}
+	if err != nil {
+		return nil, OpError{
+			Op:      OpListParts,
+			Message: "failed to list parts",
+			Reason:  err,
+		}
+	}
+	parts := []model.UploadPart{}
+	for _, entry := range entries {
+		n, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.Type().IsRegular() {
+			// skip temporary files of the parts being written
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		parts = append(parts, model.UploadPart{
+			PartNumber: n,
+			Size:       info.Size(),
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	return parts, nil
+}
+
+// CompleteMultipartUpload concatenates the parts into the object and
+// removes the upload.
+func (c *client) CompleteMultipartUpload(
+	ctx context.Context,
+	objectPath string,
+	uploadID string,
+	parts []model.UploadPart,
+) error {
+	filePath, err := c.filePath(objectPath)
+	var dir string
+	if err == nil {
+		dir, err = c.uploadDir(uploadID)
+	}
+	if err == nil {
+		err = concatParts(ctx, filePath, dir, parts)
+	}
+	if err == nil {
+		err = os.RemoveAll(dir)
+	}
+	if err != nil {
+		return OpError{
+			Op:      OpCompleteMultipartUpload,
+			Message: "failed to complete upload",
+			Reason:  err,
+		}
+	}
+	return nil
+}
+
+func concatParts(
+	ctx context.Context,
+	filePath string,
+	dir string,
+	parts []model.UploadPart,
+) error {
+	readers := make([]io.Reader, len(parts))
+	for i, part := range parts {
+		f, err := os.Open(filepath.Join(dir, partName(part.PartNumber)))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		readers[i] = f
+	}
+	return writeFile(ctx, filePath, io.MultiReader(readers...))
+}
+
+func (c *client) AbortMultipartUpload(
+	ctx context.Context,
+	objectPath string,
+	uploadID string,
+) error {
+	dir, err := c.uploadDir(uploadID)
+	if err == nil {
+		err = os.RemoveAll(dir)
+	}
+	if err != nil {
+		return OpError{
+			Op:      OpAbortMultipartUpload,
+			Message: "failed to abort upload",
+			Reason:  err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+)
+
+func TestMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	objStore, root := newTestStorage(t)
+	const objectPath = "tenant/artifact.tmp"
+
+	uploadID, err := objStore.CreateMultipartUpload(ctx, objectPath)
+	require.NoError(t, err)
+
+	// parts uploaded out of order and re-uploaded
+	for _, part := range []struct {
+		number int
+		data   string
+	}{{2, "world"}, {1, "hello "}, {2, "world!"}} {
+		up, err := objStore.UploadPart(ctx, objectPath, uploadID, part.number,
+			strings.NewReader(part.data))
+		require.NoError(t, err)
+		assert.Equal(t, &model.UploadPart{
+			PartNumber: part.number,
+			Size:       int64(len(part.data)),
+		}, up)
+	}
+
+	parts, err := objStore.ListParts(ctx, objectPath, uploadID)
+	require.NoError(t, err)
+	assert.Equal(t, []model.UploadPart{
+		{PartNumber: 1, Size: 6},
+		{PartNumber: 2, Size: 6},
+	}, parts)
+
+	err = objStore.CompleteMultipartUpload(ctx, objectPath, uploadID, parts)
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(filepath.Join(root, "tenant", "artifact.tmp"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world!", string(b))
+
+	// the upload does not exist after completion
+	_, err = objStore.ListParts(ctx, objectPath, uploadID)
+	assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+}
+
+func TestAbortMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	objStore, root := newTestStorage(t)
+	const objectPath = "artifact.tmp"
+
+	uploadID, err := objStore.CreateMultipartUpload(ctx, objectPath)
+	require.NoError(t, err)
+	_, err = objStore.UploadPart(ctx, objectPath, uploadID, 1, strings.NewReader("part"))
+	require.NoError(t, err)
+
+	err = objStore.AbortMultipartUpload(ctx, objectPath, uploadID)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(root, multipartDir, uploadID))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = objStore.UploadPart(ctx, objectPath, uploadID, 2, strings.NewReader("part"))
+	assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+	err = objStore.AbortMultipartUpload(ctx, objectPath, uploadID)
+	assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+
+	// upload IDs can not escape the upload directory
+	_, err = objStore.ListParts(ctx, objectPath, "../../etc")
+	assert.ErrorIs(t, err, storage.ErrUploadNotFound)
+}
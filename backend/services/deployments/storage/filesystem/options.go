@@ -0,0 +1,75 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package filesystem
+
+import (
+	"net/url"
+)
+
+type Options struct {
+	// ExternalURI is the base URL of the signed requests handed out to
+	// the clients (public requests).
+	ExternalURI *url.URL
+	// URI is the base URL of the signed requests used by the other
+	// services (internal requests), it defaults to the ExternalURI.
+	URI *url.URL
+
+	// Secret is the key used for signing the requests.
+	Secret []byte
+}
+
+func NewOptions(opts ...*Options) *Options {
+	opt := new(Options)
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		if o.ExternalURI != nil {
+			opt.ExternalURI = o.ExternalURI
+		}
+		if o.URI != nil {
+			opt.URI = o.URI
+		}
+		if o.Secret != nil {
+			opt.Secret = o.Secret
+		}
+	}
+	return opt
+}
+
+func (opts *Options) SetExternalURI(uri *url.URL) *Options {
+	opts.ExternalURI = uri
+	return opts
+}
+
+func (opts *Options) SetURI(uri *url.URL) *Options {
+	opts.URI = uri
+	return opts
+}
+
+func (opts *Options) SetSecret(secret []byte) *Options {
+	opts.Secret = secret
+	return opts
+}
+
+func (opts *Options) Validate() error {
+	if opts.ExternalURI == nil {
+		return ErrMissingURI
+	}
+	if len(opts.Secret) == 0 {
+		return ErrMissingSecret
+	}
+	return nil
+}
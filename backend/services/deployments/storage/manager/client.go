@@ -153,3 +153,58 @@ func (c *client) PutRequest(
 	}
 	return objStore.PutRequest(ctx, path, duration, public)
 }
+
+func (c *client) CreateMultipartUpload(ctx context.Context, path string) (string, error) {
+	objStore, err := c.clientFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return objStore.CreateMultipartUpload(ctx, path)
+}
+
+func (c *client) UploadPart(
+	ctx context.Context,
+	path string,
+	uploadID string,
+	partNumber int,
+	src io.ReadSeeker,
+) (*model.UploadPart, error) {
+	objStore, err := c.clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return objStore.UploadPart(ctx, path, uploadID, partNumber, src)
+}
+
+func (c *client) ListParts(
+	ctx context.Context,
+	path string,
+	uploadID string,
+) ([]model.UploadPart, error) {
+	objStore, err := c.clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return objStore.ListParts(ctx, path, uploadID)
+}
+
+func (c *client) CompleteMultipartUpload(
+	ctx context.Context,
+	path string,
+	uploadID string,
+	parts []model.UploadPart,
+) error {
+	objStore, err := c.clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return objStore.CompleteMultipartUpload(ctx, path, uploadID, parts)
+}
+
+func (c *client) AbortMultipartUpload(ctx context.Context, path string, uploadID string) error {
+	objStore, err := c.clientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return objStore.AbortMultipartUpload(ctx, path, uploadID)
+}
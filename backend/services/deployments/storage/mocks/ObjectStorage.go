@@ -34,6 +34,70 @@ type ObjectStorage struct {
 	mock.Mock
 }
 
+// AbortMultipartUpload provides a mock function with given fields: ctx, path, uploadID
+func (_m *ObjectStorage) AbortMultipartUpload(ctx context.Context, path string, uploadID string) error {
+	ret := _m.Called(ctx, path, uploadID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AbortMultipartUpload")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, path, uploadID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CompleteMultipartUpload provides a mock function with given fields: ctx, path, uploadID, parts
+func (_m *ObjectStorage) CompleteMultipartUpload(ctx context.Context, path string, uploadID string, parts []model.UploadPart) error {
+	ret := _m.Called(ctx, path, uploadID, parts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteMultipartUpload")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []model.UploadPart) error); ok {
+		r0 = rf(ctx, path, uploadID, parts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateMultipartUpload provides a mock function with given fields: ctx, path
+func (_m *ObjectStorage) CreateMultipartUpload(ctx context.Context, path string) (string, error) {
+	ret := _m.Called(ctx, path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMultipartUpload")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(ctx, path)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, path)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteObject provides a mock function with given fields: ctx, path
 func (_m *ObjectStorage) DeleteObject(ctx context.Context, path string) error {
 	ret := _m.Called(ctx, path)
@@ -160,6 +224,36 @@ func (_m *ObjectStorage) HealthCheck(ctx context.Context) error {
 	return r0
 }
 
+// ListParts provides a mock function with given fields: ctx, path, uploadID
+func (_m *ObjectStorage) ListParts(ctx context.Context, path string, uploadID string) ([]model.UploadPart, error) {
+	ret := _m.Called(ctx, path, uploadID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListParts")
+	}
+
+	var r0 []model.UploadPart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]model.UploadPart, error)); ok {
+		return rf(ctx, path, uploadID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []model.UploadPart); ok {
+		r0 = rf(ctx, path, uploadID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UploadPart)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, path, uploadID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PutObject provides a mock function with given fields: ctx, path, src
 func (_m *ObjectStorage) PutObject(ctx context.Context, path string, src io.Reader) error {
 	ret := _m.Called(ctx, path, src)
@@ -238,6 +332,36 @@ func (_m *ObjectStorage) StatObject(ctx context.Context, path string) (*storage.
 	return r0, r1
 }
 
+// UploadPart provides a mock function with given fields: ctx, path, uploadID, partNumber, src
+func (_m *ObjectStorage) UploadPart(ctx context.Context, path string, uploadID string, partNumber int, src io.ReadSeeker) (*model.UploadPart, error) {
+	ret := _m.Called(ctx, path, uploadID, partNumber, src)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UploadPart")
+	}
+
+	var r0 *model.UploadPart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, io.ReadSeeker) (*model.UploadPart, error)); ok {
+		return rf(ctx, path, uploadID, partNumber, src)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, io.ReadSeeker) *model.UploadPart); ok {
+		r0 = rf(ctx, path, uploadID, partNumber, src)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UploadPart)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int, io.ReadSeeker) error); ok {
+		r1 = rf(ctx, path, uploadID, partNumber, src)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewObjectStorage creates a new instance of ObjectStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewObjectStorage(t interface {
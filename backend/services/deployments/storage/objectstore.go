@@ -25,6 +25,7 @@ import (
 
 var (
 	ErrObjectNotFound = errors.New("object not found")
+	ErrUploadNotFound = errors.New("multipart upload not found")
 )
 
 // ObjectStorage allows to store and manage large files
@@ -44,6 +45,16 @@ type ObjectStorage interface {
 		duration time.Duration, public bool) (*model.Link, error)
 	PutRequest(ctx context.Context, path string,
 		duration time.Duration, public bool) (*model.Link, error)
+
+	// The following interface implements resumable multipart uploads; the
+	// uploaded parts are assembled into the object on completion.
+	CreateMultipartUpload(ctx context.Context, path string) (string, error)
+	UploadPart(ctx context.Context, path string, uploadID string,
+		partNumber int, src io.ReadSeeker) (*model.UploadPart, error)
+	ListParts(ctx context.Context, path string, uploadID string) ([]model.UploadPart, error)
+	CompleteMultipartUpload(ctx context.Context, path string, uploadID string,
+		parts []model.UploadPart) error
+	AbortMultipartUpload(ctx context.Context, path string, uploadID string) error
 }
 
 type ObjectInfo struct {
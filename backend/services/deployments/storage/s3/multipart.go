@@ -0,0 +1,187 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsHttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+)
+
+// uploadError maps the errors of unknown multipart uploads to
+// storage.ErrUploadNotFound.
+func uploadError(err error, msg string) error {
+	var noSuchUpload *types.NoSuchUpload
+	if errors.As(err, &noSuchUpload) {
+		err = storage.ErrUploadNotFound
+	} else {
+		var rspErr *awsHttp.ResponseError
+		if errors.As(err, &rspErr) &&
+			rspErr.Response.StatusCode == http.StatusNotFound {
+			err = storage.ErrUploadNotFound
+		}
+	}
+	return errors.WithMessage(err, msg)
+}
+
+func (s *SimpleStorageService) CreateMultipartUpload(
+	ctx context.Context,
+	path string,
+) (string, error) {
+	opts, err := s.optionsFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	rsp, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      opts.BucketName,
+		Key:         aws.String(path),
+		ContentType: s.contentType,
+	}, opts.options)
+	if err != nil {
+		return "", errors.WithMessage(err, "s3: error creating multipart upload")
+	}
+	return aws.ToString(rsp.UploadId), nil
+}
+
+func (s *SimpleStorageService) UploadPart(
+	ctx context.Context,
+	path string,
+	uploadID string,
+	partNumber int,
+	src io.ReadSeeker,
+) (*model.UploadPart, error) {
+	opts, err := s.optionsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	size, err := src.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = src.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        opts.BucketName,
+		Key:           aws.String(path),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(int32(partNumber)),
+		Body:          src,
+		ContentLength: aws.Int64(size),
+	}, opts.options)
+	if err != nil {
+		return nil, uploadError(err, "s3: error uploading part")
+	}
+	return &model.UploadPart{
+		PartNumber: partNumber,
+		Size:       size,
+		ETag:       aws.ToString(rsp.ETag),
+	}, nil
+}
+
+func (s *SimpleStorageService) ListParts(
+	ctx context.Context,
+	path string,
+	uploadID string,
+) ([]model.UploadPart, error) {
+	opts, err := s.optionsFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := &s3.ListPartsInput{
+		Bucket:   opts.BucketName,
+		Key:      aws.String(path),
+		UploadId: aws.String(uploadID),
+	}
+	parts := []model.UploadPart{}
+	for {
+		rsp, err := s.client.ListParts(ctx, params, opts.options)
+		if err != nil {
+			return nil, uploadError(err, "s3: error listing parts")
+		}
+		for _, part := range rsp.Parts {
+			parts = append(parts, model.UploadPart{
+				PartNumber: int(aws.ToInt32(part.PartNumber)),
+				Size:       aws.ToInt64(part.Size),
+				ETag:       aws.ToString(part.ETag),
+			})
+		}
+		if !aws.ToBool(rsp.IsTruncated) {
+			break
+		}
+		params.PartNumberMarker = rsp.NextPartNumberMarker
+	}
+	return parts, nil
+}
+
+func (s *SimpleStorageService) CompleteMultipartUpload(
+	ctx context.Context,
+	path string,
+	uploadID string,
+	parts []model.UploadPart,
+) error {
+	opts, err := s.optionsFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	completedParts := make([]types.CompletedPart, len(parts))
+	for i, part := range parts {
+		completedParts[i] = types.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int32(int32(part.PartNumber)),
+		}
+	}
+	_, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   opts.BucketName,
+		Key:      aws.String(path),
+		UploadId: aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completedParts,
+		},
+	}, opts.options)
+	if err != nil {
+		return uploadError(err, "s3: error completing multipart upload")
+	}
+	return nil
+}
+
+func (s *SimpleStorageService) AbortMultipartUpload(
+	ctx context.Context,
+	path string,
+	uploadID string,
+) error {
+	opts, err := s.optionsFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   opts.BucketName,
+		Key:      aws.String(path),
+		UploadId: aws.String(uploadID),
+	}, opts.options)
+	if err != nil {
+		return uploadError(err, "s3: error aborting multipart upload")
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/storage"
+)
+
+const (
+	testObjectPath = "foo/bar"
+	testUploadID   = "upload-id"
+)
+
+func writeNoSuchUpload(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>NoSuchUpload</Code><Message>The specified upload does not exist.</Message></Error>`))
+}
+
+func TestCreateMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	s3c, srv := newTestServerAndClient(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/"+testObjectPath, r.URL.Path)
+			assert.True(t, r.URL.Query().Has("uploads"))
+
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<InitiateMultipartUploadResult>
+  <Bucket>bucket</Bucket>
+  <Key>foo/bar</Key>
+  <UploadId>upload-id</UploadId>
+</InitiateMultipartUploadResult>`))
+		},
+	))
+	defer srv.Close()
+
+	uploadID, err := s3c.CreateMultipartUpload(context.Background(), testObjectPath)
+	assert.NoError(t, err)
+	assert.Equal(t, testUploadID, uploadID)
+}
+
+func TestUploadPart(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Handler http.HandlerFunc
+
+		Part  *model.UploadPart
+		Error error
+	}{
+		"ok": {
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPut, r.Method)
+				assert.Equal(t, "/"+testObjectPath, r.URL.Path)
+				assert.Equal(t, testUploadID, r.URL.Query().Get("uploadId"))
+				assert.Equal(t, "2", r.URL.Query().Get("partNumber"))
+				_, _ = io.Copy(io.Discard, r.Body)
+
+				w.Header().Set("ETag", `"etag-2"`)
+				w.WriteHeader(http.StatusOK)
+			},
+			Part: &model.UploadPart{
+				PartNumber: 2,
+				Size:       int64(len("part data")),
+				ETag:       `"etag-2"`,
+			},
+		},
+		"error/upload not found": {
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(io.Discard, r.Body)
+				writeNoSuchUpload(w)
+			},
+			Error: storage.ErrUploadNotFound,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s3c, srv := newTestServerAndClient(tc.Handler)
+			defer srv.Close()
+
+			// the source is read from the start, wherever it is positioned
+			src := bytes.NewReader([]byte("part data"))
+			_, _ = src.Seek(4, io.SeekStart)
+			part, err := s3c.UploadPart(context.Background(),
+				testObjectPath, testUploadID, 2, src)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.Part, part)
+			}
+		})
+	}
+}
+
+func TestListParts(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Handler http.HandlerFunc
+
+		Parts []model.UploadPart
+		Error error
+	}{
+		"ok, paginated": {
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/"+testObjectPath, r.URL.Path)
+				assert.Equal(t, testUploadID, r.URL.Query().Get("uploadId"))
+
+				w.Header().Set("Content-Type", "application/xml")
+				if r.URL.Query().Get("part-number-marker") == "" {
+					_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListPartsResult>
+  <IsTruncated>true</IsTruncated>
+  <NextPartNumberMarker>1</NextPartNumberMarker>
+  <Part><PartNumber>1</PartNumber><ETag>"etag-1"</ETag><Size>5242880</Size></Part>
+</ListPartsResult>`))
+					return
+				}
+				assert.Equal(t, "1", r.URL.Query().Get("part-number-marker"))
+				_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListPartsResult>
+  <IsTruncated>false</IsTruncated>
+  <Part><PartNumber>2</PartNumber><ETag>"etag-2"</ETag><Size>10</Size></Part>
+</ListPartsResult>`))
+			},
+			Parts: []model.UploadPart{
+				{PartNumber: 1, Size: 5242880, ETag: `"etag-1"`},
+				{PartNumber: 2, Size: 10, ETag: `"etag-2"`},
+			},
+		},
+		"ok, no parts": {
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/xml")
+				_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListPartsResult><IsTruncated>false</IsTruncated></ListPartsResult>`))
+			},
+			Parts: []model.UploadPart{},
+		},
+		"error/upload not found": {
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				writeNoSuchUpload(w)
+			},
+			Error: storage.ErrUploadNotFound,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s3c, srv := newTestServerAndClient(tc.Handler)
+			defer srv.Close()
+
+			parts, err := s3c.ListParts(context.Background(), testObjectPath, testUploadID)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.Parts, parts)
+			}
+		})
+	}
+}
+
+func TestCompleteMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	parts := []model.UploadPart{
+		{PartNumber: 1, Size: 5242880, ETag: `"etag-1"`},
+		{PartNumber: 2, Size: 10, ETag: `"etag-2"`},
+	}
+	testCases := map[string]struct {
+		Handler http.HandlerFunc
+		Error   error
+	}{
+		"ok": {
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/"+testObjectPath, r.URL.Path)
+				assert.Equal(t, testUploadID, r.URL.Query().Get("uploadId"))
+
+				var upload struct {
+					Parts []struct {
+						PartNumber int
+						ETag       string
+					} `xml:"Part"`
+				}
+				if assert.NoError(t, xml.NewDecoder(r.Body).Decode(&upload)) &&
+					assert.Len(t, upload.Parts, len(parts)) {
+					for i, part := range parts {
+						assert.Equal(t, part.PartNumber, upload.Parts[i].PartNumber)
+						assert.Equal(t, part.ETag, upload.Parts[i].ETag)
+					}
+				}
+
+				w.Header().Set("Content-Type", "application/xml")
+				_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<CompleteMultipartUploadResult>
+  <Bucket>bucket</Bucket>
+  <Key>foo/bar</Key>
+  <ETag>"etag"</ETag>
+</CompleteMultipartUploadResult>`))
+			},
+		},
+		"error/upload not found": {
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(io.Discard, r.Body)
+				writeNoSuchUpload(w)
+			},
+			Error: storage.ErrUploadNotFound,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s3c, srv := newTestServerAndClient(tc.Handler)
+			defer srv.Close()
+
+			err := s3c.CompleteMultipartUpload(context.Background(),
+				testObjectPath, testUploadID, parts)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAbortMultipartUpload(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		Handler http.HandlerFunc
+		Error   error
+	}{
+		"ok": {
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				assert.Equal(t, "/"+testObjectPath, r.URL.Path)
+				assert.Equal(t, testUploadID, r.URL.Query().Get("uploadId"))
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		"error/upload not found": {
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				writeNoSuchUpload(w)
+			},
+			Error: storage.ErrUploadNotFound,
+		},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s3c, srv := newTestServerAndClient(tc.Handler)
+			defer srv.Close()
+
+			err := s3c.AbortMultipartUpload(context.Background(),
+				testObjectPath, testUploadID)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
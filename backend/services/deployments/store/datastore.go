@@ -42,10 +42,26 @@ type DataStore interface {
 
 	//limits
 	GetLimit(ctx context.Context, name string) (*model.Limit, error)
+	IncrementDownloadUsage(ctx context.Context, usage model.DownloadUsage) error
+	GetDownloadUsage(ctx context.Context,
+		filter model.DownloadUsageFilter) (*model.DownloadUsage, error)
 
 	//storage settings
 	GetStorageSettings(ctx context.Context) (*model.StorageSettings, error)
 	SetStorageSettings(ctx context.Context, storageSettings *model.StorageSettings) error
+	GetArtifactSignatureSettings(ctx context.Context) (*model.ArtifactSignatureSettings, error)
+	SetArtifactSignatureSettings(
+		ctx context.Context,
+		settings *model.ArtifactSignatureSettings,
+	) error
+	InsertSigningKey(ctx context.Context, key *model.SigningKey) error
+	ListSigningKeys(ctx context.Context) ([]model.SigningKey, error)
+	DeleteSigningKey(ctx context.Context, id string) error
+	GetArtifactRetentionSettings(ctx context.Context) (*model.ArtifactRetentionSettings, error)
+	SetArtifactRetentionSettings(
+		ctx context.Context,
+		settings *model.ArtifactRetentionSettings,
+	) error
 
 	//tenants
 	ProvisionTenant(ctx context.Context, tenantId string) error
@@ -55,6 +71,7 @@ type DataStore interface {
 	Update(ctx context.Context, image *model.Image) (bool, error)
 	InsertImage(ctx context.Context, image *model.Image) error
 	FindImageByID(ctx context.Context, id string) (*model.Image, error)
+	SetImageContents(ctx context.Context, id string, contents *model.ArtifactContents) error
 	IsArtifactUnique(ctx context.Context, artifactName string,
 		deviceTypesCompatible []string) (bool, error)
 	DeleteImage(ctx context.Context, id string) error
@@ -65,13 +82,14 @@ type DataStore interface {
 	//artifact getter
 	ImagesByName(ctx context.Context,
 		artifactName string) ([]*model.Image, error)
-	ImageByIdsAndDeviceType(ctx context.Context,
-		ids []string, deviceType string) (*model.Image, error)
+	ImagesByIdsAndDeviceType(ctx context.Context,
+		ids []string, deviceType string) ([]*model.Image, error)
 	ImageByNameAndDeviceType(ctx context.Context,
 		name, deviceType string) (*model.Image, error)
 
 	// upload intents
 	InsertUploadIntent(ctx context.Context, link *model.UploadLink) error
+	FindUploadIntent(ctx context.Context, id string) (*model.UploadLink, error)
 	UpdateUploadIntentStatus(ctx context.Context, id string, from, to model.LinkStatus) error
 	FindUploadLinks(ctx context.Context, expired time.Time) (Iterator[model.UploadLink], error)
 
@@ -79,6 +97,14 @@ type DataStore interface {
 	SaveDeviceDeploymentLog(ctx context.Context, log model.DeploymentLog) error
 	GetDeviceDeploymentLog(ctx context.Context,
 		deviceID, deploymentID string) (*model.DeploymentLog, error)
+	GetDeviceDeploymentAttemptLog(ctx context.Context,
+		deviceID, deploymentID string, attempt int) (*model.DeploymentLog, error)
+	ArchiveDeviceDeploymentLog(ctx context.Context,
+		deviceID, deploymentID string, attempt int) (bool, error)
+	SearchDeviceDeploymentLogs(
+		ctx context.Context,
+		search model.DeploymentLogSearch,
+	) ([]model.DeploymentLogMatch, int, error)
 
 	// device deployments
 	InsertDeviceDeployment(ctx context.Context, deviceDeployment *model.DeviceDeployment,
@@ -100,6 +126,18 @@ type DataStore interface {
 		state model.DeviceDeploymentState,
 		currentStatus model.DeviceDeploymentStatus,
 	) (model.DeviceDeploymentStatus, error)
+	RetryDeviceDeployment(
+		ctx context.Context,
+		deviceID string,
+		deploymentID string,
+		attempt model.DeviceDeploymentAttempt,
+		retryAfter *time.Time,
+	) (model.DeviceDeploymentStatus, error)
+	ContinueDeviceDeployments(
+		ctx context.Context,
+		deploymentID string,
+		deviceIDs []string,
+	) (int, error)
 	UpdateDeviceDeploymentLogAvailability(ctx context.Context,
 		deviceID string, deploymentID string, log bool) error
 	AssignArtifact(
@@ -116,13 +154,20 @@ type DataStore interface {
 		query ListQuery) ([]model.DeviceDeployment, int, error)
 	GetDeviceDeploymentsForDevice(ctx context.Context,
 		query ListQueryDeviceDeployments) ([]model.DeviceDeployment, int, error)
+	ExportDeviceDeploymentsHistory(
+		ctx context.Context,
+		filter model.DeviceDeploymentHistoryFilter,
+	) (Iterator[model.DeviceDeploymentHistoryRecord], error)
 	HasDeploymentForDevice(ctx context.Context,
 		deploymentID string, deviceID string) (bool, error)
 	AbortDeviceDeployments(ctx context.Context, deploymentID string) error
+	ExpireDeviceDeployments(ctx context.Context, deploymentID string) error
 	DeleteDeviceDeploymentsHistory(ctx context.Context, deviceId string) error
 	DecommissionDeviceDeployments(ctx context.Context, deviceId string) error
 	GetDeviceDeployment(ctx context.Context, deploymentID string,
 		deviceID string, includeDeleted bool) (*model.DeviceDeployment, error)
+	FindDeviceDeploymentIDs(ctx context.Context,
+		deviceID string, deploymentIDs []string) ([]string, error)
 	GetDeviceDeployments(
 		ctx context.Context,
 		skip int,
@@ -166,12 +211,17 @@ type DataStore interface {
 		createdAfter *time.Time, deviceID string) (*model.Deployment, error)
 	FindNewerActiveDeployments(ctx context.Context,
 		createdAfter *time.Time, skip, limit int) ([]*model.Deployment, error)
+	FindActiveContinuousDeployments(ctx context.Context) ([]*model.Deployment, error)
+	FindExpiredActiveDeployments(ctx context.Context, now time.Time) ([]*model.Deployment, error)
 	ExistUnfinishedByArtifactId(ctx context.Context, id string) (bool, error)
 	ExistUnfinishedByArtifactName(ctx context.Context, artifactName string) (bool, error)
 	ExistByArtifactId(ctx context.Context, id string) (bool, error)
+	FindLatestDeploymentByArtifactId(ctx context.Context, id string) (*model.Deployment, error)
 	SetDeploymentDeviceCount(ctx context.Context, deploymentID string, count int) error
 	IncrementDeploymentDeviceCount(ctx context.Context, deploymentID string, increment int) error
 	IncrementDeploymentTotalSize(ctx context.Context, deploymentID string, increment int64) error
+	IncrementDeploymentDownloadSize(ctx context.Context,
+		deploymentID string, served, downloaded int64) error
 	DeviceCountByDeployment(ctx context.Context, id string) (int, error)
 	UpdateDeploymentsWithArtifactName(
 		ctx context.Context,
@@ -179,6 +229,13 @@ type DataStore interface {
 		artifactIDs []string,
 	) error
 	GetDeploymentIDsByArtifactNames(ctx context.Context, artifactNames []string) ([]string, error)
+	SetDeploymentAbortReason(ctx context.Context, deploymentID string, reason string) error
+	SetDeploymentPhaseResumed(
+		ctx context.Context,
+		deploymentID string,
+		phaseID string,
+		resumed time.Time,
+	) error
 
 	GetTenantDbs() ([]string, error)
 	SaveLastDeviceDeploymentStatus(
@@ -205,6 +262,24 @@ type DataStore interface {
 	SaveUpdateTypes(ctx context.Context, updateTypes []string) error
 	GetUpdateTypes(ctx context.Context) ([]string, error)
 	DeleteReleasesByNames(ctx context.Context, names []string) error
+
+	// Storage migrations
+	InsertStorageMigration(ctx context.Context, migration *model.StorageMigration) error
+	GetStorageMigration(ctx context.Context, id string) (*model.StorageMigration, error)
+	FindUnfinishedStorageMigration(ctx context.Context) (*model.StorageMigration, error)
+	UpdateStorageMigration(ctx context.Context, migration *model.StorageMigration) error
+
+	// Edge caches
+	InsertEdgeCache(ctx context.Context, cache *model.EdgeCache) error
+	ListEdgeCaches(ctx context.Context) ([]model.EdgeCache, error)
+	GetEdgeCache(ctx context.Context, id string) (*model.EdgeCache, error)
+	DeleteEdgeCache(ctx context.Context, id string) error
+	SetEdgeCacheArtifacts(
+		ctx context.Context,
+		id string,
+		artifacts []model.EdgeCacheArtifact,
+		reported time.Time,
+	) error
 }
 
 var ErrNotFound = errors.New("document not found")
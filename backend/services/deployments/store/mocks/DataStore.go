@@ -80,6 +80,34 @@ func (_m *DataStore) AggregateDeviceDeploymentByStatus(ctx context.Context, id s
 	return r0, r1
 }
 
+// ArchiveDeviceDeploymentLog provides a mock function with given fields: ctx, deviceID, deploymentID, attempt
+func (_m *DataStore) ArchiveDeviceDeploymentLog(ctx context.Context, deviceID string, deploymentID string, attempt int) (bool, error) {
+	ret := _m.Called(ctx, deviceID, deploymentID, attempt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveDeviceDeploymentLog")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) (bool, error)); ok {
+		return rf(ctx, deviceID, deploymentID, attempt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) bool); ok {
+		r0 = rf(ctx, deviceID, deploymentID, attempt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, deviceID, deploymentID, attempt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AssignArtifact provides a mock function with given fields: ctx, deviceID, deploymentID, artifact
 func (_m *DataStore) AssignArtifact(ctx context.Context, deviceID string, deploymentID string, artifact *model.Image) error {
 	ret := _m.Called(ctx, deviceID, deploymentID, artifact)
@@ -98,6 +126,34 @@ func (_m *DataStore) AssignArtifact(ctx context.Context, deviceID string, deploy
 	return r0
 }
 
+// ContinueDeviceDeployments provides a mock function with given fields: ctx, deploymentID, deviceIDs
+func (_m *DataStore) ContinueDeviceDeployments(ctx context.Context, deploymentID string, deviceIDs []string) (int, error) {
+	ret := _m.Called(ctx, deploymentID, deviceIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ContinueDeviceDeployments")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) (int, error)); ok {
+		return rf(ctx, deploymentID, deviceIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) int); ok {
+		r0 = rf(ctx, deploymentID, deviceIDs)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, deploymentID, deviceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DecommissionDeviceDeployments provides a mock function with given fields: ctx, deviceId
 func (_m *DataStore) DecommissionDeviceDeployments(ctx context.Context, deviceId string) error {
 	ret := _m.Called(ctx, deviceId)
@@ -152,6 +208,24 @@ func (_m *DataStore) DeleteDeviceDeploymentsHistory(ctx context.Context, deviceI
 	return r0
 }
 
+// DeleteEdgeCache provides a mock function with given fields: ctx, id
+func (_m *DataStore) DeleteEdgeCache(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEdgeCache")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteImage provides a mock function with given fields: ctx, id
 func (_m *DataStore) DeleteImage(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
@@ -206,6 +280,24 @@ func (_m *DataStore) DeleteReleasesByNames(ctx context.Context, names []string)
 	return r0
 }
 
+// DeleteSigningKey provides a mock function with given fields: ctx, id
+func (_m *DataStore) DeleteSigningKey(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSigningKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeviceCountByDeployment provides a mock function with given fields: ctx, id
 func (_m *DataStore) DeviceCountByDeployment(ctx context.Context, id string) (int, error) {
 	ret := _m.Called(ctx, id)
@@ -346,6 +438,84 @@ func (_m *DataStore) Exists(ctx context.Context, id string) (bool, error) {
 	return r0, r1
 }
 
+// ExpireDeviceDeployments provides a mock function with given fields: ctx, deploymentID
+func (_m *DataStore) ExpireDeviceDeployments(ctx context.Context, deploymentID string) error {
+	ret := _m.Called(ctx, deploymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpireDeviceDeployments")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, deploymentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportDeviceDeploymentsHistory provides a mock function with given fields: ctx, filter
+func (_m *DataStore) ExportDeviceDeploymentsHistory(ctx context.Context, filter model.DeviceDeploymentHistoryFilter) (store.Iterator[model.DeviceDeploymentHistoryRecord], error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportDeviceDeploymentsHistory")
+	}
+
+	var r0 store.Iterator[model.DeviceDeploymentHistoryRecord]
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DeviceDeploymentHistoryFilter) (store.Iterator[model.DeviceDeploymentHistoryRecord], error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.DeviceDeploymentHistoryFilter) store.Iterator[model.DeviceDeploymentHistoryRecord]); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.Iterator[model.DeviceDeploymentHistoryRecord])
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.DeviceDeploymentHistoryFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindActiveContinuousDeployments provides a mock function with given fields: ctx
+func (_m *DataStore) FindActiveContinuousDeployments(ctx context.Context) ([]*model.Deployment, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindActiveContinuousDeployments")
+	}
+
+	var r0 []*model.Deployment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*model.Deployment, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*model.Deployment); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Deployment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindDeploymentByID provides a mock function with given fields: ctx, id
 func (_m *DataStore) FindDeploymentByID(ctx context.Context, id string) (*model.Deployment, error) {
 	ret := _m.Called(ctx, id)
@@ -450,6 +620,66 @@ func (_m *DataStore) FindDeployments(ctx context.Context, query model.Query) ([]
 	return r0, r1, r2
 }
 
+// FindDeviceDeploymentIDs provides a mock function with given fields: ctx, deviceID, deploymentIDs
+func (_m *DataStore) FindDeviceDeploymentIDs(ctx context.Context, deviceID string, deploymentIDs []string) ([]string, error) {
+	ret := _m.Called(ctx, deviceID, deploymentIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindDeviceDeploymentIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) ([]string, error)); ok {
+		return rf(ctx, deviceID, deploymentIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) []string); ok {
+		r0 = rf(ctx, deviceID, deploymentIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, deviceID, deploymentIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindExpiredActiveDeployments provides a mock function with given fields: ctx, now
+func (_m *DataStore) FindExpiredActiveDeployments(ctx context.Context, now time.Time) ([]*model.Deployment, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindExpiredActiveDeployments")
+	}
+
+	var r0 []*model.Deployment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*model.Deployment, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*model.Deployment); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Deployment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindImageByID provides a mock function with given fields: ctx, id
 func (_m *DataStore) FindImageByID(ctx context.Context, id string) (*model.Image, error) {
 	ret := _m.Called(ctx, id)
@@ -480,6 +710,36 @@ func (_m *DataStore) FindImageByID(ctx context.Context, id string) (*model.Image
 	return r0, r1
 }
 
+// FindLatestDeploymentByArtifactId provides a mock function with given fields: ctx, id
+func (_m *DataStore) FindLatestDeploymentByArtifactId(ctx context.Context, id string) (*model.Deployment, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindLatestDeploymentByArtifactId")
+	}
+
+	var r0 *model.Deployment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Deployment, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Deployment); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Deployment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindLatestInactiveDeviceDeployment provides a mock function with given fields: ctx, deviceID
 func (_m *DataStore) FindLatestInactiveDeviceDeployment(ctx context.Context, deviceID string) (*model.DeviceDeployment, error) {
 	ret := _m.Called(ctx, deviceID)
@@ -630,6 +890,66 @@ func (_m *DataStore) FindUnfinishedByID(ctx context.Context, id string) (*model.
 	return r0, r1
 }
 
+// FindUnfinishedStorageMigration provides a mock function with given fields: ctx
+func (_m *DataStore) FindUnfinishedStorageMigration(ctx context.Context) (*model.StorageMigration, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindUnfinishedStorageMigration")
+	}
+
+	var r0 *model.StorageMigration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.StorageMigration, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.StorageMigration); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.StorageMigration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindUploadIntent provides a mock function with given fields: ctx, id
+func (_m *DataStore) FindUploadIntent(ctx context.Context, id string) (*model.UploadLink, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindUploadIntent")
+	}
+
+	var r0 *model.UploadLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.UploadLink, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.UploadLink); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UploadLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindUploadLinks provides a mock function with given fields: ctx, expired
 func (_m *DataStore) FindUploadLinks(ctx context.Context, expired time.Time) (store.Iterator[model.UploadLink], error) {
 	ret := _m.Called(ctx, expired)
@@ -660,6 +980,66 @@ func (_m *DataStore) FindUploadLinks(ctx context.Context, expired time.Time) (st
 	return r0, r1
 }
 
+// GetArtifactRetentionSettings provides a mock function with given fields: ctx
+func (_m *DataStore) GetArtifactRetentionSettings(ctx context.Context) (*model.ArtifactRetentionSettings, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetArtifactRetentionSettings")
+	}
+
+	var r0 *model.ArtifactRetentionSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.ArtifactRetentionSettings, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.ArtifactRetentionSettings); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ArtifactRetentionSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetArtifactSignatureSettings provides a mock function with given fields: ctx
+func (_m *DataStore) GetArtifactSignatureSettings(ctx context.Context) (*model.ArtifactSignatureSettings, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetArtifactSignatureSettings")
+	}
+
+	var r0 *model.ArtifactSignatureSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.ArtifactSignatureSettings, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.ArtifactSignatureSettings); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ArtifactSignatureSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDeploymentIDsByArtifactNames provides a mock function with given fields: ctx, artifactNames
 func (_m *DataStore) GetDeploymentIDsByArtifactNames(ctx context.Context, artifactNames []string) ([]string, error) {
 	ret := _m.Called(ctx, artifactNames)
@@ -720,6 +1100,36 @@ func (_m *DataStore) GetDeviceDeployment(ctx context.Context, deploymentID strin
 	return r0, r1
 }
 
+// GetDeviceDeploymentAttemptLog provides a mock function with given fields: ctx, deviceID, deploymentID, attempt
+func (_m *DataStore) GetDeviceDeploymentAttemptLog(ctx context.Context, deviceID string, deploymentID string, attempt int) (*model.DeploymentLog, error) {
+	ret := _m.Called(ctx, deviceID, deploymentID, attempt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeviceDeploymentAttemptLog")
+	}
+
+	var r0 *model.DeploymentLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) (*model.DeploymentLog, error)); ok {
+		return rf(ctx, deviceID, deploymentID, attempt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) *model.DeploymentLog); ok {
+		r0 = rf(ctx, deviceID, deploymentID, attempt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DeploymentLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, deviceID, deploymentID, attempt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDeviceDeploymentLog provides a mock function with given fields: ctx, deviceID, deploymentID
 func (_m *DataStore) GetDeviceDeploymentLog(ctx context.Context, deviceID string, deploymentID string) (*model.DeploymentLog, error) {
 	ret := _m.Called(ctx, deviceID, deploymentID)
@@ -884,6 +1294,66 @@ func (_m *DataStore) GetDevicesListForDeployment(ctx context.Context, query stor
 	return r0, r1, r2
 }
 
+// GetDownloadUsage provides a mock function with given fields: ctx, filter
+func (_m *DataStore) GetDownloadUsage(ctx context.Context, filter model.DownloadUsageFilter) (*model.DownloadUsage, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDownloadUsage")
+	}
+
+	var r0 *model.DownloadUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DownloadUsageFilter) (*model.DownloadUsage, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.DownloadUsageFilter) *model.DownloadUsage); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DownloadUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.DownloadUsageFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEdgeCache provides a mock function with given fields: ctx, id
+func (_m *DataStore) GetEdgeCache(ctx context.Context, id string) (*model.EdgeCache, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEdgeCache")
+	}
+
+	var r0 *model.EdgeCache
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.EdgeCache, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.EdgeCache); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.EdgeCache)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLastDeviceDeploymentStatus provides a mock function with given fields: ctx, devicesIds
 func (_m *DataStore) GetLastDeviceDeploymentStatus(ctx context.Context, devicesIds []string) ([]model.DeviceDeploymentLastStatus, error) {
 	ret := _m.Called(ctx, devicesIds)
@@ -1011,6 +1481,36 @@ func (_m *DataStore) GetReleases(ctx context.Context, filt *model.ReleaseOrImage
 	return r0, r1, r2
 }
 
+// GetStorageMigration provides a mock function with given fields: ctx, id
+func (_m *DataStore) GetStorageMigration(ctx context.Context, id string) (*model.StorageMigration, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStorageMigration")
+	}
+
+	var r0 *model.StorageMigration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.StorageMigration, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.StorageMigration); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.StorageMigration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStorageSettings provides a mock function with given fields: ctx
 func (_m *DataStore) GetStorageSettings(ctx context.Context) (*model.StorageSettings, error) {
 	ret := _m.Called(ctx)
@@ -1129,36 +1629,6 @@ func (_m *DataStore) HasDeploymentForDevice(ctx context.Context, deploymentID st
 	return r0, r1
 }
 
-// ImageByIdsAndDeviceType provides a mock function with given fields: ctx, ids, deviceType
-func (_m *DataStore) ImageByIdsAndDeviceType(ctx context.Context, ids []string, deviceType string) (*model.Image, error) {
-	ret := _m.Called(ctx, ids, deviceType)
-
-	if len(ret) == 0 {
-		panic("no return value specified for ImageByIdsAndDeviceType")
-	}
-
-	var r0 *model.Image
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, []string, string) (*model.Image, error)); ok {
-		return rf(ctx, ids, deviceType)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, []string, string) *model.Image); ok {
-		r0 = rf(ctx, ids, deviceType)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Image)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, []string, string) error); ok {
-		r1 = rf(ctx, ids, deviceType)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
 // ImageByNameAndDeviceType provides a mock function with given fields: ctx, name, deviceType
 func (_m *DataStore) ImageByNameAndDeviceType(ctx context.Context, name string, deviceType string) (*model.Image, error) {
 	ret := _m.Called(ctx, name, deviceType)
@@ -1189,6 +1659,36 @@ func (_m *DataStore) ImageByNameAndDeviceType(ctx context.Context, name string,
 	return r0, r1
 }
 
+// ImagesByIdsAndDeviceType provides a mock function with given fields: ctx, ids, deviceType
+func (_m *DataStore) ImagesByIdsAndDeviceType(ctx context.Context, ids []string, deviceType string) ([]*model.Image, error) {
+	ret := _m.Called(ctx, ids, deviceType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImagesByIdsAndDeviceType")
+	}
+
+	var r0 []*model.Image
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, string) ([]*model.Image, error)); ok {
+		return rf(ctx, ids, deviceType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, string) []*model.Image); ok {
+		r0 = rf(ctx, ids, deviceType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Image)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, string) error); ok {
+		r1 = rf(ctx, ids, deviceType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ImagesByName provides a mock function with given fields: ctx, artifactName
 func (_m *DataStore) ImagesByName(ctx context.Context, artifactName string) ([]*model.Image, error) {
 	ret := _m.Called(ctx, artifactName)
@@ -1237,6 +1737,24 @@ func (_m *DataStore) IncrementDeploymentDeviceCount(ctx context.Context, deploym
 	return r0
 }
 
+// IncrementDeploymentDownloadSize provides a mock function with given fields: ctx, deploymentID, served, downloaded
+func (_m *DataStore) IncrementDeploymentDownloadSize(ctx context.Context, deploymentID string, served int64, downloaded int64) error {
+	ret := _m.Called(ctx, deploymentID, served, downloaded)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementDeploymentDownloadSize")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64) error); ok {
+		r0 = rf(ctx, deploymentID, served, downloaded)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // IncrementDeploymentTotalSize provides a mock function with given fields: ctx, deploymentID, increment
 func (_m *DataStore) IncrementDeploymentTotalSize(ctx context.Context, deploymentID string, increment int64) error {
 	ret := _m.Called(ctx, deploymentID, increment)
@@ -1255,6 +1773,24 @@ func (_m *DataStore) IncrementDeploymentTotalSize(ctx context.Context, deploymen
 	return r0
 }
 
+// IncrementDownloadUsage provides a mock function with given fields: ctx, usage
+func (_m *DataStore) IncrementDownloadUsage(ctx context.Context, usage model.DownloadUsage) error {
+	ret := _m.Called(ctx, usage)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementDownloadUsage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DownloadUsage) error); ok {
+		r0 = rf(ctx, usage)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertDeployment provides a mock function with given fields: ctx, deployment
 func (_m *DataStore) InsertDeployment(ctx context.Context, deployment *model.Deployment) error {
 	ret := _m.Called(ctx, deployment)
@@ -1291,6 +1827,24 @@ func (_m *DataStore) InsertDeviceDeployment(ctx context.Context, deviceDeploymen
 	return r0
 }
 
+// InsertEdgeCache provides a mock function with given fields: ctx, cache
+func (_m *DataStore) InsertEdgeCache(ctx context.Context, cache *model.EdgeCache) error {
+	ret := _m.Called(ctx, cache)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertEdgeCache")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.EdgeCache) error); ok {
+		r0 = rf(ctx, cache)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertImage provides a mock function with given fields: ctx, image
 func (_m *DataStore) InsertImage(ctx context.Context, image *model.Image) error {
 	ret := _m.Called(ctx, image)
@@ -1334,6 +1888,42 @@ func (_m *DataStore) InsertMany(ctx context.Context, deployment ...*model.Device
 	return r0
 }
 
+// InsertSigningKey provides a mock function with given fields: ctx, key
+func (_m *DataStore) InsertSigningKey(ctx context.Context, key *model.SigningKey) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertSigningKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.SigningKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertStorageMigration provides a mock function with given fields: ctx, migration
+func (_m *DataStore) InsertStorageMigration(ctx context.Context, migration *model.StorageMigration) error {
+	ret := _m.Called(ctx, migration)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertStorageMigration")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.StorageMigration) error); ok {
+		r0 = rf(ctx, migration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertUploadIntent provides a mock function with given fields: ctx, link
 func (_m *DataStore) InsertUploadIntent(ctx context.Context, link *model.UploadLink) error {
 	ret := _m.Called(ctx, link)
@@ -1380,6 +1970,36 @@ func (_m *DataStore) IsArtifactUnique(ctx context.Context, artifactName string,
 	return r0, r1
 }
 
+// ListEdgeCaches provides a mock function with given fields: ctx
+func (_m *DataStore) ListEdgeCaches(ctx context.Context) ([]model.EdgeCache, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListEdgeCaches")
+	}
+
+	var r0 []model.EdgeCache
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.EdgeCache, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.EdgeCache); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.EdgeCache)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListImages provides a mock function with given fields: ctx, filt
 func (_m *DataStore) ListImages(ctx context.Context, filt *model.ReleaseOrImageFilter) ([]*model.Image, int, error) {
 	ret := _m.Called(ctx, filt)
@@ -1477,6 +2097,36 @@ func (_m *DataStore) ListReleaseTags(ctx context.Context) (model.Tags, error) {
 	return r0, r1
 }
 
+// ListSigningKeys provides a mock function with given fields: ctx
+func (_m *DataStore) ListSigningKeys(ctx context.Context) ([]model.SigningKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSigningKeys")
+	}
+
+	var r0 []model.SigningKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.SigningKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.SigningKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SigningKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Ping provides a mock function with given fields: ctx
 func (_m *DataStore) Ping(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -1531,6 +2181,34 @@ func (_m *DataStore) ReplaceReleaseTags(ctx context.Context, releaseName string,
 	return r0
 }
 
+// RetryDeviceDeployment provides a mock function with given fields: ctx, deviceID, deploymentID, attempt, retryAfter
+func (_m *DataStore) RetryDeviceDeployment(ctx context.Context, deviceID string, deploymentID string, attempt model.DeviceDeploymentAttempt, retryAfter *time.Time) (model.DeviceDeploymentStatus, error) {
+	ret := _m.Called(ctx, deviceID, deploymentID, attempt, retryAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetryDeviceDeployment")
+	}
+
+	var r0 model.DeviceDeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.DeviceDeploymentAttempt, *time.Time) (model.DeviceDeploymentStatus, error)); ok {
+		return rf(ctx, deviceID, deploymentID, attempt, retryAfter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.DeviceDeploymentAttempt, *time.Time) model.DeviceDeploymentStatus); ok {
+		r0 = rf(ctx, deviceID, deploymentID, attempt, retryAfter)
+	} else {
+		r0 = ret.Get(0).(model.DeviceDeploymentStatus)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, model.DeviceDeploymentAttempt, *time.Time) error); ok {
+		r1 = rf(ctx, deviceID, deploymentID, attempt, retryAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveDeviceDeploymentLog provides a mock function with given fields: ctx, log
 func (_m *DataStore) SaveDeviceDeploymentLog(ctx context.Context, log model.DeploymentLog) error {
 	ret := _m.Called(ctx, log)
@@ -1603,6 +2281,97 @@ func (_m *DataStore) SaveUpdateTypes(ctx context.Context, updateTypes []string)
 	return r0
 }
 
+// SearchDeviceDeploymentLogs provides a mock function with given fields: ctx, search
+func (_m *DataStore) SearchDeviceDeploymentLogs(ctx context.Context, search model.DeploymentLogSearch) ([]model.DeploymentLogMatch, int, error) {
+	ret := _m.Called(ctx, search)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchDeviceDeploymentLogs")
+	}
+
+	var r0 []model.DeploymentLogMatch
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DeploymentLogSearch) ([]model.DeploymentLogMatch, int, error)); ok {
+		return rf(ctx, search)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.DeploymentLogSearch) []model.DeploymentLogMatch); ok {
+		r0 = rf(ctx, search)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.DeploymentLogMatch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.DeploymentLogSearch) int); ok {
+		r1 = rf(ctx, search)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.DeploymentLogSearch) error); ok {
+		r2 = rf(ctx, search)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetArtifactRetentionSettings provides a mock function with given fields: ctx, settings
+func (_m *DataStore) SetArtifactRetentionSettings(ctx context.Context, settings *model.ArtifactRetentionSettings) error {
+	ret := _m.Called(ctx, settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetArtifactRetentionSettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArtifactRetentionSettings) error); ok {
+		r0 = rf(ctx, settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetArtifactSignatureSettings provides a mock function with given fields: ctx, settings
+func (_m *DataStore) SetArtifactSignatureSettings(ctx context.Context, settings *model.ArtifactSignatureSettings) error {
+	ret := _m.Called(ctx, settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetArtifactSignatureSettings")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ArtifactSignatureSettings) error); ok {
+		r0 = rf(ctx, settings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetDeploymentAbortReason provides a mock function with given fields: ctx, deploymentID, reason
+func (_m *DataStore) SetDeploymentAbortReason(ctx context.Context, deploymentID string, reason string) error {
+	ret := _m.Called(ctx, deploymentID, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDeploymentAbortReason")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, deploymentID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetDeploymentDeviceCount provides a mock function with given fields: ctx, deploymentID, count
 func (_m *DataStore) SetDeploymentDeviceCount(ctx context.Context, deploymentID string, count int) error {
 	ret := _m.Called(ctx, deploymentID, count)
@@ -1621,6 +2390,24 @@ func (_m *DataStore) SetDeploymentDeviceCount(ctx context.Context, deploymentID
 	return r0
 }
 
+// SetDeploymentPhaseResumed provides a mock function with given fields: ctx, deploymentID, phaseID, resumed
+func (_m *DataStore) SetDeploymentPhaseResumed(ctx context.Context, deploymentID string, phaseID string, resumed time.Time) error {
+	ret := _m.Called(ctx, deploymentID, phaseID, resumed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDeploymentPhaseResumed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) error); ok {
+		r0 = rf(ctx, deploymentID, phaseID, resumed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetDeploymentStatus provides a mock function with given fields: ctx, id, status, now
 func (_m *DataStore) SetDeploymentStatus(ctx context.Context, id string, status model.DeploymentStatus, now time.Time) error {
 	ret := _m.Called(ctx, id, status, now)
@@ -1639,6 +2426,42 @@ func (_m *DataStore) SetDeploymentStatus(ctx context.Context, id string, status
 	return r0
 }
 
+// SetEdgeCacheArtifacts provides a mock function with given fields: ctx, id, artifacts, reported
+func (_m *DataStore) SetEdgeCacheArtifacts(ctx context.Context, id string, artifacts []model.EdgeCacheArtifact, reported time.Time) error {
+	ret := _m.Called(ctx, id, artifacts, reported)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetEdgeCacheArtifacts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []model.EdgeCacheArtifact, time.Time) error); ok {
+		r0 = rf(ctx, id, artifacts, reported)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetImageContents provides a mock function with given fields: ctx, id, contents
+func (_m *DataStore) SetImageContents(ctx context.Context, id string, contents *model.ArtifactContents) error {
+	ret := _m.Called(ctx, id, contents)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetImageContents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.ArtifactContents) error); ok {
+		r0 = rf(ctx, id, contents)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetStorageSettings provides a mock function with given fields: ctx, storageSettings
 func (_m *DataStore) SetStorageSettings(ctx context.Context, storageSettings *model.StorageSettings) error {
 	ret := _m.Called(ctx, storageSettings)
@@ -1851,6 +2674,24 @@ func (_m *DataStore) UpdateStatsInc(ctx context.Context, id string, stateFrom mo
 	return r0, r1
 }
 
+// UpdateStorageMigration provides a mock function with given fields: ctx, migration
+func (_m *DataStore) UpdateStorageMigration(ctx context.Context, migration *model.StorageMigration) error {
+	ret := _m.Called(ctx, migration)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStorageMigration")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.StorageMigration) error); ok {
+		r0 = rf(ctx, migration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateUploadIntentStatus provides a mock function with given fields: ctx, id, from, to
 func (_m *DataStore) UpdateUploadIntentStatus(ctx context.Context, id string, from model.LinkStatus, to model.LinkStatus) error {
 	ret := _m.Called(ctx, id, from, to)
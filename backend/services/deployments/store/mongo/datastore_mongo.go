@@ -110,6 +110,9 @@ var (
 	// Indexes 1.2.17
 	IndexNameDeploymentName = "deployment_name"
 
+	// Indexes 1.2.18
+	IndexNameDeviceDeploymentsLogsExpireAt = "devices_logs_expire_at"
+
 	_false         = false
 	_true          = true
 	StorageIndexes = mongo.IndexModel{
@@ -324,6 +327,16 @@ var (
 			Name:       &IndexNameDeploymentName,
 		},
 	}
+
+	// 1.2.18
+	IndexDeviceDeploymentsLogsExpireAt = mongo.IndexModel{
+		Keys: bson.D{
+			{Key: StorageKeyDeviceDeploymentLogExpireAt, Value: 1},
+		},
+		Options: mopts.Index().
+			SetName(IndexNameDeviceDeploymentsLogsExpireAt).
+			SetExpireAfterSeconds(0),
+	}
 )
 
 // Errors
@@ -369,6 +382,7 @@ const (
 	StorageKeyUpdateType       = "meta_artifact.updates.typeinfo.type"
 	StorageKeyImageDescription = "meta.description"
 	StorageKeyImageModified    = "modified"
+	StorageKeyImageContents    = "contents"
 
 	// releases
 	StorageKeyReleaseName                      = "_id"
@@ -394,7 +408,10 @@ const (
 	StorageKeyReleaseImageProvidesIdx = StorageKeyReleaseArtifacts + "." +
 		StorageKeyImageProvidesIdx
 
-	StorageKeyDeviceDeploymentLogMessages = "messages"
+	StorageKeyDeviceDeploymentLogMessages           = "messages"
+	StorageKeyDeviceDeploymentLogMessagesCompressed = "messages_gz"
+	StorageKeyDeviceDeploymentLogExpireAt           = "expire_at"
+	StorageKeyDeviceDeploymentLogAttempt            = "attempt"
 
 	StorageKeyDeviceDeploymentAssignedImage   = "image"
 	StorageKeyDeviceDeploymentAssignedImageId = StorageKeyDeviceDeploymentAssignedImage +
@@ -412,6 +429,9 @@ const (
 	StorageKeyDeviceDeploymentArtifact       = "image"
 	StorageKeyDeviceDeploymentRequest        = "request"
 	StorageKeyDeviceDeploymentDeleted        = "deleted"
+	StorageKeyDeviceDeploymentAttempts       = "attempts"
+	StorageKeyDeviceDeploymentRetryAfter     = "retry_after"
+	StorageKeyDeviceDeploymentContinued      = "continued_states"
 
 	StorageKeyDeploymentName                = "deploymentconstructor.name"
 	StorageKeyDeploymentArtifactName        = "deploymentconstructor.artifactname"
@@ -428,6 +448,12 @@ const (
 	StorageKeyDeploymentMaxDevices          = "max_devices"
 	StorageKeyDeploymentType                = "type"
 	StorageKeyDeploymentTotalSize           = "statistics.total_size"
+	StorageKeyDeploymentPhases              = "deploymentconstructor.phases"
+	StorageKeyDeploymentPhaseId             = StorageKeyDeploymentPhases + ".id"
+	StorageKeyDeploymentPhaseResumed        = StorageKeyDeploymentPhases + ".$.resumed_ts"
+	StorageKeyDeploymentAbortReason         = "abort_reason"
+	StorageKeyDeploymentContinuous          = "deploymentconstructor.continuous"
+	StorageKeyDeploymentEndTime             = "deploymentconstructor.end_ts"
 
 	StorageKeyStorageSettingsDefaultID      = "settings"
 	StorageKeyStorageSettingsBucket         = "bucket"
@@ -844,6 +870,30 @@ func (db *DataStoreMongo) Update(ctx context.Context,
 	return true, nil
 }
 
+// SetImageContents caches the contents of the artifact payloads on the
+// image, leaving the modification time untouched.
+func (db *DataStoreMongo) SetImageContents(ctx context.Context,
+	id string, contents *model.ArtifactContents) error {
+
+	if len(id) == 0 {
+		return ErrImagesStorageInvalidID
+	}
+
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collImg := database.Collection(CollectionImages)
+
+	res, err := collImg.UpdateOne(ctx,
+		bson.M{StorageKeyId: id},
+		bson.M{"$set": bson.M{StorageKeyImageContents: contents}},
+	)
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return ErrStorageNotFound
+	}
+	return nil
+}
+
 // ImageByNameAndDeviceType finds image with specified application name and target device type
 func (db *DataStoreMongo) ImageByNameAndDeviceType(ctx context.Context,
 	name, deviceType string) (*model.Image, error) {
@@ -883,9 +933,10 @@ func (db *DataStoreMongo) ImageByNameAndDeviceType(ctx context.Context,
 	return &image, nil
 }
 
-// ImageByIdsAndDeviceType finds image with id from ids and target device type
-func (db *DataStoreMongo) ImageByIdsAndDeviceType(ctx context.Context,
-	ids []string, deviceType string) (*model.Image, error) {
+// ImagesByIdsAndDeviceType finds images with id from ids and target device
+// type sorted by size in ascending order
+func (db *DataStoreMongo) ImagesByIdsAndDeviceType(ctx context.Context,
+	ids []string, deviceType string) ([]*model.Image, error) {
 
 	if len(deviceType) == 0 {
 		return nil, ErrImagesStorageInvalidDeviceType
@@ -903,21 +954,20 @@ func (db *DataStoreMongo) ImageByIdsAndDeviceType(ctx context.Context,
 	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
 	collImg := database.Collection(CollectionImages)
 
-	// If multiple entries matches, pick the smallest one
-	findOpts := mopts.FindOne()
+	// smaller images are preferred
+	findOpts := mopts.Find()
 	findOpts.SetSort(bson.D{{Key: StorageKeyImageSize, Value: 1}})
 
-	// Both we lookup unique object, should be one or none.
-	var image model.Image
-	if err := collImg.FindOne(ctx, query, findOpts).
-		Decode(&image); err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
-		}
+	cursor, err := collImg.Find(ctx, query, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	var images []*model.Image
+	if err := cursor.All(ctx, &images); err != nil {
 		return nil, err
 	}
 
-	return &image, nil
+	return images, nil
 }
 
 // ImagesByName finds images with specified artifact name
@@ -1014,6 +1064,30 @@ func (db *DataStoreMongo) InsertUploadIntent(ctx context.Context, link *model.Up
 	return err
 }
 
+func (db *DataStoreMongo) FindUploadIntent(
+	ctx context.Context,
+	id string,
+) (*model.UploadLink, error) {
+	collUploads := db.client.
+		Database(DatabaseName).
+		Collection(CollectionUploadIntents)
+	q := bson.D{{Key: "_id", Value: id}}
+	if idty := identity.FromContext(ctx); idty != nil {
+		q = append(q, bson.E{
+			Key:   StorageKeyTenantId,
+			Value: idty.Tenant,
+		})
+	}
+	var link model.UploadLink
+	err := collUploads.FindOne(ctx, q).Decode(&link)
+	if err == mongo.ErrNoDocuments {
+		return nil, store.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
 func (db *DataStoreMongo) UpdateUploadIntentStatus(
 	ctx context.Context,
 	id string,
@@ -1435,19 +1509,19 @@ func (db *DataStoreMongo) SaveDeviceDeploymentLog(ctx context.Context,
 	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
 	collLogs := database.Collection(CollectionDeviceDeploymentLogs)
 
-	query := bson.D{
-		{Key: StorageKeyDeviceDeploymentDeviceId,
-			Value: log.DeviceID},
-		{Key: StorageKeyDeviceDeploymentDeploymentID,
-			Value: log.DeploymentID},
-	}
+	query := deploymentLogQuery(log.DeviceID, log.DeploymentID, log.Attempt)
 
 	// update log messages
 	// if the deployment log is already present than messages will be overwritten
+	set, unset, err := deploymentLogUpdate(log)
+	if err != nil {
+		return err
+	}
 	update := bson.D{
-		{Key: "$set", Value: bson.M{
-			StorageKeyDeviceDeploymentLogMessages: log.Messages,
-		}},
+		{Key: "$set", Value: set},
+	}
+	if len(unset) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: unset})
 	}
 	updateOptions := mopts.Update()
 	updateOptions.SetUpsert(true)
@@ -1461,24 +1535,47 @@ func (db *DataStoreMongo) SaveDeviceDeploymentLog(ctx context.Context,
 
 func (db *DataStoreMongo) GetDeviceDeploymentLog(ctx context.Context,
 	deviceID, deploymentID string) (*model.DeploymentLog, error) {
+	return db.GetDeviceDeploymentAttemptLog(ctx, deviceID, deploymentID, 0)
+}
+
+// GetDeviceDeploymentAttemptLog returns the log of the failed attempt of the
+// device, or the log of the current attempt if attempt is zero.
+func (db *DataStoreMongo) GetDeviceDeploymentAttemptLog(ctx context.Context,
+	deviceID, deploymentID string, attempt int) (*model.DeploymentLog, error) {
 
 	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
 	collLogs := database.Collection(CollectionDeviceDeploymentLogs)
 
-	query := bson.M{
-		StorageKeyDeviceDeploymentDeviceId:     deviceID,
-		StorageKeyDeviceDeploymentDeploymentID: deploymentID,
-	}
+	query := deploymentLogQuery(deviceID, deploymentID, attempt)
 
-	var depl model.DeploymentLog
-	if err := collLogs.FindOne(ctx, query).Decode(&depl); err != nil {
+	var doc deploymentLogDocument
+	if err := collLogs.FindOne(ctx, query).Decode(&doc); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return &depl, nil
+	return doc.DeploymentLog()
+}
+
+// ArchiveDeviceDeploymentLog tags the log of the current attempt of the
+// device with the number of the failed attempt, so that the next attempt
+// starts with a new log. Returns false if the device has no current log.
+func (db *DataStoreMongo) ArchiveDeviceDeploymentLog(ctx context.Context,
+	deviceID, deploymentID string, attempt int) (bool, error) {
+
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collLogs := database.Collection(CollectionDeviceDeploymentLogs)
+
+	res, err := collLogs.UpdateOne(ctx,
+		deploymentLogQuery(deviceID, deploymentID, 0),
+		bson.M{"$set": bson.M{StorageKeyDeviceDeploymentLogAttempt: attempt}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
 }
 
 // device deployments
@@ -1713,6 +1810,113 @@ func (db *DataStoreMongo) UpdateDeviceDeploymentStatus(
 	return old.Status, nil
 }
 
+// RetryDeviceDeployment records the failed attempt of the device and resets
+// the device deployment to pending, so that the deployment is offered to the
+// device again after retryAfter.
+func (db *DataStoreMongo) RetryDeviceDeployment(
+	ctx context.Context,
+	deviceID string,
+	deploymentID string,
+	attempt model.DeviceDeploymentAttempt,
+	retryAfter *time.Time,
+) (model.DeviceDeploymentStatus, error) {
+	if len(deviceID) == 0 || len(deploymentID) == 0 {
+		return model.DeviceDeploymentStatusNull, ErrStorageInvalidID
+	}
+
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collDevs := database.Collection(CollectionDevices)
+
+	query := bson.D{
+		{Key: StorageKeyDeviceDeploymentDeviceId, Value: deviceID},
+		{Key: StorageKeyDeviceDeploymentDeploymentID, Value: deploymentID},
+		{Key: StorageKeyDeviceDeploymentDeleted, Value: bson.D{
+			{Key: "$exists", Value: false},
+		}},
+	}
+	set := bson.M{
+		StorageKeyDeviceDeploymentStatus:         model.DeviceDeploymentStatusPending,
+		StorageKeyDeviceDeploymentActive:         true,
+		StorageKeyDeviceDeploymentIsLogAvailable: false,
+	}
+	unset := bson.M{
+		StorageKeyDeviceDeploymentStarted:   "",
+		StorageKeyDeviceDeploymentFinished:  "",
+		StorageKeyDeviceDeploymentSubState:  "",
+		StorageKeyDeviceDeploymentRequest:   "",
+		StorageKeyDeviceDeploymentContinued: "",
+	}
+	if retryAfter != nil {
+		set[StorageKeyDeviceDeploymentRetryAfter] = retryAfter
+	} else {
+		unset[StorageKeyDeviceDeploymentRetryAfter] = ""
+	}
+	update := bson.D{
+		{Key: "$set", Value: set},
+		{Key: "$unset", Value: unset},
+		{Key: "$push", Value: bson.M{StorageKeyDeviceDeploymentAttempts: attempt}},
+	}
+
+	var old model.DeviceDeployment
+	if err := collDevs.FindOneAndUpdate(ctx, query, update).
+		Decode(&old); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return model.DeviceDeploymentStatusNull, ErrStorageNotFound
+		}
+		return model.DeviceDeploymentStatusNull, err
+	}
+
+	return old.Status, nil
+}
+
+// ContinueDeviceDeployments marks the paused devices of the deployment as
+// continued in the state they are paused in; all the paused devices are
+// continued if deviceIDs is empty. Returns the number of devices continued.
+func (db *DataStoreMongo) ContinueDeviceDeployments(
+	ctx context.Context,
+	deploymentID string,
+	deviceIDs []string,
+) (int, error) {
+	if len(deploymentID) == 0 {
+		return 0, ErrStorageInvalidID
+	}
+
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collDevs := database.Collection(CollectionDevices)
+
+	var count int
+	for _, status := range []model.DeviceDeploymentStatus{
+		model.DeviceDeploymentStatusPauseBeforeInstall,
+		model.DeviceDeploymentStatusPauseBeforeReboot,
+		model.DeviceDeploymentStatusPauseBeforeCommit,
+	} {
+		state := model.UpdateControlStatePaused(status)
+		query := bson.D{
+			{Key: StorageKeyDeviceDeploymentDeploymentID, Value: deploymentID},
+			{Key: StorageKeyDeviceDeploymentStatus, Value: status},
+			{Key: StorageKeyDeviceDeploymentContinued, Value: bson.M{"$ne": state}},
+			{Key: StorageKeyDeviceDeploymentDeleted, Value: bson.D{
+				{Key: "$exists", Value: false},
+			}},
+		}
+		if len(deviceIDs) > 0 {
+			query = append(query, bson.E{
+				Key: StorageKeyDeviceDeploymentDeviceId, Value: bson.M{"$in": deviceIDs},
+			})
+		}
+		update := bson.M{
+			"$addToSet": bson.M{StorageKeyDeviceDeploymentContinued: state},
+		}
+		res, err := collDevs.UpdateMany(ctx, query, update)
+		if err != nil {
+			return count, err
+		}
+		count += int(res.ModifiedCount)
+	}
+
+	return count, nil
+}
+
 func (db *DataStoreMongo) UpdateDeviceDeploymentLogAvailability(ctx context.Context,
 	deviceID string, deploymentID string, log bool) error {
 
@@ -1938,6 +2142,7 @@ func (db *DataStoreMongo) GetDevicesListForDeployment(ctx context.Context,
 						model.DeviceDeploymentStatusNoArtifact,
 						model.DeviceDeploymentStatusAlreadyInst,
 						model.DeviceDeploymentStatusDecommissioned,
+						model.DeviceDeploymentStatusExpired,
 					},
 				}},
 			})
@@ -2043,6 +2248,7 @@ func (db *DataStoreMongo) GetDeviceDeploymentsForDevice(ctx context.Context,
 						model.DeviceDeploymentStatusNoArtifact,
 						model.DeviceDeploymentStatusAlreadyInst,
 						model.DeviceDeploymentStatusDecommissioned,
+						model.DeviceDeploymentStatusExpired,
 					},
 				}},
 			})
@@ -2157,6 +2363,40 @@ func (db *DataStoreMongo) AbortDeviceDeployments(ctx context.Context,
 	return nil
 }
 
+// ExpireDeviceDeployments marks the device deployments which did not start yet
+// as expired.
+func (db *DataStoreMongo) ExpireDeviceDeployments(ctx context.Context,
+	deploymentId string) error {
+
+	if len(deploymentId) == 0 {
+		return ErrStorageInvalidID
+	}
+
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collDevs := database.Collection(CollectionDevices)
+	selector := bson.M{
+		StorageKeyDeviceDeploymentDeploymentID: deploymentId,
+		StorageKeyDeviceDeploymentActive:       true,
+		StorageKeyDeviceDeploymentStatus:       model.DeviceDeploymentStatusPending,
+		StorageKeyDeviceDeploymentDeleted: bson.D{
+			{Key: "$exists", Value: false},
+		},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			StorageKeyDeviceDeploymentStatus: model.DeviceDeploymentStatusExpired,
+			StorageKeyDeviceDeploymentActive: false,
+		},
+	}
+
+	if _, err := collDevs.UpdateMany(ctx, selector, update); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (db *DataStoreMongo) DeleteDeviceDeploymentsHistory(ctx context.Context,
 	deviceID string) error {
 	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
@@ -2248,6 +2488,38 @@ func (db *DataStoreMongo) GetDeviceDeployment(ctx context.Context, deploymentID
 	return &dd, nil
 }
 
+// FindDeviceDeploymentIDs returns the IDs of the given deployments which
+// were offered to the device, including the deleted device deployments.
+func (db *DataStoreMongo) FindDeviceDeploymentIDs(
+	ctx context.Context,
+	deviceID string,
+	deploymentIDs []string,
+) ([]string, error) {
+	if len(deploymentIDs) == 0 {
+		return []string{}, nil
+	}
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collDevs := database.Collection(CollectionDevices)
+
+	filter := bson.D{
+		{Key: StorageKeyDeviceDeploymentDeviceId, Value: deviceID},
+		{Key: StorageKeyDeviceDeploymentDeploymentID, Value: bson.D{
+			{Key: "$in", Value: deploymentIDs},
+		}},
+	}
+	values, err := collDevs.Distinct(ctx, StorageKeyDeviceDeploymentDeploymentID, filter)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
 func (db *DataStoreMongo) GetDeviceDeployments(
 	ctx context.Context,
 	skip int,
@@ -2912,6 +3184,69 @@ func (db *DataStoreMongo) FindNewerActiveDeployment(ctx context.Context,
 	return deployment, nil
 }
 
+// FindExpiredActiveDeployments returns the active deployments whose end
+// time is past, the oldest first.
+func (db *DataStoreMongo) FindExpiredActiveDeployments(
+	ctx context.Context,
+	now time.Time,
+) ([]*model.Deployment, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	c := database.Collection(CollectionDeployments)
+
+	findQuery := bson.D{
+		{Key: StorageKeyDeploymentActive, Value: true},
+		{Key: StorageKeyDeploymentEndTime, Value: bson.M{"$lte": now}},
+	}
+	findOptions := mopts.Find().
+		SetSort(bson.D{{Key: StorageKeyDeploymentCreated, Value: 1}}).
+		SetProjection(bson.M{
+			StorageKeyDeploymentConstructorChecksum: 0,
+			StorageKeyDeploymentDeviceList:          0,
+		})
+	cursor, err := c.Find(ctx, findQuery, findOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get deployments")
+	}
+	defer cursor.Close(ctx)
+
+	var deployments []*model.Deployment
+	if err = cursor.All(ctx, &deployments); err != nil {
+		return nil, errors.Wrap(err, "failed to get deployments")
+	}
+	return deployments, nil
+}
+
+// FindActiveContinuousDeployments returns the active continuous deployments,
+// the most recent first.
+func (db *DataStoreMongo) FindActiveContinuousDeployments(
+	ctx context.Context,
+) ([]*model.Deployment, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	c := database.Collection(CollectionDeployments)
+
+	findQuery := bson.D{
+		{Key: StorageKeyDeploymentActive, Value: true},
+		{Key: StorageKeyDeploymentContinuous, Value: true},
+	}
+	findOptions := mopts.Find().
+		SetSort(bson.D{{Key: StorageKeyDeploymentCreated, Value: -1}}).
+		SetProjection(bson.M{
+			StorageKeyDeploymentConstructorChecksum: 0,
+			StorageKeyDeploymentDeviceList:          0,
+		})
+	cursor, err := c.Find(ctx, findQuery, findOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get deployments")
+	}
+	defer cursor.Close(ctx)
+
+	var deployments []*model.Deployment
+	if err = cursor.All(ctx, &deployments); err != nil {
+		return nil, errors.Wrap(err, "failed to get deployments")
+	}
+	return deployments, nil
+}
+
 // SetDeploymentStatus simply sets the status field
 // optionally sets 'finished time' if deployment is indeed finished
 func (db *DataStoreMongo) SetDeploymentStatus(
@@ -3040,6 +3375,35 @@ func (db *DataStoreMongo) ExistByArtifactId(ctx context.Context,
 	return true, nil
 }
 
+// FindLatestDeploymentByArtifactId returns the most recently created
+// deployment using the given artifact, or nil if the artifact was never used
+func (db *DataStoreMongo) FindLatestDeploymentByArtifactId(ctx context.Context,
+	id string) (*model.Deployment, error) {
+
+	if len(id) == 0 {
+		return nil, ErrStorageInvalidID
+	}
+
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collDpl := database.Collection(CollectionDeployments)
+
+	query := bson.D{
+		{Key: StorageKeyDeploymentArtifacts, Value: id},
+	}
+	findOptions := mopts.FindOne().
+		SetSort(bson.D{{Key: StorageKeyDeploymentCreated, Value: -1}})
+
+	var deployment *model.Deployment
+	if err := collDpl.FindOne(ctx, query, findOptions).Decode(&deployment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
 // Per-tenant storage settings
 func (db *DataStoreMongo) GetStorageSettings(ctx context.Context) (*model.StorageSettings, error) {
 	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
@@ -3104,6 +3468,73 @@ func (db *DataStoreMongo) UpdateDeploymentsWithArtifactName(
 	return err
 }
 
+// SetDeploymentPhaseResumed sets the time the given phase of an active
+// deployment was resumed; resuming a phase more than once is a noop.
+func (db *DataStoreMongo) SetDeploymentPhaseResumed(
+	ctx context.Context,
+	deploymentID string,
+	phaseID string,
+	resumed time.Time,
+) error {
+	if len(deploymentID) == 0 || len(phaseID) == 0 {
+		return ErrStorageInvalidID
+	}
+
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collDpl := database.Collection(CollectionDeployments)
+
+	query := bson.D{
+		{Key: StorageKeyId, Value: deploymentID},
+		{Key: StorageKeyDeploymentActive, Value: true},
+		{Key: StorageKeyDeploymentPhases, Value: bson.M{
+			"$elemMatch": bson.M{
+				"id":         phaseID,
+				"resumed_ts": bson.M{"$exists": false},
+			},
+		}},
+	}
+	update := bson.M{
+		mongoOpSet: bson.M{
+			StorageKeyDeploymentPhaseResumed: resumed,
+		},
+	}
+
+	res, err := collDpl.UpdateOne(ctx, query, update)
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return ErrStorageNotFound
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) SetDeploymentAbortReason(
+	ctx context.Context,
+	deploymentID string,
+	reason string,
+) error {
+	if len(deploymentID) == 0 {
+		return ErrStorageInvalidID
+	}
+
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collDpl := database.Collection(CollectionDeployments)
+
+	update := bson.M{
+		mongoOpSet: bson.M{
+			StorageKeyDeploymentAbortReason: reason,
+		},
+	}
+
+	res, err := collDpl.UpdateOne(ctx, bson.M{StorageKeyId: deploymentID}, update)
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return ErrStorageNotFound
+	}
+	return nil
+}
+
 func (db *DataStoreMongo) GetDeploymentIDsByArtifactNames(
 	ctx context.Context,
 	artifactNames []string,
@@ -0,0 +1,106 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	mstore "github.com/mendersoftware/mender-server/pkg/store"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+// ExportDeviceDeploymentsHistory returns an iterator over the device
+// deployments matching the filter, sorted by device and creation time,
+// joined with the name of their deployment.
+func (db *DataStoreMongo) ExportDeviceDeploymentsHistory(
+	ctx context.Context,
+	filter model.DeviceDeploymentHistoryFilter,
+) (store.Iterator[model.DeviceDeploymentHistoryRecord], error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collDevs := database.Collection(CollectionDevices)
+
+	match := bson.D{}
+	if filter.DeviceIDs != nil {
+		match = append(match, bson.E{
+			Key:   StorageKeyDeviceDeploymentDeviceId,
+			Value: bson.D{{Key: "$in", Value: filter.DeviceIDs}},
+		})
+	}
+	created := bson.D{}
+	if filter.CreatedAfter != nil {
+		created = append(created, bson.E{Key: "$gte", Value: *filter.CreatedAfter})
+	}
+	if filter.CreatedBefore != nil {
+		created = append(created, bson.E{Key: "$lt", Value: *filter.CreatedBefore})
+	}
+	if len(created) > 0 {
+		match = append(match, bson.E{
+			Key:   StorageKeyDeviceDeploymentCreated,
+			Value: created,
+		})
+	}
+
+	pipeline := []bson.D{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: StorageKeyDeviceDeploymentDeviceId, Value: 1},
+			{Key: StorageKeyDeviceDeploymentCreated, Value: 1},
+		}}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: CollectionDeployments},
+			{Key: "localField", Value: StorageKeyDeviceDeploymentDeploymentID},
+			{Key: "foreignField", Value: StorageKeyId},
+			{Key: "as", Value: "deployment"},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: StorageKeyId, Value: 0},
+			{Key: StorageKeyDeviceDeploymentDeviceId, Value: 1},
+			{Key: StorageKeyDeviceDeploymentDeploymentID, Value: 1},
+			{Key: "deployment_name", Value: bson.D{{
+				Key:   "$arrayElemAt",
+				Value: bson.A{"$deployment." + StorageKeyDeploymentName, 0},
+			}}},
+			{Key: "artifact_name", Value: bson.D{{
+				Key: "$ifNull",
+				Value: bson.A{
+					"$" + StorageKeyDeviceDeploymentAssignedImage + "." +
+						StorageKeyImageName,
+					bson.D{{
+						Key: "$arrayElemAt",
+						Value: bson.A{
+							"$deployment." + StorageKeyDeploymentArtifactName, 0,
+						},
+					}},
+				},
+			}}},
+			{Key: StorageKeyDeviceDeploymentStatus, Value: 1},
+			{Key: StorageKeyDeviceDeploymentSubState, Value: 1},
+			{Key: StorageKeyDeviceDeploymentCreated, Value: 1},
+			{Key: StorageKeyDeviceDeploymentStarted, Value: 1},
+			{Key: StorageKeyDeviceDeploymentFinished, Value: 1},
+		}}},
+	}
+	cur, err := collDevs.Aggregate(ctx, pipeline,
+		mopts.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	return IteratorFromCursor[model.DeviceDeploymentHistoryRecord](cur), nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+func TestExportDeviceDeploymentsHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestExportDeviceDeploymentsHistory in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	deployment, err := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "Q1 update",
+		ArtifactName: "release-1",
+		Devices:      []string{"device-1", "device-2"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ds.InsertDeployment(ctx, deployment))
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(24 * time.Hour)
+	t2 := t1.Add(24 * time.Hour)
+	newDeviceDeployment := func(
+		deviceID string,
+		created time.Time,
+		status model.DeviceDeploymentStatus,
+	) *model.DeviceDeployment {
+		dd := model.NewDeviceDeployment(deviceID, deployment.Id)
+		dd.Created = &created
+		dd.Status = status
+		return dd
+	}
+	installed := newDeviceDeployment("device-1", t0, model.DeviceDeploymentStatusSuccess)
+	installed.Finished = &t1
+	installed.Image = &model.Image{
+		Id: "image",
+		ArtifactMeta: &model.ArtifactMeta{
+			Name: "release-1-rpi4",
+		},
+	}
+	failed := newDeviceDeployment("device-2", t0, model.DeviceDeploymentStatusFailure)
+	failed.SubState = "ArtifactInstall"
+	retried := newDeviceDeployment("device-2", t2, model.DeviceDeploymentStatusPending)
+	assert.NoError(t, ds.InsertMany(ctx, retried, failed, installed))
+
+	history := func(filter model.DeviceDeploymentHistoryFilter) []model.DeviceDeploymentHistoryRecord {
+		it, err := ds.ExportDeviceDeploymentsHistory(ctx, filter)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		defer it.Close(ctx)
+		records := []model.DeviceDeploymentHistoryRecord{}
+		for {
+			next, err := it.Next(ctx)
+			assert.NoError(t, err)
+			if !next {
+				return records
+			}
+			var record model.DeviceDeploymentHistoryRecord
+			assert.NoError(t, it.Decode(&record))
+			records = append(records, record)
+		}
+	}
+
+	assert.Equal(t, []model.DeviceDeploymentHistoryRecord{{
+		DeviceID:       "device-1",
+		DeploymentID:   deployment.Id,
+		DeploymentName: "Q1 update",
+		ArtifactName:   "release-1-rpi4",
+		Status:         model.DeviceDeploymentStatusSuccess,
+		Created:        &t0,
+		Finished:       &t1,
+	}, {
+		DeviceID:       "device-2",
+		DeploymentID:   deployment.Id,
+		DeploymentName: "Q1 update",
+		ArtifactName:   "release-1",
+		Status:         model.DeviceDeploymentStatusFailure,
+		SubState:       "ArtifactInstall",
+		Created:        &t0,
+	}, {
+		DeviceID:       "device-2",
+		DeploymentID:   deployment.Id,
+		DeploymentName: "Q1 update",
+		ArtifactName:   "release-1",
+		Status:         model.DeviceDeploymentStatusPending,
+		Created:        &t2,
+	}}, history(model.DeviceDeploymentHistoryFilter{}))
+
+	records := history(model.DeviceDeploymentHistoryFilter{
+		DeviceIDs:     []string{"device-2"},
+		CreatedBefore: &t1,
+	})
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, model.DeviceDeploymentStatusFailure, records[0].Status)
+	}
+
+	records = history(model.DeviceDeploymentHistoryFilter{CreatedAfter: &t1})
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, model.DeviceDeploymentStatusPending, records[0].Status)
+	}
+
+	assert.Empty(t, history(model.DeviceDeploymentHistoryFilter{
+		DeviceIDs: []string{},
+	}))
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	mstore "github.com/mendersoftware/mender-server/pkg/store"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+const (
+	CollectionDownloadUsage        = "download_usage"
+	CollectionDownloadUsageMonthly = "download_usage_monthly"
+
+	StorageKeyDownloadUsageMonth        = "month"
+	StorageKeyDownloadUsageDeploymentID = "deployment_id"
+	StorageKeyDownloadUsageDeviceID     = "device_id"
+	StorageKeyDownloadUsageServed       = "served"
+	StorageKeyDownloadUsageDownloaded   = "downloaded"
+
+	StorageKeyDeploymentServedSize     = "statistics.served_size"
+	StorageKeyDeploymentDownloadedSize = "statistics.downloaded_size"
+
+	IndexNameDownloadUsage         = "month_deployment_device"
+	IndexNameDownloadUsageDeviceID = "device_month"
+)
+
+var (
+	// 1.2.19
+	IndexDownloadUsage = mongo.IndexModel{
+		Keys: bson.D{
+			{Key: StorageKeyDownloadUsageMonth, Value: 1},
+			{Key: StorageKeyDownloadUsageDeploymentID, Value: 1},
+			{Key: StorageKeyDownloadUsageDeviceID, Value: 1},
+		},
+		Options: mopts.Index().
+			SetName(IndexNameDownloadUsage).
+			SetUnique(true),
+	}
+	IndexDownloadUsageDeviceID = mongo.IndexModel{
+		Keys: bson.D{
+			{Key: StorageKeyDownloadUsageDeviceID, Value: 1},
+			{Key: StorageKeyDownloadUsageMonth, Value: 1},
+		},
+		Options: mopts.Index().
+			SetName(IndexNameDownloadUsageDeviceID),
+	}
+)
+
+// IncrementDownloadUsage adds the usage to the usage of the device and the
+// deployment, and to the total of the month.
+func (db *DataStoreMongo) IncrementDownloadUsage(
+	ctx context.Context,
+	usage model.DownloadUsage,
+) error {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	update := bson.M{
+		"$inc": bson.M{
+			StorageKeyDownloadUsageServed:     usage.Served,
+			StorageKeyDownloadUsageDownloaded: usage.Downloaded,
+		},
+	}
+	opts := mopts.Update().SetUpsert(true)
+
+	_, err := database.Collection(CollectionDownloadUsage).UpdateOne(ctx, bson.D{
+		{Key: StorageKeyDownloadUsageMonth, Value: usage.Month},
+		{Key: StorageKeyDownloadUsageDeploymentID, Value: usage.DeploymentID},
+		{Key: StorageKeyDownloadUsageDeviceID, Value: usage.DeviceID},
+	}, update, opts)
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection(CollectionDownloadUsageMonthly).UpdateOne(ctx,
+		bson.M{"_id": usage.Month}, update, opts)
+	return err
+}
+
+// GetDownloadUsage sums up the download usage matching the filter.
+func (db *DataStoreMongo) GetDownloadUsage(
+	ctx context.Context,
+	filter model.DownloadUsageFilter,
+) (*model.DownloadUsage, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	usage := &model.DownloadUsage{
+		Month:        filter.Month,
+		DeploymentID: filter.DeploymentID,
+		DeviceID:     filter.DeviceID,
+	}
+	var totals struct {
+		Served     int64 `bson:"served"`
+		Downloaded int64 `bson:"downloaded"`
+	}
+
+	if filter.DeploymentID == "" && filter.DeviceID == "" {
+		err := database.Collection(CollectionDownloadUsageMonthly).
+			FindOne(ctx, bson.M{"_id": filter.Month}).
+			Decode(&totals)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+	} else {
+		match := bson.M{StorageKeyDownloadUsageMonth: filter.Month}
+		if filter.DeploymentID != "" {
+			match[StorageKeyDownloadUsageDeploymentID] = filter.DeploymentID
+		}
+		if filter.DeviceID != "" {
+			match[StorageKeyDownloadUsageDeviceID] = filter.DeviceID
+		}
+		cursor, err := database.Collection(CollectionDownloadUsage).
+			Aggregate(ctx, []bson.M{
+				{"$match": match},
+				{"$group": bson.M{
+					"_id": nil,
+					StorageKeyDownloadUsageServed: bson.M{
+						"$sum": "$" + StorageKeyDownloadUsageServed,
+					},
+					StorageKeyDownloadUsageDownloaded: bson.M{
+						"$sum": "$" + StorageKeyDownloadUsageDownloaded,
+					},
+				}},
+			})
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+		if cursor.Next(ctx) {
+			if err := cursor.Decode(&totals); err != nil {
+				return nil, err
+			}
+		} else if err := cursor.Err(); err != nil {
+			return nil, err
+		}
+	}
+	usage.Served = totals.Served
+	usage.Downloaded = totals.Downloaded
+	return usage, nil
+}
+
+// IncrementDeploymentDownloadSize adds to the bytes of the artifacts served
+// to, and downloaded by, the devices of the deployment.
+func (db *DataStoreMongo) IncrementDeploymentDownloadSize(
+	ctx context.Context,
+	deploymentID string,
+	served, downloaded int64,
+) error {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionDeployments)
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": deploymentID}, bson.M{
+		"$inc": bson.M{
+			StorageKeyDeploymentServedSize:     served,
+			StorageKeyDeploymentDownloadedSize: downloaded,
+		},
+	})
+	return err
+}
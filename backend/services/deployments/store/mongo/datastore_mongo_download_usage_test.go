@@ -0,0 +1,139 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+func TestDownloadUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestDownloadUsage in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	const (
+		month        = "2024-05"
+		deploymentID = "a108ae14-bb4e-455f-9b40-2ef4bab97bb7"
+	)
+	usage, err := ds.GetDownloadUsage(ctx, model.DownloadUsageFilter{Month: month})
+	assert.NoError(t, err)
+	assert.Equal(t, &model.DownloadUsage{Month: month}, usage)
+
+	for _, increment := range []model.DownloadUsage{{
+		Month:        month,
+		DeploymentID: deploymentID,
+		DeviceID:     "device-1",
+		Served:       100,
+	}, {
+		Month:        month,
+		DeploymentID: deploymentID,
+		DeviceID:     "device-1",
+		Downloaded:   100,
+	}, {
+		Month:        month,
+		DeploymentID: deploymentID,
+		DeviceID:     "device-2",
+		Served:       100,
+	}, {
+		Month:  month,
+		Served: 50,
+	}, {
+		Month:        "2024-06",
+		DeploymentID: deploymentID,
+		DeviceID:     "device-1",
+		Served:       100,
+	}} {
+		assert.NoError(t, ds.IncrementDownloadUsage(ctx, increment))
+	}
+
+	usage, err = ds.GetDownloadUsage(ctx, model.DownloadUsageFilter{Month: month})
+	assert.NoError(t, err)
+	assert.Equal(t, &model.DownloadUsage{
+		Month:      month,
+		Served:     250,
+		Downloaded: 100,
+	}, usage)
+
+	usage, err = ds.GetDownloadUsage(ctx, model.DownloadUsageFilter{
+		Month:        month,
+		DeploymentID: deploymentID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &model.DownloadUsage{
+		Month:        month,
+		DeploymentID: deploymentID,
+		Served:       200,
+		Downloaded:   100,
+	}, usage)
+
+	usage, err = ds.GetDownloadUsage(ctx, model.DownloadUsageFilter{
+		Month:    month,
+		DeviceID: "device-2",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &model.DownloadUsage{
+		Month:    month,
+		DeviceID: "device-2",
+		Served:   100,
+	}, usage)
+
+	usage, err = ds.GetDownloadUsage(ctx, model.DownloadUsageFilter{
+		Month:    month,
+		DeviceID: "device-3",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &model.DownloadUsage{
+		Month:    month,
+		DeviceID: "device-3",
+	}, usage)
+}
+
+func TestIncrementDeploymentDownloadSize(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestIncrementDeploymentDownloadSize in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	deployment, err := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+		Name:         "foo",
+		ArtifactName: "bar",
+		Devices:      []string{"device"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ds.InsertDeployment(ctx, deployment))
+
+	assert.NoError(t, ds.IncrementDeploymentDownloadSize(ctx, deployment.Id, 100, 0))
+	assert.NoError(t, ds.IncrementDeploymentDownloadSize(ctx, deployment.Id, 0, 100))
+	assert.NoError(t, ds.IncrementDeploymentDownloadSize(ctx, deployment.Id, 100, 0))
+
+	found, err := ds.FindDeploymentByID(ctx, deployment.Id)
+	assert.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, int64(200), found.Statistics.ServedSize)
+		assert.Equal(t, int64(100), found.Statistics.DownloadedSize)
+	}
+}
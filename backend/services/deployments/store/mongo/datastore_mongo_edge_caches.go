@@ -0,0 +1,137 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	mstore "github.com/mendersoftware/mender-server/pkg/store"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+const (
+	CollectionEdgeCaches = "edge_caches"
+
+	StorageKeyEdgeCachePriority  = "priority"
+	StorageKeyEdgeCacheCreated   = "created"
+	StorageKeyEdgeCacheArtifacts = "artifacts"
+	StorageKeyEdgeCacheReported  = "reported"
+)
+
+func (db *DataStoreMongo) InsertEdgeCache(
+	ctx context.Context,
+	cache *model.EdgeCache,
+) error {
+	if cache == nil {
+		return ErrStorageInvalidInput
+	}
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionEdgeCaches)
+
+	_, err := collection.InsertOne(ctx, cache)
+	return err
+}
+
+// ListEdgeCaches returns the cache endpoints ordered by priority.
+func (db *DataStoreMongo) ListEdgeCaches(ctx context.Context) ([]model.EdgeCache, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionEdgeCaches)
+
+	opts := mopts.Find().
+		SetSort(bson.D{
+			{Key: StorageKeyEdgeCachePriority, Value: 1},
+			{Key: StorageKeyEdgeCacheCreated, Value: 1},
+		})
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	caches := []model.EdgeCache{}
+	if err := cursor.All(ctx, &caches); err != nil {
+		return nil, err
+	}
+	return caches, nil
+}
+
+// GetEdgeCache returns the cache endpoint, or nil if not found.
+func (db *DataStoreMongo) GetEdgeCache(
+	ctx context.Context,
+	id string,
+) (*model.EdgeCache, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionEdgeCaches)
+
+	cache := new(model.EdgeCache)
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(cache)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+func (db *DataStoreMongo) DeleteEdgeCache(ctx context.Context, id string) error {
+	if len(id) == 0 {
+		return ErrStorageInvalidID
+	}
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionEdgeCaches)
+
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	} else if res.DeletedCount == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// SetEdgeCacheArtifacts replaces the artifacts held by the cache.
+func (db *DataStoreMongo) SetEdgeCacheArtifacts(
+	ctx context.Context,
+	id string,
+	artifacts []model.EdgeCacheArtifact,
+	reported time.Time,
+) error {
+	if len(id) == 0 {
+		return ErrStorageInvalidID
+	}
+	if artifacts == nil {
+		artifacts = []model.EdgeCacheArtifact{}
+	}
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionEdgeCaches)
+
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		mongoOpSet: bson.M{
+			StorageKeyEdgeCacheArtifacts: artifacts,
+			StorageKeyEdgeCacheReported:  reported,
+		},
+	})
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
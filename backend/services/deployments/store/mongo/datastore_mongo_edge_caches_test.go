@@ -0,0 +1,95 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+func TestEdgeCaches(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestEdgeCaches in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	caches, err := ds.ListEdgeCaches(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, caches)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	site := &model.EdgeCache{
+		ID:  "1",
+		URL: "https://cache.plant-7.local",
+		Attribute: model.EdgeCacheAttribute{
+			Scope: "inventory",
+			Name:  "site",
+			Value: "plant-7",
+		},
+		Secret:    "secret1",
+		Artifacts: []model.EdgeCacheArtifact{},
+		Created:   now.Add(time.Minute),
+	}
+	region := &model.EdgeCache{
+		ID:  "2",
+		URL: "https://cache.region.local",
+		Attribute: model.EdgeCacheAttribute{
+			Scope: "inventory",
+			Name:  "region",
+			Value: "north",
+		},
+		Priority:  10,
+		Secret:    "secret2",
+		Artifacts: []model.EdgeCacheArtifact{},
+		Created:   now,
+	}
+	assert.NoError(t, ds.InsertEdgeCache(ctx, region))
+	assert.NoError(t, ds.InsertEdgeCache(ctx, site))
+
+	caches, err = ds.ListEdgeCaches(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.EdgeCache{*site, *region}, caches)
+
+	artifacts := []model.EdgeCacheArtifact{{ID: "artifact", Synced: now}}
+	assert.NoError(t, ds.SetEdgeCacheArtifacts(ctx, site.ID, artifacts, now))
+	assert.Equal(t, store.ErrNotFound,
+		ds.SetEdgeCacheArtifacts(ctx, "3", artifacts, now))
+
+	cache, err := ds.GetEdgeCache(ctx, site.ID)
+	assert.NoError(t, err)
+	site.Artifacts = artifacts
+	site.Reported = &now
+	assert.Equal(t, site, cache)
+
+	cache, err = ds.GetEdgeCache(ctx, "3")
+	assert.NoError(t, err)
+	assert.Nil(t, cache)
+
+	assert.NoError(t, ds.DeleteEdgeCache(ctx, site.ID))
+	assert.Equal(t, store.ErrNotFound, ds.DeleteEdgeCache(ctx, site.ID))
+
+	caches, err = ds.ListEdgeCaches(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.EdgeCache{*region}, caches)
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package mongo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	mstore "github.com/mendersoftware/mender-server/pkg/store"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+// DeploymentLogCompressThreshold is the size of the encoded log messages
+// above which the deployment logs are stored compressed.
+const DeploymentLogCompressThreshold = 16 * 1024
+
+// deploymentLogDocument is the device deployment log as stored in the
+// database: large logs keep the messages compressed.
+type deploymentLogDocument struct {
+	DeviceID           string             `bson:"deviceid"`
+	DeploymentID       string             `bson:"deploymentid"`
+	Messages           []model.LogMessage `bson:"messages,omitempty"`
+	MessagesCompressed []byte             `bson:"messages_gz,omitempty"`
+	ExpireAt           *time.Time         `bson:"expire_at,omitempty"`
+	Attempt            int                `bson:"attempt,omitempty"`
+}
+
+// DeploymentLog returns the log with the messages decompressed.
+func (doc deploymentLogDocument) DeploymentLog() (*model.DeploymentLog, error) {
+	log := &model.DeploymentLog{
+		DeviceID:     doc.DeviceID,
+		DeploymentID: doc.DeploymentID,
+		Messages:     doc.Messages,
+		ExpireAt:     doc.ExpireAt,
+		Attempt:      doc.Attempt,
+	}
+	if len(doc.MessagesCompressed) > 0 {
+		zr, err := gzip.NewReader(bytes.NewReader(doc.MessagesCompressed))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decompress the deployment log")
+		}
+		defer zr.Close()
+		if err := json.NewDecoder(zr).Decode(&log.Messages); err != nil {
+			return nil, errors.Wrap(err, "failed to decompress the deployment log")
+		}
+	}
+	return log, nil
+}
+
+// deploymentLogQuery selects the log of the failed attempt of the device;
+// the log of the current attempt has no attempt number.
+func deploymentLogQuery(deviceID, deploymentID string, attempt int) bson.D {
+	query := bson.D{
+		{Key: StorageKeyDeviceDeploymentDeviceId, Value: deviceID},
+		{Key: StorageKeyDeviceDeploymentDeploymentID, Value: deploymentID},
+	}
+	if attempt > 0 {
+		return append(query, bson.E{
+			Key: StorageKeyDeviceDeploymentLogAttempt, Value: attempt,
+		})
+	}
+	return append(query, bson.E{
+		Key: StorageKeyDeviceDeploymentLogAttempt, Value: bson.M{"$exists": false},
+	})
+}
+
+// deploymentLogUpdate returns the fields to set and unset when saving the
+// log, compressing the messages of the large logs.
+func deploymentLogUpdate(log model.DeploymentLog) (bson.M, bson.M, error) {
+	set := bson.M{}
+	unset := bson.M{}
+	if log.ExpireAt != nil {
+		set[StorageKeyDeviceDeploymentLogExpireAt] = log.ExpireAt
+	} else {
+		unset[StorageKeyDeviceDeploymentLogExpireAt] = ""
+	}
+
+	encoded, err := json.Marshal(log.Messages)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(encoded) <= DeploymentLogCompressThreshold {
+		set[StorageKeyDeviceDeploymentLogMessages] = log.Messages
+		unset[StorageKeyDeviceDeploymentLogMessagesCompressed] = ""
+		return set, unset, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, bytes.NewReader(encoded)); err != nil {
+		return nil, nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, nil, err
+	}
+	set[StorageKeyDeviceDeploymentLogMessagesCompressed] = buf.Bytes()
+	unset[StorageKeyDeviceDeploymentLogMessages] = ""
+	return set, unset, nil
+}
+
+// SearchDeviceDeploymentLogs returns the page of the device deployment logs
+// of the deployment with messages matching the search, sorted by device ID,
+// and the total number of matching logs. The compressed logs are matched
+// after decompression.
+func (db *DataStoreMongo) SearchDeviceDeploymentLogs(
+	ctx context.Context,
+	search model.DeploymentLogSearch,
+) ([]model.DeploymentLogMatch, int, error) {
+	if err := search.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collLogs := database.Collection(CollectionDeviceDeploymentLogs)
+
+	query := bson.D{
+		{Key: StorageKeyDeviceDeploymentDeploymentID, Value: search.DeploymentID},
+		{Key: StorageKeyDeviceDeploymentLogAttempt, Value: bson.M{"$exists": false}},
+		{Key: "$or", Value: bson.A{
+			bson.M{
+				StorageKeyDeviceDeploymentLogMessages + ".message": primitive.Regex{
+					Pattern: search.Pattern(),
+				},
+			},
+			bson.M{
+				StorageKeyDeviceDeploymentLogMessagesCompressed: bson.M{"$exists": true},
+			},
+		}},
+	}
+	findOptions := mopts.Find().
+		SetSort(bson.D{{Key: StorageKeyDeviceDeploymentDeviceId, Value: 1}})
+	cursor, err := collLogs.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	skip := (search.Page - 1) * search.PerPage
+	matches := []model.DeploymentLogMatch{}
+	count := 0
+	for cursor.Next(ctx) {
+		var doc deploymentLogDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, 0, err
+		}
+		log, err := doc.DeploymentLog()
+		if err != nil {
+			return nil, 0, err
+		}
+		messages := search.Match(*log)
+		if len(messages) == 0 {
+			continue
+		}
+		if count >= skip && len(matches) < search.PerPage {
+			matches = append(matches, model.DeploymentLogMatch{
+				DeviceID: log.DeviceID,
+				Messages: messages,
+			})
+		}
+		count++
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, 0, err
+	}
+	return matches, count, nil
+}
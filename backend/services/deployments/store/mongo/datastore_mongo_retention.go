@@ -0,0 +1,68 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	mstore "github.com/mendersoftware/mender-server/pkg/store"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+const (
+	// the retention settings are stored in the settings collection
+	StorageKeyArtifactRetentionSettingsID = "artifact_retention"
+)
+
+func (db *DataStoreMongo) GetArtifactRetentionSettings(
+	ctx context.Context,
+) (*model.ArtifactRetentionSettings, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionStorageSettings)
+
+	settings := new(model.ArtifactRetentionSettings)
+	query := bson.M{
+		"_id": StorageKeyArtifactRetentionSettingsID,
+	}
+	if err := collection.FindOne(ctx, query).Decode(settings); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (db *DataStoreMongo) SetArtifactRetentionSettings(
+	ctx context.Context,
+	settings *model.ArtifactRetentionSettings,
+) error {
+	if settings == nil {
+		return ErrStorageInvalidInput
+	}
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionStorageSettings)
+
+	filter := bson.M{
+		"_id": StorageKeyArtifactRetentionSettingsID,
+	}
+	// replace the document so that the disabled rules are removed
+	_, err := collection.ReplaceOne(ctx, filter, settings, mopts.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+)
+
+func TestArtifactRetentionSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestArtifactRetentionSettings in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	settings, err := ds.GetArtifactRetentionSettings(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, settings)
+
+	expected := &model.ArtifactRetentionSettings{
+		KeepLastPerDeviceType: 5,
+		DeleteUnusedAfterDays: 30,
+	}
+	assert.NoError(t, ds.SetArtifactRetentionSettings(ctx, expected))
+
+	// the disabled rules are removed
+	expected = &model.ArtifactRetentionSettings{
+		KeepLastPerRelease: 2,
+	}
+	assert.NoError(t, ds.SetArtifactRetentionSettings(ctx, expected))
+
+	settings, err = ds.GetArtifactRetentionSettings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, settings)
+
+	assert.ErrorIs(t, ds.SetArtifactRetentionSettings(ctx, nil), ErrStorageInvalidInput)
+}
+
+func TestFindLatestDeploymentByArtifactId(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestFindLatestDeploymentByArtifactId in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	const artifactID = "f826484e-1157-4109-af21-304e6d711560"
+	now := time.Now().Round(time.Second).UTC()
+	var latest *model.Deployment
+	for i := 0; i < 3; i++ {
+		deployment, err := model.NewDeploymentFromConstructor(&model.DeploymentConstructor{
+			Name:         "foo",
+			ArtifactName: "bar",
+			AllDevices:   true,
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		created := now.Add(time.Duration(i) * time.Hour)
+		deployment.Created = &created
+		deployment.Artifacts = []string{artifactID}
+		if !assert.NoError(t, ds.InsertDeployment(ctx, deployment)) {
+			return
+		}
+		latest = deployment
+	}
+
+	deployment, err := ds.FindLatestDeploymentByArtifactId(ctx, artifactID)
+	if assert.NoError(t, err) && assert.NotNil(t, deployment) {
+		assert.Equal(t, latest.Id, deployment.Id)
+	}
+
+	deployment, err = ds.FindLatestDeploymentByArtifactId(ctx,
+		"30b3e62c-9ec2-4312-a7fa-cff24cc7397a")
+	assert.NoError(t, err)
+	assert.Nil(t, deployment)
+
+	_, err = ds.FindLatestDeploymentByArtifactId(ctx, "")
+	assert.ErrorIs(t, err, ErrStorageInvalidID)
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	mstore "github.com/mendersoftware/mender-server/pkg/store"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+const (
+	CollectionSigningKeys = "signing_keys"
+
+	StorageKeySigningKeyCreated = "created"
+
+	// the signature settings are stored in the settings collection
+	StorageKeyArtifactSignatureSettingsID = "artifact_signature"
+)
+
+func (db *DataStoreMongo) InsertSigningKey(
+	ctx context.Context,
+	key *model.SigningKey,
+) error {
+	if key == nil {
+		return ErrStorageInvalidInput
+	}
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collKeys := database.Collection(CollectionSigningKeys)
+
+	_, err := collKeys.InsertOne(ctx, key)
+	return err
+}
+
+func (db *DataStoreMongo) ListSigningKeys(ctx context.Context) ([]model.SigningKey, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collKeys := database.Collection(CollectionSigningKeys)
+
+	opts := mopts.Find().
+		SetSort(bson.D{{Key: StorageKeySigningKeyCreated, Value: 1}})
+	cursor, err := collKeys.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	keys := []model.SigningKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (db *DataStoreMongo) DeleteSigningKey(ctx context.Context, id string) error {
+	if len(id) == 0 {
+		return ErrStorageInvalidID
+	}
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collKeys := database.Collection(CollectionSigningKeys)
+
+	res, err := collKeys.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	} else if res.DeletedCount == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) GetArtifactSignatureSettings(
+	ctx context.Context,
+) (*model.ArtifactSignatureSettings, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionStorageSettings)
+
+	settings := new(model.ArtifactSignatureSettings)
+	query := bson.M{
+		"_id": StorageKeyArtifactSignatureSettingsID,
+	}
+	if err := collection.FindOne(ctx, query).Decode(settings); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (db *DataStoreMongo) SetArtifactSignatureSettings(
+	ctx context.Context,
+	settings *model.ArtifactSignatureSettings,
+) error {
+	if settings == nil {
+		return ErrStorageInvalidInput
+	}
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionStorageSettings)
+
+	filter := bson.M{
+		"_id": StorageKeyArtifactSignatureSettingsID,
+	}
+	update := bson.M{
+		mongoOpSet: settings,
+	}
+	_, err := collection.UpdateOne(ctx, filter, update, mopts.Update().SetUpsert(true))
+	return err
+}
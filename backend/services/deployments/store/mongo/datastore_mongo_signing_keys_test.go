@@ -0,0 +1,96 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+func TestSigningKeys(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestSigningKeys in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	keys, err := ds.ListSigningKeys(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	first := &model.SigningKey{
+		ID:        "1",
+		Name:      "first",
+		Type:      model.SigningKeyTypeRSA,
+		PublicKey: "key1",
+		Created:   now,
+	}
+	second := &model.SigningKey{
+		ID:        "2",
+		Type:      model.SigningKeyTypeED25519,
+		PublicKey: "key2",
+		Created:   now.Add(time.Minute),
+	}
+	assert.NoError(t, ds.InsertSigningKey(ctx, second))
+	assert.NoError(t, ds.InsertSigningKey(ctx, first))
+
+	keys, err = ds.ListSigningKeys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.SigningKey{*first, *second}, keys)
+
+	assert.NoError(t, ds.DeleteSigningKey(ctx, first.ID))
+	assert.Equal(t, store.ErrNotFound, ds.DeleteSigningKey(ctx, first.ID))
+
+	keys, err = ds.ListSigningKeys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.SigningKey{*second}, keys)
+}
+
+func TestArtifactSignatureSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestArtifactSignatureSettings in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	settings, err := ds.GetArtifactSignatureSettings(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, settings)
+
+	expected := &model.ArtifactSignatureSettings{
+		Policy: model.SignaturePolicyRequire,
+	}
+	assert.NoError(t, ds.SetArtifactSignatureSettings(ctx, expected))
+
+	settings, err = ds.GetArtifactSignatureSettings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, settings)
+
+	// storage settings share the collection
+	storageSettings, err := ds.GetStorageSettings(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, storageSettings)
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	mstore "github.com/mendersoftware/mender-server/pkg/store"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+const (
+	CollectionStorageMigrations = "storage_migrations"
+
+	StorageKeyStorageMigrationStatus  = "status"
+	StorageKeyStorageMigrationCreated = "created"
+)
+
+func (db *DataStoreMongo) InsertStorageMigration(
+	ctx context.Context,
+	migration *model.StorageMigration,
+) error {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionStorageMigrations)
+
+	_, err := collection.InsertOne(ctx, migration)
+	return err
+}
+
+// GetStorageMigration returns the storage migration, or nil if not found.
+func (db *DataStoreMongo) GetStorageMigration(
+	ctx context.Context,
+	id string,
+) (*model.StorageMigration, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionStorageMigrations)
+
+	migration := new(model.StorageMigration)
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(migration)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return migration, nil
+}
+
+// FindUnfinishedStorageMigration returns the oldest pending, running or
+// switching storage migration, or nil if there is none.
+func (db *DataStoreMongo) FindUnfinishedStorageMigration(
+	ctx context.Context,
+) (*model.StorageMigration, error) {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionStorageMigrations)
+
+	migration := new(model.StorageMigration)
+	err := collection.FindOne(ctx, bson.M{
+		StorageKeyStorageMigrationStatus: bson.M{"$in": []string{
+			model.StorageMigrationStatusPending,
+			model.StorageMigrationStatusRunning,
+			model.StorageMigrationStatusSwitching,
+		}},
+	}, mopts.FindOne().
+		SetSort(bson.M{StorageKeyStorageMigrationCreated: 1}),
+	).Decode(migration)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return migration, nil
+}
+
+// UpdateStorageMigration saves the status and the progress of the storage
+// migration.
+func (db *DataStoreMongo) UpdateStorageMigration(
+	ctx context.Context,
+	migration *model.StorageMigration,
+) error {
+	database := db.client.Database(mstore.DbFromContext(ctx, DatabaseName))
+	collection := database.Collection(CollectionStorageMigrations)
+
+	res, err := collection.ReplaceOne(ctx, bson.M{"_id": migration.ID}, migration)
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
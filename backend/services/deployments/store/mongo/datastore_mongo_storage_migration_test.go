@@ -0,0 +1,99 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/services/deployments/model"
+	"github.com/mendersoftware/mender-server/services/deployments/store"
+)
+
+func TestStorageMigrations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestStorageMigrations in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	migration, err := ds.GetStorageMigration(ctx, "first")
+	assert.NoError(t, err)
+	assert.Nil(t, migration)
+	migration, err = ds.FindUnfinishedStorageMigration(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, migration)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	later := now.Add(time.Minute)
+	completed := &model.StorageMigration{
+		ID:       "completed",
+		Mode:     model.StorageMigrationModeMirror,
+		Status:   model.StorageMigrationStatusCompleted,
+		Created:  &now,
+		Updated:  &now,
+		Finished: &now,
+	}
+	first := &model.StorageMigration{
+		ID:   "first",
+		Mode: model.StorageMigrationModeMigrate,
+		Target: &model.StorageSettings{
+			Type:   model.StorageTypeAzure,
+			Bucket: "container",
+			Key:    "account",
+			Secret: "account-key",
+		},
+		Status:  model.StorageMigrationStatusPending,
+		Created: &now,
+		Updated: &now,
+	}
+	second := &model.StorageMigration{
+		ID:      "second",
+		Mode:    model.StorageMigrationModeMirror,
+		Status:  model.StorageMigrationStatusPending,
+		Created: &later,
+		Updated: &later,
+	}
+	for _, m := range []*model.StorageMigration{completed, second, first} {
+		assert.NoError(t, ds.InsertStorageMigration(ctx, m))
+	}
+
+	migration, err = ds.FindUnfinishedStorageMigration(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, first, migration)
+
+	first.Status = model.StorageMigrationStatusRunning
+	first.Artifacts = 2
+	first.Copied = 1
+	first.LastArtifactID = "artifact"
+	assert.NoError(t, ds.UpdateStorageMigration(ctx, first))
+	migration, err = ds.GetStorageMigration(ctx, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, first, migration)
+
+	first.Status = model.StorageMigrationStatusFailed
+	assert.NoError(t, ds.UpdateStorageMigration(ctx, first))
+	migration, err = ds.FindUnfinishedStorageMigration(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, second, migration)
+
+	assert.Equal(t, store.ErrNotFound,
+		ds.UpdateStorageMigration(ctx, &model.StorageMigration{ID: "missing"}))
+}
@@ -86,6 +86,47 @@ func TestInsertUploadIntent(t *testing.T) {
 	}
 }
 
+func TestFindUploadIntent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestFindUploadIntent in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+	link := &model.UploadLink{
+		Link: model.Link{
+			Expire: time.Now().Add(time.Minute).Round(time.Second).UTC(),
+		},
+		ArtifactID:        uuid.New().String(),
+		IssuedAt:          time.Now().Round(time.Second).UTC(),
+		MultipartUploadID: "upload-id",
+	}
+	ctxTenant := identity.WithContext(ctx, &identity.Identity{
+		Tenant: "123456789012345678901234",
+	})
+	err := ds.InsertUploadIntent(ctxTenant, link)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	actual, err := ds.FindUploadIntent(ctxTenant, link.ArtifactID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, link.ArtifactID, actual.ArtifactID)
+		assert.Equal(t, link.MultipartUploadID, actual.MultipartUploadID)
+		assert.Equal(t, link.TenantID, actual.TenantID)
+	}
+
+	_, err = ds.FindUploadIntent(ctxTenant, uuid.New().String())
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	ctxOther := identity.WithContext(ctx, &identity.Identity{
+		Tenant: "000000000000000000000000",
+	})
+	_, err = ds.FindUploadIntent(ctxOther, link.ArtifactID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
 func TestFindUploadLinks(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping TestFindUploadLinks in short mode.")
@@ -430,6 +471,50 @@ func TestFindNewerActiveDeployments(t *testing.T) {
 	}
 }
 
+func TestFindActiveContinuousDeployments(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestFindActiveContinuousDeployments in short mode.")
+	}
+	db.Wipe()
+	store := NewDataStoreMongoWithClient(db.Client())
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	newDeployment := func(id string, continuous bool, created time.Time,
+		status model.DeploymentStatus) interface{} {
+		return &model.Deployment{
+			DeploymentConstructor: &model.DeploymentConstructor{
+				Name:         "baseline",
+				ArtifactName: "release-1",
+				Continuous:   continuous,
+			},
+			Id:      id,
+			Created: &created,
+			Status:  status,
+		}
+	}
+	collDep := db.Client().Database(DatabaseName).Collection(CollectionDeployments)
+	_, err := collDep.InsertMany(ctx, []interface{}{
+		newDeployment("a108ae14-bb4e-455f-9b40-2ef4bab97bb7", true,
+			now.Add(-time.Hour), model.DeploymentStatusInProgress),
+		newDeployment("d1804903-5caa-4a73-a3ae-0efcc3205405", true,
+			now, model.DeploymentStatusPending),
+		newDeployment("b532b01a-9313-404f-8d19-e7fcbe5cc347", true,
+			now, model.DeploymentStatusFinished),
+		newDeployment("f826484e-1157-4109-af21-304e6d711560", false,
+			now, model.DeploymentStatusPending),
+	})
+	assert.NoError(t, err)
+
+	deployments, err := store.FindActiveContinuousDeployments(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, deployments, 2) {
+		assert.Equal(t, "d1804903-5caa-4a73-a3ae-0efcc3205405", deployments[0].Id)
+		assert.Equal(t, "a108ae14-bb4e-455f-9b40-2ef4bab97bb7", deployments[1].Id)
+		assert.True(t, deployments[0].IsContinuous())
+	}
+}
+
 func TestInsertDeploymentConflict(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping TestInsertDeploymentConflict in short mode.")
@@ -2676,3 +2761,130 @@ func TestFindDeployments(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDeploymentPhaseResumed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestSetDeploymentPhaseResumed in short mode.")
+	}
+
+	now := time.Now()
+	newDeployment := func(id string, active bool) *model.Deployment {
+		return &model.Deployment{
+			Id:      id,
+			Created: &now,
+			Active:  active,
+			Status:  model.DeploymentStatusPending,
+			DeploymentConstructor: &model.DeploymentConstructor{
+				Name:         "name",
+				ArtifactName: "artifact",
+				Phases: []model.DeploymentPhase{
+					{Id: "phase-1", BatchSize: 10},
+					{Id: "phase-2", PauseUntilResumed: true},
+				},
+			},
+		}
+	}
+	testCases := map[string]struct {
+		deployment   *model.Deployment
+		deploymentID string
+		phaseID      string
+
+		err error
+	}{
+		"ok": {
+			deployment:   newDeployment("d50eda0d-2cea-4de1-8d42-9cd3e7e86711", true),
+			deploymentID: "d50eda0d-2cea-4de1-8d42-9cd3e7e86711",
+			phaseID:      "phase-2",
+		},
+		"error, deployment finished": {
+			deployment: func() *model.Deployment {
+				d := newDeployment("d50eda0d-2cea-4de1-8d42-9cd3e7e86712", false)
+				d.Status = model.DeploymentStatusFinished
+				return d
+			}(),
+			deploymentID: "d50eda0d-2cea-4de1-8d42-9cd3e7e86712",
+			phaseID:      "phase-2",
+			err:          ErrStorageNotFound,
+		},
+		"error, phase not found": {
+			deployment:   newDeployment("d50eda0d-2cea-4de1-8d42-9cd3e7e86713", true),
+			deploymentID: "d50eda0d-2cea-4de1-8d42-9cd3e7e86713",
+			phaseID:      "phase-3",
+			err:          ErrStorageNotFound,
+		},
+		"error, invalid id": {
+			phaseID: "phase-1",
+			err:     ErrStorageInvalidID,
+		},
+	}
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if tc.deployment != nil {
+				err := ds.InsertDeployment(ctx, tc.deployment)
+				assert.NoError(t, err)
+			}
+
+			err := ds.SetDeploymentPhaseResumed(ctx, tc.deploymentID, tc.phaseID, now)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+
+			deployment, err := ds.FindDeploymentByID(ctx, tc.deploymentID)
+			assert.NoError(t, err)
+			for _, phase := range deployment.Phases {
+				if phase.Id == tc.phaseID {
+					if assert.NotNil(t, phase.Resumed) {
+						assert.WithinDuration(t, now, *phase.Resumed, time.Second)
+					}
+				} else {
+					assert.Nil(t, phase.Resumed)
+				}
+			}
+
+			// resuming the phase twice is not possible
+			err = ds.SetDeploymentPhaseResumed(ctx, tc.deploymentID, tc.phaseID, now)
+			assert.ErrorIs(t, err, ErrStorageNotFound)
+		})
+	}
+}
+
+func TestSetDeploymentAbortReason(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestSetDeploymentAbortReason in short mode.")
+	}
+
+	now := time.Now()
+	deployment := &model.Deployment{
+		Id:      "1b2fb4a2-7c83-4f2e-9aa9-a43d4fe8ef1a",
+		Created: &now,
+		Status:  model.DeploymentStatusInProgress,
+		DeploymentConstructor: &model.DeploymentConstructor{
+			Name:         "name",
+			ArtifactName: "artifact",
+		},
+	}
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+	err := ds.InsertDeployment(ctx, deployment)
+	assert.NoError(t, err)
+
+	err = ds.SetDeploymentAbortReason(ctx, deployment.Id, "too many failures")
+	assert.NoError(t, err)
+
+	found, err := ds.FindDeploymentByID(ctx, deployment.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "too many failures", found.AbortReason)
+
+	err = ds.SetDeploymentAbortReason(ctx, "d50eda0d-2cea-4de1-8d42-9cd3e7e86799", "reason")
+	assert.ErrorIs(t, err, ErrStorageNotFound)
+
+	err = ds.SetDeploymentAbortReason(ctx, "", "reason")
+	assert.ErrorIs(t, err, ErrStorageInvalidID)
+}
@@ -16,6 +16,7 @@ package mongo
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -300,3 +301,262 @@ func TestGetDeviceDeploymentLog(t *testing.T) {
 	}
 	db.Wipe()
 }
+
+func TestDeviceDeploymentLogCompressed(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping TestDeviceDeploymentLogCompressed in short mode.")
+	}
+
+	const deploymentID = "30b3e62c-9ec2-4312-a7fa-cff24cc7397a"
+	messages := make([]model.LogMessage, 0, 1000)
+	for i := 0; i < cap(messages); i++ {
+		messages = append(messages, model.LogMessage{
+			Level:     "notice",
+			Message:   fmt.Sprintf("writing block %d of the root filesystem", i),
+			Timestamp: parseTime(t, "2006-01-02T15:04:05-07:00"),
+		})
+	}
+	expireAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+
+	db.Wipe()
+	client := db.Client()
+	store := NewDataStoreMongoWithClient(client)
+	ctx := context.Background()
+
+	err := store.SaveDeviceDeploymentLog(ctx, model.DeploymentLog{
+		DeviceID:     "123",
+		DeploymentID: deploymentID,
+		Messages:     messages,
+		ExpireAt:     &expireAt,
+	})
+	assert.NoError(t, err)
+
+	var raw bson.M
+	err = client.Database(DatabaseName).
+		Collection(CollectionDeviceDeploymentLogs).
+		FindOne(ctx, bson.M{StorageKeyDeviceDeploymentDeviceId: "123"}).
+		Decode(&raw)
+	assert.NoError(t, err)
+	assert.NotContains(t, raw, StorageKeyDeviceDeploymentLogMessages)
+	assert.Contains(t, raw, StorageKeyDeviceDeploymentLogMessagesCompressed)
+	assert.Contains(t, raw, StorageKeyDeviceDeploymentLogExpireAt)
+
+	dlog, err := store.GetDeviceDeploymentLog(ctx, "123", deploymentID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, dlog) {
+		assert.Len(t, dlog.Messages, len(messages))
+		for i, m := range messages {
+			assert.True(t, m.Timestamp.Equal(*dlog.Messages[i].Timestamp))
+			assert.Equal(t, m.Message, dlog.Messages[i].Message)
+		}
+		if assert.NotNil(t, dlog.ExpireAt) {
+			assert.True(t, expireAt.Equal(*dlog.ExpireAt))
+		}
+	}
+
+	// saving a small log over the compressed one drops the compressed messages
+	err = store.SaveDeviceDeploymentLog(ctx, model.DeploymentLog{
+		DeviceID:     "123",
+		DeploymentID: deploymentID,
+		Messages:     messages[:1],
+	})
+	assert.NoError(t, err)
+	dlog, err = store.GetDeviceDeploymentLog(ctx, "123", deploymentID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, dlog) {
+		assert.Len(t, dlog.Messages, 1)
+	}
+	db.Wipe()
+}
+
+func TestDeviceDeploymentAttemptLog(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping TestDeviceDeploymentAttemptLog in short mode.")
+	}
+
+	const deploymentID = "30b3e62c-9ec2-4312-a7fa-cff24cc7397a"
+	message := func(msg string) []model.LogMessage {
+		return []model.LogMessage{{
+			Level:     "error",
+			Message:   msg,
+			Timestamp: parseTime(t, "2006-01-02T15:04:05-07:00"),
+		}}
+	}
+
+	db.Wipe()
+	store := NewDataStoreMongoWithClient(db.Client())
+	ctx := context.Background()
+
+	for attempt, msg := range []string{"current", "first attempt", "second attempt"} {
+		err := store.SaveDeviceDeploymentLog(ctx, model.DeploymentLog{
+			DeviceID:     "123",
+			DeploymentID: deploymentID,
+			Messages:     message(msg),
+			Attempt:      attempt,
+		})
+		assert.NoError(t, err)
+	}
+
+	dlog, err := store.GetDeviceDeploymentLog(ctx, "123", deploymentID)
+	if assert.NoError(t, err) && assert.NotNil(t, dlog) {
+		assert.Equal(t, "current", dlog.Messages[0].Message)
+		assert.Zero(t, dlog.Attempt)
+	}
+	dlog, err = store.GetDeviceDeploymentAttemptLog(ctx, "123", deploymentID, 2)
+	if assert.NoError(t, err) && assert.NotNil(t, dlog) {
+		assert.Equal(t, "second attempt", dlog.Messages[0].Message)
+		assert.Equal(t, 2, dlog.Attempt)
+	}
+	dlog, err = store.GetDeviceDeploymentAttemptLog(ctx, "123", deploymentID, 3)
+	assert.NoError(t, err)
+	assert.Nil(t, dlog)
+
+	// the search only matches the logs of the current attempts
+	matches, count, err := store.SearchDeviceDeploymentLogs(ctx, model.DeploymentLogSearch{
+		DeploymentID: deploymentID,
+		Query:        "attempt",
+		Page:         1,
+		PerPage:      10,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+	assert.Zero(t, count)
+
+	// archiving the current log leaves no current log behind
+	archived, err := store.ArchiveDeviceDeploymentLog(ctx, "123", deploymentID, 3)
+	assert.NoError(t, err)
+	assert.True(t, archived)
+	dlog, err = store.GetDeviceDeploymentLog(ctx, "123", deploymentID)
+	assert.NoError(t, err)
+	assert.Nil(t, dlog)
+	dlog, err = store.GetDeviceDeploymentAttemptLog(ctx, "123", deploymentID, 3)
+	if assert.NoError(t, err) && assert.NotNil(t, dlog) {
+		assert.Equal(t, "current", dlog.Messages[0].Message)
+	}
+	archived, err = store.ArchiveDeviceDeploymentLog(ctx, "123", deploymentID, 4)
+	assert.NoError(t, err)
+	assert.False(t, archived)
+	db.Wipe()
+}
+
+func TestSearchDeviceDeploymentLogs(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping TestSearchDeviceDeploymentLogs in short mode.")
+	}
+
+	const deploymentID = "30b3e62c-9ec2-4312-a7fa-cff24cc7397a"
+	timestamp := parseTime(t, "2006-01-02T15:04:05-07:00")
+	failed := model.LogMessage{
+		Level:     "error",
+		Message:   "installation failed",
+		Timestamp: timestamp,
+	}
+	large := make([]model.LogMessage, 0, 1000)
+	for i := 0; i < cap(large); i++ {
+		large = append(large, model.LogMessage{
+			Level:     "notice",
+			Message:   fmt.Sprintf("writing block %d of the root filesystem", i),
+			Timestamp: timestamp,
+		})
+	}
+	logs := []model.DeploymentLog{
+		{
+			DeviceID:     "1",
+			DeploymentID: deploymentID,
+			Messages:     []model.LogMessage{failed},
+		},
+		{
+			DeviceID:     "2",
+			DeploymentID: deploymentID,
+			Messages: []model.LogMessage{{
+				Level:     "notice",
+				Message:   "installation completed",
+				Timestamp: timestamp,
+			}},
+		},
+		{
+			// compressed
+			DeviceID:     "3",
+			DeploymentID: deploymentID,
+			Messages:     append(large, failed),
+		},
+		{
+			DeviceID:     "4",
+			DeploymentID: "30b3e62c-9ec2-4312-a7fa-cff24cc7397b",
+			Messages:     []model.LogMessage{failed},
+		},
+	}
+
+	db.Wipe()
+	store := NewDataStoreMongoWithClient(db.Client())
+	ctx := context.Background()
+	for _, dlog := range logs {
+		err := store.SaveDeviceDeploymentLog(ctx, dlog)
+		assert.NoError(t, err)
+	}
+
+	testCases := map[string]struct {
+		Search model.DeploymentLogSearch
+
+		Devices []string
+		Count   int
+	}{
+		"ok": {
+			Search: model.DeploymentLogSearch{
+				DeploymentID: deploymentID,
+				Query:        "failed",
+				Page:         1,
+				PerPage:      20,
+			},
+			Devices: []string{"1", "3"},
+			Count:   2,
+		},
+		"ok, regex": {
+			Search: model.DeploymentLogSearch{
+				DeploymentID: deploymentID,
+				Query:        "^installation (failed|completed)$",
+				Regex:        true,
+				Page:         1,
+				PerPage:      20,
+			},
+			Devices: []string{"1", "2", "3"},
+			Count:   3,
+		},
+		"ok, second page": {
+			Search: model.DeploymentLogSearch{
+				DeploymentID: deploymentID,
+				Query:        "failed",
+				Page:         2,
+				PerPage:      1,
+			},
+			Devices: []string{"3"},
+			Count:   2,
+		},
+		"ok, no match": {
+			Search: model.DeploymentLogSearch{
+				DeploymentID: deploymentID,
+				Query:        "reboot",
+				Page:         1,
+				PerPage:      20,
+			},
+			Devices: []string{},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			matches, count, err := store.SearchDeviceDeploymentLogs(ctx, tc.Search)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Count, count)
+			devices := make([]string, len(matches))
+			for i, match := range matches {
+				devices[i] = match.DeviceID
+				assert.NotEmpty(t, match.Messages)
+			}
+			assert.Equal(t, tc.Devices, devices)
+		})
+	}
+	db.Wipe()
+}
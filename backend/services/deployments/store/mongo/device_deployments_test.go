@@ -644,6 +644,7 @@ func TestAggregateDeviceDeploymentByStatus(t *testing.T) {
 				model.DeviceDeploymentStatusAlreadyInstStr:        0,
 				model.DeviceDeploymentStatusAbortedStr:            0,
 				model.DeviceDeploymentStatusDecommissionedStr:     0,
+				model.DeviceDeploymentStatusExpiredStr:            0,
 				model.DeviceDeploymentStatusPauseBeforeCommitStr:  0,
 				model.DeviceDeploymentStatusPauseBeforeInstallStr: 0,
 				model.DeviceDeploymentStatusPauseBeforeRebootStr:  0,
@@ -673,6 +674,7 @@ func TestAggregateDeviceDeploymentByStatus(t *testing.T) {
 				model.DeviceDeploymentStatusAlreadyInstStr:        0,
 				model.DeviceDeploymentStatusAbortedStr:            0,
 				model.DeviceDeploymentStatusDecommissionedStr:     0,
+				model.DeviceDeploymentStatusExpiredStr:            0,
 				model.DeviceDeploymentStatusPauseBeforeCommitStr:  1,
 				model.DeviceDeploymentStatusPauseBeforeInstallStr: 1,
 				model.DeviceDeploymentStatusPauseBeforeRebootStr:  1,
@@ -1440,3 +1442,182 @@ func TestSaveDeviceDeploymentRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestExpireDeviceDeployments(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("skipping TestExpireDeviceDeployments in short mode.")
+	}
+
+	const deploymentID = "30b3e62c-9ec2-4312-a7fa-cff24cc7397a"
+
+	pending := model.NewDeviceDeployment("456", deploymentID)
+	downloading := model.NewDeviceDeployment("567", deploymentID)
+	downloading.Status = model.DeviceDeploymentStatusDownloading
+
+	db.Wipe()
+	client := db.Client()
+	store := NewDataStoreMongoWithClient(client)
+	ctx := context.Background()
+
+	err := store.InsertMany(ctx, pending, downloading)
+	assert.NoError(t, err)
+
+	err = store.ExpireDeviceDeployments(ctx, "")
+	assert.EqualError(t, err, ErrStorageInvalidID.Error())
+
+	err = store.ExpireDeviceDeployments(ctx, deploymentID)
+	assert.NoError(t, err)
+
+	dd, err := store.GetDeviceDeployment(ctx, deploymentID, "456", false)
+	assert.NoError(t, err)
+	assert.Equal(t, model.DeviceDeploymentStatusExpired, dd.Status)
+	assert.False(t, dd.Active)
+
+	// devices already updating are not affected
+	dd, err = store.GetDeviceDeployment(ctx, deploymentID, "567", false)
+	assert.NoError(t, err)
+	assert.Equal(t, model.DeviceDeploymentStatusDownloading, dd.Status)
+	assert.True(t, dd.Active)
+}
+
+func TestRetryDeviceDeployment(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestRetryDeviceDeployment in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	const deploymentID = "30b3e62c-9ec2-4312-a7fa-cff24cc7397a"
+	started := time.Now().Add(-time.Hour).Round(time.Second).UTC()
+	dd := model.NewDeviceDeployment("device", deploymentID)
+	dd.Retries = 2
+	err := ds.InsertMany(ctx, dd)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = ds.UpdateDeviceDeploymentStatus(ctx, "device", deploymentID,
+		model.DeviceDeploymentState{
+			Status:   model.DeviceDeploymentStatusInstalling,
+			SubState: "install",
+		}, model.DeviceDeploymentStatusPending)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	finished := time.Now().Round(time.Second).UTC()
+	retryAfter := finished.Add(time.Minute)
+	attempt := model.DeviceDeploymentAttempt{
+		Started:  &started,
+		Finished: &finished,
+		Status:   model.DeviceDeploymentStatusFailure,
+		SubState: "install",
+	}
+	old, err := ds.RetryDeviceDeployment(ctx, "device", deploymentID, attempt, &retryAfter)
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.DeviceDeploymentStatusInstalling, old)
+	}
+
+	actual, err := ds.GetDeviceDeployment(ctx, deploymentID, "device", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.DeviceDeploymentStatusPending, actual.Status)
+		assert.True(t, actual.Active)
+		assert.Nil(t, actual.Started)
+		assert.Empty(t, actual.SubState)
+		assert.Equal(t, []model.DeviceDeploymentAttempt{attempt}, actual.Attempts)
+		if assert.NotNil(t, actual.RetryAfter) {
+			assert.True(t, retryAfter.Equal(*actual.RetryAfter))
+		}
+	}
+
+	_, err = ds.RetryDeviceDeployment(ctx, "other", deploymentID, attempt, nil)
+	assert.ErrorIs(t, err, ErrStorageNotFound)
+}
+
+func TestFindDeviceDeploymentIDs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestFindDeviceDeploymentIDs in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	const (
+		offeredID = "30b3e62c-9ec2-4312-a7fa-cff24cc7397a"
+		deletedID = "d2e6c5b4-1f6a-4a0e-9d3b-6c7e8f9a0b1c"
+		otherID   = "5c6d7e8f-9a0b-4c1d-8e2f-3a4b5c6d7e8f"
+	)
+	now := time.Now()
+	deleted := model.NewDeviceDeployment("device", deletedID)
+	deleted.Deleted = &now
+	err := ds.InsertMany(ctx,
+		model.NewDeviceDeployment("device", offeredID),
+		deleted,
+		model.NewDeviceDeployment("other", otherID),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ids, err := ds.FindDeviceDeploymentIDs(ctx, "device",
+		[]string{offeredID, deletedID, otherID})
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []string{offeredID, deletedID}, ids)
+	}
+
+	ids, err = ds.FindDeviceDeploymentIDs(ctx, "device", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestContinueDeviceDeployments(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestContinueDeviceDeployments in short mode.")
+	}
+	db.Wipe()
+
+	ctx := context.Background()
+	ds := NewDataStoreMongoWithClient(db.Client())
+
+	const deploymentID = "30b3e62c-9ec2-4312-a7fa-cff24cc7397a"
+	statuses := map[string]model.DeviceDeploymentStatus{
+		"install": model.DeviceDeploymentStatusPauseBeforeInstall,
+		"reboot":  model.DeviceDeploymentStatusPauseBeforeReboot,
+		"busy":    model.DeviceDeploymentStatusInstalling,
+	}
+	for devID, status := range statuses {
+		dd := model.NewDeviceDeployment(devID, deploymentID)
+		dd.Status = status
+		err := ds.InsertMany(ctx, dd)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	count, err := ds.ContinueDeviceDeployments(ctx, deploymentID, []string{"install"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, count)
+	}
+	count, err = ds.ContinueDeviceDeployments(ctx, deploymentID, nil)
+	if assert.NoError(t, err) {
+		// the device paused before install was already continued
+		assert.Equal(t, 1, count)
+	}
+
+	for devID, continued := range map[string][]string{
+		"install": {model.UpdateControlStateArtifactInstall},
+		"reboot":  {model.UpdateControlStateArtifactReboot},
+		"busy":    nil,
+	} {
+		actual, err := ds.GetDeviceDeployment(ctx, deploymentID, devID, false)
+		if assert.NoError(t, err) {
+			assert.Equal(t, continued, actual.ContinuedStates, devID)
+		}
+	}
+
+	_, err = ds.ContinueDeviceDeployments(ctx, "", nil)
+	assert.ErrorIs(t, err, ErrStorageInvalidID)
+}
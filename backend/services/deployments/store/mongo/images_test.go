@@ -348,6 +348,63 @@ func TestArtifactUpdate(t *testing.T) {
 	assert.Equal(t, img.ImageMeta.Description, imgFromDB.ImageMeta.Description)
 }
 
+func TestSetImageContents(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestSetImageContents in short mode.")
+	}
+
+	img := &model.Image{
+		Id: "a3719bc6-62af-4d65-b781-effa992048ba",
+		ImageMeta: &model.ImageMeta{
+			Description: "description",
+		},
+		ArtifactMeta: &model.ArtifactMeta{
+			Name:                  "app1-v1.0",
+			DeviceTypesCompatible: []string{"foo"},
+			Updates:               []model.Update{},
+		},
+		Modified: timePtr("2010-09-22T22:00:00+00:00"),
+	}
+	contents := &model.ArtifactContents{
+		Payloads: []model.PayloadContents{{
+			Type:     "app",
+			MetaData: map[string]interface{}{"container": "app"},
+			Files: []model.PayloadFile{{
+				Name:     "update.tar",
+				Size:     10240,
+				Checksum: "checksum",
+				Entries: []model.PayloadEntry{{
+					Path:     "etc/app.conf",
+					Type:     model.PayloadEntryTypeFile,
+					Size:     3,
+					Mode:     0644,
+					Checksum: "checksum",
+				}},
+			}},
+		}},
+	}
+
+	ctx := context.Background()
+	db.Wipe()
+	store := NewDataStoreMongoWithClient(db.Client())
+	err := store.InsertImage(ctx, img)
+	assert.NoError(t, err)
+
+	err = store.SetImageContents(ctx, img.Id, contents)
+	assert.NoError(t, err)
+
+	imgFromDB, err := store.FindImageByID(ctx, img.Id)
+	assert.NoError(t, err)
+	if assert.NotNil(t, imgFromDB) {
+		assert.Equal(t, contents, imgFromDB.Contents)
+		// the contents do not modify the artifact
+		assert.True(t, img.Modified.Equal(*imgFromDB.Modified))
+	}
+
+	err = store.SetImageContents(ctx, "6d4f6e27-c3bb-438c-ad9c-d9de30e59d80", contents)
+	assert.Equal(t, ErrStorageNotFound, err)
+}
+
 func makeTestImages(t *testing.T) (*DataStoreMongo, []*model.Image) {
 	inputImgs := []*model.Image{
 		{
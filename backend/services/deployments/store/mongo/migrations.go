@@ -25,8 +25,8 @@ import (
 )
 
 const (
-	DbVersion        = "1.2.17"
-	DbMinimumVersion = "1.2.17"
+	DbVersion        = "1.2.19"
+	DbMinimumVersion = "1.2.19"
 	DbName           = "deployment_service"
 )
 
@@ -143,6 +143,14 @@ func MigrateSingle(ctx context.Context,
 			client: client,
 			db:     db,
 		},
+		&migration_1_2_18{
+			client: client,
+			db:     db,
+		},
+		&migration_1_2_19{
+			client: client,
+			db:     db,
+		},
 	}
 
 	err = m.Apply(ctx, *ver, migrations)
@@ -0,0 +1,127 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/rest.utils"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+func (i *DevAuthApiHandlers) GetAutoAcceptRulesHandler(c *gin.Context) {
+	rules, err := i.app.GetAutoAcceptRules(c.Request.Context())
+	if err != nil {
+		rest.RenderInternalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+func (i *DevAuthApiHandlers) GetAutoAcceptRuleHandler(c *gin.Context) {
+	rule, err := i.app.GetAutoAcceptRule(c.Request.Context(), c.Param("id"))
+	switch err {
+	case nil:
+		c.JSON(http.StatusOK, rule)
+	case store.ErrAutoAcceptRuleNotFound:
+		rest.RenderError(c, http.StatusNotFound, err)
+	default:
+		rest.RenderInternalError(c, err)
+	}
+}
+
+func (i *DevAuthApiHandlers) AddAutoAcceptRuleHandler(c *gin.Context) {
+	var req model.AutoAcceptRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err = errors.Wrap(err, "failed to decode auto-accept rule")
+		rest.RenderError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		err = errors.Wrap(err, "invalid auto-accept rule")
+		rest.RenderError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	rule, err := i.app.AddAutoAcceptRule(c.Request.Context(), req)
+	if err != nil {
+		rest.RenderInternalError(c, err)
+		return
+	}
+	c.Header("Location", "rules/"+rule.Id)
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (i *DevAuthApiHandlers) DeleteAutoAcceptRuleHandler(c *gin.Context) {
+	err := i.app.DeleteAutoAcceptRule(c.Request.Context(), c.Param("id"))
+	switch err {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case store.ErrAutoAcceptRuleNotFound:
+		rest.RenderError(c, http.StatusNotFound, err)
+	default:
+		rest.RenderInternalError(c, err)
+	}
+}
+
+// GetAutoAcceptAuditHandler lists the devices accepted by the auto-accept
+// rules, newest first.
+func (i *DevAuthApiHandlers) GetAutoAcceptAuditHandler(c *gin.Context) {
+	page, perPage, err := rest.ParsePagingParameters(c.Request)
+	if err != nil {
+		rest.RenderError(c, http.StatusBadRequest, err)
+		return
+	}
+	filter := model.AutoAcceptAuditFilter{
+		RuleId:   c.Query("rule_id"),
+		DeviceId: c.Query("device_id"),
+	}
+
+	skip := (page - 1) * perPage
+	limit := perPage + 1
+	entries, err := i.app.GetAutoAcceptAudit(
+		c.Request.Context(), filter, uint(skip), uint(limit),
+	)
+	if err != nil {
+		rest.RenderInternalError(c, err)
+		return
+	}
+
+	num := int64(len(entries))
+	hasNext := false
+	if num > perPage {
+		hasNext = true
+		num -= 1
+	}
+
+	hints := rest.NewPagingHints().
+		SetPage(page).
+		SetPerPage(perPage).
+		SetHasNext(hasNext)
+	links, err := rest.MakePagingHeaders(c.Request, hints)
+	if err != nil {
+		rest.RenderError(c, http.StatusBadRequest, err)
+		return
+	}
+	for _, l := range links {
+		c.Writer.Header().Add("Link", l)
+	}
+	c.JSON(http.StatusOK, entries[:num])
+}
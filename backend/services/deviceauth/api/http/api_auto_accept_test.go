@@ -0,0 +1,302 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+	mtest "github.com/mendersoftware/mender-server/services/deviceauth/utils/testing"
+)
+
+func TestApiDevAuthSubmitAuthReqSourceIP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+
+		depth int
+		xff   string
+
+		sourceIP string
+	}{{
+		desc:     "ok, address appended by the proxy",
+		depth:    1,
+		xff:      "192.168.1.1, 10.1.2.3",
+		sourceIP: "10.1.2.3",
+	}, {
+		desc:     "ok, address appended by the second proxy",
+		depth:    2,
+		xff:      "192.168.1.1, 10.1.2.3",
+		sourceIP: "192.168.1.1",
+	}, {
+		desc:  "ok, no forwarded address",
+		depth: 1,
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			da := &mocks.App{}
+			defer da.AssertExpectations(t)
+			da.On("SubmitAuthRequest",
+				mtest.ContextMatcher(),
+				mock.MatchedBy(func(r *model.AuthReq) bool {
+					if tc.sourceIP == "" {
+						return r.SourceIP == nil
+					}
+					return r.SourceIP.String() == tc.sourceIP
+				})).
+				Return("token", nil)
+
+			apih := NewRouter(da, nil, SetClientIPProxyDepth(tc.depth))
+
+			req := makeAuthReq(map[string]interface{}{
+				"id_data": `{"mac":"00:11:22:33:44:55"}`,
+				"pubkey":  mtest.LoadPubKeyStr("testdata/public.pem"),
+			}, mtest.LoadPrivKey("testdata/private.pem"), "", t)
+			if tc.xff != "" {
+				req.Header.Set("X-Forwarded-For", tc.xff)
+			}
+			runTestRequest(t, apih, req, http.StatusOK, "token")
+		})
+	}
+}
+
+func TestApiV2AutoAcceptRules(t *testing.T) {
+	t.Parallel()
+
+	const baseURL = "http://localhost/api/management/v2/devauth/auto_accept"
+
+	ruleReq := model.AutoAcceptRuleReq{
+		Name:        "factory",
+		SourceCIDRs: []string{"10.0.0.0/8"},
+		MaxAccepts:  10,
+	}
+	rule := model.NewAutoAcceptRule(ruleReq)
+	ruleJSON, _ := json.Marshal(rule)
+	audit := model.AutoAcceptAudit{
+		Id:        "b5c98eda-ce7e-4b6e-9e12-3578e9d243f7",
+		RuleId:    rule.Id,
+		DeviceId:  "c7acb245-3ef4-4922-b28d-2d1ab8bfb930",
+		AuthSetId: "0e4b3ac1-f2bc-4b2e-a6ab-cae26b5e8d23",
+		SourceIP:  "10.1.2.3",
+	}
+	auditJSON, _ := json.Marshal(audit)
+
+	testCases := []struct {
+		desc string
+
+		req *http.Request
+
+		method string
+		args   []interface{}
+		ret    []interface{}
+
+		code int
+		body string
+	}{{
+		desc: "list, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL + "/rules",
+			Auth:   true,
+		}),
+		method: "GetAutoAcceptRules",
+		ret:    []interface{}{[]model.AutoAcceptRule{*rule}, nil},
+
+		code: http.StatusOK,
+		body: "[" + string(ruleJSON) + "]",
+	}, {
+		desc: "list, error",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL + "/rules",
+			Auth:   true,
+		}),
+		method: "GetAutoAcceptRules",
+		ret:    []interface{}{nil, errors.New("mongo")},
+
+		code: http.StatusInternalServerError,
+		body: RestError("internal error"),
+	}, {
+		desc: "get, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL + "/rules/" + rule.Id,
+			Auth:   true,
+		}),
+		method: "GetAutoAcceptRule",
+		args:   []interface{}{rule.Id},
+		ret:    []interface{}{rule, nil},
+
+		code: http.StatusOK,
+		body: string(ruleJSON),
+	}, {
+		desc: "get, not found",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL + "/rules/" + rule.Id,
+			Auth:   true,
+		}),
+		method: "GetAutoAcceptRule",
+		args:   []interface{}{rule.Id},
+		ret:    []interface{}{nil, store.ErrAutoAcceptRuleNotFound},
+
+		code: http.StatusNotFound,
+		body: RestError(store.ErrAutoAcceptRuleNotFound.Error()),
+	}, {
+		desc: "add, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPost,
+			Path:   baseURL + "/rules",
+			Auth:   true,
+			Body:   ruleReq,
+		}),
+		method: "AddAutoAcceptRule",
+		args:   []interface{}{ruleReq},
+		ret:    []interface{}{rule, nil},
+
+		code: http.StatusCreated,
+		body: string(ruleJSON),
+	}, {
+		desc: "add, invalid request",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPost,
+			Path:   baseURL + "/rules",
+			Auth:   true,
+			Body: model.AutoAcceptRuleReq{
+				MaxAccepts: 10,
+			},
+		}),
+
+		code: http.StatusBadRequest,
+		body: RestError("invalid auto-accept rule: " +
+			model.ErrAutoAcceptRuleNoCriteria.Error()),
+	}, {
+		desc: "add, error",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPost,
+			Path:   baseURL + "/rules",
+			Auth:   true,
+			Body:   ruleReq,
+		}),
+		method: "AddAutoAcceptRule",
+		args:   []interface{}{ruleReq},
+		ret:    []interface{}{nil, errors.New("mongo")},
+
+		code: http.StatusInternalServerError,
+		body: RestError("internal error"),
+	}, {
+		desc: "delete, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodDelete,
+			Path:   baseURL + "/rules/" + rule.Id,
+			Auth:   true,
+		}),
+		method: "DeleteAutoAcceptRule",
+		args:   []interface{}{rule.Id},
+		ret:    []interface{}{nil},
+
+		code: http.StatusNoContent,
+	}, {
+		desc: "delete, not found",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodDelete,
+			Path:   baseURL + "/rules/" + rule.Id,
+			Auth:   true,
+		}),
+		method: "DeleteAutoAcceptRule",
+		args:   []interface{}{rule.Id},
+		ret:    []interface{}{store.ErrAutoAcceptRuleNotFound},
+
+		code: http.StatusNotFound,
+		body: RestError(store.ErrAutoAcceptRuleNotFound.Error()),
+	}, {
+		desc: "audit, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL + "/audit?rule_id=" + rule.Id + "&page=2&per_page=1",
+			Auth:   true,
+		}),
+		method: "GetAutoAcceptAudit",
+		args: []interface{}{
+			model.AutoAcceptAuditFilter{RuleId: rule.Id},
+			uint(1), uint(2),
+		},
+		ret: []interface{}{[]model.AutoAcceptAudit{audit, audit}, nil},
+
+		code: http.StatusOK,
+		body: "[" + string(auditJSON) + "]",
+	}, {
+		desc: "audit, invalid paging",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL + "/audit?page=foo",
+			Auth:   true,
+		}),
+
+		code: http.StatusBadRequest,
+		body: RestError("invalid page query: \"foo\""),
+	}, {
+		desc: "audit, error",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path: baseURL + "/audit?device_id=" +
+				"c7acb245-3ef4-4922-b28d-2d1ab8bfb930",
+			Auth: true,
+		}),
+		method: "GetAutoAcceptAudit",
+		args: []interface{}{
+			model.AutoAcceptAuditFilter{
+				DeviceId: "c7acb245-3ef4-4922-b28d-2d1ab8bfb930",
+			},
+			uint(0), uint(21),
+		},
+		ret: []interface{}{nil, errors.New("mongo")},
+
+		code: http.StatusInternalServerError,
+		body: RestError("internal error"),
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(fmt.Sprintf("tc %s", tc.desc), func(t *testing.T) {
+			t.Parallel()
+
+			da := &mocks.App{}
+			defer da.AssertExpectations(t)
+			if tc.method != "" {
+				args := append([]interface{}{mtest.ContextMatcher()}, tc.args...)
+				da.On(tc.method, args...).Return(tc.ret...)
+			}
+
+			apih := makeMockApiHandler(t, da, nil)
+			runTestRequest(t, apih, tc.req, tc.code, tc.body)
+		})
+	}
+}
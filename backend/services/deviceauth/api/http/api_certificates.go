@@ -0,0 +1,138 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/rest.utils"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+	"github.com/mendersoftware/mender-server/services/deviceauth/utils"
+)
+
+// decodeClientCertificate decodes the client certificate passed by the
+// gateway as URL escaped PEM or base64 encoded DER.
+func decodeClientCertificate(hdr string) (string, error) {
+	if unescaped, err := url.PathUnescape(hdr); err == nil &&
+		strings.Contains(unescaped, "-----BEGIN") {
+		return unescaped, nil
+	}
+	der, err := base64.StdEncoding.DecodeString(hdr)
+	if err != nil {
+		return "", errors.New("expected URL escaped PEM or base64 encoded DER")
+	}
+	if _, err := x509.ParseCertificate(der); err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: der,
+	})), nil
+}
+
+func (i *DevAuthApiHandlers) GetCACertificatesHandler(c *gin.Context) {
+	certs, err := i.app.GetCACertificates(c.Request.Context())
+	if err != nil {
+		rest.RenderInternalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, certs)
+}
+
+func (i *DevAuthApiHandlers) GetCACertificateHandler(c *gin.Context) {
+	cert, err := i.app.GetCACertificate(c.Request.Context(), c.Param("id"))
+	switch err {
+	case nil:
+		c.JSON(http.StatusOK, cert)
+	case store.ErrCACertificateNotFound:
+		rest.RenderError(c, http.StatusNotFound, err)
+	default:
+		rest.RenderInternalError(c, err)
+	}
+}
+
+func (i *DevAuthApiHandlers) AddCACertificateHandler(c *gin.Context) {
+	var req model.CACertificateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err = errors.Wrap(err, "failed to decode CA certificate request")
+		rest.RenderError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		err = errors.Wrap(err, "invalid CA certificate request")
+		rest.RenderError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	cert, err := i.app.AddCACertificate(c.Request.Context(), req)
+	switch {
+	case err == nil:
+		c.Header("Location", "authorities/"+cert.Id)
+		c.JSON(http.StatusCreated, cert)
+	case err == devauth.ErrCACertificateExists:
+		rest.RenderError(c, http.StatusConflict, err)
+	case devauth.IsErrDevAuthBadRequest(err):
+		rest.RenderError(c, http.StatusBadRequest, errors.Cause(err))
+	default:
+		rest.RenderInternalError(c, err)
+	}
+}
+
+func (i *DevAuthApiHandlers) DeleteCACertificateHandler(c *gin.Context) {
+	err := i.app.DeleteCACertificate(c.Request.Context(), c.Param("id"))
+	switch err {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case store.ErrCACertificateNotFound:
+		rest.RenderError(c, http.StatusNotFound, err)
+	default:
+		rest.RenderInternalError(c, err)
+	}
+}
+
+// PutCACertificateCRLHandler replaces the revocation list of the CA with
+// the PEM or DER encoded list in the request body.
+func (i *DevAuthApiHandlers) PutCACertificateCRLHandler(c *gin.Context) {
+	body, err := utils.ReadBodyRaw(c.Request)
+	if err != nil || len(body) == 0 {
+		rest.RenderError(c, http.StatusBadRequest,
+			errors.New("missing revocation list"),
+		)
+		return
+	}
+
+	cert, err := i.app.SetCACertificateCRL(c.Request.Context(), c.Param("id"), body)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, cert)
+	case err == store.ErrCACertificateNotFound:
+		rest.RenderError(c, http.StatusNotFound, err)
+	case devauth.IsErrDevAuthBadRequest(err):
+		rest.RenderError(c, http.StatusBadRequest, errors.Cause(err))
+	default:
+		rest.RenderInternalError(c, err)
+	}
+}
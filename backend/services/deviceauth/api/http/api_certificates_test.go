@@ -0,0 +1,394 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth"
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+	"github.com/mendersoftware/mender-server/services/deviceauth/utils"
+	mtest "github.com/mendersoftware/mender-server/services/deviceauth/utils/testing"
+)
+
+func TestDecodeClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	ca, caKey := mtest.NewCertificate(t, "ca", 1, nil, nil)
+	leaf, _ := mtest.NewCertificate(t, "device", 2, ca, caKey)
+	certPEM := mtest.CertificatePEM(leaf)
+
+	res, err := decodeClientCertificate(url.PathEscape(certPEM))
+	assert.NoError(t, err)
+	assert.Equal(t, certPEM, res)
+
+	res, err = decodeClientCertificate(base64.StdEncoding.EncodeToString(leaf.Raw))
+	assert.NoError(t, err)
+	assert.Equal(t, certPEM, res)
+
+	_, err = decodeClientCertificate("garbage!")
+	assert.EqualError(t, err, "expected URL escaped PEM or base64 encoded DER")
+
+	_, err = decodeClientCertificate(base64.StdEncoding.EncodeToString([]byte("garbage")))
+	assert.Error(t, err)
+}
+
+func TestApiDevAuthSubmitAuthReqClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	const hdrClientCert = "X-Client-Cert"
+
+	ca, caKey := mtest.NewCertificate(t, "ca", 1, nil, nil)
+	leaf, leafKey := mtest.NewCertificate(t, "device", 2, ca, caKey)
+	certPEM := mtest.CertificatePEM(leaf)
+	pubKey, err := utils.SerializePubKey(leaf.PublicKey)
+	assert.NoError(t, err)
+	_, otherKey := mtest.NewCertificate(t, "other", 3, ca, caKey)
+
+	testCases := []struct {
+		desc string
+
+		header  string
+		payload map[string]interface{}
+		hdr     string
+		signKey crypto.PrivateKey
+
+		code int
+		body string
+	}{{
+		desc:   "ok, certificate from the gateway",
+		header: hdrClientCert,
+		payload: map[string]interface{}{
+			"pubkey": pubKey,
+		},
+		hdr: url.PathEscape(certPEM),
+
+		code: http.StatusOK,
+		body: "token",
+	}, {
+		desc: "ok, certificate in the request",
+		payload: map[string]interface{}{
+			"pubkey":      pubKey,
+			"certificate": certPEM,
+		},
+
+		code: http.StatusOK,
+		body: "token",
+	}, {
+		desc: "error, request not signed with the certificate key",
+		payload: map[string]interface{}{
+			"pubkey":      pubKey,
+			"certificate": certPEM,
+		},
+		signKey: otherKey,
+
+		code: http.StatusUnauthorized,
+		body: RestError("signature verification failed"),
+	}, {
+		desc: "error, header not trusted",
+		payload: map[string]interface{}{
+			"pubkey": pubKey,
+		},
+		hdr: url.PathEscape(certPEM),
+
+		code: http.StatusBadRequest,
+		body: RestError("invalid auth request: id_data must be provided"),
+	}, {
+		desc:   "error, malformed header",
+		header: hdrClientCert,
+		payload: map[string]interface{}{
+			"pubkey": pubKey,
+		},
+		hdr: "garbage!",
+
+		code: http.StatusBadRequest,
+		body: RestError("invalid client certificate: " +
+			"expected URL escaped PEM or base64 encoded DER"),
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			da := &mocks.App{}
+			defer da.AssertExpectations(t)
+			if tc.code == http.StatusOK {
+				da.On("SubmitAuthRequest",
+					mtest.ContextMatcher(),
+					mock.MatchedBy(func(r *model.AuthReq) bool {
+						return r.Certificate == certPEM
+					})).
+					Return("token", nil).Once()
+			}
+
+			options := []Option{}
+			if tc.header != "" {
+				options = append(options, SetClientCertificateHeader(tc.header))
+			}
+			apih := NewRouter(da, nil, options...)
+
+			signKey := tc.signKey
+			if signKey == nil {
+				signKey = leafKey
+			}
+			req := makeAuthReq(tc.payload, signKey, "", t)
+			if tc.hdr != "" {
+				req.Header.Set(hdrClientCert, tc.hdr)
+			}
+			runTestRequest(t, apih, req, tc.code, tc.body)
+		})
+	}
+}
+
+func TestApiV2CACertificates(t *testing.T) {
+	t.Parallel()
+
+	const baseURL = "http://localhost/api/management/v2/devauth/certificates/authorities"
+
+	ca, caKey := mtest.NewCertificate(t, "ca", 1, nil, nil)
+	cert, err := model.NewCACertificate(model.CACertificateReq{
+		Certificate: mtest.CertificatePEM(ca),
+	})
+	assert.NoError(t, err)
+	certJSON, _ := json.Marshal(cert)
+	crl := mtest.NewRevocationList(t, ca, caKey, 2)
+
+	makeCRLReq := func(body []byte) *http.Request {
+		req, _ := http.NewRequest(http.MethodPut,
+			baseURL+"/"+cert.Id+"/crl", bytes.NewReader(body))
+		req.Header.Set("Authorization", rtest.DEFAULT_AUTH)
+		return req
+	}
+
+	testCases := []struct {
+		desc string
+
+		req *http.Request
+
+		method string
+		args   []interface{}
+		ret    []interface{}
+
+		code int
+		body string
+	}{{
+		desc: "list, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL,
+			Auth:   true,
+		}),
+		method: "GetCACertificates",
+		ret:    []interface{}{[]model.CACertificate{*cert}, nil},
+
+		code: http.StatusOK,
+		body: "[" + string(certJSON) + "]",
+	}, {
+		desc: "list, error",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL,
+			Auth:   true,
+		}),
+		method: "GetCACertificates",
+		ret:    []interface{}{nil, errors.New("mongo")},
+
+		code: http.StatusInternalServerError,
+		body: RestError("internal error"),
+	}, {
+		desc: "get, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL + "/" + cert.Id,
+			Auth:   true,
+		}),
+		method: "GetCACertificate",
+		args:   []interface{}{cert.Id},
+		ret:    []interface{}{cert, nil},
+
+		code: http.StatusOK,
+		body: string(certJSON),
+	}, {
+		desc: "get, not found",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   baseURL + "/" + cert.Id,
+			Auth:   true,
+		}),
+		method: "GetCACertificate",
+		args:   []interface{}{cert.Id},
+		ret:    []interface{}{nil, store.ErrCACertificateNotFound},
+
+		code: http.StatusNotFound,
+		body: RestError(store.ErrCACertificateNotFound.Error()),
+	}, {
+		desc: "add, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPost,
+			Path:   baseURL,
+			Auth:   true,
+			Body: model.CACertificateReq{
+				Certificate: cert.Certificate,
+			},
+		}),
+		method: "AddCACertificate",
+		args: []interface{}{model.CACertificateReq{
+			Certificate: cert.Certificate,
+		}},
+		ret: []interface{}{cert, nil},
+
+		code: http.StatusCreated,
+		body: string(certJSON),
+	}, {
+		desc: "add, invalid request",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPost,
+			Path:   baseURL,
+			Auth:   true,
+			Body:   model.CACertificateReq{},
+		}),
+
+		code: http.StatusBadRequest,
+		body: RestError("invalid CA certificate request: certificate: cannot be blank."),
+	}, {
+		desc: "add, not a CA",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPost,
+			Path:   baseURL,
+			Auth:   true,
+			Body: model.CACertificateReq{
+				Certificate: cert.Certificate,
+			},
+		}),
+		method: "AddCACertificate",
+		args: []interface{}{model.CACertificateReq{
+			Certificate: cert.Certificate,
+		}},
+		ret: []interface{}{nil, devauth.MakeErrDevAuthBadRequest(model.ErrCertificateNotCA)},
+
+		code: http.StatusBadRequest,
+		body: RestError(model.ErrCertificateNotCA.Error()),
+	}, {
+		desc: "add, conflict",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPost,
+			Path:   baseURL,
+			Auth:   true,
+			Body: model.CACertificateReq{
+				Certificate: cert.Certificate,
+			},
+		}),
+		method: "AddCACertificate",
+		args: []interface{}{model.CACertificateReq{
+			Certificate: cert.Certificate,
+		}},
+		ret: []interface{}{nil, devauth.ErrCACertificateExists},
+
+		code: http.StatusConflict,
+		body: RestError(devauth.ErrCACertificateExists.Error()),
+	}, {
+		desc: "delete, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodDelete,
+			Path:   baseURL + "/" + cert.Id,
+			Auth:   true,
+		}),
+		method: "DeleteCACertificate",
+		args:   []interface{}{cert.Id},
+		ret:    []interface{}{nil},
+
+		code: http.StatusNoContent,
+	}, {
+		desc: "delete, not found",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodDelete,
+			Path:   baseURL + "/" + cert.Id,
+			Auth:   true,
+		}),
+		method: "DeleteCACertificate",
+		args:   []interface{}{cert.Id},
+		ret:    []interface{}{store.ErrCACertificateNotFound},
+
+		code: http.StatusNotFound,
+		body: RestError(store.ErrCACertificateNotFound.Error()),
+	}, {
+		desc:   "crl, ok",
+		req:    makeCRLReq(crl),
+		method: "SetCACertificateCRL",
+		args:   []interface{}{cert.Id, crl},
+		ret:    []interface{}{cert, nil},
+
+		code: http.StatusOK,
+		body: string(certJSON),
+	}, {
+		desc: "crl, empty",
+		req:  makeCRLReq(nil),
+
+		code: http.StatusBadRequest,
+		body: RestError("missing revocation list"),
+	}, {
+		desc:   "crl, invalid",
+		req:    makeCRLReq([]byte("garbage")),
+		method: "SetCACertificateCRL",
+		args:   []interface{}{cert.Id, []byte("garbage")},
+		ret: []interface{}{nil, devauth.MakeErrDevAuthBadRequest(
+			errors.New("failed to parse revocation list"))},
+
+		code: http.StatusBadRequest,
+		body: RestError("failed to parse revocation list"),
+	}, {
+		desc:   "crl, not found",
+		req:    makeCRLReq(crl),
+		method: "SetCACertificateCRL",
+		args:   []interface{}{cert.Id, crl},
+		ret:    []interface{}{nil, store.ErrCACertificateNotFound},
+
+		code: http.StatusNotFound,
+		body: RestError(store.ErrCACertificateNotFound.Error()),
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(fmt.Sprintf("tc %s", tc.desc), func(t *testing.T) {
+			t.Parallel()
+
+			da := &mocks.App{}
+			defer da.AssertExpectations(t)
+			if tc.method != "" {
+				args := append([]interface{}{mtest.ContextMatcher()}, tc.args...)
+				da.On(tc.method, args...).Return(tc.ret...)
+			}
+
+			apih := makeMockApiHandler(t, da, nil)
+			runTestRequest(t, apih, tc.req, tc.code, tc.body)
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 
 	ctxhttpheader "github.com/mendersoftware/mender-server/pkg/context/httpheader"
 	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/netutils"
 	"github.com/mendersoftware/mender-server/pkg/rest.utils"
 
 	"github.com/mendersoftware/mender-server/services/deviceauth/access"
@@ -46,9 +47,11 @@ var (
 )
 
 type DevAuthApiHandlers struct {
-	app         devauth.App
-	db          store.DataStore
-	rateLimiter gin.HandlerFunc
+	app              devauth.App
+	db               store.DataStore
+	rateLimiter      gin.HandlerFunc
+	clientCertHeader string
+	clientIPDepth    int
 }
 
 type DevAuthApiStatus struct {
@@ -66,9 +69,11 @@ func NewDevAuthApiHandlers(
 	}
 
 	return &DevAuthApiHandlers{
-		app:         devAuth,
-		db:          db,
-		rateLimiter: cfg.AuthVerifyRatelimits,
+		app:              devAuth,
+		db:               db,
+		rateLimiter:      cfg.AuthVerifyRatelimits,
+		clientCertHeader: cfg.ClientCertificateHeader,
+		clientIPDepth:    cfg.ClientIPProxyDepth,
 	}
 }
 
@@ -111,6 +116,20 @@ func (i *DevAuthApiHandlers) SubmitAuthRequestHandler(c *gin.Context) {
 		return
 	}
 
+	// the certificate of the device authenticated by the gateway
+	if i.clientCertHeader != "" && authreq.Certificate == "" {
+		if hdr := c.GetHeader(i.clientCertHeader); hdr != "" {
+			authreq.Certificate, err = decodeClientCertificate(hdr)
+			if err != nil {
+				err = errors.Wrap(err, "invalid client certificate")
+				rest.RenderError(c, http.StatusBadRequest, err)
+				return
+			}
+		}
+	}
+
+	authreq.SourceIP = netutils.GetIPFromXFFDepth(c.Request, i.clientIPDepth)
+
 	err = authreq.Validate()
 	if err != nil {
 		err = errors.Wrap(err, "invalid auth request")
@@ -126,6 +145,20 @@ func (i *DevAuthApiHandlers) SubmitAuthRequestHandler(c *gin.Context) {
 		)
 		return
 	}
+	// the identity of the devices presenting a certificate is derived from
+	// the certificate: the signature must prove the device holds its private
+	// key before the request is processed
+	if authreq.Certificate != "" {
+		err = utils.VerifyAuthReqSign(signature, authreq.PubKeyStruct, body)
+		if err != nil {
+			rest.RenderErrorWithMessage(c,
+				http.StatusUnauthorized,
+				errors.Cause(err),
+				"signature verification failed",
+			)
+			return
+		}
+	}
 
 	token, err := i.app.SubmitAuthRequest(ctx, &authreq)
 	if err != nil {
@@ -376,6 +409,17 @@ func (i *DevAuthApiHandlers) DeleteTokenHandler(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// GetJWKSHandler publishes the public keys verifying the device tokens
+// to the API gateway and the other verifiers.
+func (i *DevAuthApiHandlers) GetJWKSHandler(c *gin.Context) {
+	jwks, err := i.app.GetJWKS(c.Request.Context())
+	if err != nil {
+		rest.RenderInternalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, jwks)
+}
+
 func (i *DevAuthApiHandlers) VerifyTokenHandler(c *gin.Context) {
 	ctx := c.Request.Context()
 
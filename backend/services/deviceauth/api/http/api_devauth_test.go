@@ -29,6 +29,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/keys"
 	"github.com/mendersoftware/mender-server/pkg/requestid"
 	"github.com/mendersoftware/mender-server/pkg/rest.utils"
 	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
@@ -114,6 +115,54 @@ func TestAliveHandler(t *testing.T) {
 	runTestRequest(t, apih, req, http.StatusNoContent, "")
 }
 
+func TestGetJWKS(t *testing.T) {
+	jwks := &keys.JWKS{Keys: []keys.JWK{{
+		KeyType:   keys.JWKKeyTypeOKP,
+		KeyID:     "2",
+		Use:       keys.JWKUseSignature,
+		Algorithm: keys.JWKAlgEdDSA,
+		Curve:     keys.JWKCurveEd25519,
+		X:         "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+	}}}
+	jwksJSON, _ := json.Marshal(jwks)
+
+	testCases := []struct {
+		Name string
+
+		JWKS         *keys.JWKS
+		AppError     error
+		ResponseCode int
+		ResponseBody string
+	}{{
+		Name:         "ok",
+		JWKS:         jwks,
+		ResponseCode: http.StatusOK,
+		ResponseBody: string(jwksJSON),
+	}, {
+		Name:         "error",
+		AppError:     errors.New("unsupported public key type"),
+		ResponseCode: http.StatusInternalServerError,
+		ResponseBody: RestError("internal error"),
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			da := &mocks.App{}
+			defer da.AssertExpectations(t)
+			da.On("GetJWKS", mtest.ContextMatcher()).Return(tc.JWKS, tc.AppError)
+
+			api := makeMockApiHandler(t, da, nil)
+			req, _ := http.NewRequest(
+				"GET",
+				"http://localhost"+apiUrlInternalV1+uriJWKS,
+				nil,
+			)
+			req.Header.Set("X-MEN-RequestID", "test")
+			runTestRequest(t, api, req, tc.ResponseCode, tc.ResponseBody)
+		})
+	}
+}
+
 func TestHealthCheck(t *testing.T) {
 	testCases := []struct {
 		Name string
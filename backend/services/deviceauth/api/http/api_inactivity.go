@@ -0,0 +1,72 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/rest.utils"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+func (i *DevAuthApiHandlers) GetInactivityPolicyHandler(c *gin.Context) {
+	policy, err := i.app.GetInactivityPolicy(c.Request.Context())
+	switch err {
+	case nil:
+		c.JSON(http.StatusOK, policy)
+	case store.ErrInactivityPolicyNotFound:
+		rest.RenderError(c, http.StatusNotFound, err)
+	default:
+		rest.RenderInternalError(c, err)
+	}
+}
+
+func (i *DevAuthApiHandlers) PutInactivityPolicyHandler(c *gin.Context) {
+	var policy model.InactivityPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		err = errors.Wrap(err, "failed to decode inactivity policy")
+		rest.RenderError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := policy.Validate(); err != nil {
+		err = errors.Wrap(err, "invalid inactivity policy")
+		rest.RenderError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := i.app.SetInactivityPolicy(c.Request.Context(), policy)
+	if err != nil {
+		rest.RenderInternalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (i *DevAuthApiHandlers) DeleteInactivityPolicyHandler(c *gin.Context) {
+	err := i.app.DeleteInactivityPolicy(c.Request.Context())
+	switch err {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case store.ErrInactivityPolicyNotFound:
+		rest.RenderError(c, http.StatusNotFound, err)
+	default:
+		rest.RenderInternalError(c, err)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+	mtest "github.com/mendersoftware/mender-server/services/deviceauth/utils/testing"
+)
+
+func TestApiV2InactivityPolicy(t *testing.T) {
+	t.Parallel()
+
+	const url = "http://localhost/api/management/v2/devauth/inactivity_policy"
+
+	policyReq := model.InactivityPolicy{
+		InactiveDays: 90,
+		GraceDays:    14,
+		Action:       model.InactivityActionDecommission,
+	}
+	policy := policyReq
+	policy.UpdatedTs = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	policyJSON, _ := json.Marshal(policy)
+
+	testCases := []struct {
+		desc string
+
+		req *http.Request
+
+		method string
+		args   []interface{}
+		ret    []interface{}
+
+		code int
+		body string
+	}{{
+		desc: "get, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   url,
+			Auth:   true,
+		}),
+		method: "GetInactivityPolicy",
+		ret:    []interface{}{&policy, nil},
+
+		code: http.StatusOK,
+		body: string(policyJSON),
+	}, {
+		desc: "get, not found",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodGet,
+			Path:   url,
+			Auth:   true,
+		}),
+		method: "GetInactivityPolicy",
+		ret:    []interface{}{nil, store.ErrInactivityPolicyNotFound},
+
+		code: http.StatusNotFound,
+		body: RestError(store.ErrInactivityPolicyNotFound.Error()),
+	}, {
+		desc: "put, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPut,
+			Path:   url,
+			Auth:   true,
+			Body:   policyReq,
+		}),
+		method: "SetInactivityPolicy",
+		args:   []interface{}{policyReq},
+		ret:    []interface{}{&policy, nil},
+
+		code: http.StatusOK,
+		body: string(policyJSON),
+	}, {
+		desc: "put, invalid policy",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPut,
+			Path:   url,
+			Auth:   true,
+			Body: model.InactivityPolicy{
+				InactiveDays: 90,
+				GraceDays:    120,
+				Action:       model.InactivityActionReject,
+			},
+		}),
+
+		code: http.StatusBadRequest,
+		body: RestError("invalid inactivity policy: " +
+			model.ErrInactivityPolicyGrace.Error()),
+	}, {
+		desc: "put, error",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodPut,
+			Path:   url,
+			Auth:   true,
+			Body:   policyReq,
+		}),
+		method: "SetInactivityPolicy",
+		args:   []interface{}{policyReq},
+		ret:    []interface{}{nil, errors.New("mongo")},
+
+		code: http.StatusInternalServerError,
+		body: RestError("internal error"),
+	}, {
+		desc: "delete, ok",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodDelete,
+			Path:   url,
+			Auth:   true,
+		}),
+		method: "DeleteInactivityPolicy",
+		ret:    []interface{}{nil},
+
+		code: http.StatusNoContent,
+	}, {
+		desc: "delete, not found",
+		req: rtest.MakeTestRequest(&rtest.TestRequest{
+			Method: http.MethodDelete,
+			Path:   url,
+			Auth:   true,
+		}),
+		method: "DeleteInactivityPolicy",
+		ret:    []interface{}{store.ErrInactivityPolicyNotFound},
+
+		code: http.StatusNotFound,
+		body: RestError(store.ErrInactivityPolicyNotFound.Error()),
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(fmt.Sprintf("tc %s", tc.desc), func(t *testing.T) {
+			t.Parallel()
+
+			da := &mocks.App{}
+			defer da.AssertExpectations(t)
+			if tc.method != "" {
+				args := append([]interface{}{mtest.ContextMatcher()}, tc.args...)
+				da.On(tc.method, args...).Return(tc.ret...)
+			}
+
+			apih := makeMockApiHandler(t, da, nil)
+			runTestRequest(t, apih, tc.req, tc.code, tc.body)
+		})
+	}
+}
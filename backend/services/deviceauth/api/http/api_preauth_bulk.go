@@ -0,0 +1,106 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/rest.utils"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+)
+
+const (
+	ParamDryRun = "dry_run"
+
+	contentTypeCSV    = "text/csv"
+	contentTypeNDJSON = "application/x-ndjson"
+
+	// PreAuthMaxBodySize and PreAuthMaxRecords limit the size of the files
+	// imported through the API; larger files are imported with the
+	// preauthorize command of the service.
+	PreAuthMaxBodySize = 4 * 1024 * 1024
+	PreAuthMaxRecords  = 1000
+)
+
+var (
+	ErrPreAuthContentType = errors.New(
+		"Content-Type must be one of: " + contentTypeCSV + ", " + contentTypeNDJSON)
+	ErrPreAuthTooLarge = errors.Errorf(
+		"the file exceeds the limit of %d bytes or %d records; "+
+			"use the deviceauth preauthorize command to import larger files",
+		PreAuthMaxBodySize, PreAuthMaxRecords)
+)
+
+// PreauthorizeDevicesHandler preauthorizes the devices listed in the CSV or
+// newline delimited JSON request body, returning the result of each record.
+func (i *DevAuthApiHandlers) PreauthorizeDevicesHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var format string
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case contentTypeCSV:
+		format = model.PreAuthFormatCSV
+	case contentTypeNDJSON:
+		format = model.PreAuthFormatNDJSON
+	default:
+		rest.RenderError(c, http.StatusUnsupportedMediaType, ErrPreAuthContentType)
+		return
+	}
+
+	dryRun := false
+	if q := c.Query(ParamDryRun); q != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(q)
+		if err != nil {
+			rest.RenderError(c, http.StatusBadRequest,
+				errors.Errorf("invalid %s query parameter: %q", ParamDryRun, q))
+			return
+		}
+	}
+
+	if c.Request.ContentLength > PreAuthMaxBodySize {
+		rest.RenderError(c, http.StatusRequestEntityTooLarge, ErrPreAuthTooLarge)
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, PreAuthMaxBodySize)
+	records, err := model.ReadPreAuthRecords(body, format)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			rest.RenderError(c, http.StatusRequestEntityTooLarge, ErrPreAuthTooLarge)
+		} else {
+			rest.RenderError(c, http.StatusBadRequest, err)
+		}
+		return
+	}
+	if len(records) > PreAuthMaxRecords {
+		rest.RenderError(c, http.StatusRequestEntityTooLarge, ErrPreAuthTooLarge)
+		return
+	}
+
+	res, err := i.app.PreauthorizeDevices(ctx, records, 0, dryRun)
+	if err != nil {
+		rest.RenderInternalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
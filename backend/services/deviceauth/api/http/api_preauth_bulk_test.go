@@ -0,0 +1,167 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	rtest "github.com/mendersoftware/mender-server/pkg/testing/rest"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	mtest "github.com/mendersoftware/mender-server/services/deviceauth/utils/testing"
+)
+
+func TestPreauthorizeDevices(t *testing.T) {
+	t.Parallel()
+
+	const url = "http://localhost/api/management/v2/devauth/devices/preauthorize"
+
+	result := &model.PreAuthBulkResult{
+		Created: 1,
+		Results: []model.PreAuthRecordResult{{
+			Line:     2,
+			Status:   model.PreAuthResultCreated,
+			DeviceId: "0e4b3ac1-f2bc-4b2e-a6ab-cae26b5e8d23",
+		}},
+	}
+	resultJSON, _ := json.Marshal(result)
+
+	makeReq := func(contentType, query, body string) *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, url+query, strings.NewReader(body))
+		req.Header.Set("Authorization", rtest.DEFAULT_AUTH)
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+
+	tooManyRecords := "sn,pubkey\n" +
+		strings.Repeat("sn,key\n", PreAuthMaxRecords+1)
+	tooLarge := "sn,pubkey\n" + strings.Repeat("x", PreAuthMaxBodySize)
+	// without the Content-Length the body is cut while it is read
+	tooLargeChunked := makeReq("text/csv", "", tooLarge)
+	tooLargeChunked.ContentLength = -1
+
+	testCases := []struct {
+		desc string
+
+		req *http.Request
+
+		records []model.PreAuthRecord
+		dryRun  bool
+		result  *model.PreAuthBulkResult
+		err     error
+
+		code int
+		body string
+	}{{
+		desc: "ok, csv",
+		req:  makeReq("text/csv; charset=utf-8", "", "sn,pubkey\nsn-1,key\n"),
+		records: []model.PreAuthRecord{{
+			Line:   2,
+			IdData: map[string]interface{}{"sn": "sn-1"},
+			PubKey: "key",
+		}},
+		result: result,
+
+		code: http.StatusOK,
+		body: string(resultJSON),
+	}, {
+		desc: "ok, ndjson, dry run",
+		req: makeReq("application/x-ndjson", "?dry_run=true",
+			`{"identity_data":{"sn":"sn-1"},"pubkey":"key","force":true}`+"\n"),
+		records: []model.PreAuthRecord{{
+			Line:   1,
+			Force:  true,
+			IdData: map[string]interface{}{"sn": "sn-1"},
+			PubKey: "key",
+		}},
+		dryRun: true,
+		result: result,
+
+		code: http.StatusOK,
+		body: string(resultJSON),
+	}, {
+		desc: "error, content type",
+		req:  makeReq("application/json", "", "[]"),
+
+		code: http.StatusUnsupportedMediaType,
+		body: RestError(ErrPreAuthContentType.Error()),
+	}, {
+		desc: "error, dry run",
+		req:  makeReq("text/csv", "?dry_run=maybe", "sn,pubkey\n"),
+
+		code: http.StatusBadRequest,
+		body: RestError(`invalid dry_run query parameter: "maybe"`),
+	}, {
+		desc: "error, csv header",
+		req:  makeReq("text/csv", "", "sn,mac\n"),
+
+		code: http.StatusBadRequest,
+		body: RestError(model.ErrPreAuthNoPubKey.Error()),
+	}, {
+		desc: "error, too many records",
+		req:  makeReq("text/csv", "", tooManyRecords),
+
+		code: http.StatusRequestEntityTooLarge,
+		body: RestError(ErrPreAuthTooLarge.Error()),
+	}, {
+		desc: "error, body too large",
+		req:  makeReq("text/csv", "", tooLarge),
+
+		code: http.StatusRequestEntityTooLarge,
+		body: RestError(ErrPreAuthTooLarge.Error()),
+	}, {
+		desc: "error, body too large, no content length",
+		req:  tooLargeChunked,
+
+		code: http.StatusRequestEntityTooLarge,
+		body: RestError(ErrPreAuthTooLarge.Error()),
+	}, {
+		desc: "error, internal",
+		req:  makeReq("text/csv", "", "sn,pubkey\n"),
+
+		records: []model.PreAuthRecord{},
+		err:     errors.New("context canceled"),
+
+		code: http.StatusInternalServerError,
+		body: RestError("internal error"),
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			da := &mocks.App{}
+			defer da.AssertExpectations(t)
+			if tc.records != nil {
+				da.On("PreauthorizeDevices",
+					mtest.ContextMatcher(),
+					tc.records,
+					0,
+					tc.dryRun).
+					Return(tc.result, tc.err)
+			}
+
+			apih := makeMockApiHandler(t, da, nil)
+			runTestRequest(t, apih, tc.req, tc.code, tc.body)
+		})
+	}
+}
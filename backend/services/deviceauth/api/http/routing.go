@@ -44,17 +44,26 @@ const (
 	uriTenantDeviceStatus = "/tenants/:tid/devices/:did/status"
 	uriTenantDevices      = "/tenants/:tid/devices"
 	uriTenantDevicesCount = "/tenants/:tid/devices/count"
+	uriJWKS               = "/.well-known/jwks.json"
 
 	// management API v2
 	apiUrlManagementV2       = "/api/management/v2/devauth"
 	v2uriDevices             = "/devices"
 	v2uriDevicesCount        = "/devices/count"
 	v2uriDevicesSearch       = "/devices/search"
+	v2uriDevicesPreauthorize = "/devices/preauthorize"
 	v2uriDevice              = "/devices/:id"
 	v2uriDeviceAuthSet       = "/devices/:id/auth/:aid"
 	v2uriDeviceAuthSetStatus = "/devices/:id/auth/:aid/status"
 	v2uriToken               = "/tokens/:id"
 	v2uriDevicesLimit        = "/limits/:name"
+	v2uriCACertificates      = "/certificates/authorities"
+	v2uriCACertificate       = "/certificates/authorities/:id"
+	v2uriCACertificateCRL    = "/certificates/authorities/:id/crl"
+	v2uriAutoAcceptRules     = "/auto_accept/rules"
+	v2uriAutoAcceptRule      = "/auto_accept/rules/:id"
+	v2uriAutoAcceptAudit     = "/auto_accept/audit"
+	v2uriInactivityPolicy    = "/inactivity_policy"
 
 	HdrAuthReqSign = "X-MEN-Signature"
 )
@@ -101,8 +110,10 @@ func AutogenOptionsRoutes(router *gin.Engine, gen HttpOptionsGenerator) {
 }
 
 type Config struct {
-	AuthVerifyRatelimits gin.HandlerFunc
-	MaxRequestSize       int64
+	AuthVerifyRatelimits    gin.HandlerFunc
+	MaxRequestSize          int64
+	ClientCertificateHeader string
+	ClientIPProxyDepth      int
 }
 
 func NewConfig() *Config {
@@ -119,6 +130,22 @@ func SetMaxRequestSize(size int64) Option {
 	}
 }
 
+// SetClientCertificateHeader sets the header the gateway passes the client
+// certificate of the device in.
+func SetClientCertificateHeader(header string) Option {
+	return func(c *Config) {
+		c.ClientCertificateHeader = header
+	}
+}
+
+// SetClientIPProxyDepth sets the depth of the X-Forwarded-For header the
+// source address of the devices is taken from.
+func SetClientIPProxyDepth(depth int) Option {
+	return func(c *Config) {
+		c.ClientIPProxyDepth = depth
+	}
+}
+
 func ConfigAuthVerifyRatelimits(handler gin.HandlerFunc) Option {
 	return func(c *Config) {
 		c.AuthVerifyRatelimits = handler
@@ -155,10 +182,24 @@ func NewRouter(app devauth.App, db store.DataStore, options ...Option) http.Hand
 	mgmtAPIV2.DELETE(v2uriDevice, d.DecommissionDeviceHandler)
 	mgmtAPIV2.DELETE(v2uriDeviceAuthSet, d.DeleteDeviceAuthSetHandler)
 	mgmtAPIV2.DELETE(v2uriToken, d.DeleteTokenHandler)
+	mgmtAPIV2.GET(v2uriCACertificates, d.GetCACertificatesHandler)
+	mgmtAPIV2.GET(v2uriCACertificate, d.GetCACertificateHandler)
+	mgmtAPIV2.DELETE(v2uriCACertificate, d.DeleteCACertificateHandler)
+	mgmtAPIV2.PUT(v2uriCACertificateCRL, d.PutCACertificateCRLHandler)
+	mgmtAPIV2.GET(v2uriAutoAcceptRules, d.GetAutoAcceptRulesHandler)
+	mgmtAPIV2.GET(v2uriAutoAcceptRule, d.GetAutoAcceptRuleHandler)
+	mgmtAPIV2.DELETE(v2uriAutoAcceptRule, d.DeleteAutoAcceptRuleHandler)
+	mgmtAPIV2.GET(v2uriAutoAcceptAudit, d.GetAutoAcceptAuditHandler)
+	mgmtAPIV2.GET(v2uriInactivityPolicy, d.GetInactivityPolicyHandler)
+	mgmtAPIV2.DELETE(v2uriInactivityPolicy, d.DeleteInactivityPolicyHandler)
+	mgmtAPIV2.POST(v2uriDevicesPreauthorize, d.PreauthorizeDevicesHandler)
 	mgmtAPIV2.Group(".").Use(contenttype.CheckJSON()).
 		POST(v2uriDevices, d.PostDevicesV2Handler).
 		PUT(v2uriDeviceAuthSetStatus, d.UpdateDeviceStatusHandler).
-		POST(v2uriDevicesSearch, d.SearchDevicesV2Handler)
+		POST(v2uriDevicesSearch, d.SearchDevicesV2Handler).
+		POST(v2uriCACertificates, d.AddCACertificateHandler).
+		POST(v2uriAutoAcceptRules, d.AddAutoAcceptRuleHandler).
+		PUT(v2uriInactivityPolicy, d.PutInactivityPolicyHandler)
 
 	// automatically add Option routes for public endpoints
 	AutogenOptionsRoutes(router, AllowHeaderOptionsGenerator)
@@ -167,6 +208,7 @@ func NewRouter(app devauth.App, db store.DataStore, options ...Option) http.Hand
 
 	intrnlAPIV1.GET(uriAlive, d.AliveHandler)
 	intrnlAPIV1.GET(uriHealth, d.HealthCheckHandler)
+	intrnlAPIV1.GET(uriJWKS, d.GetJWKSHandler)
 
 	intrnlAPIV1.Group(".").
 		Use(identity.Middleware()).
@@ -0,0 +1,78 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+// ApplyInactivityPolicies applies the inactivity policy of the tenant, or of
+// all the tenants if tenant is empty, and writes the report of each tenant
+// with devices to act on as a JSON line to out; the error is returned if
+// the policy could not be applied to any of the devices.
+func ApplyInactivityPolicies(
+	app devauth.App,
+	ds store.DataStore,
+	tenant string,
+	dryRun bool,
+	out io.Writer,
+) error {
+	var (
+		enc    = json.NewEncoder(out)
+		failed = 0
+	)
+	// mapFunc is applied to all existing databases in datastore.
+	mapFunc := func(ctx context.Context) error {
+		if identity.FromContext(ctx) == nil {
+			// the token cache is keyed by the (empty) tenant of
+			// the identity
+			ctx = identity.WithContext(ctx, &identity.Identity{})
+		}
+		report, err := app.ApplyInactivityPolicy(ctx, dryRun)
+		if report != nil && len(report.Devices) > 0 {
+			if encErr := enc.Encode(report); encErr != nil && err == nil {
+				err = errors.Wrap(encErr, "failed to write the report")
+			}
+			failed += report.Failed
+		}
+		return err
+	}
+
+	var err error
+	if tenant != "" {
+		err = mapFunc(identity.WithContext(context.Background(),
+			&identity.Identity{
+				Tenant: tenant,
+			},
+		))
+	} else {
+		err = ds.ForEachTenant(context.Background(), mapFunc)
+	}
+	if err != nil {
+		return err
+	} else if failed > 0 {
+		return errors.Errorf("failed to apply the inactivity policy to %d devices", failed)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+	mstore "github.com/mendersoftware/mender-server/services/deviceauth/store/mocks"
+)
+
+func TestApplyInactivityPolicies(t *testing.T) {
+	const devID = "0e4b3ac1-f2bc-4b2e-a6ab-cae26b5e8d23"
+
+	tenantMatcher := func(tenant string) interface{} {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			id := identity.FromContext(ctx)
+			return id != nil && id.Tenant == tenant
+		})
+	}
+	report := func(tenant, result string) *model.InactivityReport {
+		report := &model.InactivityReport{
+			TenantID: tenant,
+			DryRun:   true,
+			Policy: &model.InactivityPolicy{
+				InactiveDays: 90,
+				Action:       model.InactivityActionDecommission,
+			},
+		}
+		report.Add(model.InactiveDevice{DeviceId: devID, Result: result})
+		return report
+	}
+
+	testCases := []struct {
+		desc string
+
+		tenant  string
+		tenants []string
+
+		setup func(app *mocks.App)
+
+		reports []string
+		err     string
+	}{{
+		desc:    "ok, all tenants",
+		tenants: []string{"", "tenant-1", "tenant-2"},
+		setup: func(app *mocks.App) {
+			app.On("ApplyInactivityPolicy", tenantMatcher(""), true).
+				Return(report("", model.InactiveDeviceNotified), nil)
+			app.On("ApplyInactivityPolicy", tenantMatcher("tenant-1"), true).
+				Return(&model.InactivityReport{
+					TenantID: "tenant-1",
+					DryRun:   true,
+					Devices:  []model.InactiveDevice{},
+				}, nil)
+			app.On("ApplyInactivityPolicy", tenantMatcher("tenant-2"), true).
+				Return(report("tenant-2", model.InactiveDeviceDecommissioned), nil)
+		},
+
+		reports: []string{"", "tenant-2"},
+	}, {
+		desc:   "ok, tenant",
+		tenant: "tenant-1",
+		setup: func(app *mocks.App) {
+			app.On("ApplyInactivityPolicy", tenantMatcher("tenant-1"), true).
+				Return(report("tenant-1", model.InactiveDevicePending), nil)
+		},
+
+		reports: []string{"tenant-1"},
+	}, {
+		desc:    "error, failed devices",
+		tenants: []string{"tenant-1", "tenant-2"},
+		setup: func(app *mocks.App) {
+			app.On("ApplyInactivityPolicy", tenantMatcher("tenant-1"), true).
+				Return(report("tenant-1", model.InactiveDeviceFailed), nil)
+			app.On("ApplyInactivityPolicy", tenantMatcher("tenant-2"), true).
+				Return(report("tenant-2", model.InactiveDeviceRejected), nil)
+		},
+
+		reports: []string{"tenant-1", "tenant-2"},
+		err:     "failed to apply the inactivity policy to 1 devices",
+	}, {
+		desc:    "error, tenant",
+		tenants: []string{"tenant-1", "tenant-2"},
+		setup: func(app *mocks.App) {
+			app.On("ApplyInactivityPolicy", tenantMatcher("tenant-1"), true).
+				Return(nil, errors.New("mongo"))
+		},
+
+		err: "mongo",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			app := &mocks.App{}
+			defer app.AssertExpectations(t)
+			tc.setup(app)
+
+			ds := &mstore.DataStore{}
+			defer ds.AssertExpectations(t)
+			if tc.tenant == "" {
+				ds.On("ForEachTenant",
+					mock.MatchedBy(func(ctx context.Context) bool { return true }),
+					mock.AnythingOfType("store.MapFunc"),
+				).Return(func(ctx context.Context, mapFunc store.MapFunc) error {
+					// A simplified version of what
+					// mongo.ForEachTenant does
+					for _, tenant := range tc.tenants {
+						tenantCtx := ctx
+						if tenant != "" {
+							tenantCtx = identity.WithContext(ctx,
+								&identity.Identity{Tenant: tenant})
+						}
+						if err := mapFunc(tenantCtx); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			}
+
+			out := &bytes.Buffer{}
+			err := ApplyInactivityPolicies(app, ds, tc.tenant, true, out)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tenants := []string{}
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				if line == "" {
+					continue
+				}
+				var report model.InactivityReport
+				assert.NoError(t, json.Unmarshal([]byte(line), &report))
+				tenants = append(tenants, report.TenantID)
+			}
+			if tc.reports == nil {
+				tc.reports = []string{}
+			}
+			assert.Equal(t, tc.reports, tenants)
+		})
+	}
+}
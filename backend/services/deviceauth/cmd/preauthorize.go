@@ -0,0 +1,84 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+)
+
+// PreAuthFormatFromPath returns the format of the bulk preauthorization
+// file from its extension.
+func PreAuthFormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return model.PreAuthFormatCSV, nil
+	case ".ndjson", ".jsonl":
+		return model.PreAuthFormatNDJSON, nil
+	default:
+		return "", errors.Errorf(
+			"cannot tell the format of %q from its extension", path)
+	}
+}
+
+// PreauthorizeDevices preauthorizes the devices of the tenant listed in the
+// CSV or NDJSON source and writes the results as JSON to out; the error is
+// returned if any of the devices could not be preauthorized.
+func PreauthorizeDevices(
+	app devauth.App,
+	tenant string,
+	source io.Reader,
+	format string,
+	batchSize int,
+	dryRun bool,
+	out io.Writer,
+) error {
+	records, err := model.ReadPreAuthRecords(source, format)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if tenant != "" {
+		ctx = identity.WithContext(ctx, &identity.Identity{
+			Tenant: tenant,
+		})
+	}
+	res, err := app.PreauthorizeDevices(ctx, records, batchSize, dryRun)
+	if res != nil {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(res); encErr != nil && err == nil {
+			err = errors.Wrap(encErr, "failed to write the results")
+		}
+	}
+	if err != nil {
+		return err
+	} else if res.Failed > 0 {
+		return errors.Errorf("failed to preauthorize %d out of %d devices",
+			res.Failed, len(records))
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+)
+
+func TestPreAuthFormatFromPath(t *testing.T) {
+	format, err := PreAuthFormatFromPath("/tmp/batch-42.CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, model.PreAuthFormatCSV, format)
+
+	format, err = PreAuthFormatFromPath("batch-42.jsonl")
+	assert.NoError(t, err)
+	assert.Equal(t, model.PreAuthFormatNDJSON, format)
+
+	_, err = PreAuthFormatFromPath("batch-42.xlsx")
+	assert.EqualError(t, err, `cannot tell the format of "batch-42.xlsx" from its extension`)
+}
+
+func TestPreauthorizeDevices(t *testing.T) {
+	const tenantID = "5abcb6de7a673a0001287c71"
+
+	testCases := []struct {
+		desc string
+
+		file   string
+		result *model.PreAuthBulkResult
+
+		err string
+	}{{
+		desc: "ok",
+		file: "sn,pubkey\nsn-1,key\n",
+		result: &model.PreAuthBulkResult{
+			DryRun:    true,
+			Unchanged: 1,
+			Results: []model.PreAuthRecordResult{{
+				Line:   2,
+				Status: model.PreAuthResultUnchanged,
+			}},
+		},
+	}, {
+		desc: "error, failed records",
+		file: "sn,pubkey\nsn-1,key\n",
+		result: &model.PreAuthBulkResult{
+			DryRun: true,
+			Failed: 1,
+			Results: []model.PreAuthRecordResult{{
+				Line:   2,
+				Status: model.PreAuthResultInvalid,
+				Error:  "cannot decode public key",
+			}},
+		},
+
+		err: "failed to preauthorize 1 out of 1 devices",
+	}, {
+		desc: "error, file",
+		file: "sn\nsn-1\n",
+
+		err: model.ErrPreAuthNoPubKey.Error(),
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			app := &mocks.App{}
+			defer app.AssertExpectations(t)
+			if tc.result != nil {
+				app.On("PreauthorizeDevices",
+					mock.MatchedBy(func(ctx context.Context) bool {
+						id := identity.FromContext(ctx)
+						return id != nil && id.Tenant == tenantID
+					}),
+					[]model.PreAuthRecord{{
+						Line:   2,
+						IdData: map[string]interface{}{"sn": "sn-1"},
+						PubKey: "key",
+					}},
+					10,
+					true).
+					Return(tc.result, nil)
+			}
+
+			out := &bytes.Buffer{}
+			err := PreauthorizeDevices(app, tenantID,
+				strings.NewReader(tc.file), model.PreAuthFormatCSV, 10, true, out)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.result != nil {
+				var res model.PreAuthBulkResult
+				assert.NoError(t, json.Unmarshal(out.Bytes(), &res))
+				assert.Equal(t, *tc.result, res)
+			}
+		})
+	}
+}
@@ -55,6 +55,16 @@ const (
 	SettingServerPrivKeyPath        = "server_priv_key_path"
 	SettingServerPrivKeyPathDefault = "/etc/deviceauth/rsa/private.pem"
 
+	// SettingServerPrivKeyFileNamePattern is the pattern of the file names
+	// of the keys of the keyring, in the directory of the active key; the
+	// first submatch is the id of the key.
+	SettingServerPrivKeyFileNamePattern        = "server_priv_key_filename_pattern"
+	SettingServerPrivKeyFileNamePatternDefault = "private\\.id\\.([0-9]*)\\.pem"
+
+	// SettingServerRetiredKeyIds are the ids of the keys no longer
+	// verifying the tokens nor published to the verifiers.
+	SettingServerRetiredKeyIds = "server_retired_key_ids"
+
 	SettingServerFallbackPrivKeyPath        = "server_fallback_priv_key_path"
 	SettingServerFallbackPrivKeyPathDefault = ""
 
@@ -80,6 +90,20 @@ const (
 	SettingHaveAddons        = "have_addons"
 	SettingHaveAddonsDefault = false
 
+	// SettingClientCertificateHeader is the header the gateway terminating
+	// mTLS passes the client certificate of the device in, either URL
+	// escaped PEM or base64 encoded DER. Empty disables the header; the
+	// gateway must strip the header from the incoming requests.
+	SettingClientCertificateHeader        = "client_certificate_header"
+	SettingClientCertificateHeaderDefault = ""
+
+	// SettingClientIPProxyDepth is the number of proxies in front of the
+	// service appending to the X-Forwarded-For header; the source address
+	// of the auth requests matched by the auto-accept rules is taken from
+	// the header at this depth. Zero uses the address of the connection.
+	SettingClientIPProxyDepth        = "client_ip_proxy_depth"
+	SettingClientIPProxyDepthDefault = 1
+
 	// Max Request body size
 	SettingMaxRequestSize        = "request_size_limit"
 	SettingMaxRequestSizeDefault = 1024 * 1024 // 1 MiB
@@ -97,6 +121,9 @@ var (
 		{Key: SettingTenantAdmAddr, Value: SettingTenantAdmAddrDefault},
 		{Key: SettingDefaultTenantToken, Value: SettingDefaultTenantTokenDefault},
 		{Key: SettingServerPrivKeyPath, Value: SettingServerPrivKeyPathDefault},
+		{Key: SettingServerPrivKeyFileNamePattern,
+			Value: SettingServerPrivKeyFileNamePatternDefault},
+		{Key: SettingServerRetiredKeyIds, Value: []string{}},
 		{Key: SettingServerFallbackPrivKeyPath, Value: SettingServerFallbackPrivKeyPathDefault},
 		{Key: SettingJWTIssuer, Value: SettingJWTIssuerDefault},
 		{Key: SettingJWTExpirationTimeout, Value: SettingJWTExpirationTimeoutDefault},
@@ -106,5 +133,7 @@ var (
 		{Key: SettingRedisKeyPrefix, Value: SettingRedisKeyPrefixDefault},
 		{Key: SettingHaveAddons, Value: SettingHaveAddonsDefault},
 		{Key: SettingMaxRequestSize, Value: SettingMaxRequestSizeDefault},
+		{Key: SettingClientCertificateHeader, Value: SettingClientCertificateHeaderDefault},
+		{Key: SettingClientIPProxyDepth, Value: SettingClientIPProxyDepthDefault},
 	}
 )
@@ -0,0 +1,153 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package devauth
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/log"
+	"github.com/mendersoftware/mender-server/pkg/mongo/oid"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+func (d *DevAuth) GetAutoAcceptRules(ctx context.Context) ([]model.AutoAcceptRule, error) {
+	rules, err := d.db.GetAutoAcceptRules(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list auto-accept rules")
+	}
+	return rules, nil
+}
+
+func (d *DevAuth) GetAutoAcceptRule(
+	ctx context.Context,
+	id string,
+) (*model.AutoAcceptRule, error) {
+	return d.db.GetAutoAcceptRule(ctx, id)
+}
+
+func (d *DevAuth) AddAutoAcceptRule(
+	ctx context.Context,
+	req model.AutoAcceptRuleReq,
+) (*model.AutoAcceptRule, error) {
+	rule := model.NewAutoAcceptRule(req)
+	if err := d.db.AddAutoAcceptRule(ctx, *rule); err != nil {
+		return nil, errors.Wrap(err, "failed to add auto-accept rule")
+	}
+	return rule, nil
+}
+
+func (d *DevAuth) DeleteAutoAcceptRule(ctx context.Context, id string) error {
+	return d.db.DeleteAutoAcceptRule(ctx, id)
+}
+
+func (d *DevAuth) GetAutoAcceptAudit(
+	ctx context.Context,
+	filter model.AutoAcceptAuditFilter,
+	skip,
+	limit uint,
+) ([]model.AutoAcceptAudit, error) {
+	entries, err := d.db.GetAutoAcceptAudit(ctx, filter, skip, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list auto-accept audit")
+	}
+	return entries, nil
+}
+
+// autoAcceptDevice accepts the pending auth set if the device matches one
+// of the auto-accept rules of the tenant with budget left; the rules are
+// evaluated oldest first and the first matching one takes one accept from
+// its budget. The accept is returned to the budget if the device cannot be
+// accepted, e.g. because of the device limit. The rules never apply to a new
+// auth set of a device which is already accepted or preauthorized: accepting
+// it would reject the auth sets the device is enrolled with.
+func (d *DevAuth) autoAcceptDevice(
+	ctx context.Context,
+	r *model.AuthReq,
+	aset *model.AuthSet,
+	tokenVariant string,
+) (*model.AuthSet, error) {
+	l := log.FromContext(ctx)
+
+	rules, err := d.db.GetAutoAcceptRules(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list auto-accept rules")
+	} else if len(rules) == 0 {
+		return aset, nil
+	}
+
+	dev, err := d.db.GetDeviceById(ctx, aset.DeviceId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the device")
+	}
+	switch dev.Status {
+	case model.DevStatusAccepted, model.DevStatusPreauth:
+		l.Warnf("auto-accept rules skipped for the new auth set %s of the %s device %s",
+			aset.Id, dev.Status, dev.Id)
+		return aset, nil
+	}
+
+	idData, _, err := parseIdData(r.IdData)
+	if err != nil {
+		return nil, MakeErrDevAuthBadRequest(err)
+	}
+	now := d.clock.Now()
+	for _, rule := range rules {
+		if !rule.Matches(idData, r.SourceIP, tokenVariant, now) {
+			continue
+		}
+		err = d.db.ConsumeAutoAcceptRule(ctx, rule.Id)
+		if err == store.ErrAutoAcceptRuleExhausted {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to update auto-accept rule")
+		}
+
+		accepted, err := d.handlePreAuthDevice(ctx, aset)
+		if err != nil {
+			if errRelease := d.db.ReleaseAutoAcceptRule(ctx, rule.Id); errRelease != nil {
+				l.Errorf("failed to release auto-accept rule %s: %s",
+					rule.Id, errRelease.Error())
+			}
+			return nil, err
+		}
+
+		audit := model.AutoAcceptAudit{
+			Id:           oid.NewUUIDv4().String(),
+			RuleId:       rule.Id,
+			RuleName:     rule.Name,
+			DeviceId:     accepted.DeviceId,
+			AuthSetId:    accepted.Id,
+			IdentityData: idData,
+			TenantToken:  tokenVariant,
+			Timestamp:    now,
+		}
+		if r.SourceIP != nil {
+			audit.SourceIP = r.SourceIP.String()
+		}
+		if err := d.db.AddAutoAcceptAudit(ctx, audit); err != nil {
+			l.Errorf("failed to record device %s accepted by auto-accept rule %s: %s",
+				accepted.DeviceId, rule.Id, err.Error())
+		} else {
+			l.Infof("device %s accepted by auto-accept rule %s",
+				accepted.DeviceId, rule.Id)
+		}
+		return accepted, nil
+	}
+	return aset, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package devauth
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	morchestrator "github.com/mendersoftware/mender-server/services/deviceauth/client/orchestrator/mocks"
+	mjwt "github.com/mendersoftware/mender-server/services/deviceauth/jwt/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+	mstore "github.com/mendersoftware/mender-server/services/deviceauth/store/mocks"
+	mtesting "github.com/mendersoftware/mender-server/services/deviceauth/utils/testing"
+)
+
+func TestDevAuthAutoAcceptDevice(t *testing.T) {
+	t.Parallel()
+
+	const (
+		devID  = "0e4b3ac1-f2bc-4b2e-a6ab-cae26b5e8d23"
+		authID = "1a6a6d6b-e3ad-4bc2-8e06-3a0bd95d6a52"
+		ruleID = "4b1b0a6e-2f1b-4d3c-9f5a-7d4cbcd2a7e1"
+		idData = `{"mac":"00:11:22:33:44:55"}`
+	)
+
+	factoryRule := model.AutoAcceptRule{
+		Id:          ruleID,
+		Name:        "factory",
+		Identity:    []model.AutoAcceptIdentityMatch{{Key: "mac", Regex: "^00:11:"}},
+		SourceCIDRs: []string{"10.0.0.0/8"},
+		TenantToken: model.TenantTokenAny,
+		MaxAccepts:  10,
+	}
+	otherRule := model.AutoAcceptRule{
+		Id:          "other",
+		Identity:    []model.AutoAcceptIdentityMatch{{Key: "serial"}},
+		TenantToken: model.TenantTokenAny,
+		MaxAccepts:  10,
+	}
+
+	testCases := []struct {
+		desc string
+
+		sourceIP net.IP
+		rules    []model.AutoAcceptRule
+		rulesErr error
+
+		devStatus  string
+		consumeErr error
+		devCount   int
+		auditErr   error
+
+		status string
+		err    string
+	}{{
+		desc:     "ok, device accepted",
+		sourceIP: net.ParseIP("10.1.2.3"),
+		rules:    []model.AutoAcceptRule{otherRule, factoryRule},
+		status:   model.DevStatusAccepted,
+	}, {
+		desc:      "ok, accepted device with another key stays pending",
+		sourceIP:  net.ParseIP("10.1.2.3"),
+		rules:     []model.AutoAcceptRule{factoryRule},
+		devStatus: model.DevStatusAccepted,
+		status:    model.DevStatusPending,
+	}, {
+		desc:      "ok, preauthorized device with another key stays pending",
+		sourceIP:  net.ParseIP("10.1.2.3"),
+		rules:     []model.AutoAcceptRule{factoryRule},
+		devStatus: model.DevStatusPreauth,
+		status:    model.DevStatusPending,
+	}, {
+		desc:     "ok, audit failure does not fail the accept",
+		sourceIP: net.ParseIP("10.1.2.3"),
+		rules:    []model.AutoAcceptRule{factoryRule},
+		auditErr: errors.New("internal error"),
+		status:   model.DevStatusAccepted,
+	}, {
+		desc:   "ok, no rules",
+		status: model.DevStatusPending,
+	}, {
+		desc:     "ok, source address not matching",
+		sourceIP: net.ParseIP("192.168.1.2"),
+		rules:    []model.AutoAcceptRule{factoryRule},
+		status:   model.DevStatusPending,
+	}, {
+		desc:       "ok, budget used up concurrently",
+		sourceIP:   net.ParseIP("10.1.2.3"),
+		rules:      []model.AutoAcceptRule{factoryRule},
+		consumeErr: store.ErrAutoAcceptRuleExhausted,
+		status:     model.DevStatusPending,
+	}, {
+		desc:     "error, device limit reached",
+		sourceIP: net.ParseIP("10.1.2.3"),
+		rules:    []model.AutoAcceptRule{factoryRule},
+		devCount: 5,
+		err:      ErrMaxDeviceCountReached.Error(),
+	}, {
+		desc:     "error, listing the rules",
+		rulesErr: errors.New("internal error"),
+		err:      "failed to list auto-accept rules: internal error",
+	}, {
+		desc:       "error, consuming the rule",
+		sourceIP:   net.ParseIP("10.1.2.3"),
+		rules:      []model.AutoAcceptRule{factoryRule},
+		consumeErr: errors.New("internal error"),
+		err:        "failed to update auto-accept rule: internal error",
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctxMatcher := mtesting.ContextMatcher()
+			db := &mstore.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("GetAutoAcceptRules", ctxMatcher).Return(tc.rules, tc.rulesErr)
+			db.On("ConsumeAutoAcceptRule", ctxMatcher, ruleID).
+				Return(tc.consumeErr).Maybe()
+			if tc.devStatus == "" {
+				tc.devStatus = model.DevStatusPending
+			}
+			db.On("GetDeviceById", ctxMatcher, devID).
+				Return(&model.Device{
+					Id:     devID,
+					Status: tc.devStatus,
+				}, nil).Maybe()
+
+			db.On("GetLimit", ctxMatcher, model.LimitMaxDeviceCount).
+				Return(&model.Limit{Value: 5}, nil).Maybe()
+			db.On("GetDevCountByStatus", ctxMatcher, model.DevStatusAccepted).
+				Return(tc.devCount, nil).Maybe()
+			db.On("RejectAuthSetsForDevice", ctxMatcher, devID).
+				Return(nil).Maybe()
+			db.On("UpdateAuthSetById", ctxMatcher, authID,
+				model.AuthSetUpdate{Status: model.DevStatusAccepted},
+			).Return(nil).Maybe()
+			db.On("GetDeviceStatus", ctxMatcher, devID).
+				Return(model.DevStatusAccepted, nil).Maybe()
+			db.On("UpdateDevice", ctxMatcher, devID, mock.AnythingOfType("model.DeviceUpdate")).
+				Return(nil).Maybe()
+			db.On("AddAutoAcceptAudit", ctxMatcher,
+				mock.MatchedBy(func(a model.AutoAcceptAudit) bool {
+					return assert.Equal(t, ruleID, a.RuleId) &&
+						assert.Equal(t, "factory", a.RuleName) &&
+						assert.Equal(t, devID, a.DeviceId) &&
+						assert.Equal(t, authID, a.AuthSetId) &&
+						assert.Equal(t, "10.1.2.3", a.SourceIP) &&
+						assert.Equal(t, model.TenantTokenSupplied, a.TenantToken) &&
+						assert.Equal(t, "00:11:22:33:44:55", a.IdentityData["mac"])
+				})).Return(tc.auditErr).Maybe()
+			if tc.devCount > 0 {
+				db.On("ReleaseAutoAcceptRule", ctxMatcher, ruleID).Return(nil)
+			}
+
+			co := &morchestrator.ClientRunner{}
+			co.On("SubmitProvisionDeviceJob", ctxMatcher,
+				mock.AnythingOfType("orchestrator.ProvisionDeviceReq")).
+				Return(nil).Maybe()
+			co.On("SubmitUpdateDeviceStatusJob", ctxMatcher,
+				mock.AnythingOfType("orchestrator.UpdateDeviceStatusReq")).
+				Return(nil).Maybe()
+
+			devauth := NewDevAuth(db, co, &mjwt.Handler{}, Config{})
+			aset, err := devauth.autoAcceptDevice(context.Background(),
+				&model.AuthReq{
+					IdData:   idData,
+					SourceIP: tc.sourceIP,
+				},
+				&model.AuthSet{
+					Id:       authID,
+					IdData:   idData,
+					DeviceId: devID,
+					Status:   model.DevStatusPending,
+				},
+				model.TenantTokenSupplied,
+			)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.status, aset.Status)
+			}
+			if tc.devStatus != model.DevStatusPending {
+				// the rule is not consumed and no auth set is rejected
+				db.AssertNotCalled(t, "ConsumeAutoAcceptRule", ctxMatcher, ruleID)
+				db.AssertNotCalled(t, "RejectAuthSetsForDevice", ctxMatcher, devID)
+			}
+		})
+	}
+}
+
+func TestDevAuthAddAutoAcceptRule(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := &mstore.DataStore{}
+	defer db.AssertExpectations(t)
+
+	req := model.AutoAcceptRuleReq{
+		Name:        "factory",
+		SourceCIDRs: []string{"10.0.0.0/8"},
+		MaxAccepts:  10,
+	}
+	db.On("AddAutoAcceptRule", ctx, mock.MatchedBy(func(r model.AutoAcceptRule) bool {
+		return r.Name == "factory" && r.TenantToken == model.TenantTokenAny
+	})).Return(nil).Once()
+	db.On("AddAutoAcceptRule", ctx, mock.AnythingOfType("model.AutoAcceptRule")).
+		Return(errors.New("internal error")).Once()
+
+	devauth := NewDevAuth(db, nil, nil, Config{})
+	rule, err := devauth.AddAutoAcceptRule(ctx, req)
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, rule.Id)
+		assert.Equal(t, []string{"10.0.0.0/8"}, rule.SourceCIDRs)
+	}
+
+	_, err = devauth.AddAutoAcceptRule(ctx, req)
+	assert.EqualError(t, err, "failed to add auto-accept rule: internal error")
+}
@@ -0,0 +1,211 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package devauth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+	"github.com/mendersoftware/mender-server/services/deviceauth/utils"
+)
+
+var (
+	ErrCACertificateExists    = errors.New("CA certificate already exists")
+	ErrCertificateKeyMismatch = errors.New("the public key does not match the certificate")
+	ErrCertificateUntrusted   = errors.New("the certificate is not issued by a trusted CA")
+	ErrCertificateRevoked     = errors.New("the certificate is revoked")
+)
+
+func (d *DevAuth) GetCACertificates(ctx context.Context) ([]model.CACertificate, error) {
+	certs, err := d.db.GetCACertificates(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list CA certificates")
+	}
+	return certs, nil
+}
+
+func (d *DevAuth) GetCACertificate(ctx context.Context, id string) (*model.CACertificate, error) {
+	return d.db.GetCACertificate(ctx, id)
+}
+
+func (d *DevAuth) AddCACertificate(
+	ctx context.Context,
+	req model.CACertificateReq,
+) (*model.CACertificate, error) {
+	cert, err := model.NewCACertificate(req)
+	if err != nil {
+		return nil, MakeErrDevAuthBadRequest(err)
+	}
+	err = d.db.AddCACertificate(ctx, *cert)
+	switch err {
+	case nil:
+		return cert, nil
+	case store.ErrObjectExists:
+		return nil, ErrCACertificateExists
+	default:
+		return nil, errors.Wrap(err, "failed to add CA certificate")
+	}
+}
+
+func (d *DevAuth) DeleteCACertificate(ctx context.Context, id string) error {
+	return d.db.DeleteCACertificate(ctx, id)
+}
+
+// SetCACertificateCRL replaces the revocation list of the CA; the list
+// must be signed by the CA.
+func (d *DevAuth) SetCACertificateCRL(
+	ctx context.Context,
+	id string,
+	data []byte,
+) (*model.CACertificate, error) {
+	cert, err := d.db.GetCACertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := cert.X509()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse CA certificate")
+	}
+	crl, err := model.ParseCertRevocationList(data, ca)
+	if err != nil {
+		return nil, MakeErrDevAuthBadRequest(err)
+	}
+	if err := d.db.SetCACertificateCRL(ctx, id, *crl); err != nil {
+		return nil, err
+	}
+	cert.CRL = crl
+	return cert, nil
+}
+
+// verifyCertificateChain verifies the certificate chain against the CA
+// certificates and returns the CA certificate issuing the chain.
+func (d *DevAuth) verifyCertificateChain(
+	chain []*x509.Certificate,
+	cas []model.CACertificate,
+) (*model.CACertificate, error) {
+	roots := x509.NewCertPool()
+	trusted := make(map[string]*model.CACertificate, len(cas))
+	for i := range cas {
+		ca, err := cas[i].X509()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse CA certificate")
+		}
+		roots.AddCert(ca)
+		trusted[cas[i].Fingerprint] = &cas[i]
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	verified, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   d.clock.Now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCertificateUntrusted, err)
+	}
+
+	// every certificate of the chain is checked against the revocation
+	// list of its issuer, if the issuer is registered
+	var issuer *model.CACertificate
+	for _, path := range verified {
+		revoked := false
+		for i := 0; i < len(path)-1; i++ {
+			ca, ok := trusted[model.CertificateFingerprint(path[i+1])]
+			if ok && ca.IsRevoked(path[i].SerialNumber) {
+				revoked = true
+				break
+			}
+		}
+		if revoked {
+			continue
+		}
+		issuer = trusted[model.CertificateFingerprint(path[len(path)-1])]
+		break
+	}
+	if issuer == nil {
+		return nil, ErrCertificateRevoked
+	}
+	return issuer, nil
+}
+
+// processCertificateAuthRequest processes the auth request of a device
+// presenting a certificate; the identity data is derived from the
+// certificate and the devices with a certificate issued by a tenant CA
+// are accepted, unless rejected by the user.
+func (d *DevAuth) processCertificateAuthRequest(
+	ctx context.Context,
+	r *model.AuthReq,
+) (*model.AuthSet, error) {
+	l := log.FromContext(ctx)
+
+	chain, err := model.ParseCertificateChain(r.Certificate)
+	if err != nil {
+		return nil, MakeErrDevAuthBadRequest(err)
+	}
+	leaf := chain[0]
+
+	// the request signature, verified against the public key of the
+	// request by the API handler, proves the device holds the private key;
+	// the key must be the one of the certificate
+	pubKey, err := utils.SerializePubKey(leaf.PublicKey)
+	if err != nil {
+		return nil, MakeErrDevAuthBadRequest(err)
+	}
+	if pubKey != r.PubKey {
+		return nil, MakeErrDevAuthUnauthorized(ErrCertificateKeyMismatch)
+	}
+
+	cas, err := d.db.GetCACertificates(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list CA certificates")
+	}
+	ca, err := d.verifyCertificateChain(chain, cas)
+	if err != nil {
+		l.Warnf("certificate %s rejected: %s", leaf.Subject, err)
+		return nil, MakeErrDevAuthUnauthorized(err)
+	}
+
+	idData, err := model.CertificateIdentity(leaf, ca.IdentityFields)
+	if err != nil {
+		return nil, MakeErrDevAuthBadRequest(err)
+	}
+	// keys are sorted by the encoder
+	b, _ := json.Marshal(idData)
+	r.IdData = string(b)
+
+	authSet, err := d.processPreAuthRequest(ctx, r)
+	if err != nil || authSet != nil {
+		return authSet, err
+	}
+	authSet, err = d.processAuthRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if authSet.Status == model.DevStatusPending {
+		return d.handlePreAuthDevice(ctx, authSet)
+	}
+	return authSet, nil
+}
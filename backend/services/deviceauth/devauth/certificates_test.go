@@ -0,0 +1,402 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package devauth
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	morchestrator "github.com/mendersoftware/mender-server/services/deviceauth/client/orchestrator/mocks"
+	mjwt "github.com/mendersoftware/mender-server/services/deviceauth/jwt/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+	mstore "github.com/mendersoftware/mender-server/services/deviceauth/store/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/utils"
+	mtesting "github.com/mendersoftware/mender-server/services/deviceauth/utils/testing"
+)
+
+func TestDevAuthSubmitAuthRequestCertificate(t *testing.T) {
+	t.Parallel()
+
+	ca, caKey := mtesting.NewCertificate(t, "ca", 1, nil, nil)
+	intermediate, intermediateKey := mtesting.NewIntermediateCertificate(t,
+		"intermediate", 2, ca, caKey)
+	leaf, _ := mtesting.NewCertificate(t, "device-0001", 3, ca, caKey)
+	chainLeaf, _ := mtesting.NewCertificate(t,
+		"device-0002", 4, intermediate, intermediateKey)
+	other, otherKey := mtesting.NewCertificate(t, "other", 1, nil, nil)
+	otherLeaf, _ := mtesting.NewCertificate(t, "device-0003", 5, other, otherKey)
+
+	caCert, err := model.NewCACertificate(model.CACertificateReq{
+		Certificate: mtesting.CertificatePEM(ca),
+	})
+	assert.NoError(t, err)
+	revokedCACert := *caCert
+	revokedCACert.CRL, err = model.ParseCertRevocationList(
+		mtesting.NewRevocationList(t, ca, caKey, 3), ca)
+	assert.NoError(t, err)
+	snCACert := *caCert
+	snCACert.IdentityFields = []model.CertIdentityField{{
+		Key:   "sn",
+		Field: model.CertFieldSerialNumber,
+	}}
+
+	pubKey := func(cert *x509.Certificate) string {
+		s, _ := utils.SerializePubKey(cert.PublicKey)
+		return s
+	}
+
+	const (
+		devID  = "0e4b3ac1-f2bc-4b2e-a6ab-cae26b5e8d23"
+		authID = "1a6a6d6b-e3ad-4bc2-8e06-3a0bd95d6a52"
+	)
+
+	testCases := []struct {
+		desc string
+
+		req *model.AuthReq
+		cas []model.CACertificate
+
+		authSetStatus string
+		devCount      int
+
+		idData string
+		token  string
+		err    string
+	}{{
+		desc: "ok, device accepted",
+		req: &model.AuthReq{
+			Certificate: mtesting.CertificatePEM(leaf),
+			PubKey:      pubKey(leaf),
+		},
+		cas:           []model.CACertificate{*caCert},
+		authSetStatus: model.DevStatusPending,
+
+		idData: `{"common_name":"device-0001"}`,
+		token:  "token",
+	}, {
+		desc: "ok, chain with intermediate certificate",
+		req: &model.AuthReq{
+			Certificate: mtesting.CertificatePEM(chainLeaf, intermediate),
+			PubKey:      pubKey(chainLeaf),
+		},
+		cas:           []model.CACertificate{*caCert},
+		authSetStatus: model.DevStatusAccepted,
+
+		idData: `{"common_name":"device-0002"}`,
+		token:  "token",
+	}, {
+		desc: "error, device rejected by the user",
+		req: &model.AuthReq{
+			Certificate: mtesting.CertificatePEM(leaf),
+			PubKey:      pubKey(leaf),
+		},
+		cas:           []model.CACertificate{*caCert},
+		authSetStatus: model.DevStatusRejected,
+
+		idData: `{"common_name":"device-0001"}`,
+		err:    ErrDevAuthUnauthorized.Error(),
+	}, {
+		desc: "error, device limit reached",
+		req: &model.AuthReq{
+			Certificate: mtesting.CertificatePEM(leaf),
+			PubKey:      pubKey(leaf),
+		},
+		cas:           []model.CACertificate{*caCert},
+		authSetStatus: model.DevStatusPending,
+		devCount:      5,
+
+		idData: `{"common_name":"device-0001"}`,
+		err:    ErrMaxDeviceCountReached.Error(),
+	}, {
+		desc: "error, certificate issued by another CA",
+		req: &model.AuthReq{
+			Certificate: mtesting.CertificatePEM(otherLeaf),
+			PubKey:      pubKey(otherLeaf),
+		},
+		cas: []model.CACertificate{*caCert},
+
+		err: MsgErrDevAuthUnauthorized + ": " + ErrCertificateUntrusted.Error() +
+			": x509: certificate signed by unknown authority",
+	}, {
+		desc: "error, certificate revoked",
+		req: &model.AuthReq{
+			Certificate: mtesting.CertificatePEM(leaf),
+			PubKey:      pubKey(leaf),
+		},
+		cas: []model.CACertificate{revokedCACert},
+
+		err: MsgErrDevAuthUnauthorized + ": " + ErrCertificateRevoked.Error(),
+	}, {
+		desc: "error, public key does not match the certificate",
+		req: &model.AuthReq{
+			Certificate: mtesting.CertificatePEM(leaf),
+			PubKey:      pubKey(otherLeaf),
+		},
+		cas: []model.CACertificate{*caCert},
+
+		err: MsgErrDevAuthUnauthorized + ": " + ErrCertificateKeyMismatch.Error(),
+	}, {
+		desc: "error, certificate without the identity field",
+		req: &model.AuthReq{
+			Certificate: mtesting.CertificatePEM(leaf),
+			PubKey:      pubKey(leaf),
+		},
+		cas: []model.CACertificate{snCACert},
+
+		err: MsgErrDevAuthBadRequest +
+			": the certificate has no subject.serial_number",
+	}, {
+		desc: "error, malformed certificate",
+		req: &model.AuthReq{
+			Certificate: "garbage",
+			PubKey:      pubKey(leaf),
+		},
+
+		err: MsgErrDevAuthBadRequest + ": " + model.ErrCertificateEmpty.Error(),
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctxMatcher := mtesting.ContextMatcher()
+			db := &mstore.DataStore{}
+			defer db.AssertExpectations(t)
+
+			db.On("GetCACertificates", ctxMatcher).Return(tc.cas, nil).Maybe()
+
+			var idDataSha256 []byte
+			if tc.idData != "" {
+				_, idDataSha256, _ = parseIdData(tc.idData)
+				db.On("GetAuthSetByIdDataHashKeyByStatus",
+					ctxMatcher, idDataSha256, tc.req.PubKey, model.DevStatusPreauth,
+				).Return(nil, store.ErrAuthSetNotFound)
+				db.On("AddDevice", ctxMatcher, mock.MatchedBy(func(d model.Device) bool {
+					return d.IdData == tc.idData
+				})).Return(store.ErrObjectExists)
+				db.On("GetDeviceByIdentityDataHash", ctxMatcher, idDataSha256).
+					Return(&model.Device{
+						Id:     devID,
+						IdData: tc.idData,
+						Status: model.DevStatusPending,
+					}, nil)
+				db.On("AddAuthSet", ctxMatcher, mock.AnythingOfType("model.AuthSet")).
+					Return(nil)
+				db.On("GetDeviceStatus", ctxMatcher, devID).
+					Return(model.DevStatusPending, nil)
+				db.On("GetAuthSetByIdDataHashKey", ctxMatcher, idDataSha256, tc.req.PubKey).
+					Return(&model.AuthSet{
+						Id:           authID,
+						IdData:       tc.idData,
+						IdDataSha256: idDataSha256,
+						PubKey:       tc.req.PubKey,
+						DeviceId:     devID,
+						Status:       tc.authSetStatus,
+					}, nil)
+			}
+			if tc.authSetStatus == model.DevStatusPending {
+				db.On("GetDeviceById", ctxMatcher, devID).
+					Return(&model.Device{
+						Id:     devID,
+						Status: model.DevStatusPending,
+					}, nil)
+				db.On("GetLimit", ctxMatcher, model.LimitMaxDeviceCount).
+					Return(&model.Limit{Value: 5}, nil)
+				db.On("GetDevCountByStatus", ctxMatcher, model.DevStatusAccepted).
+					Return(tc.devCount, nil)
+				db.On("RejectAuthSetsForDevice", ctxMatcher, devID).
+					Return(nil).Maybe()
+				db.On("UpdateAuthSetById", ctxMatcher, authID,
+					model.AuthSetUpdate{Status: model.DevStatusAccepted},
+				).Return(nil).Maybe()
+			}
+			db.On("UpdateDevice", ctxMatcher, devID, mock.AnythingOfType("model.DeviceUpdate")).
+				Return(nil).Maybe()
+			db.On("AddToken", ctxMatcher, mock.AnythingOfType("*jwt.Token")).
+				Return(nil).Maybe()
+
+			co := &morchestrator.ClientRunner{}
+			co.On("SubmitProvisionDeviceJob", ctxMatcher,
+				mock.AnythingOfType("orchestrator.ProvisionDeviceReq")).
+				Return(nil).Maybe()
+			co.On("SubmitUpdateDeviceStatusJob", ctxMatcher,
+				mock.AnythingOfType("orchestrator.UpdateDeviceStatusReq")).
+				Return(nil).Maybe()
+			co.On("SubmitUpdateDeviceInventoryJob", ctxMatcher,
+				mock.AnythingOfType("orchestrator.UpdateDeviceInventoryReq")).
+				Return(nil).Maybe()
+
+			jwth := &mjwt.Handler{}
+			jwth.On("ToJWT", mock.AnythingOfType("*jwt.Token")).
+				Return("token", nil).Maybe()
+
+			devauth := NewDevAuth(db, co, jwth, Config{})
+			token, err := devauth.SubmitAuthRequest(context.Background(), tc.req)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.token, token)
+				assert.Equal(t, tc.idData, tc.req.IdData)
+			}
+		})
+	}
+}
+
+func TestDevAuthAddCACertificate(t *testing.T) {
+	t.Parallel()
+
+	ca, caKey := mtesting.NewCertificate(t, "ca", 1, nil, nil)
+	leaf, _ := mtesting.NewCertificate(t, "device", 2, ca, caKey)
+
+	testCases := []struct {
+		desc string
+
+		req   model.CACertificateReq
+		dbErr error
+
+		err string
+	}{{
+		desc: "ok",
+		req: model.CACertificateReq{
+			Certificate: mtesting.CertificatePEM(ca),
+		},
+	}, {
+		desc: "error, already registered",
+		req: model.CACertificateReq{
+			Certificate: mtesting.CertificatePEM(ca),
+		},
+		dbErr: store.ErrObjectExists,
+		err:   ErrCACertificateExists.Error(),
+	}, {
+		desc: "error, internal",
+		req: model.CACertificateReq{
+			Certificate: mtesting.CertificatePEM(ca),
+		},
+		dbErr: errors.New("mongo"),
+		err:   "failed to add CA certificate: mongo",
+	}, {
+		desc: "error, not a CA",
+		req: model.CACertificateReq{
+			Certificate: mtesting.CertificatePEM(leaf),
+		},
+		err: MsgErrDevAuthBadRequest + ": " + model.ErrCertificateNotCA.Error(),
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			db := &mstore.DataStore{}
+			defer db.AssertExpectations(t)
+			db.On("AddCACertificate", ctx, mock.MatchedBy(func(c model.CACertificate) bool {
+				return c.Fingerprint == model.CertificateFingerprint(ca)
+			})).Return(tc.dbErr).Maybe()
+
+			devauth := NewDevAuth(db, nil, nil, Config{})
+			cert, err := devauth.AddCACertificate(ctx, tc.req)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, model.CertificateFingerprint(ca), cert.Fingerprint)
+			}
+		})
+	}
+}
+
+func TestDevAuthSetCACertificateCRL(t *testing.T) {
+	t.Parallel()
+
+	ca, caKey := mtesting.NewCertificate(t, "ca", 1, nil, nil)
+	other, otherKey := mtesting.NewCertificate(t, "other", 1, nil, nil)
+	caCert, err := model.NewCACertificate(model.CACertificateReq{
+		Certificate: mtesting.CertificatePEM(ca),
+	})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc string
+
+		crl      []byte
+		getErr   error
+		setErr   error
+		setCalls bool
+
+		err string
+	}{{
+		desc:     "ok",
+		crl:      mtesting.NewRevocationList(t, ca, caKey, 2, 3),
+		setCalls: true,
+	}, {
+		desc:   "error, CA not found",
+		crl:    mtesting.NewRevocationList(t, ca, caKey, 2, 3),
+		getErr: store.ErrCACertificateNotFound,
+		err:    store.ErrCACertificateNotFound.Error(),
+	}, {
+		desc: "error, signed by another CA",
+		crl:  mtesting.NewRevocationList(t, other, otherKey, 2),
+		err: MsgErrDevAuthBadRequest +
+			": revocation list not signed by the CA: " +
+			"x509: ECDSA verification failure",
+	}, {
+		desc:     "error, internal",
+		crl:      mtesting.NewRevocationList(t, ca, caKey, 2, 3),
+		setErr:   errors.New("mongo"),
+		setCalls: true,
+		err:      "mongo",
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			db := &mstore.DataStore{}
+			defer db.AssertExpectations(t)
+
+			if tc.getErr != nil {
+				db.On("GetCACertificate", ctx, caCert.Id).Return(nil, tc.getErr)
+			} else {
+				c := *caCert
+				db.On("GetCACertificate", ctx, caCert.Id).Return(&c, nil)
+			}
+			if tc.setCalls {
+				db.On("SetCACertificateCRL", ctx, caCert.Id,
+					mock.MatchedBy(func(crl model.CertRevocationList) bool {
+						return assert.Equal(t, []string{"2", "3"}, crl.RevokedSerials)
+					}),
+				).Return(tc.setErr)
+			}
+
+			devauth := NewDevAuth(db, nil, nil, Config{})
+			cert, err := devauth.SetCACertificateCRL(ctx, caCert.Id, tc.crl)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, 2, cert.CRL.Revoked)
+			}
+		})
+	}
+}
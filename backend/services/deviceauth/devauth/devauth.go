@@ -25,6 +25,7 @@ import (
 	"github.com/mendersoftware/mender-server/pkg/addons"
 	ctxhttpheader "github.com/mendersoftware/mender-server/pkg/context/httpheader"
 	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/keys"
 	"github.com/mendersoftware/mender-server/pkg/log"
 	"github.com/mendersoftware/mender-server/pkg/mongo/oid"
 	"github.com/mendersoftware/mender-server/pkg/plan"
@@ -98,10 +99,17 @@ type App interface {
 	RejectDeviceAuth(ctx context.Context, dev_id string, auth_id string) error
 	ResetDeviceAuth(ctx context.Context, dev_id string, auth_id string) error
 	PreauthorizeDevice(ctx context.Context, req *model.PreAuthReq) (*model.Device, error)
+	PreauthorizeDevices(
+		ctx context.Context,
+		records []model.PreAuthRecord,
+		batchSize int,
+		dryRun bool,
+	) (*model.PreAuthBulkResult, error)
 
 	RevokeToken(ctx context.Context, tokenID string) error
 	VerifyToken(ctx context.Context, token string) error
 	DeleteTokens(ctx context.Context, tenantID, deviceID string) error
+	GetJWKS(ctx context.Context) (*keys.JWKS, error)
 
 	SetTenantLimit(ctx context.Context, tenant_id string, limit model.Limit) error
 	DeleteTenantLimit(ctx context.Context, tenant_id string, limit string) error
@@ -112,6 +120,31 @@ type App interface {
 	GetDevCountByStatus(ctx context.Context, status string) (int, error)
 
 	GetTenantDeviceStatus(ctx context.Context, tenantId, deviceId string) (*model.Status, error)
+
+	GetCACertificates(ctx context.Context) ([]model.CACertificate, error)
+	GetCACertificate(ctx context.Context, id string) (*model.CACertificate, error)
+	AddCACertificate(ctx context.Context, req model.CACertificateReq) (*model.CACertificate, error)
+	DeleteCACertificate(ctx context.Context, id string) error
+	SetCACertificateCRL(ctx context.Context, id string, crl []byte) (*model.CACertificate, error)
+
+	GetAutoAcceptRules(ctx context.Context) ([]model.AutoAcceptRule, error)
+	GetAutoAcceptRule(ctx context.Context, id string) (*model.AutoAcceptRule, error)
+	AddAutoAcceptRule(ctx context.Context, req model.AutoAcceptRuleReq) (*model.AutoAcceptRule, error)
+	DeleteAutoAcceptRule(ctx context.Context, id string) error
+	GetAutoAcceptAudit(
+		ctx context.Context,
+		filter model.AutoAcceptAuditFilter,
+		skip,
+		limit uint,
+	) ([]model.AutoAcceptAudit, error)
+
+	GetInactivityPolicy(ctx context.Context) (*model.InactivityPolicy, error)
+	SetInactivityPolicy(
+		ctx context.Context,
+		policy model.InactivityPolicy,
+	) (*model.InactivityPolicy, error)
+	DeleteInactivityPolicy(ctx context.Context) error
+	ApplyInactivityPolicy(ctx context.Context, dryRun bool) (*model.InactivityReport, error)
 }
 
 type DevAuth struct {
@@ -296,19 +329,23 @@ func (d *DevAuth) doVerifyTenant(ctx context.Context, token string) (*tenant.Ten
 	return t, nil
 }
 
+// getTenantWithDefault verifies the tenant token, falling back to the
+// default token; it returns the variant of the verified token, either
+// model.TenantTokenSupplied or model.TenantTokenDefault.
 func (d *DevAuth) getTenantWithDefault(
 	ctx context.Context,
 	tenantToken,
 	defaultToken string,
-) (context.Context, *tenant.Tenant, error) {
+) (context.Context, *tenant.Tenant, string, error) {
 	l := log.FromContext(ctx)
 
 	if tenantToken == "" && defaultToken == "" {
-		return nil, nil, MakeErrDevAuthUnauthorized(errors.New("tenant token missing"))
+		return nil, nil, "", MakeErrDevAuthUnauthorized(errors.New("tenant token missing"))
 	}
 
 	var t *tenant.Tenant
 	var err error
+	variant := model.TenantTokenSupplied
 
 	// try the provided token
 	// but continue on errors and maybe try the default token
@@ -322,6 +359,7 @@ func (d *DevAuth) getTenantWithDefault(
 	// if we still haven't selected a tenant - the token didn't work
 	// try the default one
 	if t == nil && defaultToken != "" {
+		variant = model.TenantTokenDefault
 		t, err = d.doVerifyTenant(ctx, defaultToken)
 		if err != nil {
 			l.Errorf("Failed to verify default tenant token: %s", err.Error())
@@ -331,9 +369,9 @@ func (d *DevAuth) getTenantWithDefault(
 	// none of the tokens worked
 	if err != nil {
 		if tenant.IsErrTokenVerificationFailed(err) {
-			return ctx, nil, MakeErrDevAuthUnauthorized(err)
+			return ctx, nil, "", MakeErrDevAuthUnauthorized(err)
 		}
-		return ctx, nil, err
+		return ctx, nil, "", err
 	}
 
 	tCtx := identity.WithContext(ctx, &identity.Identity{
@@ -341,7 +379,7 @@ func (d *DevAuth) getTenantWithDefault(
 		Tenant:  t.ID,
 	})
 
-	return tCtx, t, nil
+	return tCtx, t, variant, nil
 }
 
 func (d *DevAuth) SubmitAuthRequest(ctx context.Context, r *model.AuthReq) (string, error) {
@@ -349,9 +387,12 @@ func (d *DevAuth) SubmitAuthRequest(ctx context.Context, r *model.AuthReq) (stri
 
 	var tenant *tenant.Tenant
 	var err error
+	var tokenVariant string
 
 	if d.verifyTenant {
-		ctx, tenant, err = d.getTenantWithDefault(ctx, r.TenantToken, d.config.DefaultTenantToken)
+		ctx, tenant, tokenVariant, err = d.getTenantWithDefault(
+			ctx, r.TenantToken, d.config.DefaultTenantToken,
+		)
 		if err != nil {
 			return "", err
 		}
@@ -363,18 +404,35 @@ func (d *DevAuth) SubmitAuthRequest(ctx context.Context, r *model.AuthReq) (stri
 		ctx = identity.WithContext(ctx, nil)
 	}
 
-	// first, try to handle preauthorization
-	authSet, err := d.processPreAuthRequest(ctx, r)
-	if err != nil {
-		return "", err
-	}
-
-	// if not a preauth request, process with regular auth request handling
-	if authSet == nil {
-		authSet, err = d.processAuthRequest(ctx, r)
+	var authSet *model.AuthSet
+	if r.Certificate != "" {
+		// devices presenting a certificate are accepted by the tenant CAs
+		authSet, err = d.processCertificateAuthRequest(ctx, r)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		// first, try to handle preauthorization
+		authSet, err = d.processPreAuthRequest(ctx, r)
 		if err != nil {
 			return "", err
 		}
+
+		// if not a preauth request, process with regular auth request handling
+		if authSet == nil {
+			authSet, err = d.processAuthRequest(ctx, r)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		// pending devices may be accepted by the tenant's rules
+		if authSet.Status == model.DevStatusPending {
+			authSet, err = d.autoAcceptDevice(ctx, r, authSet, tokenVariant)
+			if err != nil {
+				return "", err
+			}
+		}
 	}
 
 	// request was already present in DB, check its status
@@ -1245,6 +1303,15 @@ func (d *DevAuth) validateJWTToken(ctx context.Context, jti oid.ObjectID, raw st
 	return nil
 }
 
+// GetJWKS returns the public keys verifying the device tokens.
+func (d *DevAuth) GetJWKS(ctx context.Context) (*keys.JWKS, error) {
+	jwks, err := d.jwt.JWKS()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode the public keys")
+	}
+	return jwks, nil
+}
+
 func (d *DevAuth) VerifyToken(ctx context.Context, raw string) error {
 	l := log.FromContext(ctx)
 
@@ -26,6 +26,7 @@ import (
 
 	ctxhttpheader "github.com/mendersoftware/mender-server/pkg/context/httpheader"
 	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/keys"
 	"github.com/mendersoftware/mender-server/pkg/mongo/oid"
 	"github.com/mendersoftware/mender-server/pkg/ratelimits"
 
@@ -534,6 +535,8 @@ func TestDevAuthSubmitAuthRequest(t *testing.T) {
 			}
 
 			db := mstore.DataStore{}
+			db.On("GetAutoAcceptRules", mock.Anything).
+				Return([]model.AutoAcceptRule{}, nil).Maybe()
 			db.On("AddDevice",
 				ctxMatcher,
 				mock.MatchedBy(
@@ -872,6 +875,8 @@ func TestDevAuthSubmitAuthRequestPreauth(t *testing.T) {
 
 			// setup mocks
 			db := mstore.DataStore{}
+			db.On("GetAutoAcceptRules", mock.Anything).
+				Return([]model.AutoAcceptRule{}, nil).Maybe()
 
 			// get the auth set to check if preauthorized
 			db.On("GetAuthSetByIdDataHashKeyByStatus",
@@ -1092,6 +1097,8 @@ func TestDevAuthPreauthorizeDevice(t *testing.T) {
 			})
 
 			db := mstore.DataStore{}
+			db.On("GetAutoAcceptRules", mock.Anything).
+				Return([]model.AutoAcceptRule{}, nil).Maybe()
 			if tc.callDb {
 				db.On("AddDevice",
 					ctxMatcher,
@@ -1829,6 +1836,26 @@ func TestDevAuthResetDevice(t *testing.T) {
 	}
 }
 
+func TestDevAuthGetJWKS(t *testing.T) {
+	t.Parallel()
+
+	jwks := &keys.JWKS{Keys: []keys.JWK{{KeyID: "1"}}}
+
+	jwth := &mjwt.Handler{}
+	defer jwth.AssertExpectations(t)
+	jwth.On("JWKS").Return(jwks, nil).Once()
+	jwth.On("JWKS").Return(nil, keys.ErrUnsupportedKeyType).Once()
+
+	devauth := NewDevAuth(nil, nil, jwth, Config{})
+	res, err := devauth.GetJWKS(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, jwks, res)
+
+	_, err = devauth.GetJWKS(context.Background())
+	assert.EqualError(t, err,
+		"failed to encode the public keys: "+keys.ErrUnsupportedKeyType.Error())
+}
+
 func TestDevAuthVerifyToken(t *testing.T) {
 	t.Parallel()
 
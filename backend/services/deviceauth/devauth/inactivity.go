@@ -0,0 +1,336 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package devauth
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/log"
+	"github.com/mendersoftware/mender-server/pkg/requestid"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/client/orchestrator"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+// Inventory attributes notifying of the upcoming action of the inactivity
+// policy; the attributes are set to empty strings when the notification is
+// withdrawn.
+const (
+	InventoryAttrInactivityAction      = "inactivity_action"
+	InventoryAttrInactivityActionAfter = "inactivity_action_after"
+)
+
+const inactivityBatchSize = 100
+
+func (d *DevAuth) GetInactivityPolicy(ctx context.Context) (*model.InactivityPolicy, error) {
+	return d.db.GetInactivityPolicy(ctx)
+}
+
+func (d *DevAuth) SetInactivityPolicy(
+	ctx context.Context,
+	policy model.InactivityPolicy,
+) (*model.InactivityPolicy, error) {
+	policy.UpdatedTs = d.clock.Now().UTC()
+	if err := d.db.SetInactivityPolicy(ctx, policy); err != nil {
+		return nil, errors.Wrap(err, "failed to set inactivity policy")
+	}
+	return &policy, nil
+}
+
+func (d *DevAuth) DeleteInactivityPolicy(ctx context.Context) error {
+	return d.db.DeleteInactivityPolicy(ctx)
+}
+
+// ApplyInactivityPolicy applies the inactivity policy of the tenant to the
+// accepted devices. The devices inactive for the policy's inactive days less
+// the grace days are notified through inventory attributes, and once both
+// the inactive days and the grace period are over the devices are rejected
+// or decommissioned. The notifications of the devices which checked in
+// since, or of all the devices if the tenant has no policy, are withdrawn.
+// On a dry run the report lists what would be done without changing
+// anything.
+func (d *DevAuth) ApplyInactivityPolicy(
+	ctx context.Context,
+	dryRun bool,
+) (*model.InactivityReport, error) {
+	report := &model.InactivityReport{
+		DryRun:  dryRun,
+		Devices: []model.InactiveDevice{},
+	}
+	if id := identity.FromContext(ctx); id != nil {
+		report.TenantID = id.Tenant
+	}
+	policy, err := d.db.GetInactivityPolicy(ctx)
+	if err == nil {
+		report.Policy = policy
+	} else if err != store.ErrInactivityPolicyNotFound {
+		return nil, errors.Wrap(err, "failed to fetch inactivity policy")
+	}
+	now := d.clock.Now().UTC()
+
+	err = forEachDevice(ctx,
+		func(afterID string) ([]model.Device, error) {
+			return d.db.GetInactivityNotifiedDevices(ctx, afterID, inactivityBatchSize)
+		},
+		func(dev *model.Device) {
+			last := d.lastActivity(ctx, report.TenantID, dev)
+			if policy != nil && !last.After(*dev.InactivityNotifiedTs) {
+				return
+			}
+			res := model.InactiveDevice{
+				DeviceId:     dev.Id,
+				IdData:       dev.IdDataStruct,
+				LastActivity: last,
+				Result:       model.InactiveDeviceWithdrawn,
+			}
+			if !dryRun {
+				if err := d.withdrawInactivityNotice(
+					ctx, report.TenantID, dev.Id,
+				); err != nil {
+					res.Result = model.InactiveDeviceFailed
+					res.Error = err.Error()
+				}
+			}
+			report.Add(res)
+		})
+	if err != nil {
+		return report, errors.Wrap(err, "failed to withdraw inactivity notifications")
+	} else if policy == nil {
+		return report, nil
+	}
+
+	noticeSince := policy.NoticeSince(now)
+	err = forEachDevice(ctx,
+		func(afterID string) ([]model.Device, error) {
+			return d.db.GetInactiveDevices(ctx, noticeSince, afterID, inactivityBatchSize)
+		},
+		func(dev *model.Device) {
+			last := d.lastActivity(ctx, report.TenantID, dev)
+			if !last.Before(noticeSince) {
+				// checked in since the check-in time was last saved
+				return
+			}
+			res := d.applyInactivityPolicy(ctx, report.TenantID, policy, dev, last, now, dryRun)
+			if res != nil {
+				report.Add(*res)
+			}
+		})
+	if err != nil {
+		return report, errors.Wrap(err, "failed to apply inactivity policy")
+	}
+
+	l := log.FromContext(ctx)
+	l.Infof("inactivity policy: %d notified, %d pending, %d rejected, "+
+		"%d decommissioned, %d withdrawn, %d failed (dry run: %t)",
+		report.Notified, report.Pending, report.Rejected,
+		report.Decommissioned, report.Withdrawn, report.Failed, dryRun)
+	return report, nil
+}
+
+func (d *DevAuth) applyInactivityPolicy(
+	ctx context.Context,
+	tenantID string,
+	policy *model.InactivityPolicy,
+	dev *model.Device,
+	last time.Time,
+	now time.Time,
+	dryRun bool,
+) *model.InactiveDevice {
+	res := &model.InactiveDevice{
+		DeviceId:     dev.Id,
+		IdData:       dev.IdDataStruct,
+		LastActivity: last,
+	}
+	notified := dev.InactivityNotifiedTs
+	if notified != nil && last.After(*notified) {
+		// the notification is withdrawn, unless it is a dry run
+		notified = nil
+	}
+
+	var err error
+	if notified == nil && policy.GraceDays > 0 {
+		after := policy.ActionAfter(last, &now)
+		res.Result = model.InactiveDeviceNotified
+		res.ActionAfter = &after
+		if !dryRun {
+			err = d.notifyInactiveDevice(ctx, tenantID, dev.Id, policy.Action, now, after)
+		}
+	} else if after := policy.ActionAfter(last, notified); now.Before(after) {
+		res.Result = model.InactiveDevicePending
+		res.ActionAfter = &after
+	} else if policy.Action == model.InactivityActionReject {
+		res.Result = model.InactiveDeviceRejected
+		if !dryRun {
+			err = d.rejectInactiveDevice(ctx, tenantID, dev)
+		}
+	} else {
+		res.Result = model.InactiveDeviceDecommissioned
+		if !dryRun {
+			err = d.DecommissionDevice(ctx, dev.Id)
+		}
+	}
+	if err != nil {
+		res.Result = model.InactiveDeviceFailed
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// forEachDevice applies the function to the devices listed by id in
+// batches.
+func forEachDevice(
+	ctx context.Context,
+	list func(afterID string) ([]model.Device, error),
+	apply func(dev *model.Device),
+) error {
+	afterID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		devs, err := list(afterID)
+		if err != nil {
+			return err
+		}
+		for i := range devs {
+			apply(&devs[i])
+		}
+		if len(devs) < inactivityBatchSize {
+			return nil
+		}
+		afterID = devs[len(devs)-1].Id
+	}
+}
+
+// lastActivity returns the last activity of the device, taking into account
+// the cached check-in time which is saved in the database once a day.
+func (d *DevAuth) lastActivity(
+	ctx context.Context,
+	tenantID string,
+	dev *model.Device,
+) time.Time {
+	last := dev.LastActivity()
+	if d.cache == nil {
+		return last
+	}
+	checkInTime, err := d.cache.GetCheckInTime(ctx, tenantID, dev.Id)
+	if err != nil {
+		log.FromContext(ctx).Errorf(
+			"failed to get check-in time for device %s: %s", dev.Id, err.Error())
+	} else if checkInTime != nil && checkInTime.After(last) {
+		last = *checkInTime
+	}
+	return last
+}
+
+func (d *DevAuth) rejectInactiveDevice(
+	ctx context.Context,
+	tenantID string,
+	dev *model.Device,
+) error {
+	asets, err := d.db.GetAuthSetsForDevice(ctx, dev.Id)
+	if err != nil {
+		return errors.Wrap(err, "db get auth sets error")
+	}
+	for _, aset := range asets {
+		if aset.Status != model.DevStatusAccepted {
+			continue
+		}
+		if err := d.RejectDeviceAuth(ctx, dev.Id, aset.Id); err != nil {
+			return err
+		}
+	}
+	if dev.InactivityNotifiedTs != nil {
+		// the device is notified again if it is accepted and
+		// still does not check in
+		return d.withdrawInactivityNotice(ctx, tenantID, dev.Id)
+	}
+	return nil
+}
+
+// notifyInactiveDevice notifies the device of the action of the inactivity
+// policy; the inventory is updated first, so that a device is not acted on
+// without the notification.
+func (d *DevAuth) notifyInactiveDevice(
+	ctx context.Context,
+	tenantID string,
+	deviceID string,
+	action string,
+	now time.Time,
+	after time.Time,
+) error {
+	err := d.syncInactivityNotice(ctx, tenantID, deviceID, action, after.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	return d.db.SetDeviceInactivityNotified(ctx, deviceID, &now)
+}
+
+// withdrawInactivityNotice withdraws the notification of the device; the
+// inventory is updated first, so that the notification is withdrawn again
+// on the next run if the update fails.
+func (d *DevAuth) withdrawInactivityNotice(
+	ctx context.Context,
+	tenantID string,
+	deviceID string,
+) error {
+	if err := d.syncInactivityNotice(ctx, tenantID, deviceID, "", ""); err != nil {
+		return err
+	}
+	return d.db.SetDeviceInactivityNotified(ctx, deviceID, nil)
+}
+
+func (d *DevAuth) syncInactivityNotice(
+	ctx context.Context,
+	tenantID string,
+	deviceID string,
+	action string,
+	after string,
+) error {
+	attributes := []model.DeviceAttribute{
+		{
+			Name:  InventoryAttrInactivityAction,
+			Value: action,
+			Scope: InventoryScopeSystem,
+		},
+		{
+			Name:  InventoryAttrInactivityActionAfter,
+			Value: after,
+			Scope: InventoryScopeSystem,
+		},
+	}
+	attrJson, err := json.Marshal(attributes)
+	if err != nil {
+		return errors.New("internal error: cannot marshal attributes into json")
+	}
+	if err := d.cOrch.SubmitUpdateDeviceInventoryJob(
+		ctx,
+		orchestrator.UpdateDeviceInventoryReq{
+			RequestId:  requestid.FromContext(ctx),
+			TenantId:   tenantID,
+			DeviceId:   deviceID,
+			Scope:      InventoryScopeSystem,
+			Attributes: string(attrJson),
+		}); err != nil {
+		return errors.Wrap(err, "failed to start device inventory update job")
+	}
+	return nil
+}
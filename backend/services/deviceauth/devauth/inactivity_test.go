@@ -0,0 +1,432 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package devauth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	mcache "github.com/mendersoftware/mender-server/services/deviceauth/cache/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/client/orchestrator"
+	morchestrator "github.com/mendersoftware/mender-server/services/deviceauth/client/orchestrator/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+	mstore "github.com/mendersoftware/mender-server/services/deviceauth/store/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/utils"
+	mtesting "github.com/mendersoftware/mender-server/services/deviceauth/utils/testing"
+)
+
+func TestDevAuthApplyInactivityPolicy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		tenantID = "5abcb6de7a673a0001287c71"
+		devID    = "0e4b3ac1-f2bc-4b2e-a6ab-cae26b5e8d23"
+		authID   = "6d6a0f5d-35d0-4d0c-a5f7-6b2e8b3e5e3f"
+	)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		ts := now.AddDate(0, 0, -days)
+		return &ts
+	}
+	device := func(checkIn, notified *time.Time) model.Device {
+		return model.Device{
+			Id:                   devID,
+			IdDataStruct:         map[string]interface{}{"sn": "sn-1"},
+			Status:               model.DevStatusAccepted,
+			CreatedTs:            now.AddDate(-1, 0, 0),
+			CheckInTime:          checkIn,
+			InactivityNotifiedTs: notified,
+		}
+	}
+	policy := &model.InactivityPolicy{
+		InactiveDays: 90,
+		GraceDays:    14,
+		Action:       model.InactivityActionDecommission,
+	}
+	noticeSince := policy.NoticeSince(now)
+	inventoryJob := func(action, after string) interface{} {
+		return mock.MatchedBy(func(req orchestrator.UpdateDeviceInventoryReq) bool {
+			var attrs []model.DeviceAttribute
+			if err := json.Unmarshal([]byte(req.Attributes), &attrs); err != nil {
+				return false
+			}
+			return req.TenantId == tenantID &&
+				req.DeviceId == devID &&
+				req.Scope == InventoryScopeSystem &&
+				len(attrs) == 2 &&
+				attrs[0].Name == InventoryAttrInactivityAction &&
+				attrs[0].Value == action &&
+				attrs[1].Name == InventoryAttrInactivityActionAfter &&
+				attrs[1].Value == after
+		})
+	}
+
+	testCases := []struct {
+		desc string
+
+		dryRun bool
+		cached *time.Time
+
+		setup func(db *mstore.DataStore, co *morchestrator.ClientRunner)
+
+		report *model.InactivityReport
+		err    string
+	}{{
+		desc: "ok, no policy, notification withdrawn",
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(nil, store.ErrInactivityPolicyNotFound)
+			db.On("GetInactivityNotifiedDevices", mtesting.ContextMatcher(), "", uint(100)).
+				Return([]model.Device{device(daysAgo(80), daysAgo(5))}, nil)
+			co.On("SubmitUpdateDeviceInventoryJob", mtesting.ContextMatcher(),
+				inventoryJob("", "")).
+				Return(nil)
+			db.On("SetDeviceInactivityNotified",
+				mtesting.ContextMatcher(), devID, (*time.Time)(nil)).
+				Return(nil)
+		},
+
+		report: &model.InactivityReport{
+			TenantID:  tenantID,
+			Withdrawn: 1,
+			Devices: []model.InactiveDevice{{
+				DeviceId:     devID,
+				IdData:       map[string]interface{}{"sn": "sn-1"},
+				LastActivity: *daysAgo(80),
+				Result:       model.InactiveDeviceWithdrawn,
+			}},
+		},
+	}, {
+		desc: "ok, notified",
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(policy, nil)
+			db.On("GetInactivityNotifiedDevices", mtesting.ContextMatcher(), "", uint(100)).
+				Return([]model.Device{}, nil)
+			db.On("GetInactiveDevices",
+				mtesting.ContextMatcher(), noticeSince, "", uint(100)).
+				Return([]model.Device{device(daysAgo(80), nil)}, nil)
+			co.On("SubmitUpdateDeviceInventoryJob", mtesting.ContextMatcher(),
+				inventoryJob(model.InactivityActionDecommission,
+					now.AddDate(0, 0, 14).Format(time.RFC3339))).
+				Return(nil)
+			db.On("SetDeviceInactivityNotified", mtesting.ContextMatcher(), devID, &now).
+				Return(nil)
+		},
+
+		report: &model.InactivityReport{
+			TenantID: tenantID,
+			Policy:   policy,
+			Notified: 1,
+			Devices: []model.InactiveDevice{{
+				DeviceId:     devID,
+				IdData:       map[string]interface{}{"sn": "sn-1"},
+				LastActivity: *daysAgo(80),
+				Result:       model.InactiveDeviceNotified,
+				ActionAfter:  daysAgo(-14),
+			}},
+		},
+	}, {
+		desc:   "ok, dry run",
+		dryRun: true,
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(policy, nil)
+			db.On("GetInactivityNotifiedDevices", mtesting.ContextMatcher(), "", uint(100)).
+				Return([]model.Device{device(daysAgo(3), daysAgo(5))}, nil)
+			db.On("GetInactiveDevices",
+				mtesting.ContextMatcher(), noticeSince, "", uint(100)).
+				Return([]model.Device{device(daysAgo(100), daysAgo(20))}, nil)
+		},
+
+		report: &model.InactivityReport{
+			TenantID:       tenantID,
+			DryRun:         true,
+			Policy:         policy,
+			Decommissioned: 1,
+			Withdrawn:      1,
+			Devices: []model.InactiveDevice{{
+				DeviceId:     devID,
+				IdData:       map[string]interface{}{"sn": "sn-1"},
+				LastActivity: *daysAgo(3),
+				Result:       model.InactiveDeviceWithdrawn,
+			}, {
+				DeviceId:     devID,
+				IdData:       map[string]interface{}{"sn": "sn-1"},
+				LastActivity: *daysAgo(100),
+				Result:       model.InactiveDeviceDecommissioned,
+			}},
+		},
+	}, {
+		desc: "ok, grace period not over",
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(policy, nil)
+			db.On("GetInactivityNotifiedDevices", mtesting.ContextMatcher(), "", uint(100)).
+				Return([]model.Device{device(daysAgo(95), daysAgo(5))}, nil)
+			db.On("GetInactiveDevices",
+				mtesting.ContextMatcher(), noticeSince, "", uint(100)).
+				Return([]model.Device{device(daysAgo(95), daysAgo(5))}, nil)
+		},
+
+		report: &model.InactivityReport{
+			TenantID: tenantID,
+			Policy:   policy,
+			Pending:  1,
+			Devices: []model.InactiveDevice{{
+				DeviceId:     devID,
+				IdData:       map[string]interface{}{"sn": "sn-1"},
+				LastActivity: *daysAgo(95),
+				Result:       model.InactiveDevicePending,
+				ActionAfter:  daysAgo(-9),
+			}},
+		},
+	}, {
+		desc: "ok, decommissioned",
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(policy, nil)
+			db.On("GetInactivityNotifiedDevices", mtesting.ContextMatcher(), "", uint(100)).
+				Return([]model.Device{}, nil)
+			db.On("GetInactiveDevices",
+				mtesting.ContextMatcher(), noticeSince, "", uint(100)).
+				Return([]model.Device{device(daysAgo(100), daysAgo(20))}, nil)
+			db.On("UpdateDevice", mtesting.ContextMatcher(), devID,
+				mock.MatchedBy(func(up model.DeviceUpdate) bool {
+					return up.Decommissioning != nil && *up.Decommissioning
+				})).
+				Return(nil)
+			co.On("SubmitDeviceDecommisioningJob", mtesting.ContextMatcher(),
+				mock.MatchedBy(func(req orchestrator.DecommissioningReq) bool {
+					return req.DeviceId == devID && req.TenantID == tenantID
+				})).
+				Return(nil)
+		},
+
+		report: &model.InactivityReport{
+			TenantID:       tenantID,
+			Policy:         policy,
+			Decommissioned: 1,
+			Devices: []model.InactiveDevice{{
+				DeviceId:     devID,
+				IdData:       map[string]interface{}{"sn": "sn-1"},
+				LastActivity: *daysAgo(100),
+				Result:       model.InactiveDeviceDecommissioned,
+			}},
+		},
+	}, {
+		desc: "ok, rejected without notification",
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			policy := &model.InactivityPolicy{
+				InactiveDays: 90,
+				Action:       model.InactivityActionReject,
+			}
+			aset := &model.AuthSet{
+				Id:       authID,
+				DeviceId: devID,
+				Status:   model.DevStatusAccepted,
+			}
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(policy, nil)
+			db.On("GetInactivityNotifiedDevices", mtesting.ContextMatcher(), "", uint(100)).
+				Return([]model.Device{}, nil)
+			db.On("GetInactiveDevices",
+				mtesting.ContextMatcher(), policy.InactiveSince(now), "", uint(100)).
+				Return([]model.Device{device(nil, nil)}, nil)
+			db.On("GetAuthSetsForDevice", mtesting.ContextMatcher(), devID).
+				Return([]model.AuthSet{*aset, {
+					Id:       "e2b8ab0e-6b43-4c0c-a0d8-c5a3b3e8b2c1",
+					DeviceId: devID,
+					Status:   model.DevStatusRejected,
+				}}, nil)
+			db.On("GetAuthSetById", mtesting.ContextMatcher(), authID).
+				Return(aset, nil)
+			db.On("DeleteTokenByDevId", mtesting.ContextMatcher(), mock.Anything).
+				Return(nil)
+			db.On("UpdateAuthSetById", mtesting.ContextMatcher(), authID,
+				model.AuthSetUpdate{Status: model.DevStatusRejected}).
+				Return(nil)
+			db.On("GetDeviceStatus", mtesting.ContextMatcher(), devID).
+				Return(model.DevStatusRejected, nil)
+			db.On("GetDeviceById", mtesting.ContextMatcher(), devID).
+				Return(&model.Device{Id: devID}, nil)
+			co.On("SubmitUpdateDeviceStatusJob", mtesting.ContextMatcher(),
+				mock.MatchedBy(func(req orchestrator.UpdateDeviceStatusReq) bool {
+					return req.Status == model.DevStatusRejected
+				})).
+				Return(nil)
+			db.On("UpdateDevice", mtesting.ContextMatcher(), devID,
+				mock.AnythingOfType("model.DeviceUpdate")).
+				Return(nil)
+		},
+
+		report: &model.InactivityReport{
+			TenantID: tenantID,
+			Policy: &model.InactivityPolicy{
+				InactiveDays: 90,
+				Action:       model.InactivityActionReject,
+			},
+			Rejected: 1,
+			Devices: []model.InactiveDevice{{
+				DeviceId:     devID,
+				IdData:       map[string]interface{}{"sn": "sn-1"},
+				LastActivity: now.AddDate(-1, 0, 0),
+				Result:       model.InactiveDeviceRejected,
+			}},
+		},
+	}, {
+		desc:   "ok, checked in according to the cache",
+		cached: daysAgo(1),
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(policy, nil)
+			db.On("GetInactivityNotifiedDevices", mtesting.ContextMatcher(), "", uint(100)).
+				Return([]model.Device{}, nil)
+			db.On("GetInactiveDevices",
+				mtesting.ContextMatcher(), noticeSince, "", uint(100)).
+				Return([]model.Device{device(daysAgo(100), nil)}, nil)
+		},
+
+		report: &model.InactivityReport{
+			TenantID: tenantID,
+			Policy:   policy,
+		},
+	}, {
+		desc: "error, notification",
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(policy, nil)
+			db.On("GetInactivityNotifiedDevices", mtesting.ContextMatcher(), "", uint(100)).
+				Return([]model.Device{}, nil)
+			db.On("GetInactiveDevices",
+				mtesting.ContextMatcher(), noticeSince, "", uint(100)).
+				Return([]model.Device{device(daysAgo(80), nil)}, nil)
+			co.On("SubmitUpdateDeviceInventoryJob", mtesting.ContextMatcher(),
+				mock.AnythingOfType("orchestrator.UpdateDeviceInventoryReq")).
+				Return(errors.New("workflows"))
+		},
+
+		report: &model.InactivityReport{
+			TenantID: tenantID,
+			Policy:   policy,
+			Failed:   1,
+			Devices: []model.InactiveDevice{{
+				DeviceId:     devID,
+				IdData:       map[string]interface{}{"sn": "sn-1"},
+				LastActivity: *daysAgo(80),
+				Result:       model.InactiveDeviceFailed,
+				ActionAfter:  daysAgo(-14),
+				Error:        "failed to start device inventory update job: workflows",
+			}},
+		},
+	}, {
+		desc: "error, store",
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(policy, nil)
+			db.On("GetInactivityNotifiedDevices", mtesting.ContextMatcher(), "", uint(100)).
+				Return([]model.Device{}, nil)
+			db.On("GetInactiveDevices",
+				mtesting.ContextMatcher(), noticeSince, "", uint(100)).
+				Return(nil, errors.New("mongo"))
+		},
+
+		err: "failed to apply inactivity policy: mongo",
+	}, {
+		desc: "error, policy",
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetInactivityPolicy", mtesting.ContextMatcher()).
+				Return(nil, errors.New("mongo"))
+		},
+
+		err: "failed to fetch inactivity policy: mongo",
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := identity.WithContext(context.Background(), &identity.Identity{
+				Tenant: tenantID,
+			})
+
+			db := &mstore.DataStore{}
+			defer db.AssertExpectations(t)
+			co := &morchestrator.ClientRunner{}
+			defer co.AssertExpectations(t)
+			tc.setup(db, co)
+
+			devauth := NewDevAuth(db, co, nil, Config{}).
+				WithClock(utils.NewMockClock(now.Unix()))
+			if tc.cached != nil {
+				cache := &mcache.Cache{}
+				defer cache.AssertExpectations(t)
+				cache.On("GetCheckInTime", mtesting.ContextMatcher(), tenantID, devID).
+					Return(tc.cached, nil)
+				devauth = devauth.WithCache(cache)
+			}
+
+			report, err := devauth.ApplyInactivityPolicy(ctx, tc.dryRun)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.report.Devices == nil {
+				tc.report.Devices = []model.InactiveDevice{}
+			}
+			assert.Equal(t, tc.report, report)
+		})
+	}
+}
+
+func TestDevAuthInactivityPolicy(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	policy := model.InactivityPolicy{
+		InactiveDays: 90,
+		GraceDays:    14,
+		Action:       model.InactivityActionReject,
+	}
+	expected := policy
+	expected.UpdatedTs = now
+
+	db := &mstore.DataStore{}
+	defer db.AssertExpectations(t)
+	db.On("SetInactivityPolicy", mtesting.ContextMatcher(), expected).
+		Return(nil).Once()
+	db.On("SetInactivityPolicy", mtesting.ContextMatcher(), expected).
+		Return(errors.New("mongo")).Once()
+
+	devauth := NewDevAuth(db, nil, nil, Config{}).
+		WithClock(utils.NewMockClock(now.Unix()))
+	res, err := devauth.SetInactivityPolicy(context.Background(), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, &expected, res)
+
+	_, err = devauth.SetInactivityPolicy(context.Background(), policy)
+	assert.EqualError(t, err, "failed to set inactivity policy: mongo")
+}
@@ -19,6 +19,8 @@ package mocks
 import (
 	context "context"
 
+	keys "github.com/mendersoftware/mender-server/pkg/keys"
+
 	mock "github.com/stretchr/testify/mock"
 
 	model "github.com/mendersoftware/mender-server/services/deviceauth/model"
@@ -47,6 +49,96 @@ func (_m *App) AcceptDeviceAuth(ctx context.Context, dev_id string, auth_id stri
 	return r0
 }
 
+// AddAutoAcceptRule provides a mock function with given fields: ctx, req
+func (_m *App) AddAutoAcceptRule(ctx context.Context, req model.AutoAcceptRuleReq) (*model.AutoAcceptRule, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAutoAcceptRule")
+	}
+
+	var r0 *model.AutoAcceptRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.AutoAcceptRuleReq) (*model.AutoAcceptRule, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.AutoAcceptRuleReq) *model.AutoAcceptRule); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AutoAcceptRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.AutoAcceptRuleReq) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddCACertificate provides a mock function with given fields: ctx, req
+func (_m *App) AddCACertificate(ctx context.Context, req model.CACertificateReq) (*model.CACertificate, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddCACertificate")
+	}
+
+	var r0 *model.CACertificate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.CACertificateReq) (*model.CACertificate, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.CACertificateReq) *model.CACertificate); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CACertificate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.CACertificateReq) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApplyInactivityPolicy provides a mock function with given fields: ctx, dryRun
+func (_m *App) ApplyInactivityPolicy(ctx context.Context, dryRun bool) (*model.InactivityReport, error) {
+	ret := _m.Called(ctx, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyInactivityPolicy")
+	}
+
+	var r0 *model.InactivityReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, bool) (*model.InactivityReport, error)); ok {
+		return rf(ctx, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bool) *model.InactivityReport); ok {
+		r0 = rf(ctx, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.InactivityReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bool) error); ok {
+		r1 = rf(ctx, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DecommissionDevice provides a mock function with given fields: ctx, dev_id
 func (_m *App) DecommissionDevice(ctx context.Context, dev_id string) error {
 	ret := _m.Called(ctx, dev_id)
@@ -83,6 +175,42 @@ func (_m *App) DeleteAuthSet(ctx context.Context, dev_id string, auth_id string)
 	return r0
 }
 
+// DeleteAutoAcceptRule provides a mock function with given fields: ctx, id
+func (_m *App) DeleteAutoAcceptRule(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAutoAcceptRule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteCACertificate provides a mock function with given fields: ctx, id
+func (_m *App) DeleteCACertificate(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCACertificate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteDevice provides a mock function with given fields: ctx, dev_id
 func (_m *App) DeleteDevice(ctx context.Context, dev_id string) error {
 	ret := _m.Called(ctx, dev_id)
@@ -101,6 +229,24 @@ func (_m *App) DeleteDevice(ctx context.Context, dev_id string) error {
 	return r0
 }
 
+// DeleteInactivityPolicy provides a mock function with given fields: ctx
+func (_m *App) DeleteInactivityPolicy(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteInactivityPolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteTenantLimit provides a mock function with given fields: ctx, tenant_id, limit
 func (_m *App) DeleteTenantLimit(ctx context.Context, tenant_id string, limit string) error {
 	ret := _m.Called(ctx, tenant_id, limit)
@@ -137,6 +283,156 @@ func (_m *App) DeleteTokens(ctx context.Context, tenantID string, deviceID strin
 	return r0
 }
 
+// GetAutoAcceptAudit provides a mock function with given fields: ctx, filter, skip, limit
+func (_m *App) GetAutoAcceptAudit(ctx context.Context, filter model.AutoAcceptAuditFilter, skip uint, limit uint) ([]model.AutoAcceptAudit, error) {
+	ret := _m.Called(ctx, filter, skip, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAutoAcceptAudit")
+	}
+
+	var r0 []model.AutoAcceptAudit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.AutoAcceptAuditFilter, uint, uint) ([]model.AutoAcceptAudit, error)); ok {
+		return rf(ctx, filter, skip, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.AutoAcceptAuditFilter, uint, uint) []model.AutoAcceptAudit); ok {
+		r0 = rf(ctx, filter, skip, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AutoAcceptAudit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.AutoAcceptAuditFilter, uint, uint) error); ok {
+		r1 = rf(ctx, filter, skip, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAutoAcceptRule provides a mock function with given fields: ctx, id
+func (_m *App) GetAutoAcceptRule(ctx context.Context, id string) (*model.AutoAcceptRule, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAutoAcceptRule")
+	}
+
+	var r0 *model.AutoAcceptRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.AutoAcceptRule, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.AutoAcceptRule); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AutoAcceptRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAutoAcceptRules provides a mock function with given fields: ctx
+func (_m *App) GetAutoAcceptRules(ctx context.Context) ([]model.AutoAcceptRule, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAutoAcceptRules")
+	}
+
+	var r0 []model.AutoAcceptRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.AutoAcceptRule, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.AutoAcceptRule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AutoAcceptRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCACertificate provides a mock function with given fields: ctx, id
+func (_m *App) GetCACertificate(ctx context.Context, id string) (*model.CACertificate, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCACertificate")
+	}
+
+	var r0 *model.CACertificate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.CACertificate, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.CACertificate); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CACertificate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCACertificates provides a mock function with given fields: ctx
+func (_m *App) GetCACertificates(ctx context.Context) ([]model.CACertificate, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCACertificates")
+	}
+
+	var r0 []model.CACertificate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.CACertificate, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.CACertificate); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.CACertificate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDevCountByStatus provides a mock function with given fields: ctx, status
 func (_m *App) GetDevCountByStatus(ctx context.Context, status string) (int, error) {
 	ret := _m.Called(ctx, status)
@@ -225,6 +521,66 @@ func (_m *App) GetDevices(ctx context.Context, skip uint, limit uint, filter mod
 	return r0, r1
 }
 
+// GetInactivityPolicy provides a mock function with given fields: ctx
+func (_m *App) GetInactivityPolicy(ctx context.Context) (*model.InactivityPolicy, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInactivityPolicy")
+	}
+
+	var r0 *model.InactivityPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.InactivityPolicy, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.InactivityPolicy); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.InactivityPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetJWKS provides a mock function with given fields: ctx
+func (_m *App) GetJWKS(ctx context.Context) (*keys.JWKS, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJWKS")
+	}
+
+	var r0 *keys.JWKS
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*keys.JWKS, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *keys.JWKS); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*keys.JWKS)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLimit provides a mock function with given fields: ctx, name
 func (_m *App) GetLimit(ctx context.Context, name string) (*model.Limit, error) {
 	ret := _m.Called(ctx, name)
@@ -363,6 +719,36 @@ func (_m *App) PreauthorizeDevice(ctx context.Context, req *model.PreAuthReq) (*
 	return r0, r1
 }
 
+// PreauthorizeDevices provides a mock function with given fields: ctx, records, batchSize, dryRun
+func (_m *App) PreauthorizeDevices(ctx context.Context, records []model.PreAuthRecord, batchSize int, dryRun bool) (*model.PreAuthBulkResult, error) {
+	ret := _m.Called(ctx, records, batchSize, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PreauthorizeDevices")
+	}
+
+	var r0 *model.PreAuthBulkResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.PreAuthRecord, int, bool) (*model.PreAuthBulkResult, error)); ok {
+		return rf(ctx, records, batchSize, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []model.PreAuthRecord, int, bool) *model.PreAuthBulkResult); ok {
+		r0 = rf(ctx, records, batchSize, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PreAuthBulkResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []model.PreAuthRecord, int, bool) error); ok {
+		r1 = rf(ctx, records, batchSize, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RejectDeviceAuth provides a mock function with given fields: ctx, dev_id, auth_id
 func (_m *App) RejectDeviceAuth(ctx context.Context, dev_id string, auth_id string) error {
 	ret := _m.Called(ctx, dev_id, auth_id)
@@ -417,6 +803,66 @@ func (_m *App) RevokeToken(ctx context.Context, tokenID string) error {
 	return r0
 }
 
+// SetCACertificateCRL provides a mock function with given fields: ctx, id, crl
+func (_m *App) SetCACertificateCRL(ctx context.Context, id string, crl []byte) (*model.CACertificate, error) {
+	ret := _m.Called(ctx, id, crl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCACertificateCRL")
+	}
+
+	var r0 *model.CACertificate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) (*model.CACertificate, error)); ok {
+		return rf(ctx, id, crl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) *model.CACertificate); ok {
+		r0 = rf(ctx, id, crl)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CACertificate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte) error); ok {
+		r1 = rf(ctx, id, crl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetInactivityPolicy provides a mock function with given fields: ctx, policy
+func (_m *App) SetInactivityPolicy(ctx context.Context, policy model.InactivityPolicy) (*model.InactivityPolicy, error) {
+	ret := _m.Called(ctx, policy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetInactivityPolicy")
+	}
+
+	var r0 *model.InactivityPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.InactivityPolicy) (*model.InactivityPolicy, error)); ok {
+		return rf(ctx, policy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.InactivityPolicy) *model.InactivityPolicy); ok {
+		r0 = rf(ctx, policy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.InactivityPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.InactivityPolicy) error); ok {
+		r1 = rf(ctx, policy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetTenantLimit provides a mock function with given fields: ctx, tenant_id, limit
 func (_m *App) SetTenantLimit(ctx context.Context, tenant_id string, limit model.Limit) error {
 	ret := _m.Called(ctx, tenant_id, limit)
@@ -0,0 +1,294 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package devauth
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/log"
+	"github.com/mendersoftware/mender-server/pkg/requestid"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/client/orchestrator"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	uto "github.com/mendersoftware/mender-server/services/deviceauth/utils/to"
+)
+
+const PreAuthBatchSizeDefault = 100
+
+// preauthDevice is a valid record of a batch being preauthorized.
+type preauthDevice struct {
+	req          *model.PreAuthReq
+	idDataStruct map[string]interface{}
+	idDataSha256 []byte
+	// dev is the device created for the record
+	dev *model.Device
+	// res points at the result of the record
+	res *model.PreAuthRecordResult
+}
+
+func (p *preauthDevice) fail(status string, err error) {
+	p.res.Status = status
+	p.res.Error = err.Error()
+}
+
+func (p *preauthDevice) authSet(deviceId string) model.AuthSet {
+	return model.AuthSet{
+		Id:           p.req.AuthSetId,
+		IdData:       p.req.IdData,
+		IdDataStruct: p.idDataStruct,
+		IdDataSha256: p.idDataSha256,
+		PubKey:       p.req.PubKey,
+		DeviceId:     deviceId,
+		Status:       model.DevStatusPreauth,
+		Timestamp:    uto.TimePtr(time.Now()),
+	}
+}
+
+// PreauthorizeDevices preauthorizes the devices of the records in batches
+// of batchSize records, returning the result of each record. The devices
+// and the auth sets of a batch are looked up and inserted with one request
+// each. The devices already preauthorized or accepted with the same key
+// are left unchanged, so that the same file can be imported again; with
+// dryRun nothing is modified, the results show what the import would do.
+// If the context is canceled, the results of the records processed so far
+// are returned along with the error.
+func (d *DevAuth) PreauthorizeDevices(
+	ctx context.Context,
+	records []model.PreAuthRecord,
+	batchSize int,
+	dryRun bool,
+) (*model.PreAuthBulkResult, error) {
+	l := log.FromContext(ctx)
+	if batchSize <= 0 {
+		batchSize = PreAuthBatchSizeDefault
+	}
+
+	result := &model.PreAuthBulkResult{
+		DryRun:  dryRun,
+		Results: make([]model.PreAuthRecordResult, 0, len(records)),
+	}
+	// lines of the records by the hash of the identity data, to detect
+	// the devices present more than once in the file
+	seen := make(map[string]int, len(records))
+	for start := 0; start < len(records); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		end := start + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		for _, res := range d.preauthorizeBatch(ctx, records[start:end], seen, dryRun) {
+			result.Add(res)
+		}
+		l.Infof("bulk preauthorization: processed %d/%d records "+
+			"(created: %d, updated: %d, unchanged: %d, failed: %d)",
+			end, len(records),
+			result.Created, result.Updated, result.Unchanged, result.Failed)
+	}
+	return result, nil
+}
+
+func (d *DevAuth) preauthorizeBatch(
+	ctx context.Context,
+	records []model.PreAuthRecord,
+	seen map[string]int,
+	dryRun bool,
+) []model.PreAuthRecordResult {
+	results := make([]model.PreAuthRecordResult, len(records))
+	batch := make([]*preauthDevice, 0, len(records))
+	hashes := make([][]byte, 0, len(records))
+	for i, record := range records {
+		results[i].Line = record.Line
+		p := &preauthDevice{res: &results[i]}
+		var err error
+		p.req, err = record.PreAuthReq()
+		if err != nil {
+			p.fail(model.PreAuthResultInvalid, err)
+			continue
+		}
+		p.idDataStruct, p.idDataSha256, err = parseIdData(p.req.IdData)
+		if err != nil {
+			p.fail(model.PreAuthResultInvalid, err)
+			continue
+		}
+		hash := hex.EncodeToString(p.idDataSha256)
+		if line, ok := seen[hash]; ok {
+			p.fail(model.PreAuthResultInvalid,
+				fmt.Errorf("duplicate identity data of line %d", line))
+			continue
+		}
+		seen[hash] = record.Line
+		batch = append(batch, p)
+		hashes = append(hashes, p.idDataSha256)
+	}
+	if len(batch) == 0 {
+		return results
+	}
+
+	devices, err := d.db.GetDevicesByIdentityDataHashes(ctx, hashes)
+	if err != nil {
+		err = errors.Wrap(err, "failed to look up the devices")
+		for _, p := range batch {
+			p.fail(model.PreAuthResultFailed, err)
+		}
+		return results
+	}
+	devicesByHash := make(map[string]*model.Device, len(devices))
+	existing := make([][]byte, 0, len(devices))
+	for i := range devices {
+		devicesByHash[string(devices[i].IdDataSha256)] = &devices[i]
+		existing = append(existing, devices[i].IdDataSha256)
+	}
+	// the auth sets by the hash of the identity data and the key
+	authSets := map[string]*model.AuthSet{}
+	var errAuthSets error
+	if len(existing) > 0 {
+		sets, err := d.db.GetAuthSetsByIdDataHashes(ctx, existing)
+		if err != nil {
+			errAuthSets = errors.Wrap(err, "failed to look up the auth sets")
+		}
+		for i := range sets {
+			authSets[string(sets[i].IdDataSha256)+sets[i].PubKey] = &sets[i]
+		}
+	}
+
+	var created, updated []*preauthDevice
+	for _, p := range batch {
+		dev, ok := devicesByHash[string(p.idDataSha256)]
+		if !ok {
+			p.res.Status = model.PreAuthResultCreated
+			created = append(created, p)
+			continue
+		}
+		p.res.DeviceId = dev.Id
+		aset := authSets[string(p.idDataSha256)+p.req.PubKey]
+		switch {
+		case errAuthSets != nil:
+			p.fail(model.PreAuthResultFailed, errAuthSets)
+		case aset != nil && (aset.Status == model.DevStatusPreauth ||
+			aset.Status == model.DevStatusAccepted):
+			p.res.Status = model.PreAuthResultUnchanged
+		case !p.req.Force:
+			p.fail(model.PreAuthResultConflict, ErrDeviceExists)
+		default:
+			p.res.Status = model.PreAuthResultUpdated
+			updated = append(updated, p)
+		}
+	}
+	if dryRun {
+		return results
+	}
+
+	d.createPreauthDevices(ctx, created)
+	for _, p := range updated {
+		authSet := p.authSet(p.res.DeviceId)
+		if err := d.db.UpsertAuthSetStatus(ctx, &authSet); err != nil {
+			p.fail(model.PreAuthResultFailed, err)
+		}
+	}
+	return results
+}
+
+// createPreauthDevices inserts the preauthorized devices and their auth
+// sets, and submits the status and identity updates of the devices.
+func (d *DevAuth) createPreauthDevices(ctx context.Context, batch []*preauthDevice) {
+	if len(batch) == 0 {
+		return
+	}
+	failAll := func(batch []*preauthDevice, err error) {
+		for _, p := range batch {
+			p.fail(model.PreAuthResultFailed, err)
+		}
+	}
+	// conflicted drops the devices at the indexes from the batch, they
+	// were added since they were looked up
+	conflicted := func(batch []*preauthDevice, indexes []int) []*preauthDevice {
+		for _, i := range indexes {
+			batch[i].fail(model.PreAuthResultConflict, ErrDeviceExists)
+			batch[i] = nil
+		}
+		added := batch[:0]
+		for _, p := range batch {
+			if p != nil {
+				added = append(added, p)
+			}
+		}
+		return added
+	}
+
+	devs := make([]model.Device, len(batch))
+	for i, p := range batch {
+		p.dev = model.NewDevice(p.req.DeviceId, p.req.IdData, p.req.PubKey)
+		p.dev.Status = model.DevStatusPreauth
+		p.dev.IdDataStruct = p.idDataStruct
+		p.dev.IdDataSha256 = p.idDataSha256
+		devs[i] = *p.dev
+	}
+	conflicts, err := d.db.AddDevices(ctx, devs)
+	if err != nil {
+		failAll(batch, errors.Wrap(err, "failed to add devices"))
+		return
+	}
+	batch = conflicted(batch, conflicts)
+	if len(batch) == 0 {
+		return
+	}
+
+	tenantId := ""
+	if idData := identity.FromContext(ctx); idData != nil {
+		tenantId = idData.Tenant
+	}
+	updates := make([]model.DeviceInventoryUpdate, len(batch))
+	for i, p := range batch {
+		updates[i] = model.DeviceInventoryUpdate{
+			Id:       p.dev.Id,
+			Revision: p.dev.Revision,
+		}
+	}
+	err = d.cOrch.SubmitUpdateDeviceStatusJob(ctx, orchestrator.UpdateDeviceStatusReq{
+		RequestId: requestid.FromContext(ctx),
+		Devices:   updates,
+		TenantId:  tenantId,
+		Status:    model.DevStatusPreauth,
+	})
+	if err != nil {
+		failAll(batch, errors.Wrap(err, "update device status job error"))
+		return
+	}
+
+	authSets := make([]model.AuthSet, len(batch))
+	for i, p := range batch {
+		authSets[i] = p.authSet(p.req.DeviceId)
+	}
+	conflicts, err = d.db.AddAuthSets(ctx, authSets)
+	if err != nil {
+		failAll(batch, errors.Wrap(err, "failed to add auth sets"))
+		return
+	}
+	for _, p := range conflicted(batch, conflicts) {
+		if err := d.setDeviceIdentity(ctx, p.dev, tenantId); err != nil {
+			p.fail(model.PreAuthResultFailed, err)
+			continue
+		}
+		p.res.DeviceId = p.req.DeviceId
+	}
+}
@@ -0,0 +1,379 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package devauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/client/orchestrator"
+	morchestrator "github.com/mendersoftware/mender-server/services/deviceauth/client/orchestrator/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	mstore "github.com/mendersoftware/mender-server/services/deviceauth/store/mocks"
+	"github.com/mendersoftware/mender-server/services/deviceauth/utils"
+	mtesting "github.com/mendersoftware/mender-server/services/deviceauth/utils/testing"
+)
+
+func TestDevAuthPreauthorizeDevices(t *testing.T) {
+	t.Parallel()
+
+	const devID = "0e4b3ac1-f2bc-4b2e-a6ab-cae26b5e8d23"
+
+	cert, _ := mtesting.NewCertificate(t, "device", 1, nil, nil)
+	pubKey, err := utils.SerializePubKey(cert.PublicKey)
+	assert.NoError(t, err)
+
+	record := func(line int, sn string, force bool) model.PreAuthRecord {
+		return model.PreAuthRecord{
+			Line:   line,
+			Force:  force,
+			IdData: map[string]interface{}{"sn": sn},
+			PubKey: pubKey,
+		}
+	}
+	hash := func(sn string) []byte {
+		_, sum, _ := parseIdData(`{"sn":"` + sn + `"}`)
+		return sum
+	}
+
+	hashes := func(sns ...string) [][]byte {
+		sums := make([][]byte, len(sns))
+		for i, sn := range sns {
+			sums[i] = hash(sn)
+		}
+		return sums
+	}
+	devices := func(n int) interface{} {
+		return mock.MatchedBy(func(devs []model.Device) bool {
+			for _, dev := range devs {
+				if dev.Status != model.DevStatusPreauth {
+					return false
+				}
+			}
+			return len(devs) == n
+		})
+	}
+	authSets := func(n int) interface{} {
+		return mock.MatchedBy(func(sets []model.AuthSet) bool {
+			for _, set := range sets {
+				if set.Status != model.DevStatusPreauth {
+					return false
+				}
+			}
+			return len(sets) == n
+		})
+	}
+	statusJob := func(n int) interface{} {
+		return mock.MatchedBy(func(req orchestrator.UpdateDeviceStatusReq) bool {
+			return req.Status == model.DevStatusPreauth && len(req.Devices) == n
+		})
+	}
+
+	testCases := []struct {
+		desc string
+
+		records []model.PreAuthRecord
+		dryRun  bool
+
+		setup func(db *mstore.DataStore, co *morchestrator.ClientRunner)
+
+		results []model.PreAuthRecordResult
+	}{{
+		desc:    "ok, created",
+		records: []model.PreAuthRecord{record(2, "sn-1", false), record(3, "sn-2", false)},
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1", "sn-2")).
+				Return([]model.Device{}, nil).Once()
+			db.On("AddDevices", mtesting.ContextMatcher(), devices(2)).
+				Return(nil, nil).Once()
+			db.On("AddAuthSets", mtesting.ContextMatcher(), authSets(2)).
+				Return(nil, nil).Once()
+			co.On("SubmitUpdateDeviceStatusJob", mtesting.ContextMatcher(), statusJob(2)).
+				Return(nil).Once()
+			co.On("SubmitUpdateDeviceInventoryJob", mtesting.ContextMatcher(),
+				mock.AnythingOfType("orchestrator.UpdateDeviceInventoryReq")).
+				Return(nil).Twice()
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:   2,
+			Status: model.PreAuthResultCreated,
+		}, {
+			Line:   3,
+			Status: model.PreAuthResultCreated,
+		}},
+	}, {
+		desc: "ok, batches",
+		records: []model.PreAuthRecord{
+			record(2, "sn-1", false),
+			record(3, "sn-2", false),
+			record(4, "sn-3", false),
+		},
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1", "sn-2")).
+				Return([]model.Device{}, nil).Once()
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-3")).
+				Return([]model.Device{}, nil).Once()
+			db.On("AddDevices", mtesting.ContextMatcher(), devices(2)).
+				Return(nil, nil).Once()
+			db.On("AddDevices", mtesting.ContextMatcher(), devices(1)).
+				Return(nil, nil).Once()
+			db.On("AddAuthSets", mtesting.ContextMatcher(), authSets(2)).
+				Return(nil, nil).Once()
+			db.On("AddAuthSets", mtesting.ContextMatcher(), authSets(1)).
+				Return(nil, nil).Once()
+			co.On("SubmitUpdateDeviceStatusJob", mtesting.ContextMatcher(), statusJob(2)).
+				Return(nil).Once()
+			co.On("SubmitUpdateDeviceStatusJob", mtesting.ContextMatcher(), statusJob(1)).
+				Return(nil).Once()
+			co.On("SubmitUpdateDeviceInventoryJob", mtesting.ContextMatcher(),
+				mock.AnythingOfType("orchestrator.UpdateDeviceInventoryReq")).
+				Return(nil).Times(3)
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:   2,
+			Status: model.PreAuthResultCreated,
+		}, {
+			Line:   3,
+			Status: model.PreAuthResultCreated,
+		}, {
+			Line:   4,
+			Status: model.PreAuthResultCreated,
+		}},
+	}, {
+		desc:    "ok, dry run",
+		records: []model.PreAuthRecord{record(2, "sn-1", false)},
+		dryRun:  true,
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1")).
+				Return([]model.Device{}, nil)
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:   2,
+			Status: model.PreAuthResultCreated,
+		}},
+	}, {
+		desc:    "ok, imported again",
+		records: []model.PreAuthRecord{record(2, "sn-1", false)},
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1")).
+				Return([]model.Device{{Id: devID, IdDataSha256: hash("sn-1")}}, nil)
+			db.On("GetAuthSetsByIdDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1")).
+				Return([]model.AuthSet{{
+					IdDataSha256: hash("sn-1"),
+					PubKey:       pubKey,
+					Status:       model.DevStatusPreauth,
+				}}, nil)
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:     2,
+			Status:   model.PreAuthResultUnchanged,
+			DeviceId: devID,
+		}},
+	}, {
+		desc:    "ok, new key forced",
+		records: []model.PreAuthRecord{record(2, "sn-1", true)},
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1")).
+				Return([]model.Device{{Id: devID, IdDataSha256: hash("sn-1")}}, nil)
+			db.On("GetAuthSetsByIdDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1")).
+				Return([]model.AuthSet{{
+					IdDataSha256: hash("sn-1"),
+					PubKey:       "another key",
+					Status:       model.DevStatusAccepted,
+				}}, nil)
+			db.On("UpsertAuthSetStatus", mtesting.ContextMatcher(),
+				mock.MatchedBy(func(aset *model.AuthSet) bool {
+					return aset.DeviceId == devID &&
+						aset.PubKey == pubKey &&
+						aset.Status == model.DevStatusPreauth
+				})).
+				Return(nil)
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:     2,
+			Status:   model.PreAuthResultUpdated,
+			DeviceId: devID,
+		}},
+	}, {
+		desc:    "error, different key",
+		records: []model.PreAuthRecord{record(2, "sn-1", false)},
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1")).
+				Return([]model.Device{{Id: devID, IdDataSha256: hash("sn-1")}}, nil)
+			db.On("GetAuthSetsByIdDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1")).
+				Return([]model.AuthSet{}, nil)
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:     2,
+			Status:   model.PreAuthResultConflict,
+			DeviceId: devID,
+			Error:    ErrDeviceExists.Error(),
+		}},
+	}, {
+		desc:    "error, added concurrently",
+		records: []model.PreAuthRecord{record(2, "sn-1", false), record(3, "sn-2", false)},
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1", "sn-2")).
+				Return([]model.Device{}, nil)
+			db.On("AddDevices", mtesting.ContextMatcher(), devices(2)).
+				Return([]int{0}, nil)
+			co.On("SubmitUpdateDeviceStatusJob", mtesting.ContextMatcher(), statusJob(1)).
+				Return(nil)
+			db.On("AddAuthSets", mtesting.ContextMatcher(), authSets(1)).
+				Return(nil, nil)
+			co.On("SubmitUpdateDeviceInventoryJob", mtesting.ContextMatcher(),
+				mock.AnythingOfType("orchestrator.UpdateDeviceInventoryReq")).
+				Return(nil).Once()
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:   2,
+			Status: model.PreAuthResultConflict,
+			Error:  ErrDeviceExists.Error(),
+		}, {
+			Line:   3,
+			Status: model.PreAuthResultCreated,
+		}},
+	}, {
+		desc: "error, invalid and duplicate records",
+		records: []model.PreAuthRecord{
+			record(2, "sn-1", false),
+			{Line: 3, IdData: map[string]interface{}{"sn": "sn-2"}, PubKey: "garbage"},
+			{Line: 4, Err: errors.New("expected 2 fields, got 3")},
+			record(5, "sn-1", false),
+		},
+		dryRun: true,
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1")).
+				Return([]model.Device{}, nil)
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:   2,
+			Status: model.PreAuthResultCreated,
+		}, {
+			Line:   3,
+			Status: model.PreAuthResultInvalid,
+			Error:  "cannot decode public key",
+		}, {
+			Line:   4,
+			Status: model.PreAuthResultInvalid,
+			Error:  "expected 2 fields, got 3",
+		}, {
+			Line:   5,
+			Status: model.PreAuthResultInvalid,
+			Error:  "duplicate identity data of line 2",
+		}},
+	}, {
+		desc:    "error, store",
+		records: []model.PreAuthRecord{record(2, "sn-1", false), record(3, "sn-2", false)},
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1", "sn-2")).
+				Return(nil, errors.New("mongo"))
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:   2,
+			Status: model.PreAuthResultFailed,
+			Error:  "failed to look up the devices: mongo",
+		}, {
+			Line:   3,
+			Status: model.PreAuthResultFailed,
+			Error:  "failed to look up the devices: mongo",
+		}},
+	}, {
+		desc:    "error, add devices",
+		records: []model.PreAuthRecord{record(2, "sn-1", false)},
+		setup: func(db *mstore.DataStore, co *morchestrator.ClientRunner) {
+			db.On("GetDevicesByIdentityDataHashes",
+				mtesting.ContextMatcher(), hashes("sn-1")).
+				Return([]model.Device{}, nil)
+			db.On("AddDevices", mtesting.ContextMatcher(), devices(1)).
+				Return(nil, errors.New("mongo"))
+		},
+
+		results: []model.PreAuthRecordResult{{
+			Line:   2,
+			Status: model.PreAuthResultFailed,
+			Error:  "failed to add devices: mongo",
+		}},
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			db := &mstore.DataStore{}
+			defer db.AssertExpectations(t)
+			co := &morchestrator.ClientRunner{}
+			defer co.AssertExpectations(t)
+			tc.setup(db, co)
+
+			devauth := NewDevAuth(db, co, nil, Config{})
+			res, err := devauth.PreauthorizeDevices(
+				context.Background(), tc.records, 2, tc.dryRun)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.dryRun, res.DryRun)
+			if assert.Len(t, res.Results, len(tc.results)) {
+				for j, expected := range tc.results {
+					actual := res.Results[j]
+					if expected.Status == model.PreAuthResultCreated &&
+						!tc.dryRun {
+						assert.NotEmpty(t, actual.DeviceId)
+						actual.DeviceId = ""
+					}
+					assert.Equal(t, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestDevAuthPreauthorizeDevicesCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	devauth := NewDevAuth(&mstore.DataStore{}, &morchestrator.ClientRunner{}, nil, Config{})
+	res, err := devauth.PreauthorizeDevices(ctx,
+		[]model.PreAuthRecord{{Line: 2}}, 0, false)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, res.Results)
+}
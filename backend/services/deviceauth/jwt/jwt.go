@@ -19,8 +19,14 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 
+	jwtv4 "github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/keys"
 )
 
 var (
@@ -31,6 +37,10 @@ var (
 const (
 	pemHeaderPKCS1 = "RSA PRIVATE KEY"
 	pemHeaderPKCS8 = "PRIVATE KEY"
+
+	// KeyIdZero is the id of the key verifying the tokens without the
+	// "kid" header, issued before the keyring was introduced.
+	KeyIdZero = 0
 )
 
 // Handler jwt generator/verifier
@@ -47,9 +57,13 @@ type Handler interface {
 	// ErrTokenExpired when the token is valid but expired
 	// ErrTokenInvalid when the token is invalid (malformed, missing required claims, etc.)
 	Validate(string) error
+	// JWKS returns the public keys verifying the tokens.
+	JWKS() (*keys.JWKS, error)
 }
 
-func NewJWTHandler(privateKeyPath string) (Handler, error) {
+// NewJWTHandler loads the private key; the id of the key is parsed from the
+// file name by the first submatch of the pattern.
+func NewJWTHandler(privateKeyPath string, privateKeyFilenamePattern string) (Handler, error) {
 	priv, err := os.ReadFile(privateKeyPath)
 	block, _ := pem.Decode(priv)
 	if block == nil {
@@ -61,7 +75,10 @@ func NewJWTHandler(privateKeyPath string) (Handler, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to read rsa private key")
 		}
-		return NewJWTHandlerRS256(privKey), nil
+		return NewJWTHandlerRS256(
+			privKey,
+			KeyIdFromPath(privateKeyPath, privateKeyFilenamePattern),
+		), nil
 	case pemHeaderPKCS8:
 		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err != nil {
@@ -69,10 +86,52 @@ func NewJWTHandler(privateKeyPath string) (Handler, error) {
 		}
 		switch v := key.(type) {
 		case *rsa.PrivateKey:
-			return NewJWTHandlerRS256(v), nil
+			return NewJWTHandlerRS256(
+				v,
+				KeyIdFromPath(privateKeyPath, privateKeyFilenamePattern),
+			), nil
 		case ed25519.PrivateKey:
-			return NewJWTHandlerEd25519(&v), nil
+			return NewJWTHandlerEd25519(
+				&v,
+				KeyIdFromPath(privateKeyPath, privateKeyFilenamePattern),
+			), nil
 		}
 	}
 	return nil, errors.Errorf("unsupported server private key type")
 }
+
+// KeyIdFromPath returns the id of the key parsed from the file name by the
+// first submatch of the pattern; KeyIdZero if the name does not match.
+func KeyIdFromPath(privateKeyPath string, privateKeyFilenamePattern string) int {
+	r, err := regexp.Compile(privateKeyFilenamePattern)
+	if err != nil {
+		return KeyIdZero
+	}
+	m := r.FindStringSubmatch(filepath.Base(privateKeyPath))
+	if len(m) < 2 {
+		return KeyIdZero
+	}
+	keyId, err := strconv.Atoi(m[1])
+	if err != nil {
+		return KeyIdZero
+	}
+	return keyId
+}
+
+// GetKeyId returns the id of the key the token was signed with, from the
+// "kid" header of the token; KeyIdZero if the header is missing.
+func GetKeyId(tokenString string) int {
+	token, _, err := jwtv4.NewParser().ParseUnverified(tokenString, &Claims{})
+	if err != nil {
+		return KeyIdZero
+	}
+	switch kid := token.Header["kid"].(type) {
+	case float64:
+		return int(kid)
+	case string:
+		if keyId, err := strconv.Atoi(kid); err == nil {
+			return keyId
+		}
+	}
+	return KeyIdZero
+}
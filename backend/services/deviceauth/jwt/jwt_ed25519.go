@@ -15,25 +15,32 @@ package jwt
 
 import (
 	"crypto/ed25519"
+	"strconv"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/keys"
 )
 
 // JWTHandlerEd25519 is an Ed25519-specific JWTHandler
 type JWTHandlerEd25519 struct {
 	privKey *ed25519.PrivateKey
+	keyId   int
 }
 
-func NewJWTHandlerEd25519(privKey *ed25519.PrivateKey) *JWTHandlerEd25519 {
+func NewJWTHandlerEd25519(privKey *ed25519.PrivateKey, keyId int) *JWTHandlerEd25519 {
 	return &JWTHandlerEd25519{
 		privKey: privKey,
+		keyId:   keyId,
 	}
 }
 
 func (j *JWTHandlerEd25519) ToJWT(token *Token) (string, error) {
 	//generate
 	jt := jwt.NewWithClaims(jwt.SigningMethodEdDSA, &token.Claims)
+	// the key id is a string, as published in the JWKS
+	jt.Header["kid"] = strconv.Itoa(j.keyId)
 
 	//sign
 	data, err := jt.SignedString(j.privKey)
@@ -79,3 +86,11 @@ func (j *JWTHandlerEd25519) Validate(tokstr string) error {
 
 	return nil
 }
+
+func (j *JWTHandlerEd25519) JWKS() (*keys.JWKS, error) {
+	jwk, err := keys.NewJWK(strconv.Itoa(j.keyId), j.privKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &keys.JWKS{Keys: []keys.JWK{*jwk}}, nil
+}
@@ -30,7 +30,7 @@ import (
 
 func TestNewJWTHandlerEd25519(t *testing.T) {
 	privKey := loadEd25519PrivKey("./testdata/ed25519.pem", t)
-	jwtHandler := NewJWTHandlerEd25519(privKey)
+	jwtHandler := NewJWTHandlerEd25519(privKey, KeyIdZero)
 
 	assert.NotNil(t, jwtHandler)
 }
@@ -68,7 +68,7 @@ func TestJWTHandlerEd25519GenerateToken(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Logf("test case: %s", name)
-		jwtHandler := NewJWTHandlerEd25519(tc.privKey)
+		jwtHandler := NewJWTHandlerEd25519(tc.privKey, KeyIdZero)
 
 		raw, err := jwtHandler.ToJWT(&Token{
 			Claims: tc.claims,
@@ -178,7 +178,7 @@ func TestJWTHandlerEd25519FromJWT(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Logf("test case: %s", name)
-		jwtHandler := NewJWTHandlerEd25519(tc.privKey)
+		jwtHandler := NewJWTHandlerEd25519(tc.privKey, KeyIdZero)
 
 		token, err := jwtHandler.FromJWT(tc.inToken)
 		if tc.outErr == nil {
@@ -270,7 +270,7 @@ func TestJWTHandlerEd25519Validate(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Logf("test case: %s", name)
-		jwtHandler := NewJWTHandlerEd25519(tc.privKey)
+		jwtHandler := NewJWTHandlerEd25519(tc.privKey, KeyIdZero)
 
 		err := jwtHandler.Validate(tc.inToken)
 		if tc.outErr == nil {
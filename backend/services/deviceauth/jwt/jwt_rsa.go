@@ -15,25 +15,32 @@ package jwt
 
 import (
 	"crypto/rsa"
+	"strconv"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/keys"
 )
 
 // JWTHandlerRS256 is an RS256-specific JWTHandler
 type JWTHandlerRS256 struct {
 	privKey *rsa.PrivateKey
+	keyId   int
 }
 
-func NewJWTHandlerRS256(privKey *rsa.PrivateKey) *JWTHandlerRS256 {
+func NewJWTHandlerRS256(privKey *rsa.PrivateKey, keyId int) *JWTHandlerRS256 {
 	return &JWTHandlerRS256{
 		privKey: privKey,
+		keyId:   keyId,
 	}
 }
 
 func (j *JWTHandlerRS256) ToJWT(token *Token) (string, error) {
 	//generate
 	jt := jwt.NewWithClaims(jwt.SigningMethodRS256, &token.Claims)
+	// the key id is a string, as published in the JWKS
+	jt.Header["kid"] = strconv.Itoa(j.keyId)
 
 	//sign
 	data, err := jt.SignedString(j.privKey)
@@ -79,3 +86,11 @@ func (j *JWTHandlerRS256) Validate(tokstr string) error {
 
 	return nil
 }
+
+func (j *JWTHandlerRS256) JWKS() (*keys.JWKS, error) {
+	jwk, err := keys.NewJWK(strconv.Itoa(j.keyId), &j.privKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &keys.JWKS{Keys: []keys.JWK{*jwk}}, nil
+}
@@ -30,7 +30,7 @@ import (
 
 func TestNewJWTHandlerRS256(t *testing.T) {
 	privKey := loadRSAPrivKey("./testdata/rsa.pem", t)
-	jwtHandler := NewJWTHandlerRS256(privKey)
+	jwtHandler := NewJWTHandlerRS256(privKey, KeyIdZero)
 
 	assert.NotNil(t, jwtHandler)
 }
@@ -68,7 +68,7 @@ func TestJWTHandlerRS256GenerateToken(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Logf("test case: %s", name)
-		jwtHandler := NewJWTHandlerRS256(tc.privKey)
+		jwtHandler := NewJWTHandlerRS256(tc.privKey, KeyIdZero)
 
 		raw, err := jwtHandler.ToJWT(&Token{
 			Claims: tc.claims,
@@ -185,7 +185,7 @@ func TestJWTHandlerRS256FromJWT(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Logf("test case: %s", name)
-		jwtHandler := NewJWTHandlerRS256(tc.privKey)
+		jwtHandler := NewJWTHandlerRS256(tc.privKey, KeyIdZero)
 
 		token, err := jwtHandler.FromJWT(tc.inToken)
 		if tc.outErr == nil {
@@ -295,7 +295,7 @@ func TestJWTHandlerRS256Validate(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Logf("test case: %s", name)
-		jwtHandler := NewJWTHandlerRS256(tc.privKey)
+		jwtHandler := NewJWTHandlerRS256(tc.privKey, KeyIdZero)
 
 		err := jwtHandler.Validate(tc.inToken)
 		if tc.outErr == nil {
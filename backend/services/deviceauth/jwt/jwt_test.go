@@ -46,7 +46,7 @@ func TestNewJWTHandler(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
-			_, err := NewJWTHandler(tc.privateKeyPath)
+			_, err := NewJWTHandler(tc.privateKeyPath, testKeyFilenamePattern)
 			if tc.err != nil {
 				assert.EqualError(t, err, tc.err.Error())
 			} else {
@@ -55,3 +55,16 @@ func TestNewJWTHandler(t *testing.T) {
 		})
 	}
 }
+
+const testKeyFilenamePattern = "private\\.id\\.([0-9]*)\\.pem"
+
+func TestKeyIdFromPath(t *testing.T) {
+	assert.Equal(t, 12,
+		KeyIdFromPath("/etc/deviceauth/rsa/private.id.12.pem", testKeyFilenamePattern))
+	assert.Equal(t, KeyIdZero,
+		KeyIdFromPath("/etc/deviceauth/rsa/private.pem", testKeyFilenamePattern))
+	assert.Equal(t, KeyIdZero,
+		KeyIdFromPath("/etc/deviceauth/rsa/private.id..pem", testKeyFilenamePattern))
+	assert.Equal(t, KeyIdZero,
+		KeyIdFromPath("/etc/deviceauth/rsa/private.id.1.pem", "("))
+}
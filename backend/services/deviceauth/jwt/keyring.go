@@ -0,0 +1,84 @@
+// Copyright 2023 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package jwt
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/keys"
+)
+
+var ErrKeyIdNotFound = errors.New("cant locate key by key id")
+
+// Keyring is the set of the server keys identified by the key id. The
+// tokens are signed with the active key and verified with the key matching
+// the "kid" header of the token; the keys of the keyring are published to
+// the verifiers, so that a new key can be distributed before it becomes
+// the active one.
+type Keyring struct {
+	activeKeyId int
+	handlers    map[int]Handler
+}
+
+func NewKeyring(activeKeyId int, handlers map[int]Handler) (*Keyring, error) {
+	if _, ok := handlers[activeKeyId]; !ok {
+		return nil, errors.Wrapf(ErrKeyIdNotFound, "active key id %d", activeKeyId)
+	}
+	return &Keyring{
+		activeKeyId: activeKeyId,
+		handlers:    handlers,
+	}, nil
+}
+
+// ActiveKeyId returns the id of the key signing the tokens.
+func (k *Keyring) ActiveKeyId() int {
+	return k.activeKeyId
+}
+
+func (k *Keyring) ToJWT(token *Token) (string, error) {
+	return k.handlers[k.activeKeyId].ToJWT(token)
+}
+
+func (k *Keyring) FromJWT(tokstr string) (*Token, error) {
+	return k.handlers[k.activeKeyId].FromJWT(tokstr)
+}
+
+func (k *Keyring) Validate(tokstr string) error {
+	handler, ok := k.handlers[GetKeyId(tokstr)]
+	if !ok {
+		return ErrTokenInvalid
+	}
+	return handler.Validate(tokstr)
+}
+
+// JWKS returns the public keys of the keyring ordered by the key id.
+func (k *Keyring) JWKS() (*keys.JWKS, error) {
+	keyIds := make([]int, 0, len(k.handlers))
+	for keyId := range k.handlers {
+		keyIds = append(keyIds, keyId)
+	}
+	sort.Ints(keyIds)
+
+	jwks := &keys.JWKS{Keys: []keys.JWK{}}
+	for _, keyId := range keyIds {
+		set, err := k.handlers[keyId].JWKS()
+		if err != nil {
+			return nil, errors.Wrapf(err, "key id %d", keyId)
+		}
+		jwks.Keys = append(jwks.Keys, set.Keys...)
+	}
+	return jwks, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package jwt
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	jwtgo "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/pkg/keys"
+	"github.com/mendersoftware/mender-server/pkg/mongo/oid"
+)
+
+func TestKeyring(t *testing.T) {
+	rsaKey := loadRSAPrivKey("./testdata/rsa.pem", t)
+	edKey := loadEd25519PrivKey("./testdata/ed25519.pem", t)
+
+	_, err := NewKeyring(2, map[int]Handler{
+		KeyIdZero: NewJWTHandlerRS256(rsaKey, KeyIdZero),
+	})
+	assert.ErrorIs(t, err, ErrKeyIdNotFound)
+
+	keyring, err := NewKeyring(2, map[int]Handler{
+		KeyIdZero: NewJWTHandlerRS256(rsaKey, KeyIdZero),
+		2:         NewJWTHandlerEd25519(edKey, 2),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keyring.ActiveKeyId())
+
+	claims := Claims{
+		ID:        oid.NewUUIDv4(),
+		Subject:   oid.NewUUIDv4(),
+		Issuer:    "Mender",
+		ExpiresAt: Time{Time: time.Now().Add(time.Hour)},
+		Device:    true,
+	}
+
+	// new tokens are signed with the active key
+	raw, err := keyring.ToJWT(&Token{Claims: claims})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, GetKeyId(raw))
+	parsed, _, err := jwtgo.NewParser().ParseUnverified(raw, jwtgo.MapClaims{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2", parsed.Header["kid"])
+	}
+	assert.NoError(t, keyring.Validate(raw))
+	token, err := keyring.FromJWT(raw)
+	if assert.NoError(t, err) {
+		assert.Equal(t, claims.ID, token.Claims.ID)
+	}
+
+	// tokens issued without the key id are verified with the key zero
+	legacy, err := jwtgo.NewWithClaims(jwtgo.SigningMethodRS256, &claims).
+		SignedString(rsaKey)
+	assert.NoError(t, err)
+	assert.Equal(t, KeyIdZero, GetKeyId(legacy))
+	assert.NoError(t, keyring.Validate(legacy))
+
+	// tokens signed with an unknown key
+	unknown := jwtgo.NewWithClaims(jwtgo.SigningMethodRS256, &claims)
+	unknown.Header["kid"] = 5
+	raw, err = unknown.SignedString(rsaKey)
+	assert.NoError(t, err)
+	assert.Equal(t, ErrTokenInvalid, keyring.Validate(raw))
+
+	// tokens claiming another key than the one they are signed with
+	forged := jwtgo.NewWithClaims(jwtgo.SigningMethodRS256, &claims)
+	forged.Header["kid"] = 2
+	raw, err = forged.SignedString(rsaKey)
+	assert.NoError(t, err)
+	assert.Error(t, keyring.Validate(raw))
+
+	jwks, err := keyring.JWKS()
+	if assert.NoError(t, err) && assert.Len(t, jwks.Keys, 2) {
+		assert.Equal(t, strconv.Itoa(KeyIdZero), jwks.Keys[0].KeyID)
+		assert.Equal(t, keys.JWKAlgRS256, jwks.Keys[0].Algorithm)
+		assert.Equal(t, "2", jwks.Keys[1].KeyID)
+		assert.Equal(t, keys.JWKAlgEdDSA, jwks.Keys[1].Algorithm)
+	}
+}
+
+func TestGetKeyId(t *testing.T) {
+	for kid, expected := range map[interface{}]int{
+		3:     3,
+		"4":   4,
+		"foo": KeyIdZero,
+		nil:   KeyIdZero,
+	} {
+		token := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, jwtgo.MapClaims{})
+		if kid != nil {
+			token.Header["kid"] = kid
+		}
+		raw, err := token.SignedString([]byte("secret"))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, GetKeyId(raw))
+	}
+	assert.Equal(t, KeyIdZero, GetKeyId("garbage"))
+}
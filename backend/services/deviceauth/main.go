@@ -17,6 +17,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -33,6 +34,7 @@ import (
 	"github.com/mendersoftware/mender-server/services/deviceauth/client/tenant"
 	"github.com/mendersoftware/mender-server/services/deviceauth/cmd"
 	dconfig "github.com/mendersoftware/mender-server/services/deviceauth/config"
+	"github.com/mendersoftware/mender-server/services/deviceauth/devauth"
 	"github.com/mendersoftware/mender-server/services/deviceauth/store/mongo"
 )
 
@@ -164,6 +166,12 @@ func doMain(args []string) {
 					Name:  "decommissioning-cleanup",
 					Usage: "Cleanup devauth database from leftovers after failed decommissioning",
 				},
+				cli.BoolFlag{
+					Name: "inactive-devices",
+					Usage: "Apply the inactivity policies of the tenants: notify, " +
+						"reject or decommission the accepted devices which " +
+						"have not checked in for the days of the policy",
+				},
 				cli.StringFlag{
 					Name:  "tenant",
 					Usage: "Tenant ID (optional).",
@@ -176,6 +184,44 @@ func doMain(args []string) {
 			},
 
 			Action: cmdMaintenance,
+		}, {
+			Name:  "preauthorize",
+			Usage: "Preauthorize the devices listed in a CSV or NDJSON file",
+			Description: "Reads the identity data and public keys of the " +
+				"devices from the file and preauthorizes them in batches, " +
+				"printing the result of each record. The CSV file must " +
+				"have a header with the pubkey column, the optional force " +
+				"column, and a column for each identity data attribute; " +
+				"the NDJSON file lists the preauthorization requests " +
+				"(identity_data, pubkey, force). The devices already " +
+				"preauthorized with the same key are left unchanged, so " +
+				"the file can be imported again.",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file, f",
+					Usage: "Path to the `FILE`, - reads the standard input.",
+				},
+				cli.StringFlag{
+					Name: "format",
+					Usage: "Format of the file <csv|ndjson>, " +
+						"by default inferred from the file extension.",
+				},
+				cli.StringFlag{
+					Name:  "tenant",
+					Usage: "Tenant ID (optional).",
+				},
+				cli.IntFlag{
+					Name:  "batch-size",
+					Usage: "Number of devices preauthorized in a batch.",
+					Value: devauth.PreAuthBatchSizeDefault,
+				},
+				cli.BoolFlag{
+					Name: "dry-run",
+					Usage: "Do not perform any modifications, only validate " +
+						"the file and show what would be done",
+				},
+			},
+			Action: cmdPreauthorize,
 		}, {
 			Name:  "check-device-limits",
 			Usage: "Warn users if user is approaching device limit",
@@ -295,6 +341,116 @@ func cmdMaintenance(args *cli.Context) error {
 	if err != nil {
 		return cli.NewExitError(err, 6)
 	}
+	if !args.Bool("inactive-devices") {
+		return nil
+	}
+
+	db, err := mongo.NewDataStoreMongo(makeDataStoreConfig())
+	if err != nil {
+		return cli.NewExitError(
+			fmt.Sprintf("failed to connect to db: %v", err),
+			2)
+	}
+	if config.Config.Get(dconfig.SettingTenantAdmAddr) != "" {
+		db = db.WithMultitenant()
+	}
+	app, err := makeCmdDevAuth(db)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+
+	err = cmd.ApplyInactivityPolicies(app,
+		db,
+		args.String("tenant"),
+		args.Bool("dry-run"),
+		os.Stdout)
+	if err != nil {
+		return cli.NewExitError(err, 6)
+	}
+	return nil
+}
+
+// makeCmdDevAuth creates the app for the commands changing the devices; the
+// cache is set up so that the tokens of the devices are removed from it.
+func makeCmdDevAuth(db *mongo.DataStoreMongo) (*devauth.DevAuth, error) {
+	app := devauth.NewDevAuth(db,
+		orchestrator.NewClient(orchestrator.Config{
+			OrchestratorAddr: config.Config.GetString(
+				dconfig.SettingOrchestratorAddr,
+			),
+			Timeout: 30 * time.Second,
+		}),
+		nil,
+		devauth.Config{
+			InventoryAddr:   config.Config.GetString(dconfig.SettingInventoryAddr),
+			EnableReporting: config.Config.GetBool(dconfig.SettingEnableReporting),
+		})
+
+	cacheConnStr := config.Config.GetString(dconfig.SettingRedisConnectionString)
+	if cacheConnStr == "" {
+		// for backward compatibility check old redis_addr setting
+		cacheConnStr = config.Config.GetString(dconfig.SettingRedisAddr)
+	}
+	if cacheConnStr != "" {
+		cmdCache, _, err := setupRedis(config.Config, cacheConnStr)
+		if err != nil {
+			return nil, err
+		}
+		app = app.WithCache(cmdCache)
+	}
+	return app, nil
+}
+
+func cmdPreauthorize(args *cli.Context) error {
+	path := args.String("file")
+	if path == "" {
+		return cli.NewExitError("the file to import is required", 1)
+	}
+	format := args.String("format")
+	var source io.Reader = os.Stdin
+	if path != "-" {
+		var err error
+		if format == "" {
+			format, err = cmd.PreAuthFormatFromPath(path)
+			if err != nil {
+				return cli.NewExitError(err, 1)
+			}
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		defer f.Close()
+		source = f
+	} else if format == "" {
+		return cli.NewExitError("the format is required reading the standard input", 1)
+	}
+
+	db, err := mongo.NewDataStoreMongo(makeDataStoreConfig())
+	if err != nil {
+		return cli.NewExitError(
+			fmt.Sprintf("failed to connect to db: %v", err),
+			2)
+	}
+	if config.Config.Get(dconfig.SettingTenantAdmAddr) != "" {
+		db = db.WithMultitenant()
+	}
+
+	app, err := makeCmdDevAuth(db)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+
+	err = cmd.PreauthorizeDevices(app,
+		args.String("tenant"),
+		source,
+		format,
+		args.Int("batch-size"),
+		args.Bool("dry-run"),
+		os.Stdout)
+	if err != nil {
+		return cli.NewExitError(err, 8)
+	}
 	return nil
 }
 
@@ -16,6 +16,7 @@ package model
 import (
 	"crypto"
 	"errors"
+	"net"
 
 	"github.com/mendersoftware/mender-server/services/deviceauth/utils"
 )
@@ -26,13 +27,18 @@ type AuthReq struct {
 	IdData      string `json:"id_data" bson:"id_data"`
 	TenantToken string `json:"tenant_token" bson:"tenant_token"`
 	PubKey      string `json:"pubkey"`
+	// Certificate is the PEM encoded certificate chain of the device,
+	// leaf first; the identity data is derived from the certificate.
+	Certificate string `json:"certificate,omitempty" bson:"-"`
 
 	//helpers, not serialized
 	PubKeyStruct crypto.PublicKey `json:"-" bson:"-"`
+	// SourceIP is the address of the device submitting the request
+	SourceIP net.IP `json:"-" bson:"-"`
 }
 
 func (r *AuthReq) Validate() error {
-	if r.IdData == "" {
+	if r.IdData == "" && r.Certificate == "" {
 		return errors.New("id_data must be provided")
 	}
 
@@ -56,6 +62,9 @@ func (r *AuthReq) Validate() error {
 
 	r.PubKey = serialized
 
+	if r.IdData == "" {
+		return nil
+	}
 	if sorted, err := utils.JsonSort(r.IdData); err != nil {
 		return err
 	} else {
@@ -0,0 +1,230 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/mongo/oid"
+)
+
+// Tenant token variants the auto-accept rules match on.
+const (
+	// TenantTokenAny matches any device
+	TenantTokenAny = "any"
+	// TenantTokenSupplied matches the devices authenticated with the
+	// tenant token they supplied
+	TenantTokenSupplied = "supplied"
+	// TenantTokenDefault matches the devices authenticated with the
+	// default tenant token of the server
+	TenantTokenDefault = "default"
+)
+
+var (
+	TenantTokenVariants = []interface{}{
+		TenantTokenAny,
+		TenantTokenSupplied,
+		TenantTokenDefault,
+	}
+
+	ErrAutoAcceptRuleNoCriteria = errors.New(
+		"the rule must match on identity data, source address, " +
+			"tenant token or time window")
+	ErrAutoAcceptRuleTimeWindow = errors.New("not_after must be later than not_before")
+)
+
+// AutoAcceptIdentityMatch matches an identity data attribute of the device;
+// an empty regex matches any value of the attribute.
+type AutoAcceptIdentityMatch struct {
+	Key   string `json:"key" bson:"key"`
+	Regex string `json:"regex,omitempty" bson:"regex,omitempty"`
+}
+
+func (m AutoAcceptIdentityMatch) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Key, validation.Required),
+		validation.Field(&m.Regex, validation.By(func(interface{}) error {
+			_, err := regexp.Compile(m.Regex)
+			return err
+		})),
+	)
+}
+
+// Matches returns true if the identity data has the attribute matching the
+// regex; attributes with multiple values match if any of the values does.
+func (m AutoAcceptIdentityMatch) Matches(idData map[string]interface{}) bool {
+	value, ok := idData[m.Key]
+	if !ok {
+		return false
+	} else if m.Regex == "" {
+		return true
+	}
+	re, err := regexp.Compile(m.Regex)
+	if err != nil {
+		return false
+	}
+	if values, ok := value.([]interface{}); ok {
+		for _, v := range values {
+			if re.MatchString(fmt.Sprint(v)) {
+				return true
+			}
+		}
+		return false
+	}
+	return re.MatchString(fmt.Sprint(value))
+}
+
+// AutoAcceptRuleReq is the request to create an auto-accept rule.
+type AutoAcceptRuleReq struct {
+	Name        string                    `json:"name"`
+	Identity    []AutoAcceptIdentityMatch `json:"identity"`
+	SourceCIDRs []string                  `json:"source_cidrs"`
+	TenantToken string                    `json:"tenant_token"`
+	NotBefore   *time.Time                `json:"not_before"`
+	NotAfter    *time.Time                `json:"not_after"`
+	MaxAccepts  uint64                    `json:"max_accepts"`
+}
+
+func (r AutoAcceptRuleReq) Validate() error {
+	err := validation.ValidateStruct(&r,
+		validation.Field(&r.Identity),
+		validation.Field(&r.SourceCIDRs, validation.Each(
+			validation.By(func(v interface{}) error {
+				_, _, err := net.ParseCIDR(v.(string))
+				return err
+			}),
+		)),
+		validation.Field(&r.TenantToken, validation.In(TenantTokenVariants...)),
+		validation.Field(&r.MaxAccepts, validation.Required),
+	)
+	if err != nil {
+		return err
+	}
+	if r.NotBefore != nil && r.NotAfter != nil && !r.NotAfter.After(*r.NotBefore) {
+		return ErrAutoAcceptRuleTimeWindow
+	}
+	if len(r.Identity) == 0 && len(r.SourceCIDRs) == 0 &&
+		(r.TenantToken == "" || r.TenantToken == TenantTokenAny) &&
+		r.NotBefore == nil && r.NotAfter == nil {
+		return ErrAutoAcceptRuleNoCriteria
+	}
+	return nil
+}
+
+// AutoAcceptRule accepts the pending devices matching all of its criteria,
+// up to MaxAccepts devices.
+type AutoAcceptRule struct {
+	Id          string                    `json:"id" bson:"_id"`
+	Name        string                    `json:"name,omitempty" bson:"name,omitempty"`
+	Identity    []AutoAcceptIdentityMatch `json:"identity" bson:"identity"`
+	SourceCIDRs []string                  `json:"source_cidrs" bson:"source_cidrs"`
+	TenantToken string                    `json:"tenant_token" bson:"tenant_token"`
+	NotBefore   *time.Time                `json:"not_before,omitempty" bson:"not_before,omitempty"`
+	NotAfter    *time.Time                `json:"not_after,omitempty" bson:"not_after,omitempty"`
+	MaxAccepts  uint64                    `json:"max_accepts" bson:"max_accepts"`
+	Accepted    uint64                    `json:"accepted" bson:"accepted"`
+	CreatedTs   time.Time                 `json:"created_ts" bson:"created_ts"`
+	TenantID    string                    `json:"-" bson:"tenant_id"`
+}
+
+func NewAutoAcceptRule(r AutoAcceptRuleReq) *AutoAcceptRule {
+	rule := &AutoAcceptRule{
+		Id:          oid.NewUUIDv4().String(),
+		Name:        r.Name,
+		Identity:    r.Identity,
+		SourceCIDRs: r.SourceCIDRs,
+		TenantToken: r.TenantToken,
+		NotBefore:   r.NotBefore,
+		NotAfter:    r.NotAfter,
+		MaxAccepts:  r.MaxAccepts,
+		CreatedTs:   time.Now().UTC(),
+	}
+	if rule.Identity == nil {
+		rule.Identity = []AutoAcceptIdentityMatch{}
+	}
+	if rule.SourceCIDRs == nil {
+		rule.SourceCIDRs = []string{}
+	}
+	if rule.TenantToken == "" {
+		rule.TenantToken = TenantTokenAny
+	}
+	return rule
+}
+
+// Matches returns true if the device matches all the criteria of the rule
+// and the budget of the rule is not exhausted.
+func (r AutoAcceptRule) Matches(
+	idData map[string]interface{},
+	sourceIP net.IP,
+	tenantToken string,
+	now time.Time,
+) bool {
+	if r.Accepted >= r.MaxAccepts {
+		return false
+	}
+	if r.NotBefore != nil && now.Before(*r.NotBefore) {
+		return false
+	}
+	if r.NotAfter != nil && !now.Before(*r.NotAfter) {
+		return false
+	}
+	if r.TenantToken != "" && r.TenantToken != TenantTokenAny &&
+		r.TenantToken != tenantToken {
+		return false
+	}
+	for _, m := range r.Identity {
+		if !m.Matches(idData) {
+			return false
+		}
+	}
+	if len(r.SourceCIDRs) == 0 {
+		return true
+	} else if sourceIP == nil {
+		return false
+	}
+	for _, cidr := range r.SourceCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err == nil && network.Contains(sourceIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// AutoAcceptAudit records the device accepted by an auto-accept rule.
+type AutoAcceptAudit struct {
+	Id           string                 `json:"id" bson:"_id"`
+	RuleId       string                 `json:"rule_id" bson:"rule_id"`
+	RuleName     string                 `json:"rule_name,omitempty" bson:"rule_name,omitempty"`
+	DeviceId     string                 `json:"device_id" bson:"device_id"`
+	AuthSetId    string                 `json:"auth_set_id" bson:"auth_set_id"`
+	IdentityData map[string]interface{} `json:"identity_data" bson:"identity_data"`
+	SourceIP     string                 `json:"source_ip,omitempty" bson:"source_ip,omitempty"`
+	TenantToken  string                 `json:"tenant_token,omitempty" bson:"tenant_token,omitempty"`
+	Timestamp    time.Time              `json:"ts" bson:"ts"`
+	TenantID     string                 `json:"-" bson:"tenant_id"`
+}
+
+// AutoAcceptAuditFilter filters the auto-accept audit trail.
+type AutoAcceptAuditFilter struct {
+	RuleId   string
+	DeviceId string
+}
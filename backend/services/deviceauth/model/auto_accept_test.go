@@ -0,0 +1,179 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoAcceptRuleReqValidate(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	req := AutoAcceptRuleReq{
+		Identity:    []AutoAcceptIdentityMatch{{Key: "mac", Regex: "^00:11:"}},
+		SourceCIDRs: []string{"10.0.0.0/8"},
+		TenantToken: TenantTokenSupplied,
+		NotBefore:   &now,
+		NotAfter:    &later,
+		MaxAccepts:  10,
+	}
+	assert.NoError(t, req.Validate())
+
+	req.NotBefore, req.NotAfter = &later, &now
+	assert.ErrorIs(t, req.Validate(), ErrAutoAcceptRuleTimeWindow)
+
+	req.NotBefore, req.NotAfter = nil, nil
+	req.SourceCIDRs = []string{"10.0.0.1"}
+	assert.EqualError(t, req.Validate(),
+		"source_cidrs: (0: invalid CIDR address: 10.0.0.1.).")
+
+	req.SourceCIDRs = nil
+	req.TenantToken = "foo"
+	assert.EqualError(t, req.Validate(), "tenant_token: must be a valid value.")
+
+	req.TenantToken = ""
+	req.Identity = []AutoAcceptIdentityMatch{{Key: "mac", Regex: "("}}
+	assert.Error(t, req.Validate())
+
+	req.Identity = nil
+	assert.ErrorIs(t, req.Validate(), ErrAutoAcceptRuleNoCriteria)
+
+	req.Identity = []AutoAcceptIdentityMatch{{Key: "mac"}}
+	req.MaxAccepts = 0
+	assert.EqualError(t, req.Validate(), "max_accepts: cannot be blank.")
+}
+
+func TestNewAutoAcceptRule(t *testing.T) {
+	rule := NewAutoAcceptRule(AutoAcceptRuleReq{
+		Name:       "factory",
+		MaxAccepts: 5,
+	})
+	assert.NotEmpty(t, rule.Id)
+	assert.Equal(t, "factory", rule.Name)
+	assert.Equal(t, TenantTokenAny, rule.TenantToken)
+	assert.Equal(t, []AutoAcceptIdentityMatch{}, rule.Identity)
+	assert.Equal(t, []string{}, rule.SourceCIDRs)
+	assert.Equal(t, uint64(5), rule.MaxAccepts)
+	assert.Zero(t, rule.Accepted)
+}
+
+func TestAutoAcceptRuleMatches(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	idData := map[string]interface{}{
+		"mac": "00:11:22:33:44:55",
+		"sku": []interface{}{"foo", "bar-1"},
+	}
+	sourceIP := net.ParseIP("10.1.2.3")
+
+	testCases := map[string]struct {
+		rule        AutoAcceptRule
+		sourceIP    net.IP
+		tenantToken string
+
+		matches bool
+	}{
+		"ok, all criteria": {
+			rule: AutoAcceptRule{
+				Identity: []AutoAcceptIdentityMatch{
+					{Key: "mac", Regex: "^00:11:"},
+					{Key: "sku", Regex: "^bar-[0-9]$"},
+				},
+				SourceCIDRs: []string{"192.168.0.0/16", "10.0.0.0/8"},
+				TenantToken: TenantTokenSupplied,
+				NotBefore:   &before,
+				NotAfter:    &after,
+				MaxAccepts:  1,
+			},
+			sourceIP:    sourceIP,
+			tenantToken: TenantTokenSupplied,
+			matches:     true,
+		},
+		"ok, attribute present": {
+			rule: AutoAcceptRule{
+				Identity:    []AutoAcceptIdentityMatch{{Key: "mac"}},
+				TenantToken: TenantTokenAny,
+				MaxAccepts:  1,
+			},
+			tenantToken: TenantTokenDefault,
+			matches:     true,
+		},
+		"budget exhausted": {
+			rule: AutoAcceptRule{
+				Identity:   []AutoAcceptIdentityMatch{{Key: "mac"}},
+				MaxAccepts: 2,
+				Accepted:   2,
+			},
+		},
+		"not yet valid": {
+			rule: AutoAcceptRule{
+				NotBefore:  &after,
+				MaxAccepts: 1,
+			},
+		},
+		"expired": {
+			rule: AutoAcceptRule{
+				NotAfter:   &before,
+				MaxAccepts: 1,
+			},
+		},
+		"tenant token mismatch": {
+			rule: AutoAcceptRule{
+				TenantToken: TenantTokenSupplied,
+				MaxAccepts:  1,
+			},
+			tenantToken: TenantTokenDefault,
+		},
+		"identity mismatch": {
+			rule: AutoAcceptRule{
+				Identity:   []AutoAcceptIdentityMatch{{Key: "mac", Regex: "^ff:"}},
+				MaxAccepts: 1,
+			},
+		},
+		"identity attribute missing": {
+			rule: AutoAcceptRule{
+				Identity:   []AutoAcceptIdentityMatch{{Key: "serial"}},
+				MaxAccepts: 1,
+			},
+		},
+		"source address mismatch": {
+			rule: AutoAcceptRule{
+				SourceCIDRs: []string{"192.168.0.0/16"},
+				MaxAccepts:  1,
+			},
+			sourceIP: sourceIP,
+		},
+		"source address unknown": {
+			rule: AutoAcceptRule{
+				SourceCIDRs: []string{"10.0.0.0/8"},
+				MaxAccepts:  1,
+			},
+		},
+	}
+	for name := range testCases {
+		tc := testCases[name]
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.matches,
+				tc.rule.Matches(idData, tc.sourceIP, tc.tenantToken, now))
+		})
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2024 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+
+	"github.com/mendersoftware/mender-server/pkg/mongo/oid"
+)
+
+// Certificate fields the identity data of the devices can be derived from.
+const (
+	CertFieldCommonName         = "subject.common_name"
+	CertFieldSerialNumber       = "subject.serial_number"
+	CertFieldOrganization       = "subject.organization"
+	CertFieldOrganizationalUnit = "subject.organizational_unit"
+	CertFieldCountry            = "subject.country"
+	CertFieldSANDNS             = "san.dns"
+	CertFieldSANEmail           = "san.email"
+	CertFieldSANURI             = "san.uri"
+	CertFieldSANIP              = "san.ip"
+
+	pemTypeCertificate = "CERTIFICATE"
+	pemTypeCRL         = "X509 CRL"
+)
+
+var (
+	CertFields = []interface{}{
+		CertFieldCommonName,
+		CertFieldSerialNumber,
+		CertFieldOrganization,
+		CertFieldOrganizationalUnit,
+		CertFieldCountry,
+		CertFieldSANDNS,
+		CertFieldSANEmail,
+		CertFieldSANURI,
+		CertFieldSANIP,
+	}
+
+	// DefaultCertIdentityFields maps the common name of the subject to
+	// the "common_name" identity attribute.
+	DefaultCertIdentityFields = []CertIdentityField{{
+		Key:   "common_name",
+		Field: CertFieldCommonName,
+	}}
+
+	ErrCertificateNotCA      = errors.New("the certificate is not a CA certificate")
+	ErrCertificateEmpty      = errors.New("no certificate found")
+	ErrCertIdentityKeyExists = errors.New("duplicate identity attribute")
+)
+
+// CertIdentityField maps a field of the device certificate to an identity
+// data attribute.
+type CertIdentityField struct {
+	Key   string `json:"key" bson:"key"`
+	Field string `json:"field" bson:"field"`
+}
+
+func (f CertIdentityField) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.Key, validation.Required),
+		validation.Field(&f.Field, validation.Required, validation.In(CertFields...)),
+	)
+}
+
+// CACertificateReq is the request to register a CA certificate.
+type CACertificateReq struct {
+	Name           string              `json:"name"`
+	Certificate    string              `json:"certificate"`
+	IdentityFields []CertIdentityField `json:"identity_fields"`
+}
+
+func (r CACertificateReq) Validate() error {
+	err := validation.ValidateStruct(&r,
+		validation.Field(&r.Certificate, validation.Required),
+		validation.Field(&r.IdentityFields),
+	)
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]struct{}, len(r.IdentityFields))
+	for _, f := range r.IdentityFields {
+		if _, ok := keys[f.Key]; ok {
+			return errors.Wrap(ErrCertIdentityKeyExists, f.Key)
+		}
+		keys[f.Key] = struct{}{}
+	}
+	return nil
+}
+
+// CACertificate is a CA certificate registered by the tenant; the devices
+// presenting a certificate chain verified by the CA are accepted.
+type CACertificate struct {
+	Id             string              `json:"id" bson:"_id"`
+	Name           string              `json:"name,omitempty" bson:"name,omitempty"`
+	Certificate    string              `json:"certificate" bson:"certificate"`
+	Subject        string              `json:"subject" bson:"subject"`
+	Fingerprint    string              `json:"fingerprint" bson:"fingerprint"`
+	NotBefore      time.Time           `json:"not_before" bson:"not_before"`
+	NotAfter       time.Time           `json:"not_after" bson:"not_after"`
+	IdentityFields []CertIdentityField `json:"identity_fields" bson:"identity_fields"`
+	CRL            *CertRevocationList `json:"crl,omitempty" bson:"crl,omitempty"`
+	CreatedTs      time.Time           `json:"created_ts" bson:"created_ts"`
+	TenantID       string              `json:"-" bson:"tenant_id"`
+}
+
+// CertRevocationList is the last revocation list uploaded for the CA.
+type CertRevocationList struct {
+	// RevokedSerials are the hex encoded serial numbers of the revoked
+	// certificates.
+	RevokedSerials []string  `json:"-" bson:"revoked_serials"`
+	Revoked        int       `json:"revoked" bson:"revoked"`
+	ThisUpdate     time.Time `json:"this_update" bson:"this_update"`
+	NextUpdate     time.Time `json:"next_update,omitempty" bson:"next_update,omitempty"`
+}
+
+// NewCACertificate parses the PEM encoded CA certificate of the request.
+func NewCACertificate(r CACertificateReq) (*CACertificate, error) {
+	certs, err := ParseCertificateChain(r.Certificate)
+	if err != nil {
+		return nil, err
+	} else if len(certs) != 1 {
+		return nil, errors.New("expected exactly one certificate")
+	}
+	cert := certs[0]
+	if !cert.BasicConstraintsValid || !cert.IsCA {
+		return nil, ErrCertificateNotCA
+	}
+	fields := r.IdentityFields
+	if len(fields) == 0 {
+		fields = DefaultCertIdentityFields
+	}
+	return &CACertificate{
+		Id:   oid.NewUUIDv4().String(),
+		Name: r.Name,
+		Certificate: string(pem.EncodeToMemory(&pem.Block{
+			Type:  pemTypeCertificate,
+			Bytes: cert.Raw,
+		})),
+		Subject:        cert.Subject.String(),
+		Fingerprint:    CertificateFingerprint(cert),
+		NotBefore:      cert.NotBefore,
+		NotAfter:       cert.NotAfter,
+		IdentityFields: fields,
+		CreatedTs:      time.Now().UTC(),
+	}, nil
+}
+
+// X509 returns the parsed CA certificate.
+func (c CACertificate) X509() (*x509.Certificate, error) {
+	certs, err := ParseCertificateChain(c.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// IsRevoked returns true if the revocation list of the CA contains the
+// serial number.
+func (c CACertificate) IsRevoked(serial *big.Int) bool {
+	if c.CRL == nil || serial == nil {
+		return false
+	}
+	s := serial.Text(16)
+	for _, revoked := range c.CRL.RevokedSerials {
+		if revoked == s {
+			return true
+		}
+	}
+	return false
+}
+
+// CertificateFingerprint returns the hex encoded SHA256 digest of the
+// DER encoded certificate.
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseCertificateChain parses the PEM encoded certificates; the first
+// certificate is the leaf.
+func ParseCertificateChain(data string) ([]*x509.Certificate, error) {
+	var (
+		certs []*x509.Certificate
+		rest  = []byte(data)
+		block *pem.Block
+	)
+	for {
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != pemTypeCertificate {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse certificate")
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, ErrCertificateEmpty
+	}
+	return certs, nil
+}
+
+// ParseCertRevocationList parses the PEM or DER encoded revocation list
+// and checks it is signed by the CA.
+func ParseCertRevocationList(data []byte, ca *x509.Certificate) (*CertRevocationList, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != pemTypeCRL {
+			return nil, errors.Errorf("unexpected PEM block type: %s", block.Type)
+		}
+		data = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse revocation list")
+	}
+	if err := crl.CheckSignatureFrom(ca); err != nil {
+		return nil, errors.Wrap(err, "revocation list not signed by the CA")
+	}
+	res := &CertRevocationList{
+		RevokedSerials: make([]string, len(crl.RevokedCertificateEntries)),
+		Revoked:        len(crl.RevokedCertificateEntries),
+		ThisUpdate:     crl.ThisUpdate,
+		NextUpdate:     crl.NextUpdate,
+	}
+	for i, entry := range crl.RevokedCertificateEntries {
+		res.RevokedSerials[i] = entry.SerialNumber.Text(16)
+	}
+	return res, nil
+}
+
+// CertificateIdentity derives the identity data of the device from the
+// fields of its certificate; the first value of a multi-valued field is
+// used.
+func CertificateIdentity(
+	cert *x509.Certificate,
+	fields []CertIdentityField,
+) (map[string]interface{}, error) {
+	if len(fields) == 0 {
+		fields = DefaultCertIdentityFields
+	}
+	idData := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		var values []string
+		switch f.Field {
+		case CertFieldCommonName:
+			values = []string{cert.Subject.CommonName}
+		case CertFieldSerialNumber:
+			values = []string{cert.Subject.SerialNumber}
+		case CertFieldOrganization:
+			values = cert.Subject.Organization
+		case CertFieldOrganizationalUnit:
+			values = cert.Subject.OrganizationalUnit
+		case CertFieldCountry:
+			values = cert.Subject.Country
+		case CertFieldSANDNS:
+			values = cert.DNSNames
+		case CertFieldSANEmail:
+			values = cert.EmailAddresses
+		case CertFieldSANURI:
+			for _, uri := range cert.URIs {
+				values = append(values, uri.String())
+			}
+		case CertFieldSANIP:
+			for _, ip := range cert.IPAddresses {
+				values = append(values, ip.String())
+			}
+		default:
+			return nil, errors.Errorf("unknown certificate field: %s", f.Field)
+		}
+		if len(values) == 0 || values[0] == "" {
+			return nil, fmt.Errorf("the certificate has no %s", f.Field)
+		}
+		idData[f.Key] = values[0]
+	}
+	return idData, nil
+}
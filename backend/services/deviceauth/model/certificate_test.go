@@ -0,0 +1,161 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mtest "github.com/mendersoftware/mender-server/services/deviceauth/utils/testing"
+)
+
+func TestCACertificateReqValidate(t *testing.T) {
+	req := CACertificateReq{
+		Certificate: "cert",
+		IdentityFields: []CertIdentityField{
+			{Key: "sn", Field: CertFieldSerialNumber},
+		},
+	}
+	assert.NoError(t, req.Validate())
+
+	req.IdentityFields = append(req.IdentityFields,
+		CertIdentityField{Key: "sn", Field: CertFieldCommonName})
+	assert.EqualError(t, req.Validate(), "sn: duplicate identity attribute")
+
+	req.IdentityFields = []CertIdentityField{{Key: "sn", Field: "subject.foo"}}
+	assert.EqualError(t, req.Validate(),
+		"identity_fields: (0: (field: must be a valid value.).).")
+
+	req.Certificate = ""
+	req.IdentityFields = nil
+	assert.EqualError(t, req.Validate(), "certificate: cannot be blank.")
+}
+
+func TestNewCACertificate(t *testing.T) {
+	ca, caKey := mtest.NewCertificate(t, "ca", 1, nil, nil)
+	leaf, _ := mtest.NewCertificate(t, "device", 2, ca, caKey)
+
+	cert, err := NewCACertificate(CACertificateReq{
+		Name:        "factory",
+		Certificate: mtest.CertificatePEM(ca),
+	})
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, cert.Id)
+		assert.Equal(t, "factory", cert.Name)
+		assert.Equal(t, "CN=ca", cert.Subject)
+		assert.Equal(t, CertificateFingerprint(ca), cert.Fingerprint)
+		assert.Equal(t, DefaultCertIdentityFields, cert.IdentityFields)
+		parsed, err := cert.X509()
+		assert.NoError(t, err)
+		assert.Equal(t, ca.Raw, parsed.Raw)
+	}
+
+	_, err = NewCACertificate(CACertificateReq{
+		Certificate: mtest.CertificatePEM(leaf),
+	})
+	assert.ErrorIs(t, err, ErrCertificateNotCA)
+
+	_, err = NewCACertificate(CACertificateReq{
+		Certificate: mtest.CertificatePEM(ca, leaf),
+	})
+	assert.EqualError(t, err, "expected exactly one certificate")
+
+	_, err = NewCACertificate(CACertificateReq{
+		Certificate: "not a certificate",
+	})
+	assert.ErrorIs(t, err, ErrCertificateEmpty)
+}
+
+func TestParseCertRevocationList(t *testing.T) {
+	ca, caKey := mtest.NewCertificate(t, "ca", 1, nil, nil)
+	other, _ := mtest.NewCertificate(t, "other", 1, nil, nil)
+	der := mtest.NewRevocationList(t, ca, caKey, 2, 255)
+
+	crl, err := ParseCertRevocationList(der, ca)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"2", "ff"}, crl.RevokedSerials)
+		assert.Equal(t, 2, crl.Revoked)
+		cert := CACertificate{CRL: crl}
+		assert.True(t, cert.IsRevoked(big.NewInt(255)))
+		assert.False(t, cert.IsRevoked(big.NewInt(3)))
+	}
+
+	// PEM encoded
+	crl, err = ParseCertRevocationList(
+		pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), ca)
+	if assert.NoError(t, err) {
+		assert.Len(t, crl.RevokedSerials, 2)
+	}
+
+	_, err = ParseCertRevocationList(der, other)
+	assert.ErrorContains(t, err, "revocation list not signed by the CA")
+
+	_, err = ParseCertRevocationList([]byte("garbage"), ca)
+	assert.ErrorContains(t, err, "failed to parse revocation list")
+
+	assert.False(t, CACertificate{}.IsRevoked(big.NewInt(2)))
+}
+
+func TestCertificateIdentity(t *testing.T) {
+	uri, _ := url.Parse("urn:device:0001")
+	cert := &x509.Certificate{}
+	cert.Subject.CommonName = "device-0001"
+	cert.Subject.SerialNumber = "0001"
+	cert.Subject.Organization = []string{"Acme", "Acme Subsidiary"}
+	cert.Subject.OrganizationalUnit = []string{"Factory"}
+	cert.Subject.Country = []string{"NO"}
+	cert.DNSNames = []string{"device-0001.example.com"}
+	cert.EmailAddresses = []string{"device-0001@example.com"}
+	cert.URIs = []*url.URL{uri}
+	cert.IPAddresses = []net.IP{net.ParseIP("10.0.0.1")}
+
+	idData, err := CertificateIdentity(cert, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"common_name": "device-0001"}, idData)
+
+	idData, err = CertificateIdentity(cert, []CertIdentityField{
+		{Key: "sn", Field: CertFieldSerialNumber},
+		{Key: "org", Field: CertFieldOrganization},
+		{Key: "ou", Field: CertFieldOrganizationalUnit},
+		{Key: "country", Field: CertFieldCountry},
+		{Key: "dns", Field: CertFieldSANDNS},
+		{Key: "email", Field: CertFieldSANEmail},
+		{Key: "uri", Field: CertFieldSANURI},
+		{Key: "ip", Field: CertFieldSANIP},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"sn":      "0001",
+		"org":     "Acme",
+		"ou":      "Factory",
+		"country": "NO",
+		"dns":     "device-0001.example.com",
+		"email":   "device-0001@example.com",
+		"uri":     "urn:device:0001",
+		"ip":      "10.0.0.1",
+	}, idData)
+
+	cert.Subject.SerialNumber = ""
+	_, err = CertificateIdentity(cert, []CertIdentityField{
+		{Key: "sn", Field: CertFieldSerialNumber},
+	})
+	assert.EqualError(t, err, "the certificate has no subject.serial_number")
+}
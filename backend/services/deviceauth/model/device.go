@@ -60,6 +60,9 @@ type Device struct {
 	UpdatedTs       time.Time              `json:"updated_ts" bson:"updated_ts,omitempty"`
 	AuthSets        []AuthSet              `json:"auth_sets" bson:"-"`
 	CheckInTime     *time.Time             `json:"check_in_time,omitempty" bson:"check_in_time,omitempty"` // nolint:lll
+	// InactivityNotifiedTs is the time the device was notified of the
+	// action of the inactivity policy
+	InactivityNotifiedTs *time.Time `json:"-" bson:"inactivity_notified_ts,omitempty"`
 	//ApiLimits override tenant-wide quota/burst config
 	ApiLimits ratelimits.ApiLimits `json:"-" bson:"api_limits"`
 
@@ -79,6 +82,15 @@ type DeviceUpdate struct {
 	CheckInTime     *time.Time             `json:"-" bson:"check_in_time,omitempty"`
 }
 
+// LastActivity returns the last check-in time of the device, or the time
+// the device was created if it never checked in.
+func (d Device) LastActivity() time.Time {
+	if d.CheckInTime != nil {
+		return *d.CheckInTime
+	}
+	return d.CreatedTs
+}
+
 func NewDevice(id, id_data, pubkey string) *Device {
 	now := time.Now()
 
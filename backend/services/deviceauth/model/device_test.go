@@ -19,6 +19,7 @@ import (
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -178,3 +179,15 @@ func TestDeviceFilterUnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestDeviceLastActivity(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	dev := Device{CreatedTs: created, UpdatedTs: created.Add(time.Hour)}
+	assert.Equal(t, created, dev.LastActivity())
+
+	checkIn := created.Add(24 * time.Hour)
+	dev.CheckInTime = &checkIn
+	assert.Equal(t, checkIn, dev.LastActivity())
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
+
+// Actions the inactivity policy takes on the inactive devices.
+const (
+	// InactivityActionReject rejects the accepted auth set of the device
+	InactivityActionReject = "reject"
+	// InactivityActionDecommission decommissions the device
+	InactivityActionDecommission = "decommission"
+)
+
+// Results of applying the inactivity policy to a device.
+const (
+	// InactiveDeviceNotified: the device was notified of the upcoming action
+	InactiveDeviceNotified = "notified"
+	// InactiveDevicePending: the grace period of the device is not over yet
+	InactiveDevicePending = "pending"
+	// InactiveDeviceRejected: the device was rejected
+	InactiveDeviceRejected = "rejected"
+	// InactiveDeviceDecommissioned: the device was decommissioned
+	InactiveDeviceDecommissioned = "decommissioned"
+	// InactiveDeviceWithdrawn: the device checked in after it was
+	// notified, the notification was withdrawn
+	InactiveDeviceWithdrawn = "withdrawn"
+	// InactiveDeviceFailed: the policy could not be applied to the device
+	InactiveDeviceFailed = "failed"
+)
+
+const (
+	day = 24 * time.Hour
+
+	maxInactiveDays = 3650
+)
+
+var (
+	InactivityActions = []interface{}{
+		InactivityActionReject,
+		InactivityActionDecommission,
+	}
+
+	ErrInactivityPolicyGrace = errors.New("grace_days must be less than inactive_days")
+)
+
+// InactivityPolicy rejects or decommissions the accepted devices which have
+// not checked in for InactiveDays days. The devices are notified GraceDays
+// days before the action is taken; no notification is sent when GraceDays
+// is 0.
+type InactivityPolicy struct {
+	InactiveDays uint      `json:"inactive_days" bson:"inactive_days"`
+	GraceDays    uint      `json:"grace_days" bson:"grace_days"`
+	Action       string    `json:"action" bson:"action"`
+	UpdatedTs    time.Time `json:"updated_ts" bson:"updated_ts"`
+	TenantID     string    `json:"-" bson:"tenant_id"`
+}
+
+func (p InactivityPolicy) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.InactiveDays,
+			validation.Required, validation.Max(uint(maxInactiveDays))),
+		validation.Field(&p.Action, validation.Required, validation.In(InactivityActions...)),
+	)
+	if err != nil {
+		return err
+	}
+	if p.GraceDays >= p.InactiveDays {
+		return ErrInactivityPolicyGrace
+	}
+	return nil
+}
+
+// InactiveSince returns the time the devices must have been inactive since
+// for the action to be taken.
+func (p InactivityPolicy) InactiveSince(now time.Time) time.Time {
+	return now.Add(-time.Duration(p.InactiveDays) * day)
+}
+
+// NoticeSince returns the time the devices must have been inactive since
+// to be notified.
+func (p InactivityPolicy) NoticeSince(now time.Time) time.Time {
+	return now.Add(-time.Duration(p.InactiveDays-p.GraceDays) * day)
+}
+
+// ActionAfter returns the time the action can be taken on a device last
+// active at lastActivity and notified at notified; the action is never
+// taken before the grace period following the notification is over.
+func (p InactivityPolicy) ActionAfter(lastActivity time.Time, notified *time.Time) time.Time {
+	after := lastActivity.Add(time.Duration(p.InactiveDays) * day)
+	if notified != nil {
+		graceEnd := notified.Add(time.Duration(p.GraceDays) * day)
+		if graceEnd.After(after) {
+			after = graceEnd
+		}
+	}
+	return after
+}
+
+// InactiveDevice is the result of applying the inactivity policy to a
+// device.
+type InactiveDevice struct {
+	DeviceId     string                 `json:"device_id"`
+	IdData       map[string]interface{} `json:"identity_data"`
+	LastActivity time.Time              `json:"last_activity"`
+	Result       string                 `json:"result"`
+	ActionAfter  *time.Time             `json:"action_after,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// InactivityReport is the result of applying the inactivity policy of a
+// tenant.
+type InactivityReport struct {
+	TenantID       string            `json:"tenant_id,omitempty"`
+	DryRun         bool              `json:"dry_run"`
+	Policy         *InactivityPolicy `json:"policy"`
+	Notified       int               `json:"notified"`
+	Pending        int               `json:"pending"`
+	Rejected       int               `json:"rejected"`
+	Decommissioned int               `json:"decommissioned"`
+	Withdrawn      int               `json:"withdrawn"`
+	Failed         int               `json:"failed"`
+	Devices        []InactiveDevice  `json:"devices"`
+}
+
+// Add records the result of a device and updates the counters.
+func (r *InactivityReport) Add(dev InactiveDevice) {
+	switch dev.Result {
+	case InactiveDeviceNotified:
+		r.Notified++
+	case InactiveDevicePending:
+		r.Pending++
+	case InactiveDeviceRejected:
+		r.Rejected++
+	case InactiveDeviceDecommissioned:
+		r.Decommissioned++
+	case InactiveDeviceWithdrawn:
+		r.Withdrawn++
+	default:
+		r.Failed++
+	}
+	r.Devices = append(r.Devices, dev)
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInactivityPolicyValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+
+		policy InactivityPolicy
+
+		err string
+	}{{
+		desc: "ok",
+		policy: InactivityPolicy{
+			InactiveDays: 90,
+			GraceDays:    14,
+			Action:       InactivityActionDecommission,
+		},
+	}, {
+		desc: "ok, no grace period",
+		policy: InactivityPolicy{
+			InactiveDays: 90,
+			Action:       InactivityActionReject,
+		},
+	}, {
+		desc: "error, no inactive days",
+		policy: InactivityPolicy{
+			Action: InactivityActionReject,
+		},
+		err: "inactive_days: cannot be blank.",
+	}, {
+		desc: "error, too many inactive days",
+		policy: InactivityPolicy{
+			InactiveDays: 5000,
+			Action:       InactivityActionReject,
+		},
+		err: "inactive_days: must be no greater than 3650.",
+	}, {
+		desc: "error, action",
+		policy: InactivityPolicy{
+			InactiveDays: 90,
+			Action:       "delete",
+		},
+		err: "action: must be a valid value.",
+	}, {
+		desc: "error, grace period",
+		policy: InactivityPolicy{
+			InactiveDays: 90,
+			GraceDays:    90,
+			Action:       InactivityActionReject,
+		},
+		err: ErrInactivityPolicyGrace.Error(),
+	}}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.policy.Validate()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInactivityPolicyTimes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	policy := InactivityPolicy{
+		InactiveDays: 90,
+		GraceDays:    14,
+		Action:       InactivityActionDecommission,
+	}
+	assert.Equal(t, now.AddDate(0, 0, -90), policy.InactiveSince(now))
+	assert.Equal(t, now.AddDate(0, 0, -76), policy.NoticeSince(now))
+
+	last := now.AddDate(0, 0, -80)
+	assert.Equal(t, last.AddDate(0, 0, 90), policy.ActionAfter(last, nil))
+	// the grace period is over after the inactive days
+	assert.Equal(t, now.AddDate(0, 0, 14), policy.ActionAfter(last, &now))
+	// the grace period is over before the inactive days
+	notified := now.AddDate(0, 0, -10)
+	assert.Equal(t, last.AddDate(0, 0, 90), policy.ActionAfter(last, &notified))
+}
+
+func TestInactivityReportAdd(t *testing.T) {
+	t.Parallel()
+
+	report := InactivityReport{}
+	for _, result := range []string{
+		InactiveDeviceNotified,
+		InactiveDeviceNotified,
+		InactiveDevicePending,
+		InactiveDeviceRejected,
+		InactiveDeviceDecommissioned,
+		InactiveDeviceWithdrawn,
+		InactiveDeviceFailed,
+	} {
+		report.Add(InactiveDevice{Result: result})
+	}
+	assert.Equal(t, 2, report.Notified)
+	assert.Equal(t, 1, report.Pending)
+	assert.Equal(t, 1, report.Rejected)
+	assert.Equal(t, 1, report.Decommissioned)
+	assert.Equal(t, 1, report.Withdrawn)
+	assert.Equal(t, 1, report.Failed)
+	assert.Len(t, report.Devices, 7)
+}
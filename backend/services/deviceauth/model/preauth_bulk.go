@@ -0,0 +1,247 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Formats of the bulk preauthorization files.
+const (
+	PreAuthFormatCSV    = "csv"
+	PreAuthFormatNDJSON = "ndjson"
+)
+
+// Columns of the bulk preauthorization CSV files with a special meaning;
+// all the other columns are identity data attributes.
+const (
+	PreAuthColumnPubKey = "pubkey"
+	PreAuthColumnForce  = "force"
+)
+
+// Results of preauthorizing a device from a bulk preauthorization file.
+const (
+	// PreAuthResultCreated: the device was preauthorized
+	PreAuthResultCreated = "created"
+	// PreAuthResultUpdated: the key was added to an existing device (force)
+	PreAuthResultUpdated = "updated"
+	// PreAuthResultUnchanged: the device is already preauthorized or
+	// accepted with the key
+	PreAuthResultUnchanged = "unchanged"
+	// PreAuthResultInvalid: the record is not valid
+	PreAuthResultInvalid = "invalid"
+	// PreAuthResultConflict: a device with the identity data exists with
+	// a different key
+	PreAuthResultConflict = "conflict"
+	// PreAuthResultFailed: the device could not be preauthorized
+	PreAuthResultFailed = "failed"
+)
+
+const maxPreAuthRecordSize = 1024 * 1024
+
+var (
+	ErrPreAuthFormat       = errors.New("format must be one of: csv, ndjson")
+	ErrPreAuthNoPubKey     = errors.New("the CSV header must have the pubkey column")
+	ErrPreAuthNoIdentity   = errors.New("the CSV header must have an identity data column")
+	ErrPreAuthDuplicateCol = errors.New("the CSV header has duplicate columns")
+)
+
+// PreAuthRecord is a device to preauthorize read from a bulk
+// preauthorization file.
+type PreAuthRecord struct {
+	// Line is the line of the file the record starts at
+	Line   int                    `json:"-"`
+	Force  bool                   `json:"force"`
+	IdData map[string]interface{} `json:"identity_data"`
+	PubKey string                 `json:"pubkey"`
+	// Err is the error reading the record from the file
+	Err error `json:"-"`
+}
+
+// PreAuthReq validates the record and returns the preauthorization request
+// of the device with new device and auth set ids.
+func (r PreAuthRecord) PreAuthReq() (*PreAuthReq, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	if len(r.IdData) == 0 {
+		return nil, errors.New("identity_data: cannot be blank.")
+	}
+	idData, err := json.Marshal(r.IdData)
+	if err != nil {
+		return nil, err
+	}
+	req := &PreAuthReq{
+		Force:     r.Force,
+		DeviceId:  uuid.NewString(),
+		AuthSetId: uuid.NewString(),
+		IdData:    string(idData),
+		PubKey:    r.PubKey,
+	}
+	if _, err := req.normalize(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// PreAuthRecordResult is the result of preauthorizing the device of a
+// record.
+type PreAuthRecordResult struct {
+	Line     int    `json:"line"`
+	Status   string `json:"status"`
+	DeviceId string `json:"device_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// PreAuthBulkResult is the result of a bulk preauthorization.
+type PreAuthBulkResult struct {
+	DryRun    bool                  `json:"dry_run"`
+	Created   int                   `json:"created"`
+	Updated   int                   `json:"updated"`
+	Unchanged int                   `json:"unchanged"`
+	Failed    int                   `json:"failed"`
+	Results   []PreAuthRecordResult `json:"results"`
+}
+
+// Add records the result of a record and updates the counters.
+func (r *PreAuthBulkResult) Add(res PreAuthRecordResult) {
+	switch res.Status {
+	case PreAuthResultCreated:
+		r.Created++
+	case PreAuthResultUpdated:
+		r.Updated++
+	case PreAuthResultUnchanged:
+		r.Unchanged++
+	default:
+		r.Failed++
+	}
+	r.Results = append(r.Results, res)
+}
+
+// ReadPreAuthRecords reads the records of a bulk preauthorization file;
+// the records which cannot be decoded carry the error, while the error is
+// returned if the file cannot be read at all.
+func ReadPreAuthRecords(source io.Reader, format string) ([]PreAuthRecord, error) {
+	switch format {
+	case PreAuthFormatCSV:
+		return readPreAuthRecordsCSV(source)
+	case PreAuthFormatNDJSON:
+		return readPreAuthRecordsNDJSON(source)
+	default:
+		return nil, ErrPreAuthFormat
+	}
+}
+
+func readPreAuthRecordsCSV(source io.Reader) ([]PreAuthRecord, error) {
+	r := csv.NewReader(source)
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return []PreAuthRecord{}, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read the CSV header")
+	}
+	columns := make(map[string]bool, len(header))
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+		if columns[header[i]] {
+			return nil, ErrPreAuthDuplicateCol
+		}
+		columns[header[i]] = true
+	}
+	if !columns[PreAuthColumnPubKey] {
+		return nil, ErrPreAuthNoPubKey
+	}
+	delete(columns, PreAuthColumnPubKey)
+	delete(columns, PreAuthColumnForce)
+	if len(columns) == 0 {
+		return nil, ErrPreAuthNoIdentity
+	}
+
+	records := []PreAuthRecord{}
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, errors.Wrap(err, "failed to read the CSV file")
+		}
+		line, _ := r.FieldPos(0)
+		record := PreAuthRecord{Line: line}
+		if err != nil {
+			record.Err = errors.Errorf(
+				"expected %d fields, got %d", len(header), len(row))
+			records = append(records, record)
+			continue
+		}
+		record.IdData = make(map[string]interface{}, len(header))
+		for i, value := range row {
+			switch header[i] {
+			case PreAuthColumnPubKey:
+				record.PubKey = value
+			case PreAuthColumnForce:
+				if value == "" {
+					continue
+				}
+				record.Force, err = strconv.ParseBool(value)
+				if err != nil {
+					record.Err = errors.Errorf("force: invalid value %q", value)
+				}
+			default:
+				// the empty cells stand for the attributes
+				// the device does not have
+				if value != "" {
+					record.IdData[header[i]] = value
+				}
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func readPreAuthRecordsNDJSON(source io.Reader) ([]PreAuthRecord, error) {
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(nil, maxPreAuthRecordSize)
+
+	records := []PreAuthRecord{}
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		record := PreAuthRecord{}
+		if err := json.Unmarshal(data, &record); err != nil {
+			record = PreAuthRecord{Err: errors.Wrap(err, "failed to decode the record")}
+		}
+		record.Line = line
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the NDJSON file")
+	}
+	return records, nil
+}
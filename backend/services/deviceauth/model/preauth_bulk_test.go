@@ -0,0 +1,153 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPreAuthRecordsCSV(t *testing.T) {
+	t.Parallel()
+
+	file := "sn, mac, pubkey, force\n" +
+		`sn-1,00:11:22:33:44:55,"` + pubKeyRSA + `",` + "\n" +
+		`sn-2,,"` + pubKeyRSA + `",true` + "\n" +
+		"sn-3,00:11:22:33:44:57\n" +
+		`sn-4,00:11:22:33:44:58,"` + pubKeyRSA + `",maybe` + "\n"
+
+	records, err := ReadPreAuthRecords(strings.NewReader(file), PreAuthFormatCSV)
+	assert.NoError(t, err)
+	if assert.Len(t, records, 4) {
+		assert.Equal(t, PreAuthRecord{
+			Line: 2,
+			IdData: map[string]interface{}{
+				"sn":  "sn-1",
+				"mac": "00:11:22:33:44:55",
+			},
+			PubKey: pubKeyRSA,
+		}, records[0])
+		assert.Equal(t, PreAuthRecord{
+			Line:   12,
+			Force:  true,
+			IdData: map[string]interface{}{"sn": "sn-2"},
+			PubKey: pubKeyRSA,
+		}, records[1])
+		assert.Equal(t, 22, records[2].Line)
+		assert.EqualError(t, records[2].Err, "expected 4 fields, got 2")
+		assert.EqualError(t, records[3].Err, `force: invalid value "maybe"`)
+	}
+
+	records, err = ReadPreAuthRecords(strings.NewReader(""), PreAuthFormatCSV)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	_, err = ReadPreAuthRecords(strings.NewReader("sn,mac\n"), PreAuthFormatCSV)
+	assert.ErrorIs(t, err, ErrPreAuthNoPubKey)
+
+	_, err = ReadPreAuthRecords(strings.NewReader("pubkey,force\n"), PreAuthFormatCSV)
+	assert.ErrorIs(t, err, ErrPreAuthNoIdentity)
+
+	_, err = ReadPreAuthRecords(strings.NewReader("sn,sn,pubkey\n"), PreAuthFormatCSV)
+	assert.ErrorIs(t, err, ErrPreAuthDuplicateCol)
+
+	_, err = ReadPreAuthRecords(
+		strings.NewReader("sn,pubkey\n\"sn-1,key\n"), PreAuthFormatCSV)
+	assert.Error(t, err)
+}
+
+func TestReadPreAuthRecordsNDJSON(t *testing.T) {
+	t.Parallel()
+
+	line, _ := json.Marshal(map[string]interface{}{
+		"identity_data": map[string]interface{}{"sn": "sn-1"},
+		"pubkey":        pubKeyRSA,
+		"force":         true,
+	})
+	file := string(line) + "\n\n" + "{garbage\n"
+
+	records, err := ReadPreAuthRecords(strings.NewReader(file), PreAuthFormatNDJSON)
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, PreAuthRecord{
+			Line:   1,
+			Force:  true,
+			IdData: map[string]interface{}{"sn": "sn-1"},
+			PubKey: pubKeyRSA,
+		}, records[0])
+		assert.Equal(t, 3, records[1].Line)
+		assert.ErrorContains(t, records[1].Err, "failed to decode the record")
+	}
+
+	_, err = ReadPreAuthRecords(strings.NewReader(file), "xml")
+	assert.ErrorIs(t, err, ErrPreAuthFormat)
+}
+
+func TestPreAuthRecordPreAuthReq(t *testing.T) {
+	t.Parallel()
+
+	record := PreAuthRecord{
+		Line: 2,
+		IdData: map[string]interface{}{
+			"sn":  "sn-1",
+			"mac": "00:11:22:33:44:55",
+		},
+		PubKey: pubKeyRSA,
+	}
+	req, err := record.PreAuthReq()
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, req.DeviceId)
+		assert.NotEmpty(t, req.AuthSetId)
+		assert.NotEqual(t, req.DeviceId, req.AuthSetId)
+		assert.Equal(t, `{"mac":"00:11:22:33:44:55","sn":"sn-1"}`, req.IdData)
+		assert.Equal(t, pubKeyRSA, req.PubKey)
+	}
+
+	_, err = PreAuthRecord{PubKey: pubKeyRSA}.PreAuthReq()
+	assert.EqualError(t, err, "identity_data: cannot be blank.")
+
+	record.PubKey = ""
+	_, err = record.PreAuthReq()
+	assert.EqualError(t, err, "pubkey: cannot be blank.")
+
+	record.PubKey = "garbage"
+	_, err = record.PreAuthReq()
+	assert.EqualError(t, err, "cannot decode public key")
+}
+
+func TestPreAuthBulkResultAdd(t *testing.T) {
+	t.Parallel()
+
+	res := PreAuthBulkResult{}
+	for _, status := range []string{
+		PreAuthResultCreated,
+		PreAuthResultCreated,
+		PreAuthResultUpdated,
+		PreAuthResultUnchanged,
+		PreAuthResultInvalid,
+		PreAuthResultConflict,
+		PreAuthResultFailed,
+	} {
+		res.Add(PreAuthRecordResult{Status: status})
+	}
+	assert.Equal(t, 2, res.Created)
+	assert.Equal(t, 1, res.Updated)
+	assert.Equal(t, 1, res.Unchanged)
+	assert.Equal(t, 3, res.Failed)
+	assert.Len(t, res.Results, 7)
+}
@@ -49,6 +49,21 @@ func ParsePreAuthReq(source io.Reader) (*PreAuthReq, error) {
 }
 
 func (r *PreAuthReq) Validate() error {
+	key, err := r.normalize()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := key.(*rsa.PublicKey); !ok {
+		return errors.New("cannot decode key as RSA public key")
+	}
+
+	return nil
+}
+
+// normalize validates the request, sorts the identity data and serializes
+// the public key of any supported type.
+func (r *PreAuthReq) normalize() (interface{}, error) {
 	err := validation.ValidateStruct(r,
 		validation.Field(&r.DeviceId, validation.Required),
 		validation.Field(&r.AuthSetId, validation.Required),
@@ -56,11 +71,11 @@ func (r *PreAuthReq) Validate() error {
 		validation.Field(&r.PubKey, validation.Required),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if sorted, err := utils.JsonSort(r.IdData); err != nil {
-		return err
+		return nil, err
 	} else {
 		r.IdData = sorted
 	}
@@ -68,20 +83,15 @@ func (r *PreAuthReq) Validate() error {
 	//normalize key
 	key, err := utils.ParsePubKey(r.PubKey)
 	if err != nil {
-		return err
-	}
-
-	keyStruct, ok := key.(*rsa.PublicKey)
-	if !ok {
-		return errors.New("cannot decode key as RSA public key")
+		return nil, err
 	}
 
-	serialized, err := utils.SerializePubKey(keyStruct)
+	serialized, err := utils.SerializePubKey(key)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	r.PubKey = serialized
 
-	return nil
+	return key, nil
 }
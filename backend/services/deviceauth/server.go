@@ -20,6 +20,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -60,14 +63,13 @@ func RunServer(c config.Reader) error {
 		return errors.Wrap(err, "database connection failed")
 	}
 
-	jwtHandler, err := jwt.NewJWTHandler(
-		c.GetString(dconfig.SettingServerPrivKeyPath),
-	)
+	jwtHandler, err := loadKeyring(c, l)
 	var jwtFallbackHandler jwt.Handler
 	fallback := c.GetString(dconfig.SettingServerFallbackPrivKeyPath)
 	if err == nil && fallback != "" {
 		jwtFallbackHandler, err = jwt.NewJWTHandler(
 			fallback,
+			c.GetString(dconfig.SettingServerPrivKeyFileNamePattern),
 		)
 	}
 	if err != nil {
@@ -128,6 +130,12 @@ func RunServer(c config.Reader) error {
 	apiOptions = append(apiOptions, api_http.SetMaxRequestSize(
 		int64(c.GetInt(dconfig.SettingMaxRequestSize)),
 	))
+	if hdr := c.GetString(dconfig.SettingClientCertificateHeader); hdr != "" {
+		apiOptions = append(apiOptions, api_http.SetClientCertificateHeader(hdr))
+	}
+	apiOptions = append(apiOptions, api_http.SetClientIPProxyDepth(
+		c.GetInt(dconfig.SettingClientIPProxyDepth),
+	))
 	apiHandler := api_http.NewRouter(devauth, db, apiOptions...)
 
 	addr := c.GetString(dconfig.SettingListen)
@@ -193,3 +201,71 @@ func setupRedis(c config.Reader, connStr string) (cache.Cache, *rate.HTTPLimiter
 	}
 	return cache, rateLimiter, nil
 }
+
+// loadKeyring loads the active key, the keys matching the filename pattern
+// in the directory of the active key and the key at the default path,
+// except the retired ones.
+func loadKeyring(c config.Reader, l *log.Logger) (*jwt.Keyring, error) {
+	activeKeyPath := c.GetString(dconfig.SettingServerPrivKeyPath)
+	pattern := c.GetString(dconfig.SettingServerPrivKeyFileNamePattern)
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid private key filename pattern")
+	}
+
+	retired := make(map[int]bool)
+	for _, s := range c.GetStringSlice(dconfig.SettingServerRetiredKeyIds) {
+		keyId, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid retired key id %q", s)
+		}
+		retired[keyId] = true
+	}
+
+	activeKeyId := jwt.KeyIdFromPath(activeKeyPath, pattern)
+	if retired[activeKeyId] {
+		return nil, errors.Errorf("the active key id %d is retired", activeKeyId)
+	}
+	activeHandler, err := jwt.NewJWTHandler(activeKeyPath, pattern)
+	if err != nil {
+		return nil, err
+	}
+	handlers := map[int]jwt.Handler{activeKeyId: activeHandler}
+
+	keysDir := filepath.Dir(activeKeyPath)
+	files, err := os.ReadDir(keysDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the private keys")
+	}
+	for _, file := range files {
+		if file.IsDir() || !r.MatchString(file.Name()) {
+			continue
+		}
+		keyPath := filepath.Join(keysDir, file.Name())
+		keyId := jwt.KeyIdFromPath(keyPath, pattern)
+		if _, ok := handlers[keyId]; ok || retired[keyId] {
+			continue
+		}
+		handler, err := jwt.NewJWTHandler(keyPath, pattern)
+		if err != nil {
+			l.Warnf("failed to load private key %s: %s", keyPath, err.Error())
+			continue
+		}
+		l.Infof("loaded private key id=%d from %s", keyId, keyPath)
+		handlers[keyId] = handler
+	}
+	// the key at the default path verifies the tokens issued without the
+	// key id before the key was rotated
+	if _, ok := handlers[jwt.KeyIdZero]; !ok && !retired[jwt.KeyIdZero] &&
+		activeKeyPath != dconfig.SettingServerPrivKeyPathDefault {
+		handler, err := jwt.NewJWTHandler(dconfig.SettingServerPrivKeyPathDefault, pattern)
+		if err == nil {
+			l.Infof("loaded private key id=%d from %s",
+				jwt.KeyIdZero, dconfig.SettingServerPrivKeyPathDefault)
+			handlers[jwt.KeyIdZero] = handler
+		}
+	}
+	l.Infof("signing tokens with private key id=%d from %s", activeKeyId, activeKeyPath)
+
+	return jwt.NewKeyring(activeKeyId, handlers)
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/pkg/log"
+
+	dconfig "github.com/mendersoftware/mender-server/services/deviceauth/config"
+)
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+	for src, dst := range map[string]string{
+		"./jwt/testdata/rsa.pem":       "private.id.1.pem",
+		"./jwt/testdata/ed25519.pem":   "private.id.2.pem",
+		"./jwt/testdata/rsa_pkcs8.pem": "private.id.3.pem",
+		"./jwt/testdata/dsa.pem":       "private.id.4.pem",
+	} {
+		data, err := os.ReadFile(src)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, dst), data, 0600))
+	}
+
+	l := log.New(log.Ctx{})
+	c := viper.New()
+	c.Set(dconfig.SettingServerPrivKeyPath, filepath.Join(dir, "private.id.2.pem"))
+	c.Set(dconfig.SettingServerPrivKeyFileNamePattern,
+		dconfig.SettingServerPrivKeyFileNamePatternDefault)
+	c.Set(dconfig.SettingServerRetiredKeyIds, []string{"3"})
+
+	keyring, err := loadKeyring(c, l)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, keyring.ActiveKeyId())
+		jwks, err := keyring.JWKS()
+		assert.NoError(t, err)
+		keyIds := []string{}
+		for _, key := range jwks.Keys {
+			keyIds = append(keyIds, key.KeyID)
+		}
+		// the retired and unsupported keys are not loaded
+		assert.Equal(t, []string{"1", "2"}, keyIds)
+	}
+
+	c.Set(dconfig.SettingServerRetiredKeyIds, []string{"2"})
+	_, err = loadKeyring(c, l)
+	assert.EqualError(t, err, "the active key id 2 is retired")
+
+	c.Set(dconfig.SettingServerRetiredKeyIds, []string{"foo"})
+	_, err = loadKeyring(c, l)
+	assert.ErrorContains(t, err, `invalid retired key id "foo"`)
+}
@@ -17,6 +17,7 @@ package store
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/mendersoftware/mender-server/pkg/mongo/migrate"
 	"github.com/mendersoftware/mender-server/pkg/mongo/oid"
@@ -36,6 +37,14 @@ var (
 	ErrLimitNotFound = errors.New("limit not found")
 	// device already exists
 	ErrObjectExists = errors.New("object exists")
+	// CA certificate not found
+	ErrCACertificateNotFound = errors.New("CA certificate not found")
+	// auto-accept rule not found
+	ErrAutoAcceptRuleNotFound = errors.New("auto-accept rule not found")
+	// auto-accept rule budget exhausted
+	ErrAutoAcceptRuleExhausted = errors.New("auto-accept rule budget exhausted")
+	// inactivity policy not found
+	ErrInactivityPolicyNotFound = errors.New("inactivity policy not found")
 	// device status unknown
 	ErrDevStatusBroken = errors.New("cannot qualify device status")
 )
@@ -70,6 +79,12 @@ type DataStore interface {
 	// returns ErrDevNotFound if device not found
 	GetDeviceByIdentityDataHash(ctx context.Context, idataHash []byte) (*model.Device, error)
 
+	// retrieve the devices with any of the identity data hashes
+	GetDevicesByIdentityDataHashes(
+		ctx context.Context,
+		idataHashes [][]byte,
+	) ([]model.Device, error)
+
 	// list devices
 	GetDevices(
 		ctx context.Context,
@@ -80,6 +95,10 @@ type DataStore interface {
 
 	AddDevice(ctx context.Context, d model.Device) error
 
+	// AddDevices inserts the devices in one request, returning the
+	// indexes of the devices whose identity data already exists
+	AddDevices(ctx context.Context, devs []model.Device) ([]int, error)
+
 	// updates a single device with deviceID, using data from `up`
 	UpdateDevice(ctx context.Context, deviceID string, up model.DeviceUpdate) error
 
@@ -87,6 +106,10 @@ type DataStore interface {
 	DeleteDevice(ctx context.Context, id string) error
 
 	AddAuthSet(ctx context.Context, set model.AuthSet) error
+
+	// AddAuthSets inserts the auth sets in one request, returning the
+	// indexes of the auth sets which already exist
+	AddAuthSets(ctx context.Context, sets []model.AuthSet) ([]int, error)
 	UpsertAuthSetStatus(ctx context.Context, authSet *model.AuthSet) error
 
 	GetAuthSetByIdDataHashKey(
@@ -95,6 +118,12 @@ type DataStore interface {
 		key string,
 	) (*model.AuthSet, error)
 
+	// retrieve the auth sets of any of the identity data hashes
+	GetAuthSetsByIdDataHashes(
+		ctx context.Context,
+		idDataHashes [][]byte,
+	) ([]model.AuthSet, error)
+
 	GetAuthSetByIdDataHashKeyByStatus(
 		ctx context.Context,
 		idDataHash []byte,
@@ -149,6 +178,92 @@ type DataStore interface {
 	// gets device status
 	GetDeviceStatus(ctx context.Context, dev_id string) (string, error)
 
+	// adds a CA certificate of the tenant
+	// returns ErrObjectExists if the certificate is already registered
+	AddCACertificate(ctx context.Context, cert model.CACertificate) error
+
+	// lists the CA certificates of the tenant
+	GetCACertificates(ctx context.Context) ([]model.CACertificate, error)
+
+	// fetches a CA certificate of the tenant
+	// returns ErrCACertificateNotFound if the certificate is not found
+	GetCACertificate(ctx context.Context, id string) (*model.CACertificate, error)
+
+	// deletes a CA certificate of the tenant
+	// returns ErrCACertificateNotFound if the certificate is not found
+	DeleteCACertificate(ctx context.Context, id string) error
+
+	// replaces the revocation list of a CA certificate of the tenant
+	// returns ErrCACertificateNotFound if the certificate is not found
+	SetCACertificateCRL(ctx context.Context, id string, crl model.CertRevocationList) error
+
+	// adds an auto-accept rule of the tenant
+	AddAutoAcceptRule(ctx context.Context, rule model.AutoAcceptRule) error
+
+	// lists the auto-accept rules of the tenant, oldest first
+	GetAutoAcceptRules(ctx context.Context) ([]model.AutoAcceptRule, error)
+
+	// fetches an auto-accept rule of the tenant
+	// returns ErrAutoAcceptRuleNotFound if the rule is not found
+	GetAutoAcceptRule(ctx context.Context, id string) (*model.AutoAcceptRule, error)
+
+	// deletes an auto-accept rule of the tenant
+	// returns ErrAutoAcceptRuleNotFound if the rule is not found
+	DeleteAutoAcceptRule(ctx context.Context, id string) error
+
+	// takes one accept from the budget of the auto-accept rule
+	// returns ErrAutoAcceptRuleExhausted if the budget is exhausted or
+	// the rule is not found
+	ConsumeAutoAcceptRule(ctx context.Context, id string) error
+
+	// returns one accept to the budget of the auto-accept rule
+	ReleaseAutoAcceptRule(ctx context.Context, id string) error
+
+	// records a device accepted by an auto-accept rule
+	AddAutoAcceptAudit(ctx context.Context, audit model.AutoAcceptAudit) error
+
+	// lists the devices accepted by the auto-accept rules, newest first
+	GetAutoAcceptAudit(
+		ctx context.Context,
+		filter model.AutoAcceptAuditFilter,
+		skip,
+		limit uint,
+	) ([]model.AutoAcceptAudit, error)
+
+	// fetches the inactivity policy of the tenant
+	// returns ErrInactivityPolicyNotFound if the tenant has no policy
+	GetInactivityPolicy(ctx context.Context) (*model.InactivityPolicy, error)
+
+	// creates or replaces the inactivity policy of the tenant
+	SetInactivityPolicy(ctx context.Context, policy model.InactivityPolicy) error
+
+	// deletes the inactivity policy of the tenant
+	// returns ErrInactivityPolicyNotFound if the tenant has no policy
+	DeleteInactivityPolicy(ctx context.Context) error
+
+	// lists the accepted devices which have not checked in since the given
+	// time, or which never checked in and were created before it; the
+	// devices are sorted by id and listed after the device with id afterID
+	GetInactiveDevices(
+		ctx context.Context,
+		since time.Time,
+		afterID string,
+		limit uint,
+	) ([]model.Device, error)
+
+	// lists the accepted devices notified of the action of the inactivity
+	// policy; the devices are sorted by id and listed after the device
+	// with id afterID
+	GetInactivityNotifiedDevices(
+		ctx context.Context,
+		afterID string,
+		limit uint,
+	) ([]model.Device, error)
+
+	// sets the time the device was notified of the action of the
+	// inactivity policy, or clears it if ts is nil
+	SetDeviceInactivityNotified(ctx context.Context, deviceID string, ts *time.Time) error
+
 	MigrateTenant(ctx context.Context, version string, tenant string) error
 	WithAutomigrate() DataStore
 	//call this one if you really know what you are doing. This is supposed to be called only
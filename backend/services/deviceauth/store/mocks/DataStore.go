@@ -29,6 +29,8 @@ import (
 	oid "github.com/mendersoftware/mender-server/pkg/mongo/oid"
 
 	store "github.com/mendersoftware/mender-server/services/deviceauth/store"
+
+	time "time"
 )
 
 // DataStore is an autogenerated mock type for the DataStore type
@@ -54,6 +56,90 @@ func (_m *DataStore) AddAuthSet(ctx context.Context, set model.AuthSet) error {
 	return r0
 }
 
+// AddAuthSets provides a mock function with given fields: ctx, sets
+func (_m *DataStore) AddAuthSets(ctx context.Context, sets []model.AuthSet) ([]int, error) {
+	ret := _m.Called(ctx, sets)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAuthSets")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.AuthSet) ([]int, error)); ok {
+		return rf(ctx, sets)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []model.AuthSet) []int); ok {
+		r0 = rf(ctx, sets)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []model.AuthSet) error); ok {
+		r1 = rf(ctx, sets)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddAutoAcceptAudit provides a mock function with given fields: ctx, audit
+func (_m *DataStore) AddAutoAcceptAudit(ctx context.Context, audit model.AutoAcceptAudit) error {
+	ret := _m.Called(ctx, audit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAutoAcceptAudit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.AutoAcceptAudit) error); ok {
+		r0 = rf(ctx, audit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddAutoAcceptRule provides a mock function with given fields: ctx, rule
+func (_m *DataStore) AddAutoAcceptRule(ctx context.Context, rule model.AutoAcceptRule) error {
+	ret := _m.Called(ctx, rule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAutoAcceptRule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.AutoAcceptRule) error); ok {
+		r0 = rf(ctx, rule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddCACertificate provides a mock function with given fields: ctx, cert
+func (_m *DataStore) AddCACertificate(ctx context.Context, cert model.CACertificate) error {
+	ret := _m.Called(ctx, cert)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddCACertificate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.CACertificate) error); ok {
+		r0 = rf(ctx, cert)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AddDevice provides a mock function with given fields: ctx, d
 func (_m *DataStore) AddDevice(ctx context.Context, d model.Device) error {
 	ret := _m.Called(ctx, d)
@@ -72,6 +158,36 @@ func (_m *DataStore) AddDevice(ctx context.Context, d model.Device) error {
 	return r0
 }
 
+// AddDevices provides a mock function with given fields: ctx, devs
+func (_m *DataStore) AddDevices(ctx context.Context, devs []model.Device) ([]int, error) {
+	ret := _m.Called(ctx, devs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddDevices")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.Device) ([]int, error)); ok {
+		return rf(ctx, devs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []model.Device) []int); ok {
+		r0 = rf(ctx, devs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []model.Device) error); ok {
+		r1 = rf(ctx, devs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddToken provides a mock function with given fields: ctx, t
 func (_m *DataStore) AddToken(ctx context.Context, t *jwt.Token) error {
 	ret := _m.Called(ctx, t)
@@ -90,6 +206,24 @@ func (_m *DataStore) AddToken(ctx context.Context, t *jwt.Token) error {
 	return r0
 }
 
+// ConsumeAutoAcceptRule provides a mock function with given fields: ctx, id
+func (_m *DataStore) ConsumeAutoAcceptRule(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeAutoAcceptRule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteAuthSetForDevice provides a mock function with given fields: ctx, devId, authId
 func (_m *DataStore) DeleteAuthSetForDevice(ctx context.Context, devId string, authId string) error {
 	ret := _m.Called(ctx, devId, authId)
@@ -126,6 +260,42 @@ func (_m *DataStore) DeleteAuthSetsForDevice(ctx context.Context, devid string)
 	return r0
 }
 
+// DeleteAutoAcceptRule provides a mock function with given fields: ctx, id
+func (_m *DataStore) DeleteAutoAcceptRule(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAutoAcceptRule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteCACertificate provides a mock function with given fields: ctx, id
+func (_m *DataStore) DeleteCACertificate(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCACertificate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteDevice provides a mock function with given fields: ctx, id
 func (_m *DataStore) DeleteDevice(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
@@ -144,6 +314,24 @@ func (_m *DataStore) DeleteDevice(ctx context.Context, id string) error {
 	return r0
 }
 
+// DeleteInactivityPolicy provides a mock function with given fields: ctx
+func (_m *DataStore) DeleteInactivityPolicy(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteInactivityPolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteLimit provides a mock function with given fields: ctx, lim
 func (_m *DataStore) DeleteLimit(ctx context.Context, lim string) error {
 	ret := _m.Called(ctx, lim)
@@ -324,6 +512,36 @@ func (_m *DataStore) GetAuthSetByIdDataHashKeyByStatus(ctx context.Context, idDa
 	return r0, r1
 }
 
+// GetAuthSetsByIdDataHashes provides a mock function with given fields: ctx, idDataHashes
+func (_m *DataStore) GetAuthSetsByIdDataHashes(ctx context.Context, idDataHashes [][]byte) ([]model.AuthSet, error) {
+	ret := _m.Called(ctx, idDataHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuthSetsByIdDataHashes")
+	}
+
+	var r0 []model.AuthSet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, [][]byte) ([]model.AuthSet, error)); ok {
+		return rf(ctx, idDataHashes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, [][]byte) []model.AuthSet); ok {
+		r0 = rf(ctx, idDataHashes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AuthSet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, [][]byte) error); ok {
+		r1 = rf(ctx, idDataHashes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAuthSetsForDevice provides a mock function with given fields: ctx, devid
 func (_m *DataStore) GetAuthSetsForDevice(ctx context.Context, devid string) ([]model.AuthSet, error) {
 	ret := _m.Called(ctx, devid)
@@ -354,6 +572,156 @@ func (_m *DataStore) GetAuthSetsForDevice(ctx context.Context, devid string) ([]
 	return r0, r1
 }
 
+// GetAutoAcceptAudit provides a mock function with given fields: ctx, filter, skip, limit
+func (_m *DataStore) GetAutoAcceptAudit(ctx context.Context, filter model.AutoAcceptAuditFilter, skip uint, limit uint) ([]model.AutoAcceptAudit, error) {
+	ret := _m.Called(ctx, filter, skip, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAutoAcceptAudit")
+	}
+
+	var r0 []model.AutoAcceptAudit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.AutoAcceptAuditFilter, uint, uint) ([]model.AutoAcceptAudit, error)); ok {
+		return rf(ctx, filter, skip, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.AutoAcceptAuditFilter, uint, uint) []model.AutoAcceptAudit); ok {
+		r0 = rf(ctx, filter, skip, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AutoAcceptAudit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.AutoAcceptAuditFilter, uint, uint) error); ok {
+		r1 = rf(ctx, filter, skip, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAutoAcceptRule provides a mock function with given fields: ctx, id
+func (_m *DataStore) GetAutoAcceptRule(ctx context.Context, id string) (*model.AutoAcceptRule, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAutoAcceptRule")
+	}
+
+	var r0 *model.AutoAcceptRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.AutoAcceptRule, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.AutoAcceptRule); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AutoAcceptRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAutoAcceptRules provides a mock function with given fields: ctx
+func (_m *DataStore) GetAutoAcceptRules(ctx context.Context) ([]model.AutoAcceptRule, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAutoAcceptRules")
+	}
+
+	var r0 []model.AutoAcceptRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.AutoAcceptRule, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.AutoAcceptRule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AutoAcceptRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCACertificate provides a mock function with given fields: ctx, id
+func (_m *DataStore) GetCACertificate(ctx context.Context, id string) (*model.CACertificate, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCACertificate")
+	}
+
+	var r0 *model.CACertificate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.CACertificate, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.CACertificate); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CACertificate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCACertificates provides a mock function with given fields: ctx
+func (_m *DataStore) GetCACertificates(ctx context.Context) ([]model.CACertificate, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCACertificates")
+	}
+
+	var r0 []model.CACertificate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.CACertificate, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.CACertificate); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.CACertificate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDevCountByStatus provides a mock function with given fields: ctx, status
 func (_m *DataStore) GetDevCountByStatus(ctx context.Context, status string) (int, error) {
 	ret := _m.Called(ctx, status)
@@ -500,6 +868,126 @@ func (_m *DataStore) GetDevices(ctx context.Context, skip uint, limit uint, filt
 	return r0, r1
 }
 
+// GetDevicesByIdentityDataHashes provides a mock function with given fields: ctx, idataHashes
+func (_m *DataStore) GetDevicesByIdentityDataHashes(ctx context.Context, idataHashes [][]byte) ([]model.Device, error) {
+	ret := _m.Called(ctx, idataHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDevicesByIdentityDataHashes")
+	}
+
+	var r0 []model.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, [][]byte) ([]model.Device, error)); ok {
+		return rf(ctx, idataHashes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, [][]byte) []model.Device); ok {
+		r0 = rf(ctx, idataHashes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, [][]byte) error); ok {
+		r1 = rf(ctx, idataHashes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInactiveDevices provides a mock function with given fields: ctx, since, afterID, limit
+func (_m *DataStore) GetInactiveDevices(ctx context.Context, since time.Time, afterID string, limit uint) ([]model.Device, error) {
+	ret := _m.Called(ctx, since, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInactiveDevices")
+	}
+
+	var r0 []model.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, string, uint) ([]model.Device, error)); ok {
+		return rf(ctx, since, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, string, uint) []model.Device); ok {
+		r0 = rf(ctx, since, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, string, uint) error); ok {
+		r1 = rf(ctx, since, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInactivityNotifiedDevices provides a mock function with given fields: ctx, afterID, limit
+func (_m *DataStore) GetInactivityNotifiedDevices(ctx context.Context, afterID string, limit uint) ([]model.Device, error) {
+	ret := _m.Called(ctx, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInactivityNotifiedDevices")
+	}
+
+	var r0 []model.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) ([]model.Device, error)); ok {
+		return rf(ctx, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) []model.Device); ok {
+		r0 = rf(ctx, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint) error); ok {
+		r1 = rf(ctx, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInactivityPolicy provides a mock function with given fields: ctx
+func (_m *DataStore) GetInactivityPolicy(ctx context.Context) (*model.InactivityPolicy, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInactivityPolicy")
+	}
+
+	var r0 *model.InactivityPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.InactivityPolicy, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.InactivityPolicy); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.InactivityPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLimit provides a mock function with given fields: ctx, name
 func (_m *DataStore) GetLimit(ctx context.Context, name string) (*model.Limit, error) {
 	ret := _m.Called(ctx, name)
@@ -662,6 +1150,78 @@ func (_m *DataStore) RejectAuthSetsForDevice(ctx context.Context, deviceID strin
 	return r0
 }
 
+// ReleaseAutoAcceptRule provides a mock function with given fields: ctx, id
+func (_m *DataStore) ReleaseAutoAcceptRule(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseAutoAcceptRule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetCACertificateCRL provides a mock function with given fields: ctx, id, crl
+func (_m *DataStore) SetCACertificateCRL(ctx context.Context, id string, crl model.CertRevocationList) error {
+	ret := _m.Called(ctx, id, crl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCACertificateCRL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.CertRevocationList) error); ok {
+		r0 = rf(ctx, id, crl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetDeviceInactivityNotified provides a mock function with given fields: ctx, deviceID, ts
+func (_m *DataStore) SetDeviceInactivityNotified(ctx context.Context, deviceID string, ts *time.Time) error {
+	ret := _m.Called(ctx, deviceID, ts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDeviceInactivityNotified")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *time.Time) error); ok {
+		r0 = rf(ctx, deviceID, ts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetInactivityPolicy provides a mock function with given fields: ctx, policy
+func (_m *DataStore) SetInactivityPolicy(ctx context.Context, policy model.InactivityPolicy) error {
+	ret := _m.Called(ctx, policy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetInactivityPolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.InactivityPolicy) error); ok {
+		r0 = rf(ctx, policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // StoreMigrationVersion provides a mock function with given fields: ctx, version
 func (_m *DataStore) StoreMigrationVersion(ctx context.Context, version *migrate.Version) error {
 	ret := _m.Called(ctx, version)
@@ -38,12 +38,18 @@ import (
 )
 
 const (
-	DbVersion     = "2.0.0"
+	DbVersion     = "2.3.0"
 	DbName        = "deviceauth"
 	DbDevicesColl = "devices"
 	DbAuthSetColl = "auth_sets"
 	DbTokensColl  = "tokens"
 	DbLimitsColl  = "limits"
+	DbCACertsColl = "ca_certificates"
+
+	DbAutoAcceptRulesColl = "auto_accept_rules"
+	DbAutoAcceptAuditColl = "auto_accept_audit"
+
+	DbInactivityPolicyColl = "inactivity_policy"
 
 	DbKeyDeviceRevision = "revision"
 	dbFieldID           = "_id"
@@ -56,6 +62,17 @@ const (
 	dbFieldTenantClaim  = "mender.tenant"
 	dbFieldName         = "name"
 	dbFieldSubject      = "sub"
+	dbFieldFingerprint  = "fingerprint"
+	dbFieldCreatedTs    = "created_ts"
+	dbFieldCRL          = "crl"
+	dbFieldAccepted     = "accepted"
+	dbFieldMaxAccepts   = "max_accepts"
+	dbFieldRuleID       = "rule_id"
+	dbFieldTimestamp    = "ts"
+
+	dbFieldCheckInTime          = "check_in_time"
+	dbFieldDecommissioning      = "decommissioning"
+	dbFieldInactivityNotifiedTs = "inactivity_notified_ts"
 )
 
 var (
@@ -306,6 +323,74 @@ func (db *DataStoreMongo) GetDeviceByIdentityDataHash(
 	return &res, nil
 }
 
+func (db *DataStoreMongo) GetDevicesByIdentityDataHashes(
+	ctx context.Context,
+	idataHashes [][]byte,
+) ([]model.Device, error) {
+	c := db.client.Database(DbName).Collection(DbDevicesColl)
+
+	filter := ctxstore.WithTenantID(ctx, bson.M{
+		dbFieldIDDataSha: bson.M{"$in": idataHashes},
+	})
+	cursor, err := c.Find(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch devices")
+	}
+	devices := []model.Device{}
+	if err = cursor.All(ctx, &devices); err != nil {
+		return nil, errors.Wrap(err, "failed to decode devices")
+	}
+	return devices, nil
+}
+
+// duplicateKeyIndexes returns the indexes of the documents of an unordered
+// insert which conflict with existing documents; the insert errors of any
+// other kind are returned as they are.
+func duplicateKeyIndexes(err error) ([]int, error) {
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || bwe.WriteConcernError != nil {
+		return nil, err
+	}
+	indexes := make([]int, 0, len(bwe.WriteErrors))
+	for _, we := range bwe.WriteErrors {
+		if !mongo.IsDuplicateKeyError(we.WriteError) {
+			return nil, err
+		}
+		indexes = append(indexes, we.Index)
+	}
+	return indexes, nil
+}
+
+func (db *DataStoreMongo) AddDevices(ctx context.Context, devs []model.Device) ([]int, error) {
+	if len(devs) == 0 {
+		return nil, nil
+	}
+	tenantId := ""
+	if id := identity.FromContext(ctx); id != nil {
+		tenantId = id.Tenant
+	}
+	docs := make([]interface{}, len(devs))
+	for i, d := range devs {
+		if d.Id == "" {
+			d.Id = oid.NewUUIDv4().String()
+		}
+		d.TenantID = tenantId
+		docs[i] = d
+	}
+
+	c := db.client.Database(DbName).Collection(DbDevicesColl)
+
+	_, err := c.InsertMany(ctx, docs, mopts.InsertMany().SetOrdered(false))
+	if err != nil {
+		conflicts, err := duplicateKeyIndexes(err)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to store devices")
+		}
+		return conflicts, nil
+	}
+	return nil, nil
+}
+
 func (db *DataStoreMongo) AddDevice(ctx context.Context, d model.Device) error {
 
 	if d.Id == "" {
@@ -565,6 +650,18 @@ func (db *DataStoreMongo) MigrateTenant(ctx context.Context, database, version s
 			ds:  db,
 			ctx: ctx,
 		},
+		&migration_2_1_0{
+			ds:  db,
+			ctx: ctx,
+		},
+		&migration_2_2_0{
+			ds:  db,
+			ctx: ctx,
+		},
+		&migration_2_3_0{
+			ds:  db,
+			ctx: ctx,
+		},
 	}
 
 	ver, err := migrate.NewVersion(version)
@@ -611,6 +708,29 @@ func (db *DataStoreMongo) AddAuthSet(ctx context.Context, set model.AuthSet) err
 	return nil
 }
 
+func (db *DataStoreMongo) AddAuthSets(ctx context.Context, sets []model.AuthSet) ([]int, error) {
+	if len(sets) == 0 {
+		return nil, nil
+	}
+	docs := make([]interface{}, len(sets))
+	for i, set := range sets {
+		fillAuthSet(ctx, &set)
+		docs[i] = set
+	}
+
+	c := db.client.Database(DbName).Collection(DbAuthSetColl)
+
+	_, err := c.InsertMany(ctx, docs, mopts.InsertMany().SetOrdered(false))
+	if err != nil {
+		conflicts, err := duplicateKeyIndexes(err)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to store auth sets")
+		}
+		return conflicts, nil
+	}
+	return nil, nil
+}
+
 // UpsertAuthSetStatus inserts a new auth set and if it exists, ensures the
 // AuthSet.Status matches the one in authSet.
 func (db *DataStoreMongo) UpsertAuthSetStatus(ctx context.Context, authSet *model.AuthSet) error {
@@ -675,6 +795,26 @@ func (db *DataStoreMongo) GetAuthSetByIdDataHashKey(
 	return &res, nil
 }
 
+func (db *DataStoreMongo) GetAuthSetsByIdDataHashes(
+	ctx context.Context,
+	idDataHashes [][]byte,
+) ([]model.AuthSet, error) {
+	c := db.client.Database(DbName).Collection(DbAuthSetColl)
+
+	filter := ctxstore.WithTenantID(ctx, bson.M{
+		dbFieldIDDataSha: bson.M{"$in": idDataHashes},
+	})
+	cursor, err := c.Find(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch authentication sets")
+	}
+	sets := []model.AuthSet{}
+	if err = cursor.All(ctx, &sets); err != nil {
+		return nil, errors.Wrap(err, "failed to decode authentication sets")
+	}
+	return sets, nil
+}
+
 func (db *DataStoreMongo) GetAuthSetByIdDataHashKeyByStatus(
 	ctx context.Context,
 	idDataHash []byte,
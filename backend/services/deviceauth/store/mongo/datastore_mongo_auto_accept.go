@@ -0,0 +1,179 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	ctxstore "github.com/mendersoftware/mender-server/pkg/store/v2"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+func (db *DataStoreMongo) AddAutoAcceptRule(ctx context.Context, rule model.AutoAcceptRule) error {
+	c := db.client.Database(DbName).Collection(DbAutoAcceptRulesColl)
+
+	rule.TenantID = ""
+	if id := identity.FromContext(ctx); id != nil {
+		rule.TenantID = id.Tenant
+	}
+
+	if _, err := c.InsertOne(ctx, rule); err != nil {
+		return errors.Wrap(err, "failed to store auto-accept rule")
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) GetAutoAcceptRules(ctx context.Context) ([]model.AutoAcceptRule, error) {
+	c := db.client.Database(DbName).Collection(DbAutoAcceptRulesColl)
+
+	opts := mopts.Find().
+		SetSort(bson.D{{Key: dbFieldCreatedTs, Value: 1}})
+	cur, err := c.Find(ctx, ctxstore.WithTenantID(ctx, bson.D{}), opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch auto-accept rules")
+	}
+
+	rules := []model.AutoAcceptRule{}
+	if err := cur.All(ctx, &rules); err != nil {
+		return nil, errors.Wrap(err, "failed to decode auto-accept rules")
+	}
+	return rules, nil
+}
+
+func (db *DataStoreMongo) GetAutoAcceptRule(
+	ctx context.Context,
+	id string,
+) (*model.AutoAcceptRule, error) {
+	c := db.client.Database(DbName).Collection(DbAutoAcceptRulesColl)
+
+	var rule model.AutoAcceptRule
+	err := c.FindOne(ctx, ctxstore.WithTenantID(ctx, bson.M{dbFieldID: id})).
+		Decode(&rule)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, store.ErrAutoAcceptRuleNotFound
+		}
+		return nil, errors.Wrap(err, "failed to fetch auto-accept rule")
+	}
+	return &rule, nil
+}
+
+func (db *DataStoreMongo) DeleteAutoAcceptRule(ctx context.Context, id string) error {
+	c := db.client.Database(DbName).Collection(DbAutoAcceptRulesColl)
+
+	res, err := c.DeleteOne(ctx, ctxstore.WithTenantID(ctx, bson.M{dbFieldID: id}))
+	if err != nil {
+		return errors.Wrap(err, "failed to remove auto-accept rule")
+	} else if res.DeletedCount < 1 {
+		return store.ErrAutoAcceptRuleNotFound
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) ConsumeAutoAcceptRule(ctx context.Context, id string) error {
+	c := db.client.Database(DbName).Collection(DbAutoAcceptRulesColl)
+
+	// the budget is checked and taken in a single update, so concurrent
+	// requests never exceed it
+	res, err := c.UpdateOne(ctx,
+		ctxstore.WithTenantID(ctx, bson.M{
+			dbFieldID: id,
+			"$expr": bson.M{
+				"$lt": bson.A{"$" + dbFieldAccepted, "$" + dbFieldMaxAccepts},
+			},
+		}),
+		bson.M{"$inc": bson.M{dbFieldAccepted: 1}},
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update auto-accept rule")
+	} else if res.MatchedCount < 1 {
+		return store.ErrAutoAcceptRuleExhausted
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) ReleaseAutoAcceptRule(ctx context.Context, id string) error {
+	c := db.client.Database(DbName).Collection(DbAutoAcceptRulesColl)
+
+	_, err := c.UpdateOne(ctx,
+		ctxstore.WithTenantID(ctx, bson.M{
+			dbFieldID:       id,
+			dbFieldAccepted: bson.M{"$gt": 0},
+		}),
+		bson.M{"$inc": bson.M{dbFieldAccepted: -1}},
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update auto-accept rule")
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) AddAutoAcceptAudit(
+	ctx context.Context,
+	audit model.AutoAcceptAudit,
+) error {
+	c := db.client.Database(DbName).Collection(DbAutoAcceptAuditColl)
+
+	audit.TenantID = ""
+	if id := identity.FromContext(ctx); id != nil {
+		audit.TenantID = id.Tenant
+	}
+
+	if _, err := c.InsertOne(ctx, audit); err != nil {
+		return errors.Wrap(err, "failed to store auto-accept audit entry")
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) GetAutoAcceptAudit(
+	ctx context.Context,
+	filter model.AutoAcceptAuditFilter,
+	skip,
+	limit uint,
+) ([]model.AutoAcceptAudit, error) {
+	c := db.client.Database(DbName).Collection(DbAutoAcceptAuditColl)
+
+	fltr := bson.D{}
+	if filter.RuleId != "" {
+		fltr = append(fltr, bson.E{Key: dbFieldRuleID, Value: filter.RuleId})
+	}
+	if filter.DeviceId != "" {
+		fltr = append(fltr, bson.E{Key: dbFieldDeviceID, Value: filter.DeviceId})
+	}
+	opts := mopts.Find().
+		SetSort(bson.D{{Key: dbFieldTimestamp, Value: -1}}).
+		SetSkip(int64(skip))
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cur, err := c.Find(ctx, ctxstore.WithTenantID(ctx, fltr), opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch auto-accept audit")
+	}
+
+	entries := []model.AutoAcceptAudit{}
+	if err := cur.All(ctx, &entries); err != nil {
+		return nil, errors.Wrap(err, "failed to decode auto-accept audit")
+	}
+	return entries, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+func TestStoreAutoAcceptRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestStoreAutoAcceptRules in short mode.")
+	}
+
+	ctx := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: tenant,
+	})
+	ctxOther := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: "other-" + tenant,
+	})
+	db := getDb(ctx)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	rule1 := model.AutoAcceptRule{
+		Id:          "9d1f4c2e-6b1a-4d3e-8f2a-1c0b5e3a7d01",
+		Name:        "factory",
+		Identity:    []model.AutoAcceptIdentityMatch{{Key: "mac", Regex: "^00:11:"}},
+		SourceCIDRs: []string{"10.0.0.0/8"},
+		TenantToken: model.TenantTokenAny,
+		MaxAccepts:  1,
+		CreatedTs:   now,
+	}
+	rule2 := rule1
+	rule2.Id = "9d1f4c2e-6b1a-4d3e-8f2a-1c0b5e3a7d02"
+	rule2.MaxAccepts = 10
+	rule2.CreatedTs = now.Add(time.Second)
+
+	assert.NoError(t, db.AddAutoAcceptRule(ctx, rule1))
+	assert.NoError(t, db.AddAutoAcceptRule(ctx, rule2))
+
+	rules, err := db.GetAutoAcceptRules(ctx)
+	assert.NoError(t, err)
+	rule1.TenantID = tenant
+	rule2.TenantID = tenant
+	assert.Equal(t, []model.AutoAcceptRule{rule1, rule2}, rules)
+
+	rules, err = db.GetAutoAcceptRules(ctxOther)
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	// the budget of the rule is consumed atomically
+	assert.NoError(t, db.ConsumeAutoAcceptRule(ctx, rule1.Id))
+	assert.Equal(t, store.ErrAutoAcceptRuleExhausted,
+		db.ConsumeAutoAcceptRule(ctx, rule1.Id))
+	assert.Equal(t, store.ErrAutoAcceptRuleExhausted,
+		db.ConsumeAutoAcceptRule(ctxOther, rule2.Id))
+	rule, err := db.GetAutoAcceptRule(ctx, rule1.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), rule.Accepted)
+
+	assert.NoError(t, db.ReleaseAutoAcceptRule(ctx, rule1.Id))
+	rule, err = db.GetAutoAcceptRule(ctx, rule1.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), rule.Accepted)
+	// the accept count does not go below zero
+	assert.NoError(t, db.ReleaseAutoAcceptRule(ctx, rule1.Id))
+	rule, err = db.GetAutoAcceptRule(ctx, rule1.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), rule.Accepted)
+
+	_, err = db.GetAutoAcceptRule(ctxOther, rule1.Id)
+	assert.Equal(t, store.ErrAutoAcceptRuleNotFound, err)
+
+	assert.Equal(t, store.ErrAutoAcceptRuleNotFound,
+		db.DeleteAutoAcceptRule(ctxOther, rule1.Id))
+	assert.NoError(t, db.DeleteAutoAcceptRule(ctx, rule1.Id))
+	assert.Equal(t, store.ErrAutoAcceptRuleNotFound,
+		db.DeleteAutoAcceptRule(ctx, rule1.Id))
+}
+
+func TestStoreAutoAcceptAudit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestStoreAutoAcceptAudit in short mode.")
+	}
+
+	ctx := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: tenant,
+	})
+	ctxOther := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: "other-" + tenant,
+	})
+	db := getDb(ctx)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	entries := make([]model.AutoAcceptAudit, 3)
+	for i := range entries {
+		entries[i] = model.AutoAcceptAudit{
+			Id:           "6a2e7b1c-3d4f-4a5b-9c8d-7e6f5a4b3c0" + string(rune('1'+i)),
+			RuleId:       "rule-1",
+			DeviceId:     "device-" + string(rune('1'+i)),
+			AuthSetId:    "authset",
+			IdentityData: map[string]interface{}{"mac": "00:11:22:33:44:55"},
+			SourceIP:     "10.1.2.3",
+			TenantToken:  model.TenantTokenSupplied,
+			Timestamp:    now.Add(time.Duration(i) * time.Second),
+		}
+		if i == 2 {
+			entries[i].RuleId = "rule-2"
+		}
+		assert.NoError(t, db.AddAutoAcceptAudit(ctx, entries[i]))
+		entries[i].TenantID = tenant
+	}
+
+	res, err := db.GetAutoAcceptAudit(ctx, model.AutoAcceptAuditFilter{}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.AutoAcceptAudit{entries[2], entries[1], entries[0]}, res)
+
+	res, err = db.GetAutoAcceptAudit(ctx,
+		model.AutoAcceptAuditFilter{RuleId: "rule-1"}, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.AutoAcceptAudit{entries[0]}, res)
+
+	res, err = db.GetAutoAcceptAudit(ctx,
+		model.AutoAcceptAuditFilter{DeviceId: "device-3"}, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.AutoAcceptAudit{entries[2]}, res)
+
+	res, err = db.GetAutoAcceptAudit(ctxOther, model.AutoAcceptAuditFilter{}, 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	ctxstore "github.com/mendersoftware/mender-server/pkg/store/v2"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+func (db *DataStoreMongo) AddCACertificate(ctx context.Context, cert model.CACertificate) error {
+	c := db.client.Database(DbName).Collection(DbCACertsColl)
+
+	cert.TenantID = ""
+	if id := identity.FromContext(ctx); id != nil {
+		cert.TenantID = id.Tenant
+	}
+
+	if _, err := c.InsertOne(ctx, cert); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return store.ErrObjectExists
+		}
+		return errors.Wrap(err, "failed to store CA certificate")
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) GetCACertificates(ctx context.Context) ([]model.CACertificate, error) {
+	c := db.client.Database(DbName).Collection(DbCACertsColl)
+
+	opts := mopts.Find().
+		SetSort(bson.D{{Key: dbFieldCreatedTs, Value: 1}})
+	cur, err := c.Find(ctx, ctxstore.WithTenantID(ctx, bson.D{}), opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch CA certificates")
+	}
+
+	certs := []model.CACertificate{}
+	if err := cur.All(ctx, &certs); err != nil {
+		return nil, errors.Wrap(err, "failed to decode CA certificates")
+	}
+	return certs, nil
+}
+
+func (db *DataStoreMongo) GetCACertificate(
+	ctx context.Context,
+	id string,
+) (*model.CACertificate, error) {
+	c := db.client.Database(DbName).Collection(DbCACertsColl)
+
+	var cert model.CACertificate
+	err := c.FindOne(ctx, ctxstore.WithTenantID(ctx, bson.M{dbFieldID: id})).
+		Decode(&cert)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, store.ErrCACertificateNotFound
+		}
+		return nil, errors.Wrap(err, "failed to fetch CA certificate")
+	}
+	return &cert, nil
+}
+
+func (db *DataStoreMongo) DeleteCACertificate(ctx context.Context, id string) error {
+	c := db.client.Database(DbName).Collection(DbCACertsColl)
+
+	res, err := c.DeleteOne(ctx, ctxstore.WithTenantID(ctx, bson.M{dbFieldID: id}))
+	if err != nil {
+		return errors.Wrap(err, "failed to remove CA certificate")
+	} else if res.DeletedCount < 1 {
+		return store.ErrCACertificateNotFound
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) SetCACertificateCRL(
+	ctx context.Context,
+	id string,
+	crl model.CertRevocationList,
+) error {
+	c := db.client.Database(DbName).Collection(DbCACertsColl)
+
+	res, err := c.UpdateOne(ctx,
+		ctxstore.WithTenantID(ctx, bson.M{dbFieldID: id}),
+		bson.M{"$set": bson.M{dbFieldCRL: crl}},
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update CA certificate")
+	} else if res.MatchedCount < 1 {
+		return store.ErrCACertificateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+func TestStoreCACertificates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestStoreCACertificates in short mode.")
+	}
+
+	ctx := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: tenant,
+	})
+	ctxOther := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: "other-" + tenant,
+	})
+	db := getDb(ctx)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	cert1 := model.CACertificate{
+		Id:             "f6c9d4f5-1c9e-4e4b-9a3c-1f0e5c3a7e01",
+		Name:           "factory",
+		Certificate:    "cert1",
+		Subject:        "CN=ca1",
+		Fingerprint:    "fingerprint1",
+		IdentityFields: model.DefaultCertIdentityFields,
+		CreatedTs:      now,
+	}
+	cert2 := cert1
+	cert2.Id = "f6c9d4f5-1c9e-4e4b-9a3c-1f0e5c3a7e02"
+	cert2.Fingerprint = "fingerprint2"
+	cert2.CreatedTs = now.Add(time.Second)
+
+	assert.NoError(t, db.AddCACertificate(ctx, cert1))
+	assert.NoError(t, db.AddCACertificate(ctx, cert2))
+	// the same certificate can be registered by another tenant
+	otherCert := cert1
+	otherCert.Id = "f6c9d4f5-1c9e-4e4b-9a3c-1f0e5c3a7e03"
+	assert.NoError(t, db.AddCACertificate(ctxOther, otherCert))
+
+	// duplicate fingerprint
+	dup := cert1
+	dup.Id = "f6c9d4f5-1c9e-4e4b-9a3c-1f0e5c3a7e04"
+	assert.Equal(t, store.ErrObjectExists, db.AddCACertificate(ctx, dup))
+
+	certs, err := db.GetCACertificates(ctx)
+	assert.NoError(t, err)
+	cert1.TenantID = tenant
+	cert2.TenantID = tenant
+	assert.Equal(t, []model.CACertificate{cert1, cert2}, certs)
+
+	cert, err := db.GetCACertificate(ctx, cert2.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, cert2, *cert)
+
+	_, err = db.GetCACertificate(ctxOther, cert2.Id)
+	assert.Equal(t, store.ErrCACertificateNotFound, err)
+
+	crl := model.CertRevocationList{
+		RevokedSerials: []string{"2", "ff"},
+		Revoked:        2,
+		ThisUpdate:     now,
+		NextUpdate:     now.Add(time.Hour),
+	}
+	assert.NoError(t, db.SetCACertificateCRL(ctx, cert1.Id, crl))
+	cert, err = db.GetCACertificate(ctx, cert1.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, &crl, cert.CRL)
+	assert.Equal(t, store.ErrCACertificateNotFound,
+		db.SetCACertificateCRL(ctxOther, cert1.Id, crl))
+
+	assert.Equal(t, store.ErrCACertificateNotFound,
+		db.DeleteCACertificate(ctxOther, cert1.Id))
+	assert.NoError(t, db.DeleteCACertificate(ctx, cert1.Id))
+	assert.Equal(t, store.ErrCACertificateNotFound,
+		db.DeleteCACertificate(ctx, cert1.Id))
+
+	certs, err = db.GetCACertificates(ctxOther)
+	assert.NoError(t, err)
+	assert.Len(t, certs, 1)
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+	ctxstore "github.com/mendersoftware/mender-server/pkg/store/v2"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+func (db *DataStoreMongo) GetInactivityPolicy(
+	ctx context.Context,
+) (*model.InactivityPolicy, error) {
+	c := db.client.Database(DbName).Collection(DbInactivityPolicyColl)
+
+	var policy model.InactivityPolicy
+	err := c.FindOne(ctx, ctxstore.WithTenantID(ctx, bson.D{})).
+		Decode(&policy)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, store.ErrInactivityPolicyNotFound
+		}
+		return nil, errors.Wrap(err, "failed to fetch inactivity policy")
+	}
+	return &policy, nil
+}
+
+func (db *DataStoreMongo) SetInactivityPolicy(
+	ctx context.Context,
+	policy model.InactivityPolicy,
+) error {
+	c := db.client.Database(DbName).Collection(DbInactivityPolicyColl)
+
+	policy.TenantID = ""
+	if id := identity.FromContext(ctx); id != nil {
+		policy.TenantID = id.Tenant
+	}
+
+	_, err := c.ReplaceOne(ctx,
+		ctxstore.WithTenantID(ctx, bson.D{}),
+		policy,
+		mopts.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to store inactivity policy")
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) DeleteInactivityPolicy(ctx context.Context) error {
+	c := db.client.Database(DbName).Collection(DbInactivityPolicyColl)
+
+	res, err := c.DeleteOne(ctx, ctxstore.WithTenantID(ctx, bson.D{}))
+	if err != nil {
+		return errors.Wrap(err, "failed to remove inactivity policy")
+	} else if res.DeletedCount < 1 {
+		return store.ErrInactivityPolicyNotFound
+	}
+	return nil
+}
+
+func (db *DataStoreMongo) GetInactiveDevices(
+	ctx context.Context,
+	since time.Time,
+	afterID string,
+	limit uint,
+) ([]model.Device, error) {
+	return db.findAcceptedDevices(ctx, bson.M{
+		"$or": bson.A{
+			bson.M{dbFieldCheckInTime: bson.M{"$lt": since}},
+			bson.M{
+				dbFieldCheckInTime: bson.M{"$exists": false},
+				dbFieldCreatedTs:   bson.M{"$lt": since},
+			},
+		},
+	}, afterID, limit)
+}
+
+func (db *DataStoreMongo) GetInactivityNotifiedDevices(
+	ctx context.Context,
+	afterID string,
+	limit uint,
+) ([]model.Device, error) {
+	return db.findAcceptedDevices(ctx, bson.M{
+		dbFieldInactivityNotifiedTs: bson.M{"$exists": true},
+	}, afterID, limit)
+}
+
+// findAcceptedDevices lists the accepted devices, which are not being
+// decommissioned, matching the filter; the devices are listed by id so the
+// listing can continue while the previous devices are being updated.
+func (db *DataStoreMongo) findAcceptedDevices(
+	ctx context.Context,
+	filter bson.M,
+	afterID string,
+	limit uint,
+) ([]model.Device, error) {
+	c := db.client.Database(DbName).Collection(DbDevicesColl)
+
+	filter[dbFieldID] = bson.M{"$gt": afterID}
+	filter[dbFieldStatus] = model.DevStatusAccepted
+	filter[dbFieldDecommissioning] = bson.M{"$ne": true}
+
+	opts := mopts.Find().
+		SetSort(bson.D{{Key: dbFieldID, Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cur, err := c.Find(ctx, ctxstore.WithTenantID(ctx, filter), opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch device list")
+	}
+
+	devices := []model.Device{}
+	if err := cur.All(ctx, &devices); err != nil {
+		return nil, errors.Wrap(err, "failed to decode device list")
+	}
+	return devices, nil
+}
+
+func (db *DataStoreMongo) SetDeviceInactivityNotified(
+	ctx context.Context,
+	deviceID string,
+	ts *time.Time,
+) error {
+	c := db.client.Database(DbName).Collection(DbDevicesColl)
+
+	update := bson.M{"$unset": bson.M{dbFieldInactivityNotifiedTs: ""}}
+	if ts != nil {
+		update = bson.M{"$set": bson.M{dbFieldInactivityNotifiedTs: *ts}}
+	}
+	res, err := c.UpdateOne(ctx,
+		ctxstore.WithTenantID(ctx, bson.M{dbFieldID: deviceID}),
+		update,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update device")
+	} else if res.MatchedCount < 1 {
+		return store.ErrDevNotFound
+	}
+	return nil
+}
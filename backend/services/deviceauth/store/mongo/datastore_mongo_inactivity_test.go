@@ -0,0 +1,171 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mendersoftware/mender-server/pkg/identity"
+
+	"github.com/mendersoftware/mender-server/services/deviceauth/model"
+	"github.com/mendersoftware/mender-server/services/deviceauth/store"
+)
+
+func TestStoreInactivityPolicy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestStoreInactivityPolicy in short mode.")
+	}
+
+	ctx := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: tenant,
+	})
+	ctxOther := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: "other-" + tenant,
+	})
+	db := getDb(ctx)
+
+	_, err := db.GetInactivityPolicy(ctx)
+	assert.Equal(t, store.ErrInactivityPolicyNotFound, err)
+
+	policy := model.InactivityPolicy{
+		InactiveDays: 90,
+		GraceDays:    14,
+		Action:       model.InactivityActionReject,
+		UpdatedTs:    time.Now().UTC().Truncate(time.Millisecond),
+	}
+	assert.NoError(t, db.SetInactivityPolicy(ctx, policy))
+	// the policy is replaced
+	policy.Action = model.InactivityActionDecommission
+	assert.NoError(t, db.SetInactivityPolicy(ctx, policy))
+
+	res, err := db.GetInactivityPolicy(ctx)
+	assert.NoError(t, err)
+	policy.TenantID = tenant
+	assert.Equal(t, &policy, res)
+
+	_, err = db.GetInactivityPolicy(ctxOther)
+	assert.Equal(t, store.ErrInactivityPolicyNotFound, err)
+	assert.Equal(t, store.ErrInactivityPolicyNotFound, db.DeleteInactivityPolicy(ctxOther))
+
+	assert.NoError(t, db.DeleteInactivityPolicy(ctx))
+	assert.Equal(t, store.ErrInactivityPolicyNotFound, db.DeleteInactivityPolicy(ctx))
+}
+
+func TestStoreInactiveDevices(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestStoreInactiveDevices in short mode.")
+	}
+
+	ctx := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: tenant,
+	})
+	ctxOther := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: "other-" + tenant,
+	})
+	db := getDb(ctx)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	daysAgo := func(days int) *time.Time {
+		ts := now.AddDate(0, 0, -days)
+		return &ts
+	}
+	devices := []model.Device{{
+		// checked in recently
+		Id:          "device-1",
+		Status:      model.DevStatusAccepted,
+		CreatedTs:   *daysAgo(200),
+		CheckInTime: daysAgo(1),
+	}, {
+		// inactive
+		Id:          "device-2",
+		Status:      model.DevStatusAccepted,
+		CreatedTs:   *daysAgo(200),
+		CheckInTime: daysAgo(100),
+	}, {
+		// never checked in
+		Id:        "device-3",
+		Status:    model.DevStatusAccepted,
+		CreatedTs: *daysAgo(100),
+	}, {
+		// created recently
+		Id:        "device-4",
+		Status:    model.DevStatusAccepted,
+		CreatedTs: *daysAgo(1),
+	}, {
+		// rejected
+		Id:          "device-5",
+		Status:      model.DevStatusRejected,
+		CreatedTs:   *daysAgo(200),
+		CheckInTime: daysAgo(100),
+	}, {
+		// being decommissioned
+		Id:              "device-6",
+		Status:          model.DevStatusAccepted,
+		Decommissioning: true,
+		CreatedTs:       *daysAgo(200),
+		CheckInTime:     daysAgo(100),
+	}, {
+		// inactive
+		Id:          "device-7",
+		Status:      model.DevStatusAccepted,
+		CreatedTs:   *daysAgo(200),
+		CheckInTime: daysAgo(95),
+	}}
+	for i, dev := range devices {
+		dev.IdDataSha256 = []byte{byte(i)}
+		assert.NoError(t, db.AddDevice(ctx, dev))
+	}
+
+	deviceIDs := func(devs []model.Device) []string {
+		ids := []string{}
+		for _, dev := range devs {
+			ids = append(ids, dev.Id)
+		}
+		return ids
+	}
+
+	devs, err := db.GetInactiveDevices(ctx, *daysAgo(90), "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"device-2", "device-3", "device-7"}, deviceIDs(devs))
+
+	devs, err = db.GetInactiveDevices(ctx, *daysAgo(90), "device-2", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"device-3"}, deviceIDs(devs))
+
+	devs, err = db.GetInactiveDevices(ctxOther, *daysAgo(90), "", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, devs)
+
+	notified := now.AddDate(0, 0, -5)
+	assert.NoError(t, db.SetDeviceInactivityNotified(ctx, "device-2", &notified))
+	assert.NoError(t, db.SetDeviceInactivityNotified(ctx, "device-7", &notified))
+	assert.Equal(t, store.ErrDevNotFound,
+		db.SetDeviceInactivityNotified(ctxOther, "device-2", &notified))
+
+	devs, err = db.GetInactivityNotifiedDevices(ctx, "", 0)
+	assert.NoError(t, err)
+	if assert.Equal(t, []string{"device-2", "device-7"}, deviceIDs(devs)) {
+		assert.Equal(t, &notified, devs[0].InactivityNotifiedTs)
+	}
+
+	assert.NoError(t, db.SetDeviceInactivityNotified(ctx, "device-2", nil))
+	devs, err = db.GetInactivityNotifiedDevices(ctx, "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"device-7"}, deviceIDs(devs))
+}
@@ -449,6 +449,97 @@ func TestStoreAddDevice(t *testing.T) {
 	assert.EqualError(t, err, store.ErrObjectExists.Error())
 }
 
+func TestStoreAddDevicesAuthSets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping TestStoreAddDevicesAuthSets in short mode.")
+	}
+	time.Local = time.UTC
+
+	ctx := identity.WithContext(context.Background(), &identity.Identity{
+		Tenant: "batch",
+	})
+	d := getDb(ctx)
+	d.MigrateTenant(ctx, DbName, DbVersion)
+
+	err := d.AddDevice(ctx, model.Device{
+		Id:           "existing",
+		IdData:       "iddata-1",
+		IdDataSha256: getIdDataHash("iddata-1"),
+	})
+	assert.NoError(t, err)
+
+	// the devices with existing identity data are reported, the others
+	// are added
+	conflicts, err := d.AddDevices(ctx, []model.Device{{
+		IdData:       "iddata-1",
+		IdDataSha256: getIdDataHash("iddata-1"),
+	}, {
+		Id:           "2",
+		IdData:       "iddata-2",
+		IdDataSha256: getIdDataHash("iddata-2"),
+	}, {
+		Id:           "3",
+		IdData:       "iddata-3",
+		IdDataSha256: getIdDataHash("iddata-3"),
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0}, conflicts)
+
+	devs, err := d.GetDevicesByIdentityDataHashes(ctx, [][]byte{
+		getIdDataHash("iddata-1"),
+		getIdDataHash("iddata-3"),
+		getIdDataHash("iddata-4"),
+	})
+	assert.NoError(t, err)
+	ids := []string{}
+	for _, dev := range devs {
+		ids = append(ids, dev.Id)
+	}
+	assert.ElementsMatch(t, []string{"existing", "3"}, ids)
+
+	// no tenant
+	devs, err = d.GetDevicesByIdentityDataHashes(context.Background(),
+		[][]byte{getIdDataHash("iddata-1")})
+	assert.NoError(t, err)
+	assert.Empty(t, devs)
+
+	conflicts, err = d.AddAuthSets(ctx, []model.AuthSet{{
+		IdData:       "iddata-2",
+		IdDataSha256: getIdDataHash("iddata-2"),
+		PubKey:       "pubkey-2",
+		DeviceId:     "2",
+		Status:       model.DevStatusPreauth,
+	}, {
+		IdData:       "iddata-3",
+		IdDataSha256: getIdDataHash("iddata-3"),
+		PubKey:       "pubkey-3",
+		DeviceId:     "3",
+		Status:       model.DevStatusPreauth,
+	}})
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	conflicts, err = d.AddAuthSets(ctx, []model.AuthSet{{
+		IdData:       "iddata-3",
+		IdDataSha256: getIdDataHash("iddata-3"),
+		PubKey:       "pubkey-3",
+		DeviceId:     "3",
+		Status:       model.DevStatusPreauth,
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0}, conflicts)
+
+	sets, err := d.GetAuthSetsByIdDataHashes(ctx, [][]byte{
+		getIdDataHash("iddata-3"),
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, sets, 1) {
+		assert.Equal(t, "pubkey-3", sets[0].PubKey)
+		assert.Equal(t, "3", sets[0].DeviceId)
+		assert.NotEmpty(t, sets[0].Id)
+	}
+}
+
 func TestStoreUpdateDevice(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping TestUpdateDevice in short mode.")
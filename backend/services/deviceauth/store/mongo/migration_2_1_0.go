@@ -0,0 +1,65 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/mendersoftware/mender-server/pkg/mongo/migrate"
+	mstorev1 "github.com/mendersoftware/mender-server/pkg/store"
+	mstore "github.com/mendersoftware/mender-server/pkg/store/v2"
+)
+
+type migration_2_1_0 struct {
+	ds  *DataStoreMongo
+	ctx context.Context
+}
+
+var DbCACertsCollectionIndices = []mongo.IndexModel{
+	{
+		Keys: bson.D{
+			{Key: mstore.FieldTenantID, Value: 1},
+			{Key: dbFieldFingerprint, Value: 1},
+		},
+		Options: mopts.Index().
+			SetName(strings.Join([]string{
+				mstore.FieldTenantID,
+				dbFieldFingerprint,
+			}, "_")).
+			SetUnique(true),
+	},
+}
+
+// Up creates the unique index on the fingerprint of the tenant CA
+// certificates
+func (m *migration_2_1_0) Up(from migrate.Version) error {
+	if mstorev1.DbFromContext(m.ctx, DbName) != DbName {
+		return nil
+	}
+	_, err := m.ds.client.Database(DbName).
+		Collection(DbCACertsColl).
+		Indexes().
+		CreateMany(m.ctx, DbCACertsCollectionIndices)
+	return err
+}
+
+func (m *migration_2_1_0) Version() migrate.Version {
+	return migrate.MakeVersion(2, 1, 0)
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/mendersoftware/mender-server/pkg/mongo/migrate"
+	mstorev1 "github.com/mendersoftware/mender-server/pkg/store"
+	mstore "github.com/mendersoftware/mender-server/pkg/store/v2"
+)
+
+type migration_2_2_0 struct {
+	ds  *DataStoreMongo
+	ctx context.Context
+}
+
+var DbAutoAcceptRulesCollectionIndices = []mongo.IndexModel{
+	{
+		Keys: bson.D{
+			{Key: mstore.FieldTenantID, Value: 1},
+			{Key: dbFieldCreatedTs, Value: 1},
+		},
+		Options: mopts.Index().
+			SetName(strings.Join([]string{
+				mstore.FieldTenantID,
+				dbFieldCreatedTs,
+			}, "_")),
+	},
+}
+
+var DbAutoAcceptAuditCollectionIndices = []mongo.IndexModel{
+	{
+		Keys: bson.D{
+			{Key: mstore.FieldTenantID, Value: 1},
+			{Key: dbFieldRuleID, Value: 1},
+			{Key: dbFieldTimestamp, Value: -1},
+		},
+		Options: mopts.Index().
+			SetName(strings.Join([]string{
+				mstore.FieldTenantID,
+				dbFieldRuleID,
+				dbFieldTimestamp,
+			}, "_")),
+	},
+	{
+		Keys: bson.D{
+			{Key: mstore.FieldTenantID, Value: 1},
+			{Key: dbFieldDeviceID, Value: 1},
+			{Key: dbFieldTimestamp, Value: -1},
+		},
+		Options: mopts.Index().
+			SetName(strings.Join([]string{
+				mstore.FieldTenantID,
+				dbFieldDeviceID,
+				dbFieldTimestamp,
+			}, "_")),
+	},
+}
+
+// Up creates the indexes of the auto-accept rules and audit collections
+func (m *migration_2_2_0) Up(from migrate.Version) error {
+	if mstorev1.DbFromContext(m.ctx, DbName) != DbName {
+		return nil
+	}
+	database := m.ds.client.Database(DbName)
+	_, err := database.Collection(DbAutoAcceptRulesColl).
+		Indexes().
+		CreateMany(m.ctx, DbAutoAcceptRulesCollectionIndices)
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection(DbAutoAcceptAuditColl).
+		Indexes().
+		CreateMany(m.ctx, DbAutoAcceptAuditCollectionIndices)
+	return err
+}
+
+func (m *migration_2_2_0) Version() migrate.Version {
+	return migrate.MakeVersion(2, 2, 0)
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mongo
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/mendersoftware/mender-server/pkg/mongo/migrate"
+	mstorev1 "github.com/mendersoftware/mender-server/pkg/store"
+	mstore "github.com/mendersoftware/mender-server/pkg/store/v2"
+)
+
+type migration_2_3_0 struct {
+	ds  *DataStoreMongo
+	ctx context.Context
+}
+
+var DbInactivityPolicyCollectionIndices = []mongo.IndexModel{
+	{
+		Keys: bson.D{
+			{Key: mstore.FieldTenantID, Value: 1},
+		},
+		Options: mopts.Index().
+			SetName(mstore.FieldTenantID).
+			SetUnique(true),
+	},
+}
+
+// the devices notified of the action of the inactivity policy are few, the
+// index covers only them
+var DbDevicesInactivityNotifiedIndex = mongo.IndexModel{
+	Keys: bson.D{
+		{Key: mstore.FieldTenantID, Value: 1},
+		{Key: dbFieldInactivityNotifiedTs, Value: 1},
+	},
+	Options: mopts.Index().
+		SetName(strings.Join([]string{
+			mstore.FieldTenantID,
+			dbFieldInactivityNotifiedTs,
+		}, "_")).
+		SetPartialFilterExpression(bson.M{
+			dbFieldInactivityNotifiedTs: bson.M{"$exists": true},
+		}),
+}
+
+// Up creates the index of the inactivity policy collection and the index of
+// the devices notified of the action of the policy
+func (m *migration_2_3_0) Up(from migrate.Version) error {
+	if mstorev1.DbFromContext(m.ctx, DbName) != DbName {
+		return nil
+	}
+	database := m.ds.client.Database(DbName)
+	_, err := database.Collection(DbInactivityPolicyColl).
+		Indexes().
+		CreateMany(m.ctx, DbInactivityPolicyCollectionIndices)
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection(DbDevicesColl).
+		Indexes().
+		CreateOne(m.ctx, DbDevicesInactivityNotifiedIndex)
+	return err
+}
+
+func (m *migration_2_3_0) Version() migrate.Version {
+	return migrate.MakeVersion(2, 3, 0)
+}
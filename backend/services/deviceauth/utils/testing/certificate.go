@@ -0,0 +1,135 @@
+// Copyright 2023 Northern.tech AS
+//
+//	Licensed under the Apache License, Version 2.0 (the "License");
+//	you may not use this file except in compliance with the License.
+//	You may obtain a copy of the License at
+//
+//	    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	Unless required by applicable law or agreed to in writing, software
+//	distributed under the License is distributed on an "AS IS" BASIS,
+//	WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	See the License for the specific language governing permissions and
+//	limitations under the License.
+package testing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// NewCertificate issues a client certificate with the given subject common
+// name and serial number, signed by the parent; a nil parent creates a self
+// signed CA certificate.
+func NewCertificate(
+	t *testing.T,
+	cn string,
+	serial int64,
+	parent *x509.Certificate,
+	parentKey crypto.Signer,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	return newCertificate(t, cn, serial, parent == nil, parent, parentKey)
+}
+
+// NewIntermediateCertificate issues an intermediate CA certificate signed
+// by the parent.
+func NewIntermediateCertificate(
+	t *testing.T,
+	cn string,
+	serial int64,
+	parent *x509.Certificate,
+	parentKey crypto.Signer,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	return newCertificate(t, cn, serial, true, parent, parentKey)
+}
+
+func newCertificate(
+	t *testing.T,
+	cn string,
+	serial int64,
+	isCA bool,
+	parent *x509.Certificate,
+	parentKey crypto.Signer,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{cn + ".example.com"},
+	}
+	if isCA {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		tmpl.ExtKeyUsage = nil
+		tmpl.DNSNames = nil
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// NewRevocationList creates a DER encoded revocation list of the CA
+// revoking the serial numbers.
+func NewRevocationList(
+	t *testing.T,
+	ca *x509.Certificate,
+	caKey crypto.Signer,
+	serials ...int64,
+) []byte {
+	t.Helper()
+	tmpl := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Minute),
+		NextUpdate: time.Now().Add(time.Hour),
+	}
+	for _, serial := range serials {
+		tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries,
+			x509.RevocationListEntry{
+				SerialNumber:   big.NewInt(serial),
+				RevocationTime: time.Now().Add(-time.Minute),
+			})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, tmpl, ca, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// CertificatePEM encodes the certificates as a PEM chain.
+func CertificatePEM(certs ...*x509.Certificate) string {
+	var b strings.Builder
+	for _, cert := range certs {
+		_ = pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return b.String()
+}
@@ -16,6 +16,7 @@ package http
 import (
 	"context"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/mendersoftware/mender-server/pkg/identity"
+	"github.com/mendersoftware/mender-server/pkg/keys"
 	"github.com/mendersoftware/mender-server/pkg/rest.utils"
 
 	"github.com/mendersoftware/mender-server/services/useradm/authz"
@@ -159,6 +161,27 @@ func (u *UserAdmApiHandlers) HealthHandler(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// JWKSHandler publishes the public keys verifying the user tokens to the
+// API gateway and the other verifiers, ordered by the key id.
+func (u *UserAdmApiHandlers) JWKSHandler(c *gin.Context) {
+	keyIds := make([]int, 0, len(u.jwth))
+	for keyId := range u.jwth {
+		keyIds = append(keyIds, keyId)
+	}
+	sort.Ints(keyIds)
+
+	jwks := keys.JWKS{Keys: []keys.JWK{}}
+	for _, keyId := range keyIds {
+		set, err := u.jwth[keyId].JWKS()
+		if err != nil {
+			rest.RenderInternalError(c, errors.Wrapf(err, "key id %d", keyId))
+			return
+		}
+		jwks.Keys = append(jwks.Keys, set.Keys...)
+	}
+	c.JSON(http.StatusOK, jwks)
+}
+
 func (u *UserAdmApiHandlers) AuthLoginHandler(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -32,6 +32,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/mendersoftware/mender-server/pkg/keys"
 	"github.com/mendersoftware/mender-server/pkg/mongo/oid"
 	"github.com/mendersoftware/mender-server/pkg/requestid"
 	"github.com/mendersoftware/mender-server/pkg/rest.utils"
@@ -71,6 +72,23 @@ func TestAlive(t *testing.T) {
 	mt.CheckHTTPResponse(t, checker, recorded)
 }
 
+func TestJWKS(t *testing.T) {
+	api := makeMockApiHandler(t, nil, nil)
+	req, _ := http.NewRequest("GET",
+		"http://localhost/api/internal/v1/useradm/.well-known/jwks.json", nil)
+	recorded := RunRequest(t, api, req)
+	assert.Equal(t, http.StatusOK, recorded.Recorder.Code)
+
+	var jwks keys.JWKS
+	err := json.Unmarshal(recorded.Recorder.Body.Bytes(), &jwks)
+	if assert.NoError(t, err) && assert.Len(t, jwks.Keys, 1) {
+		assert.Equal(t, "0", jwks.Keys[0].KeyID)
+		assert.Equal(t, keys.JWKKeyTypeRSA, jwks.Keys[0].KeyType)
+		assert.Equal(t, keys.JWKAlgRS256, jwks.Keys[0].Algorithm)
+		assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	}
+}
+
 func TestHealthCheck(t *testing.T) {
 	testCases := []struct {
 		Name string
@@ -25,6 +25,7 @@ const (
 	apiUrlInternalV1  = "/api/internal/v1/useradm"
 	uriInternalAlive  = "/alive"
 	uriInternalHealth = "/health"
+	uriInternalJWKS   = "/.well-known/jwks.json"
 
 	uriInternalAuthVerify  = "/auth/verify"
 	uriInternalTenants     = "/tenants"
@@ -69,6 +70,7 @@ func MakeRouter(i *UserAdmApiHandlers) http.Handler {
 
 	internal.GET(uriInternalAlive, i.AliveHandler)
 	internal.GET(uriInternalHealth, i.HealthHandler)
+	internal.GET(uriInternalJWKS, i.JWKSHandler)
 
 	internal.GET(uriInternalAuthVerify, identity.Middleware(),
 		i.AuthVerifyHandler)
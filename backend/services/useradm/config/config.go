@@ -30,6 +30,10 @@ const (
 	SettingServerPrivKeyFileNamePattern        = "server_priv_key_filename_pattern"
 	SettingServerPrivKeyFileNamePatternDefault = "private\\.id\\.([0-9]*)\\.pem"
 
+	// SettingServerRetiredKeyIds are the ids of the keys no longer
+	// verifying the tokens nor published to the verifiers.
+	SettingServerRetiredKeyIds = "server_retired_key_ids"
+
 	SettingServerFallbackPrivKeyPath        = "server_fallback_priv_key_path"
 	SettingServerFallbackPrivKeyPathDefault = ""
 
@@ -87,6 +91,7 @@ var (
 		{Key: SettingServerPrivKeyPath, Value: SettingServerPrivKeyPathDefault},
 		{Key: SettingServerPrivKeyFileNamePattern,
 			Value: SettingServerPrivKeyFileNamePatternDefault},
+		{Key: SettingServerRetiredKeyIds, Value: []string{}},
 		{Key: SettingServerFallbackPrivKeyPath, Value: SettingServerFallbackPrivKeyPathDefault},
 		{Key: SettingJWTIssuer, Value: SettingJWTIssuerDefault},
 		{Key: SettingJWTExpirationTimeout, Value: SettingJWTExpirationTimeoutDefault},
@@ -24,6 +24,8 @@ import (
 
 	jwtv4 "github.com/golang-jwt/jwt/v4"
 
+	"github.com/mendersoftware/mender-server/pkg/keys"
+
 	"github.com/mendersoftware/mender-server/services/useradm/common"
 )
 
@@ -47,6 +49,8 @@ type Handler interface {
 	// ErrTokenExpired when the token is valid but expired
 	// ErrTokenInvalid when the token is invalid (malformed, missing required claims, etc.)
 	FromJWT(string) (*Token, error)
+	// JWKS returns the public keys verifying the tokens.
+	JWKS() (*keys.JWKS, error)
 }
 
 func NewJWTHandler(privateKeyPath string, privateKeyFilenamePattern string) (Handler, error) {
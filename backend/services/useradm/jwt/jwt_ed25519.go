@@ -15,11 +15,14 @@ package jwt
 
 import (
 	"crypto/ed25519"
+	"sort"
 	"strconv"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
 
+	"github.com/mendersoftware/mender-server/pkg/keys"
+
 	"github.com/mendersoftware/mender-server/services/useradm/common"
 )
 
@@ -81,3 +84,22 @@ func (j *JWTHandlerEd25519) FromJWT(tokstr string) (*Token, error) {
 
 	return nil, ErrTokenInvalid
 }
+
+func (j *JWTHandlerEd25519) JWKS() (*keys.JWKS, error) {
+	keyIds := make([]int, 0, len(j.privKey))
+	for keyId := range j.privKey {
+		keyIds = append(keyIds, keyId)
+	}
+	sort.Ints(keyIds)
+
+	jwks := &keys.JWKS{Keys: make([]keys.JWK, 0, len(keyIds))}
+	for _, keyId := range keyIds {
+		key := j.privKey[keyId]
+		jwk, err := keys.NewJWK(strconv.Itoa(keyId), key.Public())
+		if err != nil {
+			return nil, err
+		}
+		jwks.Keys = append(jwks.Keys, *jwk)
+	}
+	return jwks, nil
+}
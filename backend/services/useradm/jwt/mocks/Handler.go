@@ -17,7 +17,9 @@
 package mocks
 
 import (
+	keys "github.com/mendersoftware/mender-server/pkg/keys"
 	jwt "github.com/mendersoftware/mender-server/services/useradm/jwt"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -56,6 +58,36 @@ func (_m *Handler) FromJWT(_a0 string) (*jwt.Token, error) {
 	return r0, r1
 }
 
+// JWKS provides a mock function with given fields:
+func (_m *Handler) JWKS() (*keys.JWKS, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for JWKS")
+	}
+
+	var r0 *keys.JWKS
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*keys.JWKS, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *keys.JWKS); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*keys.JWKS)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ToJWT provides a mock function with given fields: t
 func (_m *Handler) ToJWT(t *jwt.Token) (string, error) {
 	ret := _m.Called(t)
@@ -22,6 +22,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -157,6 +158,24 @@ func loadJWTHandlers(
 		return
 	}
 
+	// the retired keys are not loaded: the tokens signed with them are
+	// no longer valid
+	retired := make(map[int]bool)
+	for _, s := range c.GetStringSlice(SettingServerRetiredKeyIds) {
+		keyId, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid retired key id %q", s)
+		}
+		retired[keyId] = true
+	}
+	activeKeyId := common.KeyIdFromPath(
+		c.GetString(SettingServerPrivKeyPath),
+		privateKeyPattern,
+	)
+	if retired[activeKeyId] {
+		return nil, nil, errors.Errorf("the active key id %d is retired", activeKeyId)
+	}
+
 	handlers = make(map[int]jwt.Handler, len(files))
 	for _, fileEntry := range files {
 		if r.MatchString(fileEntry.Name()) {
@@ -166,6 +185,10 @@ func loadJWTHandlers(
 				continue
 			}
 			keyId := common.KeyIdFromPath(keyPath, privateKeyPattern)
+			if retired[keyId] {
+				l.Infof("skipping retired private key id=%d from %s", keyId, keyPath)
+				continue
+			}
 			l.Infof("loaded private key id=%d from %s", keyId, keyPath)
 			handlers[keyId] = handler
 		}
@@ -178,7 +201,7 @@ func loadJWTHandlers(
 		SettingServerPrivKeyPathDefault,
 		c.GetString(SettingServerPrivKeyFileNamePattern),
 	)
-	if err == nil && defaultHandler != nil {
+	if err == nil && defaultHandler != nil && !retired[common.KeyIdZero] {
 		// the key with id 0 is by default the default one. this allows
 		// to support tokens without "kid" in the header
 		// it is possible, that you rotated the default key, in which case you have to
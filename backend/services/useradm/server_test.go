@@ -43,3 +43,25 @@ func TestAddPrivateKeys(t *testing.T) {
 	assert.Contains(t, handlers, 9478)
 	assert.Nil(t, fallbackHandler)
 }
+
+func TestAddPrivateKeysRetired(t *testing.T) {
+	l := log.New(log.Ctx{})
+	c := viper.New()
+	c.Set(config.SettingServerPrivKeyPath, "./user/testdata/private.id.826.pem")
+	c.Set(config.SettingServerPrivKeyFileNamePattern, "private\\.id\\.([0-9]*)\\.pem")
+	c.Set(config.SettingServerRetiredKeyIds, []string{"1024", "2048"})
+	handlers, _, err := loadJWTHandlers(c, l)
+	assert.NoError(t, err)
+	assert.Len(t, handlers, 8)
+	assert.NotContains(t, handlers, 1024)
+	assert.NotContains(t, handlers, 2048)
+	assert.Contains(t, handlers, 826)
+
+	c.Set(config.SettingServerRetiredKeyIds, []string{"826"})
+	_, _, err = loadJWTHandlers(c, l)
+	assert.EqualError(t, err, "the active key id 826 is retired")
+
+	c.Set(config.SettingServerRetiredKeyIds, []string{"foo"})
+	_, _, err = loadJWTHandlers(c, l)
+	assert.ErrorContains(t, err, `invalid retired key id "foo"`)
+}